@@ -0,0 +1,73 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterScaledObjectPolicy defines the defaults the mutating webhook injects into ScaledObjects that don't set them
+// +genclient
+// +genclient:nonNamespaced
+// +kubebuilder:resource:path=clusterscaledobjectpolicies,scope=Cluster,shortName=csop
+// +kubebuilder:printcolumn:name="PollingInterval",type="integer",JSONPath=".spec.defaults.pollingInterval"
+// +kubebuilder:printcolumn:name="CooldownPeriod",type="integer",JSONPath=".spec.defaults.cooldownPeriod"
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp"
+type ClusterScaledObjectPolicy struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec ClusterScaledObjectPolicySpec `json:"spec"`
+}
+
+// ClusterScaledObjectPolicySpec is the spec of a ClusterScaledObjectPolicy
+type ClusterScaledObjectPolicySpec struct {
+	Defaults ScaledObjectDefaults `json:"defaults"`
+}
+
+// ScaledObjectDefaults are the fields of a ScaledObject that are set when the ScaledObject doesn't set them itself.
+// When several policies exist they are applied in the order of their names, the first policy setting a field wins
+type ScaledObjectDefaults struct {
+	// +optional
+	PollingInterval *int32 `json:"pollingInterval,omitempty"`
+	// +optional
+	CooldownPeriod *int32 `json:"cooldownPeriod,omitempty"`
+	// Behavior is set as the advanced.horizontalPodAutoscalerConfig.behavior
+	// +optional
+	Behavior *autoscalingv2beta2.HorizontalPodAutoscalerBehavior `json:"behavior,omitempty"`
+	// +optional
+	Fallback *Fallback `json:"fallback,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// ClusterScaledObjectPolicyList contains a list of ClusterScaledObjectPolicy
+type ClusterScaledObjectPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata"`
+	Items           []ClusterScaledObjectPolicy `json:"items"`
+}
+
+// DefaultsPolicyAnnotation lists the ClusterScaledObjectPolicies whose defaults were injected into a ScaledObject
+const DefaultsPolicyAnnotation = "scaledobject.keda.sh/defaults-policies"
+
+func init() {
+	SchemeBuilder.Register(&ClusterScaledObjectPolicy{}, &ClusterScaledObjectPolicyList{})
+}
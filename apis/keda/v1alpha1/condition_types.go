@@ -32,6 +32,9 @@ const (
 	ConditionActive ConditionType = "Active"
 	// ConditionFallback specifies that the resource has a fallback active.
 	ConditionFallback ConditionType = "Fallback"
+	// ConditionAtMaxReplicas specifies that the ScaleTarget is pinned at maxReplicaCount
+	// while the metrics are above target.
+	ConditionAtMaxReplicas ConditionType = "AtMaxReplicas"
 )
 
 // Condition to store the condition state
@@ -140,6 +143,20 @@ func (c *Conditions) SetFallbackCondition(status metav1.ConditionStatus, reason
 	c.setCondition(ConditionFallback, status, reason, message)
 }
 
+// SetAtMaxReplicasCondition modifies AtMaxReplicas Condition according to input parameters,
+// the condition is added if it isn't present as it is not part of the initialized Conditions
+func (c *Conditions) SetAtMaxReplicasCondition(status metav1.ConditionStatus, reason string, message string) {
+	for i := range *c {
+		if (*c)[i].Type == ConditionAtMaxReplicas {
+			(*c)[i].Status = status
+			(*c)[i].Reason = reason
+			(*c)[i].Message = message
+			return
+		}
+	}
+	*c = append(*c, Condition{Type: ConditionAtMaxReplicas, Status: status, Reason: reason, Message: message})
+}
+
 // GetActiveCondition returns Condition of type Active
 func (c *Conditions) GetActiveCondition() Condition {
 	if *c == nil {
@@ -164,6 +181,18 @@ func (c *Conditions) GetFallbackCondition() Condition {
 	return c.getCondition(ConditionFallback)
 }
 
+// GetAtMaxReplicasCondition returns Condition of type AtMaxReplicas
+func (c *Conditions) GetAtMaxReplicasCondition() Condition {
+	if *c == nil {
+		return Condition{Type: ConditionAtMaxReplicas, Status: metav1.ConditionUnknown}
+	}
+	condition := c.getCondition(ConditionAtMaxReplicas)
+	if condition.Type == "" {
+		return Condition{Type: ConditionAtMaxReplicas, Status: metav1.ConditionUnknown}
+	}
+	return condition
+}
+
 func (c Conditions) getCondition(conditionType ConditionType) Condition {
 	for i := range c {
 		if c[i].Type == conditionType {
@@ -58,7 +58,20 @@ type ScaledJobSpec struct {
 	MaxReplicaCount *int32 `json:"maxReplicaCount,omitempty"`
 	// +optional
 	ScalingStrategy ScalingStrategy `json:"scalingStrategy,omitempty"`
-	Triggers        []ScaleTriggers `json:"triggers"`
+	// +optional
+	JobParameters *JobParameters `json:"jobParameters,omitempty"`
+	Triggers      []ScaleTriggers `json:"triggers"`
+}
+
+// JobParameters lets a trigger split the work of its queue between the created Jobs, the parameters of every Job,
+// like the partitions it consumes, are added to the environment of its containers and to its annotations
+type JobParameters struct {
+	// TriggerName is the trigger providing the parameters, defaults to the trigger the Jobs are created for
+	// +optional
+	TriggerName string `json:"triggerName,omitempty"`
+	// ContainerName is the container the environment variables are added to, defaults to all containers
+	// +optional
+	ContainerName string `json:"containerName,omitempty"`
 }
 
 // ScaledJobStatus defines the observed state of ScaledJob
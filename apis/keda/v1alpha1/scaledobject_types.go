@@ -18,6 +18,8 @@ package v1alpha1
 
 import (
 	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -78,6 +80,10 @@ type ScaledObjectSpec struct {
 	MinReplicaCount *int32 `json:"minReplicaCount,omitempty"`
 	// +optional
 	MaxReplicaCount *int32 `json:"maxReplicaCount,omitempty"`
+	// MaxReplicaCountFrom sources the maxReplicaCount dynamically on every poll, MaxReplicaCount
+	// is used until the source can be read
+	// +optional
+	MaxReplicaCountFrom *MaxReplicaCountSource `json:"maxReplicaCountFrom,omitempty"`
 	// +optional
 	Advanced *AdvancedConfig `json:"advanced,omitempty"`
 
@@ -86,6 +92,17 @@ type ScaledObjectSpec struct {
 	Fallback *Fallback `json:"fallback,omitempty"`
 }
 
+// MaxReplicaCountSource is the source of the maxReplicaCount of a ScaledObject, exactly one of the fields has to be set
+type MaxReplicaCountSource struct {
+	// ConfigMapKeyRef reads the maxReplicaCount from a key of a ConfigMap in the namespace of the ScaledObject
+	// +optional
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
+	// TriggerName reads the maxReplicaCount from the trigger with the name, its scaler has to provide a
+	// replica ceiling, eg. the partition count of the topic of a kafka trigger
+	// +optional
+	TriggerName string `json:"triggerName,omitempty"`
+}
+
 // Fallback is the spec for fallback options
 type Fallback struct {
 	FailureThreshold int32 `json:"failureThreshold"`
@@ -98,6 +115,62 @@ type AdvancedConfig struct {
 	HorizontalPodAutoscalerConfig *HorizontalPodAutoscalerConfig `json:"horizontalPodAutoscalerConfig,omitempty"`
 	// +optional
 	RestoreToOriginalReplicaCount bool `json:"restoreToOriginalReplicaCount,omitempty"`
+	// DirectScalingFallback lets KEDA set the replica count of the ScaleTarget directly
+	// while the HPA can't get the metrics from the KEDA Metrics Server
+	// +optional
+	DirectScalingFallback bool `json:"directScalingFallback,omitempty"`
+	// ActivationLogic controls how the activity of the triggers is combined to activate the ScaleTarget:
+	// anyOf (default) activates when any trigger is active, allOf only when all triggers are active
+	// and expression when ActivationExpression over the names of the triggers is true
+	// +kubebuilder:validation:Enum=anyOf;allOf;expression
+	// +optional
+	ActivationLogic string `json:"activationLogic,omitempty"`
+	// ActivationExpression is a boolean expression over trigger names combined with
+	// AND, OR, NOT and parentheses, eg. "queue AND (business-hours OR NOT weekend)"
+	// +optional
+	ActivationExpression string `json:"activationExpression,omitempty"`
+	// SaturationMinutes is the number of minutes the ScaleTarget has to be at maxReplicaCount with
+	// the metrics above target before the AtMaxReplicas condition is set, defaults to 5
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	SaturationMinutes *int32 `json:"saturationMinutes,omitempty"`
+	// ChangePolicy controls how changes of the triggers take effect: immediate (default) applies them right away
+	// and canary keeps the applied triggers in effect for CanaryPolls polls, while the changed triggers run next
+	// to them and their divergence is reported in the status
+	// +kubebuilder:validation:Enum=immediate;canary
+	// +optional
+	ChangePolicy string `json:"changePolicy,omitempty"`
+	// CanaryPolls is the number of polls the changed triggers run next to the applied triggers with
+	// changePolicy canary, defaults to 5
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	CanaryPolls *int32 `json:"canaryPolls,omitempty"`
+}
+
+const (
+	// ActivationLogicAnyOf activates the ScaleTarget when any trigger is active
+	ActivationLogicAnyOf = "anyOf"
+	// ActivationLogicAllOf activates the ScaleTarget when all triggers are active
+	ActivationLogicAllOf = "allOf"
+	// ActivationLogicExpression activates the ScaleTarget when the ActivationExpression is true
+	ActivationLogicExpression = "expression"
+)
+
+const (
+	// ChangePolicyImmediate applies changes of the triggers right away
+	ChangePolicyImmediate = "immediate"
+	// ChangePolicyCanary applies changes of the triggers after they ran next to the applied triggers
+	ChangePolicyCanary = "canary"
+
+	defaultCanaryPolls = 5
+)
+
+// GetCanaryPolls returns the number of polls of a canary trigger change
+func (c *AdvancedConfig) GetCanaryPolls() int32 {
+	if c.CanaryPolls != nil {
+		return *c.CanaryPolls
+	}
+	return defaultCanaryPolls
 }
 
 // HorizontalPodAutoscalerConfig specifies horizontal scale config
@@ -106,6 +179,14 @@ type HorizontalPodAutoscalerConfig struct {
 	Behavior *autoscalingv2beta2.HorizontalPodAutoscalerBehavior `json:"behavior,omitempty"`
 }
 
+// ScaleDryRunAnnotation, when set to "true" on a ScaledObject, makes KEDA submit updates of the
+// ScaleTarget's scale subresource as server-side dry-run requests, so they are validated but not persisted
+const ScaleDryRunAnnotation = "scaledobject.keda.sh/scale-dry-run"
+
+// ExplainAnnotation, when set on a ScaledObject, makes KEDA write the resolved configuration of its triggers, with
+// secrets redacted, and the spec of its HPA to the ConfigMap <name>-keda-explain, changing its value writes it again
+const ExplainAnnotation = "scaledobject.keda.sh/explain"
+
 // ScaleTarget holds the a reference to the scale target Object
 type ScaleTarget struct {
 	Name string `json:"name"`
@@ -127,6 +208,104 @@ type ScaleTriggers struct {
 	AuthenticationRef *ScaledObjectAuthRef `json:"authenticationRef,omitempty"`
 	// +optional
 	FallbackReplicas *int32 `json:"fallback,omitempty"`
+	// +optional
+	ValueFrom []TriggerMetadataValueFrom `json:"valueFrom,omitempty"`
+	// MaxAllowedValue is the highest metric value accepted from the scaler, higher values are clamped
+	// +optional
+	MaxAllowedValue *resource.Quantity `json:"maxAllowedValue,omitempty"`
+	// +optional
+	SpikeDampening *SpikeDampening `json:"spikeDampening,omitempty"`
+	// ScalingDirection is normal, the replicas go up as the metric value goes up, or inverse, the replicas
+	// go down as the metric value goes up. Defaults to normal
+	// +kubebuilder:validation:Enum=normal;inverse
+	// +optional
+	ScalingDirection ScalingDirection `json:"scalingDirection,omitempty"`
+	// +optional
+	InverseScaling *InverseScaling `json:"inverseScaling,omitempty"`
+	// Transform is the list of steps applied in order to the metric values of the scaler before
+	// they are bounded and returned, the activity of the scaler isn't transformed
+	// +optional
+	Transform []MetricTransform `json:"transform,omitempty"`
+}
+
+// ScalingDirection is the relationship between the metric value of a trigger and the replicas
+type ScalingDirection string
+
+const (
+	// ScalingDirectionNormal scales out as the metric value goes up
+	ScalingDirectionNormal ScalingDirection = "normal"
+	// ScalingDirectionInverse scales in as the metric value goes up
+	ScalingDirectionInverse ScalingDirection = "inverse"
+)
+
+// InverseMapping is the function mapping the metric value of an inverse trigger
+type InverseMapping string
+
+const (
+	// InverseMappingLinear maps the metric value to the headroom left to the value, max(value - metric, 0)
+	InverseMappingLinear InverseMapping = "linear"
+	// InverseMappingReciprocal maps the metric value to value / metric, metric values below 1 count as 1
+	InverseMappingReciprocal InverseMapping = "reciprocal"
+)
+
+// InverseScaling maps the metric value of a trigger with scalingDirection inverse before it is
+// compared to the target of the trigger
+type InverseScaling struct {
+	// Mapping is linear or reciprocal. Defaults to linear
+	// +kubebuilder:validation:Enum=linear;reciprocal
+	// +optional
+	Mapping InverseMapping `json:"mapping,omitempty"`
+	// Value is the metric value at which the linear mapping returns 0 or the dividend of the reciprocal mapping
+	Value resource.Quantity `json:"value"`
+}
+
+// SpikeDampening limits how fast the metric value of a trigger can grow
+type SpikeDampening struct {
+	// MaxIncrease is the highest increase of the metric value compared to the previously returned value
+	MaxIncrease resource.Quantity `json:"maxIncrease"`
+}
+
+// MetricTransformType is the operation of a transform step
+type MetricTransformType string
+
+const (
+	// MetricTransformMultiply multiplies the metric value by value
+	MetricTransformMultiply MetricTransformType = "multiply"
+	// MetricTransformAdd adds value to the metric value
+	MetricTransformAdd MetricTransformType = "add"
+	// MetricTransformClamp limits the metric value to min and max
+	MetricTransformClamp MetricTransformType = "clamp"
+	// MetricTransformEMA smooths the metric value with an exponential moving average,
+	// alpha * value + (1 - alpha) * previous average
+	MetricTransformEMA MetricTransformType = "ema"
+)
+
+// MetricTransform is a step of the transformation of the metric values of a trigger
+type MetricTransform struct {
+	// +kubebuilder:validation:Enum=multiply;add;clamp;ema
+	Type MetricTransformType `json:"type"`
+	// Value is the factor of multiply and the addend of add
+	// +optional
+	Value *resource.Quantity `json:"value,omitempty"`
+	// Min is the lowest value of clamp
+	// +optional
+	Min *resource.Quantity `json:"min,omitempty"`
+	// Max is the highest value of clamp
+	// +optional
+	Max *resource.Quantity `json:"max,omitempty"`
+	// Alpha is the weight of the latest value in the average of ema, greater than 0 and at most 1
+	// +optional
+	Alpha *resource.Quantity `json:"alpha,omitempty"`
+}
+
+// TriggerMetadataValueFrom resolves the value of a trigger metadata parameter
+// from a key of a Secret or a ConfigMap in the namespace of the scalable object
+type TriggerMetadataValueFrom struct {
+	Parameter string `json:"parameter"`
+	// +optional
+	SecretKeyRef *corev1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+	// +optional
+	ConfigMapKeyRef *corev1.ConfigMapKeySelector `json:"configMapKeyRef,omitempty"`
 }
 
 // +k8s:openapi-gen=true
@@ -144,12 +323,47 @@ type ScaledObjectStatus struct {
 	LastActiveTime *metav1.Time `json:"lastActiveTime,omitempty"`
 	// +optional
 	ExternalMetricNames []string `json:"externalMetricNames,omitempty"`
+	// HpaName is the name of the HPA that KEDA manages for the ScaledObject
+	// +optional
+	HpaName string `json:"hpaName,omitempty"`
 	// +optional
 	ResourceMetricNames []string `json:"resourceMetricNames,omitempty"`
 	// +optional
 	Conditions Conditions `json:"conditions,omitempty"`
 	// +optional
 	Health map[string]HealthStatus `json:"health,omitempty"`
+	// ResolvedMaxReplicaCount is the maxReplicaCount read from maxReplicaCountFrom on the last poll
+	// +optional
+	ResolvedMaxReplicaCount *int32 `json:"resolvedMaxReplicaCount,omitempty"`
+	// Canary holds the triggers in effect with changePolicy canary and the evaluation of changed triggers
+	// +optional
+	Canary *CanaryStatus `json:"canary,omitempty"`
+}
+
+// CanaryStatus is the state of the triggers of a ScaledObject with changePolicy canary
+type CanaryStatus struct {
+	// AppliedTriggers are the triggers in effect
+	AppliedTriggers []ScaleTriggers `json:"appliedTriggers"`
+	// AppliedGeneration is the generation of the ScaledObject the applied triggers are from
+	AppliedGeneration int64 `json:"appliedGeneration"`
+	// CandidateGeneration is the generation of the ScaledObject whose changed triggers are evaluated
+	// +optional
+	CandidateGeneration int64 `json:"candidateGeneration,omitempty"`
+	// RemainingPolls is the number of polls left before the changed triggers take effect
+	// +optional
+	RemainingPolls int32 `json:"remainingPolls,omitempty"`
+	// DivergentPolls is the number of polls of the evaluation in which the changed triggers led to
+	// another scaling decision than the applied triggers
+	// +optional
+	DivergentPolls int32 `json:"divergentPolls,omitempty"`
+	// LastDivergence describes the scaling decisions of the last divergent poll
+	// +optional
+	LastDivergence string `json:"lastDivergence,omitempty"`
+}
+
+// IsEvaluating returns true while changed triggers are evaluated next to the applied triggers
+func (c *CanaryStatus) IsEvaluating() bool {
+	return c != nil && c.CandidateGeneration != 0
 }
 
 // +kubebuilder:object:root=true
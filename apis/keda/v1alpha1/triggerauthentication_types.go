@@ -75,6 +75,9 @@ type TriggerAuthenticationSpec struct {
 
 	// +optional
 	HashiCorpVault *HashiCorpVault `json:"hashiCorpVault,omitempty"`
+
+	// +optional
+	BoundServiceAccountToken []BoundServiceAccountToken `json:"boundServiceAccountToken,omitempty"`
 }
 
 // +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
@@ -130,6 +133,22 @@ type AuthEnvironment struct {
 	ContainerName string `json:"containerName,omitempty"`
 }
 
+// BoundServiceAccountToken is used to authenticate using a token requested for a ServiceAccount
+// in the namespace of the TriggerAuthentication, eg. as bearer token for RBAC protected endpoints.
+// The ServiceAccount has to opt in with the keda.sh/allow-bound-service-account-token: "true" annotation
+type BoundServiceAccountToken struct {
+	Parameter          string `json:"parameter"`
+	ServiceAccountName string `json:"serviceAccountName"`
+
+	// +optional
+	Audiences []string `json:"audiences,omitempty"`
+
+	// ExpirationSeconds is the requested lifetime of the token, it is requested again before it expires
+	// +kubebuilder:validation:Minimum=600
+	// +optional
+	ExpirationSeconds *int64 `json:"expirationSeconds,omitempty"`
+}
+
 // HashiCorpVault is used to authenticate using Hashicorp Vault
 type HashiCorpVault struct {
 	Address        string              `json:"address"`
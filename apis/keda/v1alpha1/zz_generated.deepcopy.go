@@ -24,6 +24,7 @@ package v1alpha1
 import (
 	"k8s.io/api/autoscaling/v2beta2"
 	"k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -35,6 +36,16 @@ func (in *AdvancedConfig) DeepCopyInto(out *AdvancedConfig) {
 		*out = new(HorizontalPodAutoscalerConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.SaturationMinutes != nil {
+		in, out := &in.SaturationMinutes, &out.SaturationMinutes
+		*out = new(int32)
+		**out = **in
+	}
+	if in.CanaryPolls != nil {
+		in, out := &in.CanaryPolls, &out.CanaryPolls
+		*out = new(int32)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new AdvancedConfig.
@@ -92,6 +103,127 @@ func (in *AuthSecretTargetRef) DeepCopy() *AuthSecretTargetRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *BoundServiceAccountToken) DeepCopyInto(out *BoundServiceAccountToken) {
+	*out = *in
+	if in.Audiences != nil {
+		in, out := &in.Audiences, &out.Audiences
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ExpirationSeconds != nil {
+		in, out := &in.ExpirationSeconds, &out.ExpirationSeconds
+		*out = new(int64)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new BoundServiceAccountToken.
+func (in *BoundServiceAccountToken) DeepCopy() *BoundServiceAccountToken {
+	if in == nil {
+		return nil
+	}
+	out := new(BoundServiceAccountToken)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanaryStatus) DeepCopyInto(out *CanaryStatus) {
+	*out = *in
+	if in.AppliedTriggers != nil {
+		in, out := &in.AppliedTriggers, &out.AppliedTriggers
+		*out = make([]ScaleTriggers, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanaryStatus.
+func (in *CanaryStatus) DeepCopy() *CanaryStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(CanaryStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterScaledObjectPolicy) DeepCopyInto(out *ClusterScaledObjectPolicy) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterScaledObjectPolicy.
+func (in *ClusterScaledObjectPolicy) DeepCopy() *ClusterScaledObjectPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterScaledObjectPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterScaledObjectPolicy) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterScaledObjectPolicyList) DeepCopyInto(out *ClusterScaledObjectPolicyList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]ClusterScaledObjectPolicy, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterScaledObjectPolicyList.
+func (in *ClusterScaledObjectPolicyList) DeepCopy() *ClusterScaledObjectPolicyList {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterScaledObjectPolicyList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *ClusterScaledObjectPolicyList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterScaledObjectPolicySpec) DeepCopyInto(out *ClusterScaledObjectPolicySpec) {
+	*out = *in
+	in.Defaults.DeepCopyInto(&out.Defaults)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterScaledObjectPolicySpec.
+func (in *ClusterScaledObjectPolicySpec) DeepCopy() *ClusterScaledObjectPolicySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterScaledObjectPolicySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ClusterTriggerAuthentication) DeepCopyInto(out *ClusterTriggerAuthentication) {
 	*out = *in
@@ -294,6 +426,92 @@ func (in *HorizontalPodAutoscalerConfig) DeepCopy() *HorizontalPodAutoscalerConf
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InverseScaling) DeepCopyInto(out *InverseScaling) {
+	*out = *in
+	out.Value = in.Value.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InverseScaling.
+func (in *InverseScaling) DeepCopy() *InverseScaling {
+	if in == nil {
+		return nil
+	}
+	out := new(InverseScaling)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JobParameters) DeepCopyInto(out *JobParameters) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JobParameters.
+func (in *JobParameters) DeepCopy() *JobParameters {
+	if in == nil {
+		return nil
+	}
+	out := new(JobParameters)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaxReplicaCountSource) DeepCopyInto(out *MaxReplicaCountSource) {
+	*out = *in
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaxReplicaCountSource.
+func (in *MaxReplicaCountSource) DeepCopy() *MaxReplicaCountSource {
+	if in == nil {
+		return nil
+	}
+	out := new(MaxReplicaCountSource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MetricTransform) DeepCopyInto(out *MetricTransform) {
+	*out = *in
+	if in.Value != nil {
+		in, out := &in.Value, &out.Value
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Min != nil {
+		in, out := &in.Min, &out.Min
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Max != nil {
+		in, out := &in.Max, &out.Max
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Alpha != nil {
+		in, out := &in.Alpha, &out.Alpha
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MetricTransform.
+func (in *MetricTransform) DeepCopy() *MetricTransform {
+	if in == nil {
+		return nil
+	}
+	out := new(MetricTransform)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScaleTarget) DeepCopyInto(out *ScaleTarget) {
 	*out = *in
@@ -329,6 +547,35 @@ func (in *ScaleTriggers) DeepCopyInto(out *ScaleTriggers) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.ValueFrom != nil {
+		in, out := &in.ValueFrom, &out.ValueFrom
+		*out = make([]TriggerMetadataValueFrom, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.MaxAllowedValue != nil {
+		in, out := &in.MaxAllowedValue, &out.MaxAllowedValue
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.SpikeDampening != nil {
+		in, out := &in.SpikeDampening, &out.SpikeDampening
+		*out = new(SpikeDampening)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.InverseScaling != nil {
+		in, out := &in.InverseScaling, &out.InverseScaling
+		*out = new(InverseScaling)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Transform != nil {
+		in, out := &in.Transform, &out.Transform
+		*out = make([]MetricTransform, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaleTriggers.
@@ -429,6 +676,11 @@ func (in *ScaledJobSpec) DeepCopyInto(out *ScaledJobSpec) {
 		**out = **in
 	}
 	in.ScalingStrategy.DeepCopyInto(&out.ScalingStrategy)
+	if in.JobParameters != nil {
+		in, out := &in.JobParameters, &out.JobParameters
+		*out = new(JobParameters)
+		**out = **in
+	}
 	if in.Triggers != nil {
 		in, out := &in.Triggers, &out.Triggers
 		*out = make([]ScaleTriggers, len(*in))
@@ -514,6 +766,41 @@ func (in *ScaledObjectAuthRef) DeepCopy() *ScaledObjectAuthRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScaledObjectDefaults) DeepCopyInto(out *ScaledObjectDefaults) {
+	*out = *in
+	if in.PollingInterval != nil {
+		in, out := &in.PollingInterval, &out.PollingInterval
+		*out = new(int32)
+		**out = **in
+	}
+	if in.CooldownPeriod != nil {
+		in, out := &in.CooldownPeriod, &out.CooldownPeriod
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Behavior != nil {
+		in, out := &in.Behavior, &out.Behavior
+		*out = new(v2beta2.HorizontalPodAutoscalerBehavior)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Fallback != nil {
+		in, out := &in.Fallback, &out.Fallback
+		*out = new(Fallback)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaledObjectDefaults.
+func (in *ScaledObjectDefaults) DeepCopy() *ScaledObjectDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(ScaledObjectDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ScaledObjectList) DeepCopyInto(out *ScaledObjectList) {
 	*out = *in
@@ -579,6 +866,11 @@ func (in *ScaledObjectSpec) DeepCopyInto(out *ScaledObjectSpec) {
 		*out = new(int32)
 		**out = **in
 	}
+	if in.MaxReplicaCountFrom != nil {
+		in, out := &in.MaxReplicaCountFrom, &out.MaxReplicaCountFrom
+		*out = new(MaxReplicaCountSource)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Advanced != nil {
 		in, out := &in.Advanced, &out.Advanced
 		*out = new(AdvancedConfig)
@@ -647,6 +939,16 @@ func (in *ScaledObjectStatus) DeepCopyInto(out *ScaledObjectStatus) {
 			(*out)[key] = *val.DeepCopy()
 		}
 	}
+	if in.ResolvedMaxReplicaCount != nil {
+		in, out := &in.ResolvedMaxReplicaCount, &out.ResolvedMaxReplicaCount
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(CanaryStatus)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScaledObjectStatus.
@@ -684,6 +986,22 @@ func (in *ScalingStrategy) DeepCopy() *ScalingStrategy {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SpikeDampening) DeepCopyInto(out *SpikeDampening) {
+	*out = *in
+	out.MaxIncrease = in.MaxIncrease.DeepCopy()
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SpikeDampening.
+func (in *SpikeDampening) DeepCopy() *SpikeDampening {
+	if in == nil {
+		return nil
+	}
+	out := new(SpikeDampening)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TriggerAuthentication) DeepCopyInto(out *TriggerAuthentication) {
 	*out = *in
@@ -765,6 +1083,13 @@ func (in *TriggerAuthenticationSpec) DeepCopyInto(out *TriggerAuthenticationSpec
 		*out = new(HashiCorpVault)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.BoundServiceAccountToken != nil {
+		in, out := &in.BoundServiceAccountToken, &out.BoundServiceAccountToken
+		*out = make([]BoundServiceAccountToken, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TriggerAuthenticationSpec.
@@ -777,6 +1102,31 @@ func (in *TriggerAuthenticationSpec) DeepCopy() *TriggerAuthenticationSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TriggerMetadataValueFrom) DeepCopyInto(out *TriggerMetadataValueFrom) {
+	*out = *in
+	if in.SecretKeyRef != nil {
+		in, out := &in.SecretKeyRef, &out.SecretKeyRef
+		*out = new(corev1.SecretKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ConfigMapKeyRef != nil {
+		in, out := &in.ConfigMapKeyRef, &out.ConfigMapKeyRef
+		*out = new(corev1.ConfigMapKeySelector)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TriggerMetadataValueFrom.
+func (in *TriggerMetadataValueFrom) DeepCopy() *TriggerMetadataValueFrom {
+	if in == nil {
+		return nil
+	}
+	out := new(TriggerMetadataValueFrom)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VaultSecret) DeepCopyInto(out *VaultSecret) {
 	*out = *in
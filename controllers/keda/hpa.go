@@ -20,28 +20,32 @@ import (
 	"context"
 	"fmt"
 	"sort"
-	"strings"
-	"unicode"
 
 	"github.com/go-logr/logr"
 	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
 	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	kedacontrollerutil "github.com/kedacore/keda/v2/controllers/keda/util"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
 	version "github.com/kedacore/keda/v2/version"
 )
 
 const (
 	defaultHPAMinReplicas int32 = 1
 	defaultHPAMaxReplicas int32 = 100
+
+	// legacyHPANamePrefix is the prefix of the names of the HPAs created before the names were generated from a template
+	legacyHPANamePrefix = "keda-hpa-"
 )
 
 // createAndDeployNewHPA creates and deploy HPA in the cluster for specified ScaledObject
 func (r *ScaledObjectReconciler) createAndDeployNewHPA(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, gvkr *kedav1alpha1.GroupVersionKindResource) error {
-	hpaName := getHPAName(scaledObject)
+	hpaName := r.getHPAName(scaledObject)
 	logger.Info("Creating a new HPA", "HPA.Namespace", scaledObject.Namespace, "HPA.Name", hpaName)
 	hpa, err := r.newHPAForScaledObject(ctx, logger, scaledObject, gvkr)
 	if err != nil {
@@ -65,6 +69,11 @@ func (r *ScaledObjectReconciler) newHPAForScaledObject(ctx context.Context, logg
 		return nil, err
 	}
 
+	return r.newHPAWithMetricSpecs(scaledObject, gvkr, scaledObjectMetricSpecs)
+}
+
+// newHPAWithMetricSpecs returns the HPA of the ScaledObject with the metric specs of its scalers
+func (r *ScaledObjectReconciler) newHPAWithMetricSpecs(scaledObject *kedav1alpha1.ScaledObject, gvkr *kedav1alpha1.GroupVersionKindResource, scaledObjectMetricSpecs []autoscalingv2beta2.MetricSpec) (*autoscalingv2beta2.HorizontalPodAutoscaler, error) {
 	var behavior *autoscalingv2beta2.HorizontalPodAutoscalerBehavior
 	if r.kubeVersion.MinorVersion >= 18 && scaledObject.Spec.Advanced != nil && scaledObject.Spec.Advanced.HorizontalPodAutoscalerConfig != nil {
 		behavior = scaledObject.Spec.Advanced.HorizontalPodAutoscalerConfig.Behavior
@@ -72,16 +81,9 @@ func (r *ScaledObjectReconciler) newHPAForScaledObject(ctx context.Context, logg
 		behavior = nil
 	}
 
-	// label can have max 63 chars
-	labelName := getHPAName(scaledObject)
-	if len(labelName) > 63 {
-		labelName = labelName[:63]
-		labelName = strings.TrimRightFunc(labelName, func(r rune) bool {
-			return !unicode.IsLetter(r) && !unicode.IsNumber(r)
-		})
-	}
+	hpaName := r.getHPAName(scaledObject)
 	labels := map[string]string{
-		"app.kubernetes.io/name":       labelName,
+		"app.kubernetes.io/name":       hpaName,
 		"app.kubernetes.io/version":    version.Version,
 		"app.kubernetes.io/part-of":    scaledObject.Name,
 		"app.kubernetes.io/managed-by": "keda-operator",
@@ -102,7 +104,7 @@ func (r *ScaledObjectReconciler) newHPAForScaledObject(ctx context.Context, logg
 				APIVersion: gvkr.GroupVersion().String(),
 			}},
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      getHPAName(scaledObject),
+			Name:      hpaName,
 			Namespace: scaledObject.Namespace,
 			Labels:    labels,
 		},
@@ -123,7 +125,7 @@ func (r *ScaledObjectReconciler) newHPAForScaledObject(ctx context.Context, logg
 func (r *ScaledObjectReconciler) updateHPAIfNeeded(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, foundHpa *autoscalingv2beta2.HorizontalPodAutoscaler, gvkr *kedav1alpha1.GroupVersionKindResource) error {
 	hpa, err := r.newHPAForScaledObject(ctx, logger, scaledObject, gvkr)
 	if err != nil {
-		logger.Error(err, "Failed to create new HPA resource", "HPA.Namespace", scaledObject.Namespace, "HPA.Name", getHPAName(scaledObject))
+		logger.Error(err, "Failed to create new HPA resource", "HPA.Namespace", scaledObject.Namespace, "HPA.Name", r.getHPAName(scaledObject))
 		return err
 	}
 
@@ -156,27 +158,52 @@ func (r *ScaledObjectReconciler) updateHPAIfNeeded(ctx context.Context, logger l
 
 // getScaledObjectMetricSpecs returns MetricSpec for HPA, generater from Triggers defitinion in ScaledObject
 func (r *ScaledObjectReconciler) getScaledObjectMetricSpecs(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject) ([]autoscalingv2beta2.MetricSpec, error) {
-	var scaledObjectMetricSpecs []autoscalingv2beta2.MetricSpec
-	var externalMetricNames []string
-	var resourceMetricNames []string
-
 	cache, err := r.scaleHandler.GetScalersCache(ctx, scaledObject)
 	if err != nil {
 		logger.Error(err, "Error getting scalers")
 		return nil, err
 	}
 
-	metricSpecs := cache.GetMetricSpecForScaling(ctx)
+	scaledObjectMetricSpecs, externalMetricNames, resourceMetricNames, err := toHPAMetricSpecs(scaledObject, cache.GetMetricSpecForScaling(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	// store External.MetricNames,Resource.MetricsNames used by scalers defined in the ScaledObject
+	status := scaledObject.Status.DeepCopy()
+	status.ExternalMetricNames = externalMetricNames
+	status.ResourceMetricNames = resourceMetricNames
+
+	updateHealthStatus(scaledObject, externalMetricNames, status)
+
+	err = kedacontrollerutil.UpdateScaledObjectStatus(ctx, r.Client, logger, scaledObject, status)
+	if err != nil {
+		logger.Error(err, "Error updating scaledObject status with used externalMetricNames")
+		return nil, err
+	}
+
+	return scaledObjectMetricSpecs, nil
+}
+
+// toHPAMetricSpecs returns the metric specs of the scalers of the ScaledObject as HPA metric specs, with the names
+// of their external and resource metrics
+func toHPAMetricSpecs(scaledObject *kedav1alpha1.ScaledObject, metricSpecs []autoscalingv2beta2.MetricSpec) ([]autoscalingv2beta2.MetricSpec, []string, []string, error) {
+	var scaledObjectMetricSpecs []autoscalingv2beta2.MetricSpec
+	var externalMetricNames []string
+	var resourceMetricNames []string
 
 	for _, metricSpec := range metricSpecs {
 		if metricSpec.Resource != nil {
 			resourceMetricNames = append(resourceMetricNames, string(metricSpec.Resource.Name))
 		}
+		if metricSpec.ContainerResource != nil {
+			resourceMetricNames = append(resourceMetricNames, string(metricSpec.ContainerResource.Name))
+		}
 
 		if metricSpec.External != nil {
 			externalMetricName := metricSpec.External.Metric.Name
 			if kedacontrollerutil.Contains(externalMetricNames, externalMetricName) {
-				return nil, fmt.Errorf("metricName %s defined multiple times in ScaledObject %s, please refer the documentation how to define metricName manually", externalMetricName, scaledObject.Name)
+				return nil, nil, nil, fmt.Errorf("metricName %s defined multiple times in ScaledObject %s, please refer the documentation how to define metricName manually", externalMetricName, scaledObject.Name)
 			}
 
 			// add the scaledobject.keda.sh/name label. This is how the MetricsAdapter will know which scaledobject a metric is for when the HPA queries it.
@@ -193,20 +220,7 @@ func (r *ScaledObjectReconciler) getScaledObjectMetricSpecs(ctx context.Context,
 		return scaledObjectMetricSpecs[i].Type < scaledObjectMetricSpecs[j].Type
 	})
 
-	// store External.MetricNames,Resource.MetricsNames used by scalers defined in the ScaledObject
-	status := scaledObject.Status.DeepCopy()
-	status.ExternalMetricNames = externalMetricNames
-	status.ResourceMetricNames = resourceMetricNames
-
-	updateHealthStatus(scaledObject, externalMetricNames, status)
-
-	err = kedacontrollerutil.UpdateScaledObjectStatus(ctx, r.Client, logger, scaledObject, status)
-	if err != nil {
-		logger.Error(err, "Error updating scaledObject status with used externalMetricNames")
-		return nil, err
-	}
-
-	return scaledObjectMetricSpecs, nil
+	return scaledObjectMetricSpecs, externalMetricNames, resourceMetricNames, nil
 }
 
 func updateHealthStatus(scaledObject *kedav1alpha1.ScaledObject, externalMetricNames []string, status *kedav1alpha1.ScaledObjectStatus) {
@@ -230,9 +244,52 @@ func (r *ScaledObjectReconciler) checkMinK8sVersionforHPABehavior(logger logr.Lo
 	}
 }
 
-// getHPAName returns generated HPA name for ScaledObject specified in the parameter
-func getHPAName(scaledObject *kedav1alpha1.ScaledObject) string {
-	return fmt.Sprintf("keda-hpa-%s", scaledObject.Name)
+// getHPAName returns the name of the HPA the ScaledObject should have with the configured template
+func (r *ScaledObjectReconciler) getHPAName(scaledObject *kedav1alpha1.ScaledObject) string {
+	template := r.HPANameTemplate
+	if template == "" {
+		template = kedautil.DefaultHPANameTemplate
+	}
+	return kedautil.GenerateHPAName(template, scaledObject)
+}
+
+// getManagedHPAName returns the name of the HPA KEDA manages for the ScaledObject, ScaledObjects created
+// before the name was recorded in the status have an HPA named keda-hpa-<name>, long names weren't shortened
+func getManagedHPAName(scaledObject *kedav1alpha1.ScaledObject) string {
+	if scaledObject.Status.HpaName != "" {
+		return scaledObject.Status.HpaName
+	}
+	return legacyHPANamePrefix + scaledObject.Name
+}
+
+// updateHPANameIfNeeded records the name of the HPA in the status of the ScaledObject, if the HPA was renamed
+// it is called once the HPA with the new name exists and deletes the HPA with the previous name so the
+// ScaleTarget is scaled by an HPA at all times
+func (r *ScaledObjectReconciler) updateHPANameIfNeeded(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, hpaName string) error {
+	if scaledObject.Status.HpaName == hpaName {
+		return nil
+	}
+
+	if previousName := getManagedHPAName(scaledObject); previousName != hpaName {
+		previousHpa := &autoscalingv2beta2.HorizontalPodAutoscaler{}
+		err := r.Client.Get(ctx, types.NamespacedName{Name: previousName, Namespace: scaledObject.Namespace}, previousHpa)
+		switch {
+		case errors.IsNotFound(err):
+		case err != nil:
+			logger.Error(err, "Failed to get HPA with previous name", "HPA.Namespace", scaledObject.Namespace, "HPA.Name", previousName)
+			return err
+		case metav1.IsControlledBy(previousHpa, scaledObject):
+			if err := r.Client.Delete(ctx, previousHpa); err != nil && !errors.IsNotFound(err) {
+				logger.Error(err, "Failed to delete HPA with previous name", "HPA.Namespace", scaledObject.Namespace, "HPA.Name", previousName)
+				return err
+			}
+			logger.Info("Renamed HPA", "HPA.Namespace", scaledObject.Namespace, "HPA.PreviousName", previousName, "HPA.Name", hpaName)
+		}
+	}
+
+	status := scaledObject.Status.DeepCopy()
+	status.HpaName = hpaName
+	return kedacontrollerutil.UpdateScaledObjectStatus(ctx, r.Client, logger, scaledObject, status)
 }
 
 // getHPAMinReplicas returns MinReplicas based on definition in ScaledObject or default value if not defined
@@ -244,8 +301,12 @@ func getHPAMinReplicas(scaledObject *kedav1alpha1.ScaledObject) *int32 {
 	return &tmp
 }
 
-// getHPAMaxReplicas returns MaxReplicas based on definition in ScaledObject or default value if not defined
+// getHPAMaxReplicas returns MaxReplicas based on definition in ScaledObject or default value if not defined,
+// the value resolved by the scale loop takes precedence with maxReplicaCountFrom
 func getHPAMaxReplicas(scaledObject *kedav1alpha1.ScaledObject) int32 {
+	if scaledObject.Spec.MaxReplicaCountFrom != nil && scaledObject.Status.ResolvedMaxReplicaCount != nil {
+		return *scaledObject.Status.ResolvedMaxReplicaCount
+	}
 	if scaledObject.Spec.MaxReplicaCount != nil {
 		return *scaledObject.Spec.MaxReplicaCount
 	}
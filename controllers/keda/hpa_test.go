@@ -18,6 +18,7 @@ package keda
 
 import (
 	"context"
+	"strings"
 
 	"github.com/go-logr/logr"
 	"github.com/golang/mock/gomock"
@@ -25,6 +26,7 @@ import (
 	. "github.com/onsi/gomega"
 	"k8s.io/api/autoscaling/v2beta2"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
 
 	"github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	"github.com/kedacore/keda/v2/pkg/mock/mock_client"
@@ -118,6 +120,91 @@ var _ = Describe("hpa", func() {
 		Expect(capturedScaledObject.Status.Health).To(Equal(expectedHealth))
 	})
 
+	It("should label external metric specs with the ScaledObject and reject duplicate metric names", func() {
+		scaledObject := &v1alpha1.ScaledObject{ObjectMeta: v1.ObjectMeta{Name: "orders"}}
+		external := func(name string) v2beta2.MetricSpec {
+			return v2beta2.MetricSpec{Type: v2beta2.ExternalMetricSourceType, External: &v2beta2.ExternalMetricSource{Metric: v2beta2.MetricIdentifier{Name: name}}}
+		}
+
+		metricSpecs, externalMetricNames, resourceMetricNames, err := toHPAMetricSpecs(scaledObject, []v2beta2.MetricSpec{external("s0-queue"), external("s1-queue")})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(metricSpecs).To(HaveLen(2))
+		Expect(metricSpecs[0].External.Metric.Selector.MatchLabels).To(HaveKeyWithValue("scaledobject.keda.sh/name", "orders"))
+		Expect(externalMetricNames).To(Equal([]string{"s0-queue", "s1-queue"}))
+		Expect(resourceMetricNames).To(BeEmpty())
+
+		_, _, _, err = toHPAMetricSpecs(scaledObject, []v2beta2.MetricSpec{external("s0-queue"), external("s0-queue")})
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should generate HPA names from the template", func() {
+		scaledObject := &v1alpha1.ScaledObject{ObjectMeta: v1.ObjectMeta{Name: "orders", Namespace: "shop"}}
+
+		Expect(reconciler.getHPAName(scaledObject)).To(Equal("keda-hpa-orders"))
+		reconciler.HPANameTemplate = "{namespace}-{scaledobject}-hpa"
+		Expect(reconciler.getHPAName(scaledObject)).To(Equal("shop-orders-hpa"))
+	})
+
+	It("should shorten long HPA names with a stable hash", func() {
+		scaledObject := &v1alpha1.ScaledObject{ObjectMeta: v1.ObjectMeta{Name: strings.Repeat("orders-", 10) + "consumer"}}
+		other := &v1alpha1.ScaledObject{ObjectMeta: v1.ObjectMeta{Name: strings.Repeat("orders-", 10) + "producer"}}
+
+		name := reconciler.getHPAName(scaledObject)
+		Expect(len(name)).To(BeNumerically("<=", 63))
+		Expect(name).To(HavePrefix("keda-hpa-orders-"))
+		Expect(reconciler.getHPAName(scaledObject)).To(Equal(name))
+		Expect(reconciler.getHPAName(other)).ToNot(Equal(name))
+	})
+
+	It("should delete the HPA with the previous name once renamed", func() {
+		scaledObject := &v1alpha1.ScaledObject{ObjectMeta: v1.ObjectMeta{Name: "orders", Namespace: "shop", UID: "so-uid"}}
+		reconciler.HPANameTemplate = "{scaledobject}-hpa"
+		controller := true
+		previousHpa := v2beta2.HorizontalPodAutoscaler{ObjectMeta: v1.ObjectMeta{
+			Name:            "keda-hpa-orders",
+			Namespace:       "shop",
+			OwnerReferences: []v1.OwnerReference{{UID: "so-uid", Controller: &controller}},
+		}}
+
+		client.EXPECT().Get(gomock.Any(), types.NamespacedName{Name: "keda-hpa-orders", Namespace: "shop"}, gomock.Any()).SetArg(2, previousHpa)
+		client.EXPECT().Delete(gomock.Any(), gomock.Any()).Do(func(_ interface{}, hpa *v2beta2.HorizontalPodAutoscaler, _ ...interface{}) {
+			Expect(hpa.Name).To(Equal("keda-hpa-orders"))
+		})
+		client.EXPECT().Status().Return(statusWriter)
+		statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any())
+
+		err := reconciler.updateHPANameIfNeeded(context.Background(), logger, scaledObject, reconciler.getHPAName(scaledObject))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(scaledObject.Status.HpaName).To(Equal("orders-hpa"))
+
+		// nothing to do once the name is recorded
+		Expect(reconciler.updateHPANameIfNeeded(context.Background(), logger, scaledObject, "orders-hpa")).To(Succeed())
+	})
+
+	It("should delete the HPA with the unshortened legacy name of a long ScaledObject name", func() {
+		name := strings.Repeat("orders-", 10) + "consumer"
+		scaledObject := &v1alpha1.ScaledObject{ObjectMeta: v1.ObjectMeta{Name: name, Namespace: "shop", UID: "so-uid"}}
+		legacyName := "keda-hpa-" + name
+		controller := true
+		previousHpa := v2beta2.HorizontalPodAutoscaler{ObjectMeta: v1.ObjectMeta{
+			Name:            legacyName,
+			Namespace:       "shop",
+			OwnerReferences: []v1.OwnerReference{{UID: "so-uid", Controller: &controller}},
+		}}
+
+		hpaName := reconciler.getHPAName(scaledObject)
+		Expect(hpaName).ToNot(Equal(legacyName))
+		client.EXPECT().Get(gomock.Any(), types.NamespacedName{Name: legacyName, Namespace: "shop"}, gomock.Any()).SetArg(2, previousHpa)
+		client.EXPECT().Delete(gomock.Any(), gomock.Any()).Do(func(_ interface{}, hpa *v2beta2.HorizontalPodAutoscaler, _ ...interface{}) {
+			Expect(hpa.Name).To(Equal(legacyName))
+		})
+		client.EXPECT().Status().Return(statusWriter)
+		statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any())
+
+		err := reconciler.updateHPANameIfNeeded(context.Background(), logger, scaledObject, hpaName)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(scaledObject.Status.HpaName).To(Equal(hpaName))
+	})
 })
 
 func setupTest(health map[string]v1alpha1.HealthStatus, scaler *mock_scalers.MockScaler, scaleHandler *mock_scaling.MockScaleHandler) *v1alpha1.ScaledObject {
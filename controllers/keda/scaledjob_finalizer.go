@@ -20,7 +20,11 @@ import (
 	"context"
 
 	"github.com/go-logr/logr"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	"github.com/kedacore/keda/v2/controllers/keda/util"
@@ -41,6 +45,11 @@ func (r *ScaledJobReconciler) finalizeScaledJob(ctx context.Context, logger logr
 			return err
 		}
 
+		// Adopt the orphan jobs, so that they are garbage collected together with the ScaledJob
+		if err := r.adoptOrphanJobs(ctx, logger, scaledJob); err != nil {
+			return err
+		}
+
 		// Remove scaledJobFinalizer. Once all finalizers have been
 		// removed, the object will be deleted.
 		scaledJob.SetFinalizers(util.Remove(scaledJob.GetFinalizers(), scaledJobFinalizer))
@@ -70,3 +79,34 @@ func (r *ScaledJobReconciler) ensureFinalizer(ctx context.Context, logger logr.L
 	}
 	return nil
 }
+
+// adoptOrphanJobs sets the ScaledJob as the controller of the jobs labeled with its name that don't have a controller,
+// eg. jobs created while setting the owner reference failed or jobs orphaned when the ScaledJob was recreated
+func (r *ScaledJobReconciler) adoptOrphanJobs(ctx context.Context, logger logr.Logger, scaledJob *kedav1alpha1.ScaledJob) error {
+	opts := []client.ListOption{
+		client.InNamespace(scaledJob.GetNamespace()),
+		client.MatchingLabels(map[string]string{"scaledjob.keda.sh/name": scaledJob.GetName()}),
+	}
+	jobs := &batchv1.JobList{}
+	if err := r.Client.List(ctx, jobs, opts...); err != nil {
+		logger.Error(err, "Failed to list Jobs to adopt")
+		return err
+	}
+
+	for _, job := range jobs.Items {
+		job := job
+		if metav1.GetControllerOf(&job) != nil || job.GetDeletionTimestamp() != nil {
+			continue
+		}
+		if err := controllerutil.SetControllerReference(scaledJob, &job, r.Scheme); err != nil {
+			logger.Error(err, "Failed to set ScaledJob as the owner of the orphan Job", "job.Name", job.Name)
+			return err
+		}
+		if err := r.Client.Update(ctx, &job); err != nil {
+			logger.Error(err, "Failed to adopt orphan Job", "job.Name", job.Name)
+			return err
+		}
+		logger.Info("Adopted orphan Job", "job.Name", job.Name)
+	}
+	return nil
+}
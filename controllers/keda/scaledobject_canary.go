@@ -0,0 +1,88 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keda
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	kedacontrollerutil "github.com/kedacore/keda/v2/controllers/keda/util"
+	"github.com/kedacore/keda/v2/pkg/eventreason"
+)
+
+// ensureCanaryStatus keeps the applied triggers of a ScaledObject with changePolicy canary in its status and starts
+// the evaluation of changed triggers, the scale loop evaluates them and applies them after the canary polls
+func (r *ScaledObjectReconciler) ensureCanaryStatus(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject) error {
+	canary := nextCanaryStatus(scaledObject)
+	if equality.Semantic.DeepEqual(canary, scaledObject.Status.Canary) {
+		return nil
+	}
+
+	if canary.IsEvaluating() && (!scaledObject.Status.Canary.IsEvaluating() || canary.CandidateGeneration != scaledObject.Status.Canary.CandidateGeneration) {
+		logger.Info("Evaluating changed triggers before they take effect", "polls", canary.RemainingPolls)
+		r.Recorder.Eventf(scaledObject, corev1.EventTypeNormal, eventreason.KEDACanaryStarted, "Evaluating changed triggers for %d polls before they take effect", canary.RemainingPolls)
+	}
+
+	status := scaledObject.Status.DeepCopy()
+	status.Canary = canary
+	return kedacontrollerutil.UpdateScaledObjectStatus(ctx, r.Client, logger, scaledObject, status)
+}
+
+// nextCanaryStatus returns the canary status of the ScaledObject for its current spec: the triggers are applied
+// right away while they are unchanged and changed triggers start an evaluation, a change of the ScaledObject
+// during an evaluation restarts it
+func nextCanaryStatus(scaledObject *kedav1alpha1.ScaledObject) *kedav1alpha1.CanaryStatus {
+	advanced := scaledObject.Spec.Advanced
+	if advanced == nil || advanced.ChangePolicy != kedav1alpha1.ChangePolicyCanary {
+		return nil
+	}
+
+	current := scaledObject.Status.Canary
+	if current == nil {
+		return &kedav1alpha1.CanaryStatus{
+			AppliedTriggers:   copyTriggers(scaledObject.Spec.Triggers),
+			AppliedGeneration: scaledObject.Generation,
+		}
+	}
+
+	next := current.DeepCopy()
+	switch {
+	case equality.Semantic.DeepEqual(current.AppliedTriggers, scaledObject.Spec.Triggers):
+		// the triggers are unchanged or the change was reverted during the evaluation
+		next.AppliedGeneration = scaledObject.Generation
+		next.CandidateGeneration = 0
+		next.RemainingPolls = 0
+	case current.CandidateGeneration != scaledObject.Generation:
+		next.CandidateGeneration = scaledObject.Generation
+		next.RemainingPolls = advanced.GetCanaryPolls()
+		next.DivergentPolls = 0
+		next.LastDivergence = ""
+	}
+	return next
+}
+
+func copyTriggers(triggers []kedav1alpha1.ScaleTriggers) []kedav1alpha1.ScaleTriggers {
+	result := make([]kedav1alpha1.ScaleTriggers, len(triggers))
+	for i := range triggers {
+		triggers[i].DeepCopyInto(&result[i])
+	}
+	return result
+}
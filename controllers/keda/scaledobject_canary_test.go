@@ -0,0 +1,87 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keda
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+var _ = Describe("canary", func() {
+	var scaledObject *v1alpha1.ScaledObject
+
+	BeforeEach(func() {
+		scaledObject = &v1alpha1.ScaledObject{
+			ObjectMeta: v1.ObjectMeta{Name: "orders", Namespace: "test", Generation: 1},
+			Spec: v1alpha1.ScaledObjectSpec{
+				Advanced: &v1alpha1.AdvancedConfig{ChangePolicy: v1alpha1.ChangePolicyCanary},
+				Triggers: []v1alpha1.ScaleTriggers{{Type: "kafka", Metadata: map[string]string{"lagThreshold": "10"}}},
+			},
+		}
+	})
+
+	It("should not track triggers without changePolicy canary", func() {
+		scaledObject.Spec.Advanced.ChangePolicy = v1alpha1.ChangePolicyImmediate
+		scaledObject.Status.Canary = &v1alpha1.CanaryStatus{AppliedGeneration: 1}
+
+		Ω(nextCanaryStatus(scaledObject)).To(BeNil())
+	})
+
+	It("should apply the first triggers right away", func() {
+		canary := nextCanaryStatus(scaledObject)
+
+		Ω(canary.IsEvaluating()).To(BeFalse())
+		Ω(canary.AppliedGeneration).To(Equal(int64(1)))
+		Ω(canary.AppliedTriggers).To(Equal(scaledObject.Spec.Triggers))
+	})
+
+	It("should evaluate changed triggers", func() {
+		scaledObject.Status.Canary = nextCanaryStatus(scaledObject)
+		scaledObject.Generation = 2
+		scaledObject.Spec.Triggers = []v1alpha1.ScaleTriggers{{Type: "kafka", Metadata: map[string]string{"lagThreshold": "20"}}}
+
+		canary := nextCanaryStatus(scaledObject)
+		Ω(canary.IsEvaluating()).To(BeTrue())
+		Ω(canary.CandidateGeneration).To(Equal(int64(2)))
+		Ω(canary.RemainingPolls).To(Equal(int32(5)))
+		Ω(canary.AppliedGeneration).To(Equal(int64(1)))
+		Ω(canary.AppliedTriggers[0].Metadata["lagThreshold"]).To(Equal("10"))
+
+		// the evaluation goes on for the same generation
+		scaledObject.Status.Canary = canary
+		scaledObject.Status.Canary.RemainingPolls = 3
+		Ω(nextCanaryStatus(scaledObject).RemainingPolls).To(Equal(int32(3)))
+	})
+
+	It("should stop the evaluation when the change is reverted", func() {
+		scaledObject.Generation = 3
+		scaledObject.Status.Canary = &v1alpha1.CanaryStatus{
+			AppliedTriggers:     scaledObject.Spec.Triggers,
+			AppliedGeneration:   1,
+			CandidateGeneration: 2,
+			RemainingPolls:      4,
+		}
+
+		canary := nextCanaryStatus(scaledObject)
+		Ω(canary.IsEvaluating()).To(BeFalse())
+		Ω(canary.AppliedGeneration).To(Equal(int64(3)))
+		Ω(canary.RemainingPolls).To(Equal(int32(0)))
+	})
+})
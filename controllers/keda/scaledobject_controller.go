@@ -49,6 +49,8 @@ import (
 	kedacontrollerutil "github.com/kedacore/keda/v2/controllers/keda/util"
 	"github.com/kedacore/keda/v2/pkg/eventreason"
 	"github.com/kedacore/keda/v2/pkg/scaling"
+	scalingcache "github.com/kedacore/keda/v2/pkg/scaling/cache"
+	"github.com/kedacore/keda/v2/pkg/scaling/resolver"
 	kedautil "github.com/kedacore/keda/v2/pkg/util"
 )
 
@@ -60,6 +62,8 @@ import (
 // +kubebuilder:rbac:groups="*",resources="*",verbs=get
 // +kubebuilder:rbac:groups="apps",resources=deployments;statefulsets,verbs=list;watch
 // +kubebuilder:rbac:groups="coordination.k8s.io",resources=leases,verbs="*"
+// +kubebuilder:rbac:groups="",resources=serviceaccounts,resourceNames=keda-secret-resolver,verbs=impersonate
+// +kubebuilder:rbac:groups="",resources=serviceaccounts/token,verbs=create
 
 // ScaledObjectReconciler reconciles a ScaledObject object
 type ScaledObjectReconciler struct {
@@ -67,11 +71,15 @@ type ScaledObjectReconciler struct {
 	Scheme            *runtime.Scheme
 	GlobalHTTPTimeout time.Duration
 	Recorder          record.EventRecorder
+	// HPANameTemplate is the template of the names of the HPAs, {scaledobject} and {namespace} are replaced by
+	// the name and namespace of the ScaledObject, it defaults to kedautil.DefaultHPANameTemplate
+	HPANameTemplate string
 
 	scaleClient              scale.ScalesGetter
 	restMapper               meta.RESTMapper
 	scaledObjectsGenerations *sync.Map
 	scaleHandler             scaling.ScaleHandler
+	secretsClient            client.Client
 	kubeVersion              kedautil.K8sVersion
 }
 
@@ -113,7 +121,13 @@ func (r *ScaledObjectReconciler) SetupWithManager(mgr ctrl.Manager, options cont
 	// Init the rest of ScaledObjectReconciler
 	r.restMapper = mgr.GetRESTMapper()
 	r.scaledObjectsGenerations = &sync.Map{}
-	r.scaleHandler = scaling.NewScaleHandler(mgr.GetClient(), r.scaleClient, mgr.GetScheme(), r.GlobalHTTPTimeout, r.Recorder)
+	secretsClient, err := resolver.NewSecretsClient(mgr.GetConfig(), mgr.GetScheme(), mgr.GetClient())
+	if err != nil {
+		setupLog.Error(err, "Not able to create secrets client")
+		return err
+	}
+	r.secretsClient = secretsClient
+	r.scaleHandler = scaling.NewScaleHandler(secretsClient, r.scaleClient, mgr.GetScheme(), r.GlobalHTTPTimeout, r.Recorder)
 
 	// Start controller
 	return ctrl.NewControllerManagedBy(mgr).
@@ -121,7 +135,7 @@ func (r *ScaledObjectReconciler) SetupWithManager(mgr ctrl.Manager, options cont
 		// predicate.GenerationChangedPredicate{} ignore updates to ScaledObject Status
 		// (in this case metadata.Generation does not change)
 		// so reconcile loop is not started on Status updates
-		For(&kedav1alpha1.ScaledObject{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		For(&kedav1alpha1.ScaledObject{}, builder.WithPredicates(predicate.Or(predicate.GenerationChangedPredicate{}, explainAnnotationChangedPredicate))).
 		Owns(&autoscalingv2beta2.HorizontalPodAutoscaler{}).
 		Complete(r)
 }
@@ -196,6 +210,12 @@ func (r *ScaledObjectReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return ctrl.Result{}, err
 	}
 
+	// status updates don't trigger a reconcile, the HPA gets the metrics of the changed triggers
+	// on the first reconcile after the scale loop applied them
+	if scaledObject.Status.Canary.IsEvaluating() {
+		withTriggers := kedav1alpha1.WithTriggers{Spec: kedav1alpha1.WithTriggersSpec{PollingInterval: scaledObject.Spec.PollingInterval}}
+		return ctrl.Result{RequeueAfter: withTriggers.GetPollingInterval()}, err
+	}
 	return ctrl.Result{}, err
 }
 
@@ -224,6 +244,25 @@ func (r *ScaledObjectReconciler) reconcileScaledObject(ctx context.Context, logg
 		return "ScaledObject doesn't have correct Idle/Min/Max Replica Counts specification", err
 	}
 
+	err = scalingcache.ValidateActivationLogic(scaledObject)
+	if err != nil {
+		return "ScaledObject doesn't have correct activationLogic specification", err
+	}
+
+	err = scalingcache.ValidateResourceTriggers(scaledObject)
+	if err != nil {
+		return "ScaledObject doesn't have correct cpu/memory triggers specification", err
+	}
+	if msg := scalingcache.ResourceTriggersWarning(scaledObject); msg != "" {
+		logger.Info(msg)
+		r.Recorder.Event(scaledObject, corev1.EventTypeWarning, eventreason.ScaledObjectCheckWarning, msg)
+	}
+
+	// the applied triggers of a canary change stay in effect for the HPA and the scale loop until it is evaluated
+	if err := r.ensureCanaryStatus(ctx, logger, scaledObject); err != nil {
+		return "Failed to update the canary status of ScaledObject", err
+	}
+
 	// Create a new HPA or update existing one according to ScaledObject
 	newHPACreated, err := r.ensureHPAForScaledObjectExists(ctx, logger, scaledObject, &gvkr)
 	if err != nil {
@@ -247,6 +286,11 @@ func (r *ScaledObjectReconciler) reconcileScaledObject(ctx context.Context, logg
 		}
 		logger.Info("Initializing Scaling logic according to ScaledObject Specification")
 	}
+
+	// the explanation is a diagnostic, failing to write it doesn't affect scaling
+	if err := r.ensureExplanation(ctx, logger, scaledObject, &gvkr); err != nil {
+		logger.Error(err, "Failed to write the explanation of ScaledObject")
+	}
 	return "ScaledObject is defined correctly and is ready for scaling", nil
 }
 
@@ -351,7 +395,7 @@ func (r *ScaledObjectReconciler) checkReplicaCountBoundsAreValid(scaledObject *k
 
 // ensureHPAForScaledObjectExists ensures that in cluster exist up-to-date HPA for specified ScaledObject, returns true if a new HPA was created
 func (r *ScaledObjectReconciler) ensureHPAForScaledObjectExists(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, gvkr *kedav1alpha1.GroupVersionKindResource) (bool, error) {
-	hpaName := getHPAName(scaledObject)
+	hpaName := r.getHPAName(scaledObject)
 	foundHpa := &autoscalingv2beta2.HorizontalPodAutoscaler{}
 	// Check if HPA for this ScaledObject already exists
 	err := r.Client.Get(ctx, types.NamespacedName{Name: hpaName, Namespace: scaledObject.Namespace}, foundHpa)
@@ -365,6 +409,11 @@ func (r *ScaledObjectReconciler) ensureHPAForScaledObjectExists(ctx context.Cont
 		// check if scaledObject.spec.behavior was defined, because it is supported only on k8s >= 1.18
 		r.checkMinK8sVersionforHPABehavior(logger, scaledObject)
 
+		// the HPA might replace one with a previous name
+		if err := r.updateHPANameIfNeeded(ctx, logger, scaledObject, hpaName); err != nil {
+			return false, err
+		}
+
 		// new HPA created successfully -> notify Reconcile function so it could fire a new ScaleLoop
 		return true, nil
 	} else if err != nil {
@@ -379,6 +428,10 @@ func (r *ScaledObjectReconciler) ensureHPAForScaledObjectExists(ctx context.Cont
 		return false, err
 	}
 
+	if err := r.updateHPANameIfNeeded(ctx, logger, scaledObject, hpaName); err != nil {
+		return false, err
+	}
+
 	return false, nil
 }
 
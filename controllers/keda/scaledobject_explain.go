@@ -0,0 +1,170 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keda
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/yaml"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/scaling"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	// explanationConfigMapSuffix is appended to the name of a ScaledObject for the ConfigMap of its explanation
+	explanationConfigMapSuffix = "-keda-explain"
+	// ExplanationConfigMapKey is the key of the explanation in its ConfigMap
+	ExplanationConfigMapKey = "explanation.yaml"
+)
+
+// ScaledObjectExplanation is what KEDA does for a ScaledObject: the resolved configuration and metric specs
+// of its triggers and the spec of the HPA it manages
+type ScaledObjectExplanation struct {
+	Namespace  string      `json:"namespace"`
+	Name       string      `json:"name"`
+	Generation int64       `json:"generation"`
+	Time       metav1.Time `json:"time"`
+
+	Triggers []scaling.TriggerExplanation `json:"triggers"`
+
+	HPAName string                                          `json:"hpaName"`
+	HPA     *autoscalingv2beta2.HorizontalPodAutoscalerSpec `json:"hpa,omitempty"`
+	// Error is why the HPA can't be built from the triggers
+	Error string `json:"error,omitempty"`
+}
+
+// ExplainScaledObject returns the explanation of the ScaledObject as the operator with the given HPA name template
+// on the given Kubernetes version would build it, secrets are redacted
+func ExplainScaledObject(ctx context.Context, client client.Client, scheme *runtime.Scheme, kubeVersion kedautil.K8sVersion, hpaNameTemplate string, globalHTTPTimeout time.Duration, scaledObject *kedav1alpha1.ScaledObject) (*ScaledObjectExplanation, error) {
+	gvkr := scaledObject.Status.ScaleTargetGVKR
+	if gvkr == nil {
+		parsed, err := kedautil.ParseGVKR(client.RESTMapper(), scaledObject.Spec.ScaleTargetRef.APIVersion, scaledObject.Spec.ScaleTargetRef.Kind)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing the scale target: %s", err)
+		}
+		gvkr = &parsed
+	}
+
+	r := &ScaledObjectReconciler{
+		Client:            client,
+		Scheme:            scheme,
+		GlobalHTTPTimeout: globalHTTPTimeout,
+		HPANameTemplate:   hpaNameTemplate,
+		secretsClient:     client,
+		kubeVersion:       kubeVersion,
+	}
+	return r.explainScaledObject(ctx, scaledObject, gvkr)
+}
+
+func (r *ScaledObjectReconciler) explainScaledObject(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject, gvkr *kedav1alpha1.GroupVersionKindResource) (*ScaledObjectExplanation, error) {
+	triggers, err := scaling.ExplainTriggers(ctx, r.secretsClient, scaledObject, r.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	explanation := &ScaledObjectExplanation{
+		Namespace:  scaledObject.Namespace,
+		Name:       scaledObject.Name,
+		Generation: scaledObject.Generation,
+		Time:       metav1.Now(),
+		Triggers:   triggers,
+		HPAName:    r.getHPAName(scaledObject),
+	}
+
+	// the HPA isn't updated by the operator while a trigger fails, the explanation doesn't guess it either
+	for _, trigger := range triggers {
+		if trigger.Error != "" {
+			explanation.Error = fmt.Sprintf("trigger %d (%s) failed", trigger.Index, trigger.Type)
+			return explanation, nil
+		}
+	}
+
+	metricSpecs, _, _, err := toHPAMetricSpecs(scaledObject, scaling.ExplainedMetricSpecs(triggers))
+	if err != nil {
+		explanation.Error = err.Error()
+		return explanation, nil
+	}
+	hpa, err := r.newHPAWithMetricSpecs(scaledObject, gvkr, metricSpecs)
+	if err != nil {
+		explanation.Error = err.Error()
+		return explanation, nil
+	}
+	explanation.HPA = &hpa.Spec
+	return explanation, nil
+}
+
+// ensureExplanation writes the explanation of a ScaledObject with the explain annotation to its ConfigMap,
+// the ConfigMap is owned by the ScaledObject
+func (r *ScaledObjectReconciler) ensureExplanation(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject, gvkr *kedav1alpha1.GroupVersionKindResource) error {
+	if _, ok := scaledObject.Annotations[kedav1alpha1.ExplainAnnotation]; !ok {
+		return nil
+	}
+
+	explanation, err := r.explainScaledObject(ctx, scaledObject, gvkr)
+	if err != nil {
+		return err
+	}
+	data, err := yaml.Marshal(explanation)
+	if err != nil {
+		return err
+	}
+
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      scaledObject.Name + explanationConfigMapSuffix,
+			Namespace: scaledObject.Namespace,
+		},
+	}
+	result, err := controllerutil.CreateOrUpdate(ctx, r.Client, configMap, func() error {
+		if configMap.Labels == nil {
+			configMap.Labels = map[string]string{}
+		}
+		configMap.Labels["app.kubernetes.io/managed-by"] = "keda-operator"
+		configMap.Labels["scaledobject.keda.sh/name"] = scaledObject.Name
+		configMap.Data = map[string]string{ExplanationConfigMapKey: string(data)}
+		return controllerutil.SetControllerReference(scaledObject, configMap, r.Scheme)
+	})
+	if err != nil {
+		return err
+	}
+	logger.V(1).Info("Wrote the explanation of ScaledObject", "ConfigMap.Name", configMap.Name, "result", result)
+	return nil
+}
+
+// explainAnnotationChangedPredicate reconciles ScaledObjects when their explain annotation is added or its value changed
+var explainAnnotationChangedPredicate = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		if e.ObjectOld == nil || e.ObjectNew == nil {
+			return false
+		}
+		newValue, ok := e.ObjectNew.GetAnnotations()[kedav1alpha1.ExplainAnnotation]
+		return ok && newValue != e.ObjectOld.GetAnnotations()[kedav1alpha1.ExplainAnnotation]
+	},
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keda
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+// +kubebuilder:rbac:groups=keda.sh,resources=clusterscaledobjectpolicies,verbs=get;list;watch
+// +kubebuilder:webhook:path=/mutate-keda-sh-v1alpha1-scaledobject,mutating=true,failurePolicy=ignore,sideEffects=None,groups=keda.sh,resources=scaledobjects,verbs=create;update,versions=v1alpha1,name=mscaledobject.keda.sh,admissionReviewVersions=v1
+
+// ScaledObjectDefaulter injects the defaults of the ClusterScaledObjectPolicies into ScaledObjects
+type ScaledObjectDefaulter struct {
+	Client client.Client
+}
+
+// SetupWebhookWithManager registers the mutating webhook of ScaledObjects
+func (d *ScaledObjectDefaulter) SetupWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).
+		For(&kedav1alpha1.ScaledObject{}).
+		WithDefaulter(d).
+		Complete()
+}
+
+// Default sets the fields of the ScaledObject it doesn't set from the ClusterScaledObjectPolicies
+func (d *ScaledObjectDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	scaledObject, ok := obj.(*kedav1alpha1.ScaledObject)
+	if !ok {
+		return fmt.Errorf("expected a ScaledObject but got %T", obj)
+	}
+
+	policies := &kedav1alpha1.ClusterScaledObjectPolicyList{}
+	if err := d.Client.List(ctx, policies); err != nil {
+		return fmt.Errorf("error listing ClusterScaledObjectPolicies: %s", err)
+	}
+
+	applied := applyScaledObjectDefaults(scaledObject, policies.Items)
+	if len(applied) > 0 {
+		logf.FromContext(ctx).V(1).Info("Injected defaults into ScaledObject", "scaledObject.Namespace", scaledObject.Namespace,
+			"scaledObject.Name", scaledObject.Name, "policies", applied)
+		if scaledObject.Annotations == nil {
+			scaledObject.Annotations = map[string]string{}
+		}
+		scaledObject.Annotations[kedav1alpha1.DefaultsPolicyAnnotation] = strings.Join(applied, ",")
+	}
+	return nil
+}
+
+// applyScaledObjectDefaults sets the unset fields of the ScaledObject from the policies in the order of
+// their names and returns the names of the policies that set at least one field
+func applyScaledObjectDefaults(scaledObject *kedav1alpha1.ScaledObject, policies []kedav1alpha1.ClusterScaledObjectPolicy) []string {
+	sort.Slice(policies, func(i, j int) bool {
+		return policies[i].Name < policies[j].Name
+	})
+
+	var applied []string
+	for _, policy := range policies {
+		defaults := policy.Spec.Defaults
+		spec := &scaledObject.Spec
+		changed := false
+
+		if spec.PollingInterval == nil && defaults.PollingInterval != nil {
+			pollingInterval := *defaults.PollingInterval
+			spec.PollingInterval = &pollingInterval
+			changed = true
+		}
+		if spec.CooldownPeriod == nil && defaults.CooldownPeriod != nil {
+			cooldownPeriod := *defaults.CooldownPeriod
+			spec.CooldownPeriod = &cooldownPeriod
+			changed = true
+		}
+		if spec.Fallback == nil && defaults.Fallback != nil {
+			spec.Fallback = defaults.Fallback.DeepCopy()
+			changed = true
+		}
+		if defaults.Behavior != nil && (spec.Advanced == nil || spec.Advanced.HorizontalPodAutoscalerConfig == nil ||
+			spec.Advanced.HorizontalPodAutoscalerConfig.Behavior == nil) {
+			if spec.Advanced == nil {
+				spec.Advanced = &kedav1alpha1.AdvancedConfig{}
+			}
+			if spec.Advanced.HorizontalPodAutoscalerConfig == nil {
+				spec.Advanced.HorizontalPodAutoscalerConfig = &kedav1alpha1.HorizontalPodAutoscalerConfig{}
+			}
+			spec.Advanced.HorizontalPodAutoscalerConfig.Behavior = defaults.Behavior.DeepCopy()
+			changed = true
+		}
+
+		if changed {
+			applied = append(applied, policy.Name)
+		}
+	}
+	return applied
+}
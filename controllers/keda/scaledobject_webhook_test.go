@@ -0,0 +1,104 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package keda
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/api/autoscaling/v2beta2"
+	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+var _ = Describe("ScaledObject defaults webhook", func() {
+	var (
+		scaledObject *v1alpha1.ScaledObject
+		stabilize    int32
+		behavior     *v2beta2.HorizontalPodAutoscalerBehavior
+	)
+
+	int32Ptr := func(i int32) *int32 {
+		return &i
+	}
+
+	BeforeEach(func() {
+		scaledObject = &v1alpha1.ScaledObject{
+			ObjectMeta: v1.ObjectMeta{Name: "orders", Namespace: "test"},
+			Spec: v1alpha1.ScaledObjectSpec{
+				Triggers: []v1alpha1.ScaleTriggers{{Type: "cpu", Metadata: map[string]string{"value": "50"}}},
+			},
+		}
+		stabilize = 600
+		behavior = &v2beta2.HorizontalPodAutoscalerBehavior{
+			ScaleDown: &v2beta2.HPAScalingRules{StabilizationWindowSeconds: &stabilize},
+		}
+	})
+
+	It("should inject the defaults that aren't set", func() {
+		scaledObject.Spec.CooldownPeriod = int32Ptr(60)
+		policies := []v1alpha1.ClusterScaledObjectPolicy{{
+			ObjectMeta: v1.ObjectMeta{Name: "org"},
+			Spec: v1alpha1.ClusterScaledObjectPolicySpec{Defaults: v1alpha1.ScaledObjectDefaults{
+				PollingInterval: int32Ptr(15),
+				CooldownPeriod:  int32Ptr(600),
+				Behavior:        behavior,
+				Fallback:        &v1alpha1.Fallback{FailureThreshold: 3, Replicas: 2},
+			}},
+		}}
+
+		Ω(applyScaledObjectDefaults(scaledObject, policies)).To(Equal([]string{"org"}))
+		Ω(*scaledObject.Spec.PollingInterval).To(Equal(int32(15)))
+		Ω(*scaledObject.Spec.CooldownPeriod).To(Equal(int32(60)))
+		Ω(scaledObject.Spec.Advanced.HorizontalPodAutoscalerConfig.Behavior).To(Equal(behavior))
+		Ω(*scaledObject.Spec.Fallback).To(Equal(v1alpha1.Fallback{FailureThreshold: 3, Replicas: 2}))
+	})
+
+	It("should keep the behavior of the ScaledObject", func() {
+		own := &v2beta2.HorizontalPodAutoscalerBehavior{}
+		scaledObject.Spec.Advanced = &v1alpha1.AdvancedConfig{
+			HorizontalPodAutoscalerConfig: &v1alpha1.HorizontalPodAutoscalerConfig{Behavior: own},
+		}
+		policies := []v1alpha1.ClusterScaledObjectPolicy{{
+			ObjectMeta: v1.ObjectMeta{Name: "org"},
+			Spec:       v1alpha1.ClusterScaledObjectPolicySpec{Defaults: v1alpha1.ScaledObjectDefaults{Behavior: behavior}},
+		}}
+
+		Ω(applyScaledObjectDefaults(scaledObject, policies)).To(BeEmpty())
+		Ω(scaledObject.Spec.Advanced.HorizontalPodAutoscalerConfig.Behavior).To(BeIdenticalTo(own))
+	})
+
+	It("should apply the policies in the order of their names", func() {
+		policies := []v1alpha1.ClusterScaledObjectPolicy{
+			{
+				ObjectMeta: v1.ObjectMeta{Name: "b-team"},
+				Spec: v1alpha1.ClusterScaledObjectPolicySpec{Defaults: v1alpha1.ScaledObjectDefaults{
+					PollingInterval: int32Ptr(60),
+					CooldownPeriod:  int32Ptr(120),
+				}},
+			},
+			{
+				ObjectMeta: v1.ObjectMeta{Name: "a-org"},
+				Spec:       v1alpha1.ClusterScaledObjectPolicySpec{Defaults: v1alpha1.ScaledObjectDefaults{PollingInterval: int32Ptr(15)}},
+			},
+		}
+
+		Ω(applyScaledObjectDefaults(scaledObject, policies)).To(Equal([]string{"a-org", "b-team"}))
+		Ω(*scaledObject.Spec.PollingInterval).To(Equal(int32(15)))
+		Ω(*scaledObject.Spec.CooldownPeriod).To(Equal(int32(120)))
+	})
+})
@@ -17,23 +17,30 @@ limitations under the License.
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
 	"runtime"
+	"strings"
 	"time"
 
 	apimachineryruntime "k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/discovery"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/yaml"
 
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	kedacontrollers "github.com/kedacore/keda/v2/controllers/keda"
+	"github.com/kedacore/keda/v2/pkg/scaling"
 	kedautil "github.com/kedacore/keda/v2/pkg/util"
 	"github.com/kedacore/keda/v2/version"
 	//+kubebuilder:scaffold:imports
@@ -61,15 +68,187 @@ func getWatchNamespace() (string, error) {
 	return ns, nil
 }
 
+// checkTriggers builds the scalers of a ScaledObject or ScaledJob, calls each of them once and prints
+// their activity and metric values, it exits with 1 if any of the triggers failed.
+// It is run as `keda-operator check-triggers --scaledobject namespace/name`
+func checkTriggers(args []string) {
+	var scaledObjectName, scaledJobName string
+	flags := flag.NewFlagSet("check-triggers", flag.ExitOnError)
+	flags.StringVar(&scaledObjectName, "scaledobject", "", "The namespace/name of the ScaledObject to check.")
+	flags.StringVar(&scaledJobName, "scaledjob", "", "The namespace/name of the ScaledJob to check.")
+	opts := zap.Options{}
+	opts.BindFlags(flags)
+	_ = flags.Parse(args)
+
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+
+	var scalableObject client.Object
+	name := scaledObjectName
+	switch {
+	case scaledObjectName != "" && scaledJobName != "":
+		setupLog.Error(fmt.Errorf("either --scaledobject or --scaledjob can be given"), "invalid arguments")
+		os.Exit(1)
+	case scaledObjectName != "":
+		scalableObject = &kedav1alpha1.ScaledObject{}
+	case scaledJobName != "":
+		scalableObject = &kedav1alpha1.ScaledJob{}
+		name = scaledJobName
+	default:
+		setupLog.Error(fmt.Errorf("no --scaledobject or --scaledjob given"), "invalid arguments")
+		os.Exit(1)
+	}
+	key := strings.SplitN(name, "/", 2)
+	if len(key) != 2 || key[0] == "" || key[1] == "" {
+		setupLog.Error(fmt.Errorf("%s isn't namespace/name", name), "invalid arguments")
+		os.Exit(1)
+	}
+
+	globalHTTPTimeoutMS, err := kedautil.ResolveOsEnvInt("KEDA_HTTP_DEFAULT_TIMEOUT", 3000)
+	if err != nil {
+		setupLog.Error(err, "Invalid KEDA_HTTP_DEFAULT_TIMEOUT")
+		os.Exit(1)
+	}
+
+	kubeClient, err := client.New(ctrl.GetConfigOrDie(), client.Options{Scheme: scheme})
+	if err != nil {
+		setupLog.Error(err, "unable to create client")
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	if err := kubeClient.Get(ctx, client.ObjectKey{Namespace: key[0], Name: key[1]}, scalableObject); err != nil {
+		setupLog.Error(err, "unable to get the scalable object", "name", name)
+		os.Exit(1)
+	}
+
+	checks, err := scaling.CheckTriggers(ctx, kubeClient, scalableObject, time.Duration(globalHTTPTimeoutMS)*time.Millisecond)
+	if err != nil {
+		setupLog.Error(err, "unable to check the triggers", "name", name)
+		os.Exit(1)
+	}
+
+	failed := false
+	for _, check := range checks {
+		trigger := fmt.Sprintf("trigger %d (%s)", check.Index, check.Type)
+		if check.Name != "" {
+			trigger = fmt.Sprintf("trigger %d %s (%s)", check.Index, check.Name, check.Type)
+		}
+		if check.Err != nil {
+			failed = true
+			fmt.Printf("%s: error: %s\n", trigger, check.Err)
+			continue
+		}
+		fmt.Printf("%s: active: %t\n", trigger, check.Active)
+		for _, metric := range check.Metrics {
+			fmt.Printf("  %s: %s\n", metric.MetricName, metric.Value.String())
+		}
+	}
+	if failed {
+		os.Exit(1)
+	}
+}
+
+// explain prints the resolved configuration of the triggers of a ScaledObject, with secrets redacted, and the
+// spec of the HPA the operator builds for it.
+// It is run as `keda-operator explain --scaledobject namespace/name`
+func explain(args []string) {
+	var scaledObjectName, output string
+	flags := flag.NewFlagSet("explain", flag.ExitOnError)
+	flags.StringVar(&scaledObjectName, "scaledobject", "", "The namespace/name of the ScaledObject to explain.")
+	flags.StringVar(&output, "output", "yaml", "The output format, yaml or json.")
+	opts := zap.Options{}
+	opts.BindFlags(flags)
+	_ = flags.Parse(args)
+
+	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
+
+	if output != "yaml" && output != "json" {
+		setupLog.Error(fmt.Errorf("unknown output %s", output), "invalid arguments")
+		os.Exit(1)
+	}
+	key := strings.SplitN(scaledObjectName, "/", 2)
+	if len(key) != 2 || key[0] == "" || key[1] == "" {
+		setupLog.Error(fmt.Errorf("--scaledobject %q isn't namespace/name", scaledObjectName), "invalid arguments")
+		os.Exit(1)
+	}
+
+	globalHTTPTimeoutMS, err := kedautil.ResolveOsEnvInt("KEDA_HTTP_DEFAULT_TIMEOUT", 3000)
+	if err != nil {
+		setupLog.Error(err, "Invalid KEDA_HTTP_DEFAULT_TIMEOUT")
+		os.Exit(1)
+	}
+	hpaNameTemplate, err := kedautil.ResolveHPANameTemplate()
+	if err != nil {
+		setupLog.Error(err, "Invalid KEDA_HPA_NAME_TEMPLATE")
+		os.Exit(1)
+	}
+
+	cfg := ctrl.GetConfigOrDie()
+	kubeClient, err := client.New(cfg, client.Options{Scheme: scheme})
+	if err != nil {
+		setupLog.Error(err, "unable to create client")
+		os.Exit(1)
+	}
+	clientset, err := discovery.NewDiscoveryClientForConfig(cfg)
+	if err != nil {
+		setupLog.Error(err, "unable to create discovery client")
+		os.Exit(1)
+	}
+	serverVersion, err := clientset.ServerVersion()
+	if err != nil {
+		setupLog.Error(err, "unable to get Kubernetes version")
+		os.Exit(1)
+	}
+
+	ctx := context.Background()
+	scaledObject := &kedav1alpha1.ScaledObject{}
+	if err := kubeClient.Get(ctx, client.ObjectKey{Namespace: key[0], Name: key[1]}, scaledObject); err != nil {
+		setupLog.Error(err, "unable to get the ScaledObject", "name", scaledObjectName)
+		os.Exit(1)
+	}
+
+	explanation, err := kedacontrollers.ExplainScaledObject(ctx, kubeClient, scheme, kedautil.NewK8sVersion(serverVersion), hpaNameTemplate, time.Duration(globalHTTPTimeoutMS)*time.Millisecond, scaledObject)
+	if err != nil {
+		setupLog.Error(err, "unable to explain the ScaledObject", "name", scaledObjectName)
+		os.Exit(1)
+	}
+
+	var out []byte
+	if output == "json" {
+		out, err = json.MarshalIndent(explanation, "", "  ")
+		out = append(out, '\n')
+	} else {
+		out, err = yaml.Marshal(explanation)
+	}
+	if err != nil {
+		setupLog.Error(err, "unable to encode the explanation")
+		os.Exit(1)
+	}
+	fmt.Print(string(out))
+}
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "check-triggers" {
+		checkTriggers(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "explain" {
+		explain(os.Args[2:])
+		return
+	}
+
 	var metricsAddr string
 	var enableLeaderElection bool
 	var probeAddr string
+	var enableWebhooks bool
 	flag.StringVar(&metricsAddr, "metrics-bind-address", ":8080", "The address the metric endpoint binds to.")
 	flag.StringVar(&probeAddr, "health-probe-bind-address", ":8081", "The address the probe endpoint binds to.")
 	flag.BoolVar(&enableLeaderElection, "leader-elect", false,
 		"Enable leader election for controller manager. "+
 			"Enabling this will ensure there is only one active controller manager.")
+	flag.BoolVar(&enableWebhooks, "enable-webhooks", false,
+		"Enable the mutating webhook that injects the defaults of ClusterScaledObjectPolicies into ScaledObjects. "+
+			"The serving certificate is read from the webhook cert dir of the manager.")
 	opts := zap.Options{}
 	opts.BindFlags(flag.CommandLine)
 
@@ -116,6 +295,12 @@ func main() {
 		os.Exit(1)
 	}
 
+	hpaNameTemplate, err := kedautil.ResolveHPANameTemplate()
+	if err != nil {
+		setupLog.Error(err, "Invalid KEDA_HPA_NAME_TEMPLATE")
+		os.Exit(1)
+	}
+
 	globalHTTPTimeout := time.Duration(globalHTTPTimeoutMS) * time.Millisecond
 	eventRecorder := mgr.GetEventRecorderFor("keda-operator")
 
@@ -124,6 +309,7 @@ func main() {
 		Scheme:            mgr.GetScheme(),
 		GlobalHTTPTimeout: globalHTTPTimeout,
 		Recorder:          eventRecorder,
+		HPANameTemplate:   hpaNameTemplate,
 	}).SetupWithManager(mgr, controller.Options{MaxConcurrentReconciles: scaledObjectMaxReconciles}); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "ScaledObject")
 		os.Exit(1)
@@ -153,6 +339,14 @@ func main() {
 		setupLog.Error(err, "unable to create controller", "controller", "ClusterTriggerAuthentication")
 		os.Exit(1)
 	}
+	if enableWebhooks {
+		if err = (&kedacontrollers.ScaledObjectDefaulter{
+			Client: mgr.GetClient(),
+		}).SetupWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "ScaledObject")
+			os.Exit(1)
+		}
+	}
 	//+kubebuilder:scaffold:builder
 
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
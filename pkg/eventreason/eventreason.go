@@ -26,6 +26,9 @@ const (
 	// ScaledObjectCheckFailed is for event when ScaledObject validation check fails
 	ScaledObjectCheckFailed = "ScaledObjectCheckFailed"
 
+	// ScaledObjectCheckWarning is for event when ScaledObject passes the validation check but may not scale as expected
+	ScaledObjectCheckWarning = "ScaledObjectCheckWarning"
+
 	// ScaledJobCheckFailed is for event when ScaledJob validation check fails
 	ScaledJobCheckFailed = "ScaledJobCheckFailed"
 
@@ -44,6 +47,15 @@ const (
 	// KEDAScalerFailed is for event when a scaler fails for a ScaledJob or a ScaledObject
 	KEDAScalerFailed = "KEDAScalerFailed"
 
+	// KEDAMetricValueClamped is for event when a metric value of a scaler is outside of the bounds of the trigger and was clamped
+	KEDAMetricValueClamped = "KEDAMetricValueClamped"
+
+	// KEDAMaxReplicaCountUpdated is for event when the maxReplicaCount of a ScaledObject was updated from maxReplicaCountFrom
+	KEDAMaxReplicaCountUpdated = "KEDAMaxReplicaCountUpdated"
+
+	// KEDAMaxReplicaCountFailed is for event when the maxReplicaCountFrom of a ScaledObject can't be read
+	KEDAMaxReplicaCountFailed = "KEDAMaxReplicaCountFailed"
+
 	// KEDAScaleTargetActivated is for event when the scale target of ScaledObject was activated
 	KEDAScaleTargetActivated = "KEDAScaleTargetActivated"
 
@@ -56,9 +68,27 @@ const (
 	// KEDAScaleTargetDeactivationFailed is for event when the deactivation of the scale target for ScaledObject fails
 	KEDAScaleTargetDeactivationFailed = "KEDAScaleTargetDeactivationFailed"
 
+	// KEDAScaleTargetDirectlyScaled is for event when the scale target of ScaledObject was scaled by KEDA because the HPA can't get metrics
+	KEDAScaleTargetDirectlyScaled = "KEDAScaleTargetDirectlyScaled"
+
+	// KEDAScaleTargetAtMaxReplicas is for event when the scale target of ScaledObject is pinned at maxReplicaCount with the metrics above target
+	KEDAScaleTargetAtMaxReplicas = "KEDAScaleTargetAtMaxReplicas"
+
+	// KEDACanaryStarted is for event when the evaluation of changed triggers of ScaledObject started
+	KEDACanaryStarted = "KEDACanaryStarted"
+
+	// KEDACanaryDiverged is for event when the changed triggers of ScaledObject led to another scaling decision
+	KEDACanaryDiverged = "KEDACanaryDiverged"
+
+	// KEDACanaryCompleted is for event when the changed triggers of ScaledObject took effect
+	KEDACanaryCompleted = "KEDACanaryCompleted"
+
 	// KEDAJobsCreated is for event when jobs for ScaledJob are created
 	KEDAJobsCreated = "KEDAJobsCreated"
 
+	// KEDAJobParametersFailed is for event when the parameters of the jobs of a ScaledJob with jobParameters can't be read
+	KEDAJobParametersFailed = "KEDAJobParametersFailed"
+
 	// TriggerAuthenticationDeleted is for event when a TriggerAuthentication is deleted
 	TriggerAuthenticationDeleted = "TriggerAuthenticationDeleted"
 
@@ -0,0 +1,89 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// Operator metrics are registered in the controller-runtime registry, so they are served
+// by the KEDA Operator on its metrics-bind-address together with the controller metrics
+var (
+	scaledObjectLabels        = []string{"namespace", "scaledObject"}
+	scaleUpdateConflictsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "keda_operator",
+			Subsystem: "scale_target",
+			Name:      "update_conflicts_total",
+			Help:      "Total number of conflicts when updating the scale subresource of a ScaleTarget",
+		},
+		scaledObjectLabels,
+	)
+	scaleUpdateErrorsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "keda_operator",
+			Subsystem: "scale_target",
+			Name:      "update_errors_total",
+			Help:      "Total number of failed updates of the scale subresource of a ScaleTarget, after retries",
+		},
+		scaledObjectLabels,
+	)
+	scaleTargetAtMaxReplicas = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Namespace: "keda_operator",
+			Subsystem: "scale_target",
+			Name:      "at_max_replicas",
+			Help:      "1 if the ScaleTarget has been at maxReplicaCount with the metrics above target for the saturation window, 0 otherwise",
+		},
+		scaledObjectLabels,
+	)
+)
+
+func init() {
+	metrics.Registry.MustRegister(scaleUpdateConflictsTotal)
+	metrics.Registry.MustRegister(scaleUpdateErrorsTotal)
+	metrics.Registry.MustRegister(scaleTargetAtMaxReplicas)
+	metrics.Registry.MustRegister(scalerCallTimeoutsTotal)
+}
+
+// RecordScaleUpdateConflict counts a conflicting update of the ScaleTarget owned by the ScaledObject
+func RecordScaleUpdateConflict(namespace string, scaledObject string) {
+	scaleUpdateConflictsTotal.With(prometheus.Labels{"namespace": namespace, "scaledObject": scaledObject}).Inc()
+}
+
+// RecordScaleUpdateError counts an update of the ScaleTarget owned by the ScaledObject that failed after all retries
+func RecordScaleUpdateError(namespace string, scaledObject string) {
+	scaleUpdateErrorsTotal.With(prometheus.Labels{"namespace": namespace, "scaledObject": scaledObject}).Inc()
+}
+
+// RecordScaleTargetAtMaxReplicas records whether the ScaleTarget owned by the ScaledObject is saturated at maxReplicaCount
+func RecordScaleTargetAtMaxReplicas(namespace string, scaledObject string, atMaxReplicas bool) {
+	value := 0.0
+	if atMaxReplicas {
+		value = 1
+	}
+	scaleTargetAtMaxReplicas.With(prometheus.Labels{"namespace": namespace, "scaledObject": scaledObject}).Set(value)
+}
+
+// DeleteScaledObjectMetrics removes the metrics of a deleted ScaledObject, so they aren't served anymore
+func DeleteScaledObjectMetrics(namespace string, scaledObject string) {
+	labels := prometheus.Labels{"namespace": namespace, "scaledObject": scaledObject}
+	scaleUpdateConflictsTotal.Delete(labels)
+	scaleUpdateErrorsTotal.Delete(labels)
+	scaleTargetAtMaxReplicas.Delete(labels)
+}
@@ -63,6 +63,16 @@ var (
 		},
 		[]string{"namespace", "scaledObject"},
 	)
+	// scalerCallTimeoutsTotal is served by the Metrics Server and the Operator, both call the scalers
+	scalerCallTimeoutsTotal = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: "keda",
+			Subsystem: "scaler",
+			Name:      "call_timeouts_total",
+			Help:      "Total number of IsActive and GetMetrics calls of scalers that didn't return within the scaler call timeout",
+		},
+		[]string{"namespace", "scaledObject", "scaler", "scalerIndex", "call"},
+	)
 )
 
 // PrometheusMetricServer the type of MetricsServer
@@ -76,6 +86,7 @@ func init() {
 	registry.MustRegister(scalerMetricsValue)
 	registry.MustRegister(scalerErrors)
 	registry.MustRegister(scaledObjectErrors)
+	registry.MustRegister(scalerCallTimeoutsTotal)
 }
 
 // NewServer creates a new http serving instance of prometheus metrics
@@ -135,6 +146,11 @@ func (metricsServer PrometheusMetricServer) RecordScalerObjectError(namespace st
 	}
 }
 
+// RecordScalerCallTimeout counts a call of the scaler of the ScaledObject or ScaledJob that timed out
+func RecordScalerCallTimeout(namespace string, scalableObject string, scaler string, scalerIndex int, call string) {
+	scalerCallTimeoutsTotal.With(prometheus.Labels{"namespace": namespace, "scaledObject": scalableObject, "scaler": scaler, "scalerIndex": strconv.Itoa(scalerIndex), "call": call}).Inc()
+}
+
 func getLabels(namespace string, scaledObject string, scaler string, scalerIndex int, metric string) prometheus.Labels {
 	return prometheus.Labels{"namespace": namespace, "scaledObject": scaledObject, "scaler": scaler, "scalerIndex": strconv.Itoa(scalerIndex), "metric": metric}
 }
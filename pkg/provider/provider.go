@@ -46,6 +46,9 @@ type KedaProvider struct {
 	externalMetricsInfoLock *sync.RWMutex
 }
 
+// scaledObjectNameLabel is the label of the ScaledObject in the selectors of the external metrics of its HPA
+const scaledObjectNameLabel = "scaledobject.keda.sh/name"
+
 var (
 	logger        logr.Logger
 	metricsServer prommetrics.PrometheusMetricServer
@@ -75,7 +78,7 @@ func (p *KedaProvider) GetExternalMetric(ctx context.Context, namespace string,
 	//		metric name and namespace is used to lookup for the CRD which contains configuration
 	// 		if not found then ignored and label selector is parsed for all the metrics
 	logger.V(1).Info("KEDA Metrics Server received request for external metrics", "namespace", namespace, "metric name", info.Metric, "metricSelector", metricSelector.String())
-	selector, err := labels.ConvertSelectorToLabelsMap(metricSelector.String())
+	scaledObjectSelector, metricSelector, err := splitMetricSelector(metricSelector)
 	if err != nil {
 		logger.Error(err, "Error converting Selector to Labels Map")
 		return nil, err
@@ -85,13 +88,13 @@ func (p *KedaProvider) GetExternalMetric(ctx context.Context, namespace string,
 	scaledObjects := &kedav1alpha1.ScaledObjectList{}
 	opts := []client.ListOption{
 		client.InNamespace(namespace),
-		client.MatchingLabels(selector),
+		client.MatchingLabels(scaledObjectSelector),
 	}
 	err = p.client.List(ctx, scaledObjects, opts...)
 	if err != nil {
 		return nil, err
 	} else if len(scaledObjects.Items) != 1 {
-		return nil, fmt.Errorf("exactly one ScaledObject should match label %s", metricSelector.String())
+		return nil, fmt.Errorf("exactly one ScaledObject should match label %s", scaledObjectSelector.String())
 	}
 
 	scaledObject := &scaledObjects.Items[0]
@@ -143,6 +146,30 @@ func (p *KedaProvider) GetExternalMetric(ctx context.Context, namespace string,
 	}, nil
 }
 
+// splitMetricSelector returns the labels the ScaledObject of the metric is looked up by and the selector the
+// metric values are filtered with. The HPAs select the ScaledObject by its name label and the other requirements
+// filter the values by their labels, eg. trigger.keda.sh/name. Selectors without the name label select the
+// ScaledObject by all their labels and don't filter the values
+func splitMetricSelector(metricSelector labels.Selector) (labels.Set, labels.Selector, error) {
+	requirements, _ := metricSelector.Requirements()
+	valuesSelector := labels.NewSelector()
+	var scaledObjectName *labels.Requirement
+	for i := range requirements {
+		if requirements[i].Key() == scaledObjectNameLabel {
+			scaledObjectName = &requirements[i]
+		} else {
+			valuesSelector = valuesSelector.Add(requirements[i])
+		}
+	}
+
+	if scaledObjectName == nil {
+		scaledObjectSelector, err := labels.ConvertSelectorToLabelsMap(metricSelector.String())
+		return scaledObjectSelector, labels.Everything(), err
+	}
+	scaledObjectSelector, err := labels.ConvertSelectorToLabelsMap(labels.NewSelector().Add(*scaledObjectName).String())
+	return scaledObjectSelector, valuesSelector, err
+}
+
 // ListAllExternalMetrics returns the supported external metrics for this provider
 func (p *KedaProvider) ListAllExternalMetrics() []provider.ExternalMetricInfo {
 	logger.V(1).Info("KEDA Metrics Server received request for list of all provided external metrics names")
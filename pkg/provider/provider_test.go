@@ -0,0 +1,51 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+func TestSplitMetricSelector(t *testing.T) {
+	var testData = []struct {
+		selector             string
+		scaledObjectSelector labels.Set
+		valuesSelector       string
+	}{
+		// the selector of the HPAs
+		{"scaledobject.keda.sh/name=orders", labels.Set{"scaledobject.keda.sh/name": "orders"}, ""},
+		// the values are filtered by the other requirements
+		{"scaledobject.keda.sh/name=orders,trigger.keda.sh/name=backlog", labels.Set{"scaledobject.keda.sh/name": "orders"}, "trigger.keda.sh/name=backlog"},
+		{"scaledobject.keda.sh/name=orders,trigger.keda.sh/type in (kafka,rabbitmq)", labels.Set{"scaledobject.keda.sh/name": "orders"}, "trigger.keda.sh/type in (kafka,rabbitmq)"},
+		// without the name label the ScaledObject is selected by all the labels
+		{"app=orders,team=billing", labels.Set{"app": "orders", "team": "billing"}, ""},
+	}
+
+	for _, test := range testData {
+		selector, err := labels.Parse(test.selector)
+		assert.NoError(t, err)
+
+		scaledObjectSelector, valuesSelector, err := splitMetricSelector(selector)
+
+		assert.NoError(t, err, test.selector)
+		assert.Equal(t, test.scaledObjectSelector, scaledObjectSelector, test.selector)
+		assert.Equal(t, test.valuesSelector, valuesSelector.String(), test.selector)
+	}
+}
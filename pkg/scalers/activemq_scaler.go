@@ -0,0 +1,240 @@
+package scalers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	activeMQBrokerURL                 = "brokerURL"
+	activeMQBrokerName                = "brokerName"
+	activeMQDestinationName           = "destinationName"
+	activeMQUsername                  = "username"
+	activeMQPassword                  = "password"
+	activeMQCorsHeader                = "corsHeader"
+	activeMQTargetQueueSize           = "targetQueueSize"
+	activeMQActivationTargetQueueSize = "activationTargetQueueSize"
+
+	defaultActiveMQBrokerName      = "localhost"
+	defaultActiveMQTargetQueueSize = 10
+	// activeMQJolokiaPath is the path of the Jolokia agent of the web console of ActiveMQ Classic
+	activeMQJolokiaPath = "/api/jolokia"
+)
+
+type activeMQScaler struct {
+	metadata   *activeMQMetadata
+	httpClient *http.Client
+}
+
+type activeMQMetadata struct {
+	// brokerURL is the URL of the web console, eg. http://activemq:8161
+	brokerURL       string
+	brokerName      string
+	destinationName string
+	username        string
+	password        string
+	// corsHeader is sent as Origin, the Jolokia agent of ActiveMQ checks it against its allowed origins
+	corsHeader                string
+	targetQueueSize           int64
+	activationTargetQueueSize int64
+	scalerIndex               int
+}
+
+type activeMQJolokiaRequest struct {
+	Type      string `json:"type"`
+	MBean     string `json:"mbean"`
+	Attribute string `json:"attribute"`
+}
+
+type activeMQJolokiaResponse struct {
+	Value  int64  `json:"value"`
+	Status int    `json:"status"`
+	Error  string `json:"error"`
+}
+
+var activeMQLog = logf.Log.WithName("activemq_scaler")
+
+// NewActiveMQScaler creates a new activeMQScaler
+func NewActiveMQScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseActiveMQMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing activemq metadata: %s", err)
+	}
+
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &activeMQScaler{
+		metadata:   meta,
+		httpClient: httpClient,
+	}, nil
+}
+
+func parseActiveMQMetadata(config *ScalerConfig) (*activeMQMetadata, error) {
+	meta := activeMQMetadata{
+		brokerName:      defaultActiveMQBrokerName,
+		targetQueueSize: defaultActiveMQTargetQueueSize,
+	}
+
+	brokerURL, err := GetFromAuthOrMeta(config, activeMQBrokerURL)
+	if err != nil {
+		return nil, err
+	}
+	meta.brokerURL = strings.TrimSuffix(brokerURL, "/")
+
+	if val, ok := config.TriggerMetadata[activeMQBrokerName]; ok && val != "" {
+		meta.brokerName = val
+	}
+
+	if val, ok := config.TriggerMetadata[activeMQDestinationName]; ok && val != "" {
+		meta.destinationName = val
+	} else {
+		return nil, fmt.Errorf("no %s given", activeMQDestinationName)
+	}
+
+	meta.username = config.AuthParams[activeMQUsername]
+	meta.password = config.AuthParams[activeMQPassword]
+	if (meta.username == "") != (meta.password == "") {
+		return nil, fmt.Errorf("%s and %s must be given together", activeMQUsername, activeMQPassword)
+	}
+
+	if val, ok := config.TriggerMetadata[activeMQCorsHeader]; ok && val != "" {
+		meta.corsHeader = val
+	} else {
+		meta.corsHeader = meta.brokerURL
+	}
+
+	if val, ok := config.TriggerMetadata[activeMQTargetQueueSize]; ok && val != "" {
+		targetQueueSize, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", activeMQTargetQueueSize, err)
+		}
+		meta.targetQueueSize = targetQueueSize
+	}
+
+	if val, ok := config.TriggerMetadata[activeMQActivationTargetQueueSize]; ok && val != "" {
+		activationTargetQueueSize, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", activeMQActivationTargetQueueSize, err)
+		}
+		meta.activationTargetQueueSize = activationTargetQueueSize
+	}
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+// IsActive returns true if the queue size is above the activation target
+func (s *activeMQScaler) IsActive(ctx context.Context) (bool, error) {
+	queueSize, err := s.getQueueSize(ctx)
+	if err != nil {
+		activeMQLog.Error(err, "error getting activemq queue size", "brokerURL", s.metadata.brokerURL)
+		return false, err
+	}
+
+	return queueSize > s.metadata.activationTargetQueueSize, nil
+}
+
+func (s *activeMQScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *activeMQScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetMetricValue := resource.NewQuantity(s.metadata.targetQueueSize, resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(fmt.Sprintf("activemq-%s", s.metadata.destinationName))),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetMetricValue,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// getQueueSize reads the QueueSize attribute of the queue MBean through a Jolokia read request, the request
+// is posted so destination names don't need Jolokia path escaping
+func (s *activeMQScaler) getQueueSize(ctx context.Context) (int64, error) {
+	body, err := json.Marshal(activeMQJolokiaRequest{
+		Type:      "read",
+		MBean:     fmt.Sprintf("org.apache.activemq:type=Broker,brokerName=%s,destinationType=Queue,destinationName=%s", s.metadata.brokerName, s.metadata.destinationName),
+		Attribute: "QueueSize",
+	})
+	if err != nil {
+		return -1, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.metadata.brokerURL+activeMQJolokiaPath, bytes.NewReader(body))
+	if err != nil {
+		return -1, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Origin", s.metadata.corsHeader)
+	if s.metadata.username != "" {
+		req.SetBasicAuth(s.metadata.username, s.metadata.password)
+	}
+
+	r, err := s.httpClient.Do(req)
+	if err != nil {
+		return -1, err
+	}
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return -1, err
+	}
+	r.Body.Close()
+
+	if !(r.StatusCode >= 200 && r.StatusCode <= 299) {
+		return -1, fmt.Errorf("activemq jolokia api returned error. status: %d response: %s", r.StatusCode, string(b))
+	}
+
+	// Jolokia reports errors like unknown MBeans in the status of the response body
+	var result activeMQJolokiaResponse
+	if err := json.Unmarshal(b, &result); err != nil {
+		return -1, err
+	}
+	if result.Status != http.StatusOK {
+		return -1, fmt.Errorf("activemq jolokia api returned error. status: %d error: %s", result.Status, result.Error)
+	}
+
+	return result.Value, nil
+}
+
+func (s *activeMQScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	queueSize, err := s.getQueueSize(ctx)
+	if err != nil {
+		activeMQLog.Error(err, "error getting activemq queue size", "brokerURL", s.metadata.brokerURL)
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewQuantity(queueSize, resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
@@ -0,0 +1,148 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type parseActiveMQMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+}
+
+type activeMQMetricIdentifier struct {
+	metadataTestData *parseActiveMQMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testActiveMQMetadata = []parseActiveMQMetadataTestData{
+	// nothing passed
+	{map[string]string{}, map[string]string{}, true},
+	// properly formed
+	{map[string]string{"brokerURL": "http://activemq:8161", "destinationName": "orders"}, map[string]string{"username": "admin", "password": "admin"}, false},
+	// brokerURL from auth params, brokerName, corsHeader, targets and no credentials
+	{map[string]string{"brokerName": "broker-1", "destinationName": "orders.pending", "corsHeader": "http://keda", "targetQueueSize": "100", "activationTargetQueueSize": "5"}, map[string]string{"brokerURL": "http://activemq:8161/"}, false},
+	// missing brokerURL
+	{map[string]string{"destinationName": "orders"}, map[string]string{"username": "admin", "password": "admin"}, true},
+	// missing destinationName
+	{map[string]string{"brokerURL": "http://activemq:8161"}, map[string]string{"username": "admin", "password": "admin"}, true},
+	// username without password
+	{map[string]string{"brokerURL": "http://activemq:8161", "destinationName": "orders"}, map[string]string{"username": "admin"}, true},
+	// malformed targetQueueSize
+	{map[string]string{"brokerURL": "http://activemq:8161", "destinationName": "orders", "targetQueueSize": "a"}, map[string]string{}, true},
+	// malformed activationTargetQueueSize
+	{map[string]string{"brokerURL": "http://activemq:8161", "destinationName": "orders", "activationTargetQueueSize": "a"}, map[string]string{}, true},
+}
+
+var activeMQMetricIdentifiers = []activeMQMetricIdentifier{
+	{&testActiveMQMetadata[1], 0, "s0-activemq-orders"},
+	{&testActiveMQMetadata[2], 1, "s1-activemq-orders-pending"},
+}
+
+func TestActiveMQParseMetadata(t *testing.T) {
+	for _, testData := range testActiveMQMetadata {
+		_, err := parseActiveMQMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Error("Expected success but got error", err)
+		}
+		if testData.isError && err == nil {
+			t.Error("Expected error but got success")
+		}
+	}
+}
+
+func TestActiveMQParseDefaults(t *testing.T) {
+	meta, err := parseActiveMQMetadata(&ScalerConfig{TriggerMetadata: testActiveMQMetadata[1].metadata, AuthParams: testActiveMQMetadata[1].authParams})
+	if err != nil {
+		t.Fatal("Could not parse metadata:", err)
+	}
+	assert.Equal(t, "localhost", meta.brokerName)
+	assert.Equal(t, "http://activemq:8161", meta.corsHeader)
+	assert.Equal(t, int64(10), meta.targetQueueSize)
+	assert.Equal(t, int64(0), meta.activationTargetQueueSize)
+}
+
+func TestActiveMQGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range activeMQMetricIdentifiers {
+		meta, err := parseActiveMQMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, AuthParams: testData.metadataTestData.authParams, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockActiveMQScaler := activeMQScaler{
+			metadata:   meta,
+			httpClient: http.DefaultClient,
+		}
+
+		metricSpec := mockActiveMQScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Error("Wrong External metric source name:", metricName)
+		}
+	}
+}
+
+func TestActiveMQGetQueueSize(t *testing.T) {
+	var testData = []struct {
+		name           string
+		bodyStr        string
+		responseStatus int
+		expectedValue  int64
+		isError        bool
+	}{
+		{"queue size", `{"request":{"mbean":"org.apache.activemq:brokerName=localhost,destinationName=orders,destinationType=Queue,type=Broker","attribute":"QueueSize","type":"read"},"value":42,"timestamp":1,"status":200}`, http.StatusOK, 42, false},
+		{"unknown queue", `{"error_type":"javax.management.InstanceNotFoundException","error":"javax.management.InstanceNotFoundException : org.apache.activemq:brokerName=localhost,destinationName=orders,destinationType=Queue,type=Broker","status":404}`, http.StatusOK, -1, true},
+		{"error status response", `Unauthorized`, http.StatusUnauthorized, -1, true},
+	}
+
+	for _, test := range testData {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				assert.Equal(t, "POST", request.Method)
+				assert.Equal(t, "/api/jolokia", request.URL.Path)
+				assert.Equal(t, "http://keda", request.Header.Get("Origin"))
+				username, password, ok := request.BasicAuth()
+				assert.True(t, ok)
+				assert.Equal(t, "admin", username)
+				assert.Equal(t, "secret", password)
+
+				var body activeMQJolokiaRequest
+				assert.NoError(t, json.NewDecoder(request.Body).Decode(&body))
+				assert.Equal(t, "read", body.Type)
+				assert.Equal(t, "org.apache.activemq:type=Broker,brokerName=localhost,destinationType=Queue,destinationName=orders", body.MBean)
+				assert.Equal(t, "QueueSize", body.Attribute)
+				writer.WriteHeader(test.responseStatus)
+
+				if _, err := writer.Write([]byte(test.bodyStr)); err != nil {
+					t.Fatal(err)
+				}
+			}))
+			defer server.Close()
+
+			meta, err := parseActiveMQMetadata(&ScalerConfig{
+				TriggerMetadata: map[string]string{"brokerURL": server.URL, "destinationName": "orders", "corsHeader": "http://keda"},
+				AuthParams:      map[string]string{"username": "admin", "password": "secret"},
+			})
+			if err != nil {
+				t.Fatal("Could not parse metadata:", err)
+			}
+			scaler := activeMQScaler{metadata: meta, httpClient: http.DefaultClient}
+
+			value, err := scaler.getQueueSize(context.TODO())
+
+			assert.Equal(t, test.expectedValue, value)
+			if test.isError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
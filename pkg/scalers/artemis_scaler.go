@@ -61,7 +61,10 @@ func NewArtemisQueueScaler(config *ScalerConfig) (Scaler, error) {
 	// do we need to guarantee this timeout for a specific
 	// reason? if not, we can have buildScaler pass in
 	// the global client
-	httpClient := kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, false)
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
 
 	artemisMetadata, err := parseArtemisMetadata(config)
 	if err != nil {
@@ -0,0 +1,270 @@
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	artifactoryServiceXray        = "xray"
+	artifactoryServiceArtifactory = "artifactory"
+
+	// defaultArtifactoryXrayMetric is the Xray metric of the messages waiting in its queues, eg. the index queue of
+	// the artifacts to scan
+	defaultArtifactoryXrayMetric = "queue_messages_total"
+	artifactoryXrayQueueLabel    = "queue_name"
+)
+
+// artifactoryMetricsPaths maps the services to the paths of their open metrics endpoints
+var artifactoryMetricsPaths = map[string]string{
+	artifactoryServiceXray:        "/xray/api/v1/metrics",
+	artifactoryServiceArtifactory: "/artifactory/api/v1/metrics",
+}
+
+type artifactoryScaler struct {
+	metadata   *artifactoryMetadata
+	httpClient *http.Client
+}
+
+type artifactoryMetadata struct {
+	// url is the base URL of the JFrog Platform, eg. https://acme.jfrog.io
+	url     string
+	service string
+	metric  string
+	// queueName selects the Xray queue, the messages of all the queues are added up without it
+	queueName             string
+	targetValue           float64
+	activationTargetValue float64
+
+	// auth
+	accessToken string
+	username    string
+	password    string
+
+	scalerIndex int
+}
+
+var artifactoryLog = logf.Log.WithName("artifactory_scaler")
+
+// NewArtifactoryScaler creates a new artifactoryScaler
+func NewArtifactoryScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseArtifactoryMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing artifactory metadata: %s", err)
+	}
+
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &artifactoryScaler{
+		metadata:   meta,
+		httpClient: httpClient,
+	}, nil
+}
+
+func parseArtifactoryMetadata(config *ScalerConfig) (*artifactoryMetadata, error) {
+	meta := artifactoryMetadata{
+		service: artifactoryServiceXray,
+	}
+
+	url, err := GetFromAuthOrMeta(config, "url")
+	if err != nil {
+		return nil, err
+	}
+	meta.url = strings.TrimSuffix(url, "/")
+
+	if val, ok := config.TriggerMetadata["service"]; ok && val != "" {
+		if _, ok := artifactoryMetricsPaths[val]; !ok {
+			return nil, fmt.Errorf("service must be %s or %s, got %s", artifactoryServiceXray, artifactoryServiceArtifactory, val)
+		}
+		meta.service = val
+	}
+
+	// Artifactory has no default metric, eg. the replication queue metric of the deployment is given
+	if val, ok := config.TriggerMetadata["metric"]; ok && val != "" {
+		meta.metric = val
+	} else if meta.service == artifactoryServiceXray {
+		meta.metric = defaultArtifactoryXrayMetric
+	} else {
+		return nil, fmt.Errorf("no metric given")
+	}
+
+	meta.queueName = config.TriggerMetadata["queueName"]
+	if meta.queueName != "" && meta.service != artifactoryServiceXray {
+		return nil, fmt.Errorf("queueName can only be given for service %s", artifactoryServiceXray)
+	}
+
+	if val, ok := config.TriggerMetadata["targetValue"]; ok && val != "" {
+		targetValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing targetValue: %s", err)
+		}
+		meta.targetValue = targetValue
+	} else {
+		return nil, fmt.Errorf("no targetValue given")
+	}
+
+	if val, ok := config.TriggerMetadata["activationTargetValue"]; ok && val != "" {
+		activationTargetValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing activationTargetValue: %s", err)
+		}
+		meta.activationTargetValue = activationTargetValue
+	}
+
+	// the metrics endpoints need an admin access token or the credentials of an admin user
+	meta.accessToken = config.AuthParams["accessToken"]
+	meta.username = config.AuthParams["username"]
+	meta.password = config.AuthParams["password"]
+	switch {
+	case meta.accessToken != "" && (meta.username != "" || meta.password != ""):
+		return nil, fmt.Errorf("either accessToken or username and password can be given")
+	case meta.accessToken == "" && (meta.username == "" || meta.password == ""):
+		return nil, fmt.Errorf("no accessToken or username and password given")
+	}
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+// IsActive returns true if the value of the metric is above the activation target
+func (s *artifactoryScaler) IsActive(ctx context.Context) (bool, error) {
+	val, err := s.getValue(ctx)
+	if err != nil {
+		artifactoryLog.Error(err, "error getting artifactory metrics", "service", s.metadata.service)
+		return false, err
+	}
+
+	return val > s.metadata.activationTargetValue, nil
+}
+
+func (s *artifactoryScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *artifactoryScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	name := s.metadata.metric
+	if s.metadata.queueName != "" {
+		name = s.metadata.queueName
+	}
+	targetMetricValue := resource.NewMilliQuantity(int64(s.metadata.targetValue*1000), resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(fmt.Sprintf("%s-%s", s.metadata.service, name))),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetMetricValue,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+func (s *artifactoryScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	val, err := s.getValue(ctx)
+	if err != nil {
+		artifactoryLog.Error(err, "error getting artifactory metrics", "service", s.metadata.service)
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewMilliQuantity(int64(val*1000), resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
+
+// getValue scrapes the open metrics endpoint of the service and adds up the series of the metric
+func (s *artifactoryScaler) getValue(ctx context.Context) (float64, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", s.metadata.url+artifactoryMetricsPaths[s.metadata.service], nil)
+	if err != nil {
+		return -1, err
+	}
+	req.Header.Set("Accept", string(expfmt.FmtText))
+	if s.metadata.accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+s.metadata.accessToken)
+	} else {
+		req.SetBasicAuth(s.metadata.username, s.metadata.password)
+	}
+
+	r, err := s.httpClient.Do(req)
+	if err != nil {
+		return -1, err
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode != http.StatusOK {
+		return -1, fmt.Errorf("%s metrics endpoint returned %d", s.metadata.service, r.StatusCode)
+	}
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(r.Body)
+	if err != nil {
+		return -1, fmt.Errorf("error parsing %s metrics: %s", s.metadata.service, err)
+	}
+
+	family, ok := families[s.metadata.metric]
+	if !ok {
+		return -1, fmt.Errorf("metric %s not found in %s metrics", s.metadata.metric, s.metadata.service)
+	}
+
+	return sumArtifactoryMetric(family, s.metadata.queueName)
+}
+
+// sumArtifactoryMetric adds up the samples of the family, only the series of the queue are used when a queue
+// is given
+func sumArtifactoryMetric(family *dto.MetricFamily, queueName string) (float64, error) {
+	var sum float64
+	found := false
+	for _, metric := range family.GetMetric() {
+		if queueName != "" && artifactoryMetricLabel(metric, artifactoryXrayQueueLabel) != queueName {
+			continue
+		}
+		switch {
+		case metric.GetGauge() != nil:
+			sum += metric.GetGauge().GetValue()
+		case metric.GetCounter() != nil:
+			sum += metric.GetCounter().GetValue()
+		case metric.GetUntyped() != nil:
+			sum += metric.GetUntyped().GetValue()
+		default:
+			return -1, fmt.Errorf("metric %s is not a gauge or counter", family.GetName())
+		}
+		found = true
+	}
+	if !found {
+		return -1, fmt.Errorf("no series of metric %s found for queue %s", family.GetName(), queueName)
+	}
+	return sum, nil
+}
+
+func artifactoryMetricLabel(metric *dto.Metric, name string) string {
+	for _, label := range metric.GetLabel() {
+		if label.GetName() == name {
+			return label.GetValue()
+		}
+	}
+	return ""
+}
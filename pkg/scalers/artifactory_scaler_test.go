@@ -0,0 +1,138 @@
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type parseArtifactoryMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+}
+
+type artifactoryMetricIdentifier struct {
+	metadataTestData *parseArtifactoryMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testArtifactoryMetadata = []parseArtifactoryMetadataTestData{
+	// nothing passed
+	{map[string]string{}, map[string]string{}, true},
+	// xray index queue with access token
+	{map[string]string{"url": "https://acme.jfrog.io", "queueName": "index", "targetValue": "100"}, map[string]string{"accessToken": "token"}, false},
+	// artifactory metric with url from auth params, basic auth and activationTargetValue
+	{map[string]string{"service": "artifactory", "metric": "jfrt_replication_queue", "targetValue": "2.5", "activationTargetValue": "1"}, map[string]string{"url": "https://acme.jfrog.io/", "username": "admin", "password": "password"}, false},
+	// missing url
+	{map[string]string{"targetValue": "100"}, map[string]string{"accessToken": "token"}, true},
+	// unknown service
+	{map[string]string{"url": "https://acme.jfrog.io", "service": "distribution", "targetValue": "100"}, map[string]string{"accessToken": "token"}, true},
+	// artifactory without metric
+	{map[string]string{"url": "https://acme.jfrog.io", "service": "artifactory", "targetValue": "100"}, map[string]string{"accessToken": "token"}, true},
+	// queueName for artifactory
+	{map[string]string{"url": "https://acme.jfrog.io", "service": "artifactory", "metric": "jfrt_replication_queue", "queueName": "index", "targetValue": "100"}, map[string]string{"accessToken": "token"}, true},
+	// missing targetValue
+	{map[string]string{"url": "https://acme.jfrog.io"}, map[string]string{"accessToken": "token"}, true},
+	// malformed targetValue
+	{map[string]string{"url": "https://acme.jfrog.io", "targetValue": "a"}, map[string]string{"accessToken": "token"}, true},
+	// malformed activationTargetValue
+	{map[string]string{"url": "https://acme.jfrog.io", "targetValue": "100", "activationTargetValue": "a"}, map[string]string{"accessToken": "token"}, true},
+	// missing credentials
+	{map[string]string{"url": "https://acme.jfrog.io", "targetValue": "100"}, map[string]string{}, true},
+	// username without password
+	{map[string]string{"url": "https://acme.jfrog.io", "targetValue": "100"}, map[string]string{"username": "admin"}, true},
+	// access token and basic auth
+	{map[string]string{"url": "https://acme.jfrog.io", "targetValue": "100"}, map[string]string{"accessToken": "token", "username": "admin", "password": "password"}, true},
+}
+
+var artifactoryMetricIdentifiers = []artifactoryMetricIdentifier{
+	{&testArtifactoryMetadata[1], 0, "s0-xray-index"},
+	{&testArtifactoryMetadata[2], 1, "s1-artifactory-jfrt_replication_queue"},
+}
+
+func TestArtifactoryParseMetadata(t *testing.T) {
+	for _, testData := range testArtifactoryMetadata {
+		_, err := parseArtifactoryMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Error("Expected success but got error", err)
+		}
+		if testData.isError && err == nil {
+			t.Error("Expected error but got success")
+		}
+	}
+}
+
+func TestArtifactoryGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range artifactoryMetricIdentifiers {
+		meta, err := parseArtifactoryMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, AuthParams: testData.metadataTestData.authParams, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockArtifactoryScaler := artifactoryScaler{
+			metadata:   meta,
+			httpClient: http.DefaultClient,
+		}
+
+		metricSpec := mockArtifactoryScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Error("Wrong External metric source name:", metricName)
+		}
+	}
+}
+
+const testArtifactoryXrayMetrics = `# HELP queue_messages_total The number of messages in the queue
+# TYPE queue_messages_total gauge
+queue_messages_total{queue_name="index"} 12
+queue_messages_total{queue_name="persist"} 3
+queue_messages_total{queue_name="analysis"} 5
+`
+
+func TestArtifactoryGetValue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/xray/api/v1/metrics", r.URL.Path)
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, testArtifactoryXrayMetrics)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		metadata map[string]string
+		token    string
+		value    float64
+		isError  bool
+	}{
+		{map[string]string{"queueName": "index"}, "token", 12, false},
+		{map[string]string{}, "token", 20, false},
+		{map[string]string{"queueName": "alert"}, "token", 0, true},
+		{map[string]string{"metric": "jfxr_missing_metric"}, "token", 0, true},
+		{map[string]string{}, "wrong", 0, true},
+	}
+	for _, test := range tests {
+		test.metadata["url"] = server.URL
+		test.metadata["targetValue"] = "10"
+		scaler, err := NewArtifactoryScaler(&ScalerConfig{
+			TriggerMetadata: test.metadata,
+			AuthParams:      map[string]string{"accessToken": test.token},
+		})
+		if err != nil {
+			t.Fatal("Could not create scaler:", err)
+		}
+		value, err := scaler.(*artifactoryScaler).getValue(context.Background())
+		if test.isError {
+			assert.Error(t, err, test.metadata)
+			continue
+		}
+		assert.NoError(t, err, test.metadata)
+		assert.Equal(t, test.value, value, test.metadata)
+	}
+}
@@ -0,0 +1,224 @@
+//go:build !selective_scalers || scalers_aws
+// +build !selective_scalers scalers_aws
+
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/service/batch"
+	"github.com/aws/aws-sdk-go/service/batch/batchiface"
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	defaultTargetBatchJobCount = 5
+)
+
+// defaultBatchJobStatuses are the statuses of the jobs waiting for a compute resource
+var defaultBatchJobStatuses = []string{batch.JobStatusSubmitted, batch.JobStatusPending, batch.JobStatusRunnable}
+
+// awsBatchJobStatuses are the statuses of the jobs that are not finished
+var awsBatchJobStatuses = map[string]bool{
+	batch.JobStatusSubmitted: true,
+	batch.JobStatusPending:   true,
+	batch.JobStatusRunnable:  true,
+	batch.JobStatusStarting:  true,
+	batch.JobStatusRunning:   true,
+}
+
+type awsBatchScaler struct {
+	metadata    *awsBatchMetadata
+	batchClient batchiface.BatchAPI
+}
+
+type awsBatchMetadata struct {
+	// jobQueue is the name or the ARN of the job queue
+	jobQueue                 string
+	jobStatuses              []string
+	targetJobCount           int64
+	activationTargetJobCount int64
+	awsRegion                string
+	awsEndpoint              string
+	awsAuthorization         awsAuthorizationMetadata
+	scalerIndex              int
+}
+
+var batchLog = logf.Log.WithName("aws_batch_scaler")
+
+// NewAwsBatchScaler creates a new awsBatchScaler
+func NewAwsBatchScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseAwsBatchMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing Batch metadata: %s", err)
+	}
+
+	return &awsBatchScaler{
+		metadata:    meta,
+		batchClient: createBatchClient(meta),
+	}, nil
+}
+
+func parseAwsBatchMetadata(config *ScalerConfig) (*awsBatchMetadata, error) {
+	meta := awsBatchMetadata{}
+	meta.targetJobCount = defaultTargetBatchJobCount
+	meta.jobStatuses = defaultBatchJobStatuses
+
+	if val, ok := config.TriggerMetadata["jobQueue"]; ok && val != "" {
+		meta.jobQueue = val
+	} else {
+		return nil, fmt.Errorf("no jobQueue given")
+	}
+
+	if val, ok := config.TriggerMetadata["jobStatuses"]; ok && val != "" {
+		meta.jobStatuses = nil
+		for _, status := range strings.Split(val, ",") {
+			status = strings.ToUpper(strings.TrimSpace(status))
+			if !awsBatchJobStatuses[status] {
+				return nil, fmt.Errorf("jobStatuses must be SUBMITTED, PENDING, RUNNABLE, STARTING or RUNNING, got %s", status)
+			}
+			meta.jobStatuses = append(meta.jobStatuses, status)
+		}
+	}
+
+	if val, ok := config.TriggerMetadata["jobCount"]; ok && val != "" {
+		jobCount, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing jobCount: %s", err)
+		}
+		meta.targetJobCount = jobCount
+	}
+
+	if val, ok := config.TriggerMetadata["activationJobCount"]; ok && val != "" {
+		activationJobCount, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing activationJobCount: %s", err)
+		}
+		meta.activationTargetJobCount = activationJobCount
+	}
+
+	if val, ok := config.TriggerMetadata["awsRegion"]; ok && val != "" {
+		meta.awsRegion = val
+	} else {
+		return nil, fmt.Errorf("no awsRegion given")
+	}
+
+	endpoint, err := getAwsEndpoint(config.TriggerMetadata)
+	if err != nil {
+		return nil, err
+	}
+	meta.awsEndpoint = endpoint
+
+	auth, err := getAwsAuthorization(config.AuthParams, config.TriggerMetadata, config.ResolvedEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateAwsPartition(config.TriggerMetadata, meta.awsRegion, auth); err != nil {
+		return nil, err
+	}
+
+	meta.awsAuthorization = auth
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+func createBatchClient(metadata *awsBatchMetadata) *batch.Batch {
+	sess := newAwsSession(metadata.awsRegion)
+
+	config := newAwsConfig(metadata.awsRegion, metadata.awsEndpoint)
+	if metadata.awsAuthorization.podIdentityOwner {
+		creds := credentials.NewStaticCredentials(metadata.awsAuthorization.awsAccessKeyID, metadata.awsAuthorization.awsSecretAccessKey, "")
+
+		if metadata.awsAuthorization.awsRoleArn != "" {
+			creds = stscreds.NewCredentials(sess, metadata.awsAuthorization.awsRoleArn)
+		}
+
+		config = config.WithCredentials(creds)
+	}
+	return batch.New(sess, config)
+}
+
+// IsActive determines if we need to scale from zero
+func (s *awsBatchScaler) IsActive(ctx context.Context) (bool, error) {
+	count, err := s.GetAwsBatchJobCount(ctx)
+	if err != nil {
+		batchLog.Error(err, "Error getting job count", "jobQueue", s.metadata.jobQueue)
+		return false, err
+	}
+
+	return count > s.metadata.activationTargetJobCount, nil
+}
+
+func (s *awsBatchScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *awsBatchScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	// the name of the job queue is the last part of its ARN
+	jobQueueName := s.metadata.jobQueue[strings.LastIndex(s.metadata.jobQueue, "/")+1:]
+	targetJobCountQty := resource.NewQuantity(s.metadata.targetJobCount, resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(fmt.Sprintf("aws-batch-%s", jobQueueName))),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetJobCountQty,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{External: externalMetric, Type: externalMetricType}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// GetMetrics returns value for a supported metric and an error if there is a problem getting the metric
+func (s *awsBatchScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	count, err := s.GetAwsBatchJobCount(ctx)
+	if err != nil {
+		batchLog.Error(err, "Error getting job count", "jobQueue", s.metadata.jobQueue)
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewQuantity(count, resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
+
+// GetAwsBatchJobCount adds up the jobs of the queue in the statuses over all the pages, ListJobs filters a single
+// status per request
+func (s *awsBatchScaler) GetAwsBatchJobCount(ctx context.Context) (int64, error) {
+	var count int64
+	for _, status := range s.metadata.jobStatuses {
+		input := &batch.ListJobsInput{
+			JobQueue:  aws.String(s.metadata.jobQueue),
+			JobStatus: aws.String(status),
+		}
+		err := s.batchClient.ListJobsPagesWithContext(ctx, input, func(page *batch.ListJobsOutput, lastPage bool) bool {
+			count += int64(len(page.JobSummaryList))
+			return true
+		})
+		if err != nil {
+			return -1, err
+		}
+	}
+	return count, nil
+}
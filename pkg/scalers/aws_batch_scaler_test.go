@@ -0,0 +1,179 @@
+//go:build !selective_scalers || scalers_aws
+// +build !selective_scalers scalers_aws
+
+package scalers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/batch"
+	"github.com/aws/aws-sdk-go/service/batch/batchiface"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testAWSBatchRoleArn       = "arn:aws:iam::123456789012:role/keda"
+	testAWSBatchJobQueue      = "render"
+	testAWSBatchJobQueueArn   = "arn:aws:batch:eu-west-1:123456789012:job-queue/render"
+	testAWSBatchErrorJobQueue = "Error"
+)
+
+var testAWSBatchAuthentication = map[string]string{
+	"awsAccessKeyId":     "none",
+	"awsSecretAccessKey": "none",
+}
+
+type parseAWSBatchMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+	comment    string
+}
+
+type awsBatchMetricIdentifier struct {
+	metadataTestData *parseAWSBatchMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+// mockBatch has 2 jobs in each status, the RUNNABLE jobs are returned in two pages
+type mockBatch struct {
+	batchiface.BatchAPI
+}
+
+func (m *mockBatch) ListJobsPagesWithContext(_ aws.Context, input *batch.ListJobsInput, fn func(*batch.ListJobsOutput, bool) bool, _ ...request.Option) error {
+	if *input.JobQueue == testAWSBatchErrorJobQueue {
+		return errors.New("some error")
+	}
+	if *input.JobStatus == batch.JobStatusRunnable {
+		if fn(&batch.ListJobsOutput{JobSummaryList: make([]*batch.JobSummary, 1), NextToken: aws.String("1")}, false) {
+			fn(&batch.ListJobsOutput{JobSummaryList: make([]*batch.JobSummary, 1)}, true)
+		}
+		return nil
+	}
+	fn(&batch.ListJobsOutput{JobSummaryList: make([]*batch.JobSummary, 2)}, true)
+	return nil
+}
+
+var testAWSBatchMetadata = []parseAWSBatchMetadataTestData{
+	{map[string]string{}, testAWSBatchAuthentication, true, "metadata empty"},
+	{map[string]string{
+		"jobQueue":           testAWSBatchJobQueue,
+		"jobCount":           "10",
+		"activationJobCount": "2",
+		"awsRegion":          "eu-west-1"},
+		testAWSBatchAuthentication,
+		false,
+		"properly formed job queue"},
+	{map[string]string{
+		"jobQueue":    testAWSBatchJobQueueArn,
+		"jobStatuses": "runnable, RUNNING",
+		"awsRegion":   "eu-west-1"},
+		testAWSBatchAuthentication,
+		false,
+		"job queue ARN with job statuses"},
+	{map[string]string{
+		"jobQueue":    testAWSBatchJobQueue,
+		"jobStatuses": "SUCCEEDED",
+		"awsRegion":   "eu-west-1"},
+		testAWSBatchAuthentication,
+		true,
+		"finished job status"},
+	{map[string]string{
+		"jobQueue":  testAWSBatchJobQueue,
+		"jobCount":  "a",
+		"awsRegion": "eu-west-1"},
+		testAWSBatchAuthentication,
+		true,
+		"invalid jobCount"},
+	{map[string]string{
+		"jobQueue":           testAWSBatchJobQueue,
+		"activationJobCount": "a",
+		"awsRegion":          "eu-west-1"},
+		testAWSBatchAuthentication,
+		true,
+		"invalid activationJobCount"},
+	{map[string]string{
+		"jobQueue": testAWSBatchJobQueue},
+		testAWSBatchAuthentication,
+		true,
+		"missing awsRegion"},
+	{map[string]string{
+		"jobQueue":  testAWSBatchJobQueue,
+		"awsRegion": "eu-west-1"},
+		map[string]string{
+			"awsAccessKeyId": "none",
+		},
+		true,
+		"with AWS credentials missing secret"},
+	{map[string]string{
+		"jobQueue":  testAWSBatchJobQueue,
+		"awsRegion": "eu-west-1"},
+		map[string]string{
+			"awsRoleArn": testAWSBatchRoleArn,
+		},
+		false,
+		"with AWS role ARN"},
+}
+
+var awsBatchMetricIdentifiers = []awsBatchMetricIdentifier{
+	{&testAWSBatchMetadata[1], 0, "s0-aws-batch-render"},
+	{&testAWSBatchMetadata[2], 1, "s1-aws-batch-render"},
+}
+
+func TestAWSBatchParseMetadata(t *testing.T) {
+	for _, testData := range testAWSBatchMetadata {
+		_, err := parseAwsBatchMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, ResolvedEnv: map[string]string{}, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Errorf("Expected success because %s got error, %s", testData.comment, err)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Expected error because %s but got success, %#v", testData.comment, testData)
+		}
+	}
+}
+
+func TestAWSBatchGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range awsBatchMetricIdentifiers {
+		ctx := context.Background()
+		meta, err := parseAwsBatchMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, ResolvedEnv: map[string]string{}, AuthParams: testData.metadataTestData.authParams, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockAWSBatchScaler := awsBatchScaler{meta, &mockBatch{}}
+
+		metricSpec := mockAWSBatchScaler.GetMetricSpecForScaling(ctx)
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Error("Wrong External metric source name:", metricName)
+		}
+	}
+}
+
+func TestAWSBatchGetJobCount(t *testing.T) {
+	var tests = []struct {
+		metadata map[string]string
+		expected int64
+	}{
+		// SUBMITTED, PENDING and RUNNABLE by default
+		{testAWSBatchMetadata[1].metadata, 6},
+		{testAWSBatchMetadata[2].metadata, 4},
+	}
+	for _, test := range tests {
+		meta, err := parseAwsBatchMetadata(&ScalerConfig{TriggerMetadata: test.metadata, ResolvedEnv: map[string]string{}, AuthParams: testAWSBatchAuthentication})
+		assert.NoError(t, err)
+		scaler := awsBatchScaler{meta, &mockBatch{}}
+
+		value, err := scaler.GetMetrics(context.Background(), "MetricName", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, test.expected, value[0].Value.Value())
+	}
+
+	scaler := awsBatchScaler{&awsBatchMetadata{jobQueue: testAWSBatchErrorJobQueue, jobStatuses: defaultBatchJobStatuses}, &mockBatch{}}
+	_, err := scaler.IsActive(context.Background())
+	assert.Error(t, err)
+}
@@ -1,3 +1,6 @@
+//go:build !selective_scalers || scalers_aws
+// +build !selective_scalers scalers_aws
+
 package scalers
 
 import (
@@ -10,7 +13,6 @@ import (
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
 	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
 	"k8s.io/api/autoscaling/v2beta2"
@@ -51,7 +53,8 @@ type awsCloudwatchMetadata struct {
 	metricStatPeriod     int64
 	metricEndTimeOffset  int64
 
-	awsRegion string
+	awsRegion   string
+	awsEndpoint string
 
 	awsAuthorization awsAuthorizationMetadata
 
@@ -106,9 +109,7 @@ func getFloatMetadataValue(metadata map[string]string, key string, required bool
 }
 
 func createCloudwatchClient(metadata *awsCloudwatchMetadata) *cloudwatch.CloudWatch {
-	sess := session.Must(session.NewSession(&aws.Config{
-		Region: aws.String(metadata.awsRegion),
-	}))
+	sess := newAwsSession(metadata.awsRegion)
 
 	var cloudwatchClient *cloudwatch.CloudWatch
 	if metadata.awsAuthorization.podIdentityOwner {
@@ -118,14 +119,9 @@ func createCloudwatchClient(metadata *awsCloudwatchMetadata) *cloudwatch.CloudWa
 			creds = stscreds.NewCredentials(sess, metadata.awsAuthorization.awsRoleArn)
 		}
 
-		cloudwatchClient = cloudwatch.New(sess, &aws.Config{
-			Region:      aws.String(metadata.awsRegion),
-			Credentials: creds,
-		})
+		cloudwatchClient = cloudwatch.New(sess, newAwsConfig(metadata.awsRegion, metadata.awsEndpoint).WithCredentials(creds))
 	} else {
-		cloudwatchClient = cloudwatch.New(sess, &aws.Config{
-			Region: aws.String(metadata.awsRegion),
-		})
+		cloudwatchClient = cloudwatch.New(sess, newAwsConfig(metadata.awsRegion, metadata.awsEndpoint))
 	}
 
 	return cloudwatchClient
@@ -215,11 +211,20 @@ func parseAwsCloudwatchMetadata(config *ScalerConfig) (*awsCloudwatchMetadata, e
 		return nil, fmt.Errorf("no awsRegion given")
 	}
 
+	meta.awsEndpoint, err = getAwsEndpoint(config.TriggerMetadata)
+	if err != nil {
+		return nil, err
+	}
+
 	meta.awsAuthorization, err = getAwsAuthorization(config.AuthParams, config.TriggerMetadata, config.ResolvedEnv)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := validateAwsPartition(config.TriggerMetadata, meta.awsRegion, meta.awsAuthorization); err != nil {
+		return nil, err
+	}
+
 	meta.scalerIndex = config.ScalerIndex
 
 	return &meta, nil
@@ -272,7 +277,7 @@ func computeQueryWindow(current time.Time, metricPeriodSec, metricEndTimeOffsetS
 }
 
 func (c *awsCloudwatchScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
-	metricValue, err := c.GetCloudwatchMetrics()
+	metricValue, err := c.GetCloudwatchMetrics(ctx)
 
 	if err != nil {
 		cloudwatchLog.Error(err, "Error getting metric value")
@@ -304,7 +309,7 @@ func (c *awsCloudwatchScaler) GetMetricSpecForScaling(context.Context) []v2beta2
 }
 
 func (c *awsCloudwatchScaler) IsActive(ctx context.Context) (bool, error) {
-	val, err := c.GetCloudwatchMetrics()
+	val, err := c.GetCloudwatchMetrics(ctx)
 
 	if err != nil {
 		return false, err
@@ -317,7 +322,7 @@ func (c *awsCloudwatchScaler) Close(context.Context) error {
 	return nil
 }
 
-func (c *awsCloudwatchScaler) GetCloudwatchMetrics() (float64, error) {
+func (c *awsCloudwatchScaler) GetCloudwatchMetrics(ctx context.Context) (float64, error) {
 	dimensions := []*cloudwatch.Dimension{}
 	for i := range c.metadata.dimensionName {
 		dimensions = append(dimensions, &cloudwatch.Dimension{
@@ -355,7 +360,7 @@ func (c *awsCloudwatchScaler) GetCloudwatchMetrics() (float64, error) {
 		},
 	}
 
-	output, err := c.cwClient.GetMetricData(&input)
+	output, err := c.cwClient.GetMetricDataWithContext(ctx, &input)
 
 	if err != nil {
 		cloudwatchLog.Error(err, "Failed to get output")
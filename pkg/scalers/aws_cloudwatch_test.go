@@ -1,3 +1,6 @@
+//go:build !selective_scalers || scalers_aws
+// +build !selective_scalers scalers_aws
+
 package scalers
 
 import (
@@ -7,6 +10,7 @@ import (
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/cloudwatch"
 	"github.com/aws/aws-sdk-go/service/cloudwatch/cloudwatchiface"
 	"github.com/stretchr/testify/assert"
@@ -14,7 +18,7 @@ import (
 )
 
 const (
-	testAWSCloudwatchRoleArn         = "none"
+	testAWSCloudwatchRoleArn         = "arn:aws:iam::123456789012:role/keda"
 	testAWSCloudwatchAccessKeyID     = "none"
 	testAWSCloudwatchSecretAccessKey = "none"
 	testAWSCloudwatchErrorMetric     = "Error"
@@ -396,7 +400,7 @@ type mockCloudwatch struct {
 	cloudwatchiface.CloudWatchAPI
 }
 
-func (m *mockCloudwatch) GetMetricData(input *cloudwatch.GetMetricDataInput) (*cloudwatch.GetMetricDataOutput, error) {
+func (m *mockCloudwatch) GetMetricDataWithContext(_ aws.Context, input *cloudwatch.GetMetricDataInput, _ ...request.Option) (*cloudwatch.GetMetricDataOutput, error) {
 	switch *input.MetricDataQueries[0].MetricStat.Metric.MetricName {
 	case testAWSCloudwatchErrorMetric:
 		return nil, errors.New("error")
@@ -0,0 +1,88 @@
+//go:build !selective_scalers || scalers_aws || scalers_database
+// +build !selective_scalers scalers_aws scalers_database
+
+package scalers
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/endpoints"
+	"github.com/aws/aws-sdk-go/aws/session"
+)
+
+// getAwsEndpoint returns the custom endpoint of the AWS api given in awsEndpoint, eg. of localstack or of an
+// S3-compatible store, or an empty string to use the endpoint of the region
+func getAwsEndpoint(metadata map[string]string) (string, error) {
+	val, ok := metadata["awsEndpoint"]
+	if !ok || val == "" {
+		return "", nil
+	}
+
+	endpoint, err := url.ParseRequestURI(val)
+	if err != nil || endpoint.Host == "" {
+		return "", fmt.Errorf("awsEndpoint %s must be an url like https://host:port", val)
+	}
+	return strings.TrimSuffix(val, "/"), nil
+}
+
+// newAwsConfig returns the config of an AWS service client for the region and the optional custom endpoint
+func newAwsConfig(region, endpoint string) *aws.Config {
+	config := &aws.Config{
+		Region: aws.String(region),
+	}
+	if endpoint != "" {
+		config.Endpoint = aws.String(endpoint)
+	}
+	return config
+}
+
+// newAwsSession returns the session of the region, the role in awsRoleArn is assumed through the regional STS
+// endpoint of the region as the global endpoint isn't available in the GovCloud and China partitions
+func newAwsSession(region string) *session.Session {
+	return session.Must(session.NewSession(&aws.Config{
+		Region:              aws.String(region),
+		STSRegionalEndpoint: endpoints.RegionalSTSEndpoint,
+	}))
+}
+
+// validateAwsPartition checks the region is in the partition given in awsPartition, eg. aws-us-gov or aws-cn,
+// and the role to assume is in the partition of the region. The partition of regions unknown to the SDK
+// is awsPartition, their role isn't checked without it
+func validateAwsPartition(metadata map[string]string, region string, authorization awsAuthorizationMetadata) error {
+	partition := metadata["awsPartition"]
+	if partition != "" && !isAwsPartition(partition) {
+		return fmt.Errorf("awsPartition %s is not a known partition", partition)
+	}
+
+	if p, ok := endpoints.PartitionForRegion(endpoints.DefaultPartitions(), region); ok {
+		if partition != "" && partition != p.ID() {
+			return fmt.Errorf("awsRegion %s is in partition %s, not in awsPartition %s", region, p.ID(), partition)
+		}
+		partition = p.ID()
+	}
+
+	if authorization.awsRoleArn == "" || partition == "" {
+		return nil
+	}
+	roleArn, err := arn.Parse(authorization.awsRoleArn)
+	if err != nil {
+		return fmt.Errorf("awsRoleArn %s is not a valid arn: %s", authorization.awsRoleArn, err)
+	}
+	if roleArn.Partition != partition {
+		return fmt.Errorf("awsRoleArn %s is not in partition %s of awsRegion %s", authorization.awsRoleArn, partition, region)
+	}
+	return nil
+}
+
+func isAwsPartition(id string) bool {
+	for _, p := range endpoints.DefaultPartitions() {
+		if p.ID() == id {
+			return true
+		}
+	}
+	return false
+}
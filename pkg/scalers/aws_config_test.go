@@ -0,0 +1,59 @@
+//go:build !selective_scalers || scalers_aws
+// +build !selective_scalers scalers_aws
+
+package scalers
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/service/sts"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAWSValidatePartition(t *testing.T) {
+	var testData = []struct {
+		name       string
+		metadata   map[string]string
+		region     string
+		awsRoleArn string
+		isError    bool
+	}{
+		{"commercial region without role", map[string]string{}, "eu-west-1", "", false},
+		{"commercial region with role", map[string]string{}, "eu-west-1", "arn:aws:iam::123456789012:role/keda", false},
+		{"govcloud region with role", map[string]string{}, "us-gov-west-1", "arn:aws-us-gov:iam::123456789012:role/keda", false},
+		{"china region with role and partition", map[string]string{"awsPartition": "aws-cn"}, "cn-north-1", "arn:aws-cn:iam::123456789012:role/keda", false},
+		{"unknown region with partition", map[string]string{"awsPartition": "aws-us-gov"}, "us-gov-north-9", "arn:aws-us-gov:iam::123456789012:role/keda", false},
+		{"unknown region without partition", map[string]string{}, "xx-north-9", "arn:aws-xx:iam::123456789012:role/keda", false},
+		{"govcloud region with commercial role", map[string]string{}, "us-gov-west-1", "arn:aws:iam::123456789012:role/keda", true},
+		{"commercial region with china role", map[string]string{}, "eu-west-1", "arn:aws-cn:iam::123456789012:role/keda", true},
+		{"unknown region with role of other partition", map[string]string{"awsPartition": "aws-cn"}, "cn-north-9", "arn:aws:iam::123456789012:role/keda", true},
+		{"region not in partition", map[string]string{"awsPartition": "aws-cn"}, "us-gov-west-1", "", true},
+		{"unknown partition", map[string]string{"awsPartition": "aws-xx"}, "eu-west-1", "", true},
+		{"malformed role", map[string]string{}, "eu-west-1", "keda", true},
+	}
+
+	for _, test := range testData {
+		err := validateAwsPartition(test.metadata, test.region, awsAuthorizationMetadata{awsRoleArn: test.awsRoleArn, podIdentityOwner: true})
+		if test.isError {
+			assert.Error(t, err, test.name)
+		} else {
+			assert.NoError(t, err, test.name)
+		}
+	}
+}
+
+func TestAWSSessionRegionalSTSEndpoint(t *testing.T) {
+	var testData = []struct {
+		region   string
+		endpoint string
+	}{
+		{"us-east-1", "https://sts.us-east-1.amazonaws.com"},
+		{"us-gov-west-1", "https://sts.us-gov-west-1.amazonaws.com"},
+		{"cn-north-1", "https://sts.cn-north-1.amazonaws.com.cn"},
+	}
+
+	for _, test := range testData {
+		client := sts.New(newAwsSession(test.region))
+		assert.Equal(t, test.endpoint, client.Endpoint, test.region)
+	}
+}
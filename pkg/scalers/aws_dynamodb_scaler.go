@@ -0,0 +1,246 @@
+//go:build !selective_scalers || scalers_aws
+// +build !selective_scalers scalers_aws
+
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+type awsDynamoDBScaler struct {
+	metadata       *awsDynamoDBMetadata
+	dynamoDBClient dynamodbiface.DynamoDBAPI
+}
+
+type awsDynamoDBMetadata struct {
+	tableName string
+	indexName string
+	// keyConditionExpression selects the items with a Query, the table or index is scanned without it
+	keyConditionExpression    string
+	filterExpression          string
+	expressionAttributeNames  map[string]*string
+	expressionAttributeValues map[string]*dynamodb.AttributeValue
+	targetValue               int64
+	activationTargetValue     int64
+	awsRegion                 string
+	awsEndpoint               string
+	awsAuthorization          awsAuthorizationMetadata
+	scalerIndex               int
+}
+
+var dynamoDBLog = logf.Log.WithName("aws_dynamodb_scaler")
+
+// NewAwsDynamoDBScaler creates a new awsDynamoDBScaler
+func NewAwsDynamoDBScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseAwsDynamoDBMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing DynamoDB metadata: %s", err)
+	}
+
+	return &awsDynamoDBScaler{
+		metadata:       meta,
+		dynamoDBClient: createDynamoDBClient(meta),
+	}, nil
+}
+
+func parseAwsDynamoDBMetadata(config *ScalerConfig) (*awsDynamoDBMetadata, error) {
+	meta := awsDynamoDBMetadata{}
+
+	if val, ok := config.TriggerMetadata["tableName"]; ok && val != "" {
+		meta.tableName = val
+	} else {
+		return nil, fmt.Errorf("no tableName given")
+	}
+
+	meta.indexName = config.TriggerMetadata["indexName"]
+	meta.keyConditionExpression = config.TriggerMetadata["keyConditionExpression"]
+	meta.filterExpression = config.TriggerMetadata["filterExpression"]
+
+	// the names and values of the placeholders of the expressions, eg. {"#s": "status"} and {":s": {"S": "pending"}}
+	if val, ok := config.TriggerMetadata["expressionAttributeNames"]; ok && val != "" {
+		if err := json.Unmarshal([]byte(val), &meta.expressionAttributeNames); err != nil {
+			return nil, fmt.Errorf("error parsing expressionAttributeNames: %s", err)
+		}
+	}
+	if val, ok := config.TriggerMetadata["expressionAttributeValues"]; ok && val != "" {
+		if err := json.Unmarshal([]byte(val), &meta.expressionAttributeValues); err != nil {
+			return nil, fmt.Errorf("error parsing expressionAttributeValues: %s", err)
+		}
+	}
+
+	if val, ok := config.TriggerMetadata["targetValue"]; ok && val != "" {
+		targetValue, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing targetValue: %s", err)
+		}
+		meta.targetValue = targetValue
+	} else {
+		return nil, fmt.Errorf("no targetValue given")
+	}
+
+	if val, ok := config.TriggerMetadata["activationTargetValue"]; ok && val != "" {
+		activationTargetValue, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing activationTargetValue: %s", err)
+		}
+		meta.activationTargetValue = activationTargetValue
+	}
+
+	if val, ok := config.TriggerMetadata["awsRegion"]; ok && val != "" {
+		meta.awsRegion = val
+	} else {
+		return nil, fmt.Errorf("no awsRegion given")
+	}
+
+	endpoint, err := getAwsEndpoint(config.TriggerMetadata)
+	if err != nil {
+		return nil, err
+	}
+	meta.awsEndpoint = endpoint
+
+	auth, err := getAwsAuthorization(config.AuthParams, config.TriggerMetadata, config.ResolvedEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateAwsPartition(config.TriggerMetadata, meta.awsRegion, auth); err != nil {
+		return nil, err
+	}
+
+	meta.awsAuthorization = auth
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+func createDynamoDBClient(metadata *awsDynamoDBMetadata) *dynamodb.DynamoDB {
+	sess := newAwsSession(metadata.awsRegion)
+
+	config := newAwsConfig(metadata.awsRegion, metadata.awsEndpoint)
+	if metadata.awsAuthorization.podIdentityOwner {
+		creds := credentials.NewStaticCredentials(metadata.awsAuthorization.awsAccessKeyID, metadata.awsAuthorization.awsSecretAccessKey, "")
+
+		if metadata.awsAuthorization.awsRoleArn != "" {
+			creds = stscreds.NewCredentials(sess, metadata.awsAuthorization.awsRoleArn)
+		}
+
+		config = config.WithCredentials(creds)
+	}
+	return dynamodb.New(sess, config)
+}
+
+// IsActive determines if we need to scale from zero
+func (s *awsDynamoDBScaler) IsActive(ctx context.Context) (bool, error) {
+	count, err := s.GetAwsDynamoDBItemCount(ctx)
+	if err != nil {
+		dynamoDBLog.Error(err, "Error getting item count")
+		return false, err
+	}
+
+	return count > s.metadata.activationTargetValue, nil
+}
+
+func (s *awsDynamoDBScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *awsDynamoDBScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetValueQty := resource.NewQuantity(s.metadata.targetValue, resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(s.metricName())),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetValueQty,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{External: externalMetric, Type: externalMetricType}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+func (s *awsDynamoDBScaler) metricName() string {
+	if s.metadata.indexName != "" {
+		return fmt.Sprintf("aws-dynamodb-%s-%s", s.metadata.tableName, s.metadata.indexName)
+	}
+	return fmt.Sprintf("aws-dynamodb-%s", s.metadata.tableName)
+}
+
+// GetMetrics returns value for a supported metric and an error if there is a problem getting the metric
+func (s *awsDynamoDBScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	count, err := s.GetAwsDynamoDBItemCount(ctx)
+	if err != nil {
+		dynamoDBLog.Error(err, "Error getting item count")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewQuantity(count, resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
+
+// GetAwsDynamoDBItemCount counts the items matching the key condition and the filter over all the pages, only
+// the count is returned so no items are read into the scaler
+func (s *awsDynamoDBScaler) GetAwsDynamoDBItemCount(ctx context.Context) (int64, error) {
+	var count int64
+	if s.metadata.keyConditionExpression != "" {
+		input := &dynamodb.QueryInput{
+			TableName:                 aws.String(s.metadata.tableName),
+			KeyConditionExpression:    aws.String(s.metadata.keyConditionExpression),
+			ExpressionAttributeNames:  s.metadata.expressionAttributeNames,
+			ExpressionAttributeValues: s.metadata.expressionAttributeValues,
+			Select:                    aws.String(dynamodb.SelectCount),
+		}
+		if s.metadata.indexName != "" {
+			input.IndexName = aws.String(s.metadata.indexName)
+		}
+		if s.metadata.filterExpression != "" {
+			input.FilterExpression = aws.String(s.metadata.filterExpression)
+		}
+		err := s.dynamoDBClient.QueryPagesWithContext(ctx, input, func(page *dynamodb.QueryOutput, lastPage bool) bool {
+			count += aws.Int64Value(page.Count)
+			return true
+		})
+		return count, err
+	}
+
+	input := &dynamodb.ScanInput{
+		TableName:                 aws.String(s.metadata.tableName),
+		ExpressionAttributeNames:  s.metadata.expressionAttributeNames,
+		ExpressionAttributeValues: s.metadata.expressionAttributeValues,
+		Select:                    aws.String(dynamodb.SelectCount),
+	}
+	if s.metadata.indexName != "" {
+		input.IndexName = aws.String(s.metadata.indexName)
+	}
+	if s.metadata.filterExpression != "" {
+		input.FilterExpression = aws.String(s.metadata.filterExpression)
+	}
+	err := s.dynamoDBClient.ScanPagesWithContext(ctx, input, func(page *dynamodb.ScanOutput, lastPage bool) bool {
+		count += aws.Int64Value(page.Count)
+		return true
+	})
+	return count, err
+}
@@ -0,0 +1,224 @@
+//go:build !selective_scalers || scalers_aws
+// +build !selective_scalers scalers_aws
+
+package scalers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testAWSDynamoDBRoleArn      = "arn:aws:iam::123456789012:role/keda"
+	testAWSDynamoDBErrorTable   = "Error"
+	testAWSDynamoDBQueryTable   = "Jobs"
+	testAWSDynamoDBKeyCondition = "#s = :s"
+)
+
+var testAWSDynamoDBAuthentication = map[string]string{
+	"awsAccessKeyId":     "none",
+	"awsSecretAccessKey": "none",
+}
+
+type parseAWSDynamoDBMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+	comment    string
+}
+
+type awsDynamoDBMetricIdentifier struct {
+	metadataTestData *parseAWSDynamoDBMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+type mockDynamoDB struct {
+	dynamodbiface.DynamoDBAPI
+}
+
+func (m *mockDynamoDB) QueryPagesWithContext(_ aws.Context, input *dynamodb.QueryInput, fn func(*dynamodb.QueryOutput, bool) bool, _ ...request.Option) error {
+	if *input.TableName == testAWSDynamoDBErrorTable {
+		return errors.New("some error")
+	}
+	if fn(&dynamodb.QueryOutput{Count: aws.Int64(4)}, false) {
+		fn(&dynamodb.QueryOutput{Count: aws.Int64(3)}, true)
+	}
+	return nil
+}
+
+func (m *mockDynamoDB) ScanPagesWithContext(_ aws.Context, input *dynamodb.ScanInput, fn func(*dynamodb.ScanOutput, bool) bool, _ ...request.Option) error {
+	if *input.TableName == testAWSDynamoDBErrorTable {
+		return errors.New("some error")
+	}
+	fn(&dynamodb.ScanOutput{Count: aws.Int64(2)}, true)
+	return nil
+}
+
+var testAWSDynamoDBMetadata = []parseAWSDynamoDBMetadataTestData{
+	{map[string]string{}, testAWSDynamoDBAuthentication, true, "metadata empty"},
+	{map[string]string{
+		"tableName":                 testAWSDynamoDBQueryTable,
+		"keyConditionExpression":    testAWSDynamoDBKeyCondition,
+		"expressionAttributeNames":  `{"#s": "status"}`,
+		"expressionAttributeValues": `{":s": {"S": "pending"}}`,
+		"targetValue":               "5",
+		"awsRegion":                 "eu-west-1"},
+		testAWSDynamoDBAuthentication,
+		false,
+		"properly formed query"},
+	{map[string]string{
+		"tableName":   testAWSDynamoDBQueryTable,
+		"indexName":   "status-index",
+		"targetValue": "5",
+		"awsRegion":   "eu-west-1"},
+		testAWSDynamoDBAuthentication,
+		false,
+		"properly formed scan of an index"},
+	{map[string]string{
+		"targetValue": "5",
+		"awsRegion":   "eu-west-1"},
+		testAWSDynamoDBAuthentication,
+		true,
+		"missing tableName"},
+	{map[string]string{
+		"tableName": testAWSDynamoDBQueryTable,
+		"awsRegion": "eu-west-1"},
+		testAWSDynamoDBAuthentication,
+		true,
+		"missing targetValue"},
+	{map[string]string{
+		"tableName":   testAWSDynamoDBQueryTable,
+		"targetValue": "a",
+		"awsRegion":   "eu-west-1"},
+		testAWSDynamoDBAuthentication,
+		true,
+		"invalid targetValue"},
+	{map[string]string{
+		"tableName":             testAWSDynamoDBQueryTable,
+		"targetValue":           "5",
+		"activationTargetValue": "a",
+		"awsRegion":             "eu-west-1"},
+		testAWSDynamoDBAuthentication,
+		true,
+		"invalid activationTargetValue"},
+	{map[string]string{
+		"tableName":   testAWSDynamoDBQueryTable,
+		"targetValue": "5"},
+		testAWSDynamoDBAuthentication,
+		true,
+		"missing awsRegion"},
+	{map[string]string{
+		"tableName":                 testAWSDynamoDBQueryTable,
+		"keyConditionExpression":    testAWSDynamoDBKeyCondition,
+		"expressionAttributeValues": `{":s": "pending"}`,
+		"targetValue":               "5",
+		"awsRegion":                 "eu-west-1"},
+		testAWSDynamoDBAuthentication,
+		true,
+		"invalid expressionAttributeValues"},
+	{map[string]string{
+		"tableName":                testAWSDynamoDBQueryTable,
+		"expressionAttributeNames": `["status"]`,
+		"targetValue":              "5",
+		"awsRegion":                "eu-west-1"},
+		testAWSDynamoDBAuthentication,
+		true,
+		"invalid expressionAttributeNames"},
+	{map[string]string{
+		"tableName":   testAWSDynamoDBQueryTable,
+		"targetValue": "5",
+		"awsRegion":   "eu-west-1"},
+		map[string]string{
+			"awsAccessKeyId": "none",
+		},
+		true,
+		"with AWS credentials missing secret"},
+	{map[string]string{
+		"tableName":   testAWSDynamoDBQueryTable,
+		"targetValue": "5",
+		"awsRegion":   "eu-west-1"},
+		map[string]string{
+			"awsRoleArn": testAWSDynamoDBRoleArn,
+		},
+		false,
+		"with AWS role ARN"},
+}
+
+var awsDynamoDBMetricIdentifiers = []awsDynamoDBMetricIdentifier{
+	{&testAWSDynamoDBMetadata[1], 0, "s0-aws-dynamodb-Jobs"},
+	{&testAWSDynamoDBMetadata[2], 1, "s1-aws-dynamodb-Jobs-status-index"},
+}
+
+func TestAWSDynamoDBParseMetadata(t *testing.T) {
+	for _, testData := range testAWSDynamoDBMetadata {
+		_, err := parseAwsDynamoDBMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, ResolvedEnv: map[string]string{}, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Errorf("Expected success because %s got error, %s", testData.comment, err)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Expected error because %s but got success, %#v", testData.comment, testData)
+		}
+	}
+}
+
+func TestAWSDynamoDBParseExpressionAttributeValues(t *testing.T) {
+	meta, err := parseAwsDynamoDBMetadata(&ScalerConfig{TriggerMetadata: testAWSDynamoDBMetadata[1].metadata, ResolvedEnv: map[string]string{}, AuthParams: testAWSDynamoDBAuthentication})
+	assert.NoError(t, err)
+	assert.Equal(t, "status", aws.StringValue(meta.expressionAttributeNames["#s"]))
+	assert.Equal(t, "pending", aws.StringValue(meta.expressionAttributeValues[":s"].S))
+}
+
+func TestAWSDynamoDBGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range awsDynamoDBMetricIdentifiers {
+		ctx := context.Background()
+		meta, err := parseAwsDynamoDBMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, ResolvedEnv: map[string]string{}, AuthParams: testData.metadataTestData.authParams, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockAWSDynamoDBScaler := awsDynamoDBScaler{meta, &mockDynamoDB{}}
+
+		metricSpec := mockAWSDynamoDBScaler.GetMetricSpecForScaling(ctx)
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Error("Wrong External metric source name:", metricName)
+		}
+	}
+}
+
+func TestAWSDynamoDBGetItemCount(t *testing.T) {
+	ctx := context.Background()
+	queryScaler := awsDynamoDBScaler{&awsDynamoDBMetadata{tableName: testAWSDynamoDBQueryTable, keyConditionExpression: testAWSDynamoDBKeyCondition}, &mockDynamoDB{}}
+	count, err := queryScaler.GetAwsDynamoDBItemCount(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(7), count, "the counts of all the query pages are summed")
+
+	scanScaler := awsDynamoDBScaler{&awsDynamoDBMetadata{tableName: testAWSDynamoDBQueryTable}, &mockDynamoDB{}}
+	count, err = scanScaler.GetAwsDynamoDBItemCount(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), count)
+
+	errorScaler := awsDynamoDBScaler{&awsDynamoDBMetadata{tableName: testAWSDynamoDBErrorTable, keyConditionExpression: testAWSDynamoDBKeyCondition}, &mockDynamoDB{}}
+	_, err = errorScaler.GetAwsDynamoDBItemCount(ctx)
+	assert.Error(t, err)
+}
+
+func TestAWSDynamoDBIsActive(t *testing.T) {
+	ctx := context.Background()
+	scaler := awsDynamoDBScaler{&awsDynamoDBMetadata{tableName: testAWSDynamoDBQueryTable, activationTargetValue: 6}, &mockDynamoDB{}}
+	active, err := scaler.IsActive(ctx)
+	assert.NoError(t, err)
+	assert.False(t, active, "the scanned count of 2 is below the activation target")
+
+	scaler.metadata.keyConditionExpression = testAWSDynamoDBKeyCondition
+	active, err = scaler.IsActive(ctx)
+	assert.NoError(t, err)
+	assert.True(t, active)
+}
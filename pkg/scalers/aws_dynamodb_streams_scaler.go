@@ -0,0 +1,226 @@
+//go:build !selective_scalers || scalers_aws
+// +build !selective_scalers scalers_aws
+
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/aws/aws-sdk-go/service/dynamodbstreams"
+	"github.com/aws/aws-sdk-go/service/dynamodbstreams/dynamodbstreamsiface"
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	defaultTargetDBStreamsShardCount = 2
+)
+
+type awsDynamoDBStreamsScaler struct {
+	metadata              *awsDynamoDBStreamsMetadata
+	streamArn             *string
+	dynamoDBStreamsClient dynamodbstreamsiface.DynamoDBStreamsAPI
+}
+
+type awsDynamoDBStreamsMetadata struct {
+	targetShardCount           int64
+	activationTargetShardCount int64
+	tableName                  string
+	awsRegion                  string
+	awsEndpoint                string
+	awsAuthorization           awsAuthorizationMetadata
+	scalerIndex                int
+}
+
+var dynamoDBStreamsLog = logf.Log.WithName("aws_dynamodb_streams_scaler")
+
+// NewAwsDynamoDBStreamsScaler creates a new awsDynamoDBStreamsScaler
+func NewAwsDynamoDBStreamsScaler(ctx context.Context, config *ScalerConfig) (Scaler, error) {
+	meta, err := parseAwsDynamoDBStreamsMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing dynamodb stream metadata: %s", err)
+	}
+
+	dbClient, dbStreamClient := createClientsForDynamoDBStreamsScaler(meta)
+
+	streamArn, err := getDynamoDBStreamsArn(ctx, dbClient, &meta.tableName)
+	if err != nil {
+		return nil, fmt.Errorf("error getting dynamodb stream arn: %s", err)
+	}
+
+	return &awsDynamoDBStreamsScaler{
+		metadata:              meta,
+		streamArn:             streamArn,
+		dynamoDBStreamsClient: dbStreamClient,
+	}, nil
+}
+
+func parseAwsDynamoDBStreamsMetadata(config *ScalerConfig) (*awsDynamoDBStreamsMetadata, error) {
+	meta := awsDynamoDBStreamsMetadata{}
+	meta.targetShardCount = defaultTargetDBStreamsShardCount
+
+	if val, ok := config.TriggerMetadata["tableName"]; ok && val != "" {
+		meta.tableName = val
+	} else {
+		return nil, fmt.Errorf("no tableName given")
+	}
+
+	if val, ok := config.TriggerMetadata["awsRegion"]; ok && val != "" {
+		meta.awsRegion = val
+	} else {
+		return nil, fmt.Errorf("no awsRegion given")
+	}
+
+	endpoint, err := getAwsEndpoint(config.TriggerMetadata)
+	if err != nil {
+		return nil, err
+	}
+	meta.awsEndpoint = endpoint
+
+	if val, ok := config.TriggerMetadata["shardCount"]; ok && val != "" {
+		shardCount, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing shardCount: %s", err)
+		}
+		meta.targetShardCount = shardCount
+	}
+
+	if val, ok := config.TriggerMetadata["activationShardCount"]; ok && val != "" {
+		shardCount, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing activationShardCount: %s", err)
+		}
+		meta.activationTargetShardCount = shardCount
+	}
+
+	auth, err := getAwsAuthorization(config.AuthParams, config.TriggerMetadata, config.ResolvedEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateAwsPartition(config.TriggerMetadata, meta.awsRegion, auth); err != nil {
+		return nil, err
+	}
+
+	meta.awsAuthorization = auth
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+func createClientsForDynamoDBStreamsScaler(metadata *awsDynamoDBStreamsMetadata) (*dynamodb.DynamoDB, *dynamodbstreams.DynamoDBStreams) {
+	sess := newAwsSession(metadata.awsRegion)
+
+	config := newAwsConfig(metadata.awsRegion, metadata.awsEndpoint)
+	if metadata.awsAuthorization.podIdentityOwner {
+		creds := credentials.NewStaticCredentials(metadata.awsAuthorization.awsAccessKeyID, metadata.awsAuthorization.awsSecretAccessKey, "")
+
+		if metadata.awsAuthorization.awsRoleArn != "" {
+			creds = stscreds.NewCredentials(sess, metadata.awsAuthorization.awsRoleArn)
+		}
+
+		config = config.WithCredentials(creds)
+	}
+	return dynamodb.New(sess, config), dynamodbstreams.New(sess, config)
+}
+
+// getDynamoDBStreamsArn returns the ARN of the latest stream of the table
+func getDynamoDBStreamsArn(ctx context.Context, db dynamodbiface.DynamoDBAPI, tableName *string) (*string, error) {
+	tableOutput, err := db.DescribeTableWithContext(ctx, &dynamodb.DescribeTableInput{
+		TableName: tableName,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if tableOutput.Table.LatestStreamArn == nil {
+		return nil, fmt.Errorf("dynamodb table %s does not have a stream", *tableName)
+	}
+	return tableOutput.Table.LatestStreamArn, nil
+}
+
+// IsActive determines if we need to scale from zero
+func (s *awsDynamoDBStreamsScaler) IsActive(ctx context.Context) (bool, error) {
+	count, err := s.GetDynamoDBStreamShardCount(ctx)
+	if err != nil {
+		dynamoDBStreamsLog.Error(err, "error getting shard count")
+		return false, err
+	}
+
+	return count > s.metadata.activationTargetShardCount, nil
+}
+
+func (s *awsDynamoDBStreamsScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *awsDynamoDBStreamsScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetShardCountQty := resource.NewQuantity(s.metadata.targetShardCount, resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(fmt.Sprintf("aws-dynamodb-streams-%s", s.metadata.tableName))),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetShardCountQty,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{External: externalMetric, Type: externalMetricType}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// GetMetrics returns value for a supported metric and an error if there is a problem getting the metric
+func (s *awsDynamoDBStreamsScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	shardCount, err := s.GetDynamoDBStreamShardCount(ctx)
+	if err != nil {
+		dynamoDBStreamsLog.Error(err, "error getting shard count")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewQuantity(shardCount, resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
+
+// GetDynamoDBStreamShardCount returns the number of open shards of the stream, ie. the shards without an
+// ending sequence number, DynamoDB splits the shards of a stream as the write throughput of the table grows
+func (s *awsDynamoDBStreamsScaler) GetDynamoDBStreamShardCount(ctx context.Context) (int64, error) {
+	var shardNum int64
+	var lastShardID *string
+	for {
+		input := dynamodbstreams.DescribeStreamInput{
+			StreamArn:             s.streamArn,
+			ExclusiveStartShardId: lastShardID,
+		}
+		des, err := s.dynamoDBStreamsClient.DescribeStreamWithContext(ctx, &input)
+		if err != nil {
+			return -1, err
+		}
+		for _, shard := range des.StreamDescription.Shards {
+			if shard.SequenceNumberRange == nil || shard.SequenceNumberRange.EndingSequenceNumber == nil {
+				shardNum++
+			}
+		}
+		lastShardID = des.StreamDescription.LastEvaluatedShardId
+		if lastShardID == nil {
+			break
+		}
+	}
+	return shardNum, nil
+}
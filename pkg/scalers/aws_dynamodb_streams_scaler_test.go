@@ -0,0 +1,243 @@
+//go:build !selective_scalers || scalers_aws
+// +build !selective_scalers scalers_aws
+
+package scalers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbiface"
+	"github.com/aws/aws-sdk-go/service/dynamodbstreams"
+	"github.com/aws/aws-sdk-go/service/dynamodbstreams/dynamodbstreamsiface"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testAWSDynamoDBStreamsRoleArn         = "arn:aws:iam::123456789012:role/keda"
+	testAWSDynamoDBStreamsAccessKeyID     = "none"
+	testAWSDynamoDBStreamsSecretAccessKey = "none"
+	testAWSDynamoDBStreamsTableName       = "test"
+	testAWSDynamoDBStreamsNoStreamTable   = "no-stream"
+	testAWSDynamoDBStreamsErrorTable      = "Error"
+	testAWSDynamoDBStreamsArn             = "arn:aws:dynamodb:eu-west-1:123456789012:table/test/stream/2022-01-01T00:00:00.000"
+	testAWSDynamoDBStreamsErrorArn        = "arn:aws:dynamodb:eu-west-1:123456789012:table/Error/stream/2022-01-01T00:00:00.000"
+)
+
+var testAWSDynamoDBStreamsAuthentication = map[string]string{
+	"awsAccessKeyId":     testAWSDynamoDBStreamsAccessKeyID,
+	"awsSecretAccessKey": testAWSDynamoDBStreamsSecretAccessKey,
+}
+
+type parseAwsDynamoDBStreamsMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+	comment    string
+}
+
+type awsDynamoDBStreamsMetricIdentifier struct {
+	metadataTestData *parseAwsDynamoDBStreamsMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+type mockAwsDynamoDB struct {
+	dynamodbiface.DynamoDBAPI
+}
+
+func (m *mockAwsDynamoDB) DescribeTableWithContext(_ aws.Context, input *dynamodb.DescribeTableInput, _ ...request.Option) (*dynamodb.DescribeTableOutput, error) {
+	switch *input.TableName {
+	case testAWSDynamoDBStreamsErrorTable:
+		return nil, errors.New("some error")
+	case testAWSDynamoDBStreamsNoStreamTable:
+		return &dynamodb.DescribeTableOutput{Table: &dynamodb.TableDescription{TableName: input.TableName}}, nil
+	}
+	return &dynamodb.DescribeTableOutput{Table: &dynamodb.TableDescription{
+		TableName:       input.TableName,
+		LatestStreamArn: aws.String(testAWSDynamoDBStreamsArn),
+	}}, nil
+}
+
+// mockAwsDynamoDBStreams returns the shards of the stream in pages of two shards,
+// every third shard is closed
+type mockAwsDynamoDBStreams struct {
+	dynamodbstreamsiface.DynamoDBStreamsAPI
+	shards int
+}
+
+func (m *mockAwsDynamoDBStreams) DescribeStreamWithContext(_ aws.Context, input *dynamodbstreams.DescribeStreamInput, _ ...request.Option) (*dynamodbstreams.DescribeStreamOutput, error) {
+	if *input.StreamArn == testAWSDynamoDBStreamsErrorArn {
+		return nil, errors.New("some error")
+	}
+	start := 0
+	if input.ExclusiveStartShardId != nil {
+		if _, err := fmt.Sscanf(*input.ExclusiveStartShardId, "shard-%d", &start); err != nil {
+			return nil, err
+		}
+		start++
+	}
+	description := &dynamodbstreams.StreamDescription{}
+	for i := start; i < m.shards && i < start+2; i++ {
+		shard := &dynamodbstreams.Shard{
+			ShardId:             aws.String(fmt.Sprintf("shard-%d", i)),
+			SequenceNumberRange: &dynamodbstreams.SequenceNumberRange{StartingSequenceNumber: aws.String("1")},
+		}
+		if i%3 == 2 {
+			shard.SequenceNumberRange.EndingSequenceNumber = aws.String("2")
+		}
+		description.Shards = append(description.Shards, shard)
+	}
+	if start+2 < m.shards {
+		description.LastEvaluatedShardId = aws.String(fmt.Sprintf("shard-%d", start+1))
+	}
+	return &dynamodbstreams.DescribeStreamOutput{StreamDescription: description}, nil
+}
+
+var testAwsDynamoDBStreamsMetadata = []parseAwsDynamoDBStreamsMetadataTestData{
+	{map[string]string{}, testAWSDynamoDBStreamsAuthentication, true, "metadata empty"},
+	{map[string]string{
+		"tableName":            testAWSDynamoDBStreamsTableName,
+		"shardCount":           "2",
+		"activationShardCount": "1",
+		"awsRegion":            "eu-west-1"},
+		testAWSDynamoDBStreamsAuthentication,
+		false,
+		"properly formed metadata"},
+	{map[string]string{
+		"tableName": testAWSDynamoDBStreamsTableName,
+		"awsRegion": "eu-west-1"},
+		testAWSDynamoDBStreamsAuthentication,
+		false,
+		"default shardCount"},
+	{map[string]string{
+		"shardCount": "2",
+		"awsRegion":  "eu-west-1"},
+		testAWSDynamoDBStreamsAuthentication,
+		true,
+		"missing tableName"},
+	{map[string]string{
+		"tableName":  testAWSDynamoDBStreamsTableName,
+		"shardCount": "2"},
+		testAWSDynamoDBStreamsAuthentication,
+		true,
+		"missing awsRegion"},
+	{map[string]string{
+		"tableName":  testAWSDynamoDBStreamsTableName,
+		"shardCount": "a",
+		"awsRegion":  "eu-west-1"},
+		testAWSDynamoDBStreamsAuthentication,
+		true,
+		"invalid shardCount"},
+	{map[string]string{
+		"tableName":            testAWSDynamoDBStreamsTableName,
+		"activationShardCount": "a",
+		"awsRegion":            "eu-west-1"},
+		testAWSDynamoDBStreamsAuthentication,
+		true,
+		"invalid activationShardCount"},
+	{map[string]string{
+		"tableName": testAWSDynamoDBStreamsTableName,
+		"awsRegion": "eu-west-1"},
+		map[string]string{
+			"awsAccessKeyId": testAWSDynamoDBStreamsAccessKeyID,
+		},
+		true,
+		"with AWS credentials missing secret"},
+	{map[string]string{
+		"tableName": testAWSDynamoDBStreamsTableName,
+		"awsRegion": "eu-west-1"},
+		map[string]string{
+			"awsRoleArn": testAWSDynamoDBStreamsRoleArn,
+		},
+		false,
+		"with AWS role ARN"},
+	{map[string]string{
+		"tableName":     testAWSDynamoDBStreamsTableName,
+		"awsRegion":     "eu-west-1",
+		"identityOwner": "operator"},
+		map[string]string{},
+		false,
+		"with operator identity"},
+}
+
+var awsDynamoDBStreamsMetricIdentifiers = []awsDynamoDBStreamsMetricIdentifier{
+	{&testAwsDynamoDBStreamsMetadata[1], 0, "s0-aws-dynamodb-streams-test"},
+	{&testAwsDynamoDBStreamsMetadata[1], 1, "s1-aws-dynamodb-streams-test"},
+}
+
+func TestParseAwsDynamoDBStreamsMetadata(t *testing.T) {
+	for _, testData := range testAwsDynamoDBStreamsMetadata {
+		_, err := parseAwsDynamoDBStreamsMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, ResolvedEnv: map[string]string{}, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Errorf("Expected success because %s got error, %s", testData.comment, err)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Expected error because %s but got success, %#v", testData.comment, testData)
+		}
+	}
+}
+
+func TestAwsDynamoDBStreamsGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range awsDynamoDBStreamsMetricIdentifiers {
+		ctx := context.Background()
+		meta, err := parseAwsDynamoDBStreamsMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, ResolvedEnv: map[string]string{}, AuthParams: testData.metadataTestData.authParams, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockAwsDynamoDBStreamsScaler := awsDynamoDBStreamsScaler{meta, aws.String(testAWSDynamoDBStreamsArn), &mockAwsDynamoDBStreams{}}
+
+		metricSpec := mockAwsDynamoDBStreamsScaler.GetMetricSpecForScaling(ctx)
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Error("Wrong External metric source name:", metricName)
+		}
+	}
+}
+
+func TestGetDynamoDBStreamsArn(t *testing.T) {
+	ctx := context.Background()
+	arn, err := getDynamoDBStreamsArn(ctx, &mockAwsDynamoDB{}, aws.String(testAWSDynamoDBStreamsTableName))
+	assert.NoError(t, err)
+	assert.Equal(t, testAWSDynamoDBStreamsArn, *arn)
+
+	_, err = getDynamoDBStreamsArn(ctx, &mockAwsDynamoDB{}, aws.String(testAWSDynamoDBStreamsNoStreamTable))
+	assert.Error(t, err)
+
+	_, err = getDynamoDBStreamsArn(ctx, &mockAwsDynamoDB{}, aws.String(testAWSDynamoDBStreamsErrorTable))
+	assert.Error(t, err)
+}
+
+func TestAwsDynamoDBStreamsScalerGetMetrics(t *testing.T) {
+	var tests = []struct {
+		shards   int
+		expected int64
+	}{
+		{0, 0},
+		{2, 2},
+		// shard-2 is closed
+		{5, 4},
+		{7, 5},
+	}
+	meta := &awsDynamoDBStreamsMetadata{tableName: testAWSDynamoDBStreamsTableName, activationTargetShardCount: 2}
+	for _, test := range tests {
+		scaler := awsDynamoDBStreamsScaler{meta, aws.String(testAWSDynamoDBStreamsArn), &mockAwsDynamoDBStreams{shards: test.shards}}
+
+		value, err := scaler.GetMetrics(context.Background(), "MetricName", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, test.expected, value[0].Value.Value(), "shards: %d", test.shards)
+
+		active, err := scaler.IsActive(context.Background())
+		assert.NoError(t, err)
+		assert.Equal(t, test.expected > 2, active, "shards: %d", test.shards)
+	}
+
+	scaler := awsDynamoDBStreamsScaler{meta, aws.String(testAWSDynamoDBStreamsErrorArn), &mockAwsDynamoDBStreams{}}
+	_, err := scaler.GetMetrics(context.Background(), "MetricName", nil)
+	assert.Error(t, err)
+}
@@ -1,3 +1,6 @@
+//go:build !selective_scalers || scalers_aws || scalers_database
+// +build !selective_scalers scalers_aws scalers_database
+
 package scalers
 
 import "fmt"
@@ -1,3 +1,6 @@
+//go:build !selective_scalers || scalers_aws
+// +build !selective_scalers scalers_aws
+
 package scalers
 
 import (
@@ -8,8 +11,6 @@ import (
 	"github.com/aws/aws-sdk-go/aws/credentials"
 	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 
-	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/kinesis"
 	"github.com/aws/aws-sdk-go/service/kinesis/kinesisiface"
 	v2beta2 "k8s.io/api/autoscaling/v2beta2"
@@ -35,6 +36,7 @@ type awsKinesisStreamMetadata struct {
 	targetShardCount int
 	streamName       string
 	awsRegion        string
+	awsEndpoint      string
 	awsAuthorization awsAuthorizationMetadata
 	scalerIndex      int
 }
@@ -80,11 +82,21 @@ func parseAwsKinesisStreamMetadata(config *ScalerConfig) (*awsKinesisStreamMetad
 		return nil, fmt.Errorf("no awsRegion given")
 	}
 
+	endpoint, err := getAwsEndpoint(config.TriggerMetadata)
+	if err != nil {
+		return nil, err
+	}
+	meta.awsEndpoint = endpoint
+
 	auth, err := getAwsAuthorization(config.AuthParams, config.TriggerMetadata, config.ResolvedEnv)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := validateAwsPartition(config.TriggerMetadata, meta.awsRegion, auth); err != nil {
+		return nil, err
+	}
+
 	meta.awsAuthorization = auth
 
 	meta.scalerIndex = config.ScalerIndex
@@ -93,9 +105,7 @@ func parseAwsKinesisStreamMetadata(config *ScalerConfig) (*awsKinesisStreamMetad
 }
 
 func createKinesisClient(metadata *awsKinesisStreamMetadata) *kinesis.Kinesis {
-	sess := session.Must(session.NewSession(&aws.Config{
-		Region: aws.String(metadata.awsRegion),
-	}))
+	sess := newAwsSession(metadata.awsRegion)
 
 	var kinesisClinent *kinesis.Kinesis
 	if metadata.awsAuthorization.podIdentityOwner {
@@ -105,21 +115,16 @@ func createKinesisClient(metadata *awsKinesisStreamMetadata) *kinesis.Kinesis {
 			creds = stscreds.NewCredentials(sess, metadata.awsAuthorization.awsRoleArn)
 		}
 
-		kinesisClinent = kinesis.New(sess, &aws.Config{
-			Region:      aws.String(metadata.awsRegion),
-			Credentials: creds,
-		})
+		kinesisClinent = kinesis.New(sess, newAwsConfig(metadata.awsRegion, metadata.awsEndpoint).WithCredentials(creds))
 	} else {
-		kinesisClinent = kinesis.New(sess, &aws.Config{
-			Region: aws.String(metadata.awsRegion),
-		})
+		kinesisClinent = kinesis.New(sess, newAwsConfig(metadata.awsRegion, metadata.awsEndpoint))
 	}
 	return kinesisClinent
 }
 
 // IsActive determines if we need to scale from zero
 func (s *awsKinesisStreamScaler) IsActive(ctx context.Context) (bool, error) {
-	count, err := s.GetAwsKinesisOpenShardCount()
+	count, err := s.GetAwsKinesisOpenShardCount(ctx)
 
 	if err != nil {
 		return false, err
@@ -149,7 +154,7 @@ func (s *awsKinesisStreamScaler) GetMetricSpecForScaling(context.Context) []v2be
 
 // GetMetrics returns value for a supported metric and an error if there is a problem getting the metric
 func (s *awsKinesisStreamScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
-	shardCount, err := s.GetAwsKinesisOpenShardCount()
+	shardCount, err := s.GetAwsKinesisOpenShardCount(ctx)
 
 	if err != nil {
 		kinesisStreamLog.Error(err, "Error getting shard count")
@@ -166,12 +171,12 @@ func (s *awsKinesisStreamScaler) GetMetrics(ctx context.Context, metricName stri
 }
 
 // Get Kinesis open shard count
-func (s *awsKinesisStreamScaler) GetAwsKinesisOpenShardCount() (int64, error) {
+func (s *awsKinesisStreamScaler) GetAwsKinesisOpenShardCount(ctx context.Context) (int64, error) {
 	input := &kinesis.DescribeStreamSummaryInput{
 		StreamName: &s.metadata.streamName,
 	}
 
-	output, err := s.kinesisClient.DescribeStreamSummary(input)
+	output, err := s.kinesisClient.DescribeStreamSummaryWithContext(ctx, input)
 	if err != nil {
 		return -1, err
 	}
@@ -1,3 +1,6 @@
+//go:build !selective_scalers || scalers_aws
+// +build !selective_scalers scalers_aws
+
 package scalers
 
 import (
@@ -7,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/kinesis"
 	"github.com/aws/aws-sdk-go/service/kinesis/kinesisiface"
 	"github.com/stretchr/testify/assert"
@@ -14,7 +18,7 @@ import (
 )
 
 const (
-	testAWSKinesisRoleArn         = "none"
+	testAWSKinesisRoleArn         = "arn:aws:iam::123456789012:role/keda"
 	testAWSKinesisAccessKeyID     = "none"
 	testAWSKinesisSecretAccessKey = "none"
 	testAWSKinesisStreamName      = "test"
@@ -46,7 +50,7 @@ type mockKinesis struct {
 	kinesisiface.KinesisAPI
 }
 
-func (m *mockKinesis) DescribeStreamSummary(input *kinesis.DescribeStreamSummaryInput) (*kinesis.DescribeStreamSummaryOutput, error) {
+func (m *mockKinesis) DescribeStreamSummaryWithContext(_ aws.Context, input *kinesis.DescribeStreamSummaryInput, _ ...request.Option) (*kinesis.DescribeStreamSummaryOutput, error) {
 	if *input.StreamName == "Error" {
 		return nil, errors.New("some error")
 	}
@@ -217,6 +221,39 @@ var testAWSKinesisMetadata = []parseAWSKinesisMetadataTestData{
 		comment:     "with AWS Role assigned on KEDA operator itself",
 		scalerIndex: 8,
 	},
+	{metadata: map[string]string{
+		"streamName":  testAWSKinesisStreamName,
+		"shardCount":  "2",
+		"awsRegion":   testAWSRegion,
+		"awsEndpoint": "http://localstack:4566/"},
+		authParams: testAWSKinesisAuthentication,
+		expected: &awsKinesisStreamMetadata{
+			targetShardCount: 2,
+			streamName:       testAWSKinesisStreamName,
+			awsRegion:        testAWSRegion,
+			awsEndpoint:      "http://localstack:4566",
+			awsAuthorization: awsAuthorizationMetadata{
+				awsAccessKeyID:     testAWSKinesisAccessKeyID,
+				awsSecretAccessKey: testAWSKinesisSecretAccessKey,
+				podIdentityOwner:   true,
+			},
+			scalerIndex: 9,
+		},
+		isError:     false,
+		comment:     "with custom AWS endpoint",
+		scalerIndex: 9,
+	},
+	{metadata: map[string]string{
+		"streamName":  testAWSKinesisStreamName,
+		"shardCount":  "2",
+		"awsRegion":   testAWSRegion,
+		"awsEndpoint": "localstack"},
+		authParams:  testAWSKinesisAuthentication,
+		expected:    &awsKinesisStreamMetadata{},
+		isError:     true,
+		comment:     "with malformed AWS endpoint",
+		scalerIndex: 10,
+	},
 }
 
 var awsKinesisMetricIdentifiers = []awsKinesisMetricIdentifier{
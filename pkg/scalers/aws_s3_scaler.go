@@ -0,0 +1,234 @@
+//go:build !selective_scalers || scalers_aws
+// +build !selective_scalers scalers_aws
+
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	targetObjectCountDefault = 10
+	// maxObjectCountDefault limits the objects listed on every poll, listing a page of up to 1000 objects is a request
+	maxObjectCountDefault = 10000
+)
+
+type awsS3Scaler struct {
+	metadata *awsS3Metadata
+	s3Client s3iface.S3API
+}
+
+type awsS3Metadata struct {
+	targetObjectCount int64
+	maxObjectCount    int64
+	bucketName        string
+	prefix            string
+	awsRegion         string
+	awsEndpoint       string
+	forcePathStyle    bool
+	awsAuthorization  awsAuthorizationMetadata
+	scalerIndex       int
+}
+
+var s3Log = logf.Log.WithName("aws_s3_scaler")
+
+// NewAwsS3Scaler creates a new awsS3Scaler
+func NewAwsS3Scaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseAwsS3Metadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing S3 metadata: %s", err)
+	}
+
+	return &awsS3Scaler{
+		metadata: meta,
+		s3Client: createS3Client(meta),
+	}, nil
+}
+
+func parseAwsS3Metadata(config *ScalerConfig) (*awsS3Metadata, error) {
+	meta := awsS3Metadata{}
+	meta.targetObjectCount = targetObjectCountDefault
+
+	if val, ok := config.TriggerMetadata["objectCount"]; ok && val != "" {
+		objectCount, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing objectCount: %s", err)
+		}
+		meta.targetObjectCount = objectCount
+	}
+
+	meta.maxObjectCount = maxObjectCountDefault
+	if val, ok := config.TriggerMetadata["maxObjectCount"]; ok && val != "" {
+		maxObjectCount, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing maxObjectCount: %s", err)
+		}
+		if maxObjectCount < 0 {
+			return nil, fmt.Errorf("maxObjectCount can't be negative")
+		}
+		meta.maxObjectCount = maxObjectCount
+	}
+
+	if val, ok := config.TriggerMetadata["bucketName"]; ok && val != "" {
+		meta.bucketName = val
+	} else {
+		return nil, fmt.Errorf("no bucketName given")
+	}
+
+	meta.prefix = config.TriggerMetadata["prefix"]
+
+	if val, ok := config.TriggerMetadata["awsRegion"]; ok && val != "" {
+		meta.awsRegion = val
+	} else {
+		return nil, fmt.Errorf("no awsRegion given")
+	}
+
+	endpoint, err := getAwsEndpoint(config.TriggerMetadata)
+	if err != nil {
+		return nil, err
+	}
+	meta.awsEndpoint = endpoint
+
+	// S3-compatible stores usually don't resolve the bucket as a subdomain, so path-style addressing
+	// is the default with a custom endpoint
+	meta.forcePathStyle = meta.awsEndpoint != ""
+	if val, ok := config.TriggerMetadata["forcePathStyle"]; ok && val != "" {
+		forcePathStyle, err := strconv.ParseBool(val)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing forcePathStyle: %s", err)
+		}
+		meta.forcePathStyle = forcePathStyle
+	}
+
+	auth, err := getAwsAuthorization(config.AuthParams, config.TriggerMetadata, config.ResolvedEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateAwsPartition(config.TriggerMetadata, meta.awsRegion, auth); err != nil {
+		return nil, err
+	}
+
+	meta.awsAuthorization = auth
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+func createS3Client(metadata *awsS3Metadata) *s3.S3 {
+	sess := newAwsSession(metadata.awsRegion)
+
+	config := newAwsConfig(metadata.awsRegion, metadata.awsEndpoint).WithS3ForcePathStyle(metadata.forcePathStyle)
+	if metadata.awsAuthorization.podIdentityOwner {
+		creds := credentials.NewStaticCredentials(metadata.awsAuthorization.awsAccessKeyID, metadata.awsAuthorization.awsSecretAccessKey, "")
+
+		if metadata.awsAuthorization.awsRoleArn != "" {
+			creds = stscreds.NewCredentials(sess, metadata.awsAuthorization.awsRoleArn)
+		}
+
+		config = config.WithCredentials(creds)
+	}
+	return s3.New(sess, config)
+}
+
+// IsActive determines if we need to scale from zero
+func (s *awsS3Scaler) IsActive(ctx context.Context) (bool, error) {
+	count, err := s.GetAwsS3ObjectCount(ctx)
+
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (s *awsS3Scaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *awsS3Scaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetObjectCountQty := resource.NewQuantity(s.metadata.targetObjectCount, resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(s.metricName())),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetObjectCountQty,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{External: externalMetric, Type: externalMetricType}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+func (s *awsS3Scaler) metricName() string {
+	if s.metadata.prefix != "" {
+		return fmt.Sprintf("aws-s3-%s-%s", s.metadata.bucketName, s.metadata.prefix)
+	}
+	return fmt.Sprintf("aws-s3-%s", s.metadata.bucketName)
+}
+
+// GetMetrics returns value for a supported metric and an error if there is a problem getting the metric
+func (s *awsS3Scaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	count, err := s.GetAwsS3ObjectCount(ctx)
+
+	if err != nil {
+		s3Log.Error(err, "Error getting object count")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewQuantity(count, resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
+
+// GetAwsS3ObjectCount counts the objects of the bucket under the prefix, folder placeholder objects are skipped.
+// The listing stops at maxObjectCount (0 for no limit), so large buckets aren't paged through on every poll
+func (s *awsS3Scaler) GetAwsS3ObjectCount(ctx context.Context) (int64, error) {
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(s.metadata.bucketName),
+	}
+	if s.metadata.prefix != "" {
+		input.Prefix = aws.String(s.metadata.prefix)
+	}
+
+	var count int64
+	err := s.s3Client.ListObjectsV2PagesWithContext(ctx, input, func(page *s3.ListObjectsV2Output, lastPage bool) bool {
+		for _, object := range page.Contents {
+			if !strings.HasSuffix(aws.StringValue(object.Key), "/") {
+				count++
+			}
+			if s.metadata.maxObjectCount > 0 && count >= s.metadata.maxObjectCount {
+				return false
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return -1, err
+	}
+
+	return count, nil
+}
@@ -0,0 +1,228 @@
+//go:build !selective_scalers || scalers_aws
+// +build !selective_scalers scalers_aws
+
+package scalers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/s3"
+	"github.com/aws/aws-sdk-go/service/s3/s3iface"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+const (
+	testAWSS3AccessKeyID     = "none"
+	testAWSS3SecretAccessKey = "none"
+
+	testAWSS3BucketName  = "uploads"
+	testAWSS3ErrorBucket = "error"
+)
+
+var testAWSS3Authentication = map[string]string{
+	"awsAccessKeyId":     testAWSS3AccessKeyID,
+	"awsSecretAccessKey": testAWSS3SecretAccessKey,
+}
+
+type parseAWSS3MetadataTestData struct {
+	metadata       map[string]string
+	authParams     map[string]string
+	isError        bool
+	forcePathStyle bool
+	comment        string
+}
+
+type awsS3MetricIdentifier struct {
+	metadataTestData *parseAWSS3MetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+type mockS3 struct {
+	s3iface.S3API
+}
+
+func (m *mockS3) ListObjectsV2PagesWithContext(ctx aws.Context, input *s3.ListObjectsV2Input, fn func(*s3.ListObjectsV2Output, bool) bool, opts ...request.Option) error {
+	if *input.Bucket == testAWSS3ErrorBucket {
+		return errors.New("some error")
+	}
+
+	pages := []*s3.ListObjectsV2Output{
+		{Contents: []*s3.Object{{Key: aws.String("in/")}, {Key: aws.String("in/a.csv")}, {Key: aws.String("in/b.csv")}}},
+		{Contents: []*s3.Object{{Key: aws.String("in/c.csv")}}},
+	}
+	for i, page := range pages {
+		if !fn(page, i == len(pages)-1) {
+			break
+		}
+	}
+	return nil
+}
+
+var testAWSS3Metadata = []parseAWSS3MetadataTestData{
+	{map[string]string{},
+		testAWSS3Authentication,
+		true,
+		false,
+		"metadata empty"},
+	{map[string]string{
+		"bucketName":  testAWSS3BucketName,
+		"objectCount": "5",
+		"awsRegion":   testAWSRegion},
+		testAWSS3Authentication,
+		false,
+		false,
+		"properly formed bucket name and region"},
+	{map[string]string{
+		"bucketName": testAWSS3BucketName,
+		"prefix":     "in/",
+		"awsRegion":  testAWSRegion},
+		testAWSS3Authentication,
+		false,
+		false,
+		"properly formed bucket name, prefix and region"},
+	{map[string]string{
+		"bucketName": "",
+		"awsRegion":  testAWSRegion},
+		testAWSS3Authentication,
+		true,
+		false,
+		"missing bucket name"},
+	{map[string]string{
+		"bucketName": testAWSS3BucketName,
+		"awsRegion":  ""},
+		testAWSS3Authentication,
+		true,
+		false,
+		"missing region"},
+	{map[string]string{
+		"bucketName":  testAWSS3BucketName,
+		"objectCount": "a",
+		"awsRegion":   testAWSRegion},
+		testAWSS3Authentication,
+		true,
+		false,
+		"wrong object count"},
+	{map[string]string{
+		"bucketName":     testAWSS3BucketName,
+		"maxObjectCount": "-1",
+		"awsRegion":      testAWSRegion},
+		testAWSS3Authentication,
+		true,
+		false,
+		"negative max object count"},
+	{map[string]string{
+		"bucketName":  testAWSS3BucketName,
+		"awsRegion":   testAWSRegion,
+		"awsEndpoint": "http://minio.minio:9000"},
+		testAWSS3Authentication,
+		false,
+		true,
+		"custom endpoint defaults to path-style addressing"},
+	{map[string]string{
+		"bucketName":     testAWSS3BucketName,
+		"awsRegion":      testAWSRegion,
+		"awsEndpoint":    "https://s3.us-west-002.backblazeb2.com",
+		"forcePathStyle": "false"},
+		testAWSS3Authentication,
+		false,
+		false,
+		"custom endpoint with virtual hosted-style addressing"},
+	{map[string]string{
+		"bucketName":     testAWSS3BucketName,
+		"awsRegion":      testAWSRegion,
+		"forcePathStyle": "maybe"},
+		testAWSS3Authentication,
+		true,
+		false,
+		"wrong forcePathStyle"},
+	{map[string]string{
+		"bucketName":  testAWSS3BucketName,
+		"awsRegion":   testAWSRegion,
+		"awsEndpoint": "minio:9000"},
+		testAWSS3Authentication,
+		true,
+		false,
+		"malformed endpoint"},
+	{map[string]string{
+		"bucketName":    testAWSS3BucketName,
+		"awsRegion":     testAWSRegion,
+		"identityOwner": "operator"},
+		map[string]string{},
+		false,
+		false,
+		"with AWS Role assigned on KEDA operator itself"},
+}
+
+var awsS3MetricIdentifiers = []awsS3MetricIdentifier{
+	{&testAWSS3Metadata[1], 0, "s0-aws-s3-uploads"},
+	{&testAWSS3Metadata[2], 1, "s1-aws-s3-uploads-in-"},
+}
+
+func TestS3ParseMetadata(t *testing.T) {
+	for _, testData := range testAWSS3Metadata {
+		meta, err := parseAwsS3Metadata(&ScalerConfig{TriggerMetadata: testData.metadata, ResolvedEnv: testAWSS3Authentication, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Errorf("Expected success because %s got error, %s", testData.comment, err)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Expected error because %s but got success, %#v", testData.comment, testData)
+		}
+		if err == nil && meta.forcePathStyle != testData.forcePathStyle {
+			t.Errorf("Expected forcePathStyle %v because %s but got %v", testData.forcePathStyle, testData.comment, meta.forcePathStyle)
+		}
+	}
+}
+
+func TestAWSS3GetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range awsS3MetricIdentifiers {
+		ctx := context.Background()
+		meta, err := parseAwsS3Metadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, ResolvedEnv: testAWSS3Authentication, AuthParams: testData.metadataTestData.authParams, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockAWSS3Scaler := awsS3Scaler{meta, &mockS3{}}
+
+		metricSpec := mockAWSS3Scaler.GetMetricSpecForScaling(ctx)
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Error("Wrong External metric source name:", metricName)
+		}
+	}
+}
+
+func TestAWSS3ScalerGetMetrics(t *testing.T) {
+	var selector labels.Selector
+	for _, meta := range []*awsS3Metadata{{bucketName: testAWSS3BucketName}, {bucketName: testAWSS3BucketName, maxObjectCount: 2}, {bucketName: testAWSS3ErrorBucket}} {
+		scaler := awsS3Scaler{meta, &mockS3{}}
+		value, err := scaler.GetMetrics(context.Background(), "MetricName", selector)
+		switch {
+		case meta.bucketName == testAWSS3ErrorBucket:
+			assert.Error(t, err, "expect error because of s3 api error")
+		case meta.maxObjectCount > 0:
+			assert.EqualValues(t, meta.maxObjectCount, value[0].Value.Value())
+		default:
+			assert.EqualValues(t, int64(3), value[0].Value.Value())
+		}
+	}
+}
+
+func TestAWSS3ClientEndpoint(t *testing.T) {
+	client := createS3Client(&awsS3Metadata{
+		bucketName:     testAWSS3BucketName,
+		awsRegion:      testAWSRegion,
+		awsEndpoint:    "http://minio.minio:9000",
+		forcePathStyle: true,
+	})
+	req, _ := client.ListObjectsV2Request(&s3.ListObjectsV2Input{Bucket: aws.String(testAWSS3BucketName)})
+	if err := req.Build(); err != nil {
+		t.Fatal("Could not build request:", err)
+	}
+	assert.Equal(t, "minio.minio:9000", req.HTTPRequest.URL.Host)
+	assert.Equal(t, "/uploads", req.HTTPRequest.URL.Path)
+}
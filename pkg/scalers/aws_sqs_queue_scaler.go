@@ -1,3 +1,6 @@
+//go:build !selective_scalers || scalers_aws
+// +build !selective_scalers scalers_aws
+
 package scalers
 
 import (
@@ -11,7 +14,6 @@ import (
 	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
 
 	"github.com/aws/aws-sdk-go/aws"
-	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/sqs"
 	"github.com/aws/aws-sdk-go/service/sqs/sqsiface"
 	v2beta2 "k8s.io/api/autoscaling/v2beta2"
@@ -46,6 +48,7 @@ type awsSqsQueueMetadata struct {
 	queueURL          string
 	queueName         string
 	awsRegion         string
+	awsEndpoint       string
 	awsAuthorization  awsAuthorizationMetadata
 	scalerIndex       int
 }
@@ -102,11 +105,21 @@ func parseAwsSqsQueueMetadata(config *ScalerConfig) (*awsSqsQueueMetadata, error
 		return nil, fmt.Errorf("no awsRegion given")
 	}
 
+	endpoint, err := getAwsEndpoint(config.TriggerMetadata)
+	if err != nil {
+		return nil, err
+	}
+	meta.awsEndpoint = endpoint
+
 	auth, err := getAwsAuthorization(config.AuthParams, config.TriggerMetadata, config.ResolvedEnv)
 	if err != nil {
 		return nil, err
 	}
 
+	if err := validateAwsPartition(config.TriggerMetadata, meta.awsRegion, auth); err != nil {
+		return nil, err
+	}
+
 	meta.awsAuthorization = auth
 
 	meta.scalerIndex = config.ScalerIndex
@@ -115,9 +128,7 @@ func parseAwsSqsQueueMetadata(config *ScalerConfig) (*awsSqsQueueMetadata, error
 }
 
 func createSqsClient(metadata *awsSqsQueueMetadata) *sqs.SQS {
-	sess := session.Must(session.NewSession(&aws.Config{
-		Region: aws.String(metadata.awsRegion),
-	}))
+	sess := newAwsSession(metadata.awsRegion)
 
 	var sqsClient *sqs.SQS
 	if metadata.awsAuthorization.podIdentityOwner {
@@ -127,21 +138,16 @@ func createSqsClient(metadata *awsSqsQueueMetadata) *sqs.SQS {
 			creds = stscreds.NewCredentials(sess, metadata.awsAuthorization.awsRoleArn)
 		}
 
-		sqsClient = sqs.New(sess, &aws.Config{
-			Region:      aws.String(metadata.awsRegion),
-			Credentials: creds,
-		})
+		sqsClient = sqs.New(sess, newAwsConfig(metadata.awsRegion, metadata.awsEndpoint).WithCredentials(creds))
 	} else {
-		sqsClient = sqs.New(sess, &aws.Config{
-			Region: aws.String(metadata.awsRegion),
-		})
+		sqsClient = sqs.New(sess, newAwsConfig(metadata.awsRegion, metadata.awsEndpoint))
 	}
 	return sqsClient
 }
 
 // IsActive determines if we need to scale from zero
 func (s *awsSqsQueueScaler) IsActive(ctx context.Context) (bool, error) {
-	length, err := s.GetAwsSqsQueueLength()
+	length, err := s.GetAwsSqsQueueLength(ctx)
 
 	if err != nil {
 		return false, err
@@ -171,7 +177,7 @@ func (s *awsSqsQueueScaler) GetMetricSpecForScaling(context.Context) []v2beta2.M
 
 // GetMetrics returns value for a supported metric and an error if there is a problem getting the metric
 func (s *awsSqsQueueScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
-	queuelen, err := s.GetAwsSqsQueueLength()
+	queuelen, err := s.GetAwsSqsQueueLength(ctx)
 
 	if err != nil {
 		sqsQueueLog.Error(err, "Error getting queue length")
@@ -188,13 +194,13 @@ func (s *awsSqsQueueScaler) GetMetrics(ctx context.Context, metricName string, m
 }
 
 // Get SQS Queue Length
-func (s *awsSqsQueueScaler) GetAwsSqsQueueLength() (int32, error) {
+func (s *awsSqsQueueScaler) GetAwsSqsQueueLength(ctx context.Context) (int32, error) {
 	input := &sqs.GetQueueAttributesInput{
 		AttributeNames: aws.StringSlice(awsSqsQueueMetricNames),
 		QueueUrl:       aws.String(s.metadata.queueURL),
 	}
 
-	output, err := s.sqsClient.GetQueueAttributes(input)
+	output, err := s.sqsClient.GetQueueAttributesWithContext(ctx, input)
 	if err != nil {
 		return -1, err
 	}
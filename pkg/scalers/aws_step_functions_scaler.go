@@ -0,0 +1,206 @@
+//go:build !selective_scalers || scalers_aws
+// +build !selective_scalers scalers_aws
+
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/arn"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	"github.com/aws/aws-sdk-go/service/sfn"
+	"github.com/aws/aws-sdk-go/service/sfn/sfniface"
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	defaultTargetStepFunctionsExecutionCount = 5
+)
+
+type awsStepFunctionsScaler struct {
+	metadata  *awsStepFunctionsMetadata
+	sfnClient sfniface.SFNAPI
+}
+
+type awsStepFunctionsMetadata struct {
+	stateMachineArn                string
+	stateMachineName               string
+	targetExecutionCount           int64
+	activationTargetExecutionCount int64
+	awsRegion                      string
+	awsEndpoint                    string
+	awsAuthorization               awsAuthorizationMetadata
+	scalerIndex                    int
+}
+
+var stepFunctionsLog = logf.Log.WithName("aws_step_functions_scaler")
+
+// NewAwsStepFunctionsScaler creates a new awsStepFunctionsScaler
+func NewAwsStepFunctionsScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseAwsStepFunctionsMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing Step Functions metadata: %s", err)
+	}
+
+	return &awsStepFunctionsScaler{
+		metadata:  meta,
+		sfnClient: createStepFunctionsClient(meta),
+	}, nil
+}
+
+func parseAwsStepFunctionsMetadata(config *ScalerConfig) (*awsStepFunctionsMetadata, error) {
+	meta := awsStepFunctionsMetadata{}
+	meta.targetExecutionCount = defaultTargetStepFunctionsExecutionCount
+
+	if val, ok := config.TriggerMetadata["stateMachineArn"]; ok && val != "" {
+		// eg. arn:aws:states:eu-west-1:123456789012:stateMachine:orders
+		stateMachineArn, err := arn.Parse(val)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing stateMachineArn: %s", err)
+		}
+		resourceParts := strings.SplitN(stateMachineArn.Resource, ":", 2)
+		if stateMachineArn.Service != "states" || len(resourceParts) != 2 || resourceParts[0] != "stateMachine" || resourceParts[1] == "" {
+			return nil, fmt.Errorf("stateMachineArn %s is not the ARN of a state machine", val)
+		}
+		meta.stateMachineArn = val
+		meta.stateMachineName = resourceParts[1]
+	} else {
+		return nil, fmt.Errorf("no stateMachineArn given")
+	}
+
+	if val, ok := config.TriggerMetadata["executionCount"]; ok && val != "" {
+		executionCount, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing executionCount: %s", err)
+		}
+		meta.targetExecutionCount = executionCount
+	}
+
+	if val, ok := config.TriggerMetadata["activationExecutionCount"]; ok && val != "" {
+		activationExecutionCount, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing activationExecutionCount: %s", err)
+		}
+		meta.activationTargetExecutionCount = activationExecutionCount
+	}
+
+	if val, ok := config.TriggerMetadata["awsRegion"]; ok && val != "" {
+		meta.awsRegion = val
+	} else {
+		return nil, fmt.Errorf("no awsRegion given")
+	}
+
+	endpoint, err := getAwsEndpoint(config.TriggerMetadata)
+	if err != nil {
+		return nil, err
+	}
+	meta.awsEndpoint = endpoint
+
+	auth, err := getAwsAuthorization(config.AuthParams, config.TriggerMetadata, config.ResolvedEnv)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := validateAwsPartition(config.TriggerMetadata, meta.awsRegion, auth); err != nil {
+		return nil, err
+	}
+
+	meta.awsAuthorization = auth
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+func createStepFunctionsClient(metadata *awsStepFunctionsMetadata) *sfn.SFN {
+	sess := newAwsSession(metadata.awsRegion)
+
+	config := newAwsConfig(metadata.awsRegion, metadata.awsEndpoint)
+	if metadata.awsAuthorization.podIdentityOwner {
+		creds := credentials.NewStaticCredentials(metadata.awsAuthorization.awsAccessKeyID, metadata.awsAuthorization.awsSecretAccessKey, "")
+
+		if metadata.awsAuthorization.awsRoleArn != "" {
+			creds = stscreds.NewCredentials(sess, metadata.awsAuthorization.awsRoleArn)
+		}
+
+		config = config.WithCredentials(creds)
+	}
+	return sfn.New(sess, config)
+}
+
+// IsActive determines if we need to scale from zero
+func (s *awsStepFunctionsScaler) IsActive(ctx context.Context) (bool, error) {
+	count, err := s.GetRunningExecutionCount(ctx)
+	if err != nil {
+		stepFunctionsLog.Error(err, "Error getting running executions", "stateMachineArn", s.metadata.stateMachineArn)
+		return false, err
+	}
+
+	return count > s.metadata.activationTargetExecutionCount, nil
+}
+
+func (s *awsStepFunctionsScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *awsStepFunctionsScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetExecutionCountQty := resource.NewQuantity(s.metadata.targetExecutionCount, resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(fmt.Sprintf("aws-step-functions-%s", s.metadata.stateMachineName))),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetExecutionCountQty,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{External: externalMetric, Type: externalMetricType}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// GetMetrics returns value for a supported metric and an error if there is a problem getting the metric
+func (s *awsStepFunctionsScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	count, err := s.GetRunningExecutionCount(ctx)
+	if err != nil {
+		stepFunctionsLog.Error(err, "Error getting running executions", "stateMachineArn", s.metadata.stateMachineArn)
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewQuantity(count, resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
+
+// GetRunningExecutionCount returns the number of RUNNING executions of the state machine over all the pages,
+// the executions waiting on activity tasks are the backlog of the activity workers
+func (s *awsStepFunctionsScaler) GetRunningExecutionCount(ctx context.Context) (int64, error) {
+	var count int64
+	input := &sfn.ListExecutionsInput{
+		StateMachineArn: aws.String(s.metadata.stateMachineArn),
+		StatusFilter:    aws.String(sfn.ExecutionStatusRunning),
+	}
+	err := s.sfnClient.ListExecutionsPagesWithContext(ctx, input, func(page *sfn.ListExecutionsOutput, lastPage bool) bool {
+		count += int64(len(page.Executions))
+		return true
+	})
+	if err != nil {
+		return -1, err
+	}
+	return count, nil
+}
@@ -0,0 +1,174 @@
+//go:build !selective_scalers || scalers_aws
+// +build !selective_scalers scalers_aws
+
+package scalers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/sfn"
+	"github.com/aws/aws-sdk-go/service/sfn/sfniface"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testAWSStepFunctionsRoleArn         = "arn:aws:iam::123456789012:role/keda"
+	testAWSStepFunctionsStateMachineArn = "arn:aws:states:eu-west-1:123456789012:stateMachine:orders"
+	testAWSStepFunctionsErrorArn        = "arn:aws:states:eu-west-1:123456789012:stateMachine:error"
+)
+
+var testAWSStepFunctionsAuthentication = map[string]string{
+	"awsAccessKeyId":     "none",
+	"awsSecretAccessKey": "none",
+}
+
+type parseAWSStepFunctionsMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+	comment    string
+}
+
+type awsStepFunctionsMetricIdentifier struct {
+	metadataTestData *parseAWSStepFunctionsMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+type mockSfn struct {
+	sfniface.SFNAPI
+}
+
+// ListExecutionsPagesWithContext returns the running executions in two pages
+func (m *mockSfn) ListExecutionsPagesWithContext(_ aws.Context, input *sfn.ListExecutionsInput, fn func(*sfn.ListExecutionsOutput, bool) bool, _ ...request.Option) error {
+	if *input.StateMachineArn == testAWSStepFunctionsErrorArn {
+		return errors.New("some error")
+	}
+	if *input.StatusFilter != sfn.ExecutionStatusRunning {
+		return errors.New("only running executions are counted")
+	}
+	if fn(&sfn.ListExecutionsOutput{Executions: make([]*sfn.ExecutionListItem, 100), NextToken: aws.String("1")}, false) {
+		fn(&sfn.ListExecutionsOutput{Executions: make([]*sfn.ExecutionListItem, 3)}, true)
+	}
+	return nil
+}
+
+var testAWSStepFunctionsMetadata = []parseAWSStepFunctionsMetadataTestData{
+	{map[string]string{}, testAWSStepFunctionsAuthentication, true, "metadata empty"},
+	{map[string]string{
+		"stateMachineArn":          testAWSStepFunctionsStateMachineArn,
+		"executionCount":           "10",
+		"activationExecutionCount": "2",
+		"awsRegion":                "eu-west-1"},
+		testAWSStepFunctionsAuthentication,
+		false,
+		"properly formed state machine ARN"},
+	{map[string]string{
+		"stateMachineArn": testAWSStepFunctionsStateMachineArn,
+		"awsRegion":       "eu-west-1"},
+		testAWSStepFunctionsAuthentication,
+		false,
+		"default executionCount"},
+	{map[string]string{
+		"stateMachineArn": "orders",
+		"awsRegion":       "eu-west-1"},
+		testAWSStepFunctionsAuthentication,
+		true,
+		"stateMachineArn is not an ARN"},
+	{map[string]string{
+		"stateMachineArn": "arn:aws:states:eu-west-1:123456789012:activity:approve",
+		"awsRegion":       "eu-west-1"},
+		testAWSStepFunctionsAuthentication,
+		true,
+		"stateMachineArn is the ARN of an activity"},
+	{map[string]string{
+		"stateMachineArn": testAWSStepFunctionsStateMachineArn,
+		"executionCount":  "a",
+		"awsRegion":       "eu-west-1"},
+		testAWSStepFunctionsAuthentication,
+		true,
+		"invalid executionCount"},
+	{map[string]string{
+		"stateMachineArn":          testAWSStepFunctionsStateMachineArn,
+		"activationExecutionCount": "a",
+		"awsRegion":                "eu-west-1"},
+		testAWSStepFunctionsAuthentication,
+		true,
+		"invalid activationExecutionCount"},
+	{map[string]string{
+		"stateMachineArn": testAWSStepFunctionsStateMachineArn},
+		testAWSStepFunctionsAuthentication,
+		true,
+		"missing awsRegion"},
+	{map[string]string{
+		"stateMachineArn": testAWSStepFunctionsStateMachineArn,
+		"awsRegion":       "eu-west-1"},
+		map[string]string{
+			"awsAccessKeyId": "none",
+		},
+		true,
+		"with AWS credentials missing secret"},
+	{map[string]string{
+		"stateMachineArn": testAWSStepFunctionsStateMachineArn,
+		"awsRegion":       "eu-west-1"},
+		map[string]string{
+			"awsRoleArn": testAWSStepFunctionsRoleArn,
+		},
+		false,
+		"with AWS role ARN"},
+}
+
+var awsStepFunctionsMetricIdentifiers = []awsStepFunctionsMetricIdentifier{
+	{&testAWSStepFunctionsMetadata[1], 0, "s0-aws-step-functions-orders"},
+	{&testAWSStepFunctionsMetadata[1], 1, "s1-aws-step-functions-orders"},
+}
+
+func TestAWSStepFunctionsParseMetadata(t *testing.T) {
+	for _, testData := range testAWSStepFunctionsMetadata {
+		_, err := parseAwsStepFunctionsMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, ResolvedEnv: map[string]string{}, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Errorf("Expected success because %s got error, %s", testData.comment, err)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Expected error because %s but got success, %#v", testData.comment, testData)
+		}
+	}
+}
+
+func TestAWSStepFunctionsGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range awsStepFunctionsMetricIdentifiers {
+		ctx := context.Background()
+		meta, err := parseAwsStepFunctionsMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, ResolvedEnv: map[string]string{}, AuthParams: testData.metadataTestData.authParams, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockAWSStepFunctionsScaler := awsStepFunctionsScaler{meta, &mockSfn{}}
+
+		metricSpec := mockAWSStepFunctionsScaler.GetMetricSpecForScaling(ctx)
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Error("Wrong External metric source name:", metricName)
+		}
+	}
+}
+
+func TestAWSStepFunctionsGetMetrics(t *testing.T) {
+	ctx := context.Background()
+	scaler := awsStepFunctionsScaler{&awsStepFunctionsMetadata{stateMachineArn: testAWSStepFunctionsStateMachineArn, activationTargetExecutionCount: 200}, &mockSfn{}}
+
+	value, err := scaler.GetMetrics(ctx, "MetricName", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(103), value[0].Value.Value())
+
+	active, err := scaler.IsActive(ctx)
+	assert.NoError(t, err)
+	assert.False(t, active)
+
+	scaler.metadata.stateMachineArn = testAWSStepFunctionsErrorArn
+	_, err = scaler.GetMetrics(ctx, "MetricName", nil)
+	assert.Error(t, err)
+}
@@ -7,9 +7,31 @@ import (
 	az "github.com/Azure/go-autorest/autorest/azure"
 )
 
+const (
+	// ActiveDirectoryEndpointKey overrides the Azure Active Directory endpoint of the cloud
+	ActiveDirectoryEndpointKey = "activeDirectoryEndpoint"
+	// ResourceManagerEndpointKey overrides the Azure Resource Manager endpoint of the cloud
+	ResourceManagerEndpointKey = "resourceManagerEndpoint"
+	// EndpointSuffixKey is the endpoint suffix of a private cloud
+	EndpointSuffixKey = "endpointSuffix"
+)
+
 // EnvironmentSuffixProvider for different types of Azure scalers
 type EnvironmentSuffixProvider func(env az.Environment) (string, error)
 
+// EnvironmentFromName returns the environment of a cloud, the Cloud suffix can be omitted
+// so AzurePublic, AzureUSGovernment and AzureChina are accepted too
+func EnvironmentFromName(name string) (az.Environment, error) {
+	if !strings.HasSuffix(strings.ToLower(name), "cloud") {
+		name += "Cloud"
+	}
+	env, err := az.EnvironmentFromName(name)
+	if err != nil {
+		return env, fmt.Errorf("invalid cloud environment %s", name)
+	}
+	return env, nil
+}
+
 // ParseEndpointSuffix parses cloud and endpointSuffix metadata and returns the resolved endpoint suffix
 func ParseEndpointSuffix(metadata map[string]string, suffixProvider EnvironmentSuffixProvider) (string, error) {
 	if val, ok := metadata["cloud"]; ok && val != "" {
@@ -20,9 +42,9 @@ func ParseEndpointSuffix(metadata map[string]string, suffixProvider EnvironmentS
 			return "", fmt.Errorf("endpointSuffix must be provided for %s cloud type", PrivateCloud)
 		}
 
-		env, err := az.EnvironmentFromName(val)
+		env, err := EnvironmentFromName(val)
 		if err != nil {
-			return "", fmt.Errorf("invalid cloud environment %s", val)
+			return "", err
 		}
 
 		return suffixProvider(env)
@@ -31,3 +53,50 @@ func ParseEndpointSuffix(metadata map[string]string, suffixProvider EnvironmentS
 	// Use public cloud suffix if `cloud` isn't specified
 	return suffixProvider(az.PublicCloud)
 }
+
+// ParseEnvironment parses cloud metadata and returns the environment of the cloud, activeDirectoryEndpoint
+// and resourceManagerEndpoint override its endpoints. A private cloud only has the endpoints given in the
+// metadata, its endpointSuffix is the Service Bus endpoint suffix
+func ParseEnvironment(metadata map[string]string) (az.Environment, error) {
+	env := az.PublicCloud
+	if val, ok := metadata["cloud"]; ok && val != "" {
+		if strings.EqualFold(val, PrivateCloud) {
+			env = az.Environment{Name: PrivateCloud, ServiceBusEndpointSuffix: metadata[EndpointSuffixKey]}
+		} else {
+			var err error
+			env, err = EnvironmentFromName(val)
+			if err != nil {
+				return env, err
+			}
+		}
+	}
+
+	if val, ok := metadata[ActiveDirectoryEndpointKey]; ok && val != "" {
+		env.ActiveDirectoryEndpoint = strings.TrimSuffix(val, "/") + "/"
+	}
+	if val, ok := metadata[ResourceManagerEndpointKey]; ok && val != "" {
+		env.ResourceManagerEndpoint = strings.TrimSuffix(val, "/") + "/"
+		env.TokenAudience = env.ResourceManagerEndpoint
+	}
+
+	return env, nil
+}
+
+// RequireEnvironmentEndpoints returns an error naming the first of the endpoint keys missing in a private cloud environment
+func RequireEnvironmentEndpoints(env az.Environment, keys ...string) error {
+	for _, key := range keys {
+		var endpoint string
+		switch key {
+		case ActiveDirectoryEndpointKey:
+			endpoint = env.ActiveDirectoryEndpoint
+		case ResourceManagerEndpointKey:
+			endpoint = env.ResourceManagerEndpoint
+		case EndpointSuffixKey:
+			endpoint = env.ServiceBusEndpointSuffix
+		}
+		if endpoint == "" {
+			return fmt.Errorf("%s must be provided for %s cloud type", key, PrivateCloud)
+		}
+	}
+	return nil
+}
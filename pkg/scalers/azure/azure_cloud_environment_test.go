@@ -29,6 +29,7 @@ var parseEndpointSuffixTestDataset = []parseEndpointSuffixTestData{
 	{map[string]string{"cloud": "Private"}, "", testSuffixProvider, true},
 	{map[string]string{"cloud": "Private", "endpointSuffix": "suffix.private.cloud"}, "suffix.private.cloud", testSuffixProvider, false},
 	{map[string]string{"endpointSuffix": "ignored"}, "AzurePublicCloud.suffix", testSuffixProvider, false},
+	{map[string]string{"cloud": "AzureChina"}, "AzureChinaCloud.suffix", testSuffixProvider, false},
 }
 
 func TestParseEndpointSuffix(t *testing.T) {
@@ -50,3 +51,50 @@ func TestParseEndpointSuffix(t *testing.T) {
 		}
 	}
 }
+
+type parseEnvironmentTestData struct {
+	metadata                map[string]string
+	name                    string
+	activeDirectoryEndpoint string
+	resourceManagerEndpoint string
+	serviceBusSuffix        string
+	isError                 bool
+}
+
+var parseEnvironmentTestDataset = []parseEnvironmentTestData{
+	{map[string]string{}, "AzurePublicCloud", "https://login.microsoftonline.com/", "https://management.azure.com/", "servicebus.windows.net", false},
+	{map[string]string{"cloud": "AzureUSGovernmentCloud"}, "AzureUSGovernmentCloud", "https://login.microsoftonline.us/", "https://management.usgovcloudapi.net/", "servicebus.usgovcloudapi.net", false},
+	{map[string]string{"cloud": "AzureChina"}, "AzureChinaCloud", "https://login.chinacloudapi.cn/", "https://management.chinacloudapi.cn/", "servicebus.chinacloudapi.cn", false},
+	{map[string]string{"cloud": "Invalid"}, "", "", "", "", true},
+	{map[string]string{"cloud": "AzurePublic", "activeDirectoryEndpoint": "https://login.proxy"}, "AzurePublicCloud", "https://login.proxy/", "https://management.azure.com/", "servicebus.windows.net", false},
+	{map[string]string{"cloud": "Private"}, "Private", "", "", "", false},
+	{map[string]string{"cloud": "Private", "activeDirectoryEndpoint": "https://login.private.cloud/", "resourceManagerEndpoint": "https://management.private.cloud", "endpointSuffix": "servicebus.private.cloud"}, "Private", "https://login.private.cloud/", "https://management.private.cloud/", "servicebus.private.cloud", false},
+}
+
+func TestParseEnvironment(t *testing.T) {
+	for _, testData := range parseEnvironmentTestDataset {
+		env, err := ParseEnvironment(testData.metadata)
+		if !testData.isError && err != nil {
+			t.Error("Expected success but got error", err)
+		}
+		if testData.isError && err == nil {
+			t.Error("Expected error but got success")
+		}
+		if err == nil {
+			if env.Name != testData.name || env.ActiveDirectoryEndpoint != testData.activeDirectoryEndpoint ||
+				env.ResourceManagerEndpoint != testData.resourceManagerEndpoint || env.ServiceBusEndpointSuffix != testData.serviceBusSuffix {
+				t.Error("For", testData.metadata, "got unexpected environment", env)
+			}
+		}
+	}
+}
+
+func TestRequireEnvironmentEndpoints(t *testing.T) {
+	env := az.Environment{Name: PrivateCloud, ActiveDirectoryEndpoint: "https://login.private.cloud/"}
+	if err := RequireEnvironmentEndpoints(env, ActiveDirectoryEndpointKey); err != nil {
+		t.Error("Expected success but got error", err)
+	}
+	if err := RequireEnvironmentEndpoints(env, ActiveDirectoryEndpointKey, ResourceManagerEndpointKey); err == nil {
+		t.Error("Expected error but got success")
+	}
+}
@@ -7,7 +7,7 @@ import (
 
 	"github.com/Azure/azure-amqp-common-go/v3/aad"
 	eventhub "github.com/Azure/azure-event-hubs-go/v3"
-	"github.com/Azure/go-autorest/autorest/azure"
+	az "github.com/Azure/go-autorest/autorest/azure"
 )
 
 // EventHubInfo to keep event hub connection and resources
@@ -19,6 +19,9 @@ type EventHubInfo struct {
 	Namespace             string
 	EventHubName          string
 	CheckpointStrategy    string
+	// Environment provides the Service Bus endpoint suffix of the namespace and the Azure Active Directory
+	// endpoint when the namespace is reached with pod identity
+	Environment az.Environment
 }
 
 // GetEventHubClient returns eventhub client
@@ -34,18 +37,23 @@ func GetEventHubClient(info EventHubInfo) (*eventhub.Hub, error) {
 
 	// Since there is no connectionstring, then user wants to use pod identity
 	// Internally, the JWTProvider will use Managed Service Identity to authenticate if no Service Principal info supplied
+	// Without a cloud the environment of the AZURE_ENVIRONMENT variable or the public cloud is used
 	provider, aadErr := aad.NewJWTProvider(func(config *aad.TokenProviderConfiguration) error {
-		if config.Env == nil {
-			config.Env = &azure.PublicCloud
+		if info.Environment.Name != "" {
+			config.Env = &info.Environment
+		} else if config.Env == nil {
+			config.Env = &az.PublicCloud
 		}
 		return nil
 	})
 
-	if aadErr == nil {
-		return eventhub.NewHub(info.Namespace, info.EventHubName, provider)
+	if aadErr != nil {
+		return nil, aadErr
 	}
-
-	return nil, aadErr
+	if info.Environment.Name != "" {
+		return eventhub.NewHub(info.Namespace, info.EventHubName, provider, eventhub.HubWithEnvironment(info.Environment))
+	}
+	return eventhub.NewHub(info.Namespace, info.EventHubName, provider)
 }
 
 // ParseAzureEventHubConnectionString parses Event Hub connection string into (namespace, name)
@@ -25,6 +25,8 @@ import (
 	"time"
 
 	"github.com/Azure/azure-sdk-for-go/services/preview/monitor/mgmt/2018-03-01/insights"
+	"github.com/Azure/go-autorest/autorest"
+	az "github.com/Azure/go-autorest/autorest/azure"
 	"github.com/Azure/go-autorest/autorest/azure/auth"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
@@ -45,6 +47,7 @@ type azureExternalMetricRequest struct {
 	Timespan                  string
 	Filter                    string
 	ResourceGroup             string
+	APIVersion                string
 }
 
 // MonitorInfo to create metric request
@@ -60,6 +63,11 @@ type MonitorInfo struct {
 	AggregationType     string
 	ClientID            string
 	ClientPassword      string
+	// APIVersion overrides the api-version of the metrics request, eg. a preview version for resource types
+	// which only expose custom metrics with it
+	APIVersion string
+	// Environment provides the Azure Active Directory and Azure Resource Manager endpoints of the cloud
+	Environment az.Environment
 }
 
 var azureMonitorLog = logf.Log.WithName("azure_monitor_scaler")
@@ -82,12 +90,17 @@ func GetAzureMetricValue(ctx context.Context, info MonitorInfo, podIdentity keda
 }
 
 func createMetricsClient(info MonitorInfo, podIdentityEnabled bool) insights.MetricsClient {
-	client := insights.NewMetricsClient(info.SubscriptionID)
+	client := insights.NewMetricsClientWithBaseURI(info.Environment.ResourceManagerEndpoint, info.SubscriptionID)
 	var config auth.AuthorizerConfig
 	if podIdentityEnabled {
-		config = auth.NewMSIConfig()
+		msiConfig := auth.NewMSIConfig()
+		msiConfig.Resource = info.Environment.TokenAudience
+		config = msiConfig
 	} else {
-		config = auth.NewClientCredentialsConfig(info.ClientID, info.ClientPassword, info.TenantID)
+		clientCredentialsConfig := auth.NewClientCredentialsConfig(info.ClientID, info.ClientPassword, info.TenantID)
+		clientCredentialsConfig.AADEndpoint = info.Environment.ActiveDirectoryEndpoint
+		clientCredentialsConfig.Resource = info.Environment.TokenAudience
+		config = clientCredentialsConfig
 	}
 	authorizer, _ := config.Authorizer()
 	client.Authorizer = authorizer
@@ -103,12 +116,15 @@ func createMetricsRequest(info MonitorInfo) (*azureExternalMetricRequest, error)
 		Aggregation:     info.AggregationType,
 		Filter:          info.Filter,
 		ResourceGroup:   info.ResourceGroupName,
+		APIVersion:      info.APIVersion,
 	}
 
 	resourceInfo := strings.Split(info.ResourceURI, "/")
 	metricRequest.ResourceProviderNamespace = resourceInfo[0]
 	metricRequest.ResourceType = resourceInfo[1]
-	metricRequest.ResourceName = resourceInfo[2]
+	// child resources, eg. Microsoft.NotificationHubs/namespaces/<namespace>/notificationHubs/<hub>,
+	// keep the remaining segments as part of the resource name
+	metricRequest.ResourceName = strings.Join(resourceInfo[2:], "/")
 
 	// if no timespan is provided, defaults to 5 minutes
 	timespan, err := formatTimeSpan(info.AggregationInterval)
@@ -142,10 +158,7 @@ func getAzureMetric(ctx context.Context, client insights.MetricsClient, azMetric
 	metricResourceURI := azMetricRequest.metricResourceURI()
 	azureMonitorLog.V(2).Info("metric request", "resource uri", metricResourceURI)
 
-	metricResult, err := client.List(ctx, metricResourceURI,
-		azMetricRequest.Timespan, nil,
-		azMetricRequest.MetricName, azMetricRequest.Aggregation, nil,
-		"", azMetricRequest.Filter, "", azMetricRequest.MetricNamespace)
+	metricResult, err := listAzureMetric(ctx, client, metricResourceURI, azMetricRequest)
 	if err != nil {
 		return -1, err
 	}
@@ -155,6 +168,34 @@ func getAzureMetric(ctx context.Context, client insights.MetricsClient, azMetric
 	return value, err
 }
 
+// listAzureMetric lists the metric with the api-version of the insights client, or with the api-version of the request
+// when it's given. The responses of later versions keep the value/timeseries/data layout of the insights client
+func listAzureMetric(ctx context.Context, client insights.MetricsClient, metricResourceURI string, azMetricRequest azureExternalMetricRequest) (insights.Response, error) {
+	if azMetricRequest.APIVersion == "" {
+		return client.List(ctx, metricResourceURI,
+			azMetricRequest.Timespan, nil,
+			azMetricRequest.MetricName, azMetricRequest.Aggregation, nil,
+			"", azMetricRequest.Filter, "", azMetricRequest.MetricNamespace)
+	}
+
+	req, err := client.ListPreparer(ctx, metricResourceURI,
+		azMetricRequest.Timespan, nil,
+		azMetricRequest.MetricName, azMetricRequest.Aggregation, nil,
+		"", azMetricRequest.Filter, "", azMetricRequest.MetricNamespace)
+	if err != nil {
+		return insights.Response{}, err
+	}
+	query := req.URL.Query()
+	query.Set("api-version", azMetricRequest.APIVersion)
+	req.URL.RawQuery = query.Encode()
+
+	resp, err := client.ListSender(req)
+	if err != nil {
+		return insights.Response{Response: autorest.Response{Response: resp}}, err
+	}
+	return client.ListResponder(resp)
+}
+
 func extractValue(azMetricRequest azureExternalMetricRequest, metricResult insights.Response) (float64, error) {
 	metricVals := *metricResult.Value
 
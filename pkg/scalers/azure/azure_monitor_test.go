@@ -1,9 +1,13 @@
 package azure
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/Azure/azure-sdk-for-go/services/preview/monitor/mgmt/2018-03-01/insights"
+	az "github.com/Azure/go-autorest/autorest/azure"
 )
 
 type testExtractAzMonitorTestData struct {
@@ -45,3 +49,68 @@ func TestAzMonitorextractValue(t *testing.T) {
 		}
 	}
 }
+
+func TestAzMonitorCreateMetricsRequestChildResource(t *testing.T) {
+	request, err := createMetricsRequest(MonitorInfo{ResourceURI: "Microsoft.NotificationHubs/namespaces/ns/notificationHubs/hub"})
+	if err != nil {
+		t.Fatal("Could not create metrics request:", err)
+	}
+	if request.ResourceProviderNamespace != "Microsoft.NotificationHubs" || request.ResourceType != "namespaces" || request.ResourceName != "ns/notificationHubs/hub" {
+		t.Errorf("Wrong resource in metrics request: %s/%s/%s", request.ResourceProviderNamespace, request.ResourceType, request.ResourceName)
+	}
+}
+
+func TestAzMonitorCreateMetricsClientEnvironment(t *testing.T) {
+	client := createMetricsClient(MonitorInfo{SubscriptionID: "456", TenantID: "123", ClientID: "xxx", ClientPassword: "yyy", Environment: az.ChinaCloud}, false)
+	if client.BaseURI != az.ChinaCloud.ResourceManagerEndpoint {
+		t.Errorf("Expected base URI %s but got %s", az.ChinaCloud.ResourceManagerEndpoint, client.BaseURI)
+	}
+}
+
+func TestAzMonitorGetAzureMetricAPIVersion(t *testing.T) {
+	var tests = []struct {
+		name       string
+		apiVersion string
+		expected   string
+	}{
+		{"insights client version", "", "2018-01-01"},
+		{"preview version", "2021-05-01-preview", "2021-05-01-preview"},
+	}
+
+	for _, test := range tests {
+		var apiVersion, namespace string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			apiVersion = r.URL.Query().Get("api-version")
+			namespace = r.URL.Query().Get("metricnamespace")
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(`{"value":[{"timeseries":[{"data":[{"maximum":7}]}]}]}`))
+		}))
+
+		client := insights.NewMetricsClientWithBaseURI(server.URL, "456")
+		request := azureExternalMetricRequest{
+			MetricName:                "QueuedJobs",
+			MetricNamespace:           "jobs",
+			SubscriptionID:            "456",
+			ResourceGroup:             "test",
+			ResourceProviderNamespace: "Microsoft.Quantum",
+			ResourceType:              "workspaces",
+			ResourceName:              "ws",
+			Aggregation:               "Maximum",
+			APIVersion:                test.apiVersion,
+		}
+		value, err := getAzureMetric(context.Background(), client, request)
+		server.Close()
+		if err != nil {
+			t.Fatalf("Test: %v; Expected success but got error: %v", test.name, err)
+		}
+		if value != 7 {
+			t.Errorf("Test: %v; Expected value 7 but got %v", test.name, value)
+		}
+		if apiVersion != test.expected {
+			t.Errorf("Test: %v; Expected api-version %s but got %s", test.name, test.expected, apiVersion)
+		}
+		if namespace != "jobs" {
+			t.Errorf("Test: %v; Expected metricnamespace jobs but got %s", test.name, namespace)
+		}
+	}
+}
@@ -1,3 +1,6 @@
+//go:build !selective_scalers || scalers_azure
+// +build !selective_scalers scalers_azure
+
 /*
 Copyright 2021 The KEDA Authors
 
@@ -68,10 +71,15 @@ func NewAzureBlobScaler(config *ScalerConfig) (Scaler, error) {
 		return nil, fmt.Errorf("error parsing azure blob metadata: %s", err)
 	}
 
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
 	return &azureBlobScaler{
 		metadata:    meta,
 		podIdentity: podIdentity,
-		httpClient:  kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, false),
+		httpClient:  httpClient,
 	}, nil
 }
 
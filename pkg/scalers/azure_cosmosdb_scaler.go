@@ -0,0 +1,478 @@
+//go:build !selective_scalers || scalers_azure
+// +build !selective_scalers scalers_azure
+
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scalers
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Azure/go-autorest/autorest/azure/auth"
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	cosmosDBAPIVersion = "2018-12-31"
+	// cosmosDBResource is the resource of the Azure AD tokens for the data plane of Cosmos DB accounts
+	cosmosDBResource = "https://cosmos.azure.com"
+
+	defaultCosmosDBTargetLag = 100
+)
+
+type azureCosmosDBScaler struct {
+	metadata   *azureCosmosDBMetadata
+	httpClient *http.Client
+	// tokenProvider is the Azure AD token of the pod identity, it is nil with an account key
+	tokenProvider cosmosDBTokenProvider
+}
+
+// cosmosDBTokenProvider is implemented by adal.ServicePrincipalToken
+type cosmosDBTokenProvider interface {
+	EnsureFreshWithContext(ctx context.Context) error
+	OAuthToken() string
+}
+
+type azureCosmosDBMetadata struct {
+	// endpoint is the document endpoint of the account, eg. https://myaccount.documents.azure.com:443/
+	endpoint   string
+	accountKey string
+
+	// databaseID and containerID are the monitored container, leaseDatabaseID and leaseContainerID the
+	// container holding the leases of the change feed processor
+	databaseID       string
+	containerID      string
+	leaseDatabaseID  string
+	leaseContainerID string
+	// processorName selects the leases of one processor when several share the lease container, the ids
+	// of the leases start with it
+	processorName string
+
+	targetLag           int64
+	activationTargetLag int64
+
+	scalerIndex int
+}
+
+// cosmosDBLease is a lease document of the change feed processor, LeaseToken is the partition key range of
+// the lease, PartitionId in leases of version 1 of the processor
+type cosmosDBLease struct {
+	ID                string `json:"id"`
+	LeaseToken        string `json:"LeaseToken"`
+	PartitionID       string `json:"PartitionId"`
+	ContinuationToken string `json:"ContinuationToken"`
+}
+
+var azureCosmosDBLog = logf.Log.WithName("azure_cosmosdb_scaler")
+
+// NewAzureCosmosDBScaler creates a new azureCosmosDBScaler
+func NewAzureCosmosDBScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseAzureCosmosDBMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing azure cosmos db metadata: %s", err)
+	}
+
+	var tokenProvider cosmosDBTokenProvider
+	if meta.accountKey == "" {
+		msiConfig := auth.NewMSIConfig()
+		msiConfig.Resource = cosmosDBResource
+		tokenProvider, err = msiConfig.ServicePrincipalToken()
+		if err != nil {
+			return nil, fmt.Errorf("error creating azure cosmos db token: %s", err)
+		}
+	}
+
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &azureCosmosDBScaler{
+		metadata:      meta,
+		httpClient:    httpClient,
+		tokenProvider: tokenProvider,
+	}, nil
+}
+
+func parseAzureCosmosDBMetadata(config *ScalerConfig) (*azureCosmosDBMetadata, error) {
+	meta := azureCosmosDBMetadata{
+		targetLag: defaultCosmosDBTargetLag,
+	}
+
+	if val, ok := config.AuthParams["connection"]; ok && val != "" {
+		endpoint, accountKey, err := parseCosmosDBConnectionString(val)
+		if err != nil {
+			return nil, err
+		}
+		meta.endpoint, meta.accountKey = endpoint, accountKey
+	} else if config.PodIdentity == kedav1alpha1.PodIdentityProviderAzure {
+		if val, ok := config.TriggerMetadata["endpoint"]; ok && val != "" {
+			meta.endpoint = val
+		} else {
+			return nil, fmt.Errorf("no endpoint given")
+		}
+	} else {
+		return nil, fmt.Errorf("no connection given, or endpoint with azure pod identity")
+	}
+	if _, err := url.ParseRequestURI(meta.endpoint); err != nil {
+		return nil, fmt.Errorf("error parsing endpoint: %s", err)
+	}
+	meta.endpoint = strings.TrimSuffix(meta.endpoint, "/")
+
+	if val, ok := config.TriggerMetadata["databaseId"]; ok && val != "" {
+		meta.databaseID = val
+	} else {
+		return nil, fmt.Errorf("no databaseId given")
+	}
+
+	if val, ok := config.TriggerMetadata["containerId"]; ok && val != "" {
+		meta.containerID = val
+	} else {
+		return nil, fmt.Errorf("no containerId given")
+	}
+
+	meta.leaseDatabaseID = meta.databaseID
+	if val, ok := config.TriggerMetadata["leaseDatabaseId"]; ok && val != "" {
+		meta.leaseDatabaseID = val
+	}
+
+	if val, ok := config.TriggerMetadata["leaseContainerId"]; ok && val != "" {
+		meta.leaseContainerID = val
+	} else {
+		return nil, fmt.Errorf("no leaseContainerId given")
+	}
+
+	meta.processorName = config.TriggerMetadata["processorName"]
+
+	// targetLag is the number of changes not yet processed per replica
+	if val, ok := config.TriggerMetadata["targetLag"]; ok && val != "" {
+		targetLag, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing targetLag: %s", err)
+		}
+		meta.targetLag = targetLag
+	}
+
+	if val, ok := config.TriggerMetadata["activationTargetLag"]; ok && val != "" {
+		activationTargetLag, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing activationTargetLag: %s", err)
+		}
+		meta.activationTargetLag = activationTargetLag
+	}
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+// parseCosmosDBConnectionString returns the endpoint and key of a connection string in the format
+// AccountEndpoint=https://myaccount.documents.azure.com:443/;AccountKey=key;
+func parseCosmosDBConnectionString(connectionString string) (string, string, error) {
+	var endpoint, accountKey string
+	for _, part := range strings.Split(connectionString, ";") {
+		keyValue := strings.SplitN(part, "=", 2)
+		if len(keyValue) != 2 {
+			continue
+		}
+		switch strings.TrimSpace(keyValue[0]) {
+		case "AccountEndpoint":
+			endpoint = strings.TrimSpace(keyValue[1])
+		case "AccountKey":
+			accountKey = strings.TrimSpace(keyValue[1])
+		}
+	}
+
+	if endpoint == "" || accountKey == "" {
+		return "", "", fmt.Errorf("connection must contain AccountEndpoint and AccountKey")
+	}
+	return endpoint, accountKey, nil
+}
+
+// IsActive returns true if the change feed lag is above the activation target
+func (s *azureCosmosDBScaler) IsActive(ctx context.Context) (bool, error) {
+	lag, err := s.getChangeFeedLag(ctx)
+	if err != nil {
+		azureCosmosDBLog.Error(err, "error getting change feed lag", "container", s.metadata.containerID)
+		return false, err
+	}
+
+	return lag > s.metadata.activationTargetLag, nil
+}
+
+func (s *azureCosmosDBScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *azureCosmosDBScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetMetricVal := resource.NewQuantity(s.metadata.targetLag, resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(fmt.Sprintf("azure-cosmosdb-%s-%s", s.metadata.databaseID, s.metadata.containerID))),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetMetricVal,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{External: externalMetric, Type: externalMetricType}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// GetMetrics returns value for a supported metric and an error if there is a problem getting the metric
+func (s *azureCosmosDBScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	lag, err := s.getChangeFeedLag(ctx)
+	if err != nil {
+		azureCosmosDBLog.Error(err, "error getting change feed lag", "container", s.metadata.containerID)
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewQuantity(lag, resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
+
+// getChangeFeedLag adds up the remaining work of all leases like the change feed estimator of the SDKs
+func (s *azureCosmosDBScaler) getChangeFeedLag(ctx context.Context) (int64, error) {
+	leases, err := s.getLeases(ctx)
+	if err != nil {
+		return -1, err
+	}
+	if len(leases) == 0 {
+		return -1, fmt.Errorf("no leases found in %s/%s, is the change feed processor started?", s.metadata.leaseDatabaseID, s.metadata.leaseContainerID)
+	}
+
+	var lag int64
+	for _, lease := range leases {
+		leaseLag, err := s.getLeaseLag(ctx, lease)
+		if err != nil {
+			return -1, err
+		}
+		lag += leaseLag
+	}
+	return lag, nil
+}
+
+// getLeases queries the lease documents of the lease container, the .info and .lock documents of the
+// processor have no lease token and are left out
+func (s *azureCosmosDBScaler) getLeases(ctx context.Context) ([]cosmosDBLease, error) {
+	query := map[string]interface{}{
+		"query": "SELECT * FROM c WHERE (IS_DEFINED(c.LeaseToken) OR IS_DEFINED(c.PartitionId)) AND STARTSWITH(c.id, @prefix)",
+		"parameters": []map[string]string{
+			{"name": "@prefix", "value": s.metadata.processorName},
+		},
+	}
+	body, err := json.Marshal(query)
+	if err != nil {
+		return nil, err
+	}
+
+	resourceLink := fmt.Sprintf("dbs/%s/colls/%s", s.metadata.leaseDatabaseID, s.metadata.leaseContainerID)
+	var leases []cosmosDBLease
+	continuation := ""
+	for {
+		req, err := s.newRequest(ctx, "POST", resourceLink, body)
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Content-Type", "application/query+json")
+		req.Header.Set("x-ms-documentdb-isquery", "true")
+		req.Header.Set("x-ms-documentdb-query-enablecrosspartition", "true")
+		if continuation != "" {
+			req.Header.Set("x-ms-continuation", continuation)
+		}
+
+		b, header, err := s.do(req)
+		if err != nil {
+			return nil, err
+		}
+
+		var page struct {
+			Documents []cosmosDBLease `json:"Documents"`
+		}
+		if err := json.Unmarshal(b, &page); err != nil {
+			return nil, fmt.Errorf("error decoding leases: %s", err)
+		}
+		leases = append(leases, page.Documents...)
+
+		continuation = header.Get("x-ms-continuation")
+		if continuation == "" {
+			return leases, nil
+		}
+	}
+}
+
+// getLeaseLag reads the first change after the continuation of the lease from the change feed of its partition
+// key range, the lag is the difference between the session LSN of the range and the LSN of that change
+func (s *azureCosmosDBScaler) getLeaseLag(ctx context.Context, lease cosmosDBLease) (int64, error) {
+	leaseToken := lease.LeaseToken
+	if leaseToken == "" {
+		leaseToken = lease.PartitionID
+	}
+
+	resourceLink := fmt.Sprintf("dbs/%s/colls/%s", s.metadata.databaseID, s.metadata.containerID)
+	req, err := s.newRequest(ctx, "GET", resourceLink, nil)
+	if err != nil {
+		return -1, err
+	}
+	req.Header.Set("A-IM", "Incremental feed")
+	req.Header.Set("x-ms-documentdb-partitionkeyrangeid", leaseToken)
+	req.Header.Set("x-ms-max-item-count", "1")
+	if lease.ContinuationToken != "" {
+		req.Header.Set("If-None-Match", lease.ContinuationToken)
+	}
+
+	r, err := s.httpClient.Do(req)
+	if err != nil {
+		return -1, err
+	}
+	defer r.Body.Close()
+
+	// no changes after the continuation of the lease
+	if r.StatusCode == http.StatusNotModified {
+		return 0, nil
+	}
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return -1, err
+	}
+	if r.StatusCode != http.StatusOK {
+		return -1, fmt.Errorf("cosmos db change feed of lease %s returned %d: %s", leaseToken, r.StatusCode, string(b))
+	}
+
+	var feed struct {
+		Documents []struct {
+			LSN int64 `json:"_lsn"`
+		} `json:"Documents"`
+	}
+	if err := json.Unmarshal(b, &feed); err != nil {
+		return -1, fmt.Errorf("error decoding change feed of lease %s: %s", leaseToken, err)
+	}
+	if len(feed.Documents) == 0 {
+		return 0, nil
+	}
+
+	sessionLSN, err := parseCosmosDBSessionLSN(r.Header.Get("x-ms-session-token"))
+	if err != nil {
+		return -1, fmt.Errorf("error parsing session token of lease %s: %s", leaseToken, err)
+	}
+
+	lag := sessionLSN - feed.Documents[0].LSN + 1
+	if lag < 0 {
+		lag = 0
+	}
+	return lag, nil
+}
+
+// parseCosmosDBSessionLSN returns the global LSN of a session token, eg. "0:-1#1234" or "0:1234"
+func parseCosmosDBSessionLSN(sessionToken string) (int64, error) {
+	if i := strings.LastIndex(sessionToken, ":"); i >= 0 {
+		sessionToken = sessionToken[i+1:]
+	}
+	parts := strings.Split(sessionToken, "#")
+	lsn := parts[0]
+	if len(parts) > 1 {
+		lsn = parts[1]
+	}
+	return strconv.ParseInt(lsn, 10, 64)
+}
+
+// newRequest returns an authorized request for the documents of the container of resourceLink
+func (s *azureCosmosDBScaler) newRequest(ctx context.Context, method string, resourceLink string, body []byte) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, fmt.Sprintf("%s/%s/docs", s.metadata.endpoint, resourceLink), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	date := time.Now().UTC().Format(http.TimeFormat)
+	req.Header.Set("x-ms-date", date)
+	req.Header.Set("x-ms-version", cosmosDBAPIVersion)
+	req.Header.Set("Accept", "application/json")
+
+	var authorization string
+	if s.tokenProvider != nil {
+		if err := s.tokenProvider.EnsureFreshWithContext(ctx); err != nil {
+			return nil, fmt.Errorf("error refreshing azure cosmos db token: %s", err)
+		}
+		authorization = "type=aad&ver=1.0&sig=" + s.tokenProvider.OAuthToken()
+	} else {
+		authorization, err = cosmosDBMasterKeyAuthorization(s.metadata.accountKey, method, resourceLink, date)
+		if err != nil {
+			return nil, err
+		}
+	}
+	req.Header.Set("Authorization", url.QueryEscape(authorization))
+	return req, nil
+}
+
+// cosmosDBMasterKeyAuthorization signs a request on the documents of a container with the account key
+func cosmosDBMasterKeyAuthorization(accountKey string, method string, resourceLink string, date string) (string, error) {
+	key, err := base64.StdEncoding.DecodeString(accountKey)
+	if err != nil {
+		return "", fmt.Errorf("error decoding account key: %s", err)
+	}
+
+	payload := fmt.Sprintf("%s\n%s\n%s\n%s\n%s\n", strings.ToLower(method), "docs", resourceLink, strings.ToLower(date), "")
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(payload))
+	signature := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	return "type=master&ver=1.0&sig=" + signature, nil
+}
+
+// do sends the request and returns the body and headers of a successful response
+func (s *azureCosmosDBScaler) do(req *http.Request) ([]byte, http.Header, error) {
+	r, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer r.Body.Close()
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if r.StatusCode != http.StatusOK {
+		return nil, nil, fmt.Errorf("cosmos db returned %d: %s", r.StatusCode, string(b))
+	}
+	return b, r.Header, nil
+}
@@ -0,0 +1,201 @@
+//go:build !selective_scalers || scalers_azure
+// +build !selective_scalers scalers_azure
+
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+// base64 of "secret"
+const testCosmosDBConnection = "AccountEndpoint=https://myaccount.documents.azure.com:443/;AccountKey=c2VjcmV0;"
+
+type parseAzCosmosDBMetadataTestData struct {
+	metadata    map[string]string
+	isError     bool
+	authParams  map[string]string
+	podIdentity kedav1alpha1.PodIdentityProvider
+}
+
+type azCosmosDBMetricIdentifier struct {
+	metadataTestData *parseAzCosmosDBMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testParseAzCosmosDBMetadata = []parseAzCosmosDBMetadataTestData{
+	// nothing passed
+	{map[string]string{}, true, map[string]string{}, ""},
+	// properly formed with connection
+	{map[string]string{"databaseId": "shop", "containerId": "orders", "leaseContainerId": "leases"}, false, map[string]string{"connection": testCosmosDBConnection}, ""},
+	// with pod identity, lease container in another database
+	{map[string]string{"endpoint": "https://myaccount.documents.azure.com:443/", "databaseId": "shop", "containerId": "orders", "leaseDatabaseId": "processors", "leaseContainerId": "leases", "processorName": "invoicing", "targetLag": "500", "activationTargetLag": "10"}, false, map[string]string{}, kedav1alpha1.PodIdentityProviderAzure},
+	// missing connection
+	{map[string]string{"databaseId": "shop", "containerId": "orders", "leaseContainerId": "leases"}, true, map[string]string{}, ""},
+	// connection without key
+	{map[string]string{"databaseId": "shop", "containerId": "orders", "leaseContainerId": "leases"}, true, map[string]string{"connection": "AccountEndpoint=https://myaccount.documents.azure.com:443/;"}, ""},
+	// pod identity without endpoint
+	{map[string]string{"databaseId": "shop", "containerId": "orders", "leaseContainerId": "leases"}, true, map[string]string{}, kedav1alpha1.PodIdentityProviderAzure},
+	// missing databaseId
+	{map[string]string{"containerId": "orders", "leaseContainerId": "leases"}, true, map[string]string{"connection": testCosmosDBConnection}, ""},
+	// missing containerId
+	{map[string]string{"databaseId": "shop", "leaseContainerId": "leases"}, true, map[string]string{"connection": testCosmosDBConnection}, ""},
+	// missing leaseContainerId
+	{map[string]string{"databaseId": "shop", "containerId": "orders"}, true, map[string]string{"connection": testCosmosDBConnection}, ""},
+	// invalid targetLag
+	{map[string]string{"databaseId": "shop", "containerId": "orders", "leaseContainerId": "leases", "targetLag": "a"}, true, map[string]string{"connection": testCosmosDBConnection}, ""},
+	// invalid activationTargetLag
+	{map[string]string{"databaseId": "shop", "containerId": "orders", "leaseContainerId": "leases", "activationTargetLag": "a"}, true, map[string]string{"connection": testCosmosDBConnection}, ""},
+}
+
+var azCosmosDBMetricIdentifiers = []azCosmosDBMetricIdentifier{
+	{&testParseAzCosmosDBMetadata[1], 0, "s0-azure-cosmosdb-shop-orders"},
+	{&testParseAzCosmosDBMetadata[2], 1, "s1-azure-cosmosdb-shop-orders"},
+}
+
+func TestAzCosmosDBParseMetadata(t *testing.T) {
+	for _, testData := range testParseAzCosmosDBMetadata {
+		_, err := parseAzureCosmosDBMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams, PodIdentity: testData.podIdentity})
+		if err != nil && !testData.isError {
+			t.Errorf("Expected success but got error %s for metadata %v", err, testData.metadata)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Expected error but got success for metadata %v", testData.metadata)
+		}
+	}
+}
+
+func TestAzCosmosDBGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range azCosmosDBMetricIdentifiers {
+		meta, err := parseAzureCosmosDBMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, AuthParams: testData.metadataTestData.authParams, PodIdentity: testData.metadataTestData.podIdentity, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockAzCosmosDBScaler := azureCosmosDBScaler{metadata: meta}
+
+		metricSpec := mockAzCosmosDBScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Errorf("Wrong External metric source name: %s, expected: %s", metricName, testData.name)
+		}
+	}
+}
+
+func TestParseAzCosmosDBSessionLSN(t *testing.T) {
+	tests := map[string]int64{
+		"0:-1#1234":         1234,
+		"3:1234":            1234,
+		"1:-1#1234#1=1230":  1234,
+		"0:1#1234#3=20#4=1": 1234,
+	}
+	for sessionToken, expected := range tests {
+		lsn, err := parseCosmosDBSessionLSN(sessionToken)
+		if err != nil {
+			t.Errorf("Expected success but got error %s for %s", err, sessionToken)
+		}
+		if lsn != expected {
+			t.Errorf("Expected %d, got %d for %s", expected, lsn, sessionToken)
+		}
+	}
+
+	if _, err := parseCosmosDBSessionLSN("0:abc"); err == nil {
+		t.Error("Expected error but got success")
+	}
+}
+
+func TestAzCosmosDBGetChangeFeedLag(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization, err := url.QueryUnescape(r.Header.Get("Authorization"))
+		if err != nil || authorization == "" || r.Header.Get("x-ms-date") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		expected, _ := cosmosDBMasterKeyAuthorization("c2VjcmV0", r.Method, r.URL.Path[1:len(r.URL.Path)-len("/docs")], r.Header.Get("x-ms-date"))
+		if authorization != expected {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch r.URL.Path {
+		case "/dbs/shop/colls/leases/docs":
+			var query struct {
+				Parameters []map[string]string `json:"parameters"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&query); err != nil || r.Header.Get("x-ms-documentdb-isquery") != "true" || query.Parameters[0]["value"] != "invoicing" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			// two pages of leases
+			if r.Header.Get("x-ms-continuation") == "" {
+				w.Header().Set("x-ms-continuation", "page2")
+				fmt.Fprint(w, `{"Documents":[{"id":"invoicing..0","LeaseToken":"0","ContinuationToken":"\"100\""}]}`)
+				return
+			}
+			fmt.Fprint(w, `{"Documents":[{"id":"invoicing..1","LeaseToken":"1","ContinuationToken":"\"200\""},{"id":"invoicing..2","PartitionId":"2","ContinuationToken":null}]}`)
+		case "/dbs/shop/colls/orders/docs":
+			if r.Header.Get("A-IM") != "Incremental feed" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			switch r.Header.Get("x-ms-documentdb-partitionkeyrangeid") {
+			case "0":
+				// 50 changes after the continuation
+				w.Header().Set("x-ms-session-token", "0:-1#150")
+				fmt.Fprint(w, `{"Documents":[{"id":"a","_lsn":101}]}`)
+			case "1":
+				// processed up to date
+				w.WriteHeader(http.StatusNotModified)
+			case "2":
+				// never processed, 7 changes since the beginning
+				w.Header().Set("x-ms-session-token", "2:7")
+				fmt.Fprint(w, `{"Documents":[{"id":"b","_lsn":1}]}`)
+			default:
+				w.WriteHeader(http.StatusGone)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	meta, err := parseAzureCosmosDBMetadata(&ScalerConfig{
+		TriggerMetadata: map[string]string{"databaseId": "shop", "containerId": "orders", "leaseContainerId": "leases", "processorName": "invoicing"},
+		AuthParams:      map[string]string{"connection": fmt.Sprintf("AccountEndpoint=%s/;AccountKey=c2VjcmV0", server.URL)},
+	})
+	if err != nil {
+		t.Fatal("Could not parse metadata:", err)
+	}
+	scaler := azureCosmosDBScaler{metadata: meta, httpClient: http.DefaultClient}
+
+	lag, err := scaler.getChangeFeedLag(context.Background())
+	if err != nil {
+		t.Fatalf("Expected success but got error: %s", err)
+	}
+	if lag != 57 {
+		t.Errorf("Expected lag 57, got %d", lag)
+	}
+}
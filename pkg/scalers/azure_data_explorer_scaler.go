@@ -0,0 +1,297 @@
+//go:build !selective_scalers || scalers_azure
+// +build !selective_scalers scalers_azure
+
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scalers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/azure/auth"
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+// dataExplorerQueryPath is the v1 query endpoint of a cluster, it returns the result as plain tables
+const dataExplorerQueryPath = "/v1/rest/query"
+
+type azureDataExplorerScaler struct {
+	metadata   *azureDataExplorerMetadata
+	httpClient *http.Client
+	authorizer autorest.Authorizer
+}
+
+type azureDataExplorerMetadata struct {
+	// endpoint is the URI of the cluster, eg. https://mycluster.westeurope.kusto.windows.net
+	endpoint            string
+	databaseName        string
+	query               string
+	threshold           float64
+	activationThreshold float64
+
+	// auth
+	tenantID       string
+	clientID       string
+	clientPassword string
+
+	scalerIndex int
+}
+
+type dataExplorerQueryRequest struct {
+	DB  string `json:"db"`
+	CSL string `json:"csl"`
+}
+
+type dataExplorerQueryResponse struct {
+	Tables []struct {
+		TableName string `json:"TableName"`
+		Columns   []struct {
+			ColumnName string `json:"ColumnName"`
+			DataType   string `json:"DataType"`
+		} `json:"Columns"`
+		Rows [][]interface{} `json:"Rows"`
+	} `json:"Tables"`
+}
+
+var azureDataExplorerLog = logf.Log.WithName("azure_data_explorer_scaler")
+
+// NewAzureDataExplorerScaler creates a new azureDataExplorerScaler
+func NewAzureDataExplorerScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseAzureDataExplorerMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing azure data explorer metadata: %s", err)
+	}
+
+	// the Azure AD tokens of a cluster are requested for the cluster URI
+	var authConfig auth.AuthorizerConfig
+	if config.PodIdentity == kedav1alpha1.PodIdentityProviderAzure {
+		msiConfig := auth.NewMSIConfig()
+		msiConfig.Resource = meta.endpoint
+		authConfig = msiConfig
+	} else {
+		credentialsConfig := auth.NewClientCredentialsConfig(meta.clientID, meta.clientPassword, meta.tenantID)
+		credentialsConfig.Resource = meta.endpoint
+		authConfig = credentialsConfig
+	}
+	authorizer, err := authConfig.Authorizer()
+	if err != nil {
+		return nil, fmt.Errorf("error creating azure data explorer authorizer: %s", err)
+	}
+
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &azureDataExplorerScaler{
+		metadata:   meta,
+		httpClient: httpClient,
+		authorizer: authorizer,
+	}, nil
+}
+
+func parseAzureDataExplorerMetadata(config *ScalerConfig) (*azureDataExplorerMetadata, error) {
+	meta := azureDataExplorerMetadata{}
+
+	if val, ok := config.TriggerMetadata["endpoint"]; ok && val != "" {
+		if _, err := url.ParseRequestURI(val); err != nil {
+			return nil, fmt.Errorf("error parsing endpoint: %s", err)
+		}
+		meta.endpoint = strings.TrimSuffix(val, "/")
+	} else {
+		return nil, fmt.Errorf("no endpoint given")
+	}
+
+	if val, ok := config.TriggerMetadata["databaseName"]; ok && val != "" {
+		meta.databaseName = val
+	} else {
+		return nil, fmt.Errorf("no databaseName given")
+	}
+
+	// query is a KQL query, the first cell of its result is the metric value
+	if val, ok := config.TriggerMetadata["query"]; ok && val != "" {
+		meta.query = val
+	} else {
+		return nil, fmt.Errorf("no query given")
+	}
+
+	if val, ok := config.TriggerMetadata["threshold"]; ok && val != "" {
+		threshold, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing threshold: %s", err)
+		}
+		meta.threshold = threshold
+	} else {
+		return nil, fmt.Errorf("no threshold given")
+	}
+
+	if val, ok := config.TriggerMetadata["activationThreshold"]; ok && val != "" {
+		activationThreshold, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing activationThreshold: %s", err)
+		}
+		meta.activationThreshold = activationThreshold
+	}
+
+	if config.PodIdentity != kedav1alpha1.PodIdentityProviderAzure {
+		tenantID, err := GetFromAuthOrMeta(config, "tenantId")
+		if err != nil {
+			return nil, err
+		}
+		meta.tenantID = tenantID
+	}
+
+	clientID, clientPassword, err := parseAzurePodIdentityParams(config)
+	if err != nil {
+		return nil, err
+	}
+	meta.clientID = clientID
+	meta.clientPassword = clientPassword
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+// IsActive returns true if the result of the query is above the activation threshold
+func (s *azureDataExplorerScaler) IsActive(ctx context.Context) (bool, error) {
+	value, err := s.getQueryResult(ctx)
+	if err != nil {
+		azureDataExplorerLog.Error(err, "error running azure data explorer query", "database", s.metadata.databaseName)
+		return false, err
+	}
+
+	return value > s.metadata.activationThreshold, nil
+}
+
+func (s *azureDataExplorerScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *azureDataExplorerScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetMetricVal := resource.NewMilliQuantity(int64(s.metadata.threshold*1000), resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(fmt.Sprintf("azure-data-explorer-%s", s.metadata.databaseName))),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetMetricVal,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{External: externalMetric, Type: externalMetricType}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// GetMetrics returns value for a supported metric and an error if there is a problem getting the metric
+func (s *azureDataExplorerScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	value, err := s.getQueryResult(ctx)
+	if err != nil {
+		azureDataExplorerLog.Error(err, "error running azure data explorer query", "database", s.metadata.databaseName)
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewMilliQuantity(int64(value*1000), resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
+
+// getQueryResult runs the query on the database and returns the first cell of the primary result
+func (s *azureDataExplorerScaler) getQueryResult(ctx context.Context) (float64, error) {
+	body, err := json.Marshal(dataExplorerQueryRequest{DB: s.metadata.databaseName, CSL: s.metadata.query})
+	if err != nil {
+		return -1, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.metadata.endpoint+dataExplorerQueryPath, bytes.NewReader(body))
+	if err != nil {
+		return -1, err
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	req.Header.Set("Accept", "application/json")
+	req, err = autorest.Prepare(req, s.authorizer.WithAuthorization())
+	if err != nil {
+		return -1, fmt.Errorf("error authorizing azure data explorer request: %s", err)
+	}
+
+	r, err := s.httpClient.Do(req)
+	if err != nil {
+		return -1, err
+	}
+	defer r.Body.Close()
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return -1, err
+	}
+	if r.StatusCode != http.StatusOK {
+		return -1, fmt.Errorf("azure data explorer returned %d: %s", r.StatusCode, string(b))
+	}
+
+	return parseDataExplorerQueryResponse(b)
+}
+
+// parseDataExplorerQueryResponse returns the first cell of the first table, the primary result of the query,
+// numbers and numeric strings are accepted
+func parseDataExplorerQueryResponse(b []byte) (float64, error) {
+	var response dataExplorerQueryResponse
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	if err := decoder.Decode(&response); err != nil {
+		return -1, fmt.Errorf("error decoding azure data explorer response: %s", err)
+	}
+
+	if len(response.Tables) == 0 || len(response.Tables[0].Rows) == 0 || len(response.Tables[0].Rows[0]) == 0 {
+		return -1, fmt.Errorf("azure data explorer query returned no rows")
+	}
+
+	switch cell := response.Tables[0].Rows[0][0].(type) {
+	case json.Number:
+		return cell.Float64()
+	case string:
+		value, err := strconv.ParseFloat(cell, 64)
+		if err != nil {
+			return -1, fmt.Errorf("first cell of the azure data explorer result is not a number: %s", cell)
+		}
+		return value, nil
+	case nil:
+		return -1, fmt.Errorf("first cell of the azure data explorer result is null")
+	default:
+		return -1, fmt.Errorf("first cell of the azure data explorer result is not a number: %v", cell)
+	}
+}
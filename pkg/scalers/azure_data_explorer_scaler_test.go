@@ -0,0 +1,174 @@
+//go:build !selective_scalers || scalers_azure
+// +build !selective_scalers scalers_azure
+
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Azure/go-autorest/autorest"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+type parseAzDataExplorerMetadataTestData struct {
+	metadata    map[string]string
+	isError     bool
+	resolvedEnv map[string]string
+	authParams  map[string]string
+	podIdentity kedav1alpha1.PodIdentityProvider
+}
+
+type azDataExplorerMetricIdentifier struct {
+	metadataTestData *parseAzDataExplorerMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testParseAzDataExplorerMetadata = []parseAzDataExplorerMetadataTestData{
+	// nothing passed
+	{map[string]string{}, true, map[string]string{}, map[string]string{}, ""},
+	// properly formed with service principal
+	{map[string]string{"endpoint": "https://mycluster.westeurope.kusto.windows.net", "databaseName": "telemetry", "query": "Orders | where State == 'pending' | count", "threshold": "100", "tenantId": "123", "activeDirectoryClientId": "CLIENT_ID", "activeDirectoryClientPasswordFromEnv": "CLIENT_PASSWORD"}, false, testAzMonitorResolvedEnv, map[string]string{}, ""},
+	// service principal in TriggerAuthentication
+	{map[string]string{"endpoint": "https://mycluster.westeurope.kusto.windows.net", "databaseName": "telemetry", "query": "Orders | count", "threshold": "2.5", "activationThreshold": "1"}, false, map[string]string{}, map[string]string{"tenantId": "123", "activeDirectoryClientId": "CLIENT_ID", "activeDirectoryClientPassword": "CLIENT_PASSWORD"}, ""},
+	// with pod identity
+	{map[string]string{"endpoint": "https://mycluster.westeurope.kusto.windows.net", "databaseName": "telemetry", "query": "Orders | count", "threshold": "100"}, false, map[string]string{}, map[string]string{}, kedav1alpha1.PodIdentityProviderAzure},
+	// invalid endpoint
+	{map[string]string{"endpoint": "mycluster", "databaseName": "telemetry", "query": "Orders | count", "threshold": "100"}, true, map[string]string{}, map[string]string{}, kedav1alpha1.PodIdentityProviderAzure},
+	// missing databaseName
+	{map[string]string{"endpoint": "https://mycluster.westeurope.kusto.windows.net", "query": "Orders | count", "threshold": "100"}, true, map[string]string{}, map[string]string{}, kedav1alpha1.PodIdentityProviderAzure},
+	// missing query
+	{map[string]string{"endpoint": "https://mycluster.westeurope.kusto.windows.net", "databaseName": "telemetry", "threshold": "100"}, true, map[string]string{}, map[string]string{}, kedav1alpha1.PodIdentityProviderAzure},
+	// missing threshold
+	{map[string]string{"endpoint": "https://mycluster.westeurope.kusto.windows.net", "databaseName": "telemetry", "query": "Orders | count"}, true, map[string]string{}, map[string]string{}, kedav1alpha1.PodIdentityProviderAzure},
+	// invalid activationThreshold
+	{map[string]string{"endpoint": "https://mycluster.westeurope.kusto.windows.net", "databaseName": "telemetry", "query": "Orders | count", "threshold": "100", "activationThreshold": "a"}, true, map[string]string{}, map[string]string{}, kedav1alpha1.PodIdentityProviderAzure},
+	// missing tenantId
+	{map[string]string{"endpoint": "https://mycluster.westeurope.kusto.windows.net", "databaseName": "telemetry", "query": "Orders | count", "threshold": "100", "activeDirectoryClientId": "CLIENT_ID", "activeDirectoryClientPasswordFromEnv": "CLIENT_PASSWORD"}, true, testAzMonitorResolvedEnv, map[string]string{}, ""},
+	// missing client password
+	{map[string]string{"endpoint": "https://mycluster.westeurope.kusto.windows.net", "databaseName": "telemetry", "query": "Orders | count", "threshold": "100", "tenantId": "123", "activeDirectoryClientId": "CLIENT_ID"}, true, testAzMonitorResolvedEnv, map[string]string{}, ""},
+}
+
+var azDataExplorerMetricIdentifiers = []azDataExplorerMetricIdentifier{
+	{&testParseAzDataExplorerMetadata[1], 0, "s0-azure-data-explorer-telemetry"},
+	{&testParseAzDataExplorerMetadata[3], 1, "s1-azure-data-explorer-telemetry"},
+}
+
+func TestAzDataExplorerParseMetadata(t *testing.T) {
+	for _, testData := range testParseAzDataExplorerMetadata {
+		_, err := parseAzureDataExplorerMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, ResolvedEnv: testData.resolvedEnv, AuthParams: testData.authParams, PodIdentity: testData.podIdentity})
+		if err != nil && !testData.isError {
+			t.Errorf("Expected success but got error %s for metadata %v", err, testData.metadata)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Expected error but got success for metadata %v", testData.metadata)
+		}
+	}
+}
+
+func TestAzDataExplorerGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range azDataExplorerMetricIdentifiers {
+		meta, err := parseAzureDataExplorerMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, ResolvedEnv: testData.metadataTestData.resolvedEnv, AuthParams: testData.metadataTestData.authParams, PodIdentity: testData.metadataTestData.podIdentity, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockAzDataExplorerScaler := azureDataExplorerScaler{metadata: meta}
+
+		metricSpec := mockAzDataExplorerScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Errorf("Wrong External metric source name: %s, expected: %s", metricName, testData.name)
+		}
+	}
+}
+
+func TestParseAzDataExplorerQueryResponse(t *testing.T) {
+	tests := []struct {
+		response string
+		expected float64
+		isError  bool
+	}{
+		{`{"Tables":[{"TableName":"Table_0","Columns":[{"ColumnName":"Count","DataType":"Int64"}],"Rows":[[42]]}]}`, 42, false},
+		{`{"Tables":[{"TableName":"Table_0","Columns":[{"ColumnName":"avg_Latency","DataType":"Double"}],"Rows":[[1.5,"x"]]}]}`, 1.5, false},
+		{`{"Tables":[{"TableName":"Table_0","Columns":[{"ColumnName":"Count","DataType":"String"}],"Rows":[["7"]]}]}`, 7, false},
+		{`{"Tables":[{"TableName":"Table_0","Columns":[{"ColumnName":"Count","DataType":"Int64"}],"Rows":[]}]}`, 0, true},
+		{`{"Tables":[{"TableName":"Table_0","Columns":[{"ColumnName":"Count","DataType":"Int64"}],"Rows":[[null]]}]}`, 0, true},
+		{`{"Tables":[{"TableName":"Table_0","Columns":[{"ColumnName":"Name","DataType":"String"}],"Rows":[["orders"]]}]}`, 0, true},
+		{`{"Tables":[]}`, 0, true},
+	}
+	for _, test := range tests {
+		value, err := parseDataExplorerQueryResponse([]byte(test.response))
+		if test.isError {
+			if err == nil {
+				t.Errorf("Expected error but got %f for %s", value, test.response)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Expected success but got error %s for %s", err, test.response)
+		}
+		if value != test.expected {
+			t.Errorf("Expected %f, got %f for %s", test.expected, value, test.response)
+		}
+	}
+}
+
+func TestAzDataExplorerGetQueryResult(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" || r.URL.Path != dataExplorerQueryPath {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer aad_token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var query dataExplorerQueryRequest
+		if err := json.NewDecoder(r.Body).Decode(&query); err != nil || query.DB != "telemetry" || query.CSL != "Orders | count" {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, `{"error":{"message":"unexpected query %v"}}`, query)
+			return
+		}
+		fmt.Fprint(w, `{"Tables":[{"TableName":"Table_0","Columns":[{"ColumnName":"Count","DataType":"Int64"}],"Rows":[[12]]}]}`)
+	}))
+	defer server.Close()
+
+	meta, err := parseAzureDataExplorerMetadata(&ScalerConfig{
+		TriggerMetadata: map[string]string{"endpoint": server.URL + "/", "databaseName": "telemetry", "query": "Orders | count", "threshold": "10"},
+		PodIdentity:     kedav1alpha1.PodIdentityProviderAzure,
+	})
+	if err != nil {
+		t.Fatal("Could not parse metadata:", err)
+	}
+	scaler := azureDataExplorerScaler{metadata: meta, httpClient: http.DefaultClient, authorizer: autorest.NewBearerAuthorizer(grafanaAPIKeyToken("aad_token"))}
+
+	value, err := scaler.getQueryResult(context.Background())
+	if err != nil {
+		t.Fatalf("Expected success but got error: %s", err)
+	}
+	if value != 12 {
+		t.Errorf("Expected 12, got %f", value)
+	}
+}
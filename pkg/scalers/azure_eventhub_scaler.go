@@ -1,3 +1,6 @@
+//go:build !selective_scalers || scalers_azure
+// +build !selective_scalers scalers_azure
+
 package scalers
 
 /*
@@ -73,10 +76,15 @@ func NewAzureEventHubScaler(config *ScalerConfig) (Scaler, error) {
 		return nil, fmt.Errorf("unable to get eventhub client: %s", err)
 	}
 
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
 	return &azureEventHubScaler{
 		metadata:   parsedMetadata,
 		client:     hub,
-		httpClient: kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, false),
+		httpClient: httpClient,
 	}, nil
 }
 
@@ -151,6 +159,17 @@ func parseAzureEventHubMetadata(config *ScalerConfig) (*eventHubMetadata, error)
 		if len(meta.eventHubInfo.EventHubName) == 0 {
 			return nil, fmt.Errorf("no event hub name string given")
 		}
+
+		if val, ok := config.TriggerMetadata["cloud"]; ok && val != "" {
+			env, err := azure.ParseEnvironment(config.TriggerMetadata)
+			if err != nil {
+				return nil, err
+			}
+			if err := azure.RequireEnvironmentEndpoints(env, azure.EndpointSuffixKey); err != nil {
+				return nil, err
+			}
+			meta.eventHubInfo.Environment = env
+		}
 	}
 
 	meta.scalerIndex = config.ScalerIndex
@@ -0,0 +1,294 @@
+//go:build !selective_scalers || scalers_azure
+// +build !selective_scalers scalers_azure
+
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scalers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tidwall/gjson"
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	iotHubTwinAPIVersion = "2021-04-12"
+	// lifetime of the shared access signature generated for each request
+	iotHubSasTokenTTL = time.Hour
+)
+
+type azureIotHubTwinScaler struct {
+	metadata   *azureIotHubTwinMetadata
+	httpClient *http.Client
+}
+
+type azureIotHubTwinMetadata struct {
+	// hubURL is derived from the HostName of the connection string, eg. https://myhub.azure-devices.net
+	hubURL                string
+	sharedAccessKeyName   string
+	sharedAccessKey       string
+	deviceID              string
+	moduleID              string
+	propertyPath          string
+	targetValue           float64
+	activationTargetValue float64
+	scalerIndex           int
+}
+
+var azureIotHubTwinLog = logf.Log.WithName("azure_iot_hub_twin_scaler")
+
+// NewAzureIotHubTwinScaler creates a new azureIotHubTwinScaler
+func NewAzureIotHubTwinScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseAzureIotHubTwinMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing azure iot hub twin metadata: %s", err)
+	}
+
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &azureIotHubTwinScaler{
+		metadata:   meta,
+		httpClient: httpClient,
+	}, nil
+}
+
+func parseAzureIotHubTwinMetadata(config *ScalerConfig) (*azureIotHubTwinMetadata, error) {
+	meta := azureIotHubTwinMetadata{}
+
+	connection := ""
+	if val, ok := config.AuthParams["connection"]; ok && val != "" {
+		connection = val
+	} else if val, ok := config.TriggerMetadata["connectionFromEnv"]; ok && val != "" {
+		connection = config.ResolvedEnv[val]
+	}
+	if connection == "" {
+		return nil, fmt.Errorf("no connection given")
+	}
+	hostName, keyName, key, err := parseIotHubConnectionString(connection)
+	if err != nil {
+		return nil, err
+	}
+	meta.hubURL = "https://" + hostName
+	meta.sharedAccessKeyName = keyName
+	meta.sharedAccessKey = key
+
+	if val, ok := config.TriggerMetadata["deviceId"]; ok && val != "" {
+		meta.deviceID = val
+	} else {
+		return nil, fmt.Errorf("no deviceId given")
+	}
+
+	// without moduleId the device twin is read instead of a module twin
+	if val, ok := config.TriggerMetadata["moduleId"]; ok && val != "" {
+		meta.moduleID = val
+	}
+
+	// propertyPath is a gjson path below properties.desired, eg. "coordination.pendingJobs"
+	if val, ok := config.TriggerMetadata["propertyPath"]; ok && val != "" {
+		meta.propertyPath = val
+	} else {
+		return nil, fmt.Errorf("no propertyPath given")
+	}
+
+	if val, ok := config.TriggerMetadata[targetValueName]; ok && val != "" {
+		targetValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing targetValue: %s", err)
+		}
+		meta.targetValue = targetValue
+	} else {
+		return nil, fmt.Errorf("no targetValue given")
+	}
+
+	if val, ok := config.TriggerMetadata["activationTargetValue"]; ok && val != "" {
+		activationTargetValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing activationTargetValue: %s", err)
+		}
+		meta.activationTargetValue = activationTargetValue
+	}
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+// parseIotHubConnectionString parses an IoT Hub connection string into (hostName, sharedAccessKeyName, sharedAccessKey)
+// Connection string should be in following format:
+// HostName=myhub.azure-devices.net;SharedAccessKeyName=registryRead;SharedAccessKey=secretKey123
+func parseIotHubConnectionString(connectionString string) (string, string, string, error) {
+	var hostName, keyName, key string
+	for _, v := range strings.Split(connectionString, ";") {
+		parts := strings.SplitN(v, "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+		switch parts[0] {
+		case "HostName":
+			hostName = parts[1]
+		case "SharedAccessKeyName":
+			keyName = parts[1]
+		case "SharedAccessKey":
+			key = parts[1]
+		}
+	}
+
+	if hostName == "" || keyName == "" || key == "" {
+		return "", "", "", fmt.Errorf("can't parse iot hub connection string. Missing HostName, SharedAccessKeyName or SharedAccessKey")
+	}
+	if _, err := base64.StdEncoding.DecodeString(key); err != nil {
+		return "", "", "", fmt.Errorf("error parsing SharedAccessKey: %s", err)
+	}
+
+	return hostName, keyName, key, nil
+}
+
+func (s *azureIotHubTwinScaler) IsActive(ctx context.Context) (bool, error) {
+	val, err := s.getDesiredPropertyValue(ctx)
+	if err != nil {
+		azureIotHubTwinLog.Error(err, "error getting iot hub twin desired property")
+		return false, err
+	}
+
+	return val > s.metadata.activationTargetValue, nil
+}
+
+func (s *azureIotHubTwinScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *azureIotHubTwinScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetMetricValue := resource.NewMilliQuantity(int64(s.metadata.targetValue*1000), resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(s.metricName())),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetMetricValue,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{External: externalMetric, Type: externalMetricType}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+func (s *azureIotHubTwinScaler) metricName() string {
+	metricName := "azure-iot-hub-twin-" + s.metadata.deviceID
+	if s.metadata.moduleID != "" {
+		metricName += "-" + s.metadata.moduleID
+	}
+	return metricName + "-" + s.metadata.propertyPath
+}
+
+// GetMetrics returns value for a supported metric and an error if there is a problem getting the metric
+func (s *azureIotHubTwinScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	val, err := s.getDesiredPropertyValue(ctx)
+	if err != nil {
+		azureIotHubTwinLog.Error(err, "error getting iot hub twin desired property")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewMilliQuantity(int64(val*1000), resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
+
+// sasToken generates a shared access signature for the hub, as described in
+// https://docs.microsoft.com/azure/iot-hub/iot-hub-dev-guide-sas#security-tokens
+func (s *azureIotHubTwinScaler) sasToken(expiry time.Time) (string, error) {
+	key, err := base64.StdEncoding.DecodeString(s.metadata.sharedAccessKey)
+	if err != nil {
+		return "", err
+	}
+
+	resourceURI := url.QueryEscape(strings.TrimPrefix(strings.TrimPrefix(s.metadata.hubURL, "https://"), "http://"))
+	se := strconv.FormatInt(expiry.Unix(), 10)
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(resourceURI + "\n" + se))
+	sig := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	return fmt.Sprintf("SharedAccessSignature sr=%s&sig=%s&se=%s&skn=%s",
+		resourceURI, url.QueryEscape(sig), se, url.QueryEscape(s.metadata.sharedAccessKeyName)), nil
+}
+
+// getDesiredPropertyValue reads the twin and returns the numeric desired property at propertyPath
+func (s *azureIotHubTwinScaler) getDesiredPropertyValue(ctx context.Context) (float64, error) {
+	twinPath := "/twins/" + url.PathEscape(s.metadata.deviceID)
+	if s.metadata.moduleID != "" {
+		twinPath += "/modules/" + url.PathEscape(s.metadata.moduleID)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s%s?api-version=%s", s.metadata.hubURL, twinPath, iotHubTwinAPIVersion), nil)
+	if err != nil {
+		return -1, err
+	}
+	token, err := s.sasToken(time.Now().Add(iotHubSasTokenTTL))
+	if err != nil {
+		return -1, fmt.Errorf("error generating iot hub sas token: %s", err)
+	}
+	req.Header.Set("Authorization", token)
+
+	r, err := s.httpClient.Do(req)
+	if err != nil {
+		return -1, err
+	}
+	defer r.Body.Close()
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return -1, err
+	}
+	if r.StatusCode != http.StatusOK {
+		return -1, fmt.Errorf("iot hub api returned %d: %s", r.StatusCode, string(b))
+	}
+
+	property := gjson.GetBytes(b, "properties.desired."+s.metadata.propertyPath)
+	switch property.Type {
+	case gjson.Number:
+		return property.Float(), nil
+	case gjson.Null:
+		return -1, fmt.Errorf("desired property %s not found in twin", s.metadata.propertyPath)
+	default:
+		return -1, fmt.Errorf("desired property %s is not a number", s.metadata.propertyPath)
+	}
+}
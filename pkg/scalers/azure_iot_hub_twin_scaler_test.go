@@ -0,0 +1,155 @@
+//go:build !selective_scalers || scalers_azure
+// +build !selective_scalers scalers_azure
+
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testIotHubConnection = "HostName=myhub.azure-devices.net;SharedAccessKeyName=registryRead;SharedAccessKey=c2VjcmV0S2V5MTIz"
+
+type parseAzureIotHubTwinMetadataTestData struct {
+	metadata    map[string]string
+	resolvedEnv map[string]string
+	authParams  map[string]string
+	isError     bool
+	comment     string
+}
+
+type azureIotHubTwinMetricIdentifier struct {
+	metadataTestData *parseAzureIotHubTwinMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testAzureIotHubTwinMetadata = []parseAzureIotHubTwinMetadataTestData{
+	{map[string]string{}, map[string]string{}, map[string]string{}, true, "nothing passed"},
+	{map[string]string{"deviceId": "edge-01", "propertyPath": "pendingJobs", "targetValue": "5"}, map[string]string{}, map[string]string{"connection": testIotHubConnection}, false, "device twin"},
+	{map[string]string{"deviceId": "edge-01", "moduleId": "coordinator", "propertyPath": "demand.sessions", "targetValue": "2.5", "activationTargetValue": "1"}, map[string]string{}, map[string]string{"connection": testIotHubConnection}, false, "module twin"},
+	{map[string]string{"connectionFromEnv": "IOT_HUB_CONNECTION", "deviceId": "edge-01", "propertyPath": "pendingJobs", "targetValue": "5"}, map[string]string{"IOT_HUB_CONNECTION": testIotHubConnection}, map[string]string{}, false, "connection from env"},
+	{map[string]string{"deviceId": "edge-01", "propertyPath": "pendingJobs", "targetValue": "5"}, map[string]string{}, map[string]string{}, true, "missing connection"},
+	{map[string]string{"deviceId": "edge-01", "propertyPath": "pendingJobs", "targetValue": "5"}, map[string]string{}, map[string]string{"connection": "HostName=myhub.azure-devices.net;SharedAccessKeyName=registryRead"}, true, "connection without key"},
+	{map[string]string{"deviceId": "edge-01", "propertyPath": "pendingJobs", "targetValue": "5"}, map[string]string{}, map[string]string{"connection": "HostName=myhub.azure-devices.net;SharedAccessKeyName=registryRead;SharedAccessKey=!!"}, true, "key not base64"},
+	{map[string]string{"propertyPath": "pendingJobs", "targetValue": "5"}, map[string]string{}, map[string]string{"connection": testIotHubConnection}, true, "missing deviceId"},
+	{map[string]string{"deviceId": "edge-01", "targetValue": "5"}, map[string]string{}, map[string]string{"connection": testIotHubConnection}, true, "missing propertyPath"},
+	{map[string]string{"deviceId": "edge-01", "propertyPath": "pendingJobs"}, map[string]string{}, map[string]string{"connection": testIotHubConnection}, true, "missing targetValue"},
+	{map[string]string{"deviceId": "edge-01", "propertyPath": "pendingJobs", "targetValue": "A"}, map[string]string{}, map[string]string{"connection": testIotHubConnection}, true, "invalid targetValue"},
+	{map[string]string{"deviceId": "edge-01", "propertyPath": "pendingJobs", "targetValue": "5", "activationTargetValue": "A"}, map[string]string{}, map[string]string{"connection": testIotHubConnection}, true, "invalid activationTargetValue"},
+}
+
+var azureIotHubTwinMetricIdentifiers = []azureIotHubTwinMetricIdentifier{
+	{&testAzureIotHubTwinMetadata[1], 0, "s0-azure-iot-hub-twin-edge-01-pendingJobs"},
+	{&testAzureIotHubTwinMetadata[2], 1, "s1-azure-iot-hub-twin-edge-01-coordinator-demand-sessions"},
+}
+
+func TestAzureIotHubTwinParseMetadata(t *testing.T) {
+	for _, testData := range testAzureIotHubTwinMetadata {
+		_, err := parseAzureIotHubTwinMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, ResolvedEnv: testData.resolvedEnv, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Errorf("%s: expected success but got error: %s", testData.comment, err)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("%s: expected error but got success", testData.comment)
+		}
+	}
+}
+
+func TestAzureIotHubTwinGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range azureIotHubTwinMetricIdentifiers {
+		meta, err := parseAzureIotHubTwinMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, ResolvedEnv: testData.metadataTestData.resolvedEnv, AuthParams: testData.metadataTestData.authParams, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockScaler := azureIotHubTwinScaler{
+			metadata:   meta,
+			httpClient: http.DefaultClient,
+		}
+
+		metricSpec := mockScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Error("Wrong External metric source name:", metricName, "expected:", testData.name)
+		}
+	}
+}
+
+func TestAzureIotHubTwinGetDesiredPropertyValue(t *testing.T) {
+	var requestPath, authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestPath = r.URL.Path
+		authorization = r.Header.Get("Authorization")
+		switch r.URL.Path {
+		case "/twins/edge-01":
+			fmt.Fprint(w, `{"deviceId":"edge-01","properties":{"desired":{"pendingJobs":7,"$version":3}}}`)
+		case "/twins/edge-01/modules/coordinator":
+			fmt.Fprint(w, `{"deviceId":"edge-01","moduleId":"coordinator","properties":{"desired":{"demand":{"sessions":1.5,"mode":"eco"}}}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"Message":"ErrorCode:DeviceNotFound"}`)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		deviceID     string
+		moduleID     string
+		propertyPath string
+		value        float64
+		isError      bool
+	}{
+		{"edge-01", "", "pendingJobs", 7, false},
+		{"edge-01", "coordinator", "demand.sessions", 1.5, false},
+		{"edge-01", "coordinator", "demand.mode", 0, true},
+		{"edge-01", "", "missing", 0, true},
+		{"edge-02", "", "pendingJobs", 0, true},
+	}
+
+	for _, test := range tests {
+		s := azureIotHubTwinScaler{
+			metadata: &azureIotHubTwinMetadata{
+				hubURL:              server.URL,
+				sharedAccessKeyName: "registryRead",
+				sharedAccessKey:     "c2VjcmV0S2V5MTIz",
+				deviceID:            test.deviceID,
+				moduleID:            test.moduleID,
+				propertyPath:        test.propertyPath,
+			},
+			httpClient: http.DefaultClient,
+		}
+
+		value, err := s.getDesiredPropertyValue(context.Background())
+		if test.isError {
+			assert.Error(t, err, test.propertyPath)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, test.value, value)
+		assert.True(t, strings.HasPrefix(requestPath, "/twins/"+test.deviceID))
+		assert.True(t, strings.HasPrefix(authorization, "SharedAccessSignature sr="))
+		assert.Contains(t, authorization, "&skn=registryRead")
+	}
+}
@@ -1,3 +1,6 @@
+//go:build !selective_scalers || scalers_azure
+// +build !selective_scalers scalers_azure
+
 /*
 Copyright 2021 The KEDA Authors
 
@@ -31,6 +34,7 @@ import (
 	"sync"
 	"time"
 
+	az "github.com/Azure/go-autorest/autorest/azure"
 	v2beta2 "k8s.io/api/autoscaling/v2beta2"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -39,15 +43,26 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/scalers/azure"
 	kedautil "github.com/kedacore/keda/v2/pkg/util"
 )
 
 const (
-	miEndpoint       = "http://169.254.169.254/metadata/identity/oauth2/token?api-version=2018-02-01&resource=https%3A%2F%2Fapi.loganalytics.io%2F"
-	aadTokenEndpoint = "https://login.microsoftonline.com/%s/oauth2/token"
-	laQueryEndpoint  = "https://api.loganalytics.io/v1/workspaces/%s/query"
+	miEndpoint                = "http://169.254.169.254/metadata/identity/oauth2/token?api-version=2018-02-01&resource=%s"
+	aadTokenEndpoint          = "%s%s/oauth2/token"
+	laQueryEndpoint           = "%s/v1/workspaces/%s/query"
+	laResourceQueryEndpoint   = "%s/v1%s/query"
+	laQueryPackQueryEndpoint  = "%s%s?api-version=2019-09-01"
+	laQueryPackQueryIDPattern = "/providers/microsoft.operationalinsights/querypacks/"
 )
 
+// logAnalyticsResourceURLs are the Log Analytics API endpoints of the clouds, go-autorest doesn't know all of them
+var logAnalyticsResourceURLs = map[string]string{
+	az.PublicCloud.Name:       "https://api.loganalytics.io",
+	az.ChinaCloud.Name:        "https://api.loganalytics.azure.cn",
+	az.USGovernmentCloud.Name: "https://api.loganalytics.us",
+}
+
 type azureLogAnalyticsScaler struct {
 	metadata   *azureLogAnalyticsMetadata
 	cache      *sessionCache
@@ -61,11 +76,19 @@ type azureLogAnalyticsMetadata struct {
 	clientID     string
 	clientSecret string
 	workspaceID  string
-	podIdentity  string
-	query        string
-	threshold    int64
-	metricName   string // Custom metric name for trigger
-	scalerIndex  int
+	// workspaceResourceID is the ARM resource ID queried instead of workspaceID, eg. of an Azure Arc-connected
+	// workspace or machine
+	workspaceResourceID string
+	podIdentity         string
+	query               string
+	// queryPackQueryID is the ARM resource ID of a query saved in a query pack, which is run instead of query
+	queryPackQueryID        string
+	threshold               int64
+	logAnalyticsResourceURL string
+	activeDirectoryEndpoint string
+	resourceManagerEndpoint string
+	metricName              string // Custom metric name for trigger
+	scalerIndex             int
 }
 
 type sessionCache struct {
@@ -113,12 +136,17 @@ func NewAzureLogAnalyticsScaler(config *ScalerConfig) (Scaler, error) {
 		return nil, fmt.Errorf("failed to initialize Log Analytics scaler. Scaled object: %s. Namespace: %s. Inner Error: %v", config.Name, config.Namespace, err)
 	}
 
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
 	return &azureLogAnalyticsScaler{
 		metadata:   azureLogAnalyticsMetadata,
 		cache:      &sessionCache{metricValue: -1, metricThreshold: -1},
 		name:       config.Name,
 		namespace:  config.Namespace,
-		httpClient: kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, false),
+		httpClient: httpClient,
 	}, nil
 }
 
@@ -154,19 +182,37 @@ func parseAzureLogAnalyticsMetadata(config *ScalerConfig) (*azureLogAnalyticsMet
 		return nil, fmt.Errorf("error parsing metadata. Details: Log Analytics Scaler doesn't support pod identity %s", config.PodIdentity)
 	}
 
-	// Getting workspaceId
-	workspaceID, err := getParameterFromConfig(config, "workspaceId", true)
-	if err != nil {
-		return nil, err
+	// Getting workspaceId or workspaceResourceId
+	workspaceID, _ := getParameterFromConfig(config, "workspaceId", true)
+	workspaceResourceID, _ := getParameterFromConfig(config, "workspaceResourceId", true)
+	switch {
+	case workspaceID == "" && workspaceResourceID == "":
+		return nil, fmt.Errorf("error parsing metadata. Details: workspaceId or workspaceResourceId was not found in metadata. Check your ScaledObject configuration")
+	case workspaceID != "" && workspaceResourceID != "":
+		return nil, fmt.Errorf("error parsing metadata. Details: only one of workspaceId or workspaceResourceId can be set")
+	case workspaceResourceID != "" && !strings.HasPrefix(workspaceResourceID, "/subscriptions/"):
+		return nil, fmt.Errorf("error parsing metadata. Details: workspaceResourceId has to be an ARM resource ID starting with /subscriptions/")
 	}
 	meta.workspaceID = workspaceID
+	meta.workspaceResourceID = strings.TrimSuffix(workspaceResourceID, "/")
+
+	// Getting query or queryPackQueryId, observe that we dont check AuthParams for them
+	query, _ := getParameterFromConfig(config, "query", false)
+	queryPackQueryID, _ := getParameterFromConfig(config, "queryPackQueryId", false)
+	switch {
+	case query == "" && queryPackQueryID == "":
+		return nil, fmt.Errorf("error parsing metadata. Details: query or queryPackQueryId was not found in metadata. Check your ScaledObject configuration")
+	case query != "" && queryPackQueryID != "":
+		return nil, fmt.Errorf("error parsing metadata. Details: only one of query or queryPackQueryId can be set")
+	case queryPackQueryID != "" && !strings.Contains(strings.ToLower(queryPackQueryID), laQueryPackQueryIDPattern):
+		return nil, fmt.Errorf("error parsing metadata. Details: queryPackQueryId has to be the resource ID of a query in a query pack")
+	}
+	meta.query = query
+	meta.queryPackQueryID = queryPackQueryID
 
-	// Getting query, observe that we dont check AuthParams for query
-	query, err := getParameterFromConfig(config, "query", false)
-	if err != nil {
+	if err := parseAzureLogAnalyticsEndpoints(config, &meta); err != nil {
 		return nil, err
 	}
-	meta.query = query
 
 	// Getting threshold, observe that we dont check AuthParams for threshold
 	val, err := getParameterFromConfig(config, "threshold", false)
@@ -182,8 +228,11 @@ func parseAzureLogAnalyticsMetadata(config *ScalerConfig) (*azureLogAnalyticsMet
 	// Resolve metricName
 	if val, ok := config.TriggerMetadata["metricName"]; ok {
 		meta.metricName = kedautil.NormalizeString(fmt.Sprintf("%s-%s", "azure-log-analytics", val))
-	} else {
+	} else if meta.workspaceID != "" {
 		meta.metricName = kedautil.NormalizeString(fmt.Sprintf("%s-%s", "azure-log-analytics", meta.workspaceID))
+	} else {
+		resourceName := meta.workspaceResourceID[strings.LastIndex(meta.workspaceResourceID, "/")+1:]
+		meta.metricName = kedautil.NormalizeString(fmt.Sprintf("%s-%s", "azure-log-analytics", resourceName))
 	}
 
 	meta.scalerIndex = config.ScalerIndex
@@ -191,6 +240,40 @@ func parseAzureLogAnalyticsMetadata(config *ScalerConfig) (*azureLogAnalyticsMet
 	return &meta, nil
 }
 
+// parseAzureLogAnalyticsEndpoints resolves the Log Analytics, Azure Active Directory and Azure Resource Manager
+// endpoints of the cloud, a private cloud has to provide them in the metadata
+func parseAzureLogAnalyticsEndpoints(config *ScalerConfig, meta *azureLogAnalyticsMetadata) error {
+	env, err := azure.ParseEnvironment(config.TriggerMetadata)
+	if err != nil {
+		return fmt.Errorf("error parsing metadata. Details: %s", err)
+	}
+
+	if env.Name == azure.PrivateCloud {
+		meta.logAnalyticsResourceURL = config.TriggerMetadata["logAnalyticsResourceURL"]
+		if meta.logAnalyticsResourceURL == "" {
+			return fmt.Errorf("error parsing metadata. Details: logAnalyticsResourceURL must be provided for %s cloud type", azure.PrivateCloud)
+		}
+		endpoints := []string{azure.ActiveDirectoryEndpointKey}
+		if meta.queryPackQueryID != "" {
+			endpoints = append(endpoints, azure.ResourceManagerEndpointKey)
+		}
+		if err := azure.RequireEnvironmentEndpoints(env, endpoints...); err != nil {
+			return fmt.Errorf("error parsing metadata. Details: %s", err)
+		}
+	} else {
+		resourceURL, ok := logAnalyticsResourceURLs[env.Name]
+		if !ok {
+			return fmt.Errorf("error parsing metadata. Details: Log Analytics is not available in cloud environment %s", env.Name)
+		}
+		meta.logAnalyticsResourceURL = resourceURL
+	}
+
+	meta.logAnalyticsResourceURL = strings.TrimSuffix(meta.logAnalyticsResourceURL, "/")
+	meta.activeDirectoryEndpoint = env.ActiveDirectoryEndpoint
+	meta.resourceManagerEndpoint = strings.TrimSuffix(env.ResourceManagerEndpoint, "/")
+	return nil
+}
+
 // getParameterFromConfig gets the parameter from the configs, if checkAuthParams is true
 // then AuthParams is also check for the parameter
 func getParameterFromConfig(config *ScalerConfig, parameter string, checkAuthParams bool) (string, error) {
@@ -278,7 +361,15 @@ func (s *azureLogAnalyticsScaler) updateCache(ctx context.Context) error {
 }
 
 func (s *azureLogAnalyticsScaler) getMetricData(ctx context.Context) (metricsData, error) {
-	tokenInfo, err := s.getAccessToken(ctx)
+	if s.metadata.query == "" {
+		query, err := s.getQueryPackQuery(ctx)
+		if err != nil {
+			return metricsData{}, err
+		}
+		s.metadata.query = query
+	}
+
+	tokenInfo, err := s.getAccessToken(ctx, s.logAnalyticsResource())
 	if err != nil {
 		return metricsData{}, err
 	}
@@ -293,29 +384,69 @@ func (s *azureLogAnalyticsScaler) getMetricData(ctx context.Context) (metricsDat
 	return metricsInfo, nil
 }
 
-func (s *azureLogAnalyticsScaler) getAccessToken(ctx context.Context) (tokenData, error) {
+// logAnalyticsResource is the resource the tokens for the Log Analytics API are requested for
+func (s *azureLogAnalyticsScaler) logAnalyticsResource() string {
+	return s.metadata.logAnalyticsResourceURL + "/"
+}
+
+// getQueryPackQuery returns the body of the query saved in the query pack
+func (s *azureLogAnalyticsScaler) getQueryPackQuery(ctx context.Context) (string, error) {
+	tokenInfo, err := s.getAccessToken(ctx, s.metadata.resourceManagerEndpoint+"/")
+	if err != nil {
+		return "", err
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(laQueryPackQueryEndpoint, s.metadata.resourceManagerEndpoint, s.metadata.queryPackQueryID), nil)
+	if err != nil {
+		return "", fmt.Errorf("can't construct HTTP request to Azure Resource Manager. Inner Error: %v", err)
+	}
+	request.Header.Add("Authorization", fmt.Sprintf("Bearer %s", tokenInfo.AccessToken))
+
+	body, statusCode, err := s.runHTTP(request, "Azure Resource Manager")
+	if err != nil {
+		return "", err
+	}
+	if statusCode != 200 {
+		return "", fmt.Errorf("error getting query pack query %s. HTTP code: %d. Body: %s", s.metadata.queryPackQueryID, statusCode, string(body))
+	}
+
+	var queryPackQuery struct {
+		Properties struct {
+			Body string `json:"body"`
+		} `json:"properties"`
+	}
+	if err := json.Unmarshal(body, &queryPackQuery); err != nil {
+		return "", fmt.Errorf("error getting query pack query %s. Details: can't decode response body to JSON. Inner Error: %v. Body: %s", s.metadata.queryPackQueryID, err, string(body))
+	}
+	if queryPackQuery.Properties.Body == "" {
+		return "", fmt.Errorf("error getting query pack query %s. Details: query has no body", s.metadata.queryPackQueryID)
+	}
+	return queryPackQuery.Properties.Body, nil
+}
+
+func (s *azureLogAnalyticsScaler) getAccessToken(ctx context.Context, resource string) (tokenData, error) {
 	// if there is no token yet or it will be expired in less, that 30 secs
 	currentTimeSec := time.Now().Unix()
 	tokenInfo := tokenData{}
 
 	if s.metadata.podIdentity == "" {
-		tokenInfo, _ = getTokenFromCache(s.metadata.clientID, s.metadata.clientSecret)
+		tokenInfo, _ = getTokenFromCache(s.metadata.clientID, s.metadata.clientSecret, resource)
 	} else {
-		tokenInfo, _ = getTokenFromCache(s.metadata.podIdentity, s.metadata.podIdentity)
+		tokenInfo, _ = getTokenFromCache(s.metadata.podIdentity, s.metadata.podIdentity, resource)
 	}
 
 	if currentTimeSec+30 > tokenInfo.ExpiresOn {
-		newTokenInfo, err := s.refreshAccessToken(ctx)
+		newTokenInfo, err := s.refreshAccessToken(ctx, resource)
 		if err != nil {
 			return tokenData{}, err
 		}
 
 		if s.metadata.podIdentity == "" {
-			logAnalyticsLog.V(1).Info("Token for Service Principal has been refreshed", "clientID", s.metadata.clientID, "scaler name", s.name, "namespace", s.namespace)
-			_ = setTokenInCache(s.metadata.clientID, s.metadata.clientSecret, newTokenInfo)
+			logAnalyticsLog.V(1).Info("Token for Service Principal has been refreshed", "clientID", s.metadata.clientID, "resource", resource, "scaler name", s.name, "namespace", s.namespace)
+			_ = setTokenInCache(s.metadata.clientID, s.metadata.clientSecret, resource, newTokenInfo)
 		} else {
-			logAnalyticsLog.V(1).Info("Token for Pod Identity has been refreshed", "type", s.metadata.podIdentity, "scaler name", s.name, "namespace", s.namespace)
-			_ = setTokenInCache(s.metadata.podIdentity, s.metadata.podIdentity, newTokenInfo)
+			logAnalyticsLog.V(1).Info("Token for Pod Identity has been refreshed", "type", s.metadata.podIdentity, "resource", resource, "scaler name", s.name, "namespace", s.namespace)
+			_ = setTokenInCache(s.metadata.podIdentity, s.metadata.podIdentity, resource, newTokenInfo)
 		}
 
 		return newTokenInfo, nil
@@ -333,17 +464,18 @@ func (s *azureLogAnalyticsScaler) executeQuery(ctx context.Context, query string
 
 	// Handle expired token
 	if statusCode == 403 || (len(body) > 0 && strings.Contains(string(body), "TokenExpired")) {
-		tokenInfo, err = s.refreshAccessToken(ctx)
+		resource := s.logAnalyticsResource()
+		tokenInfo, err = s.refreshAccessToken(ctx, resource)
 		if err != nil {
 			return metricsData{}, err
 		}
 
 		if s.metadata.podIdentity == "" {
-			logAnalyticsLog.V(1).Info("Token for Service Principal has been refreshed", "clientID", s.metadata.clientID, "scaler name", s.name, "namespace", s.namespace)
-			_ = setTokenInCache(s.metadata.clientID, s.metadata.clientSecret, tokenInfo)
+			logAnalyticsLog.V(1).Info("Token for Service Principal has been refreshed", "clientID", s.metadata.clientID, "resource", resource, "scaler name", s.name, "namespace", s.namespace)
+			_ = setTokenInCache(s.metadata.clientID, s.metadata.clientSecret, resource, tokenInfo)
 		} else {
-			logAnalyticsLog.V(1).Info("Token for Pod Identity has been refreshed", "type", s.metadata.podIdentity, "scaler name", s.name, "namespace", s.namespace)
-			_ = setTokenInCache(s.metadata.podIdentity, s.metadata.podIdentity, tokenInfo)
+			logAnalyticsLog.V(1).Info("Token for Pod Identity has been refreshed", "type", s.metadata.podIdentity, "resource", resource, "scaler name", s.name, "namespace", s.namespace)
+			_ = setTokenInCache(s.metadata.podIdentity, s.metadata.podIdentity, resource, tokenInfo)
 		}
 
 		if err == nil {
@@ -431,8 +563,8 @@ func parseTableValueToInt64(value interface{}, dataType string) (int64, error) {
 	return 0, fmt.Errorf("error validating Log Analytics request. Details: value is empty, check your query")
 }
 
-func (s *azureLogAnalyticsScaler) refreshAccessToken(ctx context.Context) (tokenData, error) {
-	tokenInfo, err := s.getAuthorizationToken(ctx)
+func (s *azureLogAnalyticsScaler) refreshAccessToken(ctx context.Context, resource string) (tokenData, error) {
+	tokenInfo, err := s.getAuthorizationToken(ctx, resource)
 
 	if err != nil {
 		return tokenData{}, err
@@ -453,16 +585,16 @@ func (s *azureLogAnalyticsScaler) refreshAccessToken(ctx context.Context) (token
 	return tokenInfo, nil
 }
 
-func (s *azureLogAnalyticsScaler) getAuthorizationToken(ctx context.Context) (tokenData, error) {
+func (s *azureLogAnalyticsScaler) getAuthorizationToken(ctx context.Context, resource string) (tokenData, error) {
 	var body []byte
 	var statusCode int
 	var err error
 	var tokenInfo tokenData
 
 	if s.metadata.podIdentity == "" {
-		body, statusCode, err = s.executeAADApicall(ctx)
+		body, statusCode, err = s.executeAADApicall(ctx, resource)
 	} else {
-		body, statusCode, err = s.executeIMDSApicall(ctx)
+		body, statusCode, err = s.executeIMDSApicall(ctx, resource)
 	}
 
 	if err != nil {
@@ -491,7 +623,12 @@ func (s *azureLogAnalyticsScaler) executeLogAnalyticsREST(ctx context.Context, q
 		return nil, 0, fmt.Errorf("can't construct JSON for request to Log Analytics API. Inner Error: %v", err)
 	}
 
-	request, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf(laQueryEndpoint, s.metadata.workspaceID), bytes.NewBuffer(jsonBytes)) // URL-encoded payload
+	queryURL := fmt.Sprintf(laQueryEndpoint, s.metadata.logAnalyticsResourceURL, s.metadata.workspaceID)
+	if s.metadata.workspaceResourceID != "" {
+		queryURL = fmt.Sprintf(laResourceQueryEndpoint, s.metadata.logAnalyticsResourceURL, s.metadata.workspaceResourceID)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, queryURL, bytes.NewBuffer(jsonBytes)) // URL-encoded payload
 	if err != nil {
 		return nil, 0, fmt.Errorf("can't construct HTTP request to Log Analytics API. Inner Error: %v", err)
 	}
@@ -503,16 +640,16 @@ func (s *azureLogAnalyticsScaler) executeLogAnalyticsREST(ctx context.Context, q
 	return s.runHTTP(request, "Log Analytics REST api")
 }
 
-func (s *azureLogAnalyticsScaler) executeAADApicall(ctx context.Context) ([]byte, int, error) {
+func (s *azureLogAnalyticsScaler) executeAADApicall(ctx context.Context, resource string) ([]byte, int, error) {
 	data := url.Values{
 		"grant_type":    {"client_credentials"},
 		"client_id":     {s.metadata.clientID},
 		"redirect_uri":  {"http://"},
-		"resource":      {"https://api.loganalytics.io/"},
+		"resource":      {resource},
 		"client_secret": {s.metadata.clientSecret},
 	}
 
-	request, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf(aadTokenEndpoint, s.metadata.tenantID), strings.NewReader(data.Encode())) // URL-encoded payload
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf(aadTokenEndpoint, s.metadata.activeDirectoryEndpoint, s.metadata.tenantID), strings.NewReader(data.Encode())) // URL-encoded payload
 	if err != nil {
 		return nil, 0, fmt.Errorf("can't construct HTTP request to Azure Active Directory. Inner Error: %v", err)
 	}
@@ -523,8 +660,8 @@ func (s *azureLogAnalyticsScaler) executeAADApicall(ctx context.Context) ([]byte
 	return s.runHTTP(request, "AAD")
 }
 
-func (s *azureLogAnalyticsScaler) executeIMDSApicall(ctx context.Context) ([]byte, int, error) {
-	request, err := http.NewRequestWithContext(ctx, http.MethodGet, miEndpoint, nil)
+func (s *azureLogAnalyticsScaler) executeIMDSApicall(ctx context.Context, resource string) ([]byte, int, error) {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf(miEndpoint, url.QueryEscape(resource)), nil)
 	if err != nil {
 		return nil, 0, fmt.Errorf("can't construct HTTP request to Azure Instance Metadata service. Inner Error: %v", err)
 	}
@@ -556,8 +693,8 @@ func (s *azureLogAnalyticsScaler) runHTTP(request *http.Request, caller string)
 	return body, resp.StatusCode, nil
 }
 
-func getTokenFromCache(clientID string, clientSecret string) (tokenData, error) {
-	key, err := getHash(clientID, clientSecret)
+func getTokenFromCache(clientID string, clientSecret string, resource string) (tokenData, error) {
+	key, err := getHash(clientID, clientSecret, resource)
 	if err != nil {
 		return tokenData{}, fmt.Errorf("error calculating sha1 hash. Inner Error: %v", err)
 	}
@@ -573,8 +710,8 @@ func getTokenFromCache(clientID string, clientSecret string) (tokenData, error)
 	return tokenData{}, fmt.Errorf("error getting value from token cache. Details: unknown error")
 }
 
-func setTokenInCache(clientID string, clientSecret string, tokenInfo tokenData) error {
-	key, err := getHash(clientID, clientSecret)
+func setTokenInCache(clientID string, clientSecret string, resource string, tokenInfo tokenData) error {
+	key, err := getHash(clientID, clientSecret, resource)
 	if err != nil {
 		return err
 	}
@@ -586,9 +723,9 @@ func setTokenInCache(clientID string, clientSecret string, tokenInfo tokenData)
 	return nil
 }
 
-func getHash(clientID string, clientSecret string) (string, error) {
+func getHash(clientID string, clientSecret string, resource string) (string, error) {
 	sha1Hash := sha1.New()
-	_, err := sha1Hash.Write([]byte(fmt.Sprintf("%s|%s", clientID, clientSecret)))
+	_, err := sha1Hash.Write([]byte(fmt.Sprintf("%s|%s|%s", clientID, clientSecret, resource)))
 
 	if err != nil {
 		return "", err
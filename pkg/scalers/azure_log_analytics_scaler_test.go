@@ -1,3 +1,6 @@
+//go:build !selective_scalers || scalers_azure
+// +build !selective_scalers scalers_azure
+
 /*
 Copyright 2021 The KEDA Authors
 
@@ -19,8 +22,12 @@ package scalers
 import (
 	"context"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/stretchr/testify/assert"
+
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 )
 
@@ -43,7 +50,9 @@ type LogAnalyticsMetricIdentifier struct {
 }
 
 var (
-	query = "let x = 10; let y = 1; print MetricValue = x, Threshold = y;"
+	query               = "let x = 10; let y = 1; print MetricValue = x, Threshold = y;"
+	workspaceResourceID = "/subscriptions/5bd1b0b4-4e1d-4a77-a7d3-3c4d3dba2a0f/resourceGroups/arc/providers/Microsoft.OperationalInsights/workspaces/arc-workspace"
+	queryPackQueryID    = "/subscriptions/5bd1b0b4-4e1d-4a77-a7d3-3c4d3dba2a0f/resourceGroups/arc/providers/Microsoft.OperationalInsights/queryPacks/scaling/queries/4337c8d0-1b8c-4d7e-9ec2-d35b6bd4c0a1"
 )
 
 // Faked parameters
@@ -89,6 +98,30 @@ var testLogAnalyticsMetadata = []parseLogAnalyticsMetadataTestData{
 	{map[string]string{"tenantId": "d248da64-0e1e-4f79-b8c6-72ab7aa055eb", "clientId": "41826dd4-9e0a-4357-a5bd-a88ad771ea7d", "clientSecret": "U6DtAX5r6RPZxd~l12Ri3X8J9urt5Q-xs", "workspaceId": "074dd9f8-c368-4220-9400-acb6e80fc325", "query": query, "threshold": "1900000000"}, false},
 	// All parameters set, should succeed
 	{map[string]string{"tenantIdFromEnv": "d248da64-0e1e-4f79-b8c6-72ab7aa055eb", "clientIdFromEnv": "41826dd4-9e0a-4357-a5bd-a88ad771ea7d", "clientSecretFromEnv": "U6DtAX5r6RPZxd~l12Ri3X8J9urt5Q-xs", "workspaceIdFromEnv": "074dd9f8-c368-4220-9400-acb6e80fc325", "query": query, "threshold": "1900000000"}, false},
+	// workspaceResourceId instead of workspaceId, should succeed
+	{map[string]string{"tenantId": "d248da64-0e1e-4f79-b8c6-72ab7aa055eb", "clientId": "41826dd4-9e0a-4357-a5bd-a88ad771ea7d", "clientSecret": "U6DtAX5r6RPZxd~l12Ri3X8J9urt5Q-xs", "workspaceResourceId": workspaceResourceID, "query": query, "threshold": "1900000000"}, false},
+	// workspaceId and workspaceResourceId, should fail
+	{map[string]string{"tenantId": "d248da64-0e1e-4f79-b8c6-72ab7aa055eb", "clientId": "41826dd4-9e0a-4357-a5bd-a88ad771ea7d", "clientSecret": "U6DtAX5r6RPZxd~l12Ri3X8J9urt5Q-xs", "workspaceId": "074dd9f8-c368-4220-9400-acb6e80fc325", "workspaceResourceId": workspaceResourceID, "query": query, "threshold": "1900000000"}, true},
+	// workspaceResourceId is not a resource ID, should fail
+	{map[string]string{"tenantId": "d248da64-0e1e-4f79-b8c6-72ab7aa055eb", "clientId": "41826dd4-9e0a-4357-a5bd-a88ad771ea7d", "clientSecret": "U6DtAX5r6RPZxd~l12Ri3X8J9urt5Q-xs", "workspaceResourceId": "arc-workspace", "query": query, "threshold": "1900000000"}, true},
+	// queryPackQueryId instead of query, should succeed
+	{map[string]string{"tenantId": "d248da64-0e1e-4f79-b8c6-72ab7aa055eb", "clientId": "41826dd4-9e0a-4357-a5bd-a88ad771ea7d", "clientSecret": "U6DtAX5r6RPZxd~l12Ri3X8J9urt5Q-xs", "workspaceId": "074dd9f8-c368-4220-9400-acb6e80fc325", "queryPackQueryId": queryPackQueryID, "threshold": "1900000000"}, false},
+	// query and queryPackQueryId, should fail
+	{map[string]string{"tenantId": "d248da64-0e1e-4f79-b8c6-72ab7aa055eb", "clientId": "41826dd4-9e0a-4357-a5bd-a88ad771ea7d", "clientSecret": "U6DtAX5r6RPZxd~l12Ri3X8J9urt5Q-xs", "workspaceId": "074dd9f8-c368-4220-9400-acb6e80fc325", "query": query, "queryPackQueryId": queryPackQueryID, "threshold": "1900000000"}, true},
+	// queryPackQueryId is not a query pack query, should fail
+	{map[string]string{"tenantId": "d248da64-0e1e-4f79-b8c6-72ab7aa055eb", "clientId": "41826dd4-9e0a-4357-a5bd-a88ad771ea7d", "clientSecret": "U6DtAX5r6RPZxd~l12Ri3X8J9urt5Q-xs", "workspaceId": "074dd9f8-c368-4220-9400-acb6e80fc325", "queryPackQueryId": workspaceResourceID, "threshold": "1900000000"}, true},
+	// Azure China cloud, should succeed
+	{map[string]string{"tenantId": "d248da64-0e1e-4f79-b8c6-72ab7aa055eb", "clientId": "41826dd4-9e0a-4357-a5bd-a88ad771ea7d", "clientSecret": "U6DtAX5r6RPZxd~l12Ri3X8J9urt5Q-xs", "workspaceId": "074dd9f8-c368-4220-9400-acb6e80fc325", "query": query, "threshold": "1900000000", "cloud": "AzureChinaCloud"}, false},
+	// Unknown cloud, should fail
+	{map[string]string{"tenantId": "d248da64-0e1e-4f79-b8c6-72ab7aa055eb", "clientId": "41826dd4-9e0a-4357-a5bd-a88ad771ea7d", "clientSecret": "U6DtAX5r6RPZxd~l12Ri3X8J9urt5Q-xs", "workspaceId": "074dd9f8-c368-4220-9400-acb6e80fc325", "query": query, "threshold": "1900000000", "cloud": "MarsCloud"}, true},
+	// Cloud without Log Analytics, should fail
+	{map[string]string{"tenantId": "d248da64-0e1e-4f79-b8c6-72ab7aa055eb", "clientId": "41826dd4-9e0a-4357-a5bd-a88ad771ea7d", "clientSecret": "U6DtAX5r6RPZxd~l12Ri3X8J9urt5Q-xs", "workspaceId": "074dd9f8-c368-4220-9400-acb6e80fc325", "query": query, "threshold": "1900000000", "cloud": "AzureGermanCloud"}, true},
+	// Private cloud with endpoints, should succeed
+	{map[string]string{"tenantId": "d248da64-0e1e-4f79-b8c6-72ab7aa055eb", "clientId": "41826dd4-9e0a-4357-a5bd-a88ad771ea7d", "clientSecret": "U6DtAX5r6RPZxd~l12Ri3X8J9urt5Q-xs", "workspaceId": "074dd9f8-c368-4220-9400-acb6e80fc325", "query": query, "threshold": "1900000000", "cloud": "Private", "logAnalyticsResourceURL": "https://api.loganalytics.private", "activeDirectoryEndpoint": "https://login.private/"}, false},
+	// Private cloud without logAnalyticsResourceURL, should fail
+	{map[string]string{"tenantId": "d248da64-0e1e-4f79-b8c6-72ab7aa055eb", "clientId": "41826dd4-9e0a-4357-a5bd-a88ad771ea7d", "clientSecret": "U6DtAX5r6RPZxd~l12Ri3X8J9urt5Q-xs", "workspaceId": "074dd9f8-c368-4220-9400-acb6e80fc325", "query": query, "threshold": "1900000000", "cloud": "Private", "activeDirectoryEndpoint": "https://login.private/"}, true},
+	// Private cloud with queryPackQueryId without resourceManagerEndpoint, should fail
+	{map[string]string{"tenantId": "d248da64-0e1e-4f79-b8c6-72ab7aa055eb", "clientId": "41826dd4-9e0a-4357-a5bd-a88ad771ea7d", "clientSecret": "U6DtAX5r6RPZxd~l12Ri3X8J9urt5Q-xs", "workspaceId": "074dd9f8-c368-4220-9400-acb6e80fc325", "queryPackQueryId": queryPackQueryID, "threshold": "1900000000", "cloud": "Private", "logAnalyticsResourceURL": "https://api.loganalytics.private", "activeDirectoryEndpoint": "https://login.private/"}, true},
 }
 
 var LogAnalyticsMetricIdentifiers = []LogAnalyticsMetricIdentifier{
@@ -192,6 +225,8 @@ type parseMetadataMetricNameTestData struct {
 var testParseMetadataMetricName = []parseMetadataMetricNameTestData{
 	// WorkspaceID
 	{map[string]string{"tenantIdFromEnv": "d248da64-0e1e-4f79-b8c6-72ab7aa055eb", "clientIdFromEnv": "41826dd4-9e0a-4357-a5bd-a88ad771ea7d", "clientSecretFromEnv": "U6DtAX5r6RPZxd~l12Ri3X8J9urt5Q-xs", "workspaceIdFromEnv": "074dd9f8-c368-4220-9400-acb6e80fc325", "query": query, "threshold": "1900000000"}, 0, "azure-log-analytics-074dd9f8-c368-4220-9400-acb6e80fc325"},
+	// WorkspaceResourceID
+	{map[string]string{"tenantId": "d248da64-0e1e-4f79-b8c6-72ab7aa055eb", "clientId": "41826dd4-9e0a-4357-a5bd-a88ad771ea7d", "clientSecret": "U6DtAX5r6RPZxd~l12Ri3X8J9urt5Q-xs", "workspaceResourceId": workspaceResourceID, "query": query, "threshold": "1900000000"}, 0, "azure-log-analytics-arc-workspace"},
 	// Custom Name
 	{map[string]string{"metricName": "testName", "tenantIdFromEnv": "d248da64-0e1e-4f79-b8c6-72ab7aa055eb", "clientIdFromEnv": "41826dd4-9e0a-4357-a5bd-a88ad771ea7d", "clientSecretFromEnv": "U6DtAX5r6RPZxd~l12Ri3X8J9urt5Q-xs", "workspaceIdFromEnv": "074dd9f8-c368-4220-9400-acb6e80fc325", "query": query, "threshold": "1900000000"}, 1, "azure-log-analytics-testName"},
 }
@@ -207,3 +242,81 @@ func TestLogAnalyticsParseMetadataMetricName(t *testing.T) {
 		}
 	}
 }
+
+type logAnalyticsEndpointsTestData struct {
+	metadata                map[string]string
+	logAnalyticsResourceURL string
+	activeDirectoryEndpoint string
+	resourceManagerEndpoint string
+}
+
+var testLogAnalyticsEndpoints = []logAnalyticsEndpointsTestData{
+	// Default cloud
+	{map[string]string{}, "https://api.loganalytics.io", "https://login.microsoftonline.com/", "https://management.azure.com"},
+	// Azure China
+	{map[string]string{"cloud": "AzureChinaCloud"}, "https://api.loganalytics.azure.cn", "https://login.chinacloudapi.cn/", "https://management.chinacloudapi.cn"},
+	// Azure Government
+	{map[string]string{"cloud": "AzureUSGovernmentCloud"}, "https://api.loganalytics.us", "https://login.microsoftonline.us/", "https://management.usgovcloudapi.net"},
+	// Private cloud
+	{map[string]string{"cloud": "Private", "logAnalyticsResourceURL": "https://api.loganalytics.private/", "activeDirectoryEndpoint": "https://login.private", "resourceManagerEndpoint": "https://management.private/"}, "https://api.loganalytics.private", "https://login.private/", "https://management.private"},
+}
+
+func TestLogAnalyticsParseEndpoints(t *testing.T) {
+	for _, testData := range testLogAnalyticsEndpoints {
+		meta := azureLogAnalyticsMetadata{}
+		err := parseAzureLogAnalyticsEndpoints(&ScalerConfig{TriggerMetadata: testData.metadata}, &meta)
+		if err != nil {
+			t.Fatal("Could not parse endpoints:", err)
+		}
+		assert.Equal(t, testData.logAnalyticsResourceURL, meta.logAnalyticsResourceURL)
+		assert.Equal(t, testData.activeDirectoryEndpoint, meta.activeDirectoryEndpoint)
+		assert.Equal(t, testData.resourceManagerEndpoint, meta.resourceManagerEndpoint)
+	}
+}
+
+func TestLogAnalyticsGetMetricsFromQueryPackOnWorkspaceResource(t *testing.T) {
+	var tokenResources []string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/"+tenantID+"/oauth2/token", func(writer http.ResponseWriter, request *http.Request) {
+		assert.NoError(t, request.ParseForm())
+		tokenResources = append(tokenResources, request.PostForm.Get("resource"))
+		_, _ = writer.Write([]byte(`{"token_type":"Bearer","expires_on":"9999999999","not_before":"0","access_token":"token"}`))
+	})
+	mux.HandleFunc(queryPackQueryID, func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "Bearer token", request.Header.Get("Authorization"))
+		_, _ = writer.Write([]byte(`{"properties":{"body":"Heartbeat | count"}}`))
+	})
+	mux.HandleFunc("/v1"+workspaceResourceID+"/query", func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "Bearer token", request.Header.Get("Authorization"))
+		_, _ = writer.Write([]byte(`{"tables":[{"name":"PrimaryResult","columns":[{"name":"Count","type":"long"}],"rows":[[42]]}]}`))
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	// the token resources of both APIs have to differ, so Resource Manager is reached through localhost
+	resourceManagerEndpoint := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+	meta, err := parseAzureLogAnalyticsMetadata(&ScalerConfig{
+		TriggerMetadata: map[string]string{
+			"workspaceResourceId":     workspaceResourceID,
+			"queryPackQueryId":        queryPackQueryID,
+			"threshold":               "10",
+			"cloud":                   "Private",
+			"logAnalyticsResourceURL": server.URL,
+			"activeDirectoryEndpoint": server.URL,
+			"resourceManagerEndpoint": resourceManagerEndpoint,
+		},
+		AuthParams: map[string]string{"tenantId": tenantID, "clientId": clientID, "clientSecret": clientSecret},
+	})
+	if err != nil {
+		t.Fatal("Could not parse metadata:", err)
+	}
+	scaler := azureLogAnalyticsScaler{metadata: meta, cache: &sessionCache{metricValue: -1, metricThreshold: -1}, httpClient: http.DefaultClient}
+
+	metrics, err := scaler.GetMetrics(context.Background(), "MetricName", nil)
+	if err != nil {
+		t.Fatal("Could not get metrics:", err)
+	}
+	assert.Equal(t, int64(42), metrics[0].Value.Value())
+	assert.Equal(t, "Heartbeat | count", meta.query)
+	assert.Equal(t, []string{resourceManagerEndpoint + "/", server.URL + "/"}, tokenResources)
+}
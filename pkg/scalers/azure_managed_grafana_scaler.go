@@ -0,0 +1,304 @@
+//go:build !selective_scalers || scalers_azure
+// +build !selective_scalers scalers_azure
+
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/azure/auth"
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	// application id of Azure Managed Grafana in Azure Active Directory
+	managedGrafanaResource = "ce34e7e5-485f-4d76-964f-b3d2b16d1e4f"
+	// alertmanager v2 api of the Grafana managed alerts
+	managedGrafanaAlertsPath = "/api/alertmanager/grafana/api/v2/alerts"
+)
+
+type azureManagedGrafanaScaler struct {
+	metadata   *azureManagedGrafanaMetadata
+	httpClient *http.Client
+	authorizer autorest.Authorizer
+}
+
+type azureManagedGrafanaMetadata struct {
+	endpoint    string
+	labels      map[string]string
+	targetValue int64
+
+	// auth
+	apiKey         string
+	tenantID       string
+	clientID       string
+	clientPassword string
+
+	scalerIndex int
+}
+
+type grafanaAlert struct {
+	Labels map[string]string `json:"labels"`
+	Status struct {
+		State string `json:"state"`
+	} `json:"status"`
+}
+
+var azureManagedGrafanaLog = logf.Log.WithName("azure_managed_grafana_scaler")
+
+// NewAzureManagedGrafanaScaler creates a new azureManagedGrafanaScaler
+func NewAzureManagedGrafanaScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseAzureManagedGrafanaMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing azure managed grafana metadata: %s", err)
+	}
+
+	// a Grafana API key or service account token is sent as is, otherwise an Azure AD token is requested
+	var authorizer autorest.Authorizer
+	if meta.apiKey != "" {
+		authorizer = autorest.NewBearerAuthorizer(grafanaAPIKeyToken(meta.apiKey))
+	} else {
+		var authConfig auth.AuthorizerConfig
+		if config.PodIdentity == kedav1alpha1.PodIdentityProviderAzure {
+			msiConfig := auth.NewMSIConfig()
+			msiConfig.Resource = managedGrafanaResource
+			authConfig = msiConfig
+		} else {
+			credentialsConfig := auth.NewClientCredentialsConfig(meta.clientID, meta.clientPassword, meta.tenantID)
+			credentialsConfig.Resource = managedGrafanaResource
+			authConfig = credentialsConfig
+		}
+		authorizer, err = authConfig.Authorizer()
+		if err != nil {
+			return nil, fmt.Errorf("error creating azure managed grafana authorizer: %s", err)
+		}
+	}
+
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &azureManagedGrafanaScaler{
+		metadata:   meta,
+		httpClient: httpClient,
+		authorizer: authorizer,
+	}, nil
+}
+
+// grafanaAPIKeyToken implements adal.OAuthTokenProvider for a static token
+type grafanaAPIKeyToken string
+
+func (t grafanaAPIKeyToken) OAuthToken() string {
+	return string(t)
+}
+
+func parseAzureManagedGrafanaMetadata(config *ScalerConfig) (*azureManagedGrafanaMetadata, error) {
+	meta := azureManagedGrafanaMetadata{
+		labels: map[string]string{},
+	}
+
+	if val, ok := config.TriggerMetadata["endpoint"]; ok && val != "" {
+		if _, err := url.ParseRequestURI(val); err != nil {
+			return nil, fmt.Errorf("error parsing endpoint: %s", err)
+		}
+		meta.endpoint = strings.TrimSuffix(val, "/")
+	} else {
+		return nil, fmt.Errorf("no endpoint given")
+	}
+
+	// labels selects the alert instances, eg. "team=payments,severity=critical"
+	if val, ok := config.TriggerMetadata["labels"]; ok && val != "" {
+		for _, pair := range strings.Split(val, ",") {
+			parts := strings.SplitN(pair, "=", 2)
+			if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+				return nil, fmt.Errorf("labels must be in the format name=value,name=value")
+			}
+			meta.labels[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	}
+
+	if val, ok := config.TriggerMetadata[targetValueName]; ok && val != "" {
+		targetValue, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing targetValue: %s", err)
+		}
+		meta.targetValue = targetValue
+	} else {
+		return nil, fmt.Errorf("no targetValue given")
+	}
+
+	if val, ok := config.AuthParams["apiKey"]; ok && val != "" {
+		meta.apiKey = val
+	} else {
+		if config.PodIdentity != kedav1alpha1.PodIdentityProviderAzure {
+			if val, ok := config.TriggerMetadata["tenantId"]; ok && val != "" {
+				meta.tenantID = val
+			} else {
+				return nil, fmt.Errorf("no apiKey or tenantId given")
+			}
+		}
+
+		clientID, clientPassword, err := parseAzurePodIdentityParams(config)
+		if err != nil {
+			return nil, err
+		}
+		meta.clientID = clientID
+		meta.clientPassword = clientPassword
+	}
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+// IsActive returns true if there are firing alert instances matching the labels
+func (s *azureManagedGrafanaScaler) IsActive(ctx context.Context) (bool, error) {
+	count, err := s.getFiringAlertCount(ctx)
+	if err != nil {
+		azureManagedGrafanaLog.Error(err, "error getting grafana alerts")
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (s *azureManagedGrafanaScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *azureManagedGrafanaScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetMetricVal := resource.NewQuantity(s.metadata.targetValue, resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(s.metricName())),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetMetricVal,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{External: externalMetric, Type: externalMetricType}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+func (s *azureManagedGrafanaScaler) metricName() string {
+	names := make([]string, 0, len(s.metadata.labels))
+	for name := range s.metadata.labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	metricName := "azure-managed-grafana-alerts"
+	for _, name := range names {
+		metricName += fmt.Sprintf("-%s-%s", name, s.metadata.labels[name])
+	}
+	return metricName
+}
+
+// GetMetrics returns value for a supported metric and an error if there is a problem getting the metric
+func (s *azureManagedGrafanaScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	count, err := s.getFiringAlertCount(ctx)
+	if err != nil {
+		azureManagedGrafanaLog.Error(err, "error getting grafana alerts")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewQuantity(count, resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
+
+// labelMatchers returns the labels as sorted alertmanager matchers, eg. severity="critical"
+func (s *azureManagedGrafanaScaler) labelMatchers() []string {
+	matchers := make([]string, 0, len(s.metadata.labels))
+	for name, value := range s.metadata.labels {
+		matchers = append(matchers, fmt.Sprintf("%s=%s", name, strconv.Quote(value)))
+	}
+	sort.Strings(matchers)
+	return matchers
+}
+
+// getFiringAlertCount counts the alert instances that are firing and not silenced or inhibited
+func (s *azureManagedGrafanaScaler) getFiringAlertCount(ctx context.Context) (int64, error) {
+	query := url.Values{}
+	query.Set("active", "true")
+	query.Set("silenced", "false")
+	query.Set("inhibited", "false")
+	for _, matcher := range s.labelMatchers() {
+		query.Add("filter", matcher)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", s.metadata.endpoint+managedGrafanaAlertsPath+"?"+query.Encode(), nil)
+	if err != nil {
+		return -1, err
+	}
+	req, err = autorest.Prepare(req, s.authorizer.WithAuthorization())
+	if err != nil {
+		return -1, fmt.Errorf("error authorizing grafana request: %s", err)
+	}
+
+	r, err := s.httpClient.Do(req)
+	if err != nil {
+		return -1, err
+	}
+	defer r.Body.Close()
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return -1, err
+	}
+	if r.StatusCode != http.StatusOK {
+		return -1, fmt.Errorf("grafana api returned %d: %s", r.StatusCode, string(b))
+	}
+
+	var alerts []grafanaAlert
+	if err := json.Unmarshal(b, &alerts); err != nil {
+		return -1, err
+	}
+
+	var count int64
+	for _, alert := range alerts {
+		if alert.Status.State == "active" {
+			count++
+		}
+	}
+	return count, nil
+}
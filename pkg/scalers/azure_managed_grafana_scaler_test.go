@@ -0,0 +1,141 @@
+//go:build !selective_scalers || scalers_azure
+// +build !selective_scalers scalers_azure
+
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Azure/go-autorest/autorest"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+type parseAzManagedGrafanaMetadataTestData struct {
+	metadata    map[string]string
+	isError     bool
+	resolvedEnv map[string]string
+	authParams  map[string]string
+	podIdentity kedav1alpha1.PodIdentityProvider
+}
+
+type azManagedGrafanaMetricIdentifier struct {
+	metadataTestData *parseAzManagedGrafanaMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testParseAzManagedGrafanaMetadata = []parseAzManagedGrafanaMetadataTestData{
+	// nothing passed
+	{map[string]string{}, true, map[string]string{}, map[string]string{}, ""},
+	// properly formed with api key
+	{map[string]string{"endpoint": "https://grafana-abc.wus.grafana.azure.com", "labels": "team=payments,severity=critical", "targetValue": "5"}, false, map[string]string{}, map[string]string{"apiKey": "glsa_token"}, ""},
+	// properly formed with service principal
+	{map[string]string{"endpoint": "https://grafana-abc.wus.grafana.azure.com", "tenantId": "123", "activeDirectoryClientId": "CLIENT_ID", "activeDirectoryClientPasswordFromEnv": "CLIENT_PASSWORD", "targetValue": "5"}, false, testAzMonitorResolvedEnv, map[string]string{}, ""},
+	// with pod identity
+	{map[string]string{"endpoint": "https://grafana-abc.wus.grafana.azure.com", "labels": "alertname=DiskFull", "targetValue": "5"}, false, map[string]string{}, map[string]string{}, kedav1alpha1.PodIdentityProviderAzure},
+	// missing endpoint
+	{map[string]string{"targetValue": "5"}, true, map[string]string{}, map[string]string{"apiKey": "glsa_token"}, ""},
+	// invalid endpoint
+	{map[string]string{"endpoint": "grafana", "targetValue": "5"}, true, map[string]string{}, map[string]string{"apiKey": "glsa_token"}, ""},
+	// malformed labels
+	{map[string]string{"endpoint": "https://grafana-abc.wus.grafana.azure.com", "labels": "team", "targetValue": "5"}, true, map[string]string{}, map[string]string{"apiKey": "glsa_token"}, ""},
+	// missing targetValue
+	{map[string]string{"endpoint": "https://grafana-abc.wus.grafana.azure.com"}, true, map[string]string{}, map[string]string{"apiKey": "glsa_token"}, ""},
+	// missing apiKey and tenantId
+	{map[string]string{"endpoint": "https://grafana-abc.wus.grafana.azure.com", "targetValue": "5"}, true, map[string]string{}, map[string]string{}, ""},
+	// missing client password
+	{map[string]string{"endpoint": "https://grafana-abc.wus.grafana.azure.com", "tenantId": "123", "activeDirectoryClientId": "CLIENT_ID", "targetValue": "5"}, true, testAzMonitorResolvedEnv, map[string]string{}, ""},
+}
+
+var azManagedGrafanaMetricIdentifiers = []azManagedGrafanaMetricIdentifier{
+	{&testParseAzManagedGrafanaMetadata[1], 0, "s0-azure-managed-grafana-alerts-severity-critical-team-payments"},
+	{&testParseAzManagedGrafanaMetadata[2], 1, "s1-azure-managed-grafana-alerts"},
+}
+
+func TestAzManagedGrafanaParseMetadata(t *testing.T) {
+	for _, testData := range testParseAzManagedGrafanaMetadata {
+		_, err := parseAzureManagedGrafanaMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, ResolvedEnv: testData.resolvedEnv, AuthParams: testData.authParams, PodIdentity: testData.podIdentity})
+		if err != nil && !testData.isError {
+			t.Errorf("Expected success but got error %s for metadata %v", err, testData.metadata)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Expected error but got success for metadata %v", testData.metadata)
+		}
+	}
+}
+
+func TestAzManagedGrafanaGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range azManagedGrafanaMetricIdentifiers {
+		meta, err := parseAzureManagedGrafanaMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, ResolvedEnv: testData.metadataTestData.resolvedEnv, AuthParams: testData.metadataTestData.authParams, PodIdentity: testData.metadataTestData.podIdentity, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockAzManagedGrafanaScaler := azureManagedGrafanaScaler{metadata: meta}
+
+		metricSpec := mockAzManagedGrafanaScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Errorf("Wrong External metric source name: %s, expected: %s", metricName, testData.name)
+		}
+	}
+}
+
+func TestAzManagedGrafanaGetFiringAlertCount(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if r.URL.Path != managedGrafanaAlertsPath || query.Get("active") != "true" || query.Get("silenced") != "false" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer glsa_token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		filters := query["filter"]
+		if len(filters) != 2 || filters[0] != `severity="critical"` || filters[1] != `team="payments"` {
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprintf(w, "unexpected filters %v", filters)
+			return
+		}
+		fmt.Fprint(w, `[{"labels":{"alertname":"DiskFull"},"status":{"state":"active"}},{"labels":{"alertname":"HighLatency"},"status":{"state":"active"}},{"labels":{"alertname":"Old"},"status":{"state":"unprocessed"}}]`)
+	}))
+	defer server.Close()
+
+	meta, err := parseAzureManagedGrafanaMetadata(&ScalerConfig{
+		TriggerMetadata: map[string]string{"endpoint": server.URL + "/", "labels": "team=payments, severity=critical", "targetValue": "1"},
+		AuthParams:      map[string]string{"apiKey": "glsa_token"},
+	})
+	if err != nil {
+		t.Fatal("Could not parse metadata:", err)
+	}
+	scaler := azureManagedGrafanaScaler{metadata: meta, httpClient: http.DefaultClient, authorizer: autorest.NewBearerAuthorizer(grafanaAPIKeyToken(meta.apiKey))}
+
+	count, err := scaler.getFiringAlertCount(context.Background())
+	if err != nil {
+		t.Fatalf("Expected success but got error: %s", err)
+	}
+	if count != 2 {
+		t.Errorf("Expected 2 firing alerts, got %d", count)
+	}
+}
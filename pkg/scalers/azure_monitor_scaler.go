@@ -1,3 +1,6 @@
+//go:build !selective_scalers || scalers_azure
+// +build !selective_scalers scalers_azure
+
 /*
 Copyright 2021 The KEDA Authors
 
@@ -19,6 +22,7 @@ package scalers
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -37,6 +41,12 @@ import (
 const (
 	azureMonitorMetricName = "metricName"
 	targetValueName        = "targetValue"
+
+	// Traffic Manager convenience mode, see parseAzureMonitorTrafficManagerMetadata
+	trafficManagerResourceType   = "Microsoft.Network/trafficManagerProfiles"
+	trafficManagerQPSMetricName  = "QpsByEndpoint"
+	trafficManagerAggregation    = "Maximum"
+	trafficManagerEndpointFilter = "EndpointName eq '%s'"
 )
 
 type azureMonitorScaler struct {
@@ -52,6 +62,8 @@ type azureMonitorMetadata struct {
 
 var azureMonitorLog = logf.Log.WithName("azure_monitor_scaler")
 
+var azureMonitorAPIVersionRegex = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}(-preview)?$`)
+
 // NewAzureMonitorScaler creates a new AzureMonitorScaler
 func NewAzureMonitorScaler(config *ScalerConfig) (Scaler, error) {
 	meta, err := parseAzureMonitorMetadata(config)
@@ -81,14 +93,36 @@ func parseAzureMonitorMetadata(config *ScalerConfig) (*azureMonitorMetadata, err
 		return nil, fmt.Errorf("no targetValue given")
 	}
 
-	if val, ok := config.TriggerMetadata["resourceURI"]; ok && val != "" {
-		resourceURI := strings.Split(val, "/")
-		if len(resourceURI) != 3 {
-			return nil, fmt.Errorf("resourceURI not in the correct format. Should be namespace/resource_type/resource_name")
+	if _, ok := config.TriggerMetadata["trafficManagerProfileName"]; ok {
+		if err := parseAzureMonitorTrafficManagerMetadata(config, &meta); err != nil {
+			return nil, err
 		}
-		meta.azureMonitorInfo.ResourceURI = val
 	} else {
-		return nil, fmt.Errorf("no resourceURI given")
+		if val, ok := config.TriggerMetadata["resourceURI"]; ok && val != "" {
+			resourceURI := strings.Split(val, "/")
+			if len(resourceURI) != 3 {
+				return nil, fmt.Errorf("resourceURI not in the correct format. Should be namespace/resource_type/resource_name")
+			}
+			meta.azureMonitorInfo.ResourceURI = val
+		} else {
+			return nil, fmt.Errorf("no resourceURI given")
+		}
+
+		if val, ok := config.TriggerMetadata[azureMonitorMetricName]; ok && val != "" {
+			meta.azureMonitorInfo.Name = val
+		} else {
+			return nil, fmt.Errorf("no metricName given")
+		}
+
+		if val, ok := config.TriggerMetadata["metricAggregationType"]; ok && val != "" {
+			meta.azureMonitorInfo.AggregationType = val
+		} else {
+			return nil, fmt.Errorf("no metricAggregationType given")
+		}
+
+		if val, ok := config.TriggerMetadata["metricFilter"]; ok && val != "" {
+			meta.azureMonitorInfo.Filter = val
+		}
 	}
 
 	if val, ok := config.TriggerMetadata["resourceGroupName"]; ok && val != "" {
@@ -97,22 +131,6 @@ func parseAzureMonitorMetadata(config *ScalerConfig) (*azureMonitorMetadata, err
 		return nil, fmt.Errorf("no resourceGroupName given")
 	}
 
-	if val, ok := config.TriggerMetadata[azureMonitorMetricName]; ok && val != "" {
-		meta.azureMonitorInfo.Name = val
-	} else {
-		return nil, fmt.Errorf("no metricName given")
-	}
-
-	if val, ok := config.TriggerMetadata["metricAggregationType"]; ok && val != "" {
-		meta.azureMonitorInfo.AggregationType = val
-	} else {
-		return nil, fmt.Errorf("no metricAggregationType given")
-	}
-
-	if val, ok := config.TriggerMetadata["metricFilter"]; ok && val != "" {
-		meta.azureMonitorInfo.Filter = val
-	}
-
 	if val, ok := config.TriggerMetadata["metricAggregationInterval"]; ok && val != "" {
 		aggregationInterval := strings.Split(val, ":")
 		if len(aggregationInterval) != 3 {
@@ -139,6 +157,24 @@ func parseAzureMonitorMetadata(config *ScalerConfig) (*azureMonitorMetadata, err
 		meta.azureMonitorInfo.Namespace = val
 	}
 
+	// metricAPIVersion selects another api-version of the metrics API, eg. 2023-10-01 or a -preview version,
+	// for resource types whose custom metrics namespaces aren't served by the default version
+	if val, ok := config.TriggerMetadata["metricAPIVersion"]; ok && val != "" {
+		if !azureMonitorAPIVersionRegex.MatchString(val) {
+			return nil, fmt.Errorf("metricAPIVersion %s not in the correct format. Should be yyyy-mm-dd or yyyy-mm-dd-preview", val)
+		}
+		meta.azureMonitorInfo.APIVersion = val
+	}
+
+	env, err := azure.ParseEnvironment(config.TriggerMetadata)
+	if err != nil {
+		return nil, err
+	}
+	if err := azure.RequireEnvironmentEndpoints(env, azure.ActiveDirectoryEndpointKey, azure.ResourceManagerEndpointKey); err != nil {
+		return nil, err
+	}
+	meta.azureMonitorInfo.Environment = env
+
 	clientID, clientPassword, err := parseAzurePodIdentityParams(config)
 	if err != nil {
 		return nil, err
@@ -151,6 +187,36 @@ func parseAzureMonitorMetadata(config *ScalerConfig) (*azureMonitorMetadata, err
 	return &meta, nil
 }
 
+// parseAzureMonitorTrafficManagerMetadata configures the QpsByEndpoint metric of a Traffic Manager profile,
+// filtered on a single endpoint. Deploying one ScaledObject per region, each pointing at its own endpoint,
+// scales every regional deployment proportionally to the traffic Traffic Manager routes to that region.
+func parseAzureMonitorTrafficManagerMetadata(config *ScalerConfig, meta *azureMonitorMetadata) error {
+	for _, key := range []string{"resourceURI", azureMonitorMetricName, "metricFilter"} {
+		if _, ok := config.TriggerMetadata[key]; ok {
+			return fmt.Errorf("%s can't be used together with trafficManagerProfileName", key)
+		}
+	}
+
+	profile := config.TriggerMetadata["trafficManagerProfileName"]
+	if profile == "" {
+		return fmt.Errorf("no trafficManagerProfileName given")
+	}
+	endpoint := config.TriggerMetadata["trafficManagerEndpointName"]
+	if endpoint == "" {
+		return fmt.Errorf("no trafficManagerEndpointName given")
+	}
+
+	meta.azureMonitorInfo.ResourceURI = fmt.Sprintf("%s/%s", trafficManagerResourceType, profile)
+	meta.azureMonitorInfo.Name = trafficManagerQPSMetricName
+	meta.azureMonitorInfo.Filter = fmt.Sprintf(trafficManagerEndpointFilter, strings.ReplaceAll(endpoint, "'", "''"))
+	meta.azureMonitorInfo.AggregationType = trafficManagerAggregation
+	if val, ok := config.TriggerMetadata["metricAggregationType"]; ok && val != "" {
+		meta.azureMonitorInfo.AggregationType = val
+	}
+
+	return nil
+}
+
 // parseAzurePodIdentityParams gets the activeDirectory clientID and password
 func parseAzurePodIdentityParams(config *ScalerConfig) (clientID string, clientPassword string, err error) {
 	if config.PodIdentity == "" || config.PodIdentity == kedav1alpha1.PodIdentityProviderNone {
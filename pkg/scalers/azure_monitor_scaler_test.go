@@ -1,3 +1,6 @@
+//go:build !selective_scalers || scalers_azure
+// +build !selective_scalers scalers_azure
+
 /*
 Copyright 2021 The KEDA Authors
 
@@ -47,6 +50,10 @@ var testParseAzMonitorMetadata = []parseAzMonitorMetadataTestData{
 	{map[string]string{}, true, map[string]string{}, map[string]string{}, ""},
 	// properly formed
 	{map[string]string{"resourceURI": "test/resource/uri", "tenantId": "123", "subscriptionId": "456", "resourceGroupName": "test", "metricName": "metric", "metricAggregationInterval": "0:15:0", "metricAggregationType": "Average", "activeDirectoryClientId": "CLIENT_ID", "activeDirectoryClientPasswordFromEnv": "CLIENT_PASSWORD", "targetValue": "5", "metricNamespace": "namespace"}, false, testAzMonitorResolvedEnv, map[string]string{}, ""},
+	// custom metrics namespace with a preview api version
+	{map[string]string{"resourceURI": "Microsoft.Quantum/workspaces/ws", "tenantId": "123", "subscriptionId": "456", "resourceGroupName": "test", "metricName": "QueuedJobs", "metricAggregationType": "Maximum", "activeDirectoryClientId": "CLIENT_ID", "activeDirectoryClientPasswordFromEnv": "CLIENT_PASSWORD", "targetValue": "5", "metricNamespace": "jobs", "metricAPIVersion": "2021-05-01-preview"}, false, testAzMonitorResolvedEnv, map[string]string{}, ""},
+	// malformed metricAPIVersion
+	{map[string]string{"resourceURI": "test/resource/uri", "tenantId": "123", "subscriptionId": "456", "resourceGroupName": "test", "metricName": "metric", "metricAggregationType": "Average", "activeDirectoryClientId": "CLIENT_ID", "activeDirectoryClientPasswordFromEnv": "CLIENT_PASSWORD", "targetValue": "5", "metricAPIVersion": "latest"}, true, testAzMonitorResolvedEnv, map[string]string{}, ""},
 	// no optional parameters
 	{map[string]string{"resourceURI": "test/resource/uri", "tenantId": "123", "subscriptionId": "456", "resourceGroupName": "test", "metricName": "metric", "metricAggregationType": "Average", "activeDirectoryClientId": "CLIENT_ID", "activeDirectoryClientPasswordFromEnv": "CLIENT_PASSWORD", "targetValue": "5"}, false, testAzMonitorResolvedEnv, map[string]string{}, ""},
 	// incorrectly formatted resourceURI
@@ -79,6 +86,20 @@ var testParseAzMonitorMetadata = []parseAzMonitorMetadataTestData{
 	{map[string]string{"resourceURI": "test/resource/uri", "tenantId": "123", "subscriptionId": "456", "resourceGroupName": "test", "metricName": "metric", "metricAggregationInterval": "0:15:0", "metricAggregationType": "Average", "targetValue": "5"}, false, map[string]string{}, map[string]string{}, kedav1alpha1.PodIdentityProviderAzure},
 	// wrong podIdentity
 	{map[string]string{"resourceURI": "test/resource/uri", "tenantId": "123", "subscriptionId": "456", "resourceGroupName": "test", "metricName": "metric", "metricAggregationInterval": "0:15:0", "metricAggregationType": "Average", "targetValue": "5"}, true, map[string]string{}, map[string]string{}, kedav1alpha1.PodIdentityProvider("notAzure")},
+	// traffic manager mode
+	{map[string]string{"trafficManagerProfileName": "frontend", "trafficManagerEndpointName": "westeurope", "tenantId": "123", "subscriptionId": "456", "resourceGroupName": "test", "targetValue": "100"}, false, map[string]string{}, map[string]string{}, kedav1alpha1.PodIdentityProviderAzure},
+	// traffic manager mode without endpoint
+	{map[string]string{"trafficManagerProfileName": "frontend", "tenantId": "123", "subscriptionId": "456", "resourceGroupName": "test", "targetValue": "100"}, true, map[string]string{}, map[string]string{}, kedav1alpha1.PodIdentityProviderAzure},
+	// traffic manager mode with explicit metricName
+	{map[string]string{"trafficManagerProfileName": "frontend", "trafficManagerEndpointName": "westeurope", "metricName": "metric", "tenantId": "123", "subscriptionId": "456", "resourceGroupName": "test", "targetValue": "100"}, true, map[string]string{}, map[string]string{}, kedav1alpha1.PodIdentityProviderAzure},
+	// Azure US Government cloud
+	{map[string]string{"resourceURI": "test/resource/uri", "tenantId": "123", "subscriptionId": "456", "resourceGroupName": "test", "metricName": "metric", "metricAggregationType": "Average", "targetValue": "5", "cloud": "AzureUSGovernment"}, false, map[string]string{}, map[string]string{}, kedav1alpha1.PodIdentityProviderAzure},
+	// invalid cloud
+	{map[string]string{"resourceURI": "test/resource/uri", "tenantId": "123", "subscriptionId": "456", "resourceGroupName": "test", "metricName": "metric", "metricAggregationType": "Average", "targetValue": "5", "cloud": "Invalid"}, true, map[string]string{}, map[string]string{}, kedav1alpha1.PodIdentityProviderAzure},
+	// private cloud with endpoints
+	{map[string]string{"resourceURI": "test/resource/uri", "tenantId": "123", "subscriptionId": "456", "resourceGroupName": "test", "metricName": "metric", "metricAggregationType": "Average", "targetValue": "5", "cloud": "Private", "activeDirectoryEndpoint": "https://login.private.cloud", "resourceManagerEndpoint": "https://management.private.cloud"}, false, map[string]string{}, map[string]string{}, kedav1alpha1.PodIdentityProviderAzure},
+	// private cloud without resourceManagerEndpoint
+	{map[string]string{"resourceURI": "test/resource/uri", "tenantId": "123", "subscriptionId": "456", "resourceGroupName": "test", "metricName": "metric", "metricAggregationType": "Average", "targetValue": "5", "cloud": "Private", "activeDirectoryEndpoint": "https://login.private.cloud"}, true, map[string]string{}, map[string]string{}, kedav1alpha1.PodIdentityProviderAzure},
 }
 
 var azMonitorMetricIdentifiers = []azMonitorMetricIdentifier{
@@ -113,3 +134,27 @@ func TestAzMonitorGetMetricSpecForScaling(t *testing.T) {
 		}
 	}
 }
+
+func TestAzMonitorTrafficManagerMetadata(t *testing.T) {
+	meta, err := parseAzureMonitorMetadata(&ScalerConfig{
+		TriggerMetadata: map[string]string{"trafficManagerProfileName": "frontend", "trafficManagerEndpointName": "west'europe", "tenantId": "123", "subscriptionId": "456", "resourceGroupName": "test", "targetValue": "100"},
+		PodIdentity:     kedav1alpha1.PodIdentityProviderAzure,
+	})
+	if err != nil {
+		t.Fatal("Could not parse metadata:", err)
+	}
+
+	info := meta.azureMonitorInfo
+	if info.ResourceURI != "Microsoft.Network/trafficManagerProfiles/frontend" {
+		t.Errorf("Wrong resourceURI: %s", info.ResourceURI)
+	}
+	if info.Name != "QpsByEndpoint" {
+		t.Errorf("Wrong metric name: %s", info.Name)
+	}
+	if info.Filter != "EndpointName eq 'west''europe'" {
+		t.Errorf("Wrong filter: %s", info.Filter)
+	}
+	if info.AggregationType != "Maximum" {
+		t.Errorf("Wrong aggregation type: %s", info.AggregationType)
+	}
+}
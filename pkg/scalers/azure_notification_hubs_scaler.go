@@ -0,0 +1,206 @@
+//go:build !selective_scalers || scalers_azure
+// +build !selective_scalers scalers_azure
+
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/scalers/azure"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	notificationHubsResourceURI = "Microsoft.NotificationHubs/namespaces/%s/notificationHubs/%s"
+
+	notificationHubsModePendingScheduled = "PendingScheduled"
+	notificationHubsModePnsErrors        = "PnsErrors"
+
+	// Azure Monitor metrics of Notification Hubs
+	notificationHubsPendingScheduledMetricName = "scheduled.pending"
+	notificationHubsPnsErrorsMetricName        = "outgoing.allpns.pnserror"
+)
+
+type azureNotificationHubsScaler struct {
+	metadata    *azureNotificationHubsMetadata
+	podIdentity kedav1alpha1.PodIdentityProvider
+}
+
+type azureNotificationHubsMetadata struct {
+	azureMonitorInfo azure.MonitorInfo
+	mode             string
+	namespaceName    string
+	hubName          string
+	targetValue      int
+	scalerIndex      int
+}
+
+var azureNotificationHubsLog = logf.Log.WithName("azure_notification_hubs_scaler")
+
+// NewAzureNotificationHubsScaler creates a new azureNotificationHubsScaler
+func NewAzureNotificationHubsScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseAzureNotificationHubsMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing azure notification hubs metadata: %s", err)
+	}
+
+	return &azureNotificationHubsScaler{
+		metadata:    meta,
+		podIdentity: config.PodIdentity,
+	}, nil
+}
+
+func parseAzureNotificationHubsMetadata(config *ScalerConfig) (*azureNotificationHubsMetadata, error) {
+	meta := azureNotificationHubsMetadata{
+		mode: notificationHubsModePendingScheduled,
+	}
+
+	if val, ok := config.TriggerMetadata[targetValueName]; ok && val != "" {
+		targetValue, err := strconv.Atoi(val)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing targetValue: %s", err)
+		}
+		meta.targetValue = targetValue
+	} else {
+		return nil, fmt.Errorf("no targetValue given")
+	}
+
+	if val, ok := config.TriggerMetadata["namespaceName"]; ok && val != "" {
+		meta.namespaceName = val
+	} else {
+		return nil, fmt.Errorf("no namespaceName given")
+	}
+
+	if val, ok := config.TriggerMetadata["notificationHubName"]; ok && val != "" {
+		meta.hubName = val
+	} else {
+		return nil, fmt.Errorf("no notificationHubName given")
+	}
+	meta.azureMonitorInfo.ResourceURI = fmt.Sprintf(notificationHubsResourceURI, meta.namespaceName, meta.hubName)
+
+	if val, ok := config.TriggerMetadata["mode"]; ok && val != "" {
+		meta.mode = val
+	}
+	switch meta.mode {
+	case notificationHubsModePendingScheduled:
+		// the number of pending scheduled notifications is a gauge
+		meta.azureMonitorInfo.Name = notificationHubsPendingScheduledMetricName
+		meta.azureMonitorInfo.AggregationType = "Maximum"
+	case notificationHubsModePnsErrors:
+		meta.azureMonitorInfo.Name = notificationHubsPnsErrorsMetricName
+		meta.azureMonitorInfo.AggregationType = "Total"
+	default:
+		return nil, fmt.Errorf("mode must be one of %s, %s", notificationHubsModePendingScheduled, notificationHubsModePnsErrors)
+	}
+
+	if val, ok := config.TriggerMetadata["metricAggregationInterval"]; ok && val != "" {
+		aggregationInterval := strings.Split(val, ":")
+		if len(aggregationInterval) != 3 {
+			return nil, fmt.Errorf("metricAggregationInterval not in the correct format. Should be hh:mm:ss")
+		}
+		meta.azureMonitorInfo.AggregationInterval = val
+	}
+
+	if val, ok := config.TriggerMetadata["resourceGroupName"]; ok && val != "" {
+		meta.azureMonitorInfo.ResourceGroupName = val
+	} else {
+		return nil, fmt.Errorf("no resourceGroupName given")
+	}
+
+	if val, ok := config.TriggerMetadata["subscriptionId"]; ok && val != "" {
+		meta.azureMonitorInfo.SubscriptionID = val
+	} else {
+		return nil, fmt.Errorf("no subscriptionId given")
+	}
+
+	if val, ok := config.TriggerMetadata["tenantId"]; ok && val != "" {
+		meta.azureMonitorInfo.TenantID = val
+	} else {
+		return nil, fmt.Errorf("no tenantId given")
+	}
+
+	clientID, clientPassword, err := parseAzurePodIdentityParams(config)
+	if err != nil {
+		return nil, err
+	}
+	meta.azureMonitorInfo.ClientID = clientID
+	meta.azureMonitorInfo.ClientPassword = clientPassword
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+// IsActive returns true if there are pending scheduled notifications or PNS errors
+func (s *azureNotificationHubsScaler) IsActive(ctx context.Context) (bool, error) {
+	val, err := azure.GetAzureMetricValue(ctx, s.metadata.azureMonitorInfo, s.podIdentity)
+	if err != nil {
+		azureNotificationHubsLog.Error(err, "error getting azure notification hubs metric")
+		return false, err
+	}
+
+	return val > 0, nil
+}
+
+func (s *azureNotificationHubsScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *azureNotificationHubsScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetMetricVal := resource.NewQuantity(int64(s.metadata.targetValue), resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(fmt.Sprintf("azure-notification-hubs-%s-%s-%s", s.metadata.namespaceName, s.metadata.hubName, s.metadata.mode))),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetMetricVal,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{External: externalMetric, Type: externalMetricType}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// GetMetrics returns value for a supported metric and an error if there is a problem getting the metric
+func (s *azureNotificationHubsScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	val, err := azure.GetAzureMetricValue(ctx, s.metadata.azureMonitorInfo, s.podIdentity)
+	if err != nil {
+		azureNotificationHubsLog.Error(err, "error getting azure notification hubs metric")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewQuantity(int64(val), resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
@@ -0,0 +1,115 @@
+//go:build !selective_scalers || scalers_azure
+// +build !selective_scalers scalers_azure
+
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scalers
+
+import (
+	"context"
+	"testing"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+type parseAzNotificationHubsMetadataTestData struct {
+	metadata    map[string]string
+	isError     bool
+	resolvedEnv map[string]string
+	authParams  map[string]string
+	podIdentity kedav1alpha1.PodIdentityProvider
+}
+
+type azNotificationHubsMetricIdentifier struct {
+	metadataTestData *parseAzNotificationHubsMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testParseAzNotificationHubsMetadata = []parseAzNotificationHubsMetadataTestData{
+	// nothing passed
+	{map[string]string{}, true, map[string]string{}, map[string]string{}, ""},
+	// properly formed
+	{map[string]string{"namespaceName": "ns", "notificationHubName": "hub", "tenantId": "123", "subscriptionId": "456", "resourceGroupName": "test", "activeDirectoryClientId": "CLIENT_ID", "activeDirectoryClientPasswordFromEnv": "CLIENT_PASSWORD", "targetValue": "100"}, false, testAzMonitorResolvedEnv, map[string]string{}, ""},
+	// PnsErrors mode with aggregation interval
+	{map[string]string{"namespaceName": "ns", "notificationHubName": "hub", "mode": "PnsErrors", "metricAggregationInterval": "0:5:0", "tenantId": "123", "subscriptionId": "456", "resourceGroupName": "test", "activeDirectoryClientId": "CLIENT_ID", "activeDirectoryClientPasswordFromEnv": "CLIENT_PASSWORD", "targetValue": "10"}, false, testAzMonitorResolvedEnv, map[string]string{}, ""},
+	// unknown mode
+	{map[string]string{"namespaceName": "ns", "notificationHubName": "hub", "mode": "Outgoing", "tenantId": "123", "subscriptionId": "456", "resourceGroupName": "test", "activeDirectoryClientId": "CLIENT_ID", "activeDirectoryClientPasswordFromEnv": "CLIENT_PASSWORD", "targetValue": "10"}, true, testAzMonitorResolvedEnv, map[string]string{}, ""},
+	// improperly formatted aggregationInterval
+	{map[string]string{"namespaceName": "ns", "notificationHubName": "hub", "metricAggregationInterval": "0:5", "tenantId": "123", "subscriptionId": "456", "resourceGroupName": "test", "activeDirectoryClientId": "CLIENT_ID", "activeDirectoryClientPasswordFromEnv": "CLIENT_PASSWORD", "targetValue": "10"}, true, testAzMonitorResolvedEnv, map[string]string{}, ""},
+	// missing namespaceName
+	{map[string]string{"notificationHubName": "hub", "tenantId": "123", "subscriptionId": "456", "resourceGroupName": "test", "activeDirectoryClientId": "CLIENT_ID", "activeDirectoryClientPasswordFromEnv": "CLIENT_PASSWORD", "targetValue": "10"}, true, testAzMonitorResolvedEnv, map[string]string{}, ""},
+	// missing notificationHubName
+	{map[string]string{"namespaceName": "ns", "tenantId": "123", "subscriptionId": "456", "resourceGroupName": "test", "activeDirectoryClientId": "CLIENT_ID", "activeDirectoryClientPasswordFromEnv": "CLIENT_PASSWORD", "targetValue": "10"}, true, testAzMonitorResolvedEnv, map[string]string{}, ""},
+	// missing targetValue
+	{map[string]string{"namespaceName": "ns", "notificationHubName": "hub", "tenantId": "123", "subscriptionId": "456", "resourceGroupName": "test", "activeDirectoryClientId": "CLIENT_ID", "activeDirectoryClientPasswordFromEnv": "CLIENT_PASSWORD"}, true, testAzMonitorResolvedEnv, map[string]string{}, ""},
+	// missing tenantId
+	{map[string]string{"namespaceName": "ns", "notificationHubName": "hub", "subscriptionId": "456", "resourceGroupName": "test", "activeDirectoryClientId": "CLIENT_ID", "activeDirectoryClientPasswordFromEnv": "CLIENT_PASSWORD", "targetValue": "10"}, true, testAzMonitorResolvedEnv, map[string]string{}, ""},
+	// missing client password
+	{map[string]string{"namespaceName": "ns", "notificationHubName": "hub", "tenantId": "123", "subscriptionId": "456", "resourceGroupName": "test", "activeDirectoryClientId": "CLIENT_ID", "targetValue": "10"}, true, testAzMonitorResolvedEnv, map[string]string{}, ""},
+	// with pod identity
+	{map[string]string{"namespaceName": "ns", "notificationHubName": "hub", "tenantId": "123", "subscriptionId": "456", "resourceGroupName": "test", "targetValue": "10"}, false, map[string]string{}, map[string]string{}, kedav1alpha1.PodIdentityProviderAzure},
+}
+
+var azNotificationHubsMetricIdentifiers = []azNotificationHubsMetricIdentifier{
+	{&testParseAzNotificationHubsMetadata[1], 0, "s0-azure-notification-hubs-ns-hub-PendingScheduled"},
+	{&testParseAzNotificationHubsMetadata[2], 1, "s1-azure-notification-hubs-ns-hub-PnsErrors"},
+}
+
+func TestAzNotificationHubsParseMetadata(t *testing.T) {
+	for _, testData := range testParseAzNotificationHubsMetadata {
+		_, err := parseAzureNotificationHubsMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, ResolvedEnv: testData.resolvedEnv, AuthParams: testData.authParams, PodIdentity: testData.podIdentity})
+		if err != nil && !testData.isError {
+			t.Errorf("Expected success but got error %s for metadata %v", err, testData.metadata)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Expected error but got success for metadata %v", testData.metadata)
+		}
+	}
+}
+
+func TestAzNotificationHubsResourceURI(t *testing.T) {
+	meta, err := parseAzureNotificationHubsMetadata(&ScalerConfig{TriggerMetadata: testParseAzNotificationHubsMetadata[2].metadata, ResolvedEnv: testAzMonitorResolvedEnv, AuthParams: map[string]string{}})
+	if err != nil {
+		t.Fatal("Could not parse metadata:", err)
+	}
+	if meta.azureMonitorInfo.ResourceURI != "Microsoft.NotificationHubs/namespaces/ns/notificationHubs/hub" {
+		t.Errorf("Wrong resourceURI: %s", meta.azureMonitorInfo.ResourceURI)
+	}
+	if meta.azureMonitorInfo.Name != notificationHubsPnsErrorsMetricName || meta.azureMonitorInfo.AggregationType != "Total" {
+		t.Errorf("Wrong metric for mode PnsErrors: %s %s", meta.azureMonitorInfo.Name, meta.azureMonitorInfo.AggregationType)
+	}
+}
+
+func TestAzNotificationHubsGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range azNotificationHubsMetricIdentifiers {
+		meta, err := parseAzureNotificationHubsMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, ResolvedEnv: testData.metadataTestData.resolvedEnv, AuthParams: testData.metadataTestData.authParams, PodIdentity: testData.metadataTestData.podIdentity, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockAzNotificationHubsScaler := azureNotificationHubsScaler{
+			metadata:    meta,
+			podIdentity: testData.metadataTestData.podIdentity,
+		}
+
+		metricSpec := mockAzNotificationHubsScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Errorf("Wrong External metric source name: %s, expected: %s", metricName, testData.name)
+		}
+	}
+}
@@ -1,3 +1,6 @@
+//go:build !selective_scalers || scalers_azure
+// +build !selective_scalers scalers_azure
+
 package scalers
 
 import (
@@ -6,8 +9,10 @@ import (
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
+	"sync"
 
 	kedautil "github.com/kedacore/keda/v2/pkg/util"
 	v2beta2 "k8s.io/api/autoscaling/v2beta2"
@@ -25,15 +30,25 @@ const (
 type azurePipelinesScaler struct {
 	metadata   *azurePipelinesMetadata
 	httpClient *http.Client
+
+	// poolID is the id of the pool, it is looked up once for a poolName
+	lock   sync.Mutex
+	poolID string
 }
 
 type azurePipelinesMetadata struct {
-	organizationURL            string
-	organizationName           string
-	personalAccessToken        string
-	poolID                     string
-	targetPipelinesQueueLength int
-	scalerIndex                int
+	organizationURL     string
+	organizationName    string
+	personalAccessToken string
+	poolID              string
+	poolName            string
+	// demands are the capabilities of the agents, only the jobs whose demands they fulfil are counted,
+	// with requireAllDemands the jobs also have to demand all of them
+	demands                              []string
+	requireAllDemands                    bool
+	targetPipelinesQueueLength           int
+	activationTargetPipelinesQueueLength int
+	scalerIndex                          int
 }
 
 var azurePipelinesLog = logf.Log.WithName("azure_pipelines_scaler")
@@ -45,11 +60,15 @@ func NewAzurePipelinesScaler(config *ScalerConfig) (Scaler, error) {
 		return nil, fmt.Errorf("error parsing azure Pipelines metadata: %s", err)
 	}
 
-	httpClient := kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, false)
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
 
 	return &azurePipelinesScaler{
 		metadata:   meta,
 		httpClient: httpClient,
+		poolID:     meta.poolID,
 	}, nil
 }
 
@@ -66,6 +85,15 @@ func parseAzurePipelinesMetadata(config *ScalerConfig) (*azurePipelinesMetadata,
 		meta.targetPipelinesQueueLength = queueLength
 	}
 
+	if val, ok := config.TriggerMetadata["activationTargetPipelinesQueueLength"]; ok && val != "" {
+		queueLength, err := strconv.Atoi(val)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing azure pipelines metadata activationTargetPipelinesQueueLength: %s", err.Error())
+		}
+
+		meta.activationTargetPipelinesQueueLength = queueLength
+	}
+
 	if val, ok := config.AuthParams["organizationURL"]; ok && val != "" {
 		// Found the organizationURL in a parameter from TriggerAuthentication
 		meta.organizationURL = val
@@ -92,8 +120,26 @@ func parseAzurePipelinesMetadata(config *ScalerConfig) (*azurePipelinesMetadata,
 
 	if val, ok := config.TriggerMetadata["poolID"]; ok && val != "" {
 		meta.poolID = val
+	} else if val, ok := config.TriggerMetadata["poolName"]; ok && val != "" {
+		meta.poolName = val
 	} else {
-		return nil, fmt.Errorf("no poolID given")
+		return nil, fmt.Errorf("no poolID or poolName given")
+	}
+
+	if val, ok := config.TriggerMetadata["demands"]; ok && val != "" {
+		for _, demand := range strings.Split(val, ",") {
+			if demand = strings.TrimSpace(demand); demand != "" {
+				meta.demands = append(meta.demands, demand)
+			}
+		}
+	}
+
+	if val, ok := config.TriggerMetadata["requireAllDemands"]; ok && val != "" {
+		requireAllDemands, err := strconv.ParseBool(val)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing azure pipelines metadata requireAllDemands: %s", err.Error())
+		}
+		meta.requireAllDemands = requireAllDemands
 	}
 
 	meta.scalerIndex = config.ScalerIndex
@@ -119,57 +165,142 @@ func (s *azurePipelinesScaler) GetMetrics(ctx context.Context, metricName string
 }
 
 func (s *azurePipelinesScaler) GetAzurePipelinesQueueLength(ctx context.Context) (int, error) {
-	url := fmt.Sprintf("%s/_apis/distributedtask/pools/%s/jobrequests", s.metadata.organizationURL, s.metadata.poolID)
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	poolID, err := s.getPoolID(ctx)
+	if err != nil {
+		return -1, err
+	}
+
+	url := fmt.Sprintf("%s/_apis/distributedtask/pools/%s/jobrequests", s.metadata.organizationURL, poolID)
+	result, err := s.getAzureDevOpsAPI(ctx, url)
 	if err != nil {
 		return -1, err
 	}
 
+	var count = 0
+	jobs, ok := result["value"].([]interface{})
+
+	if !ok {
+		return -1, fmt.Errorf("the Azure DevOps REST API result returned no value data. url: %s", url)
+	}
+
+	for _, value := range jobs {
+		v := value.(map[string]interface{})
+		if v["result"] == nil && s.canAgentsFulfilDemands(v["demands"]) {
+			count++
+		}
+	}
+
+	return count, err
+}
+
+// getPoolID returns the poolID, or the id of the pool named poolName
+func (s *azurePipelinesScaler) getPoolID(ctx context.Context) (string, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.poolID != "" {
+		return s.poolID, nil
+	}
+
+	poolsURL := fmt.Sprintf("%s/_apis/distributedtask/pools?poolName=%s", s.metadata.organizationURL, url.QueryEscape(s.metadata.poolName))
+	result, err := s.getAzureDevOpsAPI(ctx, poolsURL)
+	if err != nil {
+		return "", err
+	}
+
+	pools, ok := result["value"].([]interface{})
+	if !ok || len(pools) == 0 {
+		return "", fmt.Errorf("agent pool %s not found", s.metadata.poolName)
+	}
+	if len(pools) > 1 {
+		return "", fmt.Errorf("found %d agent pools named %s, use poolID", len(pools), s.metadata.poolName)
+	}
+	id, ok := pools[0].(map[string]interface{})["id"].(float64)
+	if !ok {
+		return "", fmt.Errorf("agent pool %s has no id", s.metadata.poolName)
+	}
+
+	s.poolID = strconv.Itoa(int(id))
+	return s.poolID, nil
+}
+
+// canAgentsFulfilDemands returns true if the agents have all capabilities the job demands, the Agent.* demands
+// like Agent.Version are met by every agent. Without demands all jobs are counted
+func (s *azurePipelinesScaler) canAgentsFulfilDemands(jobDemands interface{}) bool {
+	if len(s.metadata.demands) == 0 {
+		return true
+	}
+
+	demanded := map[string]bool{}
+	if demands, ok := jobDemands.([]interface{}); ok {
+		for _, demand := range demands {
+			value, ok := demand.(string)
+			if !ok || value == "" {
+				continue
+			}
+			// demands are a capability name optionally followed by a condition, eg. "Agent.Version -gtVersion 2.182.1"
+			name := strings.Fields(value)[0]
+			if strings.HasPrefix(name, "Agent.") {
+				continue
+			}
+			demanded[name] = true
+		}
+	}
+
+	capabilities := make(map[string]bool, len(s.metadata.demands))
+	for _, capability := range s.metadata.demands {
+		capabilities[capability] = true
+	}
+	for name := range demanded {
+		if !capabilities[name] {
+			return false
+		}
+	}
+	if s.metadata.requireAllDemands {
+		for capability := range capabilities {
+			if !demanded[capability] {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (s *azurePipelinesScaler) getAzureDevOpsAPI(ctx context.Context, url string) (map[string]interface{}, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
 	req.SetBasicAuth("PAT", s.metadata.personalAccessToken)
 
 	r, err := s.httpClient.Do(req)
 	if err != nil {
-		return -1, err
+		return nil, err
 	}
 
 	b, err := ioutil.ReadAll(r.Body)
 	if err != nil {
-		return -1, err
+		return nil, err
 	}
 	r.Body.Close()
 
 	if !(r.StatusCode >= 200 && r.StatusCode <= 299) {
-		return -1, fmt.Errorf("the Azure DevOps REST API returned error. url: %s status: %d response: %s", url, r.StatusCode, string(b))
+		return nil, fmt.Errorf("the Azure DevOps REST API returned error. url: %s status: %d response: %s", url, r.StatusCode, string(b))
 	}
 
 	var result map[string]interface{}
 	err = json.Unmarshal(b, &result)
 	if err != nil {
-		return -1, err
+		return nil, err
 	}
-
-	var count = 0
-	jobs, ok := result["value"].([]interface{})
-
-	if !ok {
-		return -1, fmt.Errorf("the Azure DevOps REST API result returned no value data. url: %s status: %d", url, r.StatusCode)
-	}
-
-	for _, value := range jobs {
-		v := value.(map[string]interface{})
-		if v["result"] == nil {
-			count++
-		}
-	}
-
-	return count, err
+	return result, nil
 }
 
 func (s *azurePipelinesScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
 	targetPipelinesQueueLengthQty := resource.NewQuantity(int64(s.metadata.targetPipelinesQueueLength), resource.DecimalSI)
 	externalMetric := &v2beta2.ExternalMetricSource{
 		Metric: v2beta2.MetricIdentifier{
-			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(fmt.Sprintf("azure-pipelines-%s", s.metadata.poolID))),
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(s.metricName())),
 		},
 		Target: v2beta2.MetricTarget{
 			Type:         v2beta2.AverageValueMetricType,
@@ -180,6 +311,13 @@ func (s *azurePipelinesScaler) GetMetricSpecForScaling(context.Context) []v2beta
 	return []v2beta2.MetricSpec{metricSpec}
 }
 
+func (s *azurePipelinesScaler) metricName() string {
+	if s.metadata.poolID != "" {
+		return fmt.Sprintf("azure-pipelines-%s", s.metadata.poolID)
+	}
+	return fmt.Sprintf("azure-pipelines-%s", strings.ReplaceAll(s.metadata.poolName, " ", "-"))
+}
+
 func (s *azurePipelinesScaler) IsActive(ctx context.Context) (bool, error) {
 	queuelen, err := s.GetAzurePipelinesQueueLength(ctx)
 
@@ -188,7 +326,7 @@ func (s *azurePipelinesScaler) IsActive(ctx context.Context) (bool, error) {
 		return false, err
 	}
 
-	return queuelen > 0, nil
+	return queuelen > s.metadata.activationTargetPipelinesQueueLength, nil
 }
 
 func (s *azurePipelinesScaler) Close(context.Context) error {
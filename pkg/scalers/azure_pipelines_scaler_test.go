@@ -1,8 +1,13 @@
+//go:build !selective_scalers || scalers_azure
+// +build !selective_scalers scalers_azure
+
 package scalers
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 )
 
@@ -37,11 +42,20 @@ var testAzurePipelinesMetadata = []parseAzurePipelinesMetadataTestData{
 	{map[string]string{"organizationURLFromEnv": "AZP_URL", "poolID": "1", "targetPipelinesQueueLength": "1"}, true, testAzurePipelinesResolvedEnv, map[string]string{}},
 	// missing poolID
 	{map[string]string{"organizationURLFromEnv": "AZP_URL", "personalAccessTokenFromEnv": "AZP_TOKEN", "poolID": "", "targetPipelinesQueueLength": "1"}, true, testAzurePipelinesResolvedEnv, map[string]string{}},
+	// poolName instead of poolID
+	{map[string]string{"organizationURLFromEnv": "AZP_URL", "personalAccessTokenFromEnv": "AZP_TOKEN", "poolName": "Linux Agents"}, false, testAzurePipelinesResolvedEnv, map[string]string{}},
+	// with demands
+	{map[string]string{"organizationURLFromEnv": "AZP_URL", "personalAccessTokenFromEnv": "AZP_TOKEN", "poolID": "1", "demands": "maven, java", "requireAllDemands": "true", "activationTargetPipelinesQueueLength": "2"}, false, testAzurePipelinesResolvedEnv, map[string]string{}},
+	// invalid requireAllDemands
+	{map[string]string{"organizationURLFromEnv": "AZP_URL", "personalAccessTokenFromEnv": "AZP_TOKEN", "poolID": "1", "demands": "maven", "requireAllDemands": "a"}, true, testAzurePipelinesResolvedEnv, map[string]string{}},
+	// invalid activationTargetPipelinesQueueLength
+	{map[string]string{"organizationURLFromEnv": "AZP_URL", "personalAccessTokenFromEnv": "AZP_TOKEN", "poolID": "1", "activationTargetPipelinesQueueLength": "a"}, true, testAzurePipelinesResolvedEnv, map[string]string{}},
 }
 
 var azurePipelinesMetricIdentifiers = []azurePipelinesMetricIdentifier{
 	{&testAzurePipelinesMetadata[1], 0, "s0-azure-pipelines-1"},
 	{&testAzurePipelinesMetadata[1], 1, "s1-azure-pipelines-1"},
+	{&testAzurePipelinesMetadata[6], 0, "s0-azure-pipelines-Linux-Agents"},
 }
 
 func TestParseAzurePipelinesMetadata(t *testing.T) {
@@ -74,3 +88,66 @@ func TestAzurePipelinesGetMetricSpecForScaling(t *testing.T) {
 		}
 	}
 }
+
+func TestAzurePipelinesGetQueueLength(t *testing.T) {
+	const jobRequests = `{"count":4,"value":[
+		{"requestId":1,"result":"succeeded","demands":["maven"]},
+		{"requestId":2,"demands":["maven","Agent.Version -gtVersion 2.182.1"]},
+		{"requestId":3,"demands":["maven","java -equals 11"]},
+		{"requestId":4,"demands":["docker"]},
+		{"requestId":5}]}`
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, token, ok := r.BasicAuth(); !ok || token != "sample" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/_apis/distributedtask/pools":
+			if r.URL.Query().Get("poolName") != "Linux Agents" {
+				fmt.Fprint(w, `{"count":0,"value":[]}`)
+				return
+			}
+			fmt.Fprint(w, `{"count":1,"value":[{"id":12,"name":"Linux Agents"}]}`)
+		case "/_apis/distributedtask/pools/12/jobrequests":
+			fmt.Fprint(w, jobRequests)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		metadata map[string]string
+		expected int
+		isError  bool
+	}{
+		{map[string]string{"poolID": "12"}, 4, false},
+		{map[string]string{"poolName": "Linux Agents"}, 4, false},
+		{map[string]string{"poolName": "Windows Agents"}, -1, true},
+		{map[string]string{"poolID": "12", "demands": "maven"}, 2, false},
+		{map[string]string{"poolID": "12", "demands": "maven,java"}, 3, false},
+		{map[string]string{"poolID": "12", "demands": "maven,java", "requireAllDemands": "true"}, 1, false},
+	}
+	for _, test := range tests {
+		meta, err := parseAzurePipelinesMetadata(&ScalerConfig{TriggerMetadata: test.metadata, AuthParams: map[string]string{"organizationURL": server.URL, "personalAccessToken": "sample"}})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		scaler := azurePipelinesScaler{metadata: meta, httpClient: http.DefaultClient, poolID: meta.poolID}
+
+		queueLength, err := scaler.GetAzurePipelinesQueueLength(context.Background())
+		if test.isError {
+			if err == nil {
+				t.Errorf("Expected error but got success for %v", test.metadata)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Expected success but got error %s for %v", err, test.metadata)
+		}
+		if queueLength != test.expected {
+			t.Errorf("Expected %d, got %d for %v", test.expected, queueLength, test.metadata)
+		}
+	}
+}
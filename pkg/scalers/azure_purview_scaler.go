@@ -0,0 +1,276 @@
+//go:build !selective_scalers || scalers_azure
+// +build !selective_scalers scalers_azure
+
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/Azure/go-autorest/autorest"
+	"github.com/Azure/go-autorest/autorest/azure/auth"
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	purviewResource         = "https://purview.azure.net"
+	purviewEndpointTemplate = "https://%s.purview.azure.com/scan"
+	purviewAPIVersion       = "2022-02-01-preview"
+	defaultPurviewStatuses  = "Queued"
+	// bounds the number of pages read per request, scan runs are returned newest first
+	purviewMaxPages = 10
+)
+
+type azurePurviewScaler struct {
+	metadata   *azurePurviewMetadata
+	httpClient *http.Client
+	authorizer autorest.Authorizer
+}
+
+type azurePurviewMetadata struct {
+	endpoint       string
+	dataSourceName string
+	scanName       string
+	statuses       map[string]bool
+	targetValue    int64
+	tenantID       string
+	clientID       string
+	clientPassword string
+	scalerIndex    int
+}
+
+type purviewScanRuns struct {
+	Value []struct {
+		ID     string `json:"id"`
+		Status string `json:"status"`
+	} `json:"value"`
+	NextLink string `json:"nextLink"`
+}
+
+var azurePurviewLog = logf.Log.WithName("azure_purview_scaler")
+
+// NewAzurePurviewScaler creates a new azurePurviewScaler
+func NewAzurePurviewScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseAzurePurviewMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing azure purview metadata: %s", err)
+	}
+
+	var authConfig auth.AuthorizerConfig
+	if config.PodIdentity == kedav1alpha1.PodIdentityProviderAzure {
+		msiConfig := auth.NewMSIConfig()
+		msiConfig.Resource = purviewResource
+		authConfig = msiConfig
+	} else {
+		credentialsConfig := auth.NewClientCredentialsConfig(meta.clientID, meta.clientPassword, meta.tenantID)
+		credentialsConfig.Resource = purviewResource
+		authConfig = credentialsConfig
+	}
+	authorizer, err := authConfig.Authorizer()
+	if err != nil {
+		return nil, fmt.Errorf("error creating azure purview authorizer: %s", err)
+	}
+
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &azurePurviewScaler{
+		metadata:   meta,
+		httpClient: httpClient,
+		authorizer: authorizer,
+	}, nil
+}
+
+func parseAzurePurviewMetadata(config *ScalerConfig) (*azurePurviewMetadata, error) {
+	meta := azurePurviewMetadata{}
+
+	if val, ok := config.TriggerMetadata["endpoint"]; ok && val != "" {
+		meta.endpoint = strings.TrimSuffix(val, "/")
+	} else if val, ok := config.TriggerMetadata["accountName"]; ok && val != "" {
+		meta.endpoint = fmt.Sprintf(purviewEndpointTemplate, val)
+	} else {
+		return nil, fmt.Errorf("no accountName given")
+	}
+
+	if val, ok := config.TriggerMetadata["dataSourceName"]; ok && val != "" {
+		meta.dataSourceName = val
+	} else {
+		return nil, fmt.Errorf("no dataSourceName given")
+	}
+
+	if val, ok := config.TriggerMetadata["scanName"]; ok && val != "" {
+		meta.scanName = val
+	} else {
+		return nil, fmt.Errorf("no scanName given")
+	}
+
+	statuses := defaultPurviewStatuses
+	if val, ok := config.TriggerMetadata["scanStatuses"]; ok && val != "" {
+		statuses = val
+	}
+	meta.statuses = map[string]bool{}
+	for _, status := range strings.Split(statuses, ",") {
+		meta.statuses[strings.ToLower(strings.TrimSpace(status))] = true
+	}
+
+	if val, ok := config.TriggerMetadata[targetValueName]; ok && val != "" {
+		targetValue, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing targetValue: %s", err)
+		}
+		meta.targetValue = targetValue
+	} else {
+		return nil, fmt.Errorf("no targetValue given")
+	}
+
+	if config.PodIdentity != kedav1alpha1.PodIdentityProviderAzure {
+		if val, ok := config.TriggerMetadata["tenantId"]; ok && val != "" {
+			meta.tenantID = val
+		} else {
+			return nil, fmt.Errorf("no tenantId given")
+		}
+	}
+
+	clientID, clientPassword, err := parseAzurePodIdentityParams(config)
+	if err != nil {
+		return nil, err
+	}
+	meta.clientID = clientID
+	meta.clientPassword = clientPassword
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+// IsActive returns true if there are scan runs in one of the statuses
+func (s *azurePurviewScaler) IsActive(ctx context.Context) (bool, error) {
+	count, err := s.getScanRunCount(ctx)
+	if err != nil {
+		azurePurviewLog.Error(err, "error getting purview scan runs")
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+func (s *azurePurviewScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *azurePurviewScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetMetricVal := resource.NewQuantity(s.metadata.targetValue, resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(fmt.Sprintf("azure-purview-%s-%s", s.metadata.dataSourceName, s.metadata.scanName))),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetMetricVal,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{External: externalMetric, Type: externalMetricType}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// GetMetrics returns value for a supported metric and an error if there is a problem getting the metric
+func (s *azurePurviewScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	count, err := s.getScanRunCount(ctx)
+	if err != nil {
+		azurePurviewLog.Error(err, "error getting purview scan runs")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewQuantity(count, resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
+
+// getScanRunCount counts the runs of the scan that are in one of the configured statuses
+func (s *azurePurviewScaler) getScanRunCount(ctx context.Context) (int64, error) {
+	link := fmt.Sprintf("%s/datasources/%s/scans/%s/runs?api-version=%s", s.metadata.endpoint,
+		url.PathEscape(s.metadata.dataSourceName), url.PathEscape(s.metadata.scanName), purviewAPIVersion)
+
+	var count int64
+	for page := 0; link != "" && page < purviewMaxPages; page++ {
+		runs, err := s.getScanRuns(ctx, link)
+		if err != nil {
+			return -1, err
+		}
+		for _, run := range runs.Value {
+			if s.metadata.statuses[strings.ToLower(run.Status)] {
+				count++
+			}
+		}
+		link = runs.NextLink
+	}
+
+	return count, nil
+}
+
+func (s *azurePurviewScaler) getScanRuns(ctx context.Context, link string) (*purviewScanRuns, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", link, nil)
+	if err != nil {
+		return nil, err
+	}
+	req, err = autorest.Prepare(req, s.authorizer.WithAuthorization())
+	if err != nil {
+		return nil, fmt.Errorf("error authorizing purview request: %s", err)
+	}
+
+	r, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	if r.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("purview api returned %d: %s", r.StatusCode, string(b))
+	}
+
+	var runs purviewScanRuns
+	if err := json.Unmarshal(b, &runs); err != nil {
+		return nil, err
+	}
+	return &runs, nil
+}
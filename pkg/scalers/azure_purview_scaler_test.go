@@ -0,0 +1,145 @@
+//go:build !selective_scalers || scalers_azure
+// +build !selective_scalers scalers_azure
+
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Azure/go-autorest/autorest"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+type parseAzPurviewMetadataTestData struct {
+	metadata    map[string]string
+	isError     bool
+	resolvedEnv map[string]string
+	authParams  map[string]string
+	podIdentity kedav1alpha1.PodIdentityProvider
+}
+
+type azPurviewMetricIdentifier struct {
+	metadataTestData *parseAzPurviewMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testParseAzPurviewMetadata = []parseAzPurviewMetadataTestData{
+	// nothing passed
+	{map[string]string{}, true, map[string]string{}, map[string]string{}, ""},
+	// properly formed
+	{map[string]string{"accountName": "catalog", "dataSourceName": "sql", "scanName": "nightly", "tenantId": "123", "activeDirectoryClientId": "CLIENT_ID", "activeDirectoryClientPasswordFromEnv": "CLIENT_PASSWORD", "targetValue": "2"}, false, testAzMonitorResolvedEnv, map[string]string{}, ""},
+	// endpoint and statuses
+	{map[string]string{"endpoint": "https://catalog.scan.purview.azure.com", "dataSourceName": "sql", "scanName": "nightly", "scanStatuses": "Queued, InProgress", "tenantId": "123", "activeDirectoryClientId": "CLIENT_ID", "activeDirectoryClientPasswordFromEnv": "CLIENT_PASSWORD", "targetValue": "2"}, false, testAzMonitorResolvedEnv, map[string]string{}, ""},
+	// missing accountName
+	{map[string]string{"dataSourceName": "sql", "scanName": "nightly", "tenantId": "123", "activeDirectoryClientId": "CLIENT_ID", "activeDirectoryClientPasswordFromEnv": "CLIENT_PASSWORD", "targetValue": "2"}, true, testAzMonitorResolvedEnv, map[string]string{}, ""},
+	// missing dataSourceName
+	{map[string]string{"accountName": "catalog", "scanName": "nightly", "tenantId": "123", "activeDirectoryClientId": "CLIENT_ID", "activeDirectoryClientPasswordFromEnv": "CLIENT_PASSWORD", "targetValue": "2"}, true, testAzMonitorResolvedEnv, map[string]string{}, ""},
+	// missing scanName
+	{map[string]string{"accountName": "catalog", "dataSourceName": "sql", "tenantId": "123", "activeDirectoryClientId": "CLIENT_ID", "activeDirectoryClientPasswordFromEnv": "CLIENT_PASSWORD", "targetValue": "2"}, true, testAzMonitorResolvedEnv, map[string]string{}, ""},
+	// missing targetValue
+	{map[string]string{"accountName": "catalog", "dataSourceName": "sql", "scanName": "nightly", "tenantId": "123", "activeDirectoryClientId": "CLIENT_ID", "activeDirectoryClientPasswordFromEnv": "CLIENT_PASSWORD"}, true, testAzMonitorResolvedEnv, map[string]string{}, ""},
+	// missing tenantId
+	{map[string]string{"accountName": "catalog", "dataSourceName": "sql", "scanName": "nightly", "activeDirectoryClientId": "CLIENT_ID", "activeDirectoryClientPasswordFromEnv": "CLIENT_PASSWORD", "targetValue": "2"}, true, testAzMonitorResolvedEnv, map[string]string{}, ""},
+	// missing client password
+	{map[string]string{"accountName": "catalog", "dataSourceName": "sql", "scanName": "nightly", "tenantId": "123", "activeDirectoryClientId": "CLIENT_ID", "targetValue": "2"}, true, testAzMonitorResolvedEnv, map[string]string{}, ""},
+	// with pod identity
+	{map[string]string{"accountName": "catalog", "dataSourceName": "sql", "scanName": "nightly", "targetValue": "2"}, false, map[string]string{}, map[string]string{}, kedav1alpha1.PodIdentityProviderAzure},
+}
+
+var azPurviewMetricIdentifiers = []azPurviewMetricIdentifier{
+	{&testParseAzPurviewMetadata[1], 0, "s0-azure-purview-sql-nightly"},
+	{&testParseAzPurviewMetadata[9], 1, "s1-azure-purview-sql-nightly"},
+}
+
+func TestAzPurviewParseMetadata(t *testing.T) {
+	for _, testData := range testParseAzPurviewMetadata {
+		_, err := parseAzurePurviewMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, ResolvedEnv: testData.resolvedEnv, AuthParams: testData.authParams, PodIdentity: testData.podIdentity})
+		if err != nil && !testData.isError {
+			t.Errorf("Expected success but got error %s for metadata %v", err, testData.metadata)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Expected error but got success for metadata %v", testData.metadata)
+		}
+	}
+}
+
+func TestAzPurviewGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range azPurviewMetricIdentifiers {
+		meta, err := parseAzurePurviewMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, ResolvedEnv: testData.metadataTestData.resolvedEnv, AuthParams: testData.metadataTestData.authParams, PodIdentity: testData.metadataTestData.podIdentity, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockAzPurviewScaler := azurePurviewScaler{metadata: meta}
+
+		metricSpec := mockAzPurviewScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Errorf("Wrong External metric source name: %s, expected: %s", metricName, testData.name)
+		}
+	}
+}
+
+func TestAzPurviewGetScanRunCount(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/datasources/sql/scans/nightly/runs" || r.URL.Query().Get("api-version") != purviewAPIVersion {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		if r.URL.Query().Get("page") == "2" {
+			fmt.Fprint(w, `{"value":[{"id":"4","status":"Queued"},{"id":"5","status":"Failed"}]}`)
+			return
+		}
+		fmt.Fprintf(w, `{"value":[{"id":"1","status":"Queued"},{"id":"2","status":"InProgress"},{"id":"3","status":"Succeeded"}],"nextLink":"%s%s?api-version=%s&page=2"}`, server.URL, r.URL.Path, purviewAPIVersion)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		statuses string
+		expected int64
+	}{
+		{"", 2},
+		{"Queued,InProgress", 3},
+		{"failed", 1},
+	}
+	for _, test := range tests {
+		meta, err := parseAzurePurviewMetadata(&ScalerConfig{
+			TriggerMetadata: map[string]string{"endpoint": server.URL, "dataSourceName": "sql", "scanName": "nightly", "scanStatuses": test.statuses, "targetValue": "1"},
+			PodIdentity:     kedav1alpha1.PodIdentityProviderAzure,
+		})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		scaler := azurePurviewScaler{metadata: meta, httpClient: http.DefaultClient, authorizer: autorest.NullAuthorizer{}}
+
+		count, err := scaler.getScanRunCount(context.Background())
+		if err != nil {
+			t.Errorf("Expected success for statuses %s but got error: %s", test.statuses, err)
+		}
+		if count != test.expected {
+			t.Errorf("Expected %d scan runs for statuses %s, got %d", test.expected, test.statuses, count)
+		}
+	}
+}
@@ -1,3 +1,6 @@
+//go:build !selective_scalers || scalers_azure
+// +build !selective_scalers scalers_azure
+
 /*
 Copyright 2021 The KEDA Authors
 
@@ -36,9 +39,7 @@ import (
 )
 
 const (
-	queueLengthMetricName    = "queueLength"
-	defaultTargetQueueLength = 5
-	externalMetricType       = "External"
+	queueLengthMetricName = "queueLength"
 )
 
 type azureQueueScaler struct {
@@ -65,10 +66,15 @@ func NewAzureQueueScaler(config *ScalerConfig) (Scaler, error) {
 		return nil, fmt.Errorf("error parsing azure queue metadata: %s", err)
 	}
 
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
 	return &azureQueueScaler{
 		metadata:    meta,
 		podIdentity: podIdentity,
-		httpClient:  kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, false),
+		httpClient:  httpClient,
 	}, nil
 }
 
@@ -0,0 +1,257 @@
+package scalers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	beanstalkdServer          = "server"
+	beanstalkdTube            = "tube"
+	beanstalkdIncludeDelayed  = "includeDelayed"
+	beanstalkdIncludeBuried   = "includeBuried"
+	beanstalkdValue           = "value"
+	beanstalkdActivationValue = "activationValue"
+
+	defaultBeanstalkdValue = 10
+)
+
+type beanstalkdScaler struct {
+	metadata *beanstalkdMetadata
+	timeout  time.Duration
+}
+
+type beanstalkdMetadata struct {
+	// server is the address of beanstalkd, eg. beanstalkd:11300
+	server string
+	tube   string
+	// includeDelayed and includeBuried add the delayed and buried jobs to the ready jobs
+	includeDelayed  bool
+	includeBuried   bool
+	value           int64
+	activationValue int64
+	scalerIndex     int
+}
+
+var beanstalkdLog = logf.Log.WithName("beanstalkd_scaler")
+
+// NewBeanstalkdScaler creates a new beanstalkdScaler
+func NewBeanstalkdScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseBeanstalkdMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing beanstalkd metadata: %s", err)
+	}
+
+	return &beanstalkdScaler{
+		metadata: meta,
+		timeout:  config.GlobalHTTPTimeout,
+	}, nil
+}
+
+func parseBeanstalkdMetadata(config *ScalerConfig) (*beanstalkdMetadata, error) {
+	meta := beanstalkdMetadata{
+		value: defaultBeanstalkdValue,
+	}
+
+	server, err := GetFromAuthOrMeta(config, beanstalkdServer)
+	if err != nil {
+		return nil, err
+	}
+	meta.server = server
+
+	if val, ok := config.TriggerMetadata[beanstalkdTube]; ok && val != "" {
+		meta.tube = val
+	} else {
+		return nil, fmt.Errorf("no %s given", beanstalkdTube)
+	}
+
+	if val, ok := config.TriggerMetadata[beanstalkdIncludeDelayed]; ok && val != "" {
+		includeDelayed, err := strconv.ParseBool(val)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", beanstalkdIncludeDelayed, err)
+		}
+		meta.includeDelayed = includeDelayed
+	}
+
+	if val, ok := config.TriggerMetadata[beanstalkdIncludeBuried]; ok && val != "" {
+		includeBuried, err := strconv.ParseBool(val)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", beanstalkdIncludeBuried, err)
+		}
+		meta.includeBuried = includeBuried
+	}
+
+	if val, ok := config.TriggerMetadata[beanstalkdValue]; ok && val != "" {
+		value, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", beanstalkdValue, err)
+		}
+		meta.value = value
+	}
+
+	if val, ok := config.TriggerMetadata[beanstalkdActivationValue]; ok && val != "" {
+		activationValue, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", beanstalkdActivationValue, err)
+		}
+		meta.activationValue = activationValue
+	}
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+// IsActive returns true if the jobs of the tube are above the activation value
+func (s *beanstalkdScaler) IsActive(ctx context.Context) (bool, error) {
+	jobs, err := s.getTubeJobs(ctx)
+	if err != nil {
+		beanstalkdLog.Error(err, "error getting beanstalkd tube stats", "tube", s.metadata.tube)
+		return false, err
+	}
+
+	return jobs > s.metadata.activationValue, nil
+}
+
+func (s *beanstalkdScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *beanstalkdScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetMetricValue := resource.NewQuantity(s.metadata.value, resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(fmt.Sprintf("beanstalkd-%s", s.metadata.tube))),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetMetricValue,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// getTubeJobs runs stats-tube and returns the ready jobs of the tube, with the delayed and buried ones if
+// included. Tubes only exist while they are used, a tube not found has no jobs
+func (s *beanstalkdScaler) getTubeJobs(ctx context.Context) (int64, error) {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", s.metadata.server)
+	if err != nil {
+		return -1, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return -1, err
+		}
+	}
+
+	if _, err := fmt.Fprintf(conn, "stats-tube %s\r\n", s.metadata.tube); err != nil {
+		return -1, err
+	}
+	stats, err := readBeanstalkdStats(bufio.NewReader(conn))
+	if err != nil {
+		return -1, err
+	}
+	if stats == nil {
+		return 0, nil
+	}
+
+	fields := []string{"current-jobs-ready"}
+	if s.metadata.includeDelayed {
+		fields = append(fields, "current-jobs-delayed")
+	}
+	if s.metadata.includeBuried {
+		fields = append(fields, "current-jobs-buried")
+	}
+
+	var jobs int64
+	for _, field := range fields {
+		val, ok := stats[field]
+		if !ok {
+			return -1, fmt.Errorf("beanstalkd tube stats have no %s", field)
+		}
+		v, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return -1, fmt.Errorf("error parsing beanstalkd tube stats %s: %s", field, err)
+		}
+		jobs += v
+	}
+	return jobs, nil
+}
+
+// readBeanstalkdStats reads the response to stats-tube, the YAML dictionary of the stats, nil when the tube
+// isn't found
+func readBeanstalkdStats(r *bufio.Reader) (map[string]string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, err
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+
+	if line == "NOT_FOUND" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(line, "OK ") {
+		return nil, fmt.Errorf("beanstalkd returned error: %s", line)
+	}
+	length, err := strconv.Atoi(strings.TrimPrefix(line, "OK "))
+	if err != nil {
+		return nil, fmt.Errorf("error parsing beanstalkd response length: %s", err)
+	}
+
+	// the data is followed by \r\n
+	data := make([]byte, length+2)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return nil, err
+	}
+
+	stats := map[string]string{}
+	for _, l := range strings.Split(string(data[:length]), "\n") {
+		kv := strings.SplitN(l, ":", 2)
+		if len(kv) == 2 {
+			stats[strings.TrimSpace(kv[0])] = strings.TrimSpace(kv[1])
+		}
+	}
+	return stats, nil
+}
+
+func (s *beanstalkdScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	jobs, err := s.getTubeJobs(ctx)
+	if err != nil {
+		beanstalkdLog.Error(err, "error getting beanstalkd tube stats", "tube", s.metadata.tube)
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewQuantity(jobs, resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
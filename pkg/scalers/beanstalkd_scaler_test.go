@@ -0,0 +1,141 @@
+package scalers
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type parseBeanstalkdMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+}
+
+type beanstalkdMetricIdentifier struct {
+	metadataTestData *parseBeanstalkdMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testBeanstalkdMetadata = []parseBeanstalkdMetadataTestData{
+	// nothing passed
+	{map[string]string{}, map[string]string{}, true},
+	// properly formed
+	{map[string]string{"server": "beanstalkd:11300", "tube": "emails"}, map[string]string{}, false},
+	// server from auth params, delayed and buried jobs, values
+	{map[string]string{"tube": "reports.daily", "includeDelayed": "true", "includeBuried": "true", "value": "20", "activationValue": "2"}, map[string]string{"server": "beanstalkd:11300"}, false},
+	// missing server
+	{map[string]string{"tube": "emails"}, map[string]string{}, true},
+	// missing tube
+	{map[string]string{"server": "beanstalkd:11300"}, map[string]string{}, true},
+	// malformed includeDelayed
+	{map[string]string{"server": "beanstalkd:11300", "tube": "emails", "includeDelayed": "a"}, map[string]string{}, true},
+	// malformed includeBuried
+	{map[string]string{"server": "beanstalkd:11300", "tube": "emails", "includeBuried": "a"}, map[string]string{}, true},
+	// malformed value
+	{map[string]string{"server": "beanstalkd:11300", "tube": "emails", "value": "a"}, map[string]string{}, true},
+	// malformed activationValue
+	{map[string]string{"server": "beanstalkd:11300", "tube": "emails", "activationValue": "a"}, map[string]string{}, true},
+}
+
+var beanstalkdMetricIdentifiers = []beanstalkdMetricIdentifier{
+	{&testBeanstalkdMetadata[1], 0, "s0-beanstalkd-emails"},
+	{&testBeanstalkdMetadata[2], 1, "s1-beanstalkd-reports-daily"},
+}
+
+func TestBeanstalkdParseMetadata(t *testing.T) {
+	for _, testData := range testBeanstalkdMetadata {
+		_, err := parseBeanstalkdMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Error("Expected success but got error", err)
+		}
+		if testData.isError && err == nil {
+			t.Error("Expected error but got success")
+		}
+	}
+}
+
+func TestBeanstalkdGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range beanstalkdMetricIdentifiers {
+		meta, err := parseBeanstalkdMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, AuthParams: testData.metadataTestData.authParams, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockBeanstalkdScaler := beanstalkdScaler{metadata: meta}
+
+		metricSpec := mockBeanstalkdScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Error("Wrong External metric source name:", metricName)
+		}
+	}
+}
+
+const beanstalkdTestStats = "---\nname: emails\ncurrent-jobs-urgent: 1\ncurrent-jobs-ready: 7\ncurrent-jobs-reserved: 2\ncurrent-jobs-delayed: 3\ncurrent-jobs-buried: 4\ntotal-jobs: 40\n"
+
+func TestBeanstalkdGetTubeJobs(t *testing.T) {
+	var testData = []struct {
+		name          string
+		metadata      map[string]string
+		response      string
+		expectedValue int64
+		isError       bool
+	}{
+		{"ready jobs", map[string]string{}, fmt.Sprintf("OK %d\r\n%s\r\n", len(beanstalkdTestStats), beanstalkdTestStats), 7, false},
+		{"ready and delayed jobs", map[string]string{"includeDelayed": "true"}, fmt.Sprintf("OK %d\r\n%s\r\n", len(beanstalkdTestStats), beanstalkdTestStats), 10, false},
+		{"ready, delayed and buried jobs", map[string]string{"includeDelayed": "true", "includeBuried": "true"}, fmt.Sprintf("OK %d\r\n%s\r\n", len(beanstalkdTestStats), beanstalkdTestStats), 14, false},
+		{"tube not found", map[string]string{}, "NOT_FOUND\r\n", 0, false},
+		{"missing stats", map[string]string{}, "OK 4\r\n---\n\r\n", -1, true},
+		{"error response", map[string]string{}, "BAD_FORMAT\r\n", -1, true},
+	}
+
+	for _, test := range testData {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			listener, err := net.Listen("tcp", "127.0.0.1:0")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer listener.Close()
+			go func() {
+				conn, err := listener.Accept()
+				if err != nil {
+					return
+				}
+				defer conn.Close()
+				command, _ := bufio.NewReader(conn).ReadString('\n')
+				assert.Equal(t, "stats-tube emails\r\n", command)
+				_, _ = conn.Write([]byte(test.response))
+			}()
+
+			test.metadata["server"] = listener.Addr().String()
+			test.metadata["tube"] = "emails"
+			meta, err := parseBeanstalkdMetadata(&ScalerConfig{TriggerMetadata: test.metadata})
+			if err != nil {
+				t.Fatal("Could not parse metadata:", err)
+			}
+			scaler := beanstalkdScaler{metadata: meta, timeout: time.Second}
+
+			value, err := scaler.getTubeJobs(context.TODO())
+
+			assert.Equal(t, test.expectedValue, value)
+			if test.isError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestBeanstalkdReadStatsTruncated(t *testing.T) {
+	_, err := readBeanstalkdStats(bufio.NewReader(strings.NewReader("OK 100\r\n---\n")))
+	assert.Error(t, err)
+}
@@ -0,0 +1,289 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	defaultBuildkiteAPIURL               = "https://api.buildkite.com"
+	defaultBuildkiteQueue                = "default"
+	defaultBuildkiteTargetJobQueueLength = 1
+	buildkitePageSize                    = 100
+)
+
+type buildkiteScaler struct {
+	metadata   *buildkiteMetadata
+	httpClient *http.Client
+}
+
+type buildkiteMetadata struct {
+	buildkiteAPIURL  string
+	organizationSlug string
+	// pipelineSlug limits the jobs to the builds of a pipeline, the builds of all pipelines are read by default
+	pipelineSlug string
+	// queue is the queue of the agents, jobs without a queue agent query rule run on the default queue
+	queue string
+	// includeRunningJobs counts the running jobs too, so the agents running them aren't scaled in
+	includeRunningJobs             bool
+	targetJobQueueLength           int64
+	activationTargetJobQueueLength int64
+	apiToken                       string
+	scalerIndex                    int
+}
+
+type buildkiteBuild struct {
+	ID   string         `json:"id"`
+	Jobs []buildkiteJob `json:"jobs"`
+}
+
+type buildkiteJob struct {
+	ID              string   `json:"id"`
+	Type            string   `json:"type"`
+	State           string   `json:"state"`
+	AgentQueryRules []string `json:"agent_query_rules"`
+}
+
+var buildkiteLog = logf.Log.WithName("buildkite_scaler")
+
+// NewBuildkiteScaler creates a new buildkiteScaler
+func NewBuildkiteScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseBuildkiteMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing buildkite metadata: %s", err)
+	}
+
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &buildkiteScaler{
+		metadata:   meta,
+		httpClient: httpClient,
+	}, nil
+}
+
+func parseBuildkiteMetadata(config *ScalerConfig) (*buildkiteMetadata, error) {
+	meta := buildkiteMetadata{
+		buildkiteAPIURL:      defaultBuildkiteAPIURL,
+		queue:                defaultBuildkiteQueue,
+		targetJobQueueLength: defaultBuildkiteTargetJobQueueLength,
+	}
+
+	if val, ok := config.TriggerMetadata["buildkiteAPIURL"]; ok && val != "" {
+		if _, err := url.ParseRequestURI(val); err != nil {
+			return nil, fmt.Errorf("error parsing buildkiteAPIURL: %s", err)
+		}
+		meta.buildkiteAPIURL = strings.TrimSuffix(val, "/")
+	}
+
+	if val, ok := config.TriggerMetadata["organizationSlug"]; ok && val != "" {
+		meta.organizationSlug = val
+	} else {
+		return nil, fmt.Errorf("no organizationSlug given")
+	}
+
+	meta.pipelineSlug = config.TriggerMetadata["pipelineSlug"]
+
+	if val, ok := config.TriggerMetadata["queue"]; ok && val != "" {
+		meta.queue = val
+	}
+
+	if val, ok := config.TriggerMetadata["includeRunningJobs"]; ok && val != "" {
+		includeRunningJobs, err := strconv.ParseBool(val)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing includeRunningJobs: %s", err)
+		}
+		meta.includeRunningJobs = includeRunningJobs
+	}
+
+	if val, ok := config.TriggerMetadata["targetJobQueueLength"]; ok && val != "" {
+		queueLength, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing targetJobQueueLength: %s", err)
+		}
+		meta.targetJobQueueLength = queueLength
+	}
+
+	if val, ok := config.TriggerMetadata["activationTargetJobQueueLength"]; ok && val != "" {
+		queueLength, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing activationTargetJobQueueLength: %s", err)
+		}
+		meta.activationTargetJobQueueLength = queueLength
+	}
+
+	// apiToken is an API access token with the read_builds scope
+	if val, ok := config.AuthParams["apiToken"]; ok && val != "" {
+		meta.apiToken = val
+	} else if val, ok := config.TriggerMetadata["apiTokenFromEnv"]; ok && val != "" {
+		meta.apiToken = config.ResolvedEnv[val]
+	}
+	if meta.apiToken == "" {
+		return nil, fmt.Errorf("no apiToken given")
+	}
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+// IsActive returns true if the jobs of the queue are more than the activation target
+func (s *buildkiteScaler) IsActive(ctx context.Context) (bool, error) {
+	queueLength, err := s.getJobQueueLength(ctx)
+	if err != nil {
+		buildkiteLog.Error(err, "error getting buildkite job queue length")
+		return false, err
+	}
+
+	return queueLength > s.metadata.activationTargetJobQueueLength, nil
+}
+
+func (s *buildkiteScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *buildkiteScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	name := fmt.Sprintf("buildkite-%s-%s", s.metadata.organizationSlug, s.metadata.queue)
+	targetJobQueueLengthQty := resource.NewQuantity(s.metadata.targetJobQueueLength, resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(name)),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetJobQueueLengthQty,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{External: externalMetric, Type: externalMetricType}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// GetMetrics returns the number of scheduled, and with includeRunningJobs running, jobs of the queue
+func (s *buildkiteScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	queueLength, err := s.getJobQueueLength(ctx)
+	if err != nil {
+		buildkiteLog.Error(err, "error getting buildkite job queue length")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewQuantity(queueLength, resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
+
+// getJobQueueLength counts the jobs of the queue in the scheduled and running builds, the jobs of a build
+// are scheduled while the build is running
+func (s *buildkiteScaler) getJobQueueLength(ctx context.Context) (int64, error) {
+	buildsURL := fmt.Sprintf("%s/v2/organizations/%s/builds", s.metadata.buildkiteAPIURL, url.PathEscape(s.metadata.organizationSlug))
+	if s.metadata.pipelineSlug != "" {
+		buildsURL = fmt.Sprintf("%s/v2/organizations/%s/pipelines/%s/builds", s.metadata.buildkiteAPIURL, url.PathEscape(s.metadata.organizationSlug), url.PathEscape(s.metadata.pipelineSlug))
+	}
+	buildsURL = fmt.Sprintf("%s?state[]=scheduled&state[]=running&per_page=%d", buildsURL, buildkitePageSize)
+
+	var queueLength int64
+	for buildsURL != "" {
+		var builds []buildkiteBuild
+		next, err := s.getBuildkiteAPI(ctx, buildsURL, &builds)
+		if err != nil {
+			return -1, err
+		}
+		for _, build := range builds {
+			for _, job := range build.Jobs {
+				if s.isQueuedJob(job) {
+					queueLength++
+				}
+			}
+		}
+		buildsURL = next
+	}
+	return queueLength, nil
+}
+
+// isQueuedJob returns true for the command jobs of the queue waiting for an agent, or running with includeRunningJobs
+func (s *buildkiteScaler) isQueuedJob(job buildkiteJob) bool {
+	if job.Type != "script" {
+		return false
+	}
+	switch job.State {
+	case "scheduled":
+	case "assigned", "accepted", "running":
+		// an agent took the job already
+		if !s.metadata.includeRunningJobs {
+			return false
+		}
+	default:
+		return false
+	}
+
+	queue := defaultBuildkiteQueue
+	for _, rule := range job.AgentQueryRules {
+		if strings.HasPrefix(rule, "queue=") {
+			queue = strings.TrimPrefix(rule, "queue=")
+		}
+	}
+	return queue == s.metadata.queue
+}
+
+// getBuildkiteAPI decodes the response of the REST API and returns the URL of the next page from the Link header
+func (s *buildkiteScaler) getBuildkiteAPI(ctx context.Context, url string, result interface{}) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+s.metadata.apiToken)
+
+	r, err := s.httpClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer r.Body.Close()
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return "", err
+	}
+	if r.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("the Buildkite REST API returned error. url: %s status: %d response: %s", url, r.StatusCode, string(b))
+	}
+	if err := json.Unmarshal(b, result); err != nil {
+		return "", fmt.Errorf("error decoding the Buildkite REST API response of %s: %s", url, err)
+	}
+	return buildkiteNextPage(r.Header.Get("Link")), nil
+}
+
+// buildkiteNextPage returns the URL of the link with rel="next", eg. <https://api.buildkite.com/v2/...&page=2>; rel="next"
+func buildkiteNextPage(link string) string {
+	for _, part := range strings.Split(link, ",") {
+		fields := strings.Split(part, ";")
+		if len(fields) < 2 {
+			continue
+		}
+		for _, param := range fields[1:] {
+			if strings.TrimSpace(param) == `rel="next"` {
+				return strings.Trim(strings.TrimSpace(fields[0]), "<>")
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,144 @@
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type parseBuildkiteMetadataTestData struct {
+	metadata    map[string]string
+	isError     bool
+	resolvedEnv map[string]string
+	authParams  map[string]string
+}
+
+type buildkiteMetricIdentifier struct {
+	metadataTestData *parseBuildkiteMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testBuildkiteResolvedEnv = map[string]string{
+	"BUILDKITE_API_TOKEN": "token",
+}
+
+var testBuildkiteMetadata = []parseBuildkiteMetadataTestData{
+	// nothing passed
+	{map[string]string{}, true, testBuildkiteResolvedEnv, map[string]string{}},
+	// default queue with token in TriggerAuthentication
+	{map[string]string{"organizationSlug": "kedacore"}, false, testBuildkiteResolvedEnv, map[string]string{"apiToken": "token"}},
+	// queue of a pipeline with token from env
+	{map[string]string{"organizationSlug": "kedacore", "pipelineSlug": "keda", "queue": "linux-large", "includeRunningJobs": "true", "targetJobQueueLength": "2", "activationTargetJobQueueLength": "1", "apiTokenFromEnv": "BUILDKITE_API_TOKEN"}, false, testBuildkiteResolvedEnv, map[string]string{}},
+	// missing organizationSlug
+	{map[string]string{"queue": "default"}, true, testBuildkiteResolvedEnv, map[string]string{"apiToken": "token"}},
+	// invalid buildkiteAPIURL
+	{map[string]string{"buildkiteAPIURL": "buildkite", "organizationSlug": "kedacore"}, true, testBuildkiteResolvedEnv, map[string]string{"apiToken": "token"}},
+	// invalid includeRunningJobs
+	{map[string]string{"organizationSlug": "kedacore", "includeRunningJobs": "a"}, true, testBuildkiteResolvedEnv, map[string]string{"apiToken": "token"}},
+	// invalid targetJobQueueLength
+	{map[string]string{"organizationSlug": "kedacore", "targetJobQueueLength": "a"}, true, testBuildkiteResolvedEnv, map[string]string{"apiToken": "token"}},
+	// invalid activationTargetJobQueueLength
+	{map[string]string{"organizationSlug": "kedacore", "activationTargetJobQueueLength": "a"}, true, testBuildkiteResolvedEnv, map[string]string{"apiToken": "token"}},
+	// missing apiToken
+	{map[string]string{"organizationSlug": "kedacore"}, true, testBuildkiteResolvedEnv, map[string]string{}},
+}
+
+var buildkiteMetricIdentifiers = []buildkiteMetricIdentifier{
+	{&testBuildkiteMetadata[1], 0, "s0-buildkite-kedacore-default"},
+	{&testBuildkiteMetadata[2], 1, "s1-buildkite-kedacore-linux-large"},
+}
+
+func TestParseBuildkiteMetadata(t *testing.T) {
+	for _, testData := range testBuildkiteMetadata {
+		_, err := parseBuildkiteMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, ResolvedEnv: testData.resolvedEnv, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Errorf("Expected success but got error %s for metadata %v", err, testData.metadata)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Expected error but got success for metadata %v", testData.metadata)
+		}
+	}
+}
+
+func TestBuildkiteGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range buildkiteMetricIdentifiers {
+		meta, err := parseBuildkiteMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, ResolvedEnv: testData.metadataTestData.resolvedEnv, AuthParams: testData.metadataTestData.authParams, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockBuildkiteScaler := buildkiteScaler{metadata: meta, httpClient: http.DefaultClient}
+
+		metricSpec := mockBuildkiteScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Errorf("Wrong External metric source name: %s, expected: %s", metricName, testData.name)
+		}
+	}
+}
+
+func TestBuildkiteGetJobQueueLength(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if states := r.URL.Query()["state[]"]; len(states) != 2 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch r.URL.Path {
+		case "/v2/organizations/kedacore/builds":
+			if r.URL.Query().Get("page") == "" {
+				w.Header().Set("Link", fmt.Sprintf(`<%s/v2/organizations/kedacore/builds?state[]=scheduled&state[]=running&per_page=100&page=2>; rel="next", <%s/v2/organizations/kedacore/builds?state[]=scheduled&state[]=running&per_page=100&page=2>; rel="last"`, server.URL, server.URL))
+				fmt.Fprint(w, `[{"id":"b1","jobs":[
+					{"id":"j1","type":"script","state":"scheduled","agent_query_rules":[]},
+					{"id":"j2","type":"script","state":"running","agent_query_rules":["queue=default"]},
+					{"id":"j3","type":"waiter","state":"scheduled"},
+					{"id":"j4","type":"script","state":"scheduled","agent_query_rules":["queue=linux-large","os=linux"]}
+				]}]`)
+				return
+			}
+			fmt.Fprint(w, `[{"id":"b2","jobs":[
+				{"id":"j5","type":"script","state":"scheduled","agent_query_rules":["queue=default"]},
+				{"id":"j6","type":"script","state":"waiting","agent_query_rules":["queue=default"]}
+			]}]`)
+		case "/v2/organizations/kedacore/pipelines/keda/builds":
+			fmt.Fprint(w, `[{"id":"b3","jobs":[{"id":"j7","type":"script","state":"assigned","agent_query_rules":["queue=linux-large"]}]}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		metadata map[string]string
+		expected int64
+	}{
+		{map[string]string{}, 2},
+		{map[string]string{"includeRunningJobs": "true"}, 3},
+		{map[string]string{"queue": "linux-large"}, 1},
+		{map[string]string{"pipelineSlug": "keda", "queue": "linux-large"}, 0},
+		{map[string]string{"pipelineSlug": "keda", "queue": "linux-large", "includeRunningJobs": "true"}, 1},
+	}
+	for _, test := range tests {
+		test.metadata["buildkiteAPIURL"] = server.URL
+		test.metadata["organizationSlug"] = "kedacore"
+		meta, err := parseBuildkiteMetadata(&ScalerConfig{TriggerMetadata: test.metadata, AuthParams: map[string]string{"apiToken": "token"}})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		scaler := buildkiteScaler{metadata: meta, httpClient: http.DefaultClient}
+
+		queueLength, err := scaler.getJobQueueLength(context.Background())
+		if err != nil {
+			t.Errorf("Expected success but got error %s for %v", err, test.metadata)
+		}
+		if queueLength != test.expected {
+			t.Errorf("Expected %d, got %d for %v", test.expected, queueLength, test.metadata)
+		}
+	}
+}
@@ -0,0 +1,338 @@
+package scalers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	url_pkg "net/url"
+	"strconv"
+	"strings"
+
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/clientcredentials"
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	zeebeURL                      = "url"
+	zeebeAPI                      = "api"
+	zeebeJobType                  = "jobType"
+	zeebeFlowNodeID               = "flowNodeId"
+	zeebeProcessDefinitionKey     = "processDefinitionKey"
+	zeebeTargetJobCount           = "targetJobCount"
+	zeebeActivationTargetJobCount = "activationTargetJobCount"
+	zeebeToken                    = "token"
+	zeebeClientID                 = "clientId"
+	zeebeClientSecret             = "clientSecret"
+	zeebeAuthorizationServerURL   = "authorizationServerUrl"
+	zeebeAudience                 = "audience"
+	zeebeMetricName               = "metricName"
+
+	// zeebeAPIZeebe is the REST api of the Zeebe gateway, it searches the jobs of a job type
+	zeebeAPIZeebe = "zeebe"
+	// zeebeAPIOperate is the api of Operate, it searches the active instances of a service task
+	zeebeAPIOperate = "operate"
+
+	defaultZeebeTargetJobCount = 10
+)
+
+type zeebeScaler struct {
+	metadata    *zeebeMetadata
+	httpClient  *http.Client
+	tokenSource oauth2.TokenSource
+}
+
+type zeebeMetadata struct {
+	url string
+	api string
+	// jobType is searched in the zeebe api, flowNodeId and processDefinitionKey in the operate api
+	jobType                  string
+	flowNodeID               string
+	processDefinitionKey     int64
+	targetJobCount           int64
+	activationTargetJobCount int64
+
+	// auth, either a static token or the client credentials of an OAuth client
+	token                  string
+	clientID               string
+	clientSecret           string
+	authorizationServerURL string
+	audience               string
+
+	metricName  string
+	scalerIndex int
+}
+
+type zeebeJobSearchRequest struct {
+	Filter map[string]interface{} `json:"filter"`
+	Page   map[string]int         `json:"page"`
+}
+
+type zeebeJobSearchResult struct {
+	Page struct {
+		TotalItems int64 `json:"totalItems"`
+	} `json:"page"`
+}
+
+type zeebeOperateSearchRequest struct {
+	Filter map[string]interface{} `json:"filter"`
+	Size   int                    `json:"size"`
+}
+
+type zeebeOperateSearchResult struct {
+	Total int64 `json:"total"`
+}
+
+var zeebeLog = logf.Log.WithName("camunda_zeebe_scaler")
+
+// NewZeebeScaler creates a new zeebeScaler
+func NewZeebeScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseZeebeMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing camunda zeebe metadata: %s", err)
+	}
+
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &zeebeScaler{
+		metadata:    meta,
+		httpClient:  httpClient,
+		tokenSource: newZeebeTokenSource(meta, httpClient),
+	}, nil
+}
+
+func parseZeebeMetadata(config *ScalerConfig) (*zeebeMetadata, error) {
+	meta := zeebeMetadata{
+		api:            zeebeAPIZeebe,
+		targetJobCount: defaultZeebeTargetJobCount,
+	}
+
+	url, err := GetFromAuthOrMeta(config, zeebeURL)
+	if err != nil {
+		return nil, err
+	}
+	meta.url = strings.TrimSuffix(url, "/")
+
+	if val, ok := config.TriggerMetadata[zeebeAPI]; ok && val != "" {
+		meta.api = val
+	}
+
+	var name string
+	switch meta.api {
+	case zeebeAPIZeebe:
+		if val, ok := config.TriggerMetadata[zeebeJobType]; ok && val != "" {
+			meta.jobType = val
+		} else {
+			return nil, fmt.Errorf("no %s given", zeebeJobType)
+		}
+		name = meta.jobType
+	case zeebeAPIOperate:
+		if val, ok := config.TriggerMetadata[zeebeFlowNodeID]; ok && val != "" {
+			meta.flowNodeID = val
+		} else {
+			return nil, fmt.Errorf("no %s given", zeebeFlowNodeID)
+		}
+		if val, ok := config.TriggerMetadata[zeebeProcessDefinitionKey]; ok && val != "" {
+			processDefinitionKey, err := strconv.ParseInt(val, 10, 64)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing %s: %s", zeebeProcessDefinitionKey, err)
+			}
+			meta.processDefinitionKey = processDefinitionKey
+		}
+		name = meta.flowNodeID
+	default:
+		return nil, fmt.Errorf("%s %s must be one of %s, %s", zeebeAPI, meta.api, zeebeAPIZeebe, zeebeAPIOperate)
+	}
+
+	if val, ok := config.TriggerMetadata[zeebeTargetJobCount]; ok && val != "" {
+		targetJobCount, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", zeebeTargetJobCount, err)
+		}
+		meta.targetJobCount = targetJobCount
+	}
+
+	if val, ok := config.TriggerMetadata[zeebeActivationTargetJobCount]; ok && val != "" {
+		activationTargetJobCount, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", zeebeActivationTargetJobCount, err)
+		}
+		meta.activationTargetJobCount = activationTargetJobCount
+	}
+
+	meta.token = config.AuthParams[zeebeToken]
+	meta.clientID = config.AuthParams[zeebeClientID]
+	meta.clientSecret = config.AuthParams[zeebeClientSecret]
+	if meta.clientID != "" || meta.clientSecret != "" {
+		if meta.token != "" {
+			return nil, fmt.Errorf("either %s or %s and %s can be given", zeebeToken, zeebeClientID, zeebeClientSecret)
+		}
+		if meta.clientID == "" || meta.clientSecret == "" {
+			return nil, fmt.Errorf("%s and %s must be given together", zeebeClientID, zeebeClientSecret)
+		}
+		authorizationServerURL, err := GetFromAuthOrMeta(config, zeebeAuthorizationServerURL)
+		if err != nil {
+			return nil, err
+		}
+		meta.authorizationServerURL = authorizationServerURL
+		meta.audience, _ = GetFromAuthOrMeta(config, zeebeAudience)
+	}
+
+	if val, ok := config.TriggerMetadata[zeebeMetricName]; ok && val != "" {
+		name = val
+	}
+	meta.metricName = kedautil.NormalizeString(fmt.Sprintf("camunda-zeebe-%s", name))
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+// newZeebeTokenSource returns the source of the access tokens of the OAuth client, the tokens are cached until
+// they expire. It's nil without client credentials
+func newZeebeTokenSource(meta *zeebeMetadata, httpClient *http.Client) oauth2.TokenSource {
+	if meta.clientID == "" {
+		return nil
+	}
+	config := clientcredentials.Config{
+		ClientID:     meta.clientID,
+		ClientSecret: meta.clientSecret,
+		TokenURL:     meta.authorizationServerURL,
+		AuthStyle:    oauth2.AuthStyleInParams,
+	}
+	if meta.audience != "" {
+		config.EndpointParams = url_pkg.Values{"audience": {meta.audience}}
+	}
+	return config.TokenSource(context.WithValue(context.Background(), oauth2.HTTPClient, httpClient))
+}
+
+// IsActive returns true if the job count is above the activation target
+func (s *zeebeScaler) IsActive(ctx context.Context) (bool, error) {
+	count, err := s.getJobCount(ctx)
+	if err != nil {
+		zeebeLog.Error(err, "error getting camunda zeebe job count")
+		return false, err
+	}
+
+	return count > s.metadata.activationTargetJobCount, nil
+}
+
+func (s *zeebeScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *zeebeScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetMetricValue := resource.NewQuantity(s.metadata.targetJobCount, resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, s.metadata.metricName),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetMetricValue,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// getJobCount returns the number of jobs of the job type waiting for a worker in the zeebe api, or the number of
+// active instances of the service task in the operate api. Only the count of the search is read, not the jobs
+func (s *zeebeScaler) getJobCount(ctx context.Context) (int64, error) {
+	var url string
+	var body interface{}
+	if s.metadata.api == zeebeAPIZeebe {
+		url = fmt.Sprintf("%s/v2/jobs/search", s.metadata.url)
+		body = zeebeJobSearchRequest{
+			Filter: map[string]interface{}{"type": s.metadata.jobType, "state": "CREATED"},
+			Page:   map[string]int{"limit": 1},
+		}
+	} else {
+		url = fmt.Sprintf("%s/v1/flownode-instances/search", s.metadata.url)
+		filter := map[string]interface{}{"flowNodeId": s.metadata.flowNodeID, "state": "ACTIVE"}
+		if s.metadata.processDefinitionKey != 0 {
+			filter["processDefinitionKey"] = s.metadata.processDefinitionKey
+		}
+		body = zeebeOperateSearchRequest{Filter: filter, Size: 1}
+	}
+
+	b, err := json.Marshal(body)
+	if err != nil {
+		return -1, err
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(b))
+	if err != nil {
+		return -1, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	if s.tokenSource != nil {
+		token, err := s.tokenSource.Token()
+		if err != nil {
+			return -1, fmt.Errorf("error getting camunda access token: %s", err)
+		}
+		token.SetAuthHeader(req)
+	} else if s.metadata.token != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.metadata.token))
+	}
+
+	r, err := s.httpClient.Do(req)
+	if err != nil {
+		return -1, err
+	}
+
+	b, err = ioutil.ReadAll(r.Body)
+	if err != nil {
+		return -1, err
+	}
+	r.Body.Close()
+
+	if !(r.StatusCode >= 200 && r.StatusCode <= 299) {
+		return -1, fmt.Errorf("camunda %s api returned error. status: %d response: %s", s.metadata.api, r.StatusCode, string(b))
+	}
+
+	if s.metadata.api == zeebeAPIZeebe {
+		var result zeebeJobSearchResult
+		if err := json.Unmarshal(b, &result); err != nil {
+			return -1, err
+		}
+		return result.Page.TotalItems, nil
+	}
+
+	var result zeebeOperateSearchResult
+	if err := json.Unmarshal(b, &result); err != nil {
+		return -1, err
+	}
+	return result.Total, nil
+}
+
+func (s *zeebeScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	count, err := s.getJobCount(ctx)
+	if err != nil {
+		zeebeLog.Error(err, "error getting camunda zeebe job count")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewQuantity(count, resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
@@ -0,0 +1,187 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type parseZeebeMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+}
+
+type zeebeMetricIdentifier struct {
+	metadataTestData *parseZeebeMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testZeebeMetadata = []parseZeebeMetadataTestData{
+	// nothing passed
+	{map[string]string{}, map[string]string{}, true},
+	// properly formed job type
+	{map[string]string{"url": "http://zeebe-gateway:8080", "jobType": "payment-service"}, map[string]string{}, false},
+	// operate api with flowNodeId, processDefinitionKey, targets and client credentials
+	{map[string]string{"url": "http://operate:8080/", "api": "operate", "flowNodeId": "charge-card", "processDefinitionKey": "2251799813685249", "targetJobCount": "5", "activationTargetJobCount": "1", "authorizationServerUrl": "https://login.cloud.camunda.io/oauth/token", "audience": "operate.camunda.io"}, map[string]string{"clientId": "keda", "clientSecret": "secret"}, false},
+	// url and token from auth params, metricName
+	{map[string]string{"jobType": "payment-service", "metricName": "payments"}, map[string]string{"url": "https://zeebe.example.com", "token": "token"}, false},
+	// missing url
+	{map[string]string{"jobType": "payment-service"}, map[string]string{}, true},
+	// unknown api
+	{map[string]string{"url": "http://zeebe-gateway:8080", "api": "tasklist", "jobType": "payment-service"}, map[string]string{}, true},
+	// missing jobType
+	{map[string]string{"url": "http://zeebe-gateway:8080"}, map[string]string{}, true},
+	// missing flowNodeId
+	{map[string]string{"url": "http://operate:8080", "api": "operate", "jobType": "payment-service"}, map[string]string{}, true},
+	// malformed processDefinitionKey
+	{map[string]string{"url": "http://operate:8080", "api": "operate", "flowNodeId": "charge-card", "processDefinitionKey": "a"}, map[string]string{}, true},
+	// malformed targetJobCount
+	{map[string]string{"url": "http://zeebe-gateway:8080", "jobType": "payment-service", "targetJobCount": "a"}, map[string]string{}, true},
+	// malformed activationTargetJobCount
+	{map[string]string{"url": "http://zeebe-gateway:8080", "jobType": "payment-service", "activationTargetJobCount": "a"}, map[string]string{}, true},
+	// clientId without clientSecret
+	{map[string]string{"url": "http://zeebe-gateway:8080", "jobType": "payment-service", "authorizationServerUrl": "https://login.cloud.camunda.io/oauth/token"}, map[string]string{"clientId": "keda"}, true},
+	// client credentials without authorizationServerUrl
+	{map[string]string{"url": "http://zeebe-gateway:8080", "jobType": "payment-service"}, map[string]string{"clientId": "keda", "clientSecret": "secret"}, true},
+	// token and client credentials
+	{map[string]string{"url": "http://zeebe-gateway:8080", "jobType": "payment-service", "authorizationServerUrl": "https://login.cloud.camunda.io/oauth/token"}, map[string]string{"token": "token", "clientId": "keda", "clientSecret": "secret"}, true},
+}
+
+var zeebeMetricIdentifiers = []zeebeMetricIdentifier{
+	{&testZeebeMetadata[1], 0, "s0-camunda-zeebe-payment-service"},
+	{&testZeebeMetadata[2], 1, "s1-camunda-zeebe-charge-card"},
+	{&testZeebeMetadata[3], 2, "s2-camunda-zeebe-payments"},
+}
+
+func TestZeebeParseMetadata(t *testing.T) {
+	for _, testData := range testZeebeMetadata {
+		_, err := parseZeebeMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Error("Expected success but got error", err)
+		}
+		if testData.isError && err == nil {
+			t.Error("Expected error but got success")
+		}
+	}
+}
+
+func TestZeebeGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range zeebeMetricIdentifiers {
+		meta, err := parseZeebeMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, AuthParams: testData.metadataTestData.authParams, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockZeebeScaler := zeebeScaler{
+			metadata:   meta,
+			httpClient: http.DefaultClient,
+		}
+
+		metricSpec := mockZeebeScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Error("Wrong External metric source name:", metricName)
+		}
+	}
+}
+
+func TestZeebeGetJobCount(t *testing.T) {
+	var testData = []struct {
+		name           string
+		metadata       map[string]string
+		authParams     map[string]string
+		path           string
+		filter         map[string]interface{}
+		bodyStr        string
+		responseStatus int
+		expectedCount  int64
+		isError        bool
+	}{
+		{
+			"jobs of job type with token",
+			map[string]string{"jobType": "payment-service"},
+			map[string]string{"token": "token"},
+			"/v2/jobs/search",
+			map[string]interface{}{"type": "payment-service", "state": "CREATED"},
+			`{"items":[{"jobKey":"1"}],"page":{"totalItems":42}}`,
+			http.StatusOK, 42, false,
+		},
+		{
+			"active service task instances with client credentials",
+			map[string]string{"api": "operate", "flowNodeId": "charge-card", "processDefinitionKey": "2251799813685249", "audience": "operate-api"},
+			map[string]string{"clientId": "keda", "clientSecret": "secret"},
+			"/v1/flownode-instances/search",
+			map[string]interface{}{"flowNodeId": "charge-card", "state": "ACTIVE", "processDefinitionKey": float64(2251799813685249)},
+			`{"items":[{"key":1}],"total":7}`,
+			http.StatusOK, 7, false,
+		},
+		{
+			"error status response",
+			map[string]string{"jobType": "payment-service"},
+			map[string]string{"token": "token"},
+			"/v2/jobs/search",
+			map[string]interface{}{"type": "payment-service", "state": "CREATED"},
+			`{"title":"UNAUTHORIZED","status":401}`,
+			http.StatusUnauthorized, -1, true,
+		},
+	}
+
+	for _, test := range testData {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			mux := http.NewServeMux()
+			mux.HandleFunc("/oauth/token", func(writer http.ResponseWriter, request *http.Request) {
+				assert.NoError(t, request.ParseForm())
+				assert.Equal(t, "client_credentials", request.PostForm.Get("grant_type"))
+				assert.Equal(t, "keda", request.PostForm.Get("client_id"))
+				assert.Equal(t, "secret", request.PostForm.Get("client_secret"))
+				assert.Equal(t, "operate-api", request.PostForm.Get("audience"))
+				writer.Header().Set("Content-Type", "application/json")
+				if _, err := writer.Write([]byte(`{"access_token":"client-token","token_type":"Bearer","expires_in":300}`)); err != nil {
+					t.Fatal(err)
+				}
+			})
+			mux.HandleFunc(test.path, func(writer http.ResponseWriter, request *http.Request) {
+				if _, ok := test.authParams["token"]; ok {
+					assert.Equal(t, "Bearer token", request.Header.Get("Authorization"))
+				} else {
+					assert.Equal(t, "Bearer client-token", request.Header.Get("Authorization"))
+				}
+				var body struct {
+					Filter map[string]interface{} `json:"filter"`
+				}
+				assert.NoError(t, json.NewDecoder(request.Body).Decode(&body))
+				assert.Equal(t, test.filter, body.Filter)
+				writer.WriteHeader(test.responseStatus)
+
+				if _, err := writer.Write([]byte(test.bodyStr)); err != nil {
+					t.Fatal(err)
+				}
+			})
+			server := httptest.NewServer(mux)
+			defer server.Close()
+
+			test.metadata["url"] = server.URL
+			test.metadata["authorizationServerUrl"] = server.URL + "/oauth/token"
+			meta, err := parseZeebeMetadata(&ScalerConfig{TriggerMetadata: test.metadata, AuthParams: test.authParams})
+			if err != nil {
+				t.Fatal("Could not parse metadata:", err)
+			}
+			scaler := zeebeScaler{metadata: meta, httpClient: http.DefaultClient, tokenSource: newZeebeTokenSource(meta, http.DefaultClient)}
+
+			count, err := scaler.getJobCount(context.TODO())
+
+			assert.Equal(t, test.expectedCount, count)
+			if test.isError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
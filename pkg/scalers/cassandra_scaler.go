@@ -1,7 +1,11 @@
+//go:build !selective_scalers || scalers_database
+// +build !selective_scalers scalers_database
+
 package scalers
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strconv"
 	"strings"
@@ -36,6 +40,8 @@ type CassandraMetadata struct {
 	targetQueryValue int
 	metricName       string
 	scalerIndex      int
+	enableTLS        bool
+	tlsOptions       kedautil.TLSOptions
 }
 
 var cassandraLog = logf.Log.WithName("cassandra_scaler")
@@ -78,7 +84,9 @@ func ParseCassandraMetadata(config *ScalerConfig) (*CassandraMetadata, error) {
 		return nil, fmt.Errorf("no targetQueryValue given")
 	}
 
-	if val, ok := config.TriggerMetadata["username"]; ok {
+	if val, ok := config.AuthParams["username"]; ok && val != "" {
+		meta.username = val
+	} else if val, ok := config.TriggerMetadata["username"]; ok {
 		meta.username = val
 	} else {
 		return nil, fmt.Errorf("no username given")
@@ -116,7 +124,11 @@ func ParseCassandraMetadata(config *ScalerConfig) (*CassandraMetadata, error) {
 	}
 
 	if val, ok := config.TriggerMetadata["consistency"]; ok {
-		meta.consistency = gocql.ParseConsistency(val)
+		consistency, err := gocql.ParseConsistencyWrapper(val)
+		if err != nil {
+			return nil, fmt.Errorf("consistency parsing error %s", err.Error())
+		}
+		meta.consistency = consistency
 	} else {
 		meta.consistency = gocql.One
 	}
@@ -139,6 +151,29 @@ func ParseCassandraMetadata(config *ScalerConfig) (*CassandraMetadata, error) {
 		return nil, fmt.Errorf("no password given")
 	}
 
+	if val, ok := config.AuthParams["tls"]; ok {
+		val = strings.TrimSpace(val)
+
+		if val == "enable" {
+			certGiven := config.AuthParams["cert"] != ""
+			keyGiven := config.AuthParams["key"] != ""
+			if certGiven && !keyGiven {
+				return nil, errors.New("key must be provided with cert")
+			}
+			if keyGiven && !certGiven {
+				return nil, errors.New("cert must be provided with key")
+			}
+			tlsOptions, err := getTLSOptions(config)
+			if err != nil {
+				return nil, err
+			}
+			meta.tlsOptions = tlsOptions
+			meta.enableTLS = true
+		} else {
+			return nil, fmt.Errorf("err incorrect value for TLS given: %s", val)
+		}
+	}
+
 	meta.scalerIndex = config.ScalerIndex
 
 	return &meta, nil
@@ -153,6 +188,17 @@ func NewCassandraSession(meta *CassandraMetadata) (*gocql.Session, error) {
 		Username: meta.username,
 		Password: meta.password,
 	}
+	if meta.enableTLS {
+		tlsConfig, err := kedautil.NewTLSConfigFromOptions(meta.tlsOptions)
+		if err != nil {
+			return nil, err
+		}
+		// gocql only skips the verification of the server certificate without host verification
+		cluster.SslOpts = &gocql.SslOptions{
+			Config:                 tlsConfig,
+			EnableHostVerification: !meta.tlsOptions.UnsafeSsl,
+		}
+	}
 
 	session, err := cluster.CreateSession()
 	if err != nil {
@@ -1,3 +1,6 @@
+//go:build !selective_scalers || scalers_database
+// +build !selective_scalers scalers_database
+
 package scalers
 
 import (
@@ -5,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/gocql/gocql"
+	"github.com/stretchr/testify/assert"
 )
 
 type parseCassandraMetadataTestData struct {
@@ -40,6 +44,18 @@ var testCassandraMetadata = []parseCassandraMetadataTestData{
 	{map[string]string{"query": "SELECT COUNT(*) FROM test_keyspace.test_table;", "targetQueryValue": "1", "username": "cassandra", "clusterIPAddress": "cassandra.test:9042", "ScalerIndex": "0", "metricName": "myMetric"}, true, map[string]string{"password": "Y2Fzc2FuZHJhCg=="}},
 	// no password passed
 	{map[string]string{"query": "SELECT COUNT(*) FROM test_keyspace.test_table;", "targetQueryValue": "1", "username": "cassandra", "clusterIPAddress": "cassandra.test:9042", "keyspace": "test_keyspace", "ScalerIndex": "0", "metricName": "myMetric"}, true, map[string]string{}},
+	// username from TriggerAuthentication
+	{map[string]string{"query": "SELECT COUNT(*) FROM test_keyspace.test_table;", "targetQueryValue": "1", "clusterIPAddress": "cassandra.test:9042", "keyspace": "test_keyspace"}, false, map[string]string{"username": "cassandra", "password": "Y2Fzc2FuZHJhCg=="}},
+	// consistency level
+	{map[string]string{"query": "SELECT COUNT(*) FROM test_keyspace.test_table;", "targetQueryValue": "1", "username": "cassandra", "clusterIPAddress": "cassandra.test:9042", "keyspace": "test_keyspace", "consistency": "local_quorum"}, false, map[string]string{"password": "Y2Fzc2FuZHJhCg=="}},
+	// invalid consistency level
+	{map[string]string{"query": "SELECT COUNT(*) FROM test_keyspace.test_table;", "targetQueryValue": "1", "username": "cassandra", "clusterIPAddress": "cassandra.test:9042", "keyspace": "test_keyspace", "consistency": "most"}, true, map[string]string{"password": "Y2Fzc2FuZHJhCg=="}},
+	// tls enabled with ca, cert and key
+	{map[string]string{"query": "SELECT COUNT(*) FROM test_keyspace.test_table;", "targetQueryValue": "1", "username": "cassandra", "clusterIPAddress": "cassandra.test:9042", "keyspace": "test_keyspace"}, false, map[string]string{"password": "Y2Fzc2FuZHJhCg==", "tls": "enable", "ca": "caaa", "cert": "ceert", "key": "keey"}},
+	// tls with an invalid value
+	{map[string]string{"query": "SELECT COUNT(*) FROM test_keyspace.test_table;", "targetQueryValue": "1", "username": "cassandra", "clusterIPAddress": "cassandra.test:9042", "keyspace": "test_keyspace"}, true, map[string]string{"password": "Y2Fzc2FuZHJhCg==", "tls": "yes"}},
+	// tls cert without key
+	{map[string]string{"query": "SELECT COUNT(*) FROM test_keyspace.test_table;", "targetQueryValue": "1", "username": "cassandra", "clusterIPAddress": "cassandra.test:9042", "keyspace": "test_keyspace"}, true, map[string]string{"password": "Y2Fzc2FuZHJhCg==", "tls": "enable", "cert": "ceert"}},
 }
 
 var cassandraMetricIdentifiers = []cassandraMetricIdentifier{
@@ -78,3 +94,22 @@ func TestCassandraGetMetricSpecForScaling(t *testing.T) {
 		}
 	}
 }
+
+func TestCassandraParseTLSAndConsistency(t *testing.T) {
+	meta, err := ParseCassandraMetadata(&ScalerConfig{TriggerMetadata: testCassandraMetadata[11].metadata, AuthParams: testCassandraMetadata[11].authParams})
+	if err != nil {
+		t.Fatal("Could not parse metadata:", err)
+	}
+	assert.Equal(t, gocql.LocalQuorum, meta.consistency)
+	assert.False(t, meta.enableTLS)
+
+	meta, err = ParseCassandraMetadata(&ScalerConfig{TriggerMetadata: testCassandraMetadata[13].metadata, AuthParams: testCassandraMetadata[13].authParams})
+	if err != nil {
+		t.Fatal("Could not parse metadata:", err)
+	}
+	assert.Equal(t, gocql.One, meta.consistency)
+	assert.True(t, meta.enableTLS)
+	assert.Equal(t, "caaa", meta.tlsOptions.CA)
+	assert.Equal(t, "ceert", meta.tlsOptions.Cert)
+	assert.Equal(t, "keey", meta.tlsOptions.Key)
+}
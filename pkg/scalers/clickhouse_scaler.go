@@ -0,0 +1,230 @@
+//go:build !selective_scalers || scalers_database
+// +build !selective_scalers scalers_database
+
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	url_pkg "net/url"
+	"strconv"
+	"strings"
+
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	clickHouseHost                       = "host"
+	clickHouseDatabase                   = "database"
+	clickHouseUsername                   = "username"
+	clickHousePassword                   = "password"
+	clickHouseQuery                      = "query"
+	clickHouseTargetQueryValue           = "targetQueryValue"
+	clickHouseActivationTargetQueryValue = "activationTargetQueryValue"
+	clickHouseMetricName                 = "metricName"
+
+	defaultClickHouseDatabase = "default"
+	defaultClickHouseUsername = "default"
+	// clickHouseNull is the NULL value in the TabSeparated format
+	clickHouseNull = `\N`
+)
+
+type clickHouseScaler struct {
+	metadata   *clickHouseMetadata
+	httpClient *http.Client
+}
+
+type clickHouseMetadata struct {
+	// host is the URL of the HTTP interface, eg. http://clickhouse:8123
+	host                       string
+	database                   string
+	username                   string
+	password                   string
+	query                      string
+	targetQueryValue           float64
+	activationTargetQueryValue float64
+	metricName                 string
+	scalerIndex                int
+}
+
+var clickHouseLog = logf.Log.WithName("clickhouse_scaler")
+
+// NewClickHouseScaler creates a new clickHouseScaler
+func NewClickHouseScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseClickHouseMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing clickhouse metadata: %s", err)
+	}
+
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &clickHouseScaler{
+		metadata:   meta,
+		httpClient: httpClient,
+	}, nil
+}
+
+func parseClickHouseMetadata(config *ScalerConfig) (*clickHouseMetadata, error) {
+	meta := clickHouseMetadata{
+		database: defaultClickHouseDatabase,
+		username: defaultClickHouseUsername,
+	}
+
+	host, err := GetFromAuthOrMeta(config, clickHouseHost)
+	if err != nil {
+		return nil, err
+	}
+	if u, err := url_pkg.ParseRequestURI(host); err != nil || u.Host == "" {
+		return nil, fmt.Errorf("%s %s must be the url of the HTTP interface like http://host:8123", clickHouseHost, host)
+	}
+	meta.host = strings.TrimSuffix(host, "/")
+
+	if val, err := GetFromAuthOrMeta(config, clickHouseDatabase); err == nil {
+		meta.database = val
+	}
+	if val, err := GetFromAuthOrMeta(config, clickHouseUsername); err == nil {
+		meta.username = val
+	}
+	meta.password = config.AuthParams[clickHousePassword]
+
+	if val, ok := config.TriggerMetadata[clickHouseQuery]; ok && val != "" {
+		meta.query = val
+	} else {
+		return nil, fmt.Errorf("no %s given", clickHouseQuery)
+	}
+
+	if val, ok := config.TriggerMetadata[clickHouseTargetQueryValue]; ok && val != "" {
+		targetQueryValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", clickHouseTargetQueryValue, err)
+		}
+		meta.targetQueryValue = targetQueryValue
+	} else {
+		return nil, fmt.Errorf("no %s given", clickHouseTargetQueryValue)
+	}
+
+	if val, ok := config.TriggerMetadata[clickHouseActivationTargetQueryValue]; ok && val != "" {
+		activationTargetQueryValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", clickHouseActivationTargetQueryValue, err)
+		}
+		meta.activationTargetQueryValue = activationTargetQueryValue
+	}
+
+	metricName := meta.database
+	if val, ok := config.TriggerMetadata[clickHouseMetricName]; ok && val != "" {
+		metricName = val
+	}
+	meta.metricName = kedautil.NormalizeString(fmt.Sprintf("clickhouse-%s", metricName))
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+// IsActive returns true if the result of the query is above the activation target
+func (s *clickHouseScaler) IsActive(ctx context.Context) (bool, error) {
+	val, err := s.getQueryResult(ctx)
+	if err != nil {
+		clickHouseLog.Error(err, "error querying clickhouse")
+		return false, err
+	}
+
+	return val > s.metadata.activationTargetQueryValue, nil
+}
+
+func (s *clickHouseScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *clickHouseScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetMetricValue := resource.NewMilliQuantity(int64(s.metadata.targetQueryValue*1000), resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, s.metadata.metricName),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetMetricValue,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// getQueryResult runs the query over the HTTP interface and returns its single value, a query without rows
+// or returning NULL is 0. The query is sent in a GET request so ClickHouse runs it in readonly mode
+func (s *clickHouseScaler) getQueryResult(ctx context.Context) (float64, error) {
+	query := url_pkg.Values{}
+	query.Set("query", s.metadata.query)
+	query.Set("database", s.metadata.database)
+	query.Set("default_format", "TabSeparated")
+	url := fmt.Sprintf("%s/?%s", s.metadata.host, query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return -1, err
+	}
+	req.Header.Set("X-ClickHouse-User", s.metadata.username)
+	if s.metadata.password != "" {
+		req.Header.Set("X-ClickHouse-Key", s.metadata.password)
+	}
+
+	r, err := s.httpClient.Do(req)
+	if err != nil {
+		return -1, err
+	}
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return -1, err
+	}
+	r.Body.Close()
+
+	if !(r.StatusCode >= 200 && r.StatusCode <= 299) {
+		return -1, fmt.Errorf("clickhouse api returned error. status: %d response: %s", r.StatusCode, string(b))
+	}
+
+	result := strings.TrimSuffix(string(b), "\n")
+	if result == "" || result == clickHouseNull {
+		return 0, nil
+	}
+	if strings.ContainsAny(result, "\t\n") {
+		return -1, fmt.Errorf("clickhouse query has to return a single value, got %s", result)
+	}
+	v, err := strconv.ParseFloat(result, 64)
+	if err != nil {
+		return -1, fmt.Errorf("error parsing clickhouse query result: %s", err)
+	}
+	return v, nil
+}
+
+func (s *clickHouseScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	val, err := s.getQueryResult(ctx)
+	if err != nil {
+		clickHouseLog.Error(err, "error querying clickhouse")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewMilliQuantity(int64(val*1000), resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
@@ -0,0 +1,140 @@
+//go:build !selective_scalers || scalers_database
+// +build !selective_scalers scalers_database
+
+package scalers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type parseClickHouseMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+}
+
+type clickHouseMetricIdentifier struct {
+	metadataTestData *parseClickHouseMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testClickHouseMetadata = []parseClickHouseMetadataTestData{
+	// nothing passed
+	{map[string]string{}, map[string]string{}, true},
+	// properly formed
+	{map[string]string{"host": "http://clickhouse:8123", "query": "SELECT count() FROM buffer", "targetQueryValue": "1000"}, map[string]string{}, false},
+	// host, database and credentials from auth params, activationTargetQueryValue, metricName and unsafeSsl
+	{map[string]string{"query": "SELECT count() FROM buffer", "targetQueryValue": "2.5", "activationTargetQueryValue": "1", "metricName": "buffer", "unsafeSsl": "true"}, map[string]string{"host": "https://clickhouse:8443/", "database": "ingest", "username": "keda", "password": "secret"}, false},
+	// missing host
+	{map[string]string{"query": "SELECT count() FROM buffer", "targetQueryValue": "1000"}, map[string]string{}, true},
+	// host is not an url
+	{map[string]string{"host": "clickhouse:9000", "query": "SELECT count() FROM buffer", "targetQueryValue": "1000"}, map[string]string{}, true},
+	// missing query
+	{map[string]string{"host": "http://clickhouse:8123", "targetQueryValue": "1000"}, map[string]string{}, true},
+	// missing targetQueryValue
+	{map[string]string{"host": "http://clickhouse:8123", "query": "SELECT count() FROM buffer"}, map[string]string{}, true},
+	// malformed targetQueryValue
+	{map[string]string{"host": "http://clickhouse:8123", "query": "SELECT count() FROM buffer", "targetQueryValue": "a"}, map[string]string{}, true},
+	// malformed activationTargetQueryValue
+	{map[string]string{"host": "http://clickhouse:8123", "query": "SELECT count() FROM buffer", "targetQueryValue": "1000", "activationTargetQueryValue": "a"}, map[string]string{}, true},
+}
+
+var clickHouseMetricIdentifiers = []clickHouseMetricIdentifier{
+	{&testClickHouseMetadata[1], 0, "s0-clickhouse-default"},
+	{&testClickHouseMetadata[2], 1, "s1-clickhouse-buffer"},
+}
+
+func TestClickHouseParseMetadata(t *testing.T) {
+	for _, testData := range testClickHouseMetadata {
+		_, err := parseClickHouseMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Error("Expected success but got error", err)
+		}
+		if testData.isError && err == nil {
+			t.Error("Expected error but got success")
+		}
+	}
+}
+
+func TestClickHouseGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range clickHouseMetricIdentifiers {
+		meta, err := parseClickHouseMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, AuthParams: testData.metadataTestData.authParams, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockClickHouseScaler := clickHouseScaler{
+			metadata:   meta,
+			httpClient: http.DefaultClient,
+		}
+
+		metricSpec := mockClickHouseScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Error("Wrong External metric source name:", metricName)
+		}
+	}
+}
+
+func TestClickHouseGetQueryResult(t *testing.T) {
+	var testData = []struct {
+		name           string
+		bodyStr        string
+		responseStatus int
+		expectedValue  float64
+		isError        bool
+	}{
+		{"single value", "1234\n", http.StatusOK, 1234, false},
+		{"float value", "12.5\n", http.StatusOK, 12.5, false},
+		{"no rows", "", http.StatusOK, 0, false},
+		{"null value", "\\N\n", http.StatusOK, 0, false},
+		{"multiple columns", "1\t2\n", http.StatusOK, -1, true},
+		{"multiple rows", "1\n2\n", http.StatusOK, -1, true},
+		{"malformed value", "a\n", http.StatusOK, -1, true},
+		{"error status response", "Code: 60. DB::Exception: Table ingest.buffer doesn't exist.", http.StatusNotFound, -1, true},
+	}
+
+	for _, test := range testData {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				assert.Equal(t, "GET", request.Method)
+				assert.Equal(t, "/", request.URL.Path)
+				assert.Equal(t, "SELECT count() FROM buffer", request.URL.Query().Get("query"))
+				assert.Equal(t, "ingest", request.URL.Query().Get("database"))
+				assert.Equal(t, "TabSeparated", request.URL.Query().Get("default_format"))
+				assert.Equal(t, "keda", request.Header.Get("X-ClickHouse-User"))
+				assert.Equal(t, "secret", request.Header.Get("X-ClickHouse-Key"))
+				writer.WriteHeader(test.responseStatus)
+
+				if _, err := writer.Write([]byte(test.bodyStr)); err != nil {
+					t.Fatal(err)
+				}
+			}))
+			defer server.Close()
+
+			meta, err := parseClickHouseMetadata(&ScalerConfig{
+				TriggerMetadata: map[string]string{"host": server.URL, "database": "ingest", "query": "SELECT count() FROM buffer", "targetQueryValue": "1000"},
+				AuthParams:      map[string]string{"username": "keda", "password": "secret"},
+			})
+			if err != nil {
+				t.Fatal("Could not parse metadata:", err)
+			}
+			scaler := clickHouseScaler{metadata: meta, httpClient: http.DefaultClient}
+
+			value, err := scaler.getQueryResult(context.TODO())
+
+			assert.Equal(t, test.expectedValue, value)
+			if test.isError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,324 @@
+//go:build !selective_scalers || scalers_database
+// +build !selective_scalers scalers_database
+
+package scalers
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/lib/pq"
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	defaultCockroachDBPort     = "26257"
+	defaultCockroachDBDatabase = "defaultdb"
+	defaultCockroachDBSSLMode  = "verify-full"
+	cockroachDBApplicationName = "keda"
+)
+
+type cockroachDBScaler struct {
+	metadata   *cockroachDBMetadata
+	connection *sql.DB
+}
+
+type cockroachDBMetadata struct {
+	// connectionString is a postgresql:// URL or a key=value connection string, the other connection
+	// parameters and the certificates are added to it
+	connectionString string
+	host             string
+	port             string
+	database         string
+	username         string
+	password         string
+	sslmode          string
+	// ca, cert and key are the PEM encoded CA certificate of the cluster and the client certificate and key
+	// of the user, they are passed inline to the driver so no certificate files are needed
+	ca   string
+	cert string
+	key  string
+	// cluster is the routing ID of a CockroachDB Serverless cluster, eg. my-cluster-1234, passed with --cluster
+	cluster                    string
+	query                      string
+	targetQueryValue           float64
+	activationTargetQueryValue float64
+	metricName                 string
+	scalerIndex                int
+}
+
+var cockroachDBLog = logf.Log.WithName("cockroachdb_scaler")
+
+// NewCockroachDBScaler creates a new cockroachDBScaler
+func NewCockroachDBScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseCockroachDBMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing cockroachdb metadata: %s", err)
+	}
+
+	conn, err := newCockroachDBConnection(meta)
+	if err != nil {
+		return nil, fmt.Errorf("error establishing cockroachdb connection: %s", err)
+	}
+
+	return &cockroachDBScaler{
+		metadata:   meta,
+		connection: conn,
+	}, nil
+}
+
+func parseCockroachDBMetadata(config *ScalerConfig) (*cockroachDBMetadata, error) {
+	meta := cockroachDBMetadata{
+		port:     defaultCockroachDBPort,
+		database: defaultCockroachDBDatabase,
+	}
+
+	switch {
+	case config.AuthParams["connectionString"] != "":
+		meta.connectionString = config.AuthParams["connectionString"]
+	case config.TriggerMetadata["connectionStringFromEnv"] != "":
+		meta.connectionString = config.ResolvedEnv[config.TriggerMetadata["connectionStringFromEnv"]]
+		if meta.connectionString == "" {
+			return nil, fmt.Errorf("no connectionString found in env %s", config.TriggerMetadata["connectionStringFromEnv"])
+		}
+	default:
+		host, err := GetFromAuthOrMeta(config, "host")
+		if err != nil {
+			return nil, err
+		}
+		meta.host = host
+
+		if val, err := GetFromAuthOrMeta(config, "port"); err == nil {
+			if _, err := strconv.ParseUint(val, 10, 16); err != nil {
+				return nil, fmt.Errorf("error parsing port: %s", err)
+			}
+			meta.port = val
+		}
+
+		if val, err := GetFromAuthOrMeta(config, "database"); err == nil {
+			meta.database = val
+		}
+
+		username, err := GetFromAuthOrMeta(config, "username")
+		if err != nil {
+			return nil, err
+		}
+		meta.username = username
+	}
+
+	if val, ok := config.AuthParams["password"]; ok && val != "" {
+		meta.password = val
+	} else if val, ok := config.TriggerMetadata["passwordFromEnv"]; ok && val != "" {
+		meta.password = config.ResolvedEnv[val]
+	}
+
+	meta.ca = config.AuthParams["ca"]
+	meta.cert = config.AuthParams["cert"]
+	meta.key = config.AuthParams["key"]
+	if (meta.cert == "") != (meta.key == "") {
+		return nil, fmt.Errorf("cert and key must be given together")
+	}
+
+	if val, ok := config.TriggerMetadata["sslmode"]; ok && val != "" {
+		switch val {
+		case "disable", "require", "verify-ca", "verify-full":
+		default:
+			return nil, fmt.Errorf("sslmode must be disable, require, verify-ca or verify-full, got %s", val)
+		}
+		meta.sslmode = val
+	} else if meta.connectionString == "" {
+		meta.sslmode = defaultCockroachDBSSLMode
+	}
+	if meta.sslmode == "disable" && (meta.ca != "" || meta.cert != "") {
+		return nil, fmt.Errorf("certificates can't be given with sslmode disable")
+	}
+	if meta.connectionString == "" && meta.sslmode != "disable" && meta.password == "" && meta.cert == "" {
+		return nil, fmt.Errorf("no password or client cert given")
+	}
+
+	meta.cluster = config.TriggerMetadata["cluster"]
+
+	if val, ok := config.TriggerMetadata["query"]; ok && val != "" {
+		meta.query = val
+	} else {
+		return nil, fmt.Errorf("no query given")
+	}
+
+	if val, ok := config.TriggerMetadata["targetQueryValue"]; ok && val != "" {
+		targetQueryValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing targetQueryValue: %s", err)
+		}
+		meta.targetQueryValue = targetQueryValue
+	} else {
+		return nil, fmt.Errorf("no targetQueryValue given")
+	}
+
+	if val, ok := config.TriggerMetadata["activationTargetQueryValue"]; ok && val != "" {
+		activationTargetQueryValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing activationTargetQueryValue: %s", err)
+		}
+		meta.activationTargetQueryValue = activationTargetQueryValue
+	}
+
+	metricName := "query"
+	if val, ok := config.TriggerMetadata["metricName"]; ok && val != "" {
+		metricName = val
+	}
+	meta.metricName = kedautil.NormalizeString(fmt.Sprintf("cockroachdb-%s", metricName))
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+// cockroachDBConnectionString returns the key=value connection string of the driver, the parameters given
+// besides the connectionString override its parameters
+func cockroachDBConnectionString(meta *cockroachDBMetadata) (string, error) {
+	var params []string
+	if meta.connectionString != "" {
+		connStr := meta.connectionString
+		if strings.HasPrefix(connStr, "postgres://") || strings.HasPrefix(connStr, "postgresql://") {
+			var err error
+			connStr, err = pq.ParseURL(connStr)
+			if err != nil {
+				return "", fmt.Errorf("error parsing connectionString: %s", err)
+			}
+		}
+		params = append(params, connStr)
+	} else {
+		params = append(params,
+			cockroachDBParam("host", meta.host),
+			cockroachDBParam("port", meta.port),
+			cockroachDBParam("dbname", meta.database),
+			cockroachDBParam("user", meta.username),
+		)
+	}
+
+	if meta.password != "" {
+		params = append(params, cockroachDBParam("password", meta.password))
+	}
+	if meta.sslmode != "" {
+		params = append(params, cockroachDBParam("sslmode", meta.sslmode))
+	}
+	if meta.ca != "" || meta.cert != "" {
+		params = append(params, cockroachDBParam("sslinline", "true"))
+		if meta.ca != "" {
+			params = append(params, cockroachDBParam("sslrootcert", meta.ca))
+		}
+		if meta.cert != "" {
+			params = append(params, cockroachDBParam("sslcert", meta.cert), cockroachDBParam("sslkey", meta.key))
+		}
+	}
+	if meta.cluster != "" {
+		params = append(params, cockroachDBParam("options", "--cluster="+meta.cluster))
+	}
+	params = append(params, cockroachDBParam("fallback_application_name", cockroachDBApplicationName))
+
+	return strings.Join(params, " "), nil
+}
+
+// cockroachDBParam quotes the value of a connection string parameter, so PEM certificates can be passed inline
+func cockroachDBParam(key, value string) string {
+	value = strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value)
+	return fmt.Sprintf("%s='%s'", key, value)
+}
+
+// newCockroachDBConnection creates the CockroachDB db connection
+func newCockroachDBConnection(meta *cockroachDBMetadata) (*sql.DB, error) {
+	connStr, err := cockroachDBConnectionString(meta)
+	if err != nil {
+		return nil, err
+	}
+	connector, err := pq.NewConnector(connStr)
+	if err != nil {
+		return nil, err
+	}
+	db := sql.OpenDB(connector)
+	if err := db.Ping(); err != nil {
+		cockroachDBLog.Error(err, fmt.Sprintf("Found error pinging cockroachdb: %s", err))
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// Close disposes of the CockroachDB connections
+func (s *cockroachDBScaler) Close(context.Context) error {
+	if err := s.connection.Close(); err != nil {
+		cockroachDBLog.Error(err, "Error closing cockroachdb connection")
+		return err
+	}
+	return nil
+}
+
+// IsActive returns true if the result of the query is above the activation target
+func (s *cockroachDBScaler) IsActive(ctx context.Context) (bool, error) {
+	val, err := s.getQueryResult(ctx)
+	if err != nil {
+		cockroachDBLog.Error(err, "error querying cockroachdb")
+		return false, err
+	}
+
+	return val > s.metadata.activationTargetQueryValue, nil
+}
+
+// getQueryResult returns the first column of the first row of the query, a query without rows or returning NULL is 0
+func (s *cockroachDBScaler) getQueryResult(ctx context.Context) (float64, error) {
+	var value sql.NullFloat64
+	err := s.connection.QueryRowContext(ctx, s.metadata.query).Scan(&value)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return 0, nil
+	case err != nil:
+		return -1, fmt.Errorf("could not query cockroachdb: %s", err)
+	case !value.Valid:
+		return 0, nil
+	}
+	return value.Float64, nil
+}
+
+func (s *cockroachDBScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetMetricValue := resource.NewMilliQuantity(int64(s.metadata.targetQueryValue*1000), resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, s.metadata.metricName),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetMetricValue,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+func (s *cockroachDBScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	val, err := s.getQueryResult(ctx)
+	if err != nil {
+		cockroachDBLog.Error(err, "error querying cockroachdb")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewMilliQuantity(int64(val*1000), resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
@@ -0,0 +1,323 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	consulModeKV      = "kv"
+	consulModeService = "service"
+
+	consulIndexHeader = "X-Consul-Index"
+	consulTokenHeader = "X-Consul-Token"
+
+	// consulMaxStaleResponses is the number of responses with a lower index than the last one ignored in a row
+	consulMaxStaleResponses = 3
+)
+
+type consulScaler struct {
+	metadata   *consulMetadata
+	httpClient *http.Client
+
+	// lastIndex and lastValue hold the most recent response seen from Consul, so that a
+	// stale read served by a lagging server never moves the metric backwards. staleResponses
+	// counts the stale responses ignored in a row
+	lock           sync.Mutex
+	lastIndex      uint64
+	lastValue      float64
+	staleResponses int
+}
+
+type consulMetadata struct {
+	address         string
+	mode            string
+	kvKey           string
+	service         string
+	onlyPassing     bool
+	datacenter      string
+	namespace       string
+	consistencyMode string
+	targetValue     int
+	metricName      string
+
+	// auth
+	token string
+	ca    string
+	cert  string
+	key   string
+
+	scalerIndex int
+}
+
+var consulLog = logf.Log.WithName("consul_scaler")
+
+// NewConsulScaler creates a new consulScaler
+func NewConsulScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseConsulMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing consul metadata: %s", err)
+	}
+
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &consulScaler{
+		metadata:   meta,
+		httpClient: httpClient,
+	}, nil
+}
+
+func parseConsulMetadata(config *ScalerConfig) (*consulMetadata, error) {
+	meta := consulMetadata{
+		mode:        consulModeKV,
+		onlyPassing: true,
+	}
+
+	if val, ok := config.TriggerMetadata["address"]; ok && val != "" {
+		meta.address = strings.TrimSuffix(val, "/")
+	} else {
+		return nil, fmt.Errorf("no address given")
+	}
+
+	if val, ok := config.TriggerMetadata["mode"]; ok && val != "" {
+		meta.mode = strings.ToLower(val)
+	}
+
+	switch meta.mode {
+	case consulModeKV:
+		if val, ok := config.TriggerMetadata["key"]; ok && val != "" {
+			meta.kvKey = strings.TrimPrefix(val, "/")
+		} else {
+			return nil, fmt.Errorf("no key given")
+		}
+	case consulModeService:
+		if val, ok := config.TriggerMetadata["service"]; ok && val != "" {
+			meta.service = val
+		} else {
+			return nil, fmt.Errorf("no service given")
+		}
+
+		if val, ok := config.TriggerMetadata["onlyPassing"]; ok && val != "" {
+			onlyPassing, err := strconv.ParseBool(val)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing onlyPassing: %s", err)
+			}
+			meta.onlyPassing = onlyPassing
+		}
+	default:
+		return nil, fmt.Errorf("mode must be either '%s' or '%s'", consulModeKV, consulModeService)
+	}
+
+	if val, ok := config.TriggerMetadata["targetValue"]; ok && val != "" {
+		targetValue, err := strconv.Atoi(val)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing targetValue: %s", err)
+		}
+		meta.targetValue = targetValue
+	} else {
+		return nil, fmt.Errorf("no targetValue given")
+	}
+
+	if val, ok := config.TriggerMetadata["consistencyMode"]; ok && val != "" {
+		switch val {
+		case "default", "consistent", "stale":
+			meta.consistencyMode = val
+		default:
+			return nil, fmt.Errorf("consistencyMode must be one of 'default', 'consistent' or 'stale'")
+		}
+	}
+
+	meta.datacenter = config.TriggerMetadata["datacenter"]
+	meta.namespace = config.TriggerMetadata["namespace"]
+
+	if val, ok := config.TriggerMetadata["metricName"]; ok && val != "" {
+		meta.metricName = kedautil.NormalizeString(fmt.Sprintf("consul-%s", val))
+	} else if meta.mode == consulModeKV {
+		meta.metricName = kedautil.NormalizeString(fmt.Sprintf("consul-kv-%s", meta.kvKey))
+	} else {
+		meta.metricName = kedautil.NormalizeString(fmt.Sprintf("consul-service-%s", meta.service))
+	}
+
+	meta.token = config.AuthParams["token"]
+	meta.ca = config.AuthParams["ca"]
+	meta.cert = config.AuthParams["cert"]
+	meta.key = config.AuthParams["key"]
+	if (meta.cert == "") != (meta.key == "") {
+		return nil, fmt.Errorf("both cert and key must be provided for TLS client authentication")
+	}
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+func (s *consulScaler) IsActive(ctx context.Context) (bool, error) {
+	val, err := s.getValue(ctx)
+	if err != nil {
+		consulLog.Error(err, "error getting consul value")
+		return false, err
+	}
+
+	return val > 0, nil
+}
+
+func (s *consulScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *consulScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetMetricValue := resource.NewQuantity(int64(s.metadata.targetValue), resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, s.metadata.metricName),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetMetricValue,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+func (s *consulScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	val, err := s.getValue(ctx)
+	if err != nil {
+		consulLog.Error(err, "error getting consul value")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewQuantity(int64(val), resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
+
+func (s *consulScaler) getValue(ctx context.Context) (float64, error) {
+	var path string
+	query := url.Values{}
+	if s.metadata.mode == consulModeKV {
+		path = fmt.Sprintf("/v1/kv/%s", escapeConsulKey(s.metadata.kvKey))
+		query.Set("raw", "")
+	} else {
+		path = fmt.Sprintf("/v1/health/service/%s", url.PathEscape(s.metadata.service))
+		if s.metadata.onlyPassing {
+			query.Set("passing", "")
+		}
+	}
+	if s.metadata.datacenter != "" {
+		query.Set("dc", s.metadata.datacenter)
+	}
+	if s.metadata.namespace != "" {
+		query.Set("ns", s.metadata.namespace)
+	}
+	if s.metadata.consistencyMode != "" && s.metadata.consistencyMode != "default" {
+		query.Set(s.metadata.consistencyMode, "")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s%s?%s", s.metadata.address, path, query.Encode()), nil)
+	if err != nil {
+		return -1, err
+	}
+	if s.metadata.token != "" {
+		req.Header.Set(consulTokenHeader, s.metadata.token)
+	}
+
+	r, err := s.httpClient.Do(req)
+	if err != nil {
+		return -1, err
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode == http.StatusNotFound && s.metadata.mode == consulModeKV {
+		return -1, fmt.Errorf("consul key %s not found", s.metadata.kvKey)
+	}
+	if r.StatusCode != http.StatusOK {
+		return -1, fmt.Errorf("consul api returned %d", r.StatusCode)
+	}
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return -1, err
+	}
+
+	var value float64
+	if s.metadata.mode == consulModeKV {
+		value, err = strconv.ParseFloat(strings.TrimSpace(string(b)), 64)
+		if err != nil {
+			return -1, fmt.Errorf("consul key %s does not contain a number: %s", s.metadata.kvKey, err)
+		}
+	} else {
+		var instances []json.RawMessage
+		if err := json.Unmarshal(b, &instances); err != nil {
+			return -1, err
+		}
+		value = float64(len(instances))
+	}
+
+	return s.applyIndex(r.Header.Get(consulIndexHeader), value)
+}
+
+// escapeConsulKey escapes every segment of the key, keeping the / between them
+func escapeConsulKey(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+	return strings.Join(segments, "/")
+}
+
+// applyIndex implements Consul's versioned read semantics: responses carry the raft index
+// at which they were produced, so a response older than the last one seen (a stale read from
+// a lagging follower) is discarded in favour of the newer value already observed. The index also
+// goes backwards after a snapshot restore or a rebuild of the cluster, so after consulMaxStaleResponses
+// stale responses in a row the index is reset and the value accepted.
+func (s *consulScaler) applyIndex(header string, value float64) (float64, error) {
+	if header == "" {
+		return value, nil
+	}
+	index, err := strconv.ParseUint(header, 10, 64)
+	if err != nil {
+		return -1, errors.New("consul returned an invalid " + consulIndexHeader + " header")
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if index < s.lastIndex {
+		if s.staleResponses < consulMaxStaleResponses {
+			s.staleResponses++
+			consulLog.V(1).Info("ignoring stale consul response", "index", index, "lastIndex", s.lastIndex)
+			return s.lastValue, nil
+		}
+		consulLog.Info("consul index went backwards, resetting it", "index", index, "lastIndex", s.lastIndex)
+	}
+	s.lastIndex = index
+	s.lastValue = value
+	s.staleResponses = 0
+	return value, nil
+}
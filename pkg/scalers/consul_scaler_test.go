@@ -0,0 +1,191 @@
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type parseConsulMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+}
+
+type consulMetricIdentifier struct {
+	metadataTestData *parseConsulMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testConsulMetadata = []parseConsulMetadataTestData{
+	// nothing passed
+	{map[string]string{}, map[string]string{}, true},
+	// properly formed kv
+	{map[string]string{"address": "http://consul:8500", "key": "app/desired-replicas", "targetValue": "1"}, map[string]string{}, false},
+	// properly formed service
+	{map[string]string{"address": "http://consul:8500", "mode": "service", "service": "legacy-worker", "targetValue": "2", "onlyPassing": "false"}, map[string]string{}, false},
+	// missing address
+	{map[string]string{"key": "app/desired-replicas", "targetValue": "1"}, map[string]string{}, true},
+	// missing key
+	{map[string]string{"address": "http://consul:8500", "targetValue": "1"}, map[string]string{}, true},
+	// missing service
+	{map[string]string{"address": "http://consul:8500", "mode": "service", "targetValue": "1"}, map[string]string{}, true},
+	// unknown mode
+	{map[string]string{"address": "http://consul:8500", "mode": "catalog", "key": "app/desired-replicas", "targetValue": "1"}, map[string]string{}, true},
+	// missing targetValue
+	{map[string]string{"address": "http://consul:8500", "key": "app/desired-replicas"}, map[string]string{}, true},
+	// malformed targetValue
+	{map[string]string{"address": "http://consul:8500", "key": "app/desired-replicas", "targetValue": "one"}, map[string]string{}, true},
+	// malformed onlyPassing
+	{map[string]string{"address": "http://consul:8500", "mode": "service", "service": "legacy-worker", "targetValue": "1", "onlyPassing": "maybe"}, map[string]string{}, true},
+	// invalid consistencyMode
+	{map[string]string{"address": "http://consul:8500", "key": "app/desired-replicas", "targetValue": "1", "consistencyMode": "eventual"}, map[string]string{}, true},
+	// token and TLS from TriggerAuthentication
+	{map[string]string{"address": "https://consul:8501", "key": "app/desired-replicas", "targetValue": "1"}, map[string]string{"token": "secret", "ca": "caaa", "cert": "ceert", "key": "keey"}, false},
+	// cert without key
+	{map[string]string{"address": "https://consul:8501", "key": "app/desired-replicas", "targetValue": "1"}, map[string]string{"cert": "ceert"}, true},
+}
+
+var consulMetricIdentifiers = []consulMetricIdentifier{
+	{&testConsulMetadata[1], 0, "s0-consul-kv-app-desired-replicas"},
+	{&testConsulMetadata[2], 1, "s1-consul-service-legacy-worker"},
+}
+
+func TestConsulParseMetadata(t *testing.T) {
+	for idx, testData := range testConsulMetadata {
+		_, err := parseConsulMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Errorf("Test %d: expected success but got error: %s", idx, err)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Test %d: expected error but got success", idx)
+		}
+	}
+}
+
+func TestConsulGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range consulMetricIdentifiers {
+		meta, err := parseConsulMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, AuthParams: testData.metadataTestData.authParams, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockConsulScaler := consulScaler{metadata: meta}
+
+		metricSpec := mockConsulScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Errorf("Wrong External metric source name: %s, expected: %s", metricName, testData.name)
+		}
+	}
+}
+
+func TestConsulGetValue(t *testing.T) {
+	index := "42"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get(consulTokenHeader) != "secret" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set(consulIndexHeader, index)
+		switch r.URL.EscapedPath() {
+		case "/v1/kv/app/desired-replicas":
+			fmt.Fprint(w, "7")
+		case "/v1/kv/app/replicas%3Fv=1%2350%25":
+			fmt.Fprint(w, "4")
+		case "/v1/health/service/legacy-worker":
+			if _, ok := r.URL.Query()["passing"]; !ok {
+				t.Error("Expected passing filter in the query")
+			}
+			fmt.Fprint(w, `[{"Service":{"ID":"a"}},{"Service":{"ID":"b"}},{"Service":{"ID":"c"}}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	kvScaler, err := NewConsulScaler(&ScalerConfig{
+		TriggerMetadata: map[string]string{"address": server.URL, "key": "app/desired-replicas", "targetValue": "1"},
+		AuthParams:      map[string]string{"token": "secret"},
+	})
+	if err != nil {
+		t.Fatal("Could not create scaler:", err)
+	}
+	value, err := kvScaler.(*consulScaler).getValue(context.Background())
+	if err != nil {
+		t.Fatal("Could not get kv value:", err)
+	}
+	if value != 7 {
+		t.Errorf("Expected kv value 7, got %f", value)
+	}
+
+	// a response with an older index must not overwrite the newer value
+	index = "41"
+	kvScaler.(*consulScaler).lastValue = 9
+	value, err = kvScaler.(*consulScaler).getValue(context.Background())
+	if err != nil {
+		t.Fatal("Could not get kv value:", err)
+	}
+	if value != 9 {
+		t.Errorf("Expected stale response to be ignored and value 9 returned, got %f", value)
+	}
+
+	// an index that stays lower than the last one, eg. after a snapshot restore, is reset,
+	// the response at index 41 was the first stale one
+	index = "5"
+	for i := 1; i < consulMaxStaleResponses; i++ {
+		if value, _ = kvScaler.(*consulScaler).getValue(context.Background()); value != 9 {
+			t.Errorf("Expected stale response %d to be ignored and value 9 returned, got %f", i, value)
+		}
+	}
+	value, err = kvScaler.(*consulScaler).getValue(context.Background())
+	if err != nil {
+		t.Fatal("Could not get kv value:", err)
+	}
+	if value != 7 || kvScaler.(*consulScaler).lastIndex != 5 {
+		t.Errorf("Expected the index to be reset to 5 and value 7 returned, got %f at index %d", value, kvScaler.(*consulScaler).lastIndex)
+	}
+
+	serviceScaler, err := NewConsulScaler(&ScalerConfig{
+		TriggerMetadata: map[string]string{"address": server.URL, "mode": "service", "service": "legacy-worker", "targetValue": "1"},
+		AuthParams:      map[string]string{"token": "secret"},
+	})
+	if err != nil {
+		t.Fatal("Could not create scaler:", err)
+	}
+	value, err = serviceScaler.(*consulScaler).getValue(context.Background())
+	if err != nil {
+		t.Fatal("Could not get service instance count:", err)
+	}
+	if value != 3 {
+		t.Errorf("Expected 3 service instances, got %f", value)
+	}
+
+	escapedScaler, err := NewConsulScaler(&ScalerConfig{
+		TriggerMetadata: map[string]string{"address": server.URL, "key": "app/replicas?v=1#50%", "targetValue": "1"},
+		AuthParams:      map[string]string{"token": "secret"},
+	})
+	if err != nil {
+		t.Fatal("Could not create scaler:", err)
+	}
+	value, err = escapedScaler.(*consulScaler).getValue(context.Background())
+	if err != nil {
+		t.Fatal("Could not get kv value of a key with reserved characters:", err)
+	}
+	if value != 4 {
+		t.Errorf("Expected kv value 4, got %f", value)
+	}
+
+	missingScaler, err := NewConsulScaler(&ScalerConfig{
+		TriggerMetadata: map[string]string{"address": server.URL, "key": "app/missing", "targetValue": "1"},
+		AuthParams:      map[string]string{"token": "secret"},
+	})
+	if err != nil {
+		t.Fatal("Could not create scaler:", err)
+	}
+	if _, err := missingScaler.(*consulScaler).getValue(context.Background()); err == nil {
+		t.Error("Expected error for missing key but got success")
+	}
+}
@@ -0,0 +1,267 @@
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	corednsMetricRequests    = "requests"
+	corednsMetricResponses   = "responses"
+	corednsMetricCacheMisses = "cacheMisses"
+
+	// the counters of the prometheus plugin of CoreDNS 1.7+
+	corednsRequestsMetric    = "coredns_dns_requests_total"
+	corednsResponsesMetric   = "coredns_dns_responses_total"
+	corednsCacheMissesMetric = "coredns_cache_misses_total"
+)
+
+type corednsScaler struct {
+	metadata   *corednsMetadata
+	httpClient *http.Client
+	rate       counterRate
+}
+
+type corednsMetadata struct {
+	// metricsURLs are the metrics endpoints of the CoreDNS instances, their counters are added up
+	metricsURLs []string
+	metric      string
+	server      string
+	zone        string
+	// queryType filters the requests by the type of the query, eg. A or AAAA
+	queryType string
+	// rcode filters the responses by their response code, eg. NXDOMAIN or SERVFAIL
+	rcode                 string
+	targetValue           float64
+	activationTargetValue float64
+	scalerIndex           int
+}
+
+var corednsLog = logf.Log.WithName("coredns_scaler")
+
+// NewCoreDNSScaler creates a new corednsScaler
+func NewCoreDNSScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseCoreDNSMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing coredns metadata: %s", err)
+	}
+
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &corednsScaler{
+		metadata:   meta,
+		httpClient: httpClient,
+	}, nil
+}
+
+func parseCoreDNSMetadata(config *ScalerConfig) (*corednsMetadata, error) {
+	meta := corednsMetadata{
+		metric: corednsMetricRequests,
+	}
+
+	// metricsURL is the endpoint of the prometheus plugin of CoreDNS, eg. http://coredns:9153/metrics, a Service
+	// balances the scrapes over the instances so the endpoints of every instance are given separated by commas
+	if val, ok := config.TriggerMetadata["metricsURL"]; ok && val != "" {
+		for _, metricsURL := range splitAndTrimBySep(val, ",") {
+			if _, err := url.ParseRequestURI(metricsURL); err != nil {
+				return nil, fmt.Errorf("error parsing metricsURL: %s", err)
+			}
+			meta.metricsURLs = append(meta.metricsURLs, metricsURL)
+		}
+	} else {
+		return nil, fmt.Errorf("no metricsURL given")
+	}
+
+	if val, ok := config.TriggerMetadata["metric"]; ok && val != "" {
+		switch val {
+		case corednsMetricRequests, corednsMetricResponses, corednsMetricCacheMisses:
+			meta.metric = val
+		default:
+			return nil, fmt.Errorf("metric must be %s, %s or %s, got %s", corednsMetricRequests, corednsMetricResponses, corednsMetricCacheMisses, val)
+		}
+	}
+
+	meta.server = config.TriggerMetadata["server"]
+	meta.zone = config.TriggerMetadata["zone"]
+	meta.queryType = config.TriggerMetadata["queryType"]
+	if meta.queryType != "" && meta.metric != corednsMetricRequests {
+		return nil, fmt.Errorf("queryType is only supported with metric %s", corednsMetricRequests)
+	}
+	meta.rcode = config.TriggerMetadata["rcode"]
+	if meta.rcode != "" && meta.metric != corednsMetricResponses {
+		return nil, fmt.Errorf("rcode is only supported with metric %s", corednsMetricResponses)
+	}
+	if meta.zone != "" && meta.metric == corednsMetricCacheMisses {
+		return nil, fmt.Errorf("zone isn't supported with metric %s", corednsMetricCacheMisses)
+	}
+
+	// targetValue is the number of queries per second
+	if val, ok := config.TriggerMetadata["targetValue"]; ok && val != "" {
+		targetValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing targetValue: %s", err)
+		}
+		meta.targetValue = targetValue
+	} else {
+		return nil, fmt.Errorf("no targetValue given")
+	}
+
+	if val, ok := config.TriggerMetadata["activationTargetValue"]; ok && val != "" {
+		activationTargetValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing activationTargetValue: %s", err)
+		}
+		meta.activationTargetValue = activationTargetValue
+	}
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+// IsActive returns true if the query rate is above the activation target
+func (s *corednsScaler) IsActive(ctx context.Context) (bool, error) {
+	rate, err := s.getQueryRate(ctx)
+	if err != nil {
+		corednsLog.Error(err, "error getting coredns query rate")
+		return false, err
+	}
+
+	return rate > s.metadata.activationTargetValue, nil
+}
+
+func (s *corednsScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *corednsScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	name := "coredns-" + s.metadata.metric
+	for _, filter := range []string{s.metadata.server, s.metadata.zone, s.metadata.queryType, s.metadata.rcode} {
+		if filter != "" {
+			name = fmt.Sprintf("%s-%s", name, filter)
+		}
+	}
+	targetMetricValue := resource.NewMilliQuantity(int64(s.metadata.targetValue*1000), resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(name)),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetMetricValue,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+func (s *corednsScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	rate, err := s.getQueryRate(ctx)
+	if err != nil {
+		corednsLog.Error(err, "error getting coredns query rate")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewMilliQuantity(int64(rate*1000), resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
+
+// getQueryRate returns the queries per second since the previous sample of the counters, the first sample
+// only records the counters and returns a rate of 0
+func (s *corednsScaler) getQueryRate(ctx context.Context) (float64, error) {
+	var total float64
+	for _, metricsURL := range s.metadata.metricsURLs {
+		count, err := s.getQueryCount(ctx, metricsURL)
+		if err != nil {
+			return -1, err
+		}
+		total += count
+	}
+	return s.rate.update(total, time.Now()), nil
+}
+
+// getQueryCount scrapes a metrics endpoint and adds up the counters of the selected server, zone, type and rcode
+func (s *corednsScaler) getQueryCount(ctx context.Context, metricsURL string) (float64, error) {
+	families, err := scrapeMetricFamilies(ctx, s.httpClient, metricsURL, "")
+	if err != nil {
+		return -1, fmt.Errorf("error scraping coredns metrics: %s", err)
+	}
+
+	var name string
+	switch s.metadata.metric {
+	case corednsMetricResponses:
+		name = corednsResponsesMetric
+	case corednsMetricCacheMisses:
+		name = corednsCacheMissesMetric
+	default:
+		name = corednsRequestsMetric
+	}
+	family, ok := families[name]
+	if !ok {
+		// the counters are only exposed once they were incremented, the cache counters only with the cache plugin
+		if strings.HasPrefix(name, "coredns_dns_") {
+			return 0, nil
+		}
+		return -1, fmt.Errorf("%s not found in coredns metrics %s, is the cache plugin enabled?", name, metricsURL)
+	}
+
+	return s.sumQueries(family), nil
+}
+
+// sumQueries adds up the series of the family matching the filters
+func (s *corednsScaler) sumQueries(family *dto.MetricFamily) float64 {
+	var sum float64
+	for _, metric := range family.GetMetric() {
+		if !s.matches(metric) {
+			continue
+		}
+		if value, ok := metricValue(metric); ok {
+			sum += value
+		}
+	}
+	return sum
+}
+
+func (s *corednsScaler) matches(metric *dto.Metric) bool {
+	metricLabels := make(map[string]string, len(metric.GetLabel()))
+	for _, label := range metric.GetLabel() {
+		metricLabels[label.GetName()] = label.GetValue()
+	}
+	filters := map[string]string{
+		"server": s.metadata.server,
+		"zone":   s.metadata.zone,
+		"type":   s.metadata.queryType,
+		"rcode":  s.metadata.rcode,
+	}
+	for name, value := range filters {
+		if value != "" && metricLabels[name] != value {
+			return false
+		}
+	}
+	return true
+}
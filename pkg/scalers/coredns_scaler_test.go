@@ -0,0 +1,147 @@
+package scalers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type parseCoreDNSMetadataTestData struct {
+	metadata map[string]string
+	isError  bool
+}
+
+type corednsMetricIdentifier struct {
+	metadataTestData *parseCoreDNSMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testCoreDNSMetadata = []parseCoreDNSMetadataTestData{
+	// nothing passed
+	{map[string]string{}, true},
+	// requests of all zones
+	{map[string]string{"metricsURL": "http://coredns:9153/metrics", "targetValue": "100"}, false},
+	// requests of a zone and type on two instances
+	{map[string]string{"metricsURL": "http://10.0.0.1:9153/metrics, http://10.0.0.2:9153/metrics", "zone": "example.org.", "queryType": "AAAA", "targetValue": "2.5", "activationTargetValue": "1"}, false},
+	// failed responses
+	{map[string]string{"metricsURL": "http://coredns:9153/metrics", "metric": "responses", "rcode": "SERVFAIL", "targetValue": "10"}, false},
+	// cache misses of a server
+	{map[string]string{"metricsURL": "http://coredns:9153/metrics", "metric": "cacheMisses", "server": "dns://:53", "targetValue": "10"}, false},
+	// missing metricsURL
+	{map[string]string{"targetValue": "100"}, true},
+	// invalid metricsURL
+	{map[string]string{"metricsURL": "coredns", "targetValue": "100"}, true},
+	// invalid metric
+	{map[string]string{"metricsURL": "http://coredns:9153/metrics", "metric": "latency", "targetValue": "100"}, true},
+	// rcode with requests
+	{map[string]string{"metricsURL": "http://coredns:9153/metrics", "rcode": "NXDOMAIN", "targetValue": "100"}, true},
+	// queryType with responses
+	{map[string]string{"metricsURL": "http://coredns:9153/metrics", "metric": "responses", "queryType": "A", "targetValue": "100"}, true},
+	// zone with cache misses
+	{map[string]string{"metricsURL": "http://coredns:9153/metrics", "metric": "cacheMisses", "zone": "example.org.", "targetValue": "100"}, true},
+	// missing targetValue
+	{map[string]string{"metricsURL": "http://coredns:9153/metrics"}, true},
+	// invalid targetValue
+	{map[string]string{"metricsURL": "http://coredns:9153/metrics", "targetValue": "a"}, true},
+	// invalid activationTargetValue
+	{map[string]string{"metricsURL": "http://coredns:9153/metrics", "targetValue": "100", "activationTargetValue": "a"}, true},
+}
+
+var corednsMetricIdentifiers = []corednsMetricIdentifier{
+	{&testCoreDNSMetadata[1], 0, "s0-coredns-requests"},
+	{&testCoreDNSMetadata[2], 1, "s1-coredns-requests-example-org--AAAA"},
+	{&testCoreDNSMetadata[3], 2, "s2-coredns-responses-SERVFAIL"},
+}
+
+func TestParseCoreDNSMetadata(t *testing.T) {
+	for _, testData := range testCoreDNSMetadata {
+		_, err := parseCoreDNSMetadata(&ScalerConfig{TriggerMetadata: testData.metadata})
+		if err != nil && !testData.isError {
+			t.Error("Expected success but got error", err)
+		}
+		if testData.isError && err == nil {
+			t.Error("Expected error but got success", testData.metadata)
+		}
+	}
+}
+
+func TestCoreDNSGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range corednsMetricIdentifiers {
+		meta, err := parseCoreDNSMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockCoreDNSScaler := corednsScaler{metadata: meta}
+
+		metricSpec := mockCoreDNSScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Error("Wrong External metric source name:", metricName)
+		}
+	}
+}
+
+const testCoreDNSMetrics = `# HELP coredns_dns_requests_total Counter of DNS requests made per zone, protocol and family.
+# TYPE coredns_dns_requests_total counter
+coredns_dns_requests_total{family="1",proto="udp",server="dns://:53",type="A",view="",zone="."} 300
+coredns_dns_requests_total{family="1",proto="udp",server="dns://:53",type="AAAA",view="",zone="."} 100
+coredns_dns_requests_total{family="1",proto="udp",server="dns://:53",type="A",view="",zone="example.org."} 40
+coredns_dns_requests_total{family="1",proto="tcp",server="dns://:5353",type="A",view="",zone="example.org."} 2
+# HELP coredns_dns_responses_total Counter of response status codes.
+# TYPE coredns_dns_responses_total counter
+coredns_dns_responses_total{plugin="loadbalance",rcode="NOERROR",server="dns://:53",view="",zone="."} 380
+coredns_dns_responses_total{plugin="",rcode="NXDOMAIN",server="dns://:53",view="",zone="."} 20
+coredns_dns_responses_total{plugin="",rcode="SERVFAIL",server="dns://:53",view="",zone="example.org."} 5
+`
+
+func TestCoreDNSGetQueryCount(t *testing.T) {
+	var tests = []struct {
+		name     string
+		body     string
+		metadata corednsMetadata
+		expected float64
+		isError  bool
+	}{
+		{"all requests", testCoreDNSMetrics, corednsMetadata{metric: corednsMetricRequests}, 442, false},
+		{"zone", testCoreDNSMetrics, corednsMetadata{metric: corednsMetricRequests, zone: "example.org."}, 42, false},
+		{"server and type", testCoreDNSMetrics, corednsMetadata{metric: corednsMetricRequests, server: "dns://:53", queryType: "A"}, 340, false},
+		{"rcode", testCoreDNSMetrics, corednsMetadata{metric: corednsMetricResponses, rcode: "NXDOMAIN"}, 20, false},
+		{"no requests yet", "# TYPE coredns_build_info gauge\ncoredns_build_info{version=\"1.8.6\"} 1\n", corednsMetadata{metric: corednsMetricRequests}, 0, false},
+		{"cache plugin disabled", testCoreDNSMetrics, corednsMetadata{metric: corednsMetricCacheMisses}, 0, true},
+	}
+
+	for _, test := range tests {
+		body := test.body
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(body))
+		}))
+		meta := test.metadata
+		scaler := corednsScaler{metadata: &meta, httpClient: http.DefaultClient}
+
+		val, err := scaler.getQueryCount(context.Background(), server.URL)
+		server.Close()
+		if test.isError {
+			assert.Error(t, err, test.name)
+			continue
+		}
+		assert.NoError(t, err, test.name)
+		assert.Equal(t, test.expected, val, test.name)
+	}
+}
+
+func TestCoreDNSGetQueryRateOfInstances(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(testCoreDNSMetrics))
+	}))
+	defer server.Close()
+
+	scaler := corednsScaler{metadata: &corednsMetadata{metricsURLs: []string{server.URL, server.URL}, metric: corednsMetricRequests}, httpClient: http.DefaultClient}
+	rate, err := scaler.getQueryRate(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, float64(0), rate)
+	assert.Equal(t, float64(884), scaler.rate.lastTotal)
+}
@@ -0,0 +1,296 @@
+//go:build !selective_scalers || scalers_database
+// +build !selective_scalers scalers_database
+
+package scalers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	url_pkg "net/url"
+	"strconv"
+	"strings"
+
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	couchbaseQueryURL                   = "queryURL"
+	couchbaseUsername                   = "username"
+	couchbasePassword                   = "password"
+	couchbaseQuery                      = "query"
+	couchbaseScanConsistency            = "scanConsistency"
+	couchbaseTargetQueryValue           = "targetQueryValue"
+	couchbaseActivationTargetQueryValue = "activationTargetQueryValue"
+	couchbaseMetricName                 = "metricName"
+
+	defaultCouchbaseScanConsistency = "not_bounded"
+)
+
+type couchbaseScaler struct {
+	metadata   *couchbaseMetadata
+	httpClient *http.Client
+}
+
+type couchbaseMetadata struct {
+	// queryURL is the URL of the Query Service, eg. http://couchbase:8093 or https://couchbase:18093
+	queryURL string
+	// username and password authenticate with basic auth, without them the client certificate of the
+	// TriggerAuthentication authenticates
+	username string
+	password string
+	query    string
+	// scanConsistency is not_bounded or request_plus, request_plus waits for the indexes to catch up with the mutations
+	scanConsistency            string
+	targetQueryValue           float64
+	activationTargetQueryValue float64
+	metricName                 string
+	scalerIndex                int
+}
+
+type couchbaseQueryResponse struct {
+	Status  string            `json:"status"`
+	Results []json.RawMessage `json:"results"`
+	Errors  []struct {
+		Code int    `json:"code"`
+		Msg  string `json:"msg"`
+	} `json:"errors"`
+}
+
+var couchbaseLog = logf.Log.WithName("couchbase_scaler")
+
+// NewCouchbaseScaler creates a new couchbaseScaler
+func NewCouchbaseScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseCouchbaseMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing couchbase metadata: %s", err)
+	}
+
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &couchbaseScaler{
+		metadata:   meta,
+		httpClient: httpClient,
+	}, nil
+}
+
+func parseCouchbaseMetadata(config *ScalerConfig) (*couchbaseMetadata, error) {
+	meta := couchbaseMetadata{
+		scanConsistency: defaultCouchbaseScanConsistency,
+	}
+
+	queryURL, err := GetFromAuthOrMeta(config, couchbaseQueryURL)
+	if err != nil {
+		return nil, err
+	}
+	if u, err := url_pkg.ParseRequestURI(queryURL); err != nil || u.Host == "" {
+		return nil, fmt.Errorf("%s %s must be the url of the Query Service like http://host:8093", couchbaseQueryURL, queryURL)
+	}
+	meta.queryURL = strings.TrimSuffix(queryURL, "/")
+
+	if val, err := GetFromAuthOrMeta(config, couchbaseUsername); err == nil {
+		meta.username = val
+	}
+	if val, ok := config.AuthParams[couchbasePassword]; ok && val != "" {
+		meta.password = val
+	} else if val, ok := config.TriggerMetadata["passwordFromEnv"]; ok && val != "" {
+		meta.password = config.ResolvedEnv[val]
+	}
+	if (meta.username == "") != (meta.password == "") {
+		return nil, fmt.Errorf("%s and %s must be given together", couchbaseUsername, couchbasePassword)
+	}
+	if meta.username == "" && config.AuthParams["cert"] == "" {
+		return nil, fmt.Errorf("no %s and %s or client cert given", couchbaseUsername, couchbasePassword)
+	}
+
+	if val, ok := config.TriggerMetadata[couchbaseQuery]; ok && val != "" {
+		meta.query = val
+	} else {
+		return nil, fmt.Errorf("no %s given", couchbaseQuery)
+	}
+
+	if val, ok := config.TriggerMetadata[couchbaseScanConsistency]; ok && val != "" {
+		if val != "not_bounded" && val != "request_plus" {
+			return nil, fmt.Errorf("%s must be not_bounded or request_plus, got %s", couchbaseScanConsistency, val)
+		}
+		meta.scanConsistency = val
+	}
+
+	if val, ok := config.TriggerMetadata[couchbaseTargetQueryValue]; ok && val != "" {
+		targetQueryValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", couchbaseTargetQueryValue, err)
+		}
+		meta.targetQueryValue = targetQueryValue
+	} else {
+		return nil, fmt.Errorf("no %s given", couchbaseTargetQueryValue)
+	}
+
+	if val, ok := config.TriggerMetadata[couchbaseActivationTargetQueryValue]; ok && val != "" {
+		activationTargetQueryValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", couchbaseActivationTargetQueryValue, err)
+		}
+		meta.activationTargetQueryValue = activationTargetQueryValue
+	}
+
+	metricName := "query"
+	if val, ok := config.TriggerMetadata[couchbaseMetricName]; ok && val != "" {
+		metricName = val
+	}
+	meta.metricName = kedautil.NormalizeString(fmt.Sprintf("couchbase-%s", metricName))
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+// IsActive returns true if the result of the query is above the activation target
+func (s *couchbaseScaler) IsActive(ctx context.Context) (bool, error) {
+	val, err := s.getQueryResult(ctx)
+	if err != nil {
+		couchbaseLog.Error(err, "error querying couchbase")
+		return false, err
+	}
+
+	return val > s.metadata.activationTargetQueryValue, nil
+}
+
+func (s *couchbaseScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *couchbaseScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetMetricValue := resource.NewMilliQuantity(int64(s.metadata.targetQueryValue*1000), resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, s.metadata.metricName),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetMetricValue,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// getQueryResult runs the N1QL query with the Query Service REST API and returns its single value, a query
+// without rows or returning null or missing is 0. The query runs with readonly so it can't modify the documents
+func (s *couchbaseScaler) getQueryResult(ctx context.Context) (float64, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"statement":        s.metadata.query,
+		"readonly":         true,
+		"scan_consistency": s.metadata.scanConsistency,
+	})
+	if err != nil {
+		return -1, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.metadata.queryURL+"/query/service", bytes.NewReader(body))
+	if err != nil {
+		return -1, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.metadata.username != "" {
+		req.SetBasicAuth(s.metadata.username, s.metadata.password)
+	}
+
+	r, err := s.httpClient.Do(req)
+	if err != nil {
+		return -1, err
+	}
+	defer r.Body.Close()
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return -1, err
+	}
+
+	var response couchbaseQueryResponse
+	if err := json.Unmarshal(b, &response); err != nil {
+		return -1, fmt.Errorf("couchbase query service returned error. status: %d response: %s", r.StatusCode, string(b))
+	}
+	if len(response.Errors) > 0 {
+		return -1, fmt.Errorf("couchbase query failed with status %s: %d %s", response.Status, response.Errors[0].Code, response.Errors[0].Msg)
+	}
+	if r.StatusCode != http.StatusOK {
+		return -1, fmt.Errorf("couchbase query service returned error. status: %d response: %s", r.StatusCode, string(b))
+	}
+
+	switch len(response.Results) {
+	case 0:
+		return 0, nil
+	case 1:
+		return couchbaseScalarResult(response.Results[0])
+	default:
+		return -1, fmt.Errorf("couchbase query has to return a single row, got %d", len(response.Results))
+	}
+}
+
+// couchbaseScalarResult returns the value of a row of a SELECT RAW query, or of the only field of a row like {"$1": 5}
+func couchbaseScalarResult(row json.RawMessage) (float64, error) {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(row))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return -1, fmt.Errorf("error decoding couchbase query result: %s", err)
+	}
+
+	if fields, ok := value.(map[string]interface{}); ok {
+		if len(fields) == 0 {
+			// the selected field is missing
+			return 0, nil
+		}
+		if len(fields) > 1 {
+			return -1, fmt.Errorf("couchbase query has to return a single value, got %s", string(row))
+		}
+		for _, v := range fields {
+			value = v
+		}
+	}
+
+	switch v := value.(type) {
+	case nil:
+		return 0, nil
+	case json.Number:
+		f, err := v.Float64()
+		if err != nil {
+			return -1, fmt.Errorf("error parsing couchbase query result: %s", err)
+		}
+		return f, nil
+	default:
+		return -1, fmt.Errorf("couchbase query has to return a number, got %s", string(row))
+	}
+}
+
+func (s *couchbaseScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	val, err := s.getQueryResult(ctx)
+	if err != nil {
+		couchbaseLog.Error(err, "error querying couchbase")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewMilliQuantity(int64(val*1000), resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
@@ -0,0 +1,157 @@
+//go:build !selective_scalers || scalers_database
+// +build !selective_scalers scalers_database
+
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type parseCouchbaseMetadataTestData struct {
+	metadata    map[string]string
+	authParams  map[string]string
+	resolvedEnv map[string]string
+	isError     bool
+}
+
+type couchbaseMetricIdentifier struct {
+	metadataTestData *parseCouchbaseMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testCouchbaseResolvedEnv = map[string]string{
+	"COUCHBASE_PASSWORD": "secret",
+}
+
+var testCouchbaseMetadata = []parseCouchbaseMetadataTestData{
+	// nothing passed
+	{map[string]string{}, map[string]string{}, testCouchbaseResolvedEnv, true},
+	// properly formed
+	{map[string]string{"queryURL": "http://couchbase:8093", "query": "SELECT RAW COUNT(*) FROM work WHERE status = 'pending'", "targetQueryValue": "10"}, map[string]string{"username": "keda", "password": "secret"}, testCouchbaseResolvedEnv, false},
+	// password from env, activationTargetQueryValue, scanConsistency and metricName
+	{map[string]string{"queryURL": "http://couchbase:8093/", "query": "SELECT COUNT(*) FROM work", "targetQueryValue": "2.5", "activationTargetQueryValue": "1", "scanConsistency": "request_plus", "metricName": "pending", "username": "keda", "passwordFromEnv": "COUCHBASE_PASSWORD"}, map[string]string{}, testCouchbaseResolvedEnv, false},
+	// client certificate auth
+	{map[string]string{"query": "SELECT RAW COUNT(*) FROM work", "targetQueryValue": "10"}, map[string]string{"queryURL": "https://couchbase:18093", "ca": "caaa", "cert": "ceert", "key": "keey"}, testCouchbaseResolvedEnv, false},
+	// missing queryURL
+	{map[string]string{"query": "SELECT RAW COUNT(*) FROM work", "targetQueryValue": "10"}, map[string]string{"username": "keda", "password": "secret"}, testCouchbaseResolvedEnv, true},
+	// queryURL is not an url
+	{map[string]string{"queryURL": "couchbase:8093", "query": "SELECT RAW COUNT(*) FROM work", "targetQueryValue": "10"}, map[string]string{"username": "keda", "password": "secret"}, testCouchbaseResolvedEnv, true},
+	// no credentials
+	{map[string]string{"queryURL": "http://couchbase:8093", "query": "SELECT RAW COUNT(*) FROM work", "targetQueryValue": "10"}, map[string]string{}, testCouchbaseResolvedEnv, true},
+	// username without password
+	{map[string]string{"queryURL": "http://couchbase:8093", "query": "SELECT RAW COUNT(*) FROM work", "targetQueryValue": "10"}, map[string]string{"username": "keda"}, testCouchbaseResolvedEnv, true},
+	// missing query
+	{map[string]string{"queryURL": "http://couchbase:8093", "targetQueryValue": "10"}, map[string]string{"username": "keda", "password": "secret"}, testCouchbaseResolvedEnv, true},
+	// invalid scanConsistency
+	{map[string]string{"queryURL": "http://couchbase:8093", "query": "SELECT RAW COUNT(*) FROM work", "targetQueryValue": "10", "scanConsistency": "at_plus"}, map[string]string{"username": "keda", "password": "secret"}, testCouchbaseResolvedEnv, true},
+	// missing targetQueryValue
+	{map[string]string{"queryURL": "http://couchbase:8093", "query": "SELECT RAW COUNT(*) FROM work"}, map[string]string{"username": "keda", "password": "secret"}, testCouchbaseResolvedEnv, true},
+	// malformed targetQueryValue
+	{map[string]string{"queryURL": "http://couchbase:8093", "query": "SELECT RAW COUNT(*) FROM work", "targetQueryValue": "a"}, map[string]string{"username": "keda", "password": "secret"}, testCouchbaseResolvedEnv, true},
+	// malformed activationTargetQueryValue
+	{map[string]string{"queryURL": "http://couchbase:8093", "query": "SELECT RAW COUNT(*) FROM work", "targetQueryValue": "10", "activationTargetQueryValue": "a"}, map[string]string{"username": "keda", "password": "secret"}, testCouchbaseResolvedEnv, true},
+}
+
+var couchbaseMetricIdentifiers = []couchbaseMetricIdentifier{
+	{&testCouchbaseMetadata[1], 0, "s0-couchbase-query"},
+	{&testCouchbaseMetadata[2], 1, "s1-couchbase-pending"},
+}
+
+func TestCouchbaseParseMetadata(t *testing.T) {
+	for _, testData := range testCouchbaseMetadata {
+		_, err := parseCouchbaseMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams, ResolvedEnv: testData.resolvedEnv})
+		if err != nil && !testData.isError {
+			t.Error("Expected success but got error", err)
+		}
+		if testData.isError && err == nil {
+			t.Error("Expected error but got success", testData.metadata)
+		}
+	}
+}
+
+func TestCouchbaseGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range couchbaseMetricIdentifiers {
+		meta, err := parseCouchbaseMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, AuthParams: testData.metadataTestData.authParams, ResolvedEnv: testData.metadataTestData.resolvedEnv, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockCouchbaseScaler := couchbaseScaler{
+			metadata:   meta,
+			httpClient: http.DefaultClient,
+		}
+
+		metricSpec := mockCouchbaseScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Error("Wrong External metric source name:", metricName)
+		}
+	}
+}
+
+func TestCouchbaseGetQueryResult(t *testing.T) {
+	var tests = []struct {
+		name     string
+		status   int
+		response string
+		expected float64
+		isError  bool
+	}{
+		{"raw value", http.StatusOK, `{"status":"success","results":[42]}`, 42, false},
+		{"single field", http.StatusOK, `{"status":"success","results":[{"$1":2.5}]}`, 2.5, false},
+		{"no rows", http.StatusOK, `{"status":"success","results":[]}`, 0, false},
+		{"null", http.StatusOK, `{"status":"success","results":[null]}`, 0, false},
+		{"missing field", http.StatusOK, `{"status":"success","results":[{}]}`, 0, false},
+		{"several rows", http.StatusOK, `{"status":"success","results":[1,2]}`, -1, true},
+		{"several fields", http.StatusOK, `{"status":"success","results":[{"a":1,"b":2}]}`, -1, true},
+		{"string", http.StatusOK, `{"status":"success","results":["pending"]}`, -1, true},
+		{"query error", http.StatusNotFound, `{"status":"fatal","errors":[{"code":12003,"msg":"Keyspace not found in CB datastore: default:work"}]}`, -1, true},
+		{"unauthorized", http.StatusUnauthorized, `Unauthorized`, -1, true},
+	}
+
+	for _, test := range tests {
+		test := test
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/query/service", r.URL.Path)
+			username, password, ok := r.BasicAuth()
+			assert.True(t, ok)
+			assert.Equal(t, "keda", username)
+			assert.Equal(t, "secret", password)
+
+			var request map[string]interface{}
+			assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+			assert.Equal(t, "SELECT RAW COUNT(*) FROM work", request["statement"])
+			assert.Equal(t, true, request["readonly"])
+			assert.Equal(t, "not_bounded", request["scan_consistency"])
+
+			w.WriteHeader(test.status)
+			_, _ = w.Write([]byte(test.response))
+		}))
+
+		scaler := couchbaseScaler{
+			metadata: &couchbaseMetadata{
+				queryURL:        server.URL,
+				username:        "keda",
+				password:        "secret",
+				query:           "SELECT RAW COUNT(*) FROM work",
+				scanConsistency: "not_bounded",
+			},
+			httpClient: http.DefaultClient,
+		}
+
+		val, err := scaler.getQueryResult(context.Background())
+		server.Close()
+		if test.isError {
+			assert.Error(t, err, test.name)
+			continue
+		}
+		assert.NoError(t, err, test.name)
+		assert.Equal(t, test.expected, val, test.name)
+	}
+}
@@ -21,6 +21,27 @@ type cpuMemoryMetadata struct {
 	Type               v2beta2.MetricTargetType
 	AverageValue       *resource.Quantity
 	AverageUtilization *int32
+	// ContainerName restricts the metric to one container of the pods, the whole pods are measured if empty
+	ContainerName string
+	// ActivationAverageValue and ActivationAverageUtilization are the usage above which the trigger is active, it's
+	// always active if neither is set
+	ActivationAverageValue       *resource.Quantity
+	ActivationAverageUtilization *int32
+}
+
+type resourceMetricsContextKey struct{}
+
+// WithResourceMetrics returns a copy of ctx carrying the current metrics of the HPA of the ScaledObject, the cpu
+// and memory scalers with an activationValue compare the usage of the ScaleTarget in them to it
+func WithResourceMetrics(ctx context.Context, metrics []v2beta2.MetricStatus) context.Context {
+	return context.WithValue(ctx, resourceMetricsContextKey{}, metrics)
+}
+
+func resourceMetricsFromContext(ctx context.Context) []v2beta2.MetricStatus {
+	if metrics, ok := ctx.Value(resourceMetricsContextKey{}).([]v2beta2.MetricStatus); ok {
+		return metrics
+	}
+	return nil
 }
 
 // NewCPUMemoryScaler creates a new cpuMemoryScaler
@@ -49,10 +70,21 @@ func parseResourceMetadata(config *ScalerConfig) (*cpuMemoryMetadata, error) {
 	if value, ok = config.TriggerMetadata["value"]; !ok || value == "" {
 		return nil, fmt.Errorf("no value given")
 	}
+	activationValue := config.TriggerMetadata["activationValue"]
 	switch meta.Type {
 	case v2beta2.AverageValueMetricType:
-		averageValueQuantity := resource.MustParse(value)
+		averageValueQuantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing value: %s", err)
+		}
 		meta.AverageValue = &averageValueQuantity
+		if activationValue != "" {
+			activationQuantity, err := resource.ParseQuantity(activationValue)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing activationValue: %s", err)
+			}
+			meta.ActivationAverageValue = &activationQuantity
+		}
 	case v2beta2.UtilizationMetricType:
 		valueNum, err := strconv.ParseInt(value, 10, 32)
 		if err != nil {
@@ -60,15 +92,54 @@ func parseResourceMetadata(config *ScalerConfig) (*cpuMemoryMetadata, error) {
 		}
 		utilizationNum := int32(valueNum)
 		meta.AverageUtilization = &utilizationNum
+		if activationValue != "" {
+			activationNum, err := strconv.ParseInt(activationValue, 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("error parsing activationValue: %s", err)
+			}
+			activationUtilization := int32(activationNum)
+			meta.ActivationAverageUtilization = &activationUtilization
+		}
 	default:
 		return nil, fmt.Errorf("unsupported metric type, allowed values are 'Utilization' or 'AverageValue'")
 	}
+
+	meta.ContainerName = config.TriggerMetadata["containerName"]
 	return meta, nil
 }
 
-// IsActive always return true for cpu/memory scaler
+// IsActive returns true if the usage reported by the HPA in ctx is above the activationValue, it always returns
+// true without activationValue. The usage of pods can only be measured while the ScaleTarget has replicas, so
+// the trigger is not active without replicas, and the cpu/memory triggers without activationValue of a
+// ScaledObject with other triggers are left out of its activation and don't prevent it from scaling to zero
 func (s *cpuMemoryScaler) IsActive(ctx context.Context) (bool, error) {
-	return true, nil
+	if s.metadata.ActivationAverageValue == nil && s.metadata.ActivationAverageUtilization == nil {
+		return true, nil
+	}
+
+	current := s.currentMetric(resourceMetricsFromContext(ctx))
+	if current == nil {
+		return false, nil
+	}
+	if s.metadata.ActivationAverageUtilization != nil {
+		return current.AverageUtilization != nil && *current.AverageUtilization > *s.metadata.ActivationAverageUtilization, nil
+	}
+	return current.AverageValue != nil && current.AverageValue.Cmp(*s.metadata.ActivationAverageValue) > 0, nil
+}
+
+// currentMetric returns the current usage of the resource, or of the container of the trigger, in the HPA metrics
+func (s *cpuMemoryScaler) currentMetric(metrics []v2beta2.MetricStatus) *v2beta2.MetricValueStatus {
+	for _, metric := range metrics {
+		if s.metadata.ContainerName != "" {
+			if metric.ContainerResource != nil && metric.ContainerResource.Name == s.resourceName &&
+				metric.ContainerResource.Container == s.metadata.ContainerName {
+				return &metric.ContainerResource.Current
+			}
+		} else if metric.Resource != nil && metric.Resource.Name == s.resourceName {
+			return &metric.Resource.Current
+		}
+	}
+	return nil
 }
 
 // Close no need for cpuMemory scaler
@@ -78,13 +149,25 @@ func (s *cpuMemoryScaler) Close(context.Context) error {
 
 // GetMetricSpecForScaling returns the metric spec for the HPA
 func (s *cpuMemoryScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	target := v2beta2.MetricTarget{
+		Type:               s.metadata.Type,
+		AverageUtilization: s.metadata.AverageUtilization,
+		AverageValue:       s.metadata.AverageValue,
+	}
+
+	if s.metadata.ContainerName != "" {
+		containerCPUMemoryMetric := &v2beta2.ContainerResourceMetricSource{
+			Name:      s.resourceName,
+			Container: s.metadata.ContainerName,
+			Target:    target,
+		}
+		metricSpec := v2beta2.MetricSpec{ContainerResource: containerCPUMemoryMetric, Type: v2beta2.ContainerResourceMetricSourceType}
+		return []v2beta2.MetricSpec{metricSpec}
+	}
+
 	cpuMemoryMetric := &v2beta2.ResourceMetricSource{
-		Name: s.resourceName,
-		Target: v2beta2.MetricTarget{
-			Type:               s.metadata.Type,
-			AverageUtilization: s.metadata.AverageUtilization,
-			AverageValue:       s.metadata.AverageValue,
-		},
+		Name:   s.resourceName,
+		Target: target,
 	}
 	metricSpec := v2beta2.MetricSpec{Resource: cpuMemoryMetric, Type: v2beta2.ResourceMetricSourceType}
 	return []v2beta2.MetricSpec{metricSpec}
@@ -7,6 +7,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"k8s.io/api/autoscaling/v2beta2"
 	v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
 )
 
 type parseCPUMemoryMetadataTestData struct {
@@ -28,6 +29,12 @@ var testCPUMemoryMetadata = []parseCPUMemoryMetadataTestData{
 	{map[string]string{"type": "Value", "value": "50"}, true},
 	{map[string]string{"type": "AverageValue"}, true},
 	{map[string]string{"type": "xxx", "value": "50"}, true},
+	{map[string]string{"type": "Utilization", "value": "50", "containerName": "app"}, false},
+	{map[string]string{"type": "Utilization", "value": "50", "activationValue": "20"}, false},
+	{map[string]string{"type": "Utilization", "value": "50", "activationValue": "20m"}, true},
+	{map[string]string{"type": "AverageValue", "value": "500m", "activationValue": "100m"}, false},
+	{map[string]string{"type": "AverageValue", "value": "500m", "activationValue": "xxx"}, true},
+	{map[string]string{"type": "AverageValue", "value": "xxx"}, true},
 }
 
 func TestCPUMemoryParseMetadata(t *testing.T) {
@@ -56,3 +63,51 @@ func TestGetMetricSpecForScaling(t *testing.T) {
 	assert.Equal(t, metricSpec[0].Resource.Name, v1.ResourceCPU)
 	assert.Equal(t, metricSpec[0].Resource.Target.Type, v2beta2.UtilizationMetricType)
 }
+
+func TestGetContainerMetricSpecForScaling(t *testing.T) {
+	config := &ScalerConfig{
+		TriggerMetadata: map[string]string{"type": "AverageValue", "value": "512Mi", "containerName": "app"},
+	}
+	scaler, _ := NewCPUMemoryScaler(v1.ResourceMemory, config)
+	metricSpec := scaler.GetMetricSpecForScaling(context.Background())
+
+	assert.Equal(t, metricSpec[0].Type, v2beta2.ContainerResourceMetricSourceType)
+	assert.Nil(t, metricSpec[0].Resource)
+	assert.Equal(t, metricSpec[0].ContainerResource.Name, v1.ResourceMemory)
+	assert.Equal(t, metricSpec[0].ContainerResource.Container, "app")
+	assert.Equal(t, metricSpec[0].ContainerResource.Target.Type, v2beta2.AverageValueMetricType)
+}
+
+func TestCPUMemoryIsActive(t *testing.T) {
+	utilization := func(value int32) *int32 { return &value }
+	quantity := func(value string) *resource.Quantity {
+		q := resource.MustParse(value)
+		return &q
+	}
+	hpaMetrics := []v2beta2.MetricStatus{
+		{Type: v2beta2.ResourceMetricSourceType, Resource: &v2beta2.ResourceMetricStatus{Name: v1.ResourceCPU, Current: v2beta2.MetricValueStatus{AverageUtilization: utilization(30)}}},
+		{Type: v2beta2.ContainerResourceMetricSourceType, ContainerResource: &v2beta2.ContainerResourceMetricStatus{Name: v1.ResourceMemory, Container: "app", Current: v2beta2.MetricValueStatus{AverageValue: quantity("256Mi")}}},
+	}
+
+	tests := []struct {
+		name         string
+		resourceName v1.ResourceName
+		metadata     map[string]string
+		metrics      []v2beta2.MetricStatus
+		expected     bool
+	}{
+		{"no activationValue", v1.ResourceCPU, map[string]string{"type": "Utilization", "value": "50"}, nil, true},
+		{"above activationValue", v1.ResourceCPU, map[string]string{"type": "Utilization", "value": "50", "activationValue": "20"}, hpaMetrics, true},
+		{"below activationValue", v1.ResourceCPU, map[string]string{"type": "Utilization", "value": "50", "activationValue": "40"}, hpaMetrics, false},
+		{"no metrics", v1.ResourceCPU, map[string]string{"type": "Utilization", "value": "50", "activationValue": "20"}, nil, false},
+		{"container above activationValue", v1.ResourceMemory, map[string]string{"type": "AverageValue", "value": "512Mi", "activationValue": "128Mi", "containerName": "app"}, hpaMetrics, true},
+		{"other container", v1.ResourceMemory, map[string]string{"type": "AverageValue", "value": "512Mi", "activationValue": "128Mi", "containerName": "sidecar"}, hpaMetrics, false},
+	}
+	for _, test := range tests {
+		scaler, err := NewCPUMemoryScaler(test.resourceName, &ScalerConfig{TriggerMetadata: test.metadata})
+		assert.NoError(t, err, test.name)
+		isActive, err := scaler.IsActive(WithResourceMetrics(context.Background(), test.metrics))
+		assert.NoError(t, err, test.name)
+		assert.Equal(t, test.expected, isActive, test.name)
+	}
+}
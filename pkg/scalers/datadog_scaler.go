@@ -0,0 +1,302 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"net/http"
+	url_pkg "net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	datadogQuery               = "query"
+	datadogQueryValue          = "queryValue"
+	datadogActivationValue     = "activationQueryValue"
+	datadogQueryAggregator     = "queryAggregator"
+	datadogAge                 = "age"
+	datadogSite                = "datadogSite"
+	datadogMetricName          = "metricName"
+	datadogAPIKey              = "apiKey"
+	datadogAppKey              = "appKey"
+	datadogAggregatorAverage   = "average"
+	datadogAggregatorMax       = "max"
+	defaultDatadogSite         = "datadoghq.com"
+	defaultDatadogAge          = 90
+	defaultDatadogMetricPrefix = "datadog"
+)
+
+type datadogScaler struct {
+	metadata   *datadogMetadata
+	httpClient *http.Client
+}
+
+type datadogMetadata struct {
+	query           string
+	queryValue      float64
+	activationValue float64
+	// queryAggregator combines the latest points of the series returned by the query
+	queryAggregator string
+	// age is how far back in seconds the query looks, older points are not accepted
+	age int
+	// apiURL is the API endpoint of the Datadog site
+	apiURL     string
+	apiKey     string
+	appKey     string
+	metricName string
+
+	scalerIndex int
+}
+
+type datadogQueryResult struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Series []struct {
+		Metric    string       `json:"metric"`
+		Pointlist [][]*float64 `json:"pointlist"`
+	} `json:"series"`
+}
+
+var datadogMetricNameReplacer = regexp.MustCompile(`[^a-zA-Z0-9_]+`)
+
+var datadogLog = logf.Log.WithName("datadog_scaler")
+
+// NewDatadogScaler creates a new datadogScaler
+func NewDatadogScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseDatadogMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing datadog metadata: %s", err)
+	}
+
+	httpClient := kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, false)
+
+	return &datadogScaler{
+		metadata:   meta,
+		httpClient: httpClient,
+	}, nil
+}
+
+func parseDatadogMetadata(config *ScalerConfig) (*datadogMetadata, error) {
+	meta := datadogMetadata{
+		queryAggregator: datadogAggregatorAverage,
+		age:             defaultDatadogAge,
+	}
+
+	if val, ok := config.TriggerMetadata[datadogQuery]; ok && val != "" {
+		meta.query = val
+	} else {
+		return nil, fmt.Errorf("no %s given", datadogQuery)
+	}
+
+	if val, ok := config.TriggerMetadata[datadogQueryValue]; ok && val != "" {
+		queryValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", datadogQueryValue, err)
+		}
+		meta.queryValue = queryValue
+	} else {
+		return nil, fmt.Errorf("no %s given", datadogQueryValue)
+	}
+
+	if val, ok := config.TriggerMetadata[datadogActivationValue]; ok && val != "" {
+		activationValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", datadogActivationValue, err)
+		}
+		meta.activationValue = activationValue
+	}
+
+	if val, ok := config.TriggerMetadata[datadogQueryAggregator]; ok && val != "" {
+		if val != datadogAggregatorAverage && val != datadogAggregatorMax {
+			return nil, fmt.Errorf("%s has to be one of %s, %s", datadogQueryAggregator, datadogAggregatorAverage, datadogAggregatorMax)
+		}
+		meta.queryAggregator = val
+	}
+
+	if val, ok := config.TriggerMetadata[datadogAge]; ok && val != "" {
+		age, err := strconv.Atoi(val)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", datadogAge, err)
+		}
+		if age < 1 {
+			return nil, fmt.Errorf("%s has to be at least 1 second", datadogAge)
+		}
+		meta.age = age
+	}
+
+	site := defaultDatadogSite
+	if val, ok := config.AuthParams[datadogSite]; ok && val != "" {
+		site = val
+	} else if val, ok := config.TriggerMetadata[datadogSite]; ok && val != "" {
+		site = val
+	}
+	meta.apiURL = fmt.Sprintf("https://api.%s", site)
+
+	apiKey, ok := config.AuthParams[datadogAPIKey]
+	if !ok || apiKey == "" {
+		return nil, fmt.Errorf("no %s given", datadogAPIKey)
+	}
+	meta.apiKey = apiKey
+
+	appKey, ok := config.AuthParams[datadogAppKey]
+	if !ok || appKey == "" {
+		return nil, fmt.Errorf("no %s given", datadogAppKey)
+	}
+	meta.appKey = appKey
+
+	if val, ok := config.TriggerMetadata[datadogMetricName]; ok && val != "" {
+		meta.metricName = kedautil.NormalizeString(fmt.Sprintf("%s-%s", defaultDatadogMetricPrefix, val))
+	} else {
+		// the query contains characters not allowed in metric names, like braces and colons
+		metricName := strings.Trim(datadogMetricNameReplacer.ReplaceAllString(meta.query, "-"), "-")
+		meta.metricName = kedautil.NormalizeString(fmt.Sprintf("%s-%s", defaultDatadogMetricPrefix, metricName))
+	}
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+// IsActive returns true if the value of the query is above the activation value
+func (s *datadogScaler) IsActive(ctx context.Context) (bool, error) {
+	val, err := s.getQueryResult(ctx)
+	if err != nil {
+		datadogLog.Error(err, "error executing datadog query")
+		return false, err
+	}
+
+	return val > s.metadata.activationValue, nil
+}
+
+func (s *datadogScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *datadogScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetMetricValue := resource.NewMilliQuantity(int64(s.metadata.queryValue*1000), resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, s.metadata.metricName),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetMetricValue,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// getQueryResult runs the query over the last age seconds through the v1 query API and aggregates
+// the latest point of every series returned, a query without points is an error
+func (s *datadogScaler) getQueryResult(ctx context.Context) (float64, error) {
+	now := time.Now()
+	from := now.Add(-time.Duration(s.metadata.age) * time.Second)
+	url := fmt.Sprintf("%s/api/v1/query?from=%d&to=%d&query=%s", s.metadata.apiURL, from.Unix(), now.Unix(), url_pkg.QueryEscape(s.metadata.query))
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return -1, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("DD-API-KEY", s.metadata.apiKey)
+	req.Header.Set("DD-APPLICATION-KEY", s.metadata.appKey)
+
+	r, err := s.httpClient.Do(req)
+	if err != nil {
+		return -1, err
+	}
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return -1, err
+	}
+	r.Body.Close()
+
+	if r.StatusCode == http.StatusTooManyRequests {
+		return -1, fmt.Errorf("datadog query api rate limit reached, reset in %s seconds", r.Header.Get("X-RateLimit-Reset"))
+	}
+	if !(r.StatusCode >= 200 && r.StatusCode <= 299) {
+		return -1, fmt.Errorf("datadog query api returned error. status: %d response: %s", r.StatusCode, string(b))
+	}
+
+	var result datadogQueryResult
+	if err := json.Unmarshal(b, &result); err != nil {
+		return -1, err
+	}
+	if result.Status == "error" {
+		return -1, fmt.Errorf("datadog query %s returned error: %s", s.metadata.query, result.Error)
+	}
+
+	return aggregateDatadogSeries(result, s.metadata.queryAggregator, from)
+}
+
+// aggregateDatadogSeries returns the average or max of the latest non-null point of the series,
+// points before from are not accepted
+func aggregateDatadogSeries(result datadogQueryResult, aggregator string, from time.Time) (float64, error) {
+	var values []float64
+	for _, series := range result.Series {
+		for i := len(series.Pointlist) - 1; i >= 0; i-- {
+			point := series.Pointlist[i]
+			if len(point) < 2 || point[0] == nil || point[1] == nil {
+				continue
+			}
+			// the timestamps of the points are in milliseconds
+			if int64(*point[0]) < from.UnixNano()/int64(time.Millisecond) {
+				break
+			}
+			values = append(values, *point[1])
+			break
+		}
+	}
+
+	if len(values) == 0 {
+		return -1, fmt.Errorf("datadog query returned no points in the accepted age")
+	}
+
+	switch aggregator {
+	case datadogAggregatorMax:
+		max := values[0]
+		for _, v := range values[1:] {
+			max = math.Max(max, v)
+		}
+		return max, nil
+	default:
+		var sum float64
+		for _, v := range values {
+			sum += v
+		}
+		return sum / float64(len(values)), nil
+	}
+}
+
+func (s *datadogScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	val, err := s.getQueryResult(ctx)
+	if err != nil {
+		datadogLog.Error(err, "error executing datadog query")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewMilliQuantity(int64(val*1000), resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
@@ -0,0 +1,157 @@
+package scalers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type parseDatadogMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+}
+
+type datadogMetricIdentifier struct {
+	metadataTestData *parseDatadogMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testDatadogAuthParams = map[string]string{"apiKey": "apiKey", "appKey": "appKey"}
+
+var testDatadogMetadata = []parseDatadogMetadataTestData{
+	// nothing passed
+	{map[string]string{}, map[string]string{}, true},
+	// properly formed
+	{map[string]string{"query": "sum:trace.redis.command.hits{env:none,service:redis}.as_count()", "queryValue": "7"}, testDatadogAuthParams, false},
+	// with metricName, aggregator, age, activation value and site
+	{map[string]string{"query": "avg:nginx.net.request_per_s{*}", "queryValue": "7.5", "activationQueryValue": "1", "queryAggregator": "max", "age": "120", "metricName": "nginx-requests", "datadogSite": "datadoghq.eu"}, testDatadogAuthParams, false},
+	// site from auth params
+	{map[string]string{"query": "avg:nginx.net.request_per_s{*}", "queryValue": "7"}, map[string]string{"apiKey": "apiKey", "appKey": "appKey", "datadogSite": "us5.datadoghq.com"}, false},
+	// missing query
+	{map[string]string{"queryValue": "7"}, testDatadogAuthParams, true},
+	// missing queryValue
+	{map[string]string{"query": "avg:nginx.net.request_per_s{*}"}, testDatadogAuthParams, true},
+	// malformed queryValue
+	{map[string]string{"query": "avg:nginx.net.request_per_s{*}", "queryValue": "a"}, testDatadogAuthParams, true},
+	// malformed activationQueryValue
+	{map[string]string{"query": "avg:nginx.net.request_per_s{*}", "queryValue": "7", "activationQueryValue": "a"}, testDatadogAuthParams, true},
+	// wrong queryAggregator
+	{map[string]string{"query": "avg:nginx.net.request_per_s{*}", "queryValue": "7", "queryAggregator": "sum"}, testDatadogAuthParams, true},
+	// malformed age
+	{map[string]string{"query": "avg:nginx.net.request_per_s{*}", "queryValue": "7", "age": "a"}, testDatadogAuthParams, true},
+	// age below 1
+	{map[string]string{"query": "avg:nginx.net.request_per_s{*}", "queryValue": "7", "age": "0"}, testDatadogAuthParams, true},
+	// missing apiKey
+	{map[string]string{"query": "avg:nginx.net.request_per_s{*}", "queryValue": "7"}, map[string]string{"appKey": "appKey"}, true},
+	// missing appKey
+	{map[string]string{"query": "avg:nginx.net.request_per_s{*}", "queryValue": "7"}, map[string]string{"apiKey": "apiKey"}, true},
+}
+
+var datadogMetricIdentifiers = []datadogMetricIdentifier{
+	{&testDatadogMetadata[1], 0, "s0-datadog-sum-trace-redis-command-hits-env-none-service-redis-as_count"},
+	{&testDatadogMetadata[2], 1, "s1-datadog-nginx-requests"},
+}
+
+func TestDatadogParseMetadata(t *testing.T) {
+	for _, testData := range testDatadogMetadata {
+		_, err := parseDatadogMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Error("Expected success but got error", err)
+		}
+		if testData.isError && err == nil {
+			t.Error("Expected error but got success")
+		}
+	}
+}
+
+func TestDatadogGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range datadogMetricIdentifiers {
+		meta, err := parseDatadogMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, AuthParams: testData.metadataTestData.authParams, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockDatadogScaler := datadogScaler{
+			metadata:   meta,
+			httpClient: http.DefaultClient,
+		}
+
+		metricSpec := mockDatadogScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Error("Wrong External metric source name:", metricName)
+		}
+	}
+}
+
+func TestDatadogScalerGetQueryResult(t *testing.T) {
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	old := now - int64(10*time.Minute/time.Millisecond)
+
+	var testData = []struct {
+		name           string
+		aggregator     string
+		bodyStr        string
+		responseStatus int
+		expectedValue  float64
+		isError        bool
+	}{
+		{"latest point", "average", `{"status":"ok","series":[{"pointlist":[[` + datadogTimestamp(old) + `,1],[` + datadogTimestamp(now-1000) + `,4],[` + datadogTimestamp(now) + `,null]]}]}`, http.StatusOK, 4, false},
+		{"average of series", "average", `{"status":"ok","series":[{"pointlist":[[` + datadogTimestamp(now) + `,4]]},{"pointlist":[[` + datadogTimestamp(now) + `,8]]}]}`, http.StatusOK, 6, false},
+		{"max of series", "max", `{"status":"ok","series":[{"pointlist":[[` + datadogTimestamp(now) + `,4]]},{"pointlist":[[` + datadogTimestamp(now) + `,8]]}]}`, http.StatusOK, 8, false},
+		{"too old point", "average", `{"status":"ok","series":[{"pointlist":[[` + datadogTimestamp(old) + `,4]]}]}`, http.StatusOK, -1, true},
+		{"no series", "average", `{"status":"ok","series":[]}`, http.StatusOK, -1, true},
+		{"query error", "average", `{"status":"error","error":"Error parsing query"}`, http.StatusOK, -1, true},
+		{"rate limited", "average", `{}`, http.StatusTooManyRequests, -1, true},
+		{"forbidden", "average", `{"errors":["Forbidden"]}`, http.StatusForbidden, -1, true},
+	}
+
+	for _, test := range testData {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				assert.Equal(t, "/api/v1/query", request.URL.Path)
+				assert.Equal(t, "avg:nginx.net.request_per_s{*}", request.URL.Query().Get("query"))
+				assert.Equal(t, "apiKey", request.Header.Get("DD-API-KEY"))
+				assert.Equal(t, "appKey", request.Header.Get("DD-APPLICATION-KEY"))
+				writer.WriteHeader(test.responseStatus)
+
+				if _, err := writer.Write([]byte(test.bodyStr)); err != nil {
+					t.Fatal(err)
+				}
+			}))
+			defer server.Close()
+
+			scaler := datadogScaler{
+				metadata: &datadogMetadata{
+					query:           "avg:nginx.net.request_per_s{*}",
+					queryAggregator: test.aggregator,
+					age:             90,
+					apiURL:          server.URL,
+					apiKey:          "apiKey",
+					appKey:          "appKey",
+				},
+				httpClient: http.DefaultClient,
+			}
+
+			value, err := scaler.getQueryResult(context.TODO())
+
+			assert.Equal(t, test.expectedValue, value)
+			if test.isError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func datadogTimestamp(v int64) string {
+	return strconv.FormatInt(v, 10)
+}
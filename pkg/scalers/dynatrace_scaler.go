@@ -0,0 +1,251 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	url_pkg "net/url"
+	"strconv"
+	"strings"
+
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	dynatraceHost                = "host"
+	dynatraceToken               = "token"
+	dynatraceMetricSelector      = "metricSelector"
+	dynatraceEntitySelector      = "entitySelector"
+	dynatraceFrom                = "from"
+	dynatraceThreshold           = "threshold"
+	dynatraceActivationThreshold = "activationThreshold"
+	dynatraceMetricName          = "metricName"
+
+	defaultDynatraceFrom       = "now-2m"
+	defaultDynatraceMetricName = "dynatrace"
+)
+
+type dynatraceScaler struct {
+	metadata   *dynatraceMetadata
+	httpClient *http.Client
+}
+
+type dynatraceMetadata struct {
+	// host is the URL of the environment, eg. https://{environmentid}.live.dynatrace.com or a managed tenant
+	host           string
+	token          string
+	metricSelector string
+	entitySelector string
+	// from is the start of the query timeframe, the latest datapoint in it is used
+	from                string
+	threshold           float64
+	activationThreshold float64
+	metricName          string
+	scalerIndex         int
+}
+
+type dynatraceMetricsQueryResult struct {
+	Result []struct {
+		MetricID string `json:"metricId"`
+		Data     []struct {
+			Dimensions []string   `json:"dimensions"`
+			Timestamps []int64    `json:"timestamps"`
+			Values     []*float64 `json:"values"`
+		} `json:"data"`
+	} `json:"result"`
+}
+
+var dynatraceLog = logf.Log.WithName("dynatrace_scaler")
+
+// NewDynatraceScaler creates a new dynatraceScaler
+func NewDynatraceScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseDynatraceMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing dynatrace metadata: %s", err)
+	}
+
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dynatraceScaler{
+		metadata:   meta,
+		httpClient: httpClient,
+	}, nil
+}
+
+func parseDynatraceMetadata(config *ScalerConfig) (*dynatraceMetadata, error) {
+	meta := dynatraceMetadata{
+		from: defaultDynatraceFrom,
+	}
+
+	host, err := GetFromAuthOrMeta(config, dynatraceHost)
+	if err != nil {
+		return nil, err
+	}
+	meta.host = strings.TrimSuffix(host, "/")
+
+	if val, ok := config.AuthParams[dynatraceToken]; ok && val != "" {
+		meta.token = val
+	} else {
+		return nil, fmt.Errorf("no %s given", dynatraceToken)
+	}
+
+	if val, ok := config.TriggerMetadata[dynatraceMetricSelector]; ok && val != "" {
+		meta.metricSelector = val
+	} else {
+		return nil, fmt.Errorf("no %s given", dynatraceMetricSelector)
+	}
+
+	meta.entitySelector = config.TriggerMetadata[dynatraceEntitySelector]
+
+	if val, ok := config.TriggerMetadata[dynatraceFrom]; ok && val != "" {
+		meta.from = val
+	}
+
+	if val, ok := config.TriggerMetadata[dynatraceThreshold]; ok && val != "" {
+		t, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", dynatraceThreshold, err)
+		}
+		meta.threshold = t
+	} else {
+		return nil, fmt.Errorf("no %s given", dynatraceThreshold)
+	}
+
+	if val, ok := config.TriggerMetadata[dynatraceActivationThreshold]; ok && val != "" {
+		t, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", dynatraceActivationThreshold, err)
+		}
+		meta.activationThreshold = t
+	}
+
+	metricName := defaultDynatraceMetricName
+	if val, ok := config.TriggerMetadata[dynatraceMetricName]; ok && val != "" {
+		metricName = val
+	}
+	meta.metricName = kedautil.NormalizeString(fmt.Sprintf("dynatrace-%s", metricName))
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+// IsActive returns true if the latest datapoint is above the activation threshold
+func (s *dynatraceScaler) IsActive(ctx context.Context) (bool, error) {
+	val, err := s.getMetricValue(ctx)
+	if err != nil {
+		dynatraceLog.Error(err, "error querying dynatrace metrics")
+		return false, err
+	}
+
+	return val > s.metadata.activationThreshold, nil
+}
+
+func (s *dynatraceScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *dynatraceScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetMetricValue := resource.NewMilliQuantity(int64(s.metadata.threshold*1000), resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, s.metadata.metricName),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetMetricValue,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// getMetricValue queries the Metrics v2 API and returns the latest non-null datapoint of the single
+// series returned, no datapoints are 0
+func (s *dynatraceScaler) getMetricValue(ctx context.Context) (float64, error) {
+	query := url_pkg.Values{}
+	query.Set("metricSelector", s.metadata.metricSelector)
+	query.Set("from", s.metadata.from)
+	if s.metadata.entitySelector != "" {
+		query.Set("entitySelector", s.metadata.entitySelector)
+	}
+	url := fmt.Sprintf("%s/api/v2/metrics/query?%s", s.metadata.host, query.Encode())
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return -1, err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", fmt.Sprintf("Api-Token %s", s.metadata.token))
+
+	r, err := s.httpClient.Do(req)
+	if err != nil {
+		return -1, err
+	}
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return -1, err
+	}
+	r.Body.Close()
+
+	if !(r.StatusCode >= 200 && r.StatusCode <= 299) {
+		return -1, fmt.Errorf("dynatrace metrics api returned error. status: %d response: %s", r.StatusCode, string(b))
+	}
+
+	var result dynatraceMetricsQueryResult
+	if err := json.Unmarshal(b, &result); err != nil {
+		return -1, err
+	}
+
+	if len(result.Result) == 0 {
+		return 0, nil
+	} else if len(result.Result) > 1 {
+		return -1, fmt.Errorf("dynatrace metric selector %s returned multiple metrics", s.metadata.metricSelector)
+	}
+
+	data := result.Result[0].Data
+	if len(data) == 0 {
+		return 0, nil
+	} else if len(data) > 1 {
+		return -1, fmt.Errorf("dynatrace metric selector %s returned %d series, aggregate them in the selector", s.metadata.metricSelector, len(data))
+	}
+
+	values := data[0].Values
+	for i := len(values) - 1; i >= 0; i-- {
+		if values[i] != nil {
+			return *values[i], nil
+		}
+	}
+	return 0, nil
+}
+
+func (s *dynatraceScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	val, err := s.getMetricValue(ctx)
+	if err != nil {
+		dynatraceLog.Error(err, "error querying dynatrace metrics")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewMilliQuantity(int64(val*1000), resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
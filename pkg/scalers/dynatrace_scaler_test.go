@@ -0,0 +1,136 @@
+package scalers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type parseDynatraceMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+}
+
+type dynatraceMetricIdentifier struct {
+	metadataTestData *parseDynatraceMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testDynatraceMetadata = []parseDynatraceMetadataTestData{
+	// nothing passed
+	{map[string]string{}, map[string]string{}, true},
+	// properly formed
+	{map[string]string{"host": "https://abc12345.live.dynatrace.com", "metricSelector": "builtin:service.requestCount.total:splitBy():sum", "threshold": "100"}, map[string]string{"token": "dt0c01.token"}, false},
+	// host of a managed tenant from auth params, entitySelector, from, activationThreshold and metricName
+	{map[string]string{"metricSelector": "builtin:service.requestCount.total:splitBy():sum", "entitySelector": "type(SERVICE),entityName.equals(checkout)", "from": "now-5m", "threshold": "2.5", "activationThreshold": "1", "metricName": "checkout-requests"}, map[string]string{"host": "https://dynatrace.example.com/e/environment-id/", "token": "dt0c01.token"}, false},
+	// missing host
+	{map[string]string{"metricSelector": "builtin:service.requestCount.total:splitBy():sum", "threshold": "100"}, map[string]string{"token": "dt0c01.token"}, true},
+	// missing token
+	{map[string]string{"host": "https://abc12345.live.dynatrace.com", "metricSelector": "builtin:service.requestCount.total:splitBy():sum", "threshold": "100"}, map[string]string{}, true},
+	// token in metadata is not accepted
+	{map[string]string{"host": "https://abc12345.live.dynatrace.com", "token": "dt0c01.token", "metricSelector": "builtin:service.requestCount.total:splitBy():sum", "threshold": "100"}, map[string]string{}, true},
+	// missing metricSelector
+	{map[string]string{"host": "https://abc12345.live.dynatrace.com", "threshold": "100"}, map[string]string{"token": "dt0c01.token"}, true},
+	// missing threshold
+	{map[string]string{"host": "https://abc12345.live.dynatrace.com", "metricSelector": "builtin:service.requestCount.total:splitBy():sum"}, map[string]string{"token": "dt0c01.token"}, true},
+	// malformed threshold
+	{map[string]string{"host": "https://abc12345.live.dynatrace.com", "metricSelector": "builtin:service.requestCount.total:splitBy():sum", "threshold": "a"}, map[string]string{"token": "dt0c01.token"}, true},
+	// malformed activationThreshold
+	{map[string]string{"host": "https://abc12345.live.dynatrace.com", "metricSelector": "builtin:service.requestCount.total:splitBy():sum", "threshold": "100", "activationThreshold": "a"}, map[string]string{"token": "dt0c01.token"}, true},
+}
+
+var dynatraceMetricIdentifiers = []dynatraceMetricIdentifier{
+	{&testDynatraceMetadata[1], 0, "s0-dynatrace-dynatrace"},
+	{&testDynatraceMetadata[2], 1, "s1-dynatrace-checkout-requests"},
+}
+
+func TestDynatraceParseMetadata(t *testing.T) {
+	for _, testData := range testDynatraceMetadata {
+		_, err := parseDynatraceMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Error("Expected success but got error", err)
+		}
+		if testData.isError && err == nil {
+			t.Error("Expected error but got success")
+		}
+	}
+}
+
+func TestDynatraceGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range dynatraceMetricIdentifiers {
+		meta, err := parseDynatraceMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, AuthParams: testData.metadataTestData.authParams, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockDynatraceScaler := dynatraceScaler{
+			metadata:   meta,
+			httpClient: http.DefaultClient,
+		}
+
+		metricSpec := mockDynatraceScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Error("Wrong External metric source name:", metricName)
+		}
+	}
+}
+
+func TestDynatraceGetMetricValue(t *testing.T) {
+	var testData = []struct {
+		name           string
+		bodyStr        string
+		responseStatus int
+		expectedValue  float64
+		isError        bool
+	}{
+		{"latest datapoint", `{"result":[{"metricId":"requests","data":[{"dimensions":[],"timestamps":[1,2,3],"values":[4,7.5,null]}]}]}`, http.StatusOK, 7.5, false},
+		{"no datapoints", `{"result":[{"metricId":"requests","data":[{"dimensions":[],"timestamps":[1],"values":[null]}]}]}`, http.StatusOK, 0, false},
+		{"no series", `{"result":[{"metricId":"requests","data":[]}]}`, http.StatusOK, 0, false},
+		{"no result", `{"result":[]}`, http.StatusOK, 0, false},
+		{"multiple series", `{"result":[{"metricId":"requests","data":[{"dimensions":["a"],"values":[1]},{"dimensions":["b"],"values":[2]}]}]}`, http.StatusOK, -1, true},
+		{"multiple metrics", `{"result":[{"metricId":"a","data":[]},{"metricId":"b","data":[]}]}`, http.StatusOK, -1, true},
+		{"error status response", `{"error":{"code":401,"message":"Token Authentication failed"}}`, http.StatusUnauthorized, -1, true},
+	}
+
+	for _, test := range testData {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				assert.Equal(t, "/e/environment-id/api/v2/metrics/query", request.URL.Path)
+				assert.Equal(t, "builtin:service.requestCount.total:splitBy():sum", request.URL.Query().Get("metricSelector"))
+				assert.Equal(t, "type(SERVICE)", request.URL.Query().Get("entitySelector"))
+				assert.Equal(t, "now-2m", request.URL.Query().Get("from"))
+				assert.Equal(t, "Api-Token dt0c01.token", request.Header.Get("Authorization"))
+				writer.WriteHeader(test.responseStatus)
+
+				if _, err := writer.Write([]byte(test.bodyStr)); err != nil {
+					t.Fatal(err)
+				}
+			}))
+			defer server.Close()
+
+			meta, err := parseDynatraceMetadata(&ScalerConfig{
+				TriggerMetadata: map[string]string{"metricSelector": "builtin:service.requestCount.total:splitBy():sum", "entitySelector": "type(SERVICE)", "threshold": "100"},
+				AuthParams:      map[string]string{"host": server.URL + "/e/environment-id/", "token": "dt0c01.token"},
+			})
+			if err != nil {
+				t.Fatal("Could not parse metadata:", err)
+			}
+			scaler := dynatraceScaler{metadata: meta, httpClient: http.DefaultClient}
+
+			value, err := scaler.getMetricValue(context.TODO())
+
+			assert.Equal(t, test.expectedValue, value)
+			if test.isError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
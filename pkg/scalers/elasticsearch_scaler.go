@@ -1,3 +1,6 @@
+//go:build !selective_scalers || scalers_database
+// +build !selective_scalers scalers_database
+
 package scalers
 
 import (
@@ -12,6 +15,7 @@ import (
 	"strings"
 
 	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/elastic/go-elasticsearch/v7/esapi"
 	"github.com/tidwall/gjson"
 	"k8s.io/api/autoscaling/v2beta2"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -26,15 +30,23 @@ import (
 type elasticsearchScaler struct {
 	metadata *elasticsearchMetadata
 	esClient *elasticsearch.Client
+	// failoverClients are the clients of the failoverAddresses
+	failoverClients []*elasticsearch.Client
+	health          *failoverHealth
 }
 
 type elasticsearchMetadata struct {
-	addresses          []string
-	unsafeSsl          bool
+	addresses []string
+	cloudID   string
+	// failoverAddresses are the addresses of other clusters, eg. in other regions, queried in order when the
+	// cluster of addresses or cloudID fails
+	failoverAddresses  []string
 	username           string
 	password           string
+	apiKey             string
 	indexes            []string
 	searchTemplateName string
+	query              string
 	parameters         []string
 	valueLocation      string
 	targetValue        int
@@ -50,35 +62,63 @@ func NewElasticsearchScaler(config *ScalerConfig) (Scaler, error) {
 		return nil, fmt.Errorf("error parsing elasticsearch metadata: %s", err)
 	}
 
-	esClient, err := newElasticsearchClient(meta)
+	tlsConfig, err := createTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
+	esClient, err := newElasticsearchClient(meta, meta.addresses, meta.cloudID, tlsConfig)
 	if err != nil {
 		return nil, fmt.Errorf("error getting elasticsearch client: %s", err)
 	}
-	return &elasticsearchScaler{
-		metadata: meta,
-		esClient: esClient,
-	}, nil
-}
+	failoverClients := make([]*elasticsearch.Client, 0, len(meta.failoverAddresses))
+	for _, address := range meta.failoverAddresses {
+		client, err := newElasticsearchClient(meta, []string{address}, "", tlsConfig)
+		if err != nil {
+			return nil, fmt.Errorf("error getting elasticsearch client: %s", err)
+		}
+		failoverClients = append(failoverClients, client)
+	}
 
-const defaultUnsafeSsl = false
+	scaler := &elasticsearchScaler{
+		metadata:        meta,
+		esClient:        esClient,
+		failoverClients: failoverClients,
+		health:          newFailoverHealth(elasticsearchLog),
+	}
+
+	// the scaler can be created while the cluster is down as long as a failover cluster answers
+	err = scaler.health.do(context.Background(), scaler.clusters(), func(i int) error {
+		_, err := scaler.clients()[i].Info()
+		return err
+	})
+	if err != nil {
+		elasticsearchLog.Error(err, fmt.Sprintf("Found error when pinging search engine: %s", err))
+		return nil, fmt.Errorf("error getting elasticsearch client: %s", err)
+	}
+	return scaler, nil
+}
 
 func parseElasticsearchMetadata(config *ScalerConfig) (*elasticsearchMetadata, error) {
 	meta := elasticsearchMetadata{}
 
 	var err error
-	addresses, err := GetFromAuthOrMeta(config, "addresses")
-	if err != nil {
-		return nil, err
-	}
-	meta.addresses = splitAndTrimBySep(addresses, ",")
-
-	if val, ok := config.TriggerMetadata["unsafeSsl"]; ok {
-		meta.unsafeSsl, err = strconv.ParseBool(val)
+	// cloudID is the id of an Elastic Cloud deployment, it replaces the addresses
+	if val, ok := config.AuthParams["cloudID"]; ok && val != "" {
+		meta.cloudID = val
+	} else if val, ok := config.TriggerMetadata["cloudID"]; ok && val != "" {
+		meta.cloudID = val
+	} else {
+		addresses, err := GetFromAuthOrMeta(config, "addresses")
 		if err != nil {
-			return nil, fmt.Errorf("error parsing unsafeSsl: %s", err)
+			return nil, err
 		}
-	} else {
-		meta.unsafeSsl = defaultUnsafeSsl
+		meta.addresses = splitAndTrimBySep(addresses, ",")
+	}
+
+	meta.failoverAddresses, err = parseFailoverAddresses(config)
+	if err != nil {
+		return nil, err
 	}
 
 	if val, ok := config.AuthParams["username"]; ok {
@@ -93,15 +133,29 @@ func parseElasticsearchMetadata(config *ScalerConfig) (*elasticsearchMetadata, e
 		meta.password = config.ResolvedEnv[config.TriggerMetadata["passwordFromEnv"]]
 	}
 
+	// apiKey is the base64 encoded id:api_key, it takes precedence over username and password
+	meta.apiKey = config.AuthParams["apiKey"]
+
 	index, err := GetFromAuthOrMeta(config, "index")
 	if err != nil {
 		return nil, err
 	}
 	meta.indexes = splitAndTrimBySep(index, ";")
 
-	meta.searchTemplateName, err = GetFromAuthOrMeta(config, "searchTemplateName")
-	if err != nil {
-		return nil, err
+	// query is a search request body run instead of a search template, eg. a count of the hits or an aggregation
+	if val, ok := config.TriggerMetadata["query"]; ok && val != "" {
+		if config.TriggerMetadata["searchTemplateName"] != "" {
+			return nil, fmt.Errorf("only one of searchTemplateName or query can be given")
+		}
+		if !json.Valid([]byte(val)) {
+			return nil, fmt.Errorf("query must be a valid json search request body")
+		}
+		meta.query = val
+	} else {
+		meta.searchTemplateName, err = GetFromAuthOrMeta(config, "searchTemplateName")
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	if val, ok := config.TriggerMetadata["parameters"]; ok {
@@ -122,13 +176,17 @@ func parseElasticsearchMetadata(config *ScalerConfig) (*elasticsearchMetadata, e
 		return nil, fmt.Errorf("targetValue parsing error %s", err.Error())
 	}
 
-	meta.metricName = GenerateMetricNameWithIndex(config.ScalerIndex, kedautil.NormalizeString(fmt.Sprintf("elasticsearch-%s", meta.searchTemplateName)))
+	if meta.query != "" {
+		meta.metricName = GenerateMetricNameWithIndex(config.ScalerIndex, kedautil.NormalizeString(fmt.Sprintf("elasticsearch-query-%s", strings.Join(meta.indexes, "-"))))
+	} else {
+		meta.metricName = GenerateMetricNameWithIndex(config.ScalerIndex, kedautil.NormalizeString(fmt.Sprintf("elasticsearch-%s", meta.searchTemplateName)))
+	}
 	return &meta, nil
 }
 
-// newElasticsearchClient creates elasticsearch db connection
-func newElasticsearchClient(meta *elasticsearchMetadata) (*elasticsearch.Client, error) {
-	config := elasticsearch.Config{Addresses: meta.addresses}
+// newElasticsearchClient creates the client of the cluster of addresses or cloudID
+func newElasticsearchClient(meta *elasticsearchMetadata, addresses []string, cloudID string, tlsConfig *tls.Config) (*elasticsearch.Client, error) {
+	config := elasticsearch.Config{Addresses: addresses, CloudID: cloudID, APIKey: meta.apiKey}
 	if meta.username != "" {
 		config.Username = meta.username
 	}
@@ -136,8 +194,8 @@ func newElasticsearchClient(meta *elasticsearchMetadata) (*elasticsearch.Client,
 		config.Password = meta.password
 	}
 
-	transport := http.DefaultTransport.(*http.Transport)
-	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: meta.unsafeSsl}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
 	config.Transport = transport
 
 	esClient, err := elasticsearch.NewClient(config)
@@ -145,13 +203,21 @@ func newElasticsearchClient(meta *elasticsearchMetadata) (*elasticsearch.Client,
 		elasticsearchLog.Error(err, fmt.Sprintf("Found error when creating client: %s", err))
 		return nil, err
 	}
+	return esClient, nil
+}
 
-	_, err = esClient.Info()
-	if err != nil {
-		elasticsearchLog.Error(err, fmt.Sprintf("Found error when pinging search engine: %s", err))
-		return nil, err
+// clusters returns the names of the clusters in failover order, the cloudID or the addresses of a cluster
+func (s *elasticsearchScaler) clusters() []string {
+	cluster := s.metadata.cloudID
+	if cluster == "" {
+		cluster = strings.Join(s.metadata.addresses, ",")
 	}
-	return esClient, nil
+	return append([]string{cluster}, s.metadata.failoverAddresses...)
+}
+
+// clients returns the clients of the clusters in failover order
+func (s *elasticsearchScaler) clients() []*elasticsearch.Client {
+	return append([]*elasticsearch.Client{s.esClient}, s.failoverClients...)
 }
 
 func (s *elasticsearchScaler) Close(ctx context.Context) error {
@@ -170,25 +236,14 @@ func (s *elasticsearchScaler) IsActive(ctx context.Context) (bool, error) {
 
 // getQueryResult returns result of the scaler query
 func (s *elasticsearchScaler) getQueryResult(ctx context.Context) (int, error) {
-	// Build the request body.
-	var body bytes.Buffer
-	if err := json.NewEncoder(&body).Encode(buildQuery(s.metadata)); err != nil {
-		elasticsearchLog.Error(err, "Error encoding query: %s", err)
-	}
-
-	// Run the templated search
-	res, err := s.esClient.SearchTemplate(
-		&body,
-		s.esClient.SearchTemplate.WithIndex(s.metadata.indexes...),
-		s.esClient.SearchTemplate.WithContext(ctx),
-	)
-	if err != nil {
-		elasticsearchLog.Error(err, fmt.Sprintf("Could not query elasticsearch: %s", err))
-		return 0, err
-	}
-
-	defer res.Body.Close()
-	b, err := ioutil.ReadAll(res.Body)
+	clients := s.clients()
+
+	var b []byte
+	err := s.health.do(ctx, s.clusters(), func(i int) error {
+		var err error
+		b, err = s.search(ctx, clients[i])
+		return err
+	})
 	if err != nil {
 		return 0, err
 	}
@@ -199,6 +254,48 @@ func (s *elasticsearchScaler) getQueryResult(ctx context.Context) (int, error) {
 	return v, nil
 }
 
+// search runs the query or the search template on the cluster of esClient and returns the response body, a search
+// rejected by the cluster isn't retried on the failover clusters
+func (s *elasticsearchScaler) search(ctx context.Context, esClient *elasticsearch.Client) ([]byte, error) {
+	var res *esapi.Response
+	var err error
+	if s.metadata.query != "" {
+		// Run the search
+		res, err = esClient.Search(
+			esClient.Search.WithIndex(s.metadata.indexes...),
+			esClient.Search.WithBody(strings.NewReader(s.metadata.query)),
+			esClient.Search.WithContext(ctx),
+		)
+	} else {
+		// Build the request body.
+		var body bytes.Buffer
+		if err := json.NewEncoder(&body).Encode(buildQuery(s.metadata)); err != nil {
+			elasticsearchLog.Error(err, "Error encoding query: %s", err)
+		}
+
+		// Run the templated search
+		res, err = esClient.SearchTemplate(
+			&body,
+			esClient.SearchTemplate.WithIndex(s.metadata.indexes...),
+			esClient.SearchTemplate.WithContext(ctx),
+		)
+	}
+	if err != nil {
+		elasticsearchLog.Error(err, fmt.Sprintf("Could not query elasticsearch: %s", err))
+		return nil, err
+	}
+
+	defer res.Body.Close()
+	if res.IsError() {
+		err := fmt.Errorf("elasticsearch returned %s", res.String())
+		if res.StatusCode < http.StatusInternalServerError {
+			return nil, noFailover(err)
+		}
+		return nil, err
+	}
+	return ioutil.ReadAll(res.Body)
+}
+
 func buildQuery(metadata *elasticsearchMetadata) map[string]interface{} {
 	parameters := map[string]interface{}{}
 	for _, p := range metadata.parameters {
@@ -266,12 +363,3 @@ func (s *elasticsearchScaler) GetMetrics(ctx context.Context, metricName string,
 
 	return append([]external_metrics.ExternalMetricValue{}, metric), nil
 }
-
-// Splits a string separated by a specified separator and trims space from all the elements.
-func splitAndTrimBySep(s string, sep string) []string {
-	x := strings.Split(s, sep)
-	for i := range x {
-		x[i] = strings.Trim(x[i], " ")
-	}
-	return x
-}
@@ -1,9 +1,15 @@
+//go:build !selective_scalers || scalers_database
+// +build !selective_scalers scalers_database
+
 package scalers
 
 import (
 	"context"
 	"errors"
 	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -91,7 +97,6 @@ var testCases = []parseElasticsearchMetadataTestData{
 		},
 		expectedMetadata: &elasticsearchMetadata{
 			addresses:          []string{"http://localhost:9200"},
-			unsafeSsl:          true,
 			indexes:            []string{"index1"},
 			username:           "admin",
 			password:           "password",
@@ -120,7 +125,6 @@ var testCases = []parseElasticsearchMetadataTestData{
 		},
 		expectedMetadata: &elasticsearchMetadata{
 			addresses:          []string{"http://localhost:9200"},
-			unsafeSsl:          false,
 			indexes:            []string{"index1", "index2"},
 			username:           "admin",
 			password:           "password",
@@ -149,7 +153,6 @@ var testCases = []parseElasticsearchMetadataTestData{
 		},
 		expectedMetadata: &elasticsearchMetadata{
 			addresses:          []string{"http://localhost:9200"},
-			unsafeSsl:          false,
 			indexes:            []string{"index1", "index2"},
 			username:           "admin",
 			password:           "password",
@@ -178,7 +181,6 @@ var testCases = []parseElasticsearchMetadataTestData{
 		},
 		expectedMetadata: &elasticsearchMetadata{
 			addresses:          []string{"http://localhost:9200", "http://localhost:9201"},
-			unsafeSsl:          false,
 			indexes:            []string{"index1"},
 			username:           "admin",
 			password:           "password",
@@ -207,7 +209,6 @@ var testCases = []parseElasticsearchMetadataTestData{
 		},
 		expectedMetadata: &elasticsearchMetadata{
 			addresses:          []string{"http://localhost:9200", "http://localhost:9201"},
-			unsafeSsl:          false,
 			indexes:            []string{"index1"},
 			username:           "admin",
 			password:           "password",
@@ -239,7 +240,6 @@ var testCases = []parseElasticsearchMetadataTestData{
 		},
 		expectedMetadata: &elasticsearchMetadata{
 			addresses:          []string{"http://localhost:9200", "http://localhost:9201"},
-			unsafeSsl:          false,
 			indexes:            []string{"index1"},
 			username:           "admin",
 			password:           "password",
@@ -251,6 +251,109 @@ var testCases = []parseElasticsearchMetadataTestData{
 		},
 		expectedError: nil,
 	},
+	{
+		name: "cloudID and apiKey instead of addresses and basic auth",
+		metadata: map[string]string{
+			"index":              "index1",
+			"searchTemplateName": "myAwesomeSearch",
+			"valueLocation":      "hits.total.value",
+			"targetValue":        "12",
+		},
+		authParams: map[string]string{
+			"cloudID": "deployment:ZXUtd2VzdC0xLmF3cy5mb3VuZC5pbyRhYmMkZGVm",
+			"apiKey":  "a2V5aWQ6c2VjcmV0",
+		},
+		expectedMetadata: &elasticsearchMetadata{
+			cloudID:            "deployment:ZXUtd2VzdC0xLmF3cy5mb3VuZC5pbyRhYmMkZGVm",
+			apiKey:             "a2V5aWQ6c2VjcmV0",
+			indexes:            []string{"index1"},
+			searchTemplateName: "myAwesomeSearch",
+			valueLocation:      "hits.total.value",
+			targetValue:        12,
+			metricName:         "s0-elasticsearch-myAwesomeSearch",
+		},
+		expectedError: nil,
+	},
+	{
+		name: "query instead of search template",
+		metadata: map[string]string{
+			"addresses":     "http://localhost:9200",
+			"index":         "index1;index2",
+			"query":         `{"size": 0, "track_total_hits": true, "query": {"term": {"status": "pending"}}}`,
+			"valueLocation": "hits.total.value",
+			"targetValue":   "12",
+		},
+		authParams: map[string]string{},
+		expectedMetadata: &elasticsearchMetadata{
+			addresses:     []string{"http://localhost:9200"},
+			indexes:       []string{"index1", "index2"},
+			query:         `{"size": 0, "track_total_hits": true, "query": {"term": {"status": "pending"}}}`,
+			valueLocation: "hits.total.value",
+			targetValue:   12,
+			metricName:    "s0-elasticsearch-query-index1-index2",
+		},
+		expectedError: nil,
+	},
+	{
+		name: "failoverAddresses",
+		metadata: map[string]string{
+			"addresses":          "http://localhost:9200",
+			"failoverAddresses":  "http://eu.example.com:9200, http://us.example.com:9200/",
+			"index":              "index1",
+			"searchTemplateName": "myAwesomeSearch",
+			"valueLocation":      "hits.total.value",
+			"targetValue":        "12",
+		},
+		authParams: map[string]string{},
+		expectedMetadata: &elasticsearchMetadata{
+			addresses:          []string{"http://localhost:9200"},
+			failoverAddresses:  []string{"http://eu.example.com:9200", "http://us.example.com:9200"},
+			indexes:            []string{"index1"},
+			searchTemplateName: "myAwesomeSearch",
+			valueLocation:      "hits.total.value",
+			targetValue:        12,
+			metricName:         "s0-elasticsearch-myAwesomeSearch",
+		},
+		expectedError: nil,
+	},
+	{
+		name: "invalid failoverAddresses",
+		metadata: map[string]string{
+			"addresses":          "http://localhost:9200",
+			"failoverAddresses":  "eu.example.com:9200",
+			"index":              "index1",
+			"searchTemplateName": "myAwesomeSearch",
+			"valueLocation":      "hits.total.value",
+			"targetValue":        "12",
+		},
+		authParams:    map[string]string{},
+		expectedError: errors.New("failoverAddresses has to be a list of urls"),
+	},
+	{
+		name: "query and search template",
+		metadata: map[string]string{
+			"addresses":          "http://localhost:9200",
+			"index":              "index1",
+			"searchTemplateName": "myAwesomeSearch",
+			"query":              `{"size": 0}`,
+			"valueLocation":      "hits.total.value",
+			"targetValue":        "12",
+		},
+		authParams:    map[string]string{},
+		expectedError: errors.New("only one of searchTemplateName or query can be given"),
+	},
+	{
+		name: "malformed query",
+		metadata: map[string]string{
+			"addresses":     "http://localhost:9200",
+			"index":         "index1",
+			"query":         `{"size": 0`,
+			"valueLocation": "hits.total.value",
+			"targetValue":   "12",
+		},
+		authParams:    map[string]string{},
+		expectedError: errors.New("query must be a valid json search request body"),
+	},
 }
 
 func TestParseElasticsearchMetadata(t *testing.T) {
@@ -289,7 +392,6 @@ func TestUnsafeSslDefaultValue(t *testing.T) {
 		},
 		expectedMetadata: &elasticsearchMetadata{
 			addresses:          []string{"http://localhost:9200"},
-			unsafeSsl:          false,
 			indexes:            []string{"index1"},
 			username:           "admin",
 			password:           "password",
@@ -428,3 +530,43 @@ func TestElasticsearchGetMetricSpecForScaling(t *testing.T) {
 		assert.Equal(t, metricSpec[0].External.Metric.Name, testData.name)
 	}
 }
+
+func TestElasticsearchGetQueryResult(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Elastic-Product", "Elasticsearch")
+		if r.Header.Get("Authorization") != "APIKey a2V5aWQ6c2VjcmV0" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/":
+			_, _ = w.Write([]byte(`{"version": {"number": "7.15.1"}}`))
+		case "/index1/_search":
+			body, _ := ioutil.ReadAll(r.Body)
+			if string(body) != `{"size": 0, "track_total_hits": true}` {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			_, _ = w.Write([]byte(`{"hits": {"total": {"value": 7, "relation": "eq"}}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	scaler, err := NewElasticsearchScaler(&ScalerConfig{
+		TriggerMetadata: map[string]string{
+			"addresses":     server.URL,
+			"index":         "index1",
+			"query":         `{"size": 0, "track_total_hits": true}`,
+			"valueLocation": "hits.total.value",
+			"targetValue":   "5",
+		},
+		AuthParams: map[string]string{"apiKey": "a2V5aWQ6c2VjcmV0"},
+	})
+	assert.NoError(t, err)
+
+	metrics, err := scaler.GetMetrics(context.Background(), "MetricName", nil)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 7, metrics[0].Value.Value())
+}
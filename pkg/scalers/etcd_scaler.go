@@ -0,0 +1,246 @@
+package scalers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	clientv3 "go.etcd.io/etcd/client/v3"
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	etcdEndpoints       = "endpoints"
+	etcdKey             = "key"
+	etcdPrefix          = "prefix"
+	etcdValue           = "value"
+	etcdActivationValue = "activationValue"
+	etcdUsername        = "username"
+	etcdPassword        = "password"
+	etcdMetricName      = "metricName"
+
+	etcdDialTimeout = 5 * time.Second
+)
+
+type etcdScaler struct {
+	metadata *etcdMetadata
+	client   *clientv3.Client
+	kv       clientv3.KV
+}
+
+type etcdMetadata struct {
+	endpoints []string
+	// key is the key whose value is the metric, prefix the prefix of the keys whose count is the metric
+	key             string
+	prefix          string
+	value           float64
+	activationValue float64
+
+	username string
+	password string
+
+	enableTLS  bool
+	tlsOptions kedautil.TLSOptions
+
+	metricName  string
+	scalerIndex int
+}
+
+var etcdLog = logf.Log.WithName("etcd_scaler")
+
+// NewEtcdScaler creates a new etcdScaler
+func NewEtcdScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseEtcdMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing etcd metadata: %s", err)
+	}
+
+	clientConfig := clientv3.Config{
+		Endpoints:   meta.endpoints,
+		DialTimeout: etcdDialTimeout,
+		Username:    meta.username,
+		Password:    meta.password,
+	}
+	if meta.enableTLS {
+		tlsConfig, err := kedautil.NewTLSConfigFromOptions(meta.tlsOptions)
+		if err != nil {
+			return nil, err
+		}
+		clientConfig.TLS = tlsConfig
+	}
+
+	client, err := clientv3.New(clientConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to etcd: %s", err)
+	}
+
+	return &etcdScaler{
+		metadata: meta,
+		client:   client,
+		kv:       clientv3.NewKV(client),
+	}, nil
+}
+
+func parseEtcdMetadata(config *ScalerConfig) (*etcdMetadata, error) {
+	meta := etcdMetadata{}
+
+	if val, ok := config.TriggerMetadata[etcdEndpoints]; ok && val != "" {
+		meta.endpoints = splitAndTrimBySep(val, ",")
+	} else {
+		return nil, fmt.Errorf("no %s given", etcdEndpoints)
+	}
+
+	meta.key = config.TriggerMetadata[etcdKey]
+	meta.prefix = config.TriggerMetadata[etcdPrefix]
+	switch {
+	case meta.key != "" && meta.prefix != "":
+		return nil, fmt.Errorf("either %s or %s can be given", etcdKey, etcdPrefix)
+	case meta.key == "" && meta.prefix == "":
+		return nil, fmt.Errorf("no %s or %s given", etcdKey, etcdPrefix)
+	}
+
+	if val, ok := config.TriggerMetadata[etcdValue]; ok && val != "" {
+		value, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", etcdValue, err)
+		}
+		meta.value = value
+	} else {
+		return nil, fmt.Errorf("no %s given", etcdValue)
+	}
+
+	if val, ok := config.TriggerMetadata[etcdActivationValue]; ok && val != "" {
+		activationValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", etcdActivationValue, err)
+		}
+		meta.activationValue = activationValue
+	}
+
+	meta.username = config.AuthParams[etcdUsername]
+	meta.password = config.AuthParams[etcdPassword]
+	if (meta.username == "") != (meta.password == "") {
+		return nil, fmt.Errorf("%s and %s must be given together", etcdUsername, etcdPassword)
+	}
+
+	if val, ok := config.AuthParams["tls"]; ok {
+		val = strings.TrimSpace(val)
+
+		if val == "enable" {
+			certGiven := config.AuthParams["cert"] != ""
+			keyGiven := config.AuthParams["key"] != ""
+			if certGiven && !keyGiven {
+				return nil, errors.New("key must be provided with cert")
+			}
+			if keyGiven && !certGiven {
+				return nil, errors.New("cert must be provided with key")
+			}
+			tlsOptions, err := getTLSOptions(config)
+			if err != nil {
+				return nil, err
+			}
+			meta.tlsOptions = tlsOptions
+			meta.enableTLS = true
+		} else {
+			return nil, fmt.Errorf("err incorrect value for TLS given: %s", val)
+		}
+	}
+
+	metricName := meta.key
+	if metricName == "" {
+		metricName = meta.prefix
+	}
+	if val, ok := config.TriggerMetadata[etcdMetricName]; ok && val != "" {
+		metricName = val
+	}
+	meta.metricName = kedautil.NormalizeString(fmt.Sprintf("etcd-%s", strings.Trim(metricName, "/")))
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+// IsActive returns true if the value of the key or the count of the keys is above the activation value
+func (s *etcdScaler) IsActive(ctx context.Context) (bool, error) {
+	value, err := s.getMetricValue(ctx)
+	if err != nil {
+		etcdLog.Error(err, "error getting etcd value")
+		return false, err
+	}
+
+	return value > s.metadata.activationValue, nil
+}
+
+func (s *etcdScaler) Close(context.Context) error {
+	if s.client != nil {
+		return s.client.Close()
+	}
+	return nil
+}
+
+func (s *etcdScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetMetricValue := resource.NewMilliQuantity(int64(s.metadata.value*1000), resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, s.metadata.metricName),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetMetricValue,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// getMetricValue returns the numeric value of the key, a missing key is 0, or the count of the keys under the prefix
+func (s *etcdScaler) getMetricValue(ctx context.Context) (float64, error) {
+	if s.metadata.prefix != "" {
+		resp, err := s.kv.Get(ctx, s.metadata.prefix, clientv3.WithPrefix(), clientv3.WithCountOnly())
+		if err != nil {
+			return -1, fmt.Errorf("error counting etcd keys with prefix %s: %s", s.metadata.prefix, err)
+		}
+		return float64(resp.Count), nil
+	}
+
+	resp, err := s.kv.Get(ctx, s.metadata.key)
+	if err != nil {
+		return -1, fmt.Errorf("error getting etcd key %s: %s", s.metadata.key, err)
+	}
+	if len(resp.Kvs) == 0 {
+		return 0, nil
+	}
+	value, err := strconv.ParseFloat(strings.TrimSpace(string(resp.Kvs[0].Value)), 64)
+	if err != nil {
+		return -1, fmt.Errorf("error parsing the value of etcd key %s: %s", s.metadata.key, err)
+	}
+	return value, nil
+}
+
+func (s *etcdScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	value, err := s.getMetricValue(ctx)
+	if err != nil {
+		etcdLog.Error(err, "error getting etcd value")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewMilliQuantity(int64(value*1000), resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
@@ -0,0 +1,144 @@
+package scalers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+type parseEtcdMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+}
+
+type etcdMetricIdentifier struct {
+	metadataTestData *parseEtcdMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testEtcdMetadata = []parseEtcdMetadataTestData{
+	// nothing passed
+	{map[string]string{}, map[string]string{}, true},
+	// properly formed key
+	{map[string]string{"endpoints": "etcd-0:2379,etcd-1:2379", "key": "/jobs/pending", "value": "10"}, map[string]string{}, false},
+	// prefix with activationValue, metricName, basic auth and mTLS
+	{map[string]string{"endpoints": "https://etcd:2379", "prefix": "/queue/", "value": "2.5", "activationValue": "1", "metricName": "queue"}, map[string]string{"username": "keda", "password": "secret", "tls": "enable", "ca": "caaa", "cert": "ceert", "key": "keey"}, false},
+	// missing endpoints
+	{map[string]string{"key": "/jobs/pending", "value": "10"}, map[string]string{}, true},
+	// missing key and prefix
+	{map[string]string{"endpoints": "etcd:2379", "value": "10"}, map[string]string{}, true},
+	// key and prefix
+	{map[string]string{"endpoints": "etcd:2379", "key": "/jobs/pending", "prefix": "/queue/", "value": "10"}, map[string]string{}, true},
+	// missing value
+	{map[string]string{"endpoints": "etcd:2379", "key": "/jobs/pending"}, map[string]string{}, true},
+	// malformed value
+	{map[string]string{"endpoints": "etcd:2379", "key": "/jobs/pending", "value": "a"}, map[string]string{}, true},
+	// malformed activationValue
+	{map[string]string{"endpoints": "etcd:2379", "key": "/jobs/pending", "value": "10", "activationValue": "a"}, map[string]string{}, true},
+	// username without password
+	{map[string]string{"endpoints": "etcd:2379", "key": "/jobs/pending", "value": "10"}, map[string]string{"username": "keda"}, true},
+	// tls with an invalid value
+	{map[string]string{"endpoints": "etcd:2379", "key": "/jobs/pending", "value": "10"}, map[string]string{"tls": "yes"}, true},
+	// tls cert without key
+	{map[string]string{"endpoints": "etcd:2379", "key": "/jobs/pending", "value": "10"}, map[string]string{"tls": "enable", "cert": "ceert"}, true},
+}
+
+var etcdMetricIdentifiers = []etcdMetricIdentifier{
+	{&testEtcdMetadata[1], 0, "s0-etcd-jobs-pending"},
+	{&testEtcdMetadata[2], 1, "s1-etcd-queue"},
+}
+
+func TestEtcdParseMetadata(t *testing.T) {
+	for _, testData := range testEtcdMetadata {
+		_, err := parseEtcdMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Error("Expected success but got error", err)
+		}
+		if testData.isError && err == nil {
+			t.Error("Expected error but got success")
+		}
+	}
+}
+
+func TestEtcdGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range etcdMetricIdentifiers {
+		meta, err := parseEtcdMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, AuthParams: testData.metadataTestData.authParams, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockEtcdScaler := etcdScaler{metadata: meta}
+
+		metricSpec := mockEtcdScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Error("Wrong External metric source name:", metricName)
+		}
+	}
+}
+
+// fakeEtcdKV returns the response of Get for the key, the other methods of the KV aren't used by the scaler
+type fakeEtcdKV struct {
+	clientv3.KV
+	t        *testing.T
+	prefix   bool
+	response *clientv3.GetResponse
+	err      error
+}
+
+func (kv *fakeEtcdKV) Get(ctx context.Context, key string, opts ...clientv3.OpOption) (*clientv3.GetResponse, error) {
+	op := clientv3.OpGet(key, opts...)
+	assert.Equal(kv.t, kv.prefix, op.IsCountOnly(), "count only")
+	if kv.prefix {
+		assert.Equal(kv.t, "/queue/", key)
+		assert.Equal(kv.t, clientv3.GetPrefixRangeEnd("/queue/"), string(op.RangeBytes()))
+	} else {
+		assert.Equal(kv.t, "/jobs/pending", key)
+		assert.Empty(kv.t, op.RangeBytes())
+	}
+	return kv.response, kv.err
+}
+
+func TestEtcdGetMetricValue(t *testing.T) {
+	var testData = []struct {
+		name          string
+		metadata      map[string]string
+		response      *clientv3.GetResponse
+		err           error
+		expectedValue float64
+		isError       bool
+	}{
+		{"value of key", map[string]string{"key": "/jobs/pending"}, &clientv3.GetResponse{Kvs: []*mvccpb.KeyValue{{Key: []byte("/jobs/pending"), Value: []byte("12.5\n")}}}, nil, 12.5, false},
+		{"missing key", map[string]string{"key": "/jobs/pending"}, &clientv3.GetResponse{}, nil, 0, false},
+		{"malformed value", map[string]string{"key": "/jobs/pending"}, &clientv3.GetResponse{Kvs: []*mvccpb.KeyValue{{Key: []byte("/jobs/pending"), Value: []byte("a")}}}, nil, -1, true},
+		{"count of prefix", map[string]string{"prefix": "/queue/"}, &clientv3.GetResponse{Count: 7}, nil, 7, false},
+		{"error", map[string]string{"key": "/jobs/pending"}, nil, errors.New("etcdserver: request timed out"), -1, true},
+	}
+
+	for _, test := range testData {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			test.metadata["endpoints"] = "etcd:2379"
+			test.metadata["value"] = "10"
+			meta, err := parseEtcdMetadata(&ScalerConfig{TriggerMetadata: test.metadata, AuthParams: map[string]string{}})
+			if err != nil {
+				t.Fatal("Could not parse metadata:", err)
+			}
+			scaler := etcdScaler{metadata: meta, kv: &fakeEtcdKV{t: t, prefix: meta.prefix != "", response: test.response, err: test.err}}
+
+			value, err := scaler.getMetricValue(context.TODO())
+
+			assert.Equal(t, test.expectedValue, value)
+			if test.isError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
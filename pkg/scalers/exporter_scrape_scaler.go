@@ -0,0 +1,198 @@
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+type exporterScrapeScaler struct {
+	metadata   *exporterScrapeMetadata
+	httpClient *http.Client
+}
+
+type exporterScrapeMetadata struct {
+	url        string
+	metricName string
+	labels     map[string]string
+	threshold  float64
+
+	// auth
+	bearerToken string
+
+	scalerIndex int
+}
+
+var exporterScrapeLog = logf.Log.WithName("exporter_scrape_scaler")
+
+// NewExporterScrapeScaler creates a new exporterScrapeScaler
+func NewExporterScrapeScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseExporterScrapeMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing exporter scrape metadata: %s", err)
+	}
+
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &exporterScrapeScaler{
+		metadata:   meta,
+		httpClient: httpClient,
+	}, nil
+}
+
+func parseExporterScrapeMetadata(config *ScalerConfig) (*exporterScrapeMetadata, error) {
+	meta := exporterScrapeMetadata{
+		labels: map[string]string{},
+	}
+
+	if val, ok := config.TriggerMetadata["url"]; ok && val != "" {
+		if _, err := url.ParseRequestURI(val); err != nil {
+			return nil, fmt.Errorf("error parsing url: %s", err)
+		}
+		meta.url = val
+	} else {
+		return nil, fmt.Errorf("no url given")
+	}
+
+	if val, ok := config.TriggerMetadata["metricName"]; ok && val != "" {
+		meta.metricName = val
+	} else {
+		return nil, fmt.Errorf("no metricName given")
+	}
+
+	// labels selects the series of the metric, eg. "cpu=0,mode=idle"
+	if val, ok := config.TriggerMetadata["labels"]; ok && val != "" {
+		for _, pair := range strings.Split(val, ",") {
+			parts := strings.SplitN(pair, "=", 2)
+			if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+				return nil, fmt.Errorf("labels must be in the format name=value,name=value")
+			}
+			meta.labels[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	}
+
+	if val, ok := config.TriggerMetadata["threshold"]; ok && val != "" {
+		threshold, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing threshold: %s", err)
+		}
+		meta.threshold = threshold
+	} else {
+		return nil, fmt.Errorf("no threshold given")
+	}
+
+	meta.bearerToken = config.AuthParams["bearerToken"]
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+func (s *exporterScrapeScaler) IsActive(ctx context.Context) (bool, error) {
+	val, err := s.getValue(ctx)
+	if err != nil {
+		exporterScrapeLog.Error(err, "error scraping exporter")
+		return false, err
+	}
+
+	return val > 0, nil
+}
+
+func (s *exporterScrapeScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *exporterScrapeScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetMetricValue := resource.NewMilliQuantity(int64(s.metadata.threshold*1000), resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(fmt.Sprintf("exporter-scrape-%s", s.metadata.metricName))),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetMetricValue,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+func (s *exporterScrapeScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	val, err := s.getValue(ctx)
+	if err != nil {
+		exporterScrapeLog.Error(err, "error scraping exporter")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewMilliQuantity(int64(val*1000), resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
+
+// getValue scrapes the exposition endpoint and returns the value of the single series matching the labels
+func (s *exporterScrapeScaler) getValue(ctx context.Context) (float64, error) {
+	families, err := scrapeMetricFamilies(ctx, s.httpClient, s.metadata.url, s.metadata.bearerToken)
+	if err != nil {
+		return -1, fmt.Errorf("error scraping exporter metrics: %s", err)
+	}
+
+	family, ok := families[s.metadata.metricName]
+	if !ok {
+		return -1, fmt.Errorf("metric %s not found", s.metadata.metricName)
+	}
+
+	var matches []*dto.Metric
+	for _, metric := range family.GetMetric() {
+		if exporterScrapeLabelsMatch(metric, s.metadata.labels) {
+			matches = append(matches, metric)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return -1, fmt.Errorf("no series of metric %s matches labels %v", s.metadata.metricName, s.metadata.labels)
+	case 1:
+	default:
+		return -1, fmt.Errorf("%d series of metric %s match labels %v, add labels to select a single series", len(matches), s.metadata.metricName, s.metadata.labels)
+	}
+
+	value, ok := metricValue(matches[0])
+	if !ok {
+		return -1, fmt.Errorf("metric %s is not a gauge, counter or untyped metric", s.metadata.metricName)
+	}
+	return value, nil
+}
+
+func exporterScrapeLabelsMatch(metric *dto.Metric, selector map[string]string) bool {
+	matched := 0
+	for _, label := range metric.GetLabel() {
+		if value, ok := selector[label.GetName()]; ok {
+			if value != label.GetValue() {
+				return false
+			}
+			matched++
+		}
+	}
+	return matched == len(selector)
+}
@@ -0,0 +1,136 @@
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type parseExporterScrapeMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+}
+
+type exporterScrapeMetricIdentifier struct {
+	metadataTestData *parseExporterScrapeMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testExporterScrapeMetadata = []parseExporterScrapeMetadataTestData{
+	// nothing passed
+	{map[string]string{}, map[string]string{}, true},
+	// properly formed
+	{map[string]string{"url": "http://node-exporter:9100/metrics", "metricName": "node_load1", "threshold": "1.5"}, map[string]string{}, false},
+	// properly formed with labels and bearer token
+	{map[string]string{"url": "http://netdata:19999/api/v1/allmetrics?format=prometheus", "metricName": "netdata_system_load_load_average", "labels": "chart=system.load, dimension=load1", "threshold": "2"}, map[string]string{"bearerToken": "token"}, false},
+	// missing url
+	{map[string]string{"metricName": "node_load1", "threshold": "1"}, map[string]string{}, true},
+	// malformed url
+	{map[string]string{"url": "node-exporter", "metricName": "node_load1", "threshold": "1"}, map[string]string{}, true},
+	// missing metricName
+	{map[string]string{"url": "http://node-exporter:9100/metrics", "threshold": "1"}, map[string]string{}, true},
+	// malformed labels
+	{map[string]string{"url": "http://node-exporter:9100/metrics", "metricName": "node_load1", "labels": "cpu", "threshold": "1"}, map[string]string{}, true},
+	// missing threshold
+	{map[string]string{"url": "http://node-exporter:9100/metrics", "metricName": "node_load1"}, map[string]string{}, true},
+	// malformed threshold
+	{map[string]string{"url": "http://node-exporter:9100/metrics", "metricName": "node_load1", "threshold": "high"}, map[string]string{}, true},
+}
+
+var exporterScrapeMetricIdentifiers = []exporterScrapeMetricIdentifier{
+	{&testExporterScrapeMetadata[1], 0, "s0-exporter-scrape-node_load1"},
+	{&testExporterScrapeMetadata[2], 1, "s1-exporter-scrape-netdata_system_load_load_average"},
+}
+
+func TestExporterScrapeParseMetadata(t *testing.T) {
+	for idx, testData := range testExporterScrapeMetadata {
+		_, err := parseExporterScrapeMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Errorf("Test %d: expected success but got error: %s", idx, err)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Test %d: expected error but got success", idx)
+		}
+	}
+}
+
+func TestExporterScrapeGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range exporterScrapeMetricIdentifiers {
+		meta, err := parseExporterScrapeMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, AuthParams: testData.metadataTestData.authParams, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockExporterScrapeScaler := exporterScrapeScaler{metadata: meta}
+
+		metricSpec := mockExporterScrapeScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Errorf("Wrong External metric source name: %s, expected: %s", metricName, testData.name)
+		}
+	}
+}
+
+const testExporterScrapeMetrics = `# HELP node_load1 1m load average.
+# TYPE node_load1 gauge
+node_load1 0.75
+# HELP node_cpu_seconds_total Seconds the CPUs spent in each mode.
+# TYPE node_cpu_seconds_total counter
+node_cpu_seconds_total{cpu="0",mode="idle"} 1000.5
+node_cpu_seconds_total{cpu="0",mode="user"} 200
+node_cpu_seconds_total{cpu="1",mode="idle"} 900
+`
+
+func TestExporterScrapeGetValue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, testExporterScrapeMetrics)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		metricName string
+		labels     string
+		value      float64
+		isError    bool
+	}{
+		{"node_load1", "", 0.75, false},
+		{"node_cpu_seconds_total", "cpu=0,mode=idle", 1000.5, false},
+		{"node_cpu_seconds_total", "cpu=1", 900, false},
+		// more than one series matches
+		{"node_cpu_seconds_total", "mode=idle", 0, true},
+		// no series matches
+		{"node_cpu_seconds_total", "cpu=2", 0, true},
+		// label not on the series
+		{"node_load1", "cpu=0", 0, true},
+		{"node_missing", "", 0, true},
+	}
+	for _, test := range tests {
+		scaler, err := NewExporterScrapeScaler(&ScalerConfig{
+			TriggerMetadata: map[string]string{"url": server.URL + "/metrics", "metricName": test.metricName, "labels": test.labels, "threshold": "1"},
+			AuthParams:      map[string]string{"bearerToken": "token"},
+		})
+		if err != nil {
+			t.Fatal("Could not create scaler:", err)
+		}
+		value, err := scaler.(*exporterScrapeScaler).getValue(context.Background())
+		if test.isError {
+			if err == nil {
+				t.Errorf("Expected error for metric %s labels %s but got success", test.metricName, test.labels)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Expected success for metric %s labels %s but got error: %s", test.metricName, test.labels, err)
+		}
+		if value != test.value {
+			t.Errorf("Expected value %f for metric %s labels %s, got %f", test.value, test.metricName, test.labels, value)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// counterRateMinInterval is the minimum time between two samples of a counter to compute a new rate
+const counterRateMinInterval = time.Second
+
+// counterRate computes the per second rate of a counter from its samples. The counter is read by both
+// IsActive and GetMetrics so the rate is only recomputed after counterRateMinInterval
+type counterRate struct {
+	lock       sync.Mutex
+	lastTotal  float64
+	lastSample time.Time
+	lastRate   float64
+}
+
+// update returns the per second rate since the previous sample, the first sample only records the
+// counter and returns a rate of 0
+func (c *counterRate) update(total float64, now time.Time) float64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.lastSample.IsZero() {
+		c.lastTotal = total
+		c.lastSample = now
+		return 0
+	}
+
+	elapsed := now.Sub(c.lastSample)
+	if elapsed < counterRateMinInterval {
+		return c.lastRate
+	}
+
+	delta := total - c.lastTotal
+	if delta < 0 {
+		// the counter was reset by a restart of the process exposing it
+		delta = total
+	}
+	c.lastRate = delta / elapsed.Seconds()
+	c.lastTotal = total
+	c.lastSample = now
+	return c.lastRate
+}
+
+// scrapeMetricFamilies scrapes an endpoint serving metrics in the Prometheus text exposition format,
+// bearerToken is optional
+func scrapeMetricFamilies(ctx context.Context, httpClient *http.Client, metricsURL string, bearerToken string) (map[string]*dto.MetricFamily, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", metricsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", string(expfmt.FmtText))
+	if bearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+bearerToken)
+	}
+
+	r, err := httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("metrics endpoint %s returned %d", metricsURL, r.StatusCode)
+	}
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(r.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing metrics of %s: %s", metricsURL, err)
+	}
+	return families, nil
+}
+
+// metricValue returns the value of a gauge, counter or untyped series
+func metricValue(metric *dto.Metric) (float64, bool) {
+	switch {
+	case metric.GetGauge() != nil:
+		return metric.GetGauge().GetValue(), true
+	case metric.GetCounter() != nil:
+		return metric.GetCounter().GetValue(), true
+	case metric.GetUntyped() != nil:
+		return metric.GetUntyped().GetValue(), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,44 @@
+package scalers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCounterRateUpdate(t *testing.T) {
+	var rate counterRate
+	now := time.Now()
+
+	// the first sample has no rate yet
+	assert.Equal(t, float64(0), rate.update(100, now))
+	assert.Equal(t, float64(5), rate.update(250, now.Add(30*time.Second)))
+	// samples closer than a second keep the previous rate
+	assert.Equal(t, float64(5), rate.update(260, now.Add(30*time.Second+100*time.Millisecond)))
+	// a reset counter counts from zero
+	assert.Equal(t, float64(1), rate.update(10, now.Add(40*time.Second)))
+}
+
+func TestScrapeMetricFamilies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte("# TYPE queue_length gauge\nqueue_length{queue=\"a\"} 3\n"))
+	}))
+	defer server.Close()
+
+	families, err := scrapeMetricFamilies(context.Background(), http.DefaultClient, server.URL, "token")
+	assert.NoError(t, err)
+	value, ok := metricValue(families["queue_length"].GetMetric()[0])
+	assert.True(t, ok)
+	assert.Equal(t, float64(3), value)
+
+	_, err = scrapeMetricFamilies(context.Background(), http.DefaultClient, server.URL, "")
+	assert.Error(t, err)
+}
@@ -0,0 +1,159 @@
+package scalers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+)
+
+const (
+	// failoverAddresses is the comma separated list of the addresses tried in order when the address of the
+	// scaler fails, eg. the servers of other regions
+	failoverAddresses = "failoverAddresses"
+
+	failoverMinBackoff = 30 * time.Second
+	failoverMaxBackoff = 5 * time.Minute
+)
+
+// noFailoverError wraps the error of a request that would fail on the other addresses too, eg. an invalid query
+type noFailoverError struct {
+	err error
+}
+
+func (e noFailoverError) Error() string {
+	return e.err.Error()
+}
+
+func (e noFailoverError) Unwrap() error {
+	return e.err
+}
+
+// noFailover marks err so it's returned without trying the next addresses
+func noFailover(err error) error {
+	return noFailoverError{err: err}
+}
+
+// parseFailoverAddresses returns the failoverAddresses of the trigger, each has to be an URL
+func parseFailoverAddresses(config *ScalerConfig) ([]string, error) {
+	val, ok := config.AuthParams[failoverAddresses]
+	if !ok || val == "" {
+		val = config.TriggerMetadata[failoverAddresses]
+	}
+	if val == "" {
+		return nil, nil
+	}
+
+	var addresses []string
+	for _, address := range splitAndTrimBySep(val, ",") {
+		if address == "" {
+			continue
+		}
+		if u, err := url.ParseRequestURI(address); err != nil || u.Host == "" {
+			return nil, fmt.Errorf("%s has to be a list of urls, got %s", failoverAddresses, address)
+		}
+		addresses = append(addresses, strings.TrimSuffix(address, "/"))
+	}
+	return addresses, nil
+}
+
+// failoverHealth tracks the failures of the ordered addresses of a scaler. The requests go to the first healthy
+// address, an address failing a request is skipped for a backoff growing from failoverMinBackoff up to
+// failoverMaxBackoff with its consecutive failures and gets the requests back once it answers again
+type failoverHealth struct {
+	logger logr.Logger
+	now    func() time.Time
+
+	lock     sync.Mutex
+	failures map[string]int
+	retryAt  map[string]time.Time
+}
+
+func newFailoverHealth(logger logr.Logger) *failoverHealth {
+	return &failoverHealth{
+		logger:   logger,
+		now:      time.Now,
+		failures: map[string]int{},
+		retryAt:  map[string]time.Time{},
+	}
+}
+
+// do calls fn with the index of the addresses in failover order until a call succeeds. The healthy addresses are
+// tried first in their order, then the ones in backoff so the scaler keeps working while all are failing.
+// A single address or a nil failoverHealth calls fn once and returns its error as is
+func (h *failoverHealth) do(ctx context.Context, addresses []string, fn func(i int) error) error {
+	if h == nil || len(addresses) == 1 {
+		return fn(0)
+	}
+
+	var errs []string
+	for _, i := range h.order(addresses) {
+		err := fn(i)
+		if err == nil {
+			h.markHealthy(addresses[i])
+			return nil
+		}
+		var noFailoverErr noFailoverError
+		if errors.As(err, &noFailoverErr) {
+			h.markHealthy(addresses[i])
+			return noFailoverErr.err
+		}
+		if ctx.Err() != nil {
+			// the caller gave up, the address didn't fail
+			return err
+		}
+		h.markUnhealthy(addresses[i], err)
+		errs = append(errs, fmt.Sprintf("%s: %s", addresses[i], err))
+	}
+	return fmt.Errorf("all addresses failed: %s", strings.Join(errs, "; "))
+}
+
+// order returns the indexes of the healthy addresses, then of the addresses in backoff by their end of backoff
+func (h *failoverHealth) order(addresses []string) []int {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	now := h.now()
+	var healthy, backoff []int
+	for i, address := range addresses {
+		if retryAt, ok := h.retryAt[address]; ok && now.Before(retryAt) {
+			backoff = append(backoff, i)
+		} else {
+			healthy = append(healthy, i)
+		}
+	}
+	sort.SliceStable(backoff, func(a, b int) bool {
+		return h.retryAt[addresses[backoff[a]]].Before(h.retryAt[addresses[backoff[b]]])
+	})
+	return append(healthy, backoff...)
+}
+
+func (h *failoverHealth) markHealthy(address string) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	if h.failures[address] > 0 {
+		h.logger.Info("Address is healthy again", "address", address)
+	}
+	delete(h.failures, address)
+	delete(h.retryAt, address)
+}
+
+func (h *failoverHealth) markUnhealthy(address string, err error) {
+	h.lock.Lock()
+	defer h.lock.Unlock()
+
+	h.failures[address]++
+	backoff := failoverMinBackoff << (h.failures[address] - 1)
+	if backoff > failoverMaxBackoff || backoff <= 0 {
+		backoff = failoverMaxBackoff
+	}
+	h.retryAt[address] = h.now().Add(backoff)
+	h.logger.Error(err, "Address failed, failing over to the next address", "address", address, "failures", h.failures[address], "backoff", backoff)
+}
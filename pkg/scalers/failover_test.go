@@ -0,0 +1,126 @@
+package scalers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+type parseFailoverAddressesTestData struct {
+	name       string
+	metadata   map[string]string
+	authParams map[string]string
+	expected   []string
+	isError    bool
+}
+
+var testParseFailoverAddresses = []parseFailoverAddressesTestData{
+	{"not given", map[string]string{}, map[string]string{}, nil, false},
+	{"metadata", map[string]string{"failoverAddresses": "http://eu.example.com, https://us.example.com:8080/"}, map[string]string{}, []string{"http://eu.example.com", "https://us.example.com:8080"}, false},
+	{"auth params override metadata", map[string]string{"failoverAddresses": "http://eu.example.com"}, map[string]string{"failoverAddresses": "http://us.example.com"}, []string{"http://us.example.com"}, false},
+	{"empty entries are skipped", map[string]string{"failoverAddresses": "http://eu.example.com,,"}, map[string]string{}, []string{"http://eu.example.com"}, false},
+	{"no scheme", map[string]string{"failoverAddresses": "eu.example.com"}, map[string]string{}, nil, true},
+	{"no host", map[string]string{"failoverAddresses": "http://"}, map[string]string{}, nil, true},
+}
+
+func TestParseFailoverAddresses(t *testing.T) {
+	for _, testData := range testParseFailoverAddresses {
+		t.Run(testData.name, func(t *testing.T) {
+			addresses, err := parseFailoverAddresses(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams})
+			if testData.isError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testData.expected, addresses)
+		})
+	}
+}
+
+func TestFailoverHealthDo(t *testing.T) {
+	addresses := []string{"primary", "secondary", "tertiary"}
+	now := time.Now()
+	health := newFailoverHealth(logf.Log.WithName("failover_test"))
+	health.now = func() time.Time { return now }
+
+	failing := map[int]bool{0: true}
+	var called []int
+	do := func() error {
+		called = nil
+		return health.do(context.Background(), addresses, func(i int) error {
+			called = append(called, i)
+			if failing[i] {
+				return errors.New("unavailable")
+			}
+			return nil
+		})
+	}
+
+	// the primary fails over to the secondary
+	assert.NoError(t, do())
+	assert.Equal(t, []int{0, 1}, called)
+
+	// the primary is skipped during its backoff
+	assert.NoError(t, do())
+	assert.Equal(t, []int{1}, called)
+
+	// addresses in backoff are still tried when all healthy ones fail
+	failing = map[int]bool{0: true, 1: true, 2: true}
+	assert.Error(t, do())
+	assert.Equal(t, []int{1, 2, 0}, called)
+
+	// the backoff grows with the consecutive failures
+	now = now.Add(failoverMinBackoff)
+	failing = map[int]bool{}
+	assert.NoError(t, do())
+	assert.Equal(t, []int{1}, called)
+
+	// the primary gets the requests back once it answers again
+	now = now.Add(failoverMaxBackoff)
+	assert.NoError(t, do())
+	assert.Equal(t, []int{0}, called)
+	assert.NotContains(t, health.failures, "primary")
+}
+
+func TestFailoverHealthNoFailover(t *testing.T) {
+	health := newFailoverHealth(logf.Log.WithName("failover_test"))
+	rejected := errors.New("bad query")
+
+	calls := 0
+	err := health.do(context.Background(), []string{"primary", "secondary"}, func(i int) error {
+		calls++
+		return noFailover(rejected)
+	})
+	assert.Equal(t, rejected, err)
+	assert.Equal(t, 1, calls)
+	assert.Empty(t, health.failures)
+}
+
+func TestFailoverHealthCanceledContext(t *testing.T) {
+	health := newFailoverHealth(logf.Log.WithName("failover_test"))
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	calls := 0
+	err := health.do(ctx, []string{"primary", "secondary"}, func(i int) error {
+		calls++
+		return ctx.Err()
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Equal(t, 1, calls)
+	assert.Empty(t, health.failures)
+}
+
+func TestFailoverHealthSingleAddress(t *testing.T) {
+	var health *failoverHealth
+	rejected := errors.New("unavailable")
+
+	err := health.do(context.Background(), []string{"primary"}, func(i int) error {
+		return noFailover(rejected)
+	})
+	assert.Equal(t, noFailover(rejected), err)
+}
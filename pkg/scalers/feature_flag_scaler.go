@@ -0,0 +1,369 @@
+package scalers
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	featureFlagProviderLaunchDarkly = "launchdarkly"
+	featureFlagProviderUnleash      = "unleash"
+
+	defaultLaunchDarklyURL        = "https://clientsdk.launchdarkly.com"
+	defaultFeatureFlagContextKind = "user"
+	defaultFeatureFlagContextKey  = "keda"
+	defaultFeatureFlagUnleashApp  = "keda"
+	defaultFeatureFlagTargetValue = 1
+)
+
+type featureFlagScaler struct {
+	metadata   *featureFlagMetadata
+	httpClient *http.Client
+}
+
+type featureFlagMetadata struct {
+	provider string
+	// url is the base URL of the LaunchDarkly client-side SDK endpoints or a Relay Proxy, or the Unleash frontend API
+	// endpoint, eg. https://unleash.example.com/api/frontend, of Unleash or Unleash Edge
+	url     string
+	flagKey string
+	// clientSideID is the client-side ID of the LaunchDarkly environment, the flag has to be available to client-side SDKs
+	clientSideID string
+	// contextKind and contextKey identify the context the flag is evaluated for, so the targeting rules of the
+	// flag can serve another variation to KEDA
+	contextKind string
+	contextKey  string
+	// appName is the appName of the Unleash context
+	appName string
+	// apiToken is the Unleash frontend token
+	apiToken string
+	// defaultValue is used when the flag isn't returned, a disabled Unleash toggle isn't returned and is 0 by default
+	defaultValue          float64
+	hasDefaultValue       bool
+	targetValue           float64
+	activationTargetValue float64
+	scalerIndex           int
+	metricName            string
+}
+
+type unleashFrontendResponse struct {
+	Toggles []struct {
+		Name    string `json:"name"`
+		Enabled bool   `json:"enabled"`
+		Variant struct {
+			Name    string `json:"name"`
+			Enabled bool   `json:"enabled"`
+			Payload *struct {
+				Type  string `json:"type"`
+				Value string `json:"value"`
+			} `json:"payload"`
+		} `json:"variant"`
+	} `json:"toggles"`
+}
+
+var featureFlagLog = logf.Log.WithName("feature_flag_scaler")
+
+// NewFeatureFlagScaler creates a new featureFlagScaler
+func NewFeatureFlagScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseFeatureFlagMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing feature flag metadata: %s", err)
+	}
+
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &featureFlagScaler{
+		metadata:   meta,
+		httpClient: httpClient,
+	}, nil
+}
+
+func parseFeatureFlagMetadata(config *ScalerConfig) (*featureFlagMetadata, error) {
+	meta := featureFlagMetadata{
+		contextKind: defaultFeatureFlagContextKind,
+		contextKey:  defaultFeatureFlagContextKey,
+		appName:     defaultFeatureFlagUnleashApp,
+		targetValue: defaultFeatureFlagTargetValue,
+	}
+
+	switch val := strings.ToLower(config.TriggerMetadata["provider"]); val {
+	case featureFlagProviderLaunchDarkly, featureFlagProviderUnleash:
+		meta.provider = val
+	case "":
+		return nil, fmt.Errorf("no provider given")
+	default:
+		return nil, fmt.Errorf("provider must be %s or %s, got %s", featureFlagProviderLaunchDarkly, featureFlagProviderUnleash, val)
+	}
+
+	if val, ok := config.TriggerMetadata["flagKey"]; ok && val != "" {
+		meta.flagKey = val
+	} else {
+		return nil, fmt.Errorf("no flagKey given")
+	}
+
+	if val, ok := config.TriggerMetadata["url"]; ok && val != "" {
+		if _, err := url.ParseRequestURI(val); err != nil {
+			return nil, fmt.Errorf("error parsing url: %s", err)
+		}
+		meta.url = strings.TrimSuffix(val, "/")
+	} else if meta.provider == featureFlagProviderLaunchDarkly {
+		meta.url = defaultLaunchDarklyURL
+	} else {
+		return nil, fmt.Errorf("no url of the unleash frontend api given")
+	}
+
+	if val, ok := config.TriggerMetadata["contextKind"]; ok && val != "" {
+		meta.contextKind = val
+	}
+	if val, ok := config.TriggerMetadata["contextKey"]; ok && val != "" {
+		meta.contextKey = val
+	}
+	if val, ok := config.TriggerMetadata["appName"]; ok && val != "" {
+		meta.appName = val
+	}
+
+	switch meta.provider {
+	case featureFlagProviderLaunchDarkly:
+		if val, ok := config.TriggerMetadata["clientSideID"]; ok && val != "" {
+			meta.clientSideID = val
+		} else {
+			return nil, fmt.Errorf("no clientSideID given")
+		}
+	case featureFlagProviderUnleash:
+		if val, ok := config.AuthParams["apiToken"]; ok && val != "" {
+			meta.apiToken = val
+		} else if val, ok := config.TriggerMetadata["apiTokenFromEnv"]; ok && val != "" {
+			meta.apiToken = config.ResolvedEnv[val]
+		}
+		if meta.apiToken == "" {
+			return nil, fmt.Errorf("no apiToken given")
+		}
+	}
+
+	if val, ok := config.TriggerMetadata["defaultValue"]; ok && val != "" {
+		defaultValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing defaultValue: %s", err)
+		}
+		meta.defaultValue = defaultValue
+		meta.hasDefaultValue = true
+	}
+
+	if val, ok := config.TriggerMetadata["targetValue"]; ok && val != "" {
+		targetValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing targetValue: %s", err)
+		}
+		meta.targetValue = targetValue
+	}
+
+	if val, ok := config.TriggerMetadata["activationTargetValue"]; ok && val != "" {
+		activationTargetValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing activationTargetValue: %s", err)
+		}
+		meta.activationTargetValue = activationTargetValue
+	}
+
+	meta.metricName = kedautil.NormalizeString(fmt.Sprintf("feature-flag-%s-%s", meta.provider, meta.flagKey))
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+// IsActive returns true if the value of the flag is above the activation target
+func (s *featureFlagScaler) IsActive(ctx context.Context) (bool, error) {
+	val, err := s.getFlagValue(ctx)
+	if err != nil {
+		featureFlagLog.Error(err, "error evaluating feature flag")
+		return false, err
+	}
+
+	return val > s.metadata.activationTargetValue, nil
+}
+
+func (s *featureFlagScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *featureFlagScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetMetricValue := resource.NewMilliQuantity(int64(s.metadata.targetValue*1000), resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, s.metadata.metricName),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetMetricValue,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{External: externalMetric, Type: externalMetricType}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// GetMetrics returns the value of the flag, with the default targetValue of 1 the flag sets the number of replicas
+func (s *featureFlagScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	val, err := s.getFlagValue(ctx)
+	if err != nil {
+		featureFlagLog.Error(err, "error evaluating feature flag")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewMilliQuantity(int64(val*1000), resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
+
+func (s *featureFlagScaler) getFlagValue(ctx context.Context) (float64, error) {
+	if s.metadata.provider == featureFlagProviderUnleash {
+		return s.getUnleashFlagValue(ctx)
+	}
+	return s.getLaunchDarklyFlagValue(ctx)
+}
+
+// getLaunchDarklyFlagValue evaluates the flags for the context with the client-side SDK endpoint and returns the
+// value of the flag
+func (s *featureFlagScaler) getLaunchDarklyFlagValue(ctx context.Context) (float64, error) {
+	evalContext, err := json.Marshal(map[string]string{
+		"kind": s.metadata.contextKind,
+		"key":  s.metadata.contextKey,
+	})
+	if err != nil {
+		return -1, err
+	}
+	evalURL := fmt.Sprintf("%s/sdk/evalx/%s/contexts/%s", s.metadata.url, url.PathEscape(s.metadata.clientSideID), base64.RawURLEncoding.EncodeToString(evalContext))
+
+	var flags map[string]struct {
+		Value json.RawMessage `json:"value"`
+	}
+	if err := s.getFeatureFlagAPI(ctx, evalURL, "", &flags); err != nil {
+		return -1, err
+	}
+
+	flag, ok := flags[s.metadata.flagKey]
+	if !ok {
+		if s.metadata.hasDefaultValue {
+			return s.metadata.defaultValue, nil
+		}
+		return -1, fmt.Errorf("launchdarkly flag %s not found, is it available to client-side SDKs?", s.metadata.flagKey)
+	}
+	return featureFlagValue(flag.Value)
+}
+
+// getUnleashFlagValue evaluates the toggles for the context with the frontend API and returns the payload of the
+// variant of the toggle, or 1 for an enabled toggle without payload
+func (s *featureFlagScaler) getUnleashFlagValue(ctx context.Context) (float64, error) {
+	query := url.Values{}
+	query.Set("appName", s.metadata.appName)
+	query.Set("userId", s.metadata.contextKey)
+	evalURL := fmt.Sprintf("%s?%s", s.metadata.url, query.Encode())
+
+	var response unleashFrontendResponse
+	if err := s.getFeatureFlagAPI(ctx, evalURL, s.metadata.apiToken, &response); err != nil {
+		return -1, err
+	}
+
+	for _, toggle := range response.Toggles {
+		if toggle.Name != s.metadata.flagKey {
+			continue
+		}
+		if !toggle.Enabled {
+			return 0, nil
+		}
+		if toggle.Variant.Enabled && toggle.Variant.Payload != nil {
+			val, err := strconv.ParseFloat(strings.TrimSpace(toggle.Variant.Payload.Value), 64)
+			if err != nil {
+				return -1, fmt.Errorf("payload of variant %s of unleash toggle %s isn't a number: %s", toggle.Variant.Name, toggle.Name, toggle.Variant.Payload.Value)
+			}
+			return val, nil
+		}
+		return 1, nil
+	}
+
+	// the frontend API returns only the enabled toggles
+	if s.metadata.hasDefaultValue {
+		return s.metadata.defaultValue, nil
+	}
+	return 0, nil
+}
+
+func (s *featureFlagScaler) getFeatureFlagAPI(ctx context.Context, url, authorization string, result interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+
+	r, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	if r.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s api returned error. status: %d response: %s", s.metadata.provider, r.StatusCode, string(b))
+	}
+	if err := json.Unmarshal(b, result); err != nil {
+		return fmt.Errorf("error decoding %s api response: %s", s.metadata.provider, err)
+	}
+	return nil
+}
+
+// featureFlagValue converts the value of a flag variation to a number, a boolean flag is 1 or 0
+func featureFlagValue(raw json.RawMessage) (float64, error) {
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(raw))
+	decoder.UseNumber()
+	if err := decoder.Decode(&value); err != nil {
+		return -1, fmt.Errorf("error decoding flag value: %s", err)
+	}
+
+	switch v := value.(type) {
+	case bool:
+		if v {
+			return 1, nil
+		}
+		return 0, nil
+	case json.Number:
+		return v.Float64()
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		if err != nil {
+			return -1, fmt.Errorf("flag value %q isn't a number", v)
+		}
+		return f, nil
+	default:
+		return -1, fmt.Errorf("flag value %s isn't a boolean or a number", string(raw))
+	}
+}
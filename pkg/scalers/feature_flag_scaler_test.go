@@ -0,0 +1,176 @@
+package scalers
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type parseFeatureFlagMetadataTestData struct {
+	metadata    map[string]string
+	authParams  map[string]string
+	resolvedEnv map[string]string
+	isError     bool
+}
+
+type featureFlagMetricIdentifier struct {
+	metadataTestData *parseFeatureFlagMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testFeatureFlagResolvedEnv = map[string]string{
+	"UNLEASH_TOKEN": "default:production.token",
+}
+
+var testFeatureFlagMetadata = []parseFeatureFlagMetadataTestData{
+	// nothing passed
+	{map[string]string{}, map[string]string{}, testFeatureFlagResolvedEnv, true},
+	// launchdarkly with defaults
+	{map[string]string{"provider": "launchdarkly", "flagKey": "worker-replicas", "clientSideID": "5f1b"}, map[string]string{}, testFeatureFlagResolvedEnv, false},
+	// unleash with token from env, context and targets
+	{map[string]string{"provider": "Unleash", "url": "https://unleash.example.com/api/frontend", "flagKey": "workers", "appName": "orders", "contextKey": "eu-west", "defaultValue": "2", "targetValue": "5", "activationTargetValue": "1", "apiTokenFromEnv": "UNLEASH_TOKEN"}, map[string]string{}, testFeatureFlagResolvedEnv, false},
+	// launchdarkly relay proxy
+	{map[string]string{"provider": "launchdarkly", "url": "http://ld-relay:8030", "flagKey": "worker-replicas", "clientSideID": "5f1b", "contextKind": "service", "contextKey": "orders"}, map[string]string{}, testFeatureFlagResolvedEnv, false},
+	// unknown provider
+	{map[string]string{"provider": "flagsmith", "flagKey": "workers"}, map[string]string{}, testFeatureFlagResolvedEnv, true},
+	// missing flagKey
+	{map[string]string{"provider": "launchdarkly", "clientSideID": "5f1b"}, map[string]string{}, testFeatureFlagResolvedEnv, true},
+	// launchdarkly without clientSideID
+	{map[string]string{"provider": "launchdarkly", "flagKey": "worker-replicas"}, map[string]string{}, testFeatureFlagResolvedEnv, true},
+	// unleash without url
+	{map[string]string{"provider": "unleash", "flagKey": "workers"}, map[string]string{"apiToken": "token"}, testFeatureFlagResolvedEnv, true},
+	// unleash without apiToken
+	{map[string]string{"provider": "unleash", "url": "https://unleash.example.com/api/frontend", "flagKey": "workers"}, map[string]string{}, testFeatureFlagResolvedEnv, true},
+	// invalid url
+	{map[string]string{"provider": "unleash", "url": "unleash", "flagKey": "workers"}, map[string]string{"apiToken": "token"}, testFeatureFlagResolvedEnv, true},
+	// invalid defaultValue
+	{map[string]string{"provider": "launchdarkly", "flagKey": "worker-replicas", "clientSideID": "5f1b", "defaultValue": "a"}, map[string]string{}, testFeatureFlagResolvedEnv, true},
+	// invalid targetValue
+	{map[string]string{"provider": "launchdarkly", "flagKey": "worker-replicas", "clientSideID": "5f1b", "targetValue": "a"}, map[string]string{}, testFeatureFlagResolvedEnv, true},
+	// invalid activationTargetValue
+	{map[string]string{"provider": "launchdarkly", "flagKey": "worker-replicas", "clientSideID": "5f1b", "activationTargetValue": "a"}, map[string]string{}, testFeatureFlagResolvedEnv, true},
+}
+
+var featureFlagMetricIdentifiers = []featureFlagMetricIdentifier{
+	{&testFeatureFlagMetadata[1], 0, "s0-feature-flag-launchdarkly-worker-replicas"},
+	{&testFeatureFlagMetadata[2], 1, "s1-feature-flag-unleash-workers"},
+}
+
+func TestParseFeatureFlagMetadata(t *testing.T) {
+	for _, testData := range testFeatureFlagMetadata {
+		_, err := parseFeatureFlagMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams, ResolvedEnv: testData.resolvedEnv})
+		if err != nil && !testData.isError {
+			t.Errorf("Expected success but got error %s for metadata %v", err, testData.metadata)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Expected error but got success for metadata %v", testData.metadata)
+		}
+	}
+}
+
+func TestFeatureFlagGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range featureFlagMetricIdentifiers {
+		meta, err := parseFeatureFlagMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, AuthParams: testData.metadataTestData.authParams, ResolvedEnv: testData.metadataTestData.resolvedEnv, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockFeatureFlagScaler := featureFlagScaler{metadata: meta, httpClient: http.DefaultClient}
+
+		metricSpec := mockFeatureFlagScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Errorf("Wrong External metric source name: %s, expected: %s", metricName, testData.name)
+		}
+	}
+}
+
+func TestFeatureFlagLaunchDarklyValue(t *testing.T) {
+	var tests = []struct {
+		name         string
+		response     string
+		defaultValue string
+		expected     float64
+		isError      bool
+	}{
+		{"number", `{"worker-replicas":{"value":4,"variation":2,"version":7}}`, "", 4, false},
+		{"boolean on", `{"worker-replicas":{"value":true,"variation":0}}`, "", 1, false},
+		{"boolean off", `{"worker-replicas":{"value":false,"variation":1}}`, "", 0, false},
+		{"numeric string", `{"worker-replicas":{"value":"2.5"}}`, "", 2.5, false},
+		{"missing with default", `{"other":{"value":3}}`, "2", 2, false},
+		{"missing", `{"other":{"value":3}}`, "", -1, true},
+		{"string", `{"worker-replicas":{"value":"large"}}`, "", -1, true},
+		{"json", `{"worker-replicas":{"value":{"replicas":3}}}`, "", -1, true},
+	}
+
+	for _, test := range tests {
+		test := test
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			prefix := "/sdk/evalx/5f1b/contexts/"
+			assert.True(t, strings.HasPrefix(r.URL.Path, prefix), r.URL.Path)
+			evalContext, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(r.URL.Path, prefix))
+			assert.NoError(t, err)
+			assert.JSONEq(t, `{"kind":"user","key":"keda"}`, string(evalContext))
+			_, _ = w.Write([]byte(test.response))
+		}))
+
+		meta, err := parseFeatureFlagMetadata(&ScalerConfig{TriggerMetadata: map[string]string{"provider": "launchdarkly", "url": server.URL, "flagKey": "worker-replicas", "clientSideID": "5f1b", "defaultValue": test.defaultValue}})
+		assert.NoError(t, err)
+		scaler := featureFlagScaler{metadata: meta, httpClient: http.DefaultClient}
+
+		val, err := scaler.getFlagValue(context.Background())
+		server.Close()
+		if test.isError {
+			assert.Error(t, err, test.name)
+			continue
+		}
+		assert.NoError(t, err, test.name)
+		assert.Equal(t, test.expected, val, test.name)
+	}
+}
+
+func TestFeatureFlagUnleashValue(t *testing.T) {
+	var tests = []struct {
+		name     string
+		status   int
+		response string
+		expected float64
+		isError  bool
+	}{
+		{"number payload", http.StatusOK, `{"toggles":[{"name":"workers","enabled":true,"variant":{"name":"large","enabled":true,"payload":{"type":"number","value":"6"}}}]}`, 6, false},
+		{"enabled without variant", http.StatusOK, `{"toggles":[{"name":"workers","enabled":true,"variant":{"name":"disabled","enabled":false}}]}`, 1, false},
+		{"disabled", http.StatusOK, `{"toggles":[{"name":"other","enabled":true,"variant":{"name":"disabled","enabled":false}}]}`, 0, false},
+		{"string payload", http.StatusOK, `{"toggles":[{"name":"workers","enabled":true,"variant":{"name":"large","enabled":true,"payload":{"type":"string","value":"large"}}}]}`, -1, true},
+		{"unauthorized", http.StatusUnauthorized, `{"message":"unauthorized"}`, -1, true},
+	}
+
+	for _, test := range tests {
+		test := test
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			assert.Equal(t, "/api/frontend", r.URL.Path)
+			assert.Equal(t, "token", r.Header.Get("Authorization"))
+			assert.Equal(t, "orders", r.URL.Query().Get("appName"))
+			assert.Equal(t, "keda", r.URL.Query().Get("userId"))
+			w.WriteHeader(test.status)
+			_, _ = w.Write([]byte(test.response))
+		}))
+
+		meta, err := parseFeatureFlagMetadata(&ScalerConfig{TriggerMetadata: map[string]string{"provider": "unleash", "url": server.URL + "/api/frontend", "flagKey": "workers", "appName": "orders"}, AuthParams: map[string]string{"apiToken": "token"}})
+		assert.NoError(t, err)
+		scaler := featureFlagScaler{metadata: meta, httpClient: http.DefaultClient}
+
+		val, err := scaler.getFlagValue(context.Background())
+		server.Close()
+		if test.isError {
+			assert.Error(t, err, test.name)
+			continue
+		}
+		assert.NoError(t, err, test.name)
+		assert.Equal(t, test.expected, val, test.name)
+	}
+}
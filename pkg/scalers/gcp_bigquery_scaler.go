@@ -0,0 +1,239 @@
+//go:build !selective_scalers || scalers_gcp
+// +build !selective_scalers scalers_gcp
+
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"cloud.google.com/go/compute/metadata"
+	bigquery "google.golang.org/api/bigquery/v2"
+	option "google.golang.org/api/option"
+	"k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	// bigQueryQueryTimeoutMs is how long BigQuery waits for the query to complete before it returns
+	bigQueryQueryTimeoutMs = 10000
+)
+
+type bigQueryScaler struct {
+	service  *bigquery.Service
+	metadata *bigQueryMetadata
+}
+
+type bigQueryMetadata struct {
+	query                 string
+	projectID             string
+	location              string
+	maximumBytesBilled    int64
+	targetValue           float64
+	activationTargetValue float64
+
+	gcpAuthorization gcpAuthorizationMetadata
+	scalerIndex      int
+}
+
+var bigQueryLog = logf.Log.WithName("gcp_bigquery_scaler")
+
+// NewBigQueryScaler creates a new bigQueryScaler
+func NewBigQueryScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseBigQueryMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing BigQuery metadata: %s", err)
+	}
+
+	return &bigQueryScaler{
+		metadata: meta,
+	}, nil
+}
+
+func parseBigQueryMetadata(config *ScalerConfig) (*bigQueryMetadata, error) {
+	meta := bigQueryMetadata{}
+
+	// query has to return a single row, the first column of it is the metric value
+	if val, ok := config.TriggerMetadata["query"]; ok && val != "" {
+		meta.query = val
+	} else {
+		return nil, fmt.Errorf("no query given")
+	}
+
+	if val, ok := config.TriggerMetadata["targetValue"]; ok && val != "" {
+		targetValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("targetValue parsing error %s", err.Error())
+		}
+		meta.targetValue = targetValue
+	} else {
+		return nil, fmt.Errorf("no targetValue given")
+	}
+
+	if val, ok := config.TriggerMetadata["activationTargetValue"]; ok && val != "" {
+		activationTargetValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("activationTargetValue parsing error %s", err.Error())
+		}
+		meta.activationTargetValue = activationTargetValue
+	}
+
+	// maximumBytesBilled makes BigQuery fail the query instead of billing more than the limit
+	if val, ok := config.TriggerMetadata["maximumBytesBilled"]; ok && val != "" {
+		maximumBytesBilled, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("maximumBytesBilled parsing error %s", err.Error())
+		}
+		if maximumBytesBilled <= 0 {
+			return nil, fmt.Errorf("maximumBytesBilled must be positive, got %d", maximumBytesBilled)
+		}
+		meta.maximumBytesBilled = maximumBytesBilled
+	}
+
+	meta.location = config.TriggerMetadata["location"]
+	meta.projectID = config.TriggerMetadata["projectId"]
+
+	auth, err := getGcpAuthorization(config, config.ResolvedEnv)
+	if err != nil {
+		return nil, err
+	}
+	meta.gcpAuthorization = *auth
+	meta.scalerIndex = config.ScalerIndex
+	return &meta, nil
+}
+
+// IsActive checks if the result of the query is above the activation target
+func (s *bigQueryScaler) IsActive(ctx context.Context) (bool, error) {
+	value, err := s.getQueryResult(ctx)
+	if err != nil {
+		bigQueryLog.Error(err, "error getting Active Status")
+		return false, err
+	}
+	return value > s.metadata.activationTargetValue, nil
+}
+
+func (s *bigQueryScaler) Close(context.Context) error {
+	s.service = nil
+	return nil
+}
+
+// GetMetricSpecForScaling returns the metric spec for the HPA
+func (s *bigQueryScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetValueQty := resource.NewMilliQuantity(int64(s.metadata.targetValue*1000), resource.DecimalSI)
+
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString("gcp-bigquery")),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetValueQty,
+		},
+	}
+
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric,
+		Type:     externalMetricType,
+	}
+
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// GetMetrics runs the query and returns its result
+func (s *bigQueryScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	value, err := s.getQueryResult(ctx)
+	if err != nil {
+		bigQueryLog.Error(err, "error getting BigQuery query result")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewMilliQuantity(int64(value*1000), resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
+
+// setBigQueryService creates the BigQuery service, the project of the query defaults to the project of the
+// credentials or with pod identity to the project of the node
+func (s *bigQueryScaler) setBigQueryService(ctx context.Context) error {
+	var opts []option.ClientOption
+	if s.metadata.gcpAuthorization.podIdentityProviderEnabled {
+		if s.metadata.projectID == "" {
+			project, err := metadata.NewClient(&http.Client{}).ProjectID()
+			if err != nil {
+				return err
+			}
+			s.metadata.projectID = project
+		}
+	} else {
+		var gcpCredentials GoogleApplicationCredentials
+		if err := json.Unmarshal([]byte(s.metadata.gcpAuthorization.GoogleApplicationCredentials), &gcpCredentials); err != nil {
+			return err
+		}
+		if s.metadata.projectID == "" {
+			s.metadata.projectID = gcpCredentials.ProjectID
+		}
+		opts = append(opts, option.WithCredentialsJSON([]byte(s.metadata.gcpAuthorization.GoogleApplicationCredentials)))
+	}
+
+	service, err := bigquery.NewService(ctx, opts...)
+	if err != nil {
+		return err
+	}
+	s.service = service
+	return nil
+}
+
+// getQueryResult runs the query and parses the first column of the single row it returns
+func (s *bigQueryScaler) getQueryResult(ctx context.Context) (float64, error) {
+	if s.service == nil {
+		if err := s.setBigQueryService(ctx); err != nil {
+			return -1, err
+		}
+	}
+
+	useLegacySQL := false
+	req := &bigquery.QueryRequest{
+		Query:              s.metadata.query,
+		UseLegacySql:       &useLegacySQL,
+		Location:           s.metadata.location,
+		MaximumBytesBilled: s.metadata.maximumBytesBilled,
+		TimeoutMs:          bigQueryQueryTimeoutMs,
+	}
+	resp, err := s.service.Jobs.Query(s.metadata.projectID, req).Context(ctx).Do()
+	if err != nil {
+		return -1, err
+	}
+	if !resp.JobComplete {
+		return -1, fmt.Errorf("query didn't complete within %dms", bigQueryQueryTimeoutMs)
+	}
+	if len(resp.Rows) != 1 || len(resp.Rows[0].F) == 0 {
+		return -1, fmt.Errorf("query has to return a single row, got %d rows", len(resp.Rows))
+	}
+
+	// BigQuery returns the values of all types as strings
+	switch v := resp.Rows[0].F[0].V.(type) {
+	case string:
+		value, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return -1, fmt.Errorf("error parsing query result %q: %s", v, err)
+		}
+		return value, nil
+	case nil:
+		return 0, nil
+	default:
+		return -1, fmt.Errorf("query result %v isn't a number", v)
+	}
+}
@@ -0,0 +1,142 @@
+//go:build !selective_scalers || scalers_gcp
+// +build !selective_scalers scalers_gcp
+
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	bigquery "google.golang.org/api/bigquery/v2"
+	option "google.golang.org/api/option"
+)
+
+var testBigQueryResolvedEnv = map[string]string{
+	"SAMPLE_CREDS": "{}",
+}
+
+type parseBigQueryMetadataTestData struct {
+	authParams map[string]string
+	metadata   map[string]string
+	isError    bool
+}
+
+type gcpBigQueryMetricIdentifier struct {
+	metadataTestData *parseBigQueryMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testBigQueryMetadata = []parseBigQueryMetadataTestData{
+	{map[string]string{}, map[string]string{}, true},
+	// properly formed
+	{nil, map[string]string{"query": "SELECT COUNT(*) FROM orders.pending", "targetValue": "100", "credentialsFromEnv": "SAMPLE_CREDS"}, false},
+	// with project, location, activation and bytes limit
+	{nil, map[string]string{"query": "SELECT COUNT(*) FROM orders.pending", "projectId": "myproject", "location": "EU", "maximumBytesBilled": "10000000", "targetValue": "2.5", "activationTargetValue": "1", "credentialsFromEnv": "SAMPLE_CREDS"}, false},
+	// missing query
+	{nil, map[string]string{"targetValue": "100", "credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	// missing targetValue
+	{nil, map[string]string{"query": "SELECT 1", "credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	// malformed targetValue
+	{nil, map[string]string{"query": "SELECT 1", "targetValue": "AA", "credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	// malformed activationTargetValue
+	{nil, map[string]string{"query": "SELECT 1", "targetValue": "100", "activationTargetValue": "AA", "credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	// malformed maximumBytesBilled
+	{nil, map[string]string{"query": "SELECT 1", "targetValue": "100", "maximumBytesBilled": "10MB", "credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	// negative maximumBytesBilled
+	{nil, map[string]string{"query": "SELECT 1", "targetValue": "100", "maximumBytesBilled": "-1", "credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	// missing credentials
+	{nil, map[string]string{"query": "SELECT 1", "targetValue": "100", "credentialsFromEnv": ""}, true},
+	// credentials from AuthParams
+	{map[string]string{"GoogleApplicationCredentials": "Creds"}, map[string]string{"query": "SELECT 1", "targetValue": "100"}, false},
+}
+
+var gcpBigQueryMetricIdentifiers = []gcpBigQueryMetricIdentifier{
+	{&testBigQueryMetadata[1], 0, "s0-gcp-bigquery"},
+	{&testBigQueryMetadata[2], 1, "s1-gcp-bigquery"},
+}
+
+func TestBigQueryParseMetadata(t *testing.T) {
+	for idx, testData := range testBigQueryMetadata {
+		_, err := parseBigQueryMetadata(&ScalerConfig{AuthParams: testData.authParams, TriggerMetadata: testData.metadata, ResolvedEnv: testBigQueryResolvedEnv})
+		if err != nil && !testData.isError {
+			t.Errorf("Test %d: expected success but got error: %s", idx, err)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Test %d: expected error but got success", idx)
+		}
+	}
+}
+
+func TestBigQueryGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range gcpBigQueryMetricIdentifiers {
+		meta, err := parseBigQueryMetadata(&ScalerConfig{AuthParams: testData.metadataTestData.authParams, TriggerMetadata: testData.metadataTestData.metadata, ResolvedEnv: testBigQueryResolvedEnv, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockBigQueryScaler := bigQueryScaler{nil, meta}
+
+		metricSpec := mockBigQueryScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Errorf("Wrong External metric source name: %s, expected: %s", metricName, testData.name)
+		}
+	}
+}
+
+func TestBigQueryGetQueryResult(t *testing.T) {
+	var tests = []struct {
+		name     string
+		response string
+		expected float64
+		isError  bool
+	}{
+		{"count", `{"jobComplete":true,"rows":[{"f":[{"v":"42"}]}]}`, 42, false},
+		{"float", `{"jobComplete":true,"rows":[{"f":[{"v":"2.5"},{"v":"ignored"}]}]}`, 2.5, false},
+		{"null", `{"jobComplete":true,"rows":[{"f":[{"v":null}]}]}`, 0, false},
+		{"not a number", `{"jobComplete":true,"rows":[{"f":[{"v":"pending"}]}]}`, 0, true},
+		{"no rows", `{"jobComplete":true}`, 0, true},
+		{"several rows", `{"jobComplete":true,"rows":[{"f":[{"v":"1"}]},{"f":[{"v":"2"}]}]}`, 0, true},
+		{"incomplete", `{"jobComplete":false}`, 0, true},
+	}
+
+	for _, test := range tests {
+		var request bigquery.QueryRequest
+		var path string
+		response := test.response
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+			_ = json.NewDecoder(r.Body).Decode(&request)
+			w.Header().Set("Content-Type", "application/json")
+			_, _ = w.Write([]byte(response))
+		}))
+		service, err := bigquery.NewService(context.Background(), option.WithEndpoint(server.URL), option.WithoutAuthentication())
+		assert.NoError(t, err)
+		scaler := bigQueryScaler{
+			service: service,
+			metadata: &bigQueryMetadata{
+				query:              "SELECT COUNT(*) FROM orders.pending",
+				projectID:          "myproject",
+				location:           "EU",
+				maximumBytesBilled: 10000000,
+			},
+		}
+
+		val, err := scaler.getQueryResult(context.Background())
+		server.Close()
+		assert.Equal(t, "/projects/myproject/queries", path, test.name)
+		assert.Equal(t, "EU", request.Location, test.name)
+		assert.Equal(t, int64(10000000), request.MaximumBytesBilled, test.name)
+		assert.False(t, *request.UseLegacySql, test.name)
+		if test.isError {
+			assert.Error(t, err, test.name)
+			continue
+		}
+		assert.NoError(t, err, test.name)
+		assert.Equal(t, test.expected, val, test.name)
+	}
+}
@@ -0,0 +1,199 @@
+//go:build !selective_scalers || scalers_gcp
+// +build !selective_scalers scalers_gcp
+
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	cloudTasksStackDriverQueueDepthMetricName = "cloudtasks.googleapis.com/queue/depth"
+
+	defaultCloudTasksTargetValue = 100
+)
+
+type cloudTasksScaler struct {
+	client   *StackDriverClient
+	metadata *cloudTasksMetadata
+}
+
+type cloudTasksMetadata struct {
+	queueName             string
+	location              string
+	projectID             string
+	targetValue           int64
+	activationTargetValue int64
+
+	gcpAuthorization gcpAuthorizationMetadata
+	scalerIndex      int
+}
+
+var cloudTasksLog = logf.Log.WithName("gcp_cloud_tasks_scaler")
+
+// NewCloudTasksScaler creates a new cloudTasksScaler
+func NewCloudTasksScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseCloudTasksMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing Cloud Tasks metadata: %s", err)
+	}
+
+	return &cloudTasksScaler{
+		metadata: meta,
+	}, nil
+}
+
+func parseCloudTasksMetadata(config *ScalerConfig) (*cloudTasksMetadata, error) {
+	meta := cloudTasksMetadata{
+		targetValue: defaultCloudTasksTargetValue,
+	}
+
+	if val, ok := config.TriggerMetadata["queueName"]; ok && val != "" {
+		meta.queueName = val
+	} else {
+		return nil, fmt.Errorf("no queueName given")
+	}
+
+	// queue names are only unique per location, the location narrows down the queue when it is reused across regions
+	meta.location = config.TriggerMetadata["location"]
+	meta.projectID = config.TriggerMetadata["projectId"]
+
+	if val, ok := config.TriggerMetadata["targetValue"]; ok && val != "" {
+		targetValue, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("targetValue parsing error %s", err.Error())
+		}
+		meta.targetValue = targetValue
+	}
+
+	if val, ok := config.TriggerMetadata["activationTargetValue"]; ok && val != "" {
+		activationTargetValue, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("activationTargetValue parsing error %s", err.Error())
+		}
+		meta.activationTargetValue = activationTargetValue
+	}
+
+	auth, err := getGcpAuthorization(config, config.ResolvedEnv)
+	if err != nil {
+		return nil, err
+	}
+	meta.gcpAuthorization = *auth
+	meta.scalerIndex = config.ScalerIndex
+	return &meta, nil
+}
+
+// IsActive checks if the queue holds more tasks than the activation target
+func (s *cloudTasksScaler) IsActive(ctx context.Context) (bool, error) {
+	depth, err := s.getQueueDepth(ctx)
+	if err != nil {
+		cloudTasksLog.Error(err, "error getting Active Status")
+		return false, err
+	}
+	return depth > s.metadata.activationTargetValue, nil
+}
+
+func (s *cloudTasksScaler) Close(context.Context) error {
+	if s.client != nil {
+		err := s.client.metricsClient.Close()
+		s.client = nil
+		if err != nil {
+			cloudTasksLog.Error(err, "error closing StackDriver client")
+		}
+	}
+
+	return nil
+}
+
+// GetMetricSpecForScaling returns the metric spec for the HPA
+func (s *cloudTasksScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetValueQty := resource.NewQuantity(s.metadata.targetValue, resource.DecimalSI)
+
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(s.metricName())),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetValueQty,
+		},
+	}
+
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric,
+		Type:     externalMetricType,
+	}
+
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+func (s *cloudTasksScaler) metricName() string {
+	if s.metadata.location != "" {
+		return fmt.Sprintf("gcp-cloud-tasks-%s-%s", s.metadata.location, s.metadata.queueName)
+	}
+	return fmt.Sprintf("gcp-cloud-tasks-%s", s.metadata.queueName)
+}
+
+// GetMetrics connects to Stack Driver and retrieves the number of tasks in the queue
+func (s *cloudTasksScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	depth, err := s.getQueueDepth(ctx)
+	if err != nil {
+		cloudTasksLog.Error(err, "error getting Cloud Tasks queue depth")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewQuantity(depth, resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
+
+func (s *cloudTasksScaler) setStackdriverClient(ctx context.Context) error {
+	var client *StackDriverClient
+	var err error
+	if s.metadata.gcpAuthorization.podIdentityProviderEnabled {
+		client, err = NewStackDriverClientPodIdentity(ctx)
+	} else {
+		client, err = NewStackDriverClient(ctx, s.metadata.gcpAuthorization.GoogleApplicationCredentials)
+	}
+
+	if err != nil {
+		return err
+	}
+	s.client = client
+	return nil
+}
+
+// getQueueDepth gets the number of tasks in the queue from stackdriver api
+func (s *cloudTasksScaler) getQueueDepth(ctx context.Context) (int64, error) {
+	if s.client == nil {
+		err := s.setStackdriverClient(ctx)
+		if err != nil {
+			return -1, err
+		}
+	}
+
+	return s.client.GetMetrics(ctx, s.getFilter(), s.metadata.projectID)
+}
+
+func (s *cloudTasksScaler) getFilter() string {
+	filter := `metric.type="` + cloudTasksStackDriverQueueDepthMetricName + `" AND resource.type="cloud_tasks_queue" AND resource.labels.queue_id="` + s.metadata.queueName + `"`
+	if s.metadata.location != "" {
+		filter += ` AND resource.labels.location="` + s.metadata.location + `"`
+	}
+	return filter
+}
@@ -0,0 +1,80 @@
+//go:build !selective_scalers || scalers_gcp
+// +build !selective_scalers scalers_gcp
+
+package scalers
+
+import (
+	"context"
+	"testing"
+)
+
+var testCloudTasksResolvedEnv = map[string]string{
+	"SAMPLE_CREDS": "{}",
+}
+
+type parseCloudTasksMetadataTestData struct {
+	authParams map[string]string
+	metadata   map[string]string
+	isError    bool
+}
+
+type gcpCloudTasksMetricIdentifier struct {
+	metadataTestData *parseCloudTasksMetadataTestData
+	scalerIndex      int
+	name             string
+	filter           string
+}
+
+var testCloudTasksMetadata = []parseCloudTasksMetadataTestData{
+	{map[string]string{}, map[string]string{}, true},
+	// properly formed, default targetValue
+	{nil, map[string]string{"queueName": "myqueue", "credentialsFromEnv": "SAMPLE_CREDS"}, false},
+	// with location, project and activation
+	{nil, map[string]string{"queueName": "myqueue", "location": "europe-west1", "projectId": "myproject", "targetValue": "50", "activationTargetValue": "5", "credentialsFromEnv": "SAMPLE_CREDS"}, false},
+	// missing queueName
+	{nil, map[string]string{"targetValue": "50", "credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	// malformed targetValue
+	{nil, map[string]string{"queueName": "myqueue", "targetValue": "AA", "credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	// malformed activationTargetValue
+	{nil, map[string]string{"queueName": "myqueue", "activationTargetValue": "AA", "credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	// missing credentials
+	{nil, map[string]string{"queueName": "myqueue", "credentialsFromEnv": ""}, true},
+	// credentials from AuthParams
+	{map[string]string{"GoogleApplicationCredentials": "Creds"}, map[string]string{"queueName": "myqueue"}, false},
+}
+
+var gcpCloudTasksMetricIdentifiers = []gcpCloudTasksMetricIdentifier{
+	{&testCloudTasksMetadata[1], 0, "s0-gcp-cloud-tasks-myqueue", `metric.type="cloudtasks.googleapis.com/queue/depth" AND resource.type="cloud_tasks_queue" AND resource.labels.queue_id="myqueue"`},
+	{&testCloudTasksMetadata[2], 1, "s1-gcp-cloud-tasks-europe-west1-myqueue", `metric.type="cloudtasks.googleapis.com/queue/depth" AND resource.type="cloud_tasks_queue" AND resource.labels.queue_id="myqueue" AND resource.labels.location="europe-west1"`},
+}
+
+func TestCloudTasksParseMetadata(t *testing.T) {
+	for idx, testData := range testCloudTasksMetadata {
+		_, err := parseCloudTasksMetadata(&ScalerConfig{AuthParams: testData.authParams, TriggerMetadata: testData.metadata, ResolvedEnv: testCloudTasksResolvedEnv})
+		if err != nil && !testData.isError {
+			t.Errorf("Test %d: expected success but got error: %s", idx, err)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Test %d: expected error but got success", idx)
+		}
+	}
+}
+
+func TestCloudTasksGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range gcpCloudTasksMetricIdentifiers {
+		meta, err := parseCloudTasksMetadata(&ScalerConfig{AuthParams: testData.metadataTestData.authParams, TriggerMetadata: testData.metadataTestData.metadata, ResolvedEnv: testCloudTasksResolvedEnv, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockCloudTasksScaler := cloudTasksScaler{nil, meta}
+
+		metricSpec := mockCloudTasksScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Errorf("Wrong External metric source name: %s, expected: %s", metricName, testData.name)
+		}
+		if filter := mockCloudTasksScaler.getFilter(); filter != testData.filter {
+			t.Errorf("Wrong filter: %s, expected: %s", filter, testData.filter)
+		}
+	}
+}
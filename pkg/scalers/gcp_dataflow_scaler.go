@@ -0,0 +1,222 @@
+//go:build !selective_scalers || scalers_gcp
+// +build !selective_scalers scalers_gcp
+
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	dataflowModeSystemLag    = "SystemLag"
+	dataflowModeBacklogBytes = "BacklogBytes"
+
+	dataflowStackDriverSystemLagMetricName    = "dataflow.googleapis.com/job/system_lag"
+	dataflowStackDriverBacklogBytesMetricName = "dataflow.googleapis.com/job/backlog_bytes"
+)
+
+type dataflowScaler struct {
+	client   *StackDriverClient
+	metadata *dataflowMetadata
+}
+
+type dataflowMetadata struct {
+	mode                  string
+	jobName               string
+	stage                 string
+	projectID             string
+	targetValue           int64
+	activationTargetValue int64
+
+	gcpAuthorization gcpAuthorizationMetadata
+	scalerIndex      int
+}
+
+var dataflowLog = logf.Log.WithName("gcp_dataflow_scaler")
+
+// NewDataflowScaler creates a new dataflowScaler
+func NewDataflowScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseDataflowMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing Dataflow metadata: %s", err)
+	}
+
+	return &dataflowScaler{
+		metadata: meta,
+	}, nil
+}
+
+func parseDataflowMetadata(config *ScalerConfig) (*dataflowMetadata, error) {
+	meta := dataflowMetadata{
+		mode: dataflowModeSystemLag,
+	}
+
+	if val, ok := config.TriggerMetadata["mode"]; ok && val != "" {
+		meta.mode = val
+	}
+
+	if val, ok := config.TriggerMetadata["jobName"]; ok && val != "" {
+		meta.jobName = val
+	} else {
+		return nil, fmt.Errorf("no jobName given")
+	}
+
+	switch meta.mode {
+	case dataflowModeSystemLag:
+	case dataflowModeBacklogBytes:
+		// the backlog is reported per stage of the job, the stage selects one of them
+		meta.stage = config.TriggerMetadata["stage"]
+	default:
+		return nil, fmt.Errorf("trigger mode %s must be one of %s, %s", meta.mode, dataflowModeSystemLag, dataflowModeBacklogBytes)
+	}
+
+	// targetValue is the lag in seconds for SystemLag and the number of bytes for BacklogBytes
+	if val, ok := config.TriggerMetadata["targetValue"]; ok && val != "" {
+		targetValue, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("targetValue parsing error %s", err.Error())
+		}
+		meta.targetValue = targetValue
+	} else {
+		return nil, fmt.Errorf("no targetValue given")
+	}
+
+	if val, ok := config.TriggerMetadata["activationTargetValue"]; ok && val != "" {
+		activationTargetValue, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("activationTargetValue parsing error %s", err.Error())
+		}
+		meta.activationTargetValue = activationTargetValue
+	}
+
+	meta.projectID = config.TriggerMetadata["projectId"]
+
+	auth, err := getGcpAuthorization(config, config.ResolvedEnv)
+	if err != nil {
+		return nil, err
+	}
+	meta.gcpAuthorization = *auth
+	meta.scalerIndex = config.ScalerIndex
+	return &meta, nil
+}
+
+// IsActive checks if the lag or backlog of the job is above the activation target
+func (s *dataflowScaler) IsActive(ctx context.Context) (bool, error) {
+	value, err := s.getMetrics(ctx)
+	if err != nil {
+		dataflowLog.Error(err, "error getting Active Status")
+		return false, err
+	}
+	return value > s.metadata.activationTargetValue, nil
+}
+
+func (s *dataflowScaler) Close(context.Context) error {
+	if s.client != nil {
+		err := s.client.metricsClient.Close()
+		s.client = nil
+		if err != nil {
+			dataflowLog.Error(err, "error closing StackDriver client")
+		}
+	}
+
+	return nil
+}
+
+// GetMetricSpecForScaling returns the metric spec for the HPA
+func (s *dataflowScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetValueQty := resource.NewQuantity(s.metadata.targetValue, resource.DecimalSI)
+
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(s.metricName())),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetValueQty,
+		},
+	}
+
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric,
+		Type:     externalMetricType,
+	}
+
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+func (s *dataflowScaler) metricName() string {
+	if s.metadata.mode == dataflowModeBacklogBytes {
+		if s.metadata.stage != "" {
+			return fmt.Sprintf("gcp-dataflow-backlog-bytes-%s-%s", s.metadata.jobName, s.metadata.stage)
+		}
+		return fmt.Sprintf("gcp-dataflow-backlog-bytes-%s", s.metadata.jobName)
+	}
+	return fmt.Sprintf("gcp-dataflow-system-lag-%s", s.metadata.jobName)
+}
+
+// GetMetrics connects to Stack Driver and retrieves the system lag or backlog bytes of the job
+func (s *dataflowScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	value, err := s.getMetrics(ctx)
+	if err != nil {
+		dataflowLog.Error(err, "error getting Dataflow metric")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewQuantity(value, resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
+
+func (s *dataflowScaler) setStackdriverClient(ctx context.Context) error {
+	var client *StackDriverClient
+	var err error
+	if s.metadata.gcpAuthorization.podIdentityProviderEnabled {
+		client, err = NewStackDriverClientPodIdentity(ctx)
+	} else {
+		client, err = NewStackDriverClient(ctx, s.metadata.gcpAuthorization.GoogleApplicationCredentials)
+	}
+
+	if err != nil {
+		return err
+	}
+	s.client = client
+	return nil
+}
+
+// getMetrics gets the Dataflow metric value from stackdriver api
+func (s *dataflowScaler) getMetrics(ctx context.Context) (int64, error) {
+	if s.client == nil {
+		err := s.setStackdriverClient(ctx)
+		if err != nil {
+			return -1, err
+		}
+	}
+
+	return s.client.GetMetrics(ctx, s.getFilter(), s.metadata.projectID)
+}
+
+func (s *dataflowScaler) getFilter() string {
+	if s.metadata.mode == dataflowModeBacklogBytes {
+		filter := `metric.type="` + dataflowStackDriverBacklogBytesMetricName + `" AND resource.type="dataflow_job" AND resource.labels.job_name="` + s.metadata.jobName + `"`
+		if s.metadata.stage != "" {
+			filter += ` AND metric.labels.stage="` + s.metadata.stage + `"`
+		}
+		return filter
+	}
+	return `metric.type="` + dataflowStackDriverSystemLagMetricName + `" AND resource.type="dataflow_job" AND resource.labels.job_name="` + s.metadata.jobName + `"`
+}
@@ -0,0 +1,87 @@
+//go:build !selective_scalers || scalers_gcp
+// +build !selective_scalers scalers_gcp
+
+package scalers
+
+import (
+	"context"
+	"testing"
+)
+
+var testDataflowResolvedEnv = map[string]string{
+	"SAMPLE_CREDS": "{}",
+}
+
+type parseDataflowMetadataTestData struct {
+	authParams map[string]string
+	metadata   map[string]string
+	isError    bool
+}
+
+type gcpDataflowMetricIdentifier struct {
+	metadataTestData *parseDataflowMetadataTestData
+	scalerIndex      int
+	name             string
+	filter           string
+}
+
+var testDataflowMetadata = []parseDataflowMetadataTestData{
+	{map[string]string{}, map[string]string{}, true},
+	// system lag, default mode
+	{nil, map[string]string{"jobName": "orders-enrichment", "targetValue": "30", "credentialsFromEnv": "SAMPLE_CREDS"}, false},
+	// backlog bytes of a stage
+	{nil, map[string]string{"mode": dataflowModeBacklogBytes, "jobName": "orders-enrichment", "stage": "F12", "targetValue": "1000000", "activationTargetValue": "1000", "projectId": "myproject", "credentialsFromEnv": "SAMPLE_CREDS"}, false},
+	// backlog bytes of the job
+	{nil, map[string]string{"mode": dataflowModeBacklogBytes, "jobName": "orders-enrichment", "targetValue": "1000000", "credentialsFromEnv": "SAMPLE_CREDS"}, false},
+	// missing jobName
+	{nil, map[string]string{"targetValue": "30", "credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	// unknown mode
+	{nil, map[string]string{"mode": "Throughput", "jobName": "orders-enrichment", "targetValue": "30", "credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	// missing targetValue
+	{nil, map[string]string{"jobName": "orders-enrichment", "credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	// malformed targetValue
+	{nil, map[string]string{"jobName": "orders-enrichment", "targetValue": "AA", "credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	// malformed activationTargetValue
+	{nil, map[string]string{"jobName": "orders-enrichment", "targetValue": "30", "activationTargetValue": "AA", "credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	// missing credentials
+	{nil, map[string]string{"jobName": "orders-enrichment", "targetValue": "30", "credentialsFromEnv": ""}, true},
+	// credentials from AuthParams
+	{map[string]string{"GoogleApplicationCredentials": "Creds"}, map[string]string{"jobName": "orders-enrichment", "targetValue": "30"}, false},
+}
+
+var gcpDataflowMetricIdentifiers = []gcpDataflowMetricIdentifier{
+	{&testDataflowMetadata[1], 0, "s0-gcp-dataflow-system-lag-orders-enrichment", `metric.type="dataflow.googleapis.com/job/system_lag" AND resource.type="dataflow_job" AND resource.labels.job_name="orders-enrichment"`},
+	{&testDataflowMetadata[2], 1, "s1-gcp-dataflow-backlog-bytes-orders-enrichment-F12", `metric.type="dataflow.googleapis.com/job/backlog_bytes" AND resource.type="dataflow_job" AND resource.labels.job_name="orders-enrichment" AND metric.labels.stage="F12"`},
+	{&testDataflowMetadata[3], 2, "s2-gcp-dataflow-backlog-bytes-orders-enrichment", `metric.type="dataflow.googleapis.com/job/backlog_bytes" AND resource.type="dataflow_job" AND resource.labels.job_name="orders-enrichment"`},
+}
+
+func TestDataflowParseMetadata(t *testing.T) {
+	for idx, testData := range testDataflowMetadata {
+		_, err := parseDataflowMetadata(&ScalerConfig{AuthParams: testData.authParams, TriggerMetadata: testData.metadata, ResolvedEnv: testDataflowResolvedEnv})
+		if err != nil && !testData.isError {
+			t.Errorf("Test %d: expected success but got error: %s", idx, err)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Test %d: expected error but got success", idx)
+		}
+	}
+}
+
+func TestDataflowGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range gcpDataflowMetricIdentifiers {
+		meta, err := parseDataflowMetadata(&ScalerConfig{AuthParams: testData.metadataTestData.authParams, TriggerMetadata: testData.metadataTestData.metadata, ResolvedEnv: testDataflowResolvedEnv, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockDataflowScaler := dataflowScaler{nil, meta}
+
+		metricSpec := mockDataflowScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Errorf("Wrong External metric source name: %s, expected: %s", metricName, testData.name)
+		}
+		if filter := mockDataflowScaler.getFilter(); filter != testData.filter {
+			t.Errorf("Wrong filter: %s, expected: %s", filter, testData.filter)
+		}
+	}
+}
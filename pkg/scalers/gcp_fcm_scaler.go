@@ -0,0 +1,215 @@
+//go:build !selective_scalers || scalers_gcp
+// +build !selective_scalers scalers_gcp
+
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	fcmService = "fcm.googleapis.com"
+
+	fcmModeQuotaUsage = "QuotaUsage"
+	fcmModeBacklog    = "Backlog"
+
+	fcmStackDriverQuotaUsageMetricName = "serviceruntime.googleapis.com/quota/rate/net_usage"
+)
+
+type fcmScaler struct {
+	client   *StackDriverClient
+	metadata *fcmMetadata
+}
+
+type fcmMetadata struct {
+	mode        string
+	targetValue int
+	projectID   string
+	quotaMetric string
+	metricType  string
+
+	gcpAuthorization gcpAuthorizationMetadata
+	scalerIndex      int
+}
+
+var fcmLog = logf.Log.WithName("gcp_fcm_scaler")
+
+// NewFcmScaler creates a new fcmScaler
+func NewFcmScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseFcmMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing FCM metadata: %s", err)
+	}
+
+	return &fcmScaler{
+		metadata: meta,
+	}, nil
+}
+
+func parseFcmMetadata(config *ScalerConfig) (*fcmMetadata, error) {
+	meta := fcmMetadata{
+		mode: fcmModeQuotaUsage,
+	}
+
+	if val, ok := config.TriggerMetadata["mode"]; ok && val != "" {
+		meta.mode = val
+	}
+
+	switch meta.mode {
+	case fcmModeQuotaUsage:
+		meta.quotaMetric = config.TriggerMetadata["quotaMetric"]
+	case fcmModeBacklog:
+		if val, ok := config.TriggerMetadata["metricType"]; ok && val != "" {
+			meta.metricType = val
+		} else {
+			return nil, fmt.Errorf("no metricType given")
+		}
+	default:
+		return nil, fmt.Errorf("trigger mode %s must be one of %s, %s", meta.mode, fcmModeQuotaUsage, fcmModeBacklog)
+	}
+
+	if val, ok := config.TriggerMetadata["targetValue"]; ok && val != "" {
+		targetValue, err := strconv.Atoi(val)
+		if err != nil {
+			return nil, fmt.Errorf("targetValue parsing error %s", err.Error())
+		}
+		meta.targetValue = targetValue
+	} else {
+		return nil, fmt.Errorf("no targetValue given")
+	}
+
+	meta.projectID = config.TriggerMetadata["projectId"]
+
+	auth, err := getGcpAuthorization(config, config.ResolvedEnv)
+	if err != nil {
+		return nil, err
+	}
+	meta.gcpAuthorization = *auth
+	meta.scalerIndex = config.ScalerIndex
+	return &meta, nil
+}
+
+// IsActive checks if FCM is being used by the project
+func (s *fcmScaler) IsActive(ctx context.Context) (bool, error) {
+	value, err := s.getMetrics(ctx)
+	if err != nil {
+		fcmLog.Error(err, "error getting Active Status")
+		return false, err
+	}
+	return value > 0, nil
+}
+
+func (s *fcmScaler) Close(context.Context) error {
+	if s.client != nil {
+		err := s.client.metricsClient.Close()
+		s.client = nil
+		if err != nil {
+			fcmLog.Error(err, "error closing StackDriver client")
+		}
+	}
+
+	return nil
+}
+
+// GetMetricSpecForScaling returns the metric spec for the HPA
+func (s *fcmScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetValueQty := resource.NewQuantity(int64(s.metadata.targetValue), resource.DecimalSI)
+
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(s.metricName())),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetValueQty,
+		},
+	}
+
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric,
+		Type:     externalMetricType,
+	}
+
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+func (s *fcmScaler) metricName() string {
+	if s.metadata.mode == fcmModeBacklog {
+		return fmt.Sprintf("gcp-fcm-backlog-%s", s.metadata.metricType)
+	}
+	if s.metadata.quotaMetric != "" {
+		return fmt.Sprintf("gcp-fcm-quota-%s", s.metadata.quotaMetric)
+	}
+	return "gcp-fcm-quota"
+}
+
+// GetMetrics connects to Stack Driver and retrieves the FCM quota usage or send backlog
+func (s *fcmScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	value, err := s.getMetrics(ctx)
+	if err != nil {
+		fcmLog.Error(err, "error getting FCM metric")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewQuantity(value, resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
+
+func (s *fcmScaler) setStackdriverClient(ctx context.Context) error {
+	var client *StackDriverClient
+	var err error
+	if s.metadata.gcpAuthorization.podIdentityProviderEnabled {
+		client, err = NewStackDriverClientPodIdentity(ctx)
+	} else {
+		client, err = NewStackDriverClient(ctx, s.metadata.gcpAuthorization.GoogleApplicationCredentials)
+	}
+
+	if err != nil {
+		return err
+	}
+	s.client = client
+	return nil
+}
+
+// getMetrics gets the FCM metric value from stackdriver api
+func (s *fcmScaler) getMetrics(ctx context.Context) (int64, error) {
+	if s.client == nil {
+		err := s.setStackdriverClient(ctx)
+		if err != nil {
+			return -1, err
+		}
+	}
+
+	if s.metadata.mode == fcmModeQuotaUsage {
+		// the quota usage is reported per quota metric and method, without quotaMetric the usage of all of them adds up
+		return s.client.GetSummedMetrics(ctx, s.getFilter(), s.metadata.projectID)
+	}
+	return s.client.GetMetrics(ctx, s.getFilter(), s.metadata.projectID)
+}
+
+func (s *fcmScaler) getFilter() string {
+	if s.metadata.mode == fcmModeBacklog {
+		return `metric.type="` + s.metadata.metricType + `"`
+	}
+	filter := `metric.type="` + fcmStackDriverQuotaUsageMetricName + `" AND resource.type="consumer_quota" AND resource.labels.service="` + fcmService + `"`
+	if s.metadata.quotaMetric != "" {
+		filter += ` AND metric.labels.quota_metric="` + s.metadata.quotaMetric + `"`
+	}
+	return filter
+}
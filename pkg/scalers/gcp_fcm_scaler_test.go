@@ -0,0 +1,85 @@
+//go:build !selective_scalers || scalers_gcp
+// +build !selective_scalers scalers_gcp
+
+package scalers
+
+import (
+	"context"
+	"testing"
+)
+
+var testFcmResolvedEnv = map[string]string{
+	"SAMPLE_CREDS": "{}",
+}
+
+type parseFcmMetadataTestData struct {
+	authParams map[string]string
+	metadata   map[string]string
+	isError    bool
+}
+
+type gcpFcmMetricIdentifier struct {
+	metadataTestData *parseFcmMetadataTestData
+	scalerIndex      int
+	name             string
+	filter           string
+}
+
+var testFcmMetadata = []parseFcmMetadataTestData{
+	{map[string]string{}, map[string]string{}, true},
+	// quota usage, default mode
+	{nil, map[string]string{"targetValue": "100", "credentialsFromEnv": "SAMPLE_CREDS"}, false},
+	// quota usage for a single quota metric
+	{nil, map[string]string{"mode": fcmModeQuotaUsage, "quotaMetric": "fcm.googleapis.com/send_requests", "targetValue": "100", "projectId": "myproject", "credentialsFromEnv": "SAMPLE_CREDS"}, false},
+	// send backlog from a custom metric
+	{nil, map[string]string{"mode": fcmModeBacklog, "metricType": "custom.googleapis.com/fcm/pending_sends", "targetValue": "50", "credentialsFromEnv": "SAMPLE_CREDS"}, false},
+	// backlog without metricType
+	{nil, map[string]string{"mode": fcmModeBacklog, "targetValue": "50", "credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	// unknown mode
+	{nil, map[string]string{"mode": "Latency", "targetValue": "50", "credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	// missing targetValue
+	{nil, map[string]string{"credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	// malformed targetValue
+	{nil, map[string]string{"targetValue": "AA", "credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	// missing credentials
+	{nil, map[string]string{"targetValue": "100", "credentialsFromEnv": ""}, true},
+	// credentials from AuthParams
+	{map[string]string{"GoogleApplicationCredentials": "Creds"}, map[string]string{"targetValue": "100"}, false},
+}
+
+var gcpFcmMetricIdentifiers = []gcpFcmMetricIdentifier{
+	{&testFcmMetadata[1], 0, "s0-gcp-fcm-quota", `metric.type="serviceruntime.googleapis.com/quota/rate/net_usage" AND resource.type="consumer_quota" AND resource.labels.service="fcm.googleapis.com"`},
+	{&testFcmMetadata[2], 1, "s1-gcp-fcm-quota-fcm-googleapis-com-send_requests", `metric.type="serviceruntime.googleapis.com/quota/rate/net_usage" AND resource.type="consumer_quota" AND resource.labels.service="fcm.googleapis.com" AND metric.labels.quota_metric="fcm.googleapis.com/send_requests"`},
+	{&testFcmMetadata[3], 2, "s2-gcp-fcm-backlog-custom-googleapis-com-fcm-pending_sends", `metric.type="custom.googleapis.com/fcm/pending_sends"`},
+}
+
+func TestFcmParseMetadata(t *testing.T) {
+	for idx, testData := range testFcmMetadata {
+		_, err := parseFcmMetadata(&ScalerConfig{AuthParams: testData.authParams, TriggerMetadata: testData.metadata, ResolvedEnv: testFcmResolvedEnv})
+		if err != nil && !testData.isError {
+			t.Errorf("Test %d: expected success but got error: %s", idx, err)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Test %d: expected error but got success", idx)
+		}
+	}
+}
+
+func TestFcmGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range gcpFcmMetricIdentifiers {
+		meta, err := parseFcmMetadata(&ScalerConfig{AuthParams: testData.metadataTestData.authParams, TriggerMetadata: testData.metadataTestData.metadata, ResolvedEnv: testFcmResolvedEnv, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockFcmScaler := fcmScaler{nil, meta}
+
+		metricSpec := mockFcmScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Errorf("Wrong External metric source name: %s, expected: %s", metricName, testData.name)
+		}
+		if filter := mockFcmScaler.getFilter(); filter != testData.filter {
+			t.Errorf("Wrong filter: %s, expected: %s", filter, testData.filter)
+		}
+	}
+}
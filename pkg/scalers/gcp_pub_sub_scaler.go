@@ -1,3 +1,6 @@
+//go:build !selective_scalers || scalers_gcp
+// +build !selective_scalers scalers_gcp
+
 package scalers
 
 import (
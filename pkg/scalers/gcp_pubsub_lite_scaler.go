@@ -0,0 +1,217 @@
+//go:build !selective_scalers || scalers_gcp
+// +build !selective_scalers scalers_gcp
+
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	pubSubLiteModeBacklogMessageCount = "BacklogMessageCount"
+	pubSubLiteModeBacklogBytes        = "BacklogBytes"
+
+	pubSubLiteStackDriverBacklogMessageCountMetricName = "pubsublite.googleapis.com/subscription/backlog_message_count"
+	pubSubLiteStackDriverBacklogBytesMetricName        = "pubsublite.googleapis.com/subscription/backlog_quota_bytes"
+)
+
+type pubSubLiteScaler struct {
+	client   *StackDriverClient
+	metadata *pubSubLiteMetadata
+}
+
+type pubSubLiteMetadata struct {
+	mode                  string
+	subscriptionName      string
+	location              string
+	projectID             string
+	targetValue           int64
+	activationTargetValue int64
+
+	gcpAuthorization gcpAuthorizationMetadata
+	scalerIndex      int
+}
+
+var pubSubLiteLog = logf.Log.WithName("gcp_pubsub_lite_scaler")
+
+// NewPubSubLiteScaler creates a new pubSubLiteScaler
+func NewPubSubLiteScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parsePubSubLiteMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing Pub/Sub Lite metadata: %s", err)
+	}
+
+	return &pubSubLiteScaler{
+		metadata: meta,
+	}, nil
+}
+
+func parsePubSubLiteMetadata(config *ScalerConfig) (*pubSubLiteMetadata, error) {
+	meta := pubSubLiteMetadata{
+		mode: pubSubLiteModeBacklogMessageCount,
+	}
+
+	if val, ok := config.TriggerMetadata["mode"]; ok && val != "" {
+		if val != pubSubLiteModeBacklogMessageCount && val != pubSubLiteModeBacklogBytes {
+			return nil, fmt.Errorf("trigger mode %s must be one of %s, %s", val, pubSubLiteModeBacklogMessageCount, pubSubLiteModeBacklogBytes)
+		}
+		meta.mode = val
+	}
+
+	if val, ok := config.TriggerMetadata["subscriptionName"]; ok && val != "" {
+		meta.subscriptionName = val
+	} else {
+		return nil, fmt.Errorf("no subscriptionName given")
+	}
+
+	// Pub/Sub Lite subscriptions are zonal or regional, the location is part of their name
+	if val, ok := config.TriggerMetadata["location"]; ok && val != "" {
+		meta.location = val
+	} else {
+		return nil, fmt.Errorf("no location given")
+	}
+
+	// targetValue is the number of messages for BacklogMessageCount and the number of bytes for BacklogBytes
+	if val, ok := config.TriggerMetadata["targetValue"]; ok && val != "" {
+		targetValue, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("targetValue parsing error %s", err.Error())
+		}
+		meta.targetValue = targetValue
+	} else {
+		return nil, fmt.Errorf("no targetValue given")
+	}
+
+	if val, ok := config.TriggerMetadata["activationTargetValue"]; ok && val != "" {
+		activationTargetValue, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("activationTargetValue parsing error %s", err.Error())
+		}
+		meta.activationTargetValue = activationTargetValue
+	}
+
+	meta.projectID = config.TriggerMetadata["projectId"]
+
+	auth, err := getGcpAuthorization(config, config.ResolvedEnv)
+	if err != nil {
+		return nil, err
+	}
+	meta.gcpAuthorization = *auth
+	meta.scalerIndex = config.ScalerIndex
+	return &meta, nil
+}
+
+// IsActive checks if the backlog of the subscription is above the activation target
+func (s *pubSubLiteScaler) IsActive(ctx context.Context) (bool, error) {
+	backlog, err := s.getBacklog(ctx)
+	if err != nil {
+		pubSubLiteLog.Error(err, "error getting Active Status")
+		return false, err
+	}
+	return backlog > s.metadata.activationTargetValue, nil
+}
+
+func (s *pubSubLiteScaler) Close(context.Context) error {
+	if s.client != nil {
+		err := s.client.metricsClient.Close()
+		s.client = nil
+		if err != nil {
+			pubSubLiteLog.Error(err, "error closing StackDriver client")
+		}
+	}
+
+	return nil
+}
+
+// GetMetricSpecForScaling returns the metric spec for the HPA
+func (s *pubSubLiteScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetValueQty := resource.NewQuantity(s.metadata.targetValue, resource.DecimalSI)
+
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(s.metricName())),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetValueQty,
+		},
+	}
+
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric,
+		Type:     externalMetricType,
+	}
+
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+func (s *pubSubLiteScaler) metricName() string {
+	if s.metadata.mode == pubSubLiteModeBacklogBytes {
+		return fmt.Sprintf("gcp-pubsub-lite-backlog-bytes-%s", s.metadata.subscriptionName)
+	}
+	return fmt.Sprintf("gcp-pubsub-lite-backlog-%s", s.metadata.subscriptionName)
+}
+
+// GetMetrics connects to Stack Driver and retrieves the backlog of the subscription
+func (s *pubSubLiteScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	backlog, err := s.getBacklog(ctx)
+	if err != nil {
+		pubSubLiteLog.Error(err, "error getting Pub/Sub Lite backlog")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewQuantity(backlog, resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
+
+func (s *pubSubLiteScaler) setStackdriverClient(ctx context.Context) error {
+	var client *StackDriverClient
+	var err error
+	if s.metadata.gcpAuthorization.podIdentityProviderEnabled {
+		client, err = NewStackDriverClientPodIdentity(ctx)
+	} else {
+		client, err = NewStackDriverClient(ctx, s.metadata.gcpAuthorization.GoogleApplicationCredentials)
+	}
+
+	if err != nil {
+		return err
+	}
+	s.client = client
+	return nil
+}
+
+// getBacklog gets the backlog of the subscription from stackdriver api, the backlog is reported per partition
+func (s *pubSubLiteScaler) getBacklog(ctx context.Context) (int64, error) {
+	if s.client == nil {
+		err := s.setStackdriverClient(ctx)
+		if err != nil {
+			return -1, err
+		}
+	}
+
+	return s.client.GetSummedMetrics(ctx, s.getFilter(), s.metadata.projectID)
+}
+
+func (s *pubSubLiteScaler) getFilter() string {
+	metricType := pubSubLiteStackDriverBacklogMessageCountMetricName
+	if s.metadata.mode == pubSubLiteModeBacklogBytes {
+		metricType = pubSubLiteStackDriverBacklogBytesMetricName
+	}
+	return `metric.type="` + metricType + `" AND resource.type="pubsublite_subscription_partition" AND resource.labels.subscription_id="` + s.metadata.subscriptionName + `" AND resource.labels.location="` + s.metadata.location + `"`
+}
@@ -0,0 +1,86 @@
+//go:build !selective_scalers || scalers_gcp
+// +build !selective_scalers scalers_gcp
+
+package scalers
+
+import (
+	"context"
+	"testing"
+)
+
+var testPubSubLiteResolvedEnv = map[string]string{
+	"SAMPLE_CREDS": "{}",
+}
+
+type parsePubSubLiteMetadataTestData struct {
+	authParams map[string]string
+	metadata   map[string]string
+	isError    bool
+}
+
+type gcpPubSubLiteMetricIdentifier struct {
+	metadataTestData *parsePubSubLiteMetadataTestData
+	scalerIndex      int
+	name             string
+	filter           string
+}
+
+var testPubSubLiteMetadata = []parsePubSubLiteMetadataTestData{
+	{map[string]string{}, map[string]string{}, true},
+	// backlog message count, default mode
+	{nil, map[string]string{"subscriptionName": "orders-sub", "location": "europe-west1-b", "targetValue": "100", "credentialsFromEnv": "SAMPLE_CREDS"}, false},
+	// backlog bytes
+	{nil, map[string]string{"mode": pubSubLiteModeBacklogBytes, "subscriptionName": "orders-sub", "location": "europe-west1", "targetValue": "1000000", "activationTargetValue": "1000", "projectId": "myproject", "credentialsFromEnv": "SAMPLE_CREDS"}, false},
+	// missing subscriptionName
+	{nil, map[string]string{"location": "europe-west1-b", "targetValue": "100", "credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	// missing location
+	{nil, map[string]string{"subscriptionName": "orders-sub", "targetValue": "100", "credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	// unknown mode
+	{nil, map[string]string{"mode": "OldestUnackedMessageAge", "subscriptionName": "orders-sub", "location": "europe-west1-b", "targetValue": "100", "credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	// missing targetValue
+	{nil, map[string]string{"subscriptionName": "orders-sub", "location": "europe-west1-b", "credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	// malformed targetValue
+	{nil, map[string]string{"subscriptionName": "orders-sub", "location": "europe-west1-b", "targetValue": "AA", "credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	// malformed activationTargetValue
+	{nil, map[string]string{"subscriptionName": "orders-sub", "location": "europe-west1-b", "targetValue": "100", "activationTargetValue": "AA", "credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	// missing credentials
+	{nil, map[string]string{"subscriptionName": "orders-sub", "location": "europe-west1-b", "targetValue": "100", "credentialsFromEnv": ""}, true},
+	// credentials from AuthParams
+	{map[string]string{"GoogleApplicationCredentials": "Creds"}, map[string]string{"subscriptionName": "orders-sub", "location": "europe-west1-b", "targetValue": "100"}, false},
+}
+
+var gcpPubSubLiteMetricIdentifiers = []gcpPubSubLiteMetricIdentifier{
+	{&testPubSubLiteMetadata[1], 0, "s0-gcp-pubsub-lite-backlog-orders-sub", `metric.type="pubsublite.googleapis.com/subscription/backlog_message_count" AND resource.type="pubsublite_subscription_partition" AND resource.labels.subscription_id="orders-sub" AND resource.labels.location="europe-west1-b"`},
+	{&testPubSubLiteMetadata[2], 1, "s1-gcp-pubsub-lite-backlog-bytes-orders-sub", `metric.type="pubsublite.googleapis.com/subscription/backlog_quota_bytes" AND resource.type="pubsublite_subscription_partition" AND resource.labels.subscription_id="orders-sub" AND resource.labels.location="europe-west1"`},
+}
+
+func TestPubSubLiteParseMetadata(t *testing.T) {
+	for idx, testData := range testPubSubLiteMetadata {
+		_, err := parsePubSubLiteMetadata(&ScalerConfig{AuthParams: testData.authParams, TriggerMetadata: testData.metadata, ResolvedEnv: testPubSubLiteResolvedEnv})
+		if err != nil && !testData.isError {
+			t.Errorf("Test %d: expected success but got error: %s", idx, err)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Test %d: expected error but got success", idx)
+		}
+	}
+}
+
+func TestPubSubLiteGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range gcpPubSubLiteMetricIdentifiers {
+		meta, err := parsePubSubLiteMetadata(&ScalerConfig{AuthParams: testData.metadataTestData.authParams, TriggerMetadata: testData.metadataTestData.metadata, ResolvedEnv: testPubSubLiteResolvedEnv, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockPubSubLiteScaler := pubSubLiteScaler{nil, meta}
+
+		metricSpec := mockPubSubLiteScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Errorf("Wrong External metric source name: %s, expected: %s", metricName, testData.name)
+		}
+		if filter := mockPubSubLiteScaler.getFilter(); filter != testData.filter {
+			t.Errorf("Wrong filter: %s, expected: %s", filter, testData.filter)
+		}
+	}
+}
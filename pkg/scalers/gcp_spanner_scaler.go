@@ -0,0 +1,235 @@
+//go:build !selective_scalers || scalers_gcp
+// +build !selective_scalers scalers_gcp
+
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+
+	"k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	spannerModeRequestCount = "RequestCount"
+	spannerModeCPU          = "CPU"
+
+	spannerStackDriverRequestCountMetricName = "spanner.googleapis.com/api/request_count"
+	spannerStackDriverCPUMetricName          = "spanner.googleapis.com/instance/cpu/utilization_by_priority"
+
+	defaultSpannerCPUPriority = "high"
+)
+
+type spannerScaler struct {
+	client   *StackDriverClient
+	metadata *spannerMetadata
+}
+
+type spannerMetadata struct {
+	mode        string
+	targetValue int
+	projectID   string
+	instanceID  string
+	databaseID  string
+	method      string
+	priority    string
+
+	gcpAuthorization gcpAuthorizationMetadata
+	scalerIndex      int
+}
+
+var spannerLog = logf.Log.WithName("gcp_spanner_scaler")
+
+// NewSpannerScaler creates a new spannerScaler
+func NewSpannerScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseSpannerMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing Spanner metadata: %s", err)
+	}
+
+	return &spannerScaler{
+		metadata: meta,
+	}, nil
+}
+
+func parseSpannerMetadata(config *ScalerConfig) (*spannerMetadata, error) {
+	meta := spannerMetadata{
+		mode: spannerModeRequestCount,
+	}
+
+	if val, ok := config.TriggerMetadata["mode"]; ok && val != "" {
+		meta.mode = val
+	}
+
+	if val, ok := config.TriggerMetadata["instanceId"]; ok && val != "" {
+		meta.instanceID = val
+	} else {
+		return nil, fmt.Errorf("no instanceId given")
+	}
+
+	switch meta.mode {
+	case spannerModeRequestCount:
+		meta.databaseID = config.TriggerMetadata["databaseId"]
+		meta.method = config.TriggerMetadata["method"]
+	case spannerModeCPU:
+		meta.priority = defaultSpannerCPUPriority
+		if val, ok := config.TriggerMetadata["priority"]; ok && val != "" {
+			meta.priority = val
+		}
+	default:
+		return nil, fmt.Errorf("trigger mode %s must be one of %s, %s", meta.mode, spannerModeRequestCount, spannerModeCPU)
+	}
+
+	// targetValue is the number of requests per minute for RequestCount and the utilization percentage for CPU
+	if val, ok := config.TriggerMetadata["targetValue"]; ok && val != "" {
+		targetValue, err := strconv.Atoi(val)
+		if err != nil {
+			return nil, fmt.Errorf("targetValue parsing error %s", err.Error())
+		}
+		meta.targetValue = targetValue
+	} else {
+		return nil, fmt.Errorf("no targetValue given")
+	}
+
+	meta.projectID = config.TriggerMetadata["projectId"]
+
+	auth, err := getGcpAuthorization(config, config.ResolvedEnv)
+	if err != nil {
+		return nil, err
+	}
+	meta.gcpAuthorization = *auth
+	meta.scalerIndex = config.ScalerIndex
+	return &meta, nil
+}
+
+// IsActive checks if the Spanner instance receives requests or has CPU usage
+func (s *spannerScaler) IsActive(ctx context.Context) (bool, error) {
+	value, err := s.getMetrics(ctx)
+	if err != nil {
+		spannerLog.Error(err, "error getting Active Status")
+		return false, err
+	}
+	return value > 0, nil
+}
+
+func (s *spannerScaler) Close(context.Context) error {
+	if s.client != nil {
+		err := s.client.metricsClient.Close()
+		s.client = nil
+		if err != nil {
+			spannerLog.Error(err, "error closing StackDriver client")
+		}
+	}
+
+	return nil
+}
+
+// GetMetricSpecForScaling returns the metric spec for the HPA
+func (s *spannerScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetValueQty := resource.NewQuantity(int64(s.metadata.targetValue), resource.DecimalSI)
+
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(s.metricName())),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetValueQty,
+		},
+	}
+
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric,
+		Type:     externalMetricType,
+	}
+
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+func (s *spannerScaler) metricName() string {
+	if s.metadata.mode == spannerModeCPU {
+		return fmt.Sprintf("gcp-spanner-cpu-%s-%s", s.metadata.instanceID, s.metadata.priority)
+	}
+	if s.metadata.databaseID != "" {
+		return fmt.Sprintf("gcp-spanner-requests-%s-%s", s.metadata.instanceID, s.metadata.databaseID)
+	}
+	return fmt.Sprintf("gcp-spanner-requests-%s", s.metadata.instanceID)
+}
+
+// GetMetrics connects to Stack Driver and retrieves the Spanner request count or CPU utilization
+func (s *spannerScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	value, err := s.getMetrics(ctx)
+	if err != nil {
+		spannerLog.Error(err, "error getting Spanner metric")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewQuantity(value, resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
+
+func (s *spannerScaler) setStackdriverClient(ctx context.Context) error {
+	var client *StackDriverClient
+	var err error
+	if s.metadata.gcpAuthorization.podIdentityProviderEnabled {
+		client, err = NewStackDriverClientPodIdentity(ctx)
+	} else {
+		client, err = NewStackDriverClient(ctx, s.metadata.gcpAuthorization.GoogleApplicationCredentials)
+	}
+
+	if err != nil {
+		return err
+	}
+	s.client = client
+	return nil
+}
+
+// getMetrics gets the Spanner metric value from stackdriver api, the CPU utilization is returned as a percentage
+func (s *spannerScaler) getMetrics(ctx context.Context) (int64, error) {
+	if s.client == nil {
+		err := s.setStackdriverClient(ctx)
+		if err != nil {
+			return -1, err
+		}
+	}
+
+	// the metrics are reported per database and, for the requests, per method and status: the values of all
+	// time series matching the filter add up to the utilization or the requests of the instance
+	if s.metadata.mode == spannerModeCPU {
+		utilization, err := s.client.GetSummedDoubleMetrics(ctx, s.getFilter(), s.metadata.projectID)
+		if err != nil {
+			return -1, err
+		}
+		return int64(math.Ceil(utilization * 100)), nil
+	}
+
+	return s.client.GetSummedMetrics(ctx, s.getFilter(), s.metadata.projectID)
+}
+
+func (s *spannerScaler) getFilter() string {
+	if s.metadata.mode == spannerModeCPU {
+		return `metric.type="` + spannerStackDriverCPUMetricName + `" AND resource.type="spanner_instance" AND resource.labels.instance_id="` + s.metadata.instanceID + `" AND metric.labels.priority="` + s.metadata.priority + `"`
+	}
+	filter := `metric.type="` + spannerStackDriverRequestCountMetricName + `" AND resource.type="spanner_instance" AND resource.labels.instance_id="` + s.metadata.instanceID + `"`
+	if s.metadata.databaseID != "" {
+		filter += ` AND metric.labels.database="` + s.metadata.databaseID + `"`
+	}
+	if s.metadata.method != "" {
+		filter += ` AND metric.labels.method="` + s.metadata.method + `"`
+	}
+	return filter
+}
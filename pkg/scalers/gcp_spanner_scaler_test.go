@@ -0,0 +1,88 @@
+//go:build !selective_scalers || scalers_gcp
+// +build !selective_scalers scalers_gcp
+
+package scalers
+
+import (
+	"context"
+	"testing"
+)
+
+var testSpannerResolvedEnv = map[string]string{
+	"SAMPLE_CREDS": "{}",
+}
+
+type parseSpannerMetadataTestData struct {
+	authParams map[string]string
+	metadata   map[string]string
+	isError    bool
+}
+
+type gcpSpannerMetricIdentifier struct {
+	metadataTestData *parseSpannerMetadataTestData
+	scalerIndex      int
+	name             string
+	filter           string
+}
+
+var testSpannerMetadata = []parseSpannerMetadataTestData{
+	{map[string]string{}, map[string]string{}, true},
+	// request count, default mode
+	{nil, map[string]string{"instanceId": "myinstance", "targetValue": "1000", "credentialsFromEnv": "SAMPLE_CREDS"}, false},
+	// request count for a database and method
+	{nil, map[string]string{"mode": spannerModeRequestCount, "instanceId": "myinstance", "databaseId": "orders", "method": "ExecuteSql", "targetValue": "1000", "projectId": "myproject", "credentialsFromEnv": "SAMPLE_CREDS"}, false},
+	// high priority cpu
+	{nil, map[string]string{"mode": spannerModeCPU, "instanceId": "myinstance", "targetValue": "65", "credentialsFromEnv": "SAMPLE_CREDS"}, false},
+	// low priority cpu
+	{nil, map[string]string{"mode": spannerModeCPU, "instanceId": "myinstance", "priority": "low", "targetValue": "65", "credentialsFromEnv": "SAMPLE_CREDS"}, false},
+	// missing instanceId
+	{nil, map[string]string{"targetValue": "1000", "credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	// unknown mode
+	{nil, map[string]string{"mode": "Latency", "instanceId": "myinstance", "targetValue": "50", "credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	// missing targetValue
+	{nil, map[string]string{"instanceId": "myinstance", "credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	// malformed targetValue
+	{nil, map[string]string{"instanceId": "myinstance", "targetValue": "AA", "credentialsFromEnv": "SAMPLE_CREDS"}, true},
+	// missing credentials
+	{nil, map[string]string{"instanceId": "myinstance", "targetValue": "1000", "credentialsFromEnv": ""}, true},
+	// credentials from AuthParams
+	{map[string]string{"GoogleApplicationCredentials": "Creds"}, map[string]string{"instanceId": "myinstance", "targetValue": "1000"}, false},
+}
+
+var gcpSpannerMetricIdentifiers = []gcpSpannerMetricIdentifier{
+	{&testSpannerMetadata[1], 0, "s0-gcp-spanner-requests-myinstance", `metric.type="spanner.googleapis.com/api/request_count" AND resource.type="spanner_instance" AND resource.labels.instance_id="myinstance"`},
+	{&testSpannerMetadata[2], 1, "s1-gcp-spanner-requests-myinstance-orders", `metric.type="spanner.googleapis.com/api/request_count" AND resource.type="spanner_instance" AND resource.labels.instance_id="myinstance" AND metric.labels.database="orders" AND metric.labels.method="ExecuteSql"`},
+	{&testSpannerMetadata[3], 2, "s2-gcp-spanner-cpu-myinstance-high", `metric.type="spanner.googleapis.com/instance/cpu/utilization_by_priority" AND resource.type="spanner_instance" AND resource.labels.instance_id="myinstance" AND metric.labels.priority="high"`},
+	{&testSpannerMetadata[4], 3, "s3-gcp-spanner-cpu-myinstance-low", `metric.type="spanner.googleapis.com/instance/cpu/utilization_by_priority" AND resource.type="spanner_instance" AND resource.labels.instance_id="myinstance" AND metric.labels.priority="low"`},
+}
+
+func TestSpannerParseMetadata(t *testing.T) {
+	for idx, testData := range testSpannerMetadata {
+		_, err := parseSpannerMetadata(&ScalerConfig{AuthParams: testData.authParams, TriggerMetadata: testData.metadata, ResolvedEnv: testSpannerResolvedEnv})
+		if err != nil && !testData.isError {
+			t.Errorf("Test %d: expected success but got error: %s", idx, err)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Test %d: expected error but got success", idx)
+		}
+	}
+}
+
+func TestSpannerGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range gcpSpannerMetricIdentifiers {
+		meta, err := parseSpannerMetadata(&ScalerConfig{AuthParams: testData.metadataTestData.authParams, TriggerMetadata: testData.metadataTestData.metadata, ResolvedEnv: testSpannerResolvedEnv, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockSpannerScaler := spannerScaler{nil, meta}
+
+		metricSpec := mockSpannerScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Errorf("Wrong External metric source name: %s, expected: %s", metricName, testData.name)
+		}
+		if filter := mockSpannerScaler.getFilter(); filter != testData.filter {
+			t.Errorf("Wrong filter: %s, expected: %s", filter, testData.filter)
+		}
+	}
+}
@@ -0,0 +1,447 @@
+package scalers
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	defaultTargetWorkflowQueueLength = 1
+	defaultGithubAPIURL              = "https://api.github.com"
+	githubRunnerScopeRepo            = "repo"
+	githubRunnerScopeOrg             = "org"
+	githubPageSize                   = 100
+	// installation tokens are valid for an hour, they are renewed a bit before they expire
+	githubInstallationTokenMargin = time.Minute
+)
+
+type githubRunnerScaler struct {
+	metadata   *githubRunnerMetadata
+	httpClient *http.Client
+
+	// installationToken is the token of the installation of the GitHub App, it is cached until it expires
+	lock                    sync.Mutex
+	installationToken       string
+	installationTokenExpiry time.Time
+}
+
+type githubRunnerMetadata struct {
+	githubAPIURL string
+	owner        string
+	runnerScope  string
+	repos        []string
+	// labels are the labels of the runners, only the queued jobs they can run are counted
+	labels                              []string
+	targetWorkflowQueueLength           int64
+	activationTargetWorkflowQueueLength int64
+
+	// auth, either a personal access token or a GitHub App installation
+	personalAccessToken string
+	applicationID       string
+	installationID      string
+	appKey              *rsa.PrivateKey
+
+	scalerIndex int
+}
+
+type githubWorkflowRuns struct {
+	WorkflowRuns []struct {
+		ID int64 `json:"id"`
+	} `json:"workflow_runs"`
+}
+
+type githubWorkflowJobs struct {
+	Jobs []struct {
+		ID     int64    `json:"id"`
+		Status string   `json:"status"`
+		Labels []string `json:"labels"`
+	} `json:"jobs"`
+}
+
+type githubRepository struct {
+	Name     string `json:"name"`
+	Archived bool   `json:"archived"`
+}
+
+var githubRunnerLog = logf.Log.WithName("github_runner_scaler")
+
+// NewGitHubRunnerScaler creates a new GitHub Actions runner scaler
+func NewGitHubRunnerScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseGitHubRunnerMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing github runner metadata: %s", err)
+	}
+
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &githubRunnerScaler{
+		metadata:   meta,
+		httpClient: httpClient,
+	}, nil
+}
+
+func parseGitHubRunnerMetadata(config *ScalerConfig) (*githubRunnerMetadata, error) {
+	meta := githubRunnerMetadata{
+		githubAPIURL:              defaultGithubAPIURL,
+		runnerScope:               githubRunnerScopeRepo,
+		targetWorkflowQueueLength: defaultTargetWorkflowQueueLength,
+	}
+
+	if val, ok := config.TriggerMetadata["githubAPIURL"]; ok && val != "" {
+		meta.githubAPIURL = strings.TrimSuffix(val, "/")
+	}
+
+	if val, ok := config.TriggerMetadata["owner"]; ok && val != "" {
+		meta.owner = val
+	} else if val, ok := config.TriggerMetadata["ownerFromEnv"]; ok && val != "" {
+		meta.owner = config.ResolvedEnv[val]
+	}
+	if meta.owner == "" {
+		return nil, fmt.Errorf("no owner given")
+	}
+
+	if val, ok := config.TriggerMetadata["runnerScope"]; ok && val != "" {
+		switch val {
+		case githubRunnerScopeRepo, githubRunnerScopeOrg:
+			meta.runnerScope = val
+		default:
+			return nil, fmt.Errorf("runnerScope must be %s or %s, got %s", githubRunnerScopeRepo, githubRunnerScopeOrg, val)
+		}
+	}
+
+	if val, ok := config.TriggerMetadata["repos"]; ok && val != "" {
+		for _, repo := range splitAndTrimBySep(val, ",") {
+			if repo != "" {
+				meta.repos = append(meta.repos, repo)
+			}
+		}
+	}
+	// the jobs of all repositories of an organization are counted unless repos are given
+	if meta.runnerScope == githubRunnerScopeRepo && len(meta.repos) == 0 {
+		return nil, fmt.Errorf("no repos given")
+	}
+
+	if val, ok := config.TriggerMetadata["labels"]; ok && val != "" {
+		for _, label := range splitAndTrimBySep(val, ",") {
+			if label != "" {
+				meta.labels = append(meta.labels, label)
+			}
+		}
+	}
+
+	if val, ok := config.TriggerMetadata["targetWorkflowQueueLength"]; ok && val != "" {
+		queueLength, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing targetWorkflowQueueLength: %s", err)
+		}
+		meta.targetWorkflowQueueLength = queueLength
+	}
+
+	if val, ok := config.TriggerMetadata["activationTargetWorkflowQueueLength"]; ok && val != "" {
+		queueLength, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing activationTargetWorkflowQueueLength: %s", err)
+		}
+		meta.activationTargetWorkflowQueueLength = queueLength
+	}
+
+	if val, ok := config.AuthParams["personalAccessToken"]; ok && val != "" {
+		meta.personalAccessToken = val
+		meta.scalerIndex = config.ScalerIndex
+		return &meta, nil
+	}
+
+	appKey, ok := config.AuthParams["appKey"]
+	if !ok || appKey == "" {
+		return nil, fmt.Errorf("no personalAccessToken or appKey given")
+	}
+	key, err := parseGitHubAppKey(appKey)
+	if err != nil {
+		return nil, err
+	}
+	meta.appKey = key
+
+	applicationID, err := GetFromAuthOrMeta(config, "applicationID")
+	if err != nil {
+		return nil, err
+	}
+	meta.applicationID = applicationID
+
+	installationID, err := GetFromAuthOrMeta(config, "installationID")
+	if err != nil {
+		return nil, err
+	}
+	meta.installationID = installationID
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+// parseGitHubAppKey parses the PEM encoded private key of a GitHub App, GitHub generates PKCS#1 keys
+func parseGitHubAppKey(appKey string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(appKey))
+	if block == nil {
+		return nil, fmt.Errorf("appKey isn't a PEM encoded private key")
+	}
+	if key, err := x509.ParsePKCS1PrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	key, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing appKey: %s", err)
+	}
+	rsaKey, ok := key.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("appKey isn't an RSA private key")
+	}
+	return rsaKey, nil
+}
+
+// IsActive returns true if the queued jobs are more than the activation target
+func (s *githubRunnerScaler) IsActive(ctx context.Context) (bool, error) {
+	queueLength, err := s.getWorkflowQueueLength(ctx)
+	if err != nil {
+		githubRunnerLog.Error(err, "error getting workflow queue length", "owner", s.metadata.owner)
+		return false, err
+	}
+
+	return queueLength > s.metadata.activationTargetWorkflowQueueLength, nil
+}
+
+func (s *githubRunnerScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *githubRunnerScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetQueueLengthQty := resource.NewQuantity(s.metadata.targetWorkflowQueueLength, resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(fmt.Sprintf("github-runner-%s", s.metadata.owner))),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetQueueLengthQty,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{External: externalMetric, Type: externalMetricType}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// GetMetrics returns the number of queued jobs the runners can run
+func (s *githubRunnerScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	queueLength, err := s.getWorkflowQueueLength(ctx)
+	if err != nil {
+		githubRunnerLog.Error(err, "error getting workflow queue length", "owner", s.metadata.owner)
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewQuantity(queueLength, resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
+
+// getWorkflowQueueLength counts the queued jobs of the queued and in progress workflow runs of the repositories
+func (s *githubRunnerScaler) getWorkflowQueueLength(ctx context.Context) (int64, error) {
+	repos := s.metadata.repos
+	if len(repos) == 0 {
+		var err error
+		repos, err = s.getOrganizationRepos(ctx)
+		if err != nil {
+			return -1, err
+		}
+	}
+
+	var queueLength int64
+	for _, repo := range repos {
+		for _, status := range []string{"queued", "in_progress"} {
+			var runs githubWorkflowRuns
+			url := fmt.Sprintf("%s/repos/%s/%s/actions/runs?status=%s&per_page=%d", s.metadata.githubAPIURL, s.metadata.owner, repo, status, githubPageSize)
+			if err := s.getGitHubAPI(ctx, url, &runs); err != nil {
+				return -1, err
+			}
+
+			for _, run := range runs.WorkflowRuns {
+				var jobs githubWorkflowJobs
+				url := fmt.Sprintf("%s/repos/%s/%s/actions/runs/%d/jobs?filter=latest&per_page=%d", s.metadata.githubAPIURL, s.metadata.owner, repo, run.ID, githubPageSize)
+				if err := s.getGitHubAPI(ctx, url, &jobs); err != nil {
+					return -1, err
+				}
+				for _, job := range jobs.Jobs {
+					if job.Status == "queued" && s.canRunnersRunJob(job.Labels) {
+						queueLength++
+					}
+				}
+			}
+		}
+	}
+	return queueLength, nil
+}
+
+// getOrganizationRepos returns the repositories of the organization which aren't archived
+func (s *githubRunnerScaler) getOrganizationRepos(ctx context.Context) ([]string, error) {
+	var repos []string
+	for page := 1; ; page++ {
+		var result []githubRepository
+		url := fmt.Sprintf("%s/orgs/%s/repos?per_page=%d&page=%d", s.metadata.githubAPIURL, s.metadata.owner, githubPageSize, page)
+		if err := s.getGitHubAPI(ctx, url, &result); err != nil {
+			return nil, err
+		}
+		for _, repo := range result {
+			if !repo.Archived {
+				repos = append(repos, repo.Name)
+			}
+		}
+		if len(result) < githubPageSize {
+			return repos, nil
+		}
+	}
+}
+
+// canRunnersRunJob returns true if the runners have all labels the job requests, labels are case insensitive.
+// Without labels all queued jobs are counted
+func (s *githubRunnerScaler) canRunnersRunJob(jobLabels []string) bool {
+	if len(s.metadata.labels) == 0 {
+		return true
+	}
+	for _, jobLabel := range jobLabels {
+		found := false
+		for _, label := range s.metadata.labels {
+			if strings.EqualFold(jobLabel, label) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *githubRunnerScaler) getGitHubAPI(ctx context.Context, url string, result interface{}) error {
+	token, err := s.getToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("Authorization", "token "+token)
+
+	return s.doGitHubRequest(req, url, result)
+}
+
+func (s *githubRunnerScaler) doGitHubRequest(req *http.Request, url string, result interface{}) error {
+	r, err := s.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer r.Body.Close()
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+	if r.StatusCode < 200 || r.StatusCode > 299 {
+		return fmt.Errorf("the GitHub REST API returned error. url: %s status: %d response: %s", url, r.StatusCode, string(b))
+	}
+
+	return json.Unmarshal(b, result)
+}
+
+// getToken returns the personal access token or a token of the installation of the GitHub App
+func (s *githubRunnerScaler) getToken(ctx context.Context) (string, error) {
+	if s.metadata.personalAccessToken != "" {
+		return s.metadata.personalAccessToken, nil
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.installationToken != "" && time.Now().Add(githubInstallationTokenMargin).Before(s.installationTokenExpiry) {
+		return s.installationToken, nil
+	}
+
+	appToken, err := newGitHubAppJWT(s.metadata.applicationID, s.metadata.appKey, time.Now())
+	if err != nil {
+		return "", err
+	}
+
+	url := fmt.Sprintf("%s/app/installations/%s/access_tokens", s.metadata.githubAPIURL, s.metadata.installationID)
+	req, err := http.NewRequestWithContext(ctx, "POST", url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+	req.Header.Set("Authorization", "Bearer "+appToken)
+
+	var result struct {
+		Token     string    `json:"token"`
+		ExpiresAt time.Time `json:"expires_at"`
+	}
+	if err := s.doGitHubRequest(req, url, &result); err != nil {
+		return "", fmt.Errorf("error getting installation token: %s", err)
+	}
+
+	s.installationToken = result.Token
+	s.installationTokenExpiry = result.ExpiresAt
+	return s.installationToken, nil
+}
+
+// newGitHubAppJWT returns the JWT authenticating as the GitHub App, it is signed with RS256 and valid for 10 minutes,
+// it is issued a minute in the past to allow for clock drift
+func newGitHubAppJWT(applicationID string, key *rsa.PrivateKey, now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]interface{}{
+		"iat": now.Add(-time.Minute).Unix(),
+		"exp": now.Add(9 * time.Minute).Unix(),
+		"iss": applicationID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	hash := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, hash[:])
+	if err != nil {
+		return "", fmt.Errorf("error signing the GitHub App JWT: %s", err)
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
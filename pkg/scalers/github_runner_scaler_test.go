@@ -0,0 +1,229 @@
+package scalers
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+type parseGitHubRunnerMetadataTestData struct {
+	metadata    map[string]string
+	isError     bool
+	resolvedEnv map[string]string
+	authParams  map[string]string
+}
+
+type githubRunnerMetricIdentifier struct {
+	metadataTestData *parseGitHubRunnerMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testGitHubRunnerResolvedEnv = map[string]string{
+	"GITHUB_OWNER": "kedacore",
+}
+
+var testGitHubAppKey = newTestGitHubAppKey()
+
+func newTestGitHubAppKey() string {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		panic(err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}))
+}
+
+var testGitHubRunnerMetadata = []parseGitHubRunnerMetadataTestData{
+	// nothing passed
+	{map[string]string{}, true, testGitHubRunnerResolvedEnv, map[string]string{}},
+	// properly formed with a personal access token
+	{map[string]string{"owner": "kedacore", "repos": "keda", "labels": "self-hosted,linux"}, false, testGitHubRunnerResolvedEnv, map[string]string{"personalAccessToken": "pat"}},
+	// organization scope with GitHub App
+	{map[string]string{"ownerFromEnv": "GITHUB_OWNER", "runnerScope": "org", "applicationID": "1234", "installationID": "5678", "targetWorkflowQueueLength": "2", "activationTargetWorkflowQueueLength": "1"}, false, testGitHubRunnerResolvedEnv, map[string]string{"appKey": testGitHubAppKey}},
+	// GitHub Enterprise Server
+	{map[string]string{"githubAPIURL": "https://github.example.com/api/v3/", "owner": "kedacore", "repos": "keda, http-add-on"}, false, testGitHubRunnerResolvedEnv, map[string]string{"personalAccessToken": "pat"}},
+	// missing owner
+	{map[string]string{"repos": "keda"}, true, testGitHubRunnerResolvedEnv, map[string]string{"personalAccessToken": "pat"}},
+	// repo scope without repos
+	{map[string]string{"owner": "kedacore"}, true, testGitHubRunnerResolvedEnv, map[string]string{"personalAccessToken": "pat"}},
+	// invalid runnerScope
+	{map[string]string{"owner": "kedacore", "runnerScope": "enterprise"}, true, testGitHubRunnerResolvedEnv, map[string]string{"personalAccessToken": "pat"}},
+	// invalid targetWorkflowQueueLength
+	{map[string]string{"owner": "kedacore", "repos": "keda", "targetWorkflowQueueLength": "a"}, true, testGitHubRunnerResolvedEnv, map[string]string{"personalAccessToken": "pat"}},
+	// invalid activationTargetWorkflowQueueLength
+	{map[string]string{"owner": "kedacore", "repos": "keda", "activationTargetWorkflowQueueLength": "a"}, true, testGitHubRunnerResolvedEnv, map[string]string{"personalAccessToken": "pat"}},
+	// no auth
+	{map[string]string{"owner": "kedacore", "repos": "keda"}, true, testGitHubRunnerResolvedEnv, map[string]string{}},
+	// invalid appKey
+	{map[string]string{"owner": "kedacore", "repos": "keda", "applicationID": "1234", "installationID": "5678"}, true, testGitHubRunnerResolvedEnv, map[string]string{"appKey": "key"}},
+	// GitHub App without installationID
+	{map[string]string{"owner": "kedacore", "repos": "keda", "applicationID": "1234"}, true, testGitHubRunnerResolvedEnv, map[string]string{"appKey": testGitHubAppKey}},
+}
+
+var githubRunnerMetricIdentifiers = []githubRunnerMetricIdentifier{
+	{&testGitHubRunnerMetadata[1], 0, "s0-github-runner-kedacore"},
+	{&testGitHubRunnerMetadata[2], 1, "s1-github-runner-kedacore"},
+}
+
+func TestParseGitHubRunnerMetadata(t *testing.T) {
+	for _, testData := range testGitHubRunnerMetadata {
+		_, err := parseGitHubRunnerMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, ResolvedEnv: testData.resolvedEnv, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Errorf("Expected success but got error %s for metadata %v", err, testData.metadata)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Expected error but got success for metadata %v", testData.metadata)
+		}
+	}
+}
+
+func TestGitHubRunnerGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range githubRunnerMetricIdentifiers {
+		meta, err := parseGitHubRunnerMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, ResolvedEnv: testData.metadataTestData.resolvedEnv, AuthParams: testData.metadataTestData.authParams, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockGitHubRunnerScaler := githubRunnerScaler{metadata: meta, httpClient: http.DefaultClient}
+
+		metricSpec := mockGitHubRunnerScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Errorf("Wrong External metric source name: %s, expected: %s", metricName, testData.name)
+		}
+	}
+}
+
+func newTestGitHubServer(t *testing.T, token string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token "+token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/orgs/kedacore/repos":
+			fmt.Fprint(w, `[{"name":"keda"},{"name":"old","archived":true}]`)
+		case "/repos/kedacore/keda/actions/runs":
+			switch r.URL.Query().Get("status") {
+			case "queued":
+				fmt.Fprint(w, `{"total_count":1,"workflow_runs":[{"id":1}]}`)
+			case "in_progress":
+				fmt.Fprint(w, `{"total_count":1,"workflow_runs":[{"id":2}]}`)
+			default:
+				w.WriteHeader(http.StatusBadRequest)
+			}
+		case "/repos/kedacore/keda/actions/runs/1/jobs":
+			fmt.Fprint(w, `{"total_count":2,"jobs":[{"id":11,"status":"queued","labels":["self-hosted","linux"]},{"id":12,"status":"queued","labels":["ubuntu-latest"]}]}`)
+		case "/repos/kedacore/keda/actions/runs/2/jobs":
+			fmt.Fprint(w, `{"total_count":2,"jobs":[{"id":21,"status":"in_progress","labels":["self-hosted","linux"]},{"id":22,"status":"queued","labels":["self-hosted","Linux"]}]}`)
+		case "/repos/kedacore/old/actions/runs":
+			t.Error("runs of archived repositories shouldn't be requested")
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+}
+
+func TestGitHubRunnerGetWorkflowQueueLength(t *testing.T) {
+	server := newTestGitHubServer(t, "pat")
+	defer server.Close()
+
+	tests := []struct {
+		metadata map[string]string
+		expected int64
+	}{
+		{map[string]string{"owner": "kedacore", "repos": "keda"}, 3},
+		{map[string]string{"owner": "kedacore", "repos": "keda", "labels": "self-hosted,linux"}, 2},
+		{map[string]string{"owner": "kedacore", "runnerScope": "org", "labels": "self-hosted,linux,gpu"}, 2},
+		{map[string]string{"owner": "kedacore", "repos": "keda", "labels": "self-hosted"}, 0},
+	}
+	for _, test := range tests {
+		test.metadata["githubAPIURL"] = server.URL
+		meta, err := parseGitHubRunnerMetadata(&ScalerConfig{TriggerMetadata: test.metadata, AuthParams: map[string]string{"personalAccessToken": "pat"}})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		scaler := githubRunnerScaler{metadata: meta, httpClient: http.DefaultClient}
+
+		queueLength, err := scaler.getWorkflowQueueLength(context.Background())
+		if err != nil {
+			t.Errorf("Expected success but got error %s for %v", err, test.metadata)
+		}
+		if queueLength != test.expected {
+			t.Errorf("Expected %d, got %d for %v", test.expected, queueLength, test.metadata)
+		}
+	}
+}
+
+func TestGitHubRunnerAppInstallationToken(t *testing.T) {
+	appKey, err := parseGitHubAppKey(testGitHubAppKey)
+	if err != nil {
+		t.Fatal("Could not parse key:", err)
+	}
+
+	tokenRequests := 0
+	api := newTestGitHubServer(t, "installation-token")
+	defer api.Close()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/app/installations/5678/access_tokens" {
+			api.Config.Handler.ServeHTTP(w, r)
+			return
+		}
+		tokenRequests++
+		parts := strings.Split(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "), ".")
+		if r.Method != "POST" || len(parts) != 3 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		signature, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		hash := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		if err := rsa.VerifyPKCS1v15(&appKey.PublicKey, crypto.SHA256, hash[:], signature); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		claims, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		var jwt struct {
+			Issuer string `json:"iss"`
+		}
+		if err := json.Unmarshal(claims, &jwt); err != nil || jwt.Issuer != "1234" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprintf(w, `{"token":"installation-token","expires_at":"%s"}`, time.Now().Add(time.Hour).UTC().Format(time.RFC3339))
+	}))
+	defer server.Close()
+
+	meta, err := parseGitHubRunnerMetadata(&ScalerConfig{
+		TriggerMetadata: map[string]string{"githubAPIURL": server.URL, "owner": "kedacore", "repos": "keda", "applicationID": "1234", "installationID": "5678"},
+		AuthParams:      map[string]string{"appKey": testGitHubAppKey},
+	})
+	if err != nil {
+		t.Fatal("Could not parse metadata:", err)
+	}
+	scaler := githubRunnerScaler{metadata: meta, httpClient: http.DefaultClient}
+
+	for i := 0; i < 2; i++ {
+		queueLength, err := scaler.getWorkflowQueueLength(context.Background())
+		if err != nil {
+			t.Fatalf("Expected success but got error: %s", err)
+		}
+		if queueLength != 3 {
+			t.Errorf("Expected 3, got %d", queueLength)
+		}
+	}
+	if tokenRequests != 1 {
+		t.Errorf("Expected the installation token to be requested once, got %d", tokenRequests)
+	}
+}
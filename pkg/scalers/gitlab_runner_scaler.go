@@ -0,0 +1,331 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	defaultGitlabAPIURL            = "https://gitlab.com"
+	defaultGitlabTargetQueueLength = 1
+	gitlabPageSize                 = 100
+)
+
+// gitlabJobScopes are the statuses of the jobs which need a runner
+var gitlabJobScopes = map[string]bool{"pending": true, "running": true}
+
+type gitlabRunnerScaler struct {
+	metadata   *gitlabRunnerMetadata
+	httpClient *http.Client
+}
+
+type gitlabRunnerMetadata struct {
+	gitlabAPIURL string
+	// either the jobs of a project or of the projects of a group and its subgroups are counted
+	projectID string
+	groupID   string
+	scopes    []string
+	// tags are the tags of the runners, only the jobs they can pick are counted
+	tags                        []string
+	runUntagged                 bool
+	targetQueueLength           int64
+	activationTargetQueueLength int64
+	accessToken                 string
+	scalerIndex                 int
+}
+
+type gitlabJob struct {
+	ID      int64    `json:"id"`
+	Status  string   `json:"status"`
+	TagList []string `json:"tag_list"`
+}
+
+type gitlabProject struct {
+	ID int64 `json:"id"`
+}
+
+var gitlabRunnerLog = logf.Log.WithName("gitlab_runner_scaler")
+
+// NewGitLabRunnerScaler creates a new gitlabRunnerScaler
+func NewGitLabRunnerScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseGitLabRunnerMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing gitlab runner metadata: %s", err)
+	}
+
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gitlabRunnerScaler{
+		metadata:   meta,
+		httpClient: httpClient,
+	}, nil
+}
+
+func parseGitLabRunnerMetadata(config *ScalerConfig) (*gitlabRunnerMetadata, error) {
+	meta := gitlabRunnerMetadata{
+		gitlabAPIURL:      defaultGitlabAPIURL,
+		scopes:            []string{"pending", "running"},
+		targetQueueLength: defaultGitlabTargetQueueLength,
+	}
+
+	if val, ok := config.TriggerMetadata["gitlabAPIURL"]; ok && val != "" {
+		if _, err := url.ParseRequestURI(val); err != nil {
+			return nil, fmt.Errorf("error parsing gitlabAPIURL: %s", err)
+		}
+		meta.gitlabAPIURL = strings.TrimSuffix(val, "/")
+	}
+
+	meta.projectID = config.TriggerMetadata["projectID"]
+	meta.groupID = config.TriggerMetadata["groupID"]
+	switch {
+	case meta.projectID == "" && meta.groupID == "":
+		return nil, fmt.Errorf("no projectID or groupID given")
+	case meta.projectID != "" && meta.groupID != "":
+		return nil, fmt.Errorf("either projectID or groupID can be given")
+	}
+
+	if val, ok := config.TriggerMetadata["scope"]; ok && val != "" {
+		meta.scopes = nil
+		for _, scope := range splitAndTrimBySep(val, ",") {
+			if !gitlabJobScopes[scope] {
+				return nil, fmt.Errorf("scope must be pending and/or running, got %s", scope)
+			}
+			meta.scopes = append(meta.scopes, scope)
+		}
+	}
+
+	if val, ok := config.TriggerMetadata["tags"]; ok && val != "" {
+		for _, tag := range splitAndTrimBySep(val, ",") {
+			if tag != "" {
+				meta.tags = append(meta.tags, tag)
+			}
+		}
+	}
+
+	// like the run_untagged setting of a runner, runners without tags pick untagged jobs by default
+	meta.runUntagged = len(meta.tags) == 0
+	if val, ok := config.TriggerMetadata["runUntagged"]; ok && val != "" {
+		runUntagged, err := strconv.ParseBool(val)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing runUntagged: %s", err)
+		}
+		meta.runUntagged = runUntagged
+	}
+
+	if val, ok := config.TriggerMetadata["targetQueueLength"]; ok && val != "" {
+		queueLength, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing targetQueueLength: %s", err)
+		}
+		meta.targetQueueLength = queueLength
+	}
+
+	if val, ok := config.TriggerMetadata["activationTargetQueueLength"]; ok && val != "" {
+		queueLength, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing activationTargetQueueLength: %s", err)
+		}
+		meta.activationTargetQueueLength = queueLength
+	}
+
+	// accessToken is a project, group or personal access token with the read_api scope
+	if val, ok := config.AuthParams["accessToken"]; ok && val != "" {
+		meta.accessToken = val
+	} else if val, ok := config.TriggerMetadata["accessTokenFromEnv"]; ok && val != "" {
+		meta.accessToken = config.ResolvedEnv[val]
+	}
+	if meta.accessToken == "" {
+		return nil, fmt.Errorf("no accessToken given")
+	}
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+// IsActive returns true if the jobs for the runners are more than the activation target
+func (s *gitlabRunnerScaler) IsActive(ctx context.Context) (bool, error) {
+	queueLength, err := s.getQueueLength(ctx)
+	if err != nil {
+		gitlabRunnerLog.Error(err, "error getting gitlab job queue length")
+		return false, err
+	}
+
+	return queueLength > s.metadata.activationTargetQueueLength, nil
+}
+
+func (s *gitlabRunnerScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *gitlabRunnerScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	name := fmt.Sprintf("gitlab-runner-project-%s", s.metadata.projectID)
+	if s.metadata.groupID != "" {
+		name = fmt.Sprintf("gitlab-runner-group-%s", s.metadata.groupID)
+	}
+	targetQueueLengthQty := resource.NewQuantity(s.metadata.targetQueueLength, resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(name)),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetQueueLengthQty,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{External: externalMetric, Type: externalMetricType}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// GetMetrics returns the number of jobs the runners can pick
+func (s *gitlabRunnerScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	queueLength, err := s.getQueueLength(ctx)
+	if err != nil {
+		gitlabRunnerLog.Error(err, "error getting gitlab job queue length")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewQuantity(queueLength, resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
+
+// getQueueLength counts the jobs of the project, or of the projects of the group, the runners can pick
+func (s *gitlabRunnerScaler) getQueueLength(ctx context.Context) (int64, error) {
+	projectIDs := []string{s.metadata.projectID}
+	if s.metadata.groupID != "" {
+		var err error
+		projectIDs, err = s.getGroupProjects(ctx)
+		if err != nil {
+			return -1, err
+		}
+	}
+
+	scopes := url.Values{}
+	for _, scope := range s.metadata.scopes {
+		scopes.Add("scope[]", scope)
+	}
+
+	var queueLength int64
+	for _, projectID := range projectIDs {
+		path := fmt.Sprintf("/projects/%s/jobs?%s", url.PathEscape(projectID), scopes.Encode())
+		err := s.forEachPage(ctx, path, func(b []byte) (int, error) {
+			var jobs []gitlabJob
+			if err := json.Unmarshal(b, &jobs); err != nil {
+				return 0, err
+			}
+			for _, job := range jobs {
+				if s.canRunnersPickJob(job.TagList) {
+					queueLength++
+				}
+			}
+			return len(jobs), nil
+		})
+		if err != nil {
+			return -1, err
+		}
+	}
+	return queueLength, nil
+}
+
+// getGroupProjects returns the ids of the projects of the group and its subgroups which aren't archived
+func (s *gitlabRunnerScaler) getGroupProjects(ctx context.Context) ([]string, error) {
+	var projectIDs []string
+	path := fmt.Sprintf("/groups/%s/projects?include_subgroups=true&archived=false&simple=true", url.PathEscape(s.metadata.groupID))
+	err := s.forEachPage(ctx, path, func(b []byte) (int, error) {
+		var projects []gitlabProject
+		if err := json.Unmarshal(b, &projects); err != nil {
+			return 0, err
+		}
+		for _, project := range projects {
+			projectIDs = append(projectIDs, strconv.FormatInt(project.ID, 10))
+		}
+		return len(projects), nil
+	})
+	return projectIDs, err
+}
+
+// canRunnersPickJob returns true if the runners have all tags of the job, untagged jobs are picked with runUntagged
+func (s *gitlabRunnerScaler) canRunnersPickJob(jobTags []string) bool {
+	if len(jobTags) == 0 {
+		return s.metadata.runUntagged
+	}
+	for _, jobTag := range jobTags {
+		found := false
+		for _, tag := range s.metadata.tags {
+			if jobTag == tag {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// forEachPage requests the pages of a list of the REST API, handle returns the number of items of a page
+func (s *gitlabRunnerScaler) forEachPage(ctx context.Context, path string, handle func([]byte) (int, error)) error {
+	for page := 1; page > 0; {
+		url := fmt.Sprintf("%s/api/v4%s&per_page=%d&page=%d", s.metadata.gitlabAPIURL, path, gitlabPageSize, page)
+		req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Set("PRIVATE-TOKEN", s.metadata.accessToken)
+
+		r, err := s.httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		b, err := ioutil.ReadAll(r.Body)
+		r.Body.Close()
+		if err != nil {
+			return err
+		}
+		if r.StatusCode != http.StatusOK {
+			return fmt.Errorf("the GitLab REST API returned error. url: %s status: %d response: %s", url, r.StatusCode, string(b))
+		}
+
+		count, err := handle(b)
+		if err != nil {
+			return fmt.Errorf("error decoding the GitLab REST API response of %s: %s", url, err)
+		}
+
+		// X-Next-Page is empty on the last page, it isn't sent for lists with too many items to be counted
+		if next := r.Header.Get("X-Next-Page"); next != "" {
+			page, err = strconv.Atoi(next)
+			if err != nil {
+				return fmt.Errorf("error parsing X-Next-Page of %s: %s", url, err)
+			}
+		} else if r.Header.Get("X-Total-Pages") == "" && count == gitlabPageSize {
+			page++
+		} else {
+			page = 0
+		}
+	}
+	return nil
+}
@@ -0,0 +1,152 @@
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type parseGitLabRunnerMetadataTestData struct {
+	metadata    map[string]string
+	isError     bool
+	resolvedEnv map[string]string
+	authParams  map[string]string
+}
+
+type gitlabRunnerMetricIdentifier struct {
+	metadataTestData *parseGitLabRunnerMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testGitLabRunnerResolvedEnv = map[string]string{
+	"GITLAB_TOKEN": "glpat-sample",
+}
+
+var testGitLabRunnerMetadata = []parseGitLabRunnerMetadataTestData{
+	// nothing passed
+	{map[string]string{}, true, testGitLabRunnerResolvedEnv, map[string]string{}},
+	// project with token in TriggerAuthentication
+	{map[string]string{"projectID": "42", "tags": "docker, linux"}, false, testGitLabRunnerResolvedEnv, map[string]string{"accessToken": "glpat-sample"}},
+	// group on a self-managed instance with token from env
+	{map[string]string{"gitlabAPIURL": "https://gitlab.example.com/", "groupID": "platform/ci", "scope": "pending", "runUntagged": "true", "targetQueueLength": "2", "activationTargetQueueLength": "1", "accessTokenFromEnv": "GITLAB_TOKEN"}, false, testGitLabRunnerResolvedEnv, map[string]string{}},
+	// projectID and groupID
+	{map[string]string{"projectID": "42", "groupID": "7"}, true, testGitLabRunnerResolvedEnv, map[string]string{"accessToken": "glpat-sample"}},
+	// invalid gitlabAPIURL
+	{map[string]string{"gitlabAPIURL": "gitlab", "projectID": "42"}, true, testGitLabRunnerResolvedEnv, map[string]string{"accessToken": "glpat-sample"}},
+	// invalid scope
+	{map[string]string{"projectID": "42", "scope": "failed"}, true, testGitLabRunnerResolvedEnv, map[string]string{"accessToken": "glpat-sample"}},
+	// invalid runUntagged
+	{map[string]string{"projectID": "42", "runUntagged": "a"}, true, testGitLabRunnerResolvedEnv, map[string]string{"accessToken": "glpat-sample"}},
+	// invalid targetQueueLength
+	{map[string]string{"projectID": "42", "targetQueueLength": "a"}, true, testGitLabRunnerResolvedEnv, map[string]string{"accessToken": "glpat-sample"}},
+	// invalid activationTargetQueueLength
+	{map[string]string{"projectID": "42", "activationTargetQueueLength": "a"}, true, testGitLabRunnerResolvedEnv, map[string]string{"accessToken": "glpat-sample"}},
+	// missing accessToken
+	{map[string]string{"projectID": "42"}, true, testGitLabRunnerResolvedEnv, map[string]string{}},
+}
+
+var gitlabRunnerMetricIdentifiers = []gitlabRunnerMetricIdentifier{
+	{&testGitLabRunnerMetadata[1], 0, "s0-gitlab-runner-project-42"},
+	{&testGitLabRunnerMetadata[2], 1, "s1-gitlab-runner-group-platform-ci"},
+}
+
+func TestParseGitLabRunnerMetadata(t *testing.T) {
+	for _, testData := range testGitLabRunnerMetadata {
+		_, err := parseGitLabRunnerMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, ResolvedEnv: testData.resolvedEnv, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Errorf("Expected success but got error %s for metadata %v", err, testData.metadata)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Expected error but got success for metadata %v", testData.metadata)
+		}
+	}
+}
+
+func TestGitLabRunnerGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range gitlabRunnerMetricIdentifiers {
+		meta, err := parseGitLabRunnerMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, ResolvedEnv: testData.metadataTestData.resolvedEnv, AuthParams: testData.metadataTestData.authParams, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockGitLabRunnerScaler := gitlabRunnerScaler{metadata: meta, httpClient: http.DefaultClient}
+
+		metricSpec := mockGitLabRunnerScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Errorf("Wrong External metric source name: %s, expected: %s", metricName, testData.name)
+		}
+	}
+}
+
+func TestGitLabRunnerGetQueueLength(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "glpat-sample" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		query := r.URL.Query()
+		switch r.URL.EscapedPath() {
+		case "/api/v4/groups/platform%2Fci/projects":
+			if query.Get("include_subgroups") != "true" {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			fmt.Fprint(w, `[{"id":42},{"id":43}]`)
+		case "/api/v4/projects/42/jobs":
+			scopes := query["scope[]"]
+			// two pages of pending and running jobs
+			if query.Get("page") == "1" {
+				w.Header().Set("X-Next-Page", "2")
+				w.Header().Set("X-Total-Pages", "2")
+				if len(scopes) == 2 {
+					fmt.Fprint(w, `[{"id":1,"status":"pending","tag_list":["docker"]},{"id":2,"status":"pending","tag_list":[]}]`)
+				} else {
+					fmt.Fprint(w, `[{"id":1,"status":"pending","tag_list":["docker"]}]`)
+				}
+				return
+			}
+			w.Header().Set("X-Next-Page", "")
+			w.Header().Set("X-Total-Pages", "2")
+			if len(scopes) == 2 {
+				fmt.Fprint(w, `[{"id":3,"status":"running","tag_list":["docker","linux"]},{"id":4,"status":"running","tag_list":["windows"]}]`)
+			} else {
+				fmt.Fprint(w, `[{"id":5,"status":"pending","tag_list":["docker","gpu"]}]`)
+			}
+		case "/api/v4/projects/43/jobs":
+			fmt.Fprint(w, `[{"id":6,"status":"pending","tag_list":["linux"]}]`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		metadata map[string]string
+		expected int64
+	}{
+		{map[string]string{"projectID": "42"}, 1},
+		{map[string]string{"projectID": "42", "tags": "docker,linux"}, 2},
+		{map[string]string{"projectID": "42", "tags": "docker,linux", "runUntagged": "true"}, 3},
+		{map[string]string{"projectID": "42", "tags": "docker,gpu", "scope": "pending"}, 2},
+		{map[string]string{"groupID": "platform/ci", "tags": "docker,linux"}, 3},
+	}
+	for _, test := range tests {
+		test.metadata["gitlabAPIURL"] = server.URL
+		meta, err := parseGitLabRunnerMetadata(&ScalerConfig{TriggerMetadata: test.metadata, AuthParams: map[string]string{"accessToken": "glpat-sample"}})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		scaler := gitlabRunnerScaler{metadata: meta, httpClient: http.DefaultClient}
+
+		queueLength, err := scaler.getQueueLength(context.Background())
+		if err != nil {
+			t.Errorf("Expected success but got error %s for %v", err, test.metadata)
+		}
+		if queueLength != test.expected {
+			t.Errorf("Expected %d, got %d for %v", test.expected, queueLength, test.metadata)
+		}
+	}
+}
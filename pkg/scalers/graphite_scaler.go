@@ -25,6 +25,7 @@ const (
 	grapQuery         = "query"
 	grapThreshold     = "threshold"
 	grapQueryTime     = "queryTime"
+	grapQueryUntil    = "until"
 )
 
 type graphiteScaler struct {
@@ -38,6 +39,7 @@ type graphiteMetadata struct {
 	query         string
 	threshold     int
 	from          string
+	until         string
 
 	// basic auth
 	enableBasicAuth bool
@@ -49,7 +51,7 @@ type graphiteMetadata struct {
 type grapQueryResult []struct {
 	Target     string                 `json:"target"`
 	Tags       map[string]interface{} `json:"tags"`
-	Datapoints [][]float64            `json:"datapoints"`
+	Datapoints [][]*float64           `json:"datapoints"`
 }
 
 var graphiteLog = logf.Log.WithName("graphite_scaler")
@@ -61,7 +63,10 @@ func NewGraphiteScaler(config *ScalerConfig) (Scaler, error) {
 		return nil, fmt.Errorf("error parsing graphite metadata: %s", err)
 	}
 
-	httpClient := kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, false)
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
 
 	return &graphiteScaler{
 		metadata:   meta,
@@ -96,6 +101,9 @@ func parseGraphiteMetadata(config *ScalerConfig) (*graphiteMetadata, error) {
 		return nil, fmt.Errorf("no %s given", grapQueryTime)
 	}
 
+	// until is optional, graphite renders up to now by default
+	meta.until = config.TriggerMetadata[grapQueryUntil]
+
 	if val, ok := config.TriggerMetadata[grapThreshold]; ok && val != "" {
 		t, err := strconv.Atoi(val)
 		if err != nil {
@@ -162,7 +170,10 @@ func (s *graphiteScaler) GetMetricSpecForScaling(context.Context) []v2beta2.Metr
 
 func (s *graphiteScaler) ExecuteGrapQuery(ctx context.Context) (float64, error) {
 	queryEscaped := url_pkg.QueryEscape(s.metadata.query)
-	url := fmt.Sprintf("%s/render?from=%s&target=%s&format=json", s.metadata.serverAddress, s.metadata.from, queryEscaped)
+	url := fmt.Sprintf("%s/render?from=%s&target=%s&format=json", s.metadata.serverAddress, url_pkg.QueryEscape(s.metadata.from), queryEscaped)
+	if s.metadata.until != "" {
+		url += "&until=" + url_pkg.QueryEscape(s.metadata.until)
+	}
 	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
 		return -1, err
@@ -181,6 +192,10 @@ func (s *graphiteScaler) ExecuteGrapQuery(ctx context.Context) (float64, error)
 	}
 	r.Body.Close()
 
+	if !(r.StatusCode >= 200 && r.StatusCode <= 299) {
+		return -1, fmt.Errorf("graphite render api returned error. status: %d response: %s", r.StatusCode, string(b))
+	}
+
 	var result grapQueryResult
 	err = json.Unmarshal(b, &result)
 	if err != nil {
@@ -194,14 +209,16 @@ func (s *graphiteScaler) ExecuteGrapQuery(ctx context.Context) (float64, error)
 	}
 
 	// https://graphite-api.readthedocs.io/en/latest/api.html#json
-	if len(result[0].Datapoints) == 0 {
-		return 0, nil
+	// datapoints are [value, timestamp] pairs in time order, the value of the buckets without data
+	// is null, which is usually the case for the latest bucket that is still being written
+	datapoints := result[0].Datapoints
+	for i := len(datapoints) - 1; i >= 0; i-- {
+		if len(datapoints[i]) > 0 && datapoints[i][0] != nil {
+			return *datapoints[i][0], nil
+		}
 	}
 
-	latestDatapoint := len(result[0].Datapoints) - 1
-	datapoint := result[0].Datapoints[latestDatapoint][0]
-
-	return datapoint, nil
+	return 0, nil
 }
 
 func (s *graphiteScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
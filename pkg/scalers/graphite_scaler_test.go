@@ -2,8 +2,12 @@ package scalers
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"strings"
 	"testing"
+
+	"github.com/stretchr/testify/assert"
 )
 
 type parseGraphiteMetadataTestData struct {
@@ -31,6 +35,8 @@ var testGrapMetadata = []parseGraphiteMetadataTestData{
 	{map[string]string{"serverAddress": "http://localhost:81", "metricName": "request-count", "threshold": "100", "query": "", "queryTime": "-30Seconds", "disableScaleToZero": "true"}, true},
 	// missing queryTime
 	{map[string]string{"serverAddress": "http://localhost:81", "metricName": "request-count", "threshold": "100", "query": "stats.counters.http.hello-world.request.count.count", "queryTime": ""}, true},
+	// with until
+	{map[string]string{"serverAddress": "http://localhost:81", "metricName": "request-count", "threshold": "100", "query": "stats.counters.http.hello-world.request.count.count", "queryTime": "-5min", "until": "-1min"}, false},
 }
 
 var graphiteMetricIdentifiers = []graphiteMetricIdentifier{
@@ -102,3 +108,103 @@ func TestGraphiteScalerAuthParams(t *testing.T) {
 		}
 	}
 }
+
+type graphiteQueryResultTestData struct {
+	name           string
+	bodyStr        string
+	responseStatus int
+	expectedValue  float64
+	isError        bool
+}
+
+var testGraphiteQueryResult = []graphiteQueryResultTestData{
+	{
+		name:           "no results",
+		bodyStr:        `[]`,
+		responseStatus: http.StatusOK,
+		expectedValue:  0,
+		isError:        false,
+	},
+	{
+		name:           "no datapoints",
+		bodyStr:        `[{"target":"stats.counters.http.hello-world.request.count.count","datapoints":[]}]`,
+		responseStatus: http.StatusOK,
+		expectedValue:  0,
+		isError:        false,
+	},
+	{
+		name:           "latest datapoint",
+		bodyStr:        `[{"target":"stats.counters.http.hello-world.request.count.count","datapoints":[[1,1637233380],[2,1637233390],[3,1637233400]]}]`,
+		responseStatus: http.StatusOK,
+		expectedValue:  3,
+		isError:        false,
+	},
+	{
+		name:           "latest non-null datapoint",
+		bodyStr:        `[{"target":"stats.counters.http.hello-world.request.count.count","datapoints":[[1,1637233380],[2,1637233390],[null,1637233400]]}]`,
+		responseStatus: http.StatusOK,
+		expectedValue:  2,
+		isError:        false,
+	},
+	{
+		name:           "only null datapoints",
+		bodyStr:        `[{"target":"stats.counters.http.hello-world.request.count.count","datapoints":[[null,1637233390],[null,1637233400]]}]`,
+		responseStatus: http.StatusOK,
+		expectedValue:  0,
+		isError:        false,
+	},
+	{
+		name:           "multiple series",
+		bodyStr:        `[{"target":"a","datapoints":[]},{"target":"b","datapoints":[]}]`,
+		responseStatus: http.StatusOK,
+		expectedValue:  -1,
+		isError:        true,
+	},
+	{
+		name:           "error status response",
+		bodyStr:        `{}`,
+		responseStatus: http.StatusBadRequest,
+		expectedValue:  -1,
+		isError:        true,
+	},
+}
+
+func TestGraphiteScalerExecuteGrapQuery(t *testing.T) {
+	for _, testData := range testGraphiteQueryResult {
+		testData := testData
+		t.Run(testData.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				assert.Equal(t, "/render", request.URL.Path)
+				assert.Equal(t, "stats.counters.http.hello-world.request.count.count", request.URL.Query().Get("target"))
+				assert.Equal(t, "-5min", request.URL.Query().Get("from"))
+				assert.Equal(t, "-1min", request.URL.Query().Get("until"))
+				writer.WriteHeader(testData.responseStatus)
+
+				if _, err := writer.Write([]byte(testData.bodyStr)); err != nil {
+					t.Fatal(err)
+				}
+			}))
+			defer server.Close()
+
+			scaler := graphiteScaler{
+				metadata: &graphiteMetadata{
+					serverAddress: server.URL,
+					query:         "stats.counters.http.hello-world.request.count.count",
+					from:          "-5min",
+					until:         "-1min",
+				},
+				httpClient: http.DefaultClient,
+			}
+
+			value, err := scaler.ExecuteGrapQuery(context.TODO())
+
+			assert.Equal(t, testData.expectedValue, value)
+
+			if testData.isError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
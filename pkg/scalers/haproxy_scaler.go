@@ -0,0 +1,325 @@
+package scalers
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	haproxyMetricQueue    = "queue"
+	haproxyMetricSessions = "sessions"
+
+	// haproxyBackendRow is the svname of the row with the totals of a backend, the other rows are its servers
+	haproxyBackendRow  = "BACKEND"
+	haproxyFrontendRow = "FRONTEND"
+
+	haproxyShowStatCommand = "show stat\n"
+)
+
+type haproxyScaler struct {
+	metadata   *haproxyMetadata
+	httpClient *http.Client
+	timeout    time.Duration
+}
+
+type haproxyMetadata struct {
+	// statsURL is the CSV export of the HTTP stats page, eg. http://haproxy:8404/stats;csv
+	statsURL string
+	// socketNetwork and socketAddress are the stats socket, eg. unix /var/run/haproxy.sock or tcp haproxy:9999
+	socketNetwork         string
+	socketAddress         string
+	backend               string
+	metric                string
+	targetValue           int64
+	activationTargetValue int64
+
+	username string
+	password string
+
+	scalerIndex int
+}
+
+var haproxyLog = logf.Log.WithName("haproxy_scaler")
+
+// NewHAProxyScaler creates a new haproxyScaler
+func NewHAProxyScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseHAProxyMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing haproxy metadata: %s", err)
+	}
+
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &haproxyScaler{
+		metadata:   meta,
+		httpClient: httpClient,
+		timeout:    config.GlobalHTTPTimeout,
+	}, nil
+}
+
+func parseHAProxyMetadata(config *ScalerConfig) (*haproxyMetadata, error) {
+	meta := haproxyMetadata{
+		metric: haproxyMetricQueue,
+	}
+
+	meta.statsURL = config.TriggerMetadata["statsURL"]
+	if val, ok := config.TriggerMetadata["statsSocket"]; ok && val != "" {
+		u, err := url.Parse(val)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing statsSocket: %s", err)
+		}
+		switch u.Scheme {
+		case "unix":
+			meta.socketNetwork, meta.socketAddress = "unix", u.Path
+		case "tcp":
+			meta.socketNetwork, meta.socketAddress = "tcp", u.Host
+		default:
+			return nil, fmt.Errorf("statsSocket must be a unix:// or tcp:// address, got %s", val)
+		}
+		if meta.socketAddress == "" {
+			return nil, fmt.Errorf("statsSocket %s has no address", val)
+		}
+	}
+	if (meta.statsURL == "") == (meta.socketAddress == "") {
+		return nil, fmt.Errorf("exactly one of statsURL or statsSocket must be given")
+	}
+
+	if val, ok := config.TriggerMetadata["backend"]; ok && val != "" {
+		meta.backend = val
+	} else {
+		return nil, fmt.Errorf("no backend given")
+	}
+
+	if val, ok := config.TriggerMetadata["metric"]; ok && val != "" {
+		if val != haproxyMetricQueue && val != haproxyMetricSessions {
+			return nil, fmt.Errorf("metric must be %s or %s, got %s", haproxyMetricQueue, haproxyMetricSessions, val)
+		}
+		meta.metric = val
+	}
+
+	// targetValue is the number of queued requests or current sessions per replica
+	if val, ok := config.TriggerMetadata["targetValue"]; ok && val != "" {
+		targetValue, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing targetValue: %s", err)
+		}
+		meta.targetValue = targetValue
+	} else {
+		return nil, fmt.Errorf("no targetValue given")
+	}
+
+	if val, ok := config.TriggerMetadata["activationTargetValue"]; ok && val != "" {
+		activationTargetValue, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing activationTargetValue: %s", err)
+		}
+		meta.activationTargetValue = activationTargetValue
+	}
+
+	// the stats page can be protected with "stats auth", the socket has no authentication
+	meta.username = config.AuthParams["username"]
+	meta.password = config.AuthParams["password"]
+	if meta.password != "" && meta.username == "" {
+		return nil, fmt.Errorf("username must be provided with password")
+	}
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+// IsActive returns true if the queued requests or sessions of the backend are above the activation target
+func (s *haproxyScaler) IsActive(ctx context.Context) (bool, error) {
+	value, err := s.getBackendValue(ctx)
+	if err != nil {
+		haproxyLog.Error(err, "error getting haproxy backend stats", "backend", s.metadata.backend)
+		return false, err
+	}
+
+	return value > s.metadata.activationTargetValue, nil
+}
+
+func (s *haproxyScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *haproxyScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetMetricValue := resource.NewQuantity(s.metadata.targetValue, resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(fmt.Sprintf("haproxy-%s-%s", s.metadata.metric, s.metadata.backend))),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetMetricValue,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+func (s *haproxyScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	value, err := s.getBackendValue(ctx)
+	if err != nil {
+		haproxyLog.Error(err, "error getting haproxy backend stats", "backend", s.metadata.backend)
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewQuantity(value, resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
+
+// getBackendValue reads the stats CSV from the stats page or socket and returns the value of the backend
+func (s *haproxyScaler) getBackendValue(ctx context.Context) (int64, error) {
+	var stats io.ReadCloser
+	var err error
+	if s.metadata.statsURL != "" {
+		stats, err = s.readStatsPage(ctx)
+	} else {
+		stats, err = s.readStatsSocket(ctx)
+	}
+	if err != nil {
+		return -1, err
+	}
+	defer stats.Close()
+
+	return parseHAProxyStats(stats, s.metadata.backend, s.metadata.metric)
+}
+
+func (s *haproxyScaler) readStatsPage(ctx context.Context) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", s.metadata.statsURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	if s.metadata.username != "" {
+		req.SetBasicAuth(s.metadata.username, s.metadata.password)
+	}
+
+	r, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if r.StatusCode != http.StatusOK {
+		r.Body.Close()
+		return nil, fmt.Errorf("haproxy stats page returned %d", r.StatusCode)
+	}
+	return r.Body, nil
+}
+
+// readStatsSocket runs "show stat" on the stats socket, haproxy writes the CSV and closes the connection
+func (s *haproxyScaler) readStatsSocket(ctx context.Context) (io.ReadCloser, error) {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, s.metadata.socketNetwork, s.metadata.socketAddress)
+	if err != nil {
+		return nil, err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+
+	if _, err := io.WriteString(conn, haproxyShowStatCommand); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
+
+// parseHAProxyStats returns the current sessions of the BACKEND row of the backend for sessions, and for queue
+// the requests queued on the backend without a server assigned plus the ones queued on each of its servers
+func parseHAProxyStats(stats io.Reader, backend string, metric string) (int64, error) {
+	reader := csv.NewReader(stats)
+	reader.FieldsPerRecord = -1
+
+	header, err := reader.Read()
+	if err != nil {
+		return -1, fmt.Errorf("error reading haproxy stats header: %s", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.TrimPrefix(strings.TrimSpace(name), "# ")] = i
+	}
+
+	column := "qcur"
+	if metric == haproxyMetricSessions {
+		column = "scur"
+	}
+	for _, name := range []string{"pxname", "svname", column} {
+		if _, ok := columns[name]; !ok {
+			return -1, fmt.Errorf("haproxy stats have no %s column", name)
+		}
+	}
+
+	var value int64
+	found := false
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return -1, fmt.Errorf("error reading haproxy stats: %s", err)
+		}
+		if len(record) <= columns[column] || record[columns["pxname"]] != backend {
+			continue
+		}
+
+		svname := record[columns["svname"]]
+		if svname == haproxyFrontendRow || (metric == haproxyMetricSessions && svname != haproxyBackendRow) {
+			continue
+		}
+		if svname == haproxyBackendRow {
+			found = true
+		}
+
+		// the columns a proxy doesn't report are empty
+		if record[columns[column]] == "" {
+			continue
+		}
+		v, err := strconv.ParseInt(record[columns[column]], 10, 64)
+		if err != nil {
+			return -1, fmt.Errorf("error parsing haproxy %s of %s/%s: %s", column, backend, svname, err)
+		}
+		value += v
+	}
+
+	if !found {
+		return -1, fmt.Errorf("backend %s not found in haproxy stats", backend)
+	}
+	return value, nil
+}
@@ -0,0 +1,166 @@
+package scalers
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type parseHAProxyMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+}
+
+type haproxyMetricIdentifier struct {
+	metadataTestData *parseHAProxyMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testHAProxyMetadata = []parseHAProxyMetadataTestData{
+	// nothing passed
+	{map[string]string{}, map[string]string{}, true},
+	// queue from the stats page
+	{map[string]string{"statsURL": "http://haproxy:8404/stats;csv", "backend": "web", "targetValue": "10"}, map[string]string{}, false},
+	// sessions from the unix stats socket
+	{map[string]string{"statsSocket": "unix:///var/run/haproxy.sock", "backend": "web", "metric": "sessions", "targetValue": "100", "activationTargetValue": "5"}, map[string]string{}, false},
+	// tcp stats socket
+	{map[string]string{"statsSocket": "tcp://haproxy:9999", "backend": "web", "targetValue": "10"}, map[string]string{}, false},
+	// stats page with basic auth
+	{map[string]string{"statsURL": "http://haproxy:8404/stats;csv", "backend": "web", "targetValue": "10"}, map[string]string{"username": "admin", "password": "secret"}, false},
+	// password without username
+	{map[string]string{"statsURL": "http://haproxy:8404/stats;csv", "backend": "web", "targetValue": "10"}, map[string]string{"password": "secret"}, true},
+	// both statsURL and statsSocket
+	{map[string]string{"statsURL": "http://haproxy:8404/stats;csv", "statsSocket": "tcp://haproxy:9999", "backend": "web", "targetValue": "10"}, map[string]string{}, true},
+	// invalid statsSocket scheme
+	{map[string]string{"statsSocket": "haproxy:9999", "backend": "web", "targetValue": "10"}, map[string]string{}, true},
+	// missing backend
+	{map[string]string{"statsURL": "http://haproxy:8404/stats;csv", "targetValue": "10"}, map[string]string{}, true},
+	// invalid metric
+	{map[string]string{"statsURL": "http://haproxy:8404/stats;csv", "backend": "web", "metric": "rate", "targetValue": "10"}, map[string]string{}, true},
+	// missing targetValue
+	{map[string]string{"statsURL": "http://haproxy:8404/stats;csv", "backend": "web"}, map[string]string{}, true},
+	// invalid activationTargetValue
+	{map[string]string{"statsURL": "http://haproxy:8404/stats;csv", "backend": "web", "targetValue": "10", "activationTargetValue": "a"}, map[string]string{}, true},
+}
+
+var haproxyMetricIdentifiers = []haproxyMetricIdentifier{
+	{&testHAProxyMetadata[1], 0, "s0-haproxy-queue-web"},
+	{&testHAProxyMetadata[2], 1, "s1-haproxy-sessions-web"},
+}
+
+const testHAProxyStats = `# pxname,svname,qcur,qmax,scur,smax,slim,stot,status
+http-in,FRONTEND,,,42,80,2000,1200,OPEN
+web,web1,3,10,20,25,50,600,UP
+web,web2,4,12,18,25,50,590,UP
+web,BACKEND,5,15,38,50,200,1190,UP
+api,api1,0,0,2,4,50,10,UP
+api,BACKEND,0,0,2,4,200,10,UP
+`
+
+func TestParseHAProxyMetadata(t *testing.T) {
+	for _, testData := range testHAProxyMetadata {
+		_, err := parseHAProxyMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Error("Expected success but got error", err)
+		}
+		if testData.isError && err == nil {
+			t.Error("Expected error but got success", testData.metadata)
+		}
+	}
+}
+
+func TestHAProxyGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range haproxyMetricIdentifiers {
+		meta, err := parseHAProxyMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, AuthParams: testData.metadataTestData.authParams, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockHAProxyScaler := haproxyScaler{metadata: meta}
+
+		metricSpec := mockHAProxyScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Error("Wrong External metric source name:", metricName)
+		}
+	}
+}
+
+func TestParseHAProxyStats(t *testing.T) {
+	queue, err := parseHAProxyStats(strings.NewReader(testHAProxyStats), "web", haproxyMetricQueue)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(12), queue)
+
+	sessions, err := parseHAProxyStats(strings.NewReader(testHAProxyStats), "web", haproxyMetricSessions)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(38), sessions)
+
+	_, err = parseHAProxyStats(strings.NewReader(testHAProxyStats), "admin", haproxyMetricQueue)
+	assert.Error(t, err)
+
+	_, err = parseHAProxyStats(strings.NewReader("# pxname,svname\nweb,BACKEND\n"), "web", haproxyMetricQueue)
+	assert.Error(t, err)
+}
+
+func TestHAProxyStatsPage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username, password, ok := r.BasicAuth(); !ok || username != "admin" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(testHAProxyStats))
+	}))
+	defer server.Close()
+
+	meta, err := parseHAProxyMetadata(&ScalerConfig{
+		TriggerMetadata: map[string]string{"statsURL": server.URL + "/stats;csv", "backend": "web", "targetValue": "10", "activationTargetValue": "11"},
+		AuthParams:      map[string]string{"username": "admin", "password": "secret"},
+	})
+	assert.NoError(t, err)
+	scaler := haproxyScaler{metadata: meta, httpClient: server.Client()}
+
+	active, err := scaler.IsActive(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, active)
+
+	scaler.metadata.password = "wrong"
+	_, err = scaler.IsActive(context.Background())
+	assert.Error(t, err)
+}
+
+func TestHAProxyStatsSocket(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer listener.Close()
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		if command, err := bufio.NewReader(conn).ReadString('\n'); err != nil || command != haproxyShowStatCommand {
+			return
+		}
+		_, _ = conn.Write([]byte(testHAProxyStats))
+	}()
+
+	meta, err := parseHAProxyMetadata(&ScalerConfig{
+		TriggerMetadata: map[string]string{"statsSocket": "tcp://" + listener.Addr().String(), "backend": "web", "metric": "sessions", "targetValue": "10"},
+	})
+	assert.NoError(t, err)
+	scaler := haproxyScaler{metadata: meta}
+
+	metrics, err := scaler.GetMetrics(context.Background(), "s0-haproxy-sessions-web", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(38), metrics[0].Value.Value())
+}
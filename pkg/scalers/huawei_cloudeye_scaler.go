@@ -1,3 +1,6 @@
+//go:build !selective_scalers || scalers_huawei
+// +build !selective_scalers scalers_huawei
+
 package scalers
 
 import (
@@ -225,7 +228,7 @@ func gethuaweiAuthorization(authParams map[string]string) (huaweiAuthorizationMe
 }
 
 func (h *huaweiCloudeyeScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
-	metricValue, err := h.GetCloudeyeMetrics()
+	metricValue, err := h.GetCloudeyeMetrics(ctx)
 
 	if err != nil {
 		cloudeyeLog.Error(err, "Error getting metric value")
@@ -257,7 +260,7 @@ func (h *huaweiCloudeyeScaler) GetMetricSpecForScaling(context.Context) []v2beta
 }
 
 func (h *huaweiCloudeyeScaler) IsActive(ctx context.Context) (bool, error) {
-	val, err := h.GetCloudeyeMetrics()
+	val, err := h.GetCloudeyeMetrics(ctx)
 
 	if err != nil {
 		return false, err
@@ -270,7 +273,9 @@ func (h *huaweiCloudeyeScaler) Close(context.Context) error {
 	return nil
 }
 
-func (h *huaweiCloudeyeScaler) GetCloudeyeMetrics() (float64, error) {
+// GetCloudeyeMetrics queries the metric from Cloud Eye, the client doesn't take a context so the deadline of ctx
+// bounds each of its requests
+func (h *huaweiCloudeyeScaler) GetCloudeyeMetrics(ctx context.Context) (float64, error) {
 	options := aksk.AKSKOptions{
 		IdentityEndpoint: h.metadata.huaweiAuthorization.IdentityEndpoint,
 		ProjectID:        h.metadata.huaweiAuthorization.ProjectID,
@@ -282,7 +287,14 @@ func (h *huaweiCloudeyeScaler) GetCloudeyeMetrics() (float64, error) {
 		Cloud:            h.metadata.huaweiAuthorization.Cloud,
 	}
 
-	provider, err := openstack.AuthenticatedClient(options)
+	if err := ctx.Err(); err != nil {
+		return -1, err
+	}
+	conf := gophercloud.NewConfig()
+	if deadline, ok := ctx.Deadline(); ok {
+		conf.Timeout = time.Until(deadline)
+	}
+	provider, err := openstack.AuthenticatedClientWithOptions(options, conf)
 	if err != nil {
 		cloudeyeLog.Error(err, "Failed to get the provider")
 		return -1, err
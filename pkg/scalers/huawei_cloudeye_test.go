@@ -1,3 +1,6 @@
+//go:build !selective_scalers || scalers_huawei
+// +build !selective_scalers scalers_huawei
+
 package scalers
 
 import (
@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	v2beta2 "k8s.io/api/autoscaling/v2beta2"
@@ -23,15 +24,17 @@ import (
 
 // Default variables and settings
 const (
-	ibmMqQueueDepthMetricName = "currentQueueDepth"
-	defaultTargetQueueDepth   = 20
-	defaultTLSDisabled        = false
+	defaultTargetQueueDepth = 20
+	defaultTLSDisabled      = false
+	// ibmMqAdminPath is the path of the MQSC command endpoint of a queue manager on the administrative REST api
+	ibmMqAdminPath = "/ibmmq/rest/v2/admin/action/qmgr/%s/mqsc"
 )
 
 // IBMMQScaler assigns struct data pointer to metadata variable
 type IBMMQScaler struct {
 	metadata           *IBMMQMetadata
 	defaultHTTPTimeout time.Duration
+	tlsConfig          *tls.Config
 }
 
 // IBMMQMetadata Metadata used by KEDA to query IBM MQ queue depth and scale
@@ -42,8 +45,10 @@ type IBMMQMetadata struct {
 	username         string
 	password         string
 	targetQueueDepth int
-	tlsDisabled      bool
-	scalerIndex      int
+	// activationQueueDepth is the depth above which the queue is active
+	activationQueueDepth int
+	tlsDisabled          bool
+	scalerIndex          int
 }
 
 // CommandResponse Full structured response from MQ admin REST query
@@ -53,7 +58,10 @@ type CommandResponse struct {
 
 // Response The body of the response returned from the MQ admin query
 type Response struct {
-	Parameters Parameters `json:"parameters"`
+	CompletionCode int        `json:"completionCode"`
+	ReasonCode     int        `json:"reasonCode"`
+	Message        []string   `json:"message"`
+	Parameters     Parameters `json:"parameters"`
 }
 
 // Parameters Contains the current depth of the IBM MQ Queue
@@ -68,9 +76,17 @@ func NewIBMMQScaler(config *ScalerConfig) (Scaler, error) {
 		return nil, fmt.Errorf("error parsing IBM MQ metadata: %s", err)
 	}
 
+	tlsConfig, err := createTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	// tls: true skips the verification of the server certificate like unsafeSsl
+	tlsConfig.InsecureSkipVerify = tlsConfig.InsecureSkipVerify || meta.tlsDisabled
+
 	return &IBMMQScaler{
 		metadata:           meta,
 		defaultHTTPTimeout: config.GlobalHTTPTimeout,
+		tlsConfig:          tlsConfig,
 	}, nil
 }
 
@@ -83,11 +99,13 @@ func (s *IBMMQScaler) Close(context.Context) error {
 func parseIBMMQMetadata(config *ScalerConfig) (*IBMMQMetadata, error) {
 	meta := IBMMQMetadata{}
 
+	var host *url.URL
 	if val, ok := config.TriggerMetadata["host"]; ok {
-		_, err := url.ParseRequestURI(val)
+		u, err := url.ParseRequestURI(val)
 		if err != nil {
 			return nil, fmt.Errorf("invalid URL: %s", err)
 		}
+		host = u
 		meta.host = val
 	} else {
 		return nil, fmt.Errorf("no host URI given")
@@ -99,6 +117,11 @@ func parseIBMMQMetadata(config *ScalerConfig) (*IBMMQMetadata, error) {
 		return nil, fmt.Errorf("no queue manager given")
 	}
 
+	// host is either the MQSC endpoint of the queue manager or the base URL of the REST api
+	if strings.Trim(host.Path, "/") == "" {
+		meta.host = strings.TrimSuffix(meta.host, "/") + fmt.Sprintf(ibmMqAdminPath, url.PathEscape(meta.queueManager))
+	}
+
 	if val, ok := config.TriggerMetadata["queueName"]; ok {
 		meta.queueName = val
 	} else {
@@ -112,10 +135,17 @@ func parseIBMMQMetadata(config *ScalerConfig) (*IBMMQMetadata, error) {
 		}
 		meta.targetQueueDepth = queueDepth
 	} else {
-		fmt.Println("No target depth defined - setting default")
 		meta.targetQueueDepth = defaultTargetQueueDepth
 	}
 
+	if val, ok := config.TriggerMetadata["activationQueueDepth"]; ok && val != "" {
+		activationQueueDepth, err := strconv.Atoi(val)
+		if err != nil {
+			return nil, fmt.Errorf("invalid activationQueueDepth - must be an integer")
+		}
+		meta.activationQueueDepth = activationQueueDepth
+	}
+
 	if val, ok := config.TriggerMetadata["tls"]; ok {
 		tlsDisabled, err := strconv.ParseBool(val)
 		if err != nil {
@@ -123,7 +153,6 @@ func parseIBMMQMetadata(config *ScalerConfig) (*IBMMQMetadata, error) {
 		}
 		meta.tlsDisabled = tlsDisabled
 	} else {
-		fmt.Println("No tls setting defined - setting default")
 		meta.tlsDisabled = defaultTLSDisabled
 	}
 	val, ok := config.AuthParams["username"]
@@ -154,7 +183,7 @@ func (s *IBMMQScaler) IsActive(ctx context.Context) (bool, error) {
 	if err != nil {
 		return false, fmt.Errorf("error inspecting IBM MQ queue depth: %s", err)
 	}
-	return queueDepth > 0, nil
+	return queueDepth > s.metadata.activationQueueDepth, nil
 }
 
 // getQueueDepthViaHTTP returns the depth of the MQ Queue from the Admin endpoint
@@ -162,7 +191,16 @@ func (s *IBMMQScaler) getQueueDepthViaHTTP(ctx context.Context) (int, error) {
 	queue := s.metadata.queueName
 	url := s.metadata.host
 
-	var requestJSON = []byte(`{"type": "runCommandJSON", "command": "display", "qualifier": "qlocal", "name": "` + queue + `", "responseParameters" : ["CURDEPTH"]}`)
+	requestJSON, err := json.Marshal(map[string]interface{}{
+		"type":               "runCommandJSON",
+		"command":            "display",
+		"qualifier":          "qlocal",
+		"name":               queue,
+		"responseParameters": []string{"CURDEPTH"},
+	})
+	if err != nil {
+		return 0, err
+	}
 	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewBuffer(requestJSON))
 	if err != nil {
 		return 0, fmt.Errorf("failed to request queue depth: %s", err)
@@ -171,11 +209,7 @@ func (s *IBMMQScaler) getQueueDepthViaHTTP(ctx context.Context) (int, error) {
 	req.Header.Set("Content-Type", "application/json")
 	req.SetBasicAuth(s.metadata.username, s.metadata.password)
 
-	tr := &http.Transport{
-		TLSClientConfig: &tls.Config{InsecureSkipVerify: s.metadata.tlsDisabled},
-	}
-	client := kedautil.CreateHTTPClient(s.defaultHTTPTimeout, false)
-	client.Transport = tr
+	client := kedautil.CreateHTTPClientWithTLSConfig(s.defaultHTTPTimeout, s.tlsConfig)
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -188,6 +222,10 @@ func (s *IBMMQScaler) getQueueDepthViaHTTP(ctx context.Context) (int, error) {
 		return 0, fmt.Errorf("failed to ready body of request: %s", err)
 	}
 
+	if !(resp.StatusCode >= 200 && resp.StatusCode <= 299) {
+		return 0, fmt.Errorf("IBM MQ api returned error. status: %d response: %s", resp.StatusCode, string(body))
+	}
+
 	var response CommandResponse
 	err = json.Unmarshal(body, &response)
 	if err != nil {
@@ -197,6 +235,10 @@ func (s *IBMMQScaler) getQueueDepthViaHTTP(ctx context.Context) (int, error) {
 	if response.CommandResponse == nil || len(response.CommandResponse) == 0 {
 		return 0, fmt.Errorf("failed to parse response from REST call: %s", err)
 	}
+	// the command fails with a completion code of 2 and a reason code, eg. 2085 for an unknown queue
+	if result := response.CommandResponse[0]; result.CompletionCode != 0 {
+		return 0, fmt.Errorf("display of queue %s failed with reason code %d: %s", queue, result.ReasonCode, strings.Join(result.Message, " "))
+	}
 	return response.CommandResponse[0].Parameters.Curdepth, nil
 }
 
@@ -224,7 +266,7 @@ func (s *IBMMQScaler) GetMetrics(ctx context.Context, metricName string, metricS
 	}
 
 	metric := external_metrics.ExternalMetricValue{
-		MetricName: ibmMqQueueDepthMetricName,
+		MetricName: metricName,
 		Value:      *resource.NewQuantity(int64(queueDepth), resource.DecimalSI),
 		Timestamp:  metav1.Now(),
 	}
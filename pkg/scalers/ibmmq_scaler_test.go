@@ -2,15 +2,21 @@ package scalers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
+
+	"github.com/stretchr/testify/assert"
 )
 
 // Test host URLs for validation
 const (
 	testValidMQQueueURL   = "https://qmtest.qm2.eu-gb.mq.appdomain.cloud/ibmmq/rest/v2/admin/action/qmgr/QM1/mqsc"
 	testInvalidMQQueueURL = "testInvalidURL.com"
+	testBaseMQURL         = "https://qmtest.qm2.eu-gb.mq.appdomain.cloud/"
 )
 
 // Test data struct used for TestIBMMQParseMetadata
@@ -60,6 +66,10 @@ var testIBMMQMetadata = []parseIBMMQMetadataTestData{
 	{map[string]string{"host": testValidMQQueueURL, "queueManager": "testQueueManager", "queueName": "testQueue", "queueDepth": "10"}, true, map[string]string{"password": "Pass123"}},
 	// No password provided
 	{map[string]string{"host": testValidMQQueueURL, "queueManager": "testQueueManager", "queueName": "testQueue", "queueDepth": "10"}, true, map[string]string{"username": "testUsername"}},
+	// Base URL of the REST api and activationQueueDepth
+	{map[string]string{"host": testBaseMQURL, "queueManager": "QM1", "queueName": "testQueue", "queueDepth": "10", "activationQueueDepth": "5"}, false, map[string]string{"username": "testUsername", "password": "Pass123"}},
+	// Invalid activationQueueDepth
+	{map[string]string{"host": testValidMQQueueURL, "queueManager": "testQueueManager", "queueName": "testQueue", "queueDepth": "10", "activationQueueDepth": "AA"}, true, map[string]string{"username": "testUsername", "password": "Pass123"}},
 }
 
 // Test MQ Connection metadata is parsed correctly
@@ -124,3 +134,79 @@ func TestIBMMQGetMetricSpecForScaling(t *testing.T) {
 		}
 	}
 }
+
+func TestIBMMQParseHost(t *testing.T) {
+	var testData = []struct {
+		host     string
+		expected string
+	}{
+		{testValidMQQueueURL, testValidMQQueueURL},
+		{testBaseMQURL, "https://qmtest.qm2.eu-gb.mq.appdomain.cloud/ibmmq/rest/v2/admin/action/qmgr/QM1/mqsc"},
+		{"https://mq.example.com:9443", "https://mq.example.com:9443/ibmmq/rest/v2/admin/action/qmgr/QM1/mqsc"},
+	}
+
+	for _, test := range testData {
+		metadata, err := parseIBMMQMetadata(&ScalerConfig{TriggerMetadata: map[string]string{"host": test.host, "queueManager": "QM1", "queueName": "testQueue"}, AuthParams: map[string]string{"username": "testUsername", "password": "Pass123"}})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		assert.Equal(t, test.expected, metadata.host)
+	}
+}
+
+func TestIBMMQGetQueueDepth(t *testing.T) {
+	var testData = []struct {
+		name           string
+		bodyStr        string
+		responseStatus int
+		expectedDepth  int
+		isError        bool
+	}{
+		{"queue depth", `{"commandResponse":[{"completionCode":0,"reasonCode":0,"parameters":{"curdepth":42,"queue":"DEV.QUEUE.1"}}],"overallCompletionCode":0,"overallReasonCode":0}`, http.StatusOK, 42, false},
+		{"unknown queue", `{"commandResponse":[{"completionCode":2,"reasonCode":2085,"message":["AMQ8147E: IBM MQ object DEV.QUEUE.1 not found."]}],"overallCompletionCode":2,"overallReasonCode":3008}`, http.StatusOK, 0, true},
+		{"no command response", `{"commandResponse":[]}`, http.StatusOK, 0, true},
+		{"error status response", `{"error":[{"msgId":"MQWB0111E","message":"The user could not be authenticated."}]}`, http.StatusUnauthorized, 0, true},
+	}
+
+	for _, test := range testData {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				assert.Equal(t, "/ibmmq/rest/v2/admin/action/qmgr/QM1/mqsc", request.URL.Path)
+				assert.Equal(t, "value", request.Header.Get("ibm-mq-rest-csrf-token"))
+				username, password, ok := request.BasicAuth()
+				assert.True(t, ok)
+				assert.Equal(t, "testUsername", username)
+				assert.Equal(t, "Pass123", password)
+				var command map[string]interface{}
+				assert.NoError(t, json.NewDecoder(request.Body).Decode(&command))
+				assert.Equal(t, "DEV.QUEUE.1", command["name"])
+				assert.Equal(t, "qlocal", command["qualifier"])
+				writer.WriteHeader(test.responseStatus)
+
+				if _, err := writer.Write([]byte(test.bodyStr)); err != nil {
+					t.Error(err)
+				}
+			}))
+			defer server.Close()
+
+			metadata, err := parseIBMMQMetadata(&ScalerConfig{
+				TriggerMetadata: map[string]string{"host": server.URL, "queueManager": "QM1", "queueName": "DEV.QUEUE.1"},
+				AuthParams:      map[string]string{"username": "testUsername", "password": "Pass123"},
+			})
+			if err != nil {
+				t.Fatal("Could not parse metadata:", err)
+			}
+			scaler := IBMMQScaler{metadata: metadata, defaultHTTPTimeout: time.Second}
+
+			depth, err := scaler.getQueueDepthViaHTTP(context.TODO())
+
+			assert.Equal(t, test.expectedDepth, depth)
+			if test.isError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,297 @@
+package scalers
+
+import (
+	"bufio"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	defaultImapPort    = "993"
+	defaultImapMailbox = "INBOX"
+)
+
+var imapUnseenRegex = regexp.MustCompile(`(?i)\(.*UNSEEN (\d+).*\)`)
+
+type imapScaler struct {
+	metadata  *imapMetadata
+	timeout   time.Duration
+	tlsConfig *tls.Config
+}
+
+type imapMetadata struct {
+	host        string
+	port        string
+	useTLS      bool
+	mailbox     string
+	targetValue int64
+
+	// auth
+	username    string
+	password    string
+	accessToken string
+
+	scalerIndex int
+}
+
+var imapLog = logf.Log.WithName("imap_scaler")
+
+// NewImapScaler creates a new imapScaler
+func NewImapScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseImapMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing imap metadata: %s", err)
+	}
+
+	tlsConfig, err := createTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	if tlsConfig.ServerName == "" {
+		tlsConfig.ServerName = meta.host
+	}
+	tlsConfig.MinVersion = tls.VersionTLS12
+
+	return &imapScaler{
+		metadata:  meta,
+		timeout:   config.GlobalHTTPTimeout,
+		tlsConfig: tlsConfig,
+	}, nil
+}
+
+func parseImapMetadata(config *ScalerConfig) (*imapMetadata, error) {
+	meta := imapMetadata{
+		port:    defaultImapPort,
+		useTLS:  true,
+		mailbox: defaultImapMailbox,
+	}
+
+	if val, ok := config.TriggerMetadata["host"]; ok && val != "" {
+		meta.host = val
+	} else {
+		return nil, fmt.Errorf("no host given")
+	}
+
+	if val, ok := config.TriggerMetadata["port"]; ok && val != "" {
+		if _, err := strconv.Atoi(val); err != nil {
+			return nil, fmt.Errorf("error parsing port: %s", err)
+		}
+		meta.port = val
+	}
+
+	if val, ok := config.TriggerMetadata["tls"]; ok && val != "" {
+		useTLS, err := strconv.ParseBool(val)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing tls: %s", err)
+		}
+		meta.useTLS = useTLS
+	}
+
+	if val, ok := config.TriggerMetadata["mailbox"]; ok && val != "" {
+		meta.mailbox = val
+	}
+
+	if val, ok := config.TriggerMetadata["targetValue"]; ok && val != "" {
+		targetValue, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing targetValue: %s", err)
+		}
+		meta.targetValue = targetValue
+	} else {
+		return nil, fmt.Errorf("no targetValue given")
+	}
+
+	if val, ok := config.AuthParams["username"]; ok && val != "" {
+		meta.username = val
+	} else {
+		return nil, fmt.Errorf("no username given")
+	}
+
+	meta.password = config.AuthParams["password"]
+	meta.accessToken = config.AuthParams["accessToken"]
+	if (meta.password == "") == (meta.accessToken == "") {
+		return nil, fmt.Errorf("either password or accessToken must be given")
+	}
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+func (s *imapScaler) IsActive(ctx context.Context) (bool, error) {
+	unseen, err := s.getUnseenCount(ctx)
+	if err != nil {
+		imapLog.Error(err, "error getting unseen message count")
+		return false, err
+	}
+
+	return unseen > 0, nil
+}
+
+func (s *imapScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *imapScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetMetricValue := resource.NewQuantity(s.metadata.targetValue, resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(fmt.Sprintf("imap-%s", s.metadata.mailbox))),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetMetricValue,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+func (s *imapScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	unseen, err := s.getUnseenCount(ctx)
+	if err != nil {
+		imapLog.Error(err, "error getting unseen message count")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewQuantity(unseen, resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
+
+// getUnseenCount logs in and reads the UNSEEN count with STATUS, which doesn't select
+// the mailbox, so the \Seen flags of the messages are not changed
+func (s *imapScaler) getUnseenCount(ctx context.Context) (int64, error) {
+	address := net.JoinHostPort(s.metadata.host, s.metadata.port)
+	dialer := &net.Dialer{Timeout: s.timeout}
+
+	var conn net.Conn
+	var err error
+	if s.metadata.useTLS {
+		tlsDialer := &tls.Dialer{
+			NetDialer: dialer,
+			Config:    s.tlsConfig,
+		}
+		conn, err = tlsDialer.DialContext(ctx, "tcp", address)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", address)
+	}
+	if err != nil {
+		return -1, err
+	}
+	defer conn.Close()
+
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	} else if s.timeout > 0 {
+		_ = conn.SetDeadline(time.Now().Add(s.timeout))
+	}
+
+	client := &imapConn{reader: bufio.NewReader(conn), conn: conn}
+	greeting, err := client.readLine()
+	if err != nil {
+		return -1, err
+	}
+	if !strings.HasPrefix(strings.ToUpper(greeting), "* OK") {
+		return -1, fmt.Errorf("unexpected imap greeting: %s", greeting)
+	}
+
+	if s.metadata.accessToken != "" {
+		xoauth2 := fmt.Sprintf("user=%s\x01auth=Bearer %s\x01\x01", s.metadata.username, s.metadata.accessToken)
+		_, err = client.command("AUTHENTICATE XOAUTH2 " + base64.StdEncoding.EncodeToString([]byte(xoauth2)))
+	} else {
+		_, err = client.command(fmt.Sprintf("LOGIN %s %s", imapQuote(s.metadata.username), imapQuote(s.metadata.password)))
+	}
+	if err != nil {
+		return -1, fmt.Errorf("imap authentication failed: %s", err)
+	}
+
+	responses, err := client.command(fmt.Sprintf("STATUS %s (UNSEEN)", imapQuote(s.metadata.mailbox)))
+	if err != nil {
+		return -1, err
+	}
+	_, _ = client.command("LOGOUT")
+
+	for _, response := range responses {
+		if !strings.HasPrefix(strings.ToUpper(response), "* STATUS") {
+			continue
+		}
+		if match := imapUnseenRegex.FindStringSubmatch(response); match != nil {
+			return strconv.ParseInt(match[1], 10, 64)
+		}
+	}
+	return -1, fmt.Errorf("no UNSEEN count in STATUS response of mailbox %s", s.metadata.mailbox)
+}
+
+type imapConn struct {
+	reader *bufio.Reader
+	conn   net.Conn
+	tag    int
+}
+
+func (c *imapConn) readLine() (string, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+// command sends a tagged command and returns the untagged responses, it fails if the result is not OK
+func (c *imapConn) command(command string) ([]string, error) {
+	c.tag++
+	tag := fmt.Sprintf("k%d", c.tag)
+	if _, err := fmt.Fprintf(c.conn, "%s %s\r\n", tag, command); err != nil {
+		return nil, err
+	}
+
+	var responses []string
+	for {
+		line, err := c.readLine()
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case strings.HasPrefix(line, tag+" "):
+			result := strings.TrimPrefix(line, tag+" ")
+			if !strings.HasPrefix(strings.ToUpper(result), "OK") {
+				return nil, fmt.Errorf("%s", result)
+			}
+			return responses, nil
+		case strings.HasPrefix(line, "+"):
+			// continuation request, for XOAUTH2 the server sends the error details and waits for an empty line
+			if _, err := fmt.Fprint(c.conn, "\r\n"); err != nil {
+				return nil, err
+			}
+		default:
+			responses = append(responses, line)
+		}
+	}
+}
+
+// imapQuote returns s as an IMAP quoted string
+func imapQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
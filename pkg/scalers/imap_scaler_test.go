@@ -0,0 +1,176 @@
+package scalers
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+type parseImapMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+}
+
+type imapMetricIdentifier struct {
+	metadataTestData *parseImapMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testImapMetadata = []parseImapMetadataTestData{
+	// nothing passed
+	{map[string]string{}, map[string]string{}, true},
+	// properly formed with password
+	{map[string]string{"host": "imap.example.com", "targetValue": "10"}, map[string]string{"username": "user", "password": "pass"}, false},
+	// properly formed with access token, port, mailbox and tls
+	{map[string]string{"host": "imap.example.com", "port": "143", "tls": "false", "mailbox": "Invoices/Incoming", "targetValue": "10"}, map[string]string{"username": "user", "accessToken": "token"}, false},
+	// missing host
+	{map[string]string{"targetValue": "10"}, map[string]string{"username": "user", "password": "pass"}, true},
+	// missing targetValue
+	{map[string]string{"host": "imap.example.com"}, map[string]string{"username": "user", "password": "pass"}, true},
+	// malformed port
+	{map[string]string{"host": "imap.example.com", "port": "imaps", "targetValue": "10"}, map[string]string{"username": "user", "password": "pass"}, true},
+	// malformed tls
+	{map[string]string{"host": "imap.example.com", "tls": "sometimes", "targetValue": "10"}, map[string]string{"username": "user", "password": "pass"}, true},
+	// missing username
+	{map[string]string{"host": "imap.example.com", "targetValue": "10"}, map[string]string{"password": "pass"}, true},
+	// missing password and accessToken
+	{map[string]string{"host": "imap.example.com", "targetValue": "10"}, map[string]string{"username": "user"}, true},
+	// both password and accessToken
+	{map[string]string{"host": "imap.example.com", "targetValue": "10"}, map[string]string{"username": "user", "password": "pass", "accessToken": "token"}, true},
+}
+
+var imapMetricIdentifiers = []imapMetricIdentifier{
+	{&testImapMetadata[1], 0, "s0-imap-INBOX"},
+	{&testImapMetadata[2], 1, "s1-imap-Invoices-Incoming"},
+}
+
+func TestImapParseMetadata(t *testing.T) {
+	for idx, testData := range testImapMetadata {
+		_, err := parseImapMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Errorf("Test %d: expected success but got error: %s", idx, err)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Test %d: expected error but got success", idx)
+		}
+	}
+}
+
+func TestImapGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range imapMetricIdentifiers {
+		meta, err := parseImapMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, AuthParams: testData.metadataTestData.authParams, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockImapScaler := imapScaler{metadata: meta}
+
+		metricSpec := mockImapScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Errorf("Wrong External metric source name: %s, expected: %s", metricName, testData.name)
+		}
+	}
+}
+
+// startFakeImapServer serves LOGIN with user/pass, AUTHENTICATE XOAUTH2 with user/token and STATUS of INBOX
+func startFakeImapServer(t *testing.T) net.Listener {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("Could not listen:", err)
+	}
+	xoauth2 := base64.StdEncoding.EncodeToString([]byte("user=user\x01auth=Bearer token\x01\x01"))
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go func(conn net.Conn) {
+				defer conn.Close()
+				reader := bufio.NewReader(conn)
+				fmt.Fprint(conn, "* OK IMAP4rev1 ready\r\n")
+				authenticated := false
+				for {
+					line, err := reader.ReadString('\n')
+					if err != nil {
+						return
+					}
+					parts := strings.SplitN(strings.TrimRight(line, "\r\n"), " ", 2)
+					tag, command := parts[0], parts[1]
+					switch {
+					case command == `LOGIN "user" "pass"`, command == "AUTHENTICATE XOAUTH2 "+xoauth2:
+						authenticated = true
+						fmt.Fprintf(conn, "%s OK authenticated\r\n", tag)
+					case strings.HasPrefix(command, "LOGIN"):
+						fmt.Fprintf(conn, "%s NO [AUTHENTICATIONFAILED] invalid credentials\r\n", tag)
+					case strings.HasPrefix(command, "AUTHENTICATE"):
+						fmt.Fprint(conn, "+ eyJzdGF0dXMiOiI0MDEifQ==\r\n")
+						_, _ = reader.ReadString('\n')
+						fmt.Fprintf(conn, "%s NO [AUTHENTICATIONFAILED] invalid token\r\n", tag)
+					case command == `STATUS "INBOX" (UNSEEN)` && authenticated:
+						fmt.Fprintf(conn, "* STATUS INBOX (UNSEEN 7)\r\n%s OK STATUS completed\r\n", tag)
+					case strings.HasPrefix(command, "STATUS"):
+						fmt.Fprintf(conn, "%s NO mailbox doesn't exist\r\n", tag)
+					case command == "LOGOUT":
+						fmt.Fprintf(conn, "* BYE\r\n%s OK LOGOUT completed\r\n", tag)
+						return
+					default:
+						fmt.Fprintf(conn, "%s BAD unknown command\r\n", tag)
+					}
+				}
+			}(conn)
+		}
+	}()
+	return listener
+}
+
+func TestImapGetUnseenCount(t *testing.T) {
+	listener := startFakeImapServer(t)
+	defer listener.Close()
+	host, port, _ := net.SplitHostPort(listener.Addr().String())
+
+	tests := []struct {
+		name       string
+		mailbox    string
+		authParams map[string]string
+		expected   int64
+		isError    bool
+	}{
+		{"password", "INBOX", map[string]string{"username": "user", "password": "pass"}, 7, false},
+		{"access token", "INBOX", map[string]string{"username": "user", "accessToken": "token"}, 7, false},
+		{"wrong password", "INBOX", map[string]string{"username": "user", "password": "wrong"}, 0, true},
+		{"wrong access token", "INBOX", map[string]string{"username": "user", "accessToken": "expired"}, 0, true},
+		{"missing mailbox", "Archive", map[string]string{"username": "user", "password": "pass"}, 0, true},
+	}
+	for _, test := range tests {
+		scaler, err := NewImapScaler(&ScalerConfig{
+			TriggerMetadata:   map[string]string{"host": host, "port": port, "tls": "false", "mailbox": test.mailbox, "targetValue": "1"},
+			AuthParams:        test.authParams,
+			GlobalHTTPTimeout: time.Second,
+		})
+		if err != nil {
+			t.Fatal("Could not create scaler:", err)
+		}
+		unseen, err := scaler.(*imapScaler).getUnseenCount(context.Background())
+		if test.isError {
+			if err == nil {
+				t.Errorf("Test %s: expected error but got success", test.name)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %s: expected success but got error: %s", test.name, err)
+		}
+		if unseen != test.expected {
+			t.Errorf("Test %s: expected %d unseen messages, got %d", test.name, test.expected, unseen)
+		}
+	}
+}
@@ -1,13 +1,18 @@
+//go:build !selective_scalers || scalers_database
+// +build !selective_scalers scalers_database
+
 package scalers
 
 import (
 	"context"
-	"crypto/tls"
+	"errors"
 	"fmt"
+	"net/http"
 	"strconv"
 
 	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
 	api "github.com/influxdata/influxdb-client-go/v2/api"
+	influxhttp "github.com/influxdata/influxdb-client-go/v2/api/http"
 	kedautil "github.com/kedacore/keda/v2/pkg/util"
 	v2beta2 "k8s.io/api/autoscaling/v2beta2"
 	"k8s.io/apimachinery/pkg/api/resource"
@@ -20,17 +25,29 @@ import (
 type influxDBScaler struct {
 	client   influxdb2.Client
 	metadata *influxDBMetadata
+	// failoverClients are the clients of the failoverURLs
+	failoverClients []influxdb2.Client
+	health          *failoverHealth
 }
 
+const (
+	influxDBResultValueFirst = "first"
+	influxDBResultValueLast  = "last"
+)
+
 type influxDBMetadata struct {
-	authToken        string
-	metricName       string
+	authToken  string
+	metricName string
+	// organizationName is the name or the ID of the organization, InfluxDB accepts both
 	organizationName string
 	query            string
-	serverURL        string
-	unsafeSsl        bool
-	thresholdValue   float64
-	scalerIndex      int
+	// resultValue selects the first or the last record of the query result
+	resultValue string
+	serverURL   string
+	// failoverURLs are the servers queried in order when serverURL fails
+	failoverURLs   []string
+	thresholdValue float64
+	scalerIndex    int
 }
 
 var influxDBLog = logf.Log.WithName("influxdb_scaler")
@@ -42,15 +59,30 @@ func NewInfluxDBScaler(config *ScalerConfig) (Scaler, error) {
 		return nil, fmt.Errorf("error parsing influxdb metadata: %s", err)
 	}
 
+	tlsConfig, err := createTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+
 	influxDBLog.Info("starting up influxdb client")
 	client := influxdb2.NewClientWithOptions(
 		meta.serverURL,
 		meta.authToken,
-		influxdb2.DefaultOptions().SetTLSConfig(&tls.Config{InsecureSkipVerify: meta.unsafeSsl}))
+		influxdb2.DefaultOptions().SetTLSConfig(tlsConfig))
+
+	failoverClients := make([]influxdb2.Client, 0, len(meta.failoverURLs))
+	for _, serverURL := range meta.failoverURLs {
+		failoverClients = append(failoverClients, influxdb2.NewClientWithOptions(
+			serverURL,
+			meta.authToken,
+			influxdb2.DefaultOptions().SetTLSConfig(tlsConfig)))
+	}
 
 	return &influxDBScaler{
-		client:   client,
-		metadata: meta,
+		client:          client,
+		metadata:        meta,
+		failoverClients: failoverClients,
+		health:          newFailoverHealth(influxDBLog),
 	}, nil
 }
 
@@ -60,8 +92,8 @@ func parseInfluxDBMetadata(config *ScalerConfig) (*influxDBMetadata, error) {
 	var metricName string
 	var organizationName string
 	var query string
+	var resultValue string
 	var serverURL string
-	var unsafeSsl bool
 	var thresholdValue float64
 
 	val, ok := config.TriggerMetadata["authToken"]
@@ -92,6 +124,16 @@ func parseInfluxDBMetadata(config *ScalerConfig) (*influxDBMetadata, error) {
 		}
 	case config.AuthParams["organizationName"] != "":
 		organizationName = config.AuthParams["organizationName"]
+	case config.TriggerMetadata["organizationID"] != "":
+		organizationName = config.TriggerMetadata["organizationID"]
+	case config.TriggerMetadata["organizationIDFromEnv"] != "":
+		if val, ok := config.ResolvedEnv[config.TriggerMetadata["organizationIDFromEnv"]]; ok {
+			organizationName = val
+		} else {
+			return nil, fmt.Errorf("no organization id given")
+		}
+	case config.AuthParams["organizationID"] != "":
+		organizationName = config.AuthParams["organizationID"]
 	default:
 		return nil, fmt.Errorf("no organization name given")
 	}
@@ -102,6 +144,19 @@ func parseInfluxDBMetadata(config *ScalerConfig) (*influxDBMetadata, error) {
 		return nil, fmt.Errorf("no query provided")
 	}
 
+	// bucket is declared as a variable of the query, so it can read from(bucket: bucket)
+	if val, ok := config.TriggerMetadata["bucket"]; ok && val != "" {
+		query = fmt.Sprintf("bucket = %s\n%s", strconv.Quote(val), query)
+	}
+
+	resultValue = influxDBResultValueFirst
+	if val, ok := config.TriggerMetadata["resultValue"]; ok && val != "" {
+		if val != influxDBResultValueFirst && val != influxDBResultValueLast {
+			return nil, fmt.Errorf("resultValue has to be %s or %s", influxDBResultValueFirst, influxDBResultValueLast)
+		}
+		resultValue = val
+	}
+
 	if val, ok := config.TriggerMetadata["serverURL"]; ok {
 		serverURL = val
 	} else if val, ok := config.AuthParams["serverURL"]; ok {
@@ -110,6 +165,11 @@ func parseInfluxDBMetadata(config *ScalerConfig) (*influxDBMetadata, error) {
 		return nil, fmt.Errorf("no server url given")
 	}
 
+	failoverURLs, err := parseFailoverAddresses(config)
+	if err != nil {
+		return nil, err
+	}
+
 	if val, ok := config.TriggerMetadata["metricName"]; ok {
 		metricName = kedautil.NormalizeString(fmt.Sprintf("influxdb-%s", val))
 	} else {
@@ -125,32 +185,23 @@ func parseInfluxDBMetadata(config *ScalerConfig) (*influxDBMetadata, error) {
 	} else {
 		return nil, fmt.Errorf("no threshold value given")
 	}
-	unsafeSsl = false
-	if val, ok := config.TriggerMetadata["unsafeSsl"]; ok {
-		parsedVal, err := strconv.ParseBool(val)
-		if err != nil {
-			return nil, fmt.Errorf("error parsing unsafeSsl: %s", err)
-		}
-		unsafeSsl = parsedVal
-	}
 
 	return &influxDBMetadata{
 		authToken:        authToken,
 		metricName:       metricName,
 		organizationName: organizationName,
 		query:            query,
+		resultValue:      resultValue,
 		serverURL:        serverURL,
+		failoverURLs:     failoverURLs,
 		thresholdValue:   thresholdValue,
-		unsafeSsl:        unsafeSsl,
 		scalerIndex:      config.ScalerIndex,
 	}, nil
 }
 
 // IsActive returns true if queried value is above the minimum value
 func (s *influxDBScaler) IsActive(ctx context.Context) (bool, error) {
-	queryAPI := s.client.QueryAPI(s.metadata.organizationName)
-
-	value, err := queryInfluxDB(ctx, queryAPI, s.metadata.query)
+	value, err := s.getQueryResult(ctx)
 	if err != nil {
 		return false, err
 	}
@@ -158,16 +209,38 @@ func (s *influxDBScaler) IsActive(ctx context.Context) (bool, error) {
 	return value > 0, nil
 }
 
-// Close closes the connection of the client to the server
+// Close closes the connection of the clients to the servers
 func (s *influxDBScaler) Close(context.Context) error {
 	s.client.Close()
+	for _, client := range s.failoverClients {
+		client.Close()
+	}
 	return nil
 }
 
+// getQueryResult runs the query on the server, or on the failover servers when it fails
+func (s *influxDBScaler) getQueryResult(ctx context.Context) (float64, error) {
+	clients := append([]influxdb2.Client{s.client}, s.failoverClients...)
+	serverURLs := append([]string{s.metadata.serverURL}, s.metadata.failoverURLs...)
+
+	var value float64
+	err := s.health.do(ctx, serverURLs, func(i int) error {
+		var err error
+		value, err = queryInfluxDB(ctx, clients[i].QueryAPI(s.metadata.organizationName), s.metadata.query, s.metadata.resultValue)
+		// a query rejected by the server isn't retried on the failover servers
+		var httpErr *influxhttp.Error
+		if errors.As(err, &httpErr) && httpErr.StatusCode > 0 && httpErr.StatusCode < http.StatusInternalServerError {
+			return noFailover(err)
+		}
+		return err
+	})
+	return value, err
+}
+
 // queryInfluxDB runs the query against the associated influxdb database
-// there is an implicit assumption here that the first value returned from the iterator
-// will be the value of interest
-func queryInfluxDB(ctx context.Context, queryAPI api.QueryAPI, query string) (float64, error) {
+// there is an implicit assumption here that the first or the last value returned from the iterator,
+// depending on resultValue, will be the value of interest
+func queryInfluxDB(ctx context.Context, queryAPI api.QueryAPI, query string, resultValue string) (float64, error) {
 	result, err := queryAPI.Query(ctx, query)
 	if err != nil {
 		return 0, err
@@ -175,10 +248,23 @@ func queryInfluxDB(ctx context.Context, queryAPI api.QueryAPI, query string) (fl
 
 	valueExists := result.Next()
 	if !valueExists {
+		if result.Err() != nil {
+			return 0, result.Err()
+		}
 		return 0, fmt.Errorf("no results found from query")
 	}
 
-	switch valRaw := result.Record().Value().(type) {
+	record := result.Record()
+	if resultValue == influxDBResultValueLast {
+		for result.Next() {
+			record = result.Record()
+		}
+		if result.Err() != nil {
+			return 0, result.Err()
+		}
+	}
+
+	switch valRaw := record.Value().(type) {
 	case float64:
 		return valRaw, nil
 	case int64:
@@ -190,10 +276,7 @@ func queryInfluxDB(ctx context.Context, queryAPI api.QueryAPI, query string) (fl
 
 // GetMetrics connects to influxdb via the client and returns a value based on the query
 func (s *influxDBScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
-	// Grab QueryAPI to make queries to influxdb instance
-	queryAPI := s.client.QueryAPI(s.metadata.organizationName)
-
-	value, err := queryInfluxDB(ctx, queryAPI, s.metadata.query)
+	value, err := s.getQueryResult(ctx)
 	if err != nil {
 		return []external_metrics.ExternalMetricValue{}, err
 	}
@@ -1,15 +1,22 @@
+//go:build !selective_scalers || scalers_database
+// +build !selective_scalers scalers_database
+
 package scalers
 
 import (
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
+	"github.com/stretchr/testify/assert"
 )
 
 var testInfluxDBResolvedEnv = map[string]string{
-	"INFLUX_ORG":   "influx_org",
-	"INFLUX_TOKEN": "myToken",
+	"INFLUX_ORG":    "influx_org",
+	"INFLUX_ORG_ID": "0c3a8b0f2e7d1e46",
+	"INFLUX_TOKEN":  "myToken",
 }
 
 type parseInfluxDBMetadataTestData struct {
@@ -45,6 +52,18 @@ var testInfluxDBMetadata = []parseInfluxDBMetadataTestData{
 	{map[string]string{"query": "from(bucket: hello)", "thresholdValue": "10", "unsafeSsl": "false"}, false, map[string]string{"serverURL": "https://influxdata.com", "organizationName": "influx_org", "authToken": "myToken"}},
 	// no sunsafeSsl value passed
 	{map[string]string{"serverURL": "https://influxdata.com", "metricName": "influx_metric", "organizationName": "influx_org", "query": "from(bucket: hello)", "thresholdValue": "10", "authToken": "myToken"}, false, map[string]string{}},
+	// organizationID instead of organizationName
+	{map[string]string{"serverURL": "https://influxdata.com", "organizationID": "0c3a8b0f2e7d1e46", "query": "from(bucket: hello)", "thresholdValue": "10", "authToken": "myToken"}, false, map[string]string{}},
+	// organizationID from environment variable
+	{map[string]string{"serverURL": "https://influxdata.com", "organizationIDFromEnv": "INFLUX_ORG_ID", "query": "from(bucket: hello)", "thresholdValue": "10", "authToken": "myToken"}, false, map[string]string{}},
+	// organizationID from authParams, bucket and resultValue
+	{map[string]string{"query": "from(bucket: bucket)", "bucket": "hello", "resultValue": "last", "thresholdValue": "10"}, false, map[string]string{"serverURL": "https://influxdata.com", "organizationID": "0c3a8b0f2e7d1e46", "authToken": "myToken"}},
+	// failoverAddresses
+	{map[string]string{"serverURL": "https://influxdata.com", "failoverAddresses": "https://eu.influxdata.com,https://us.influxdata.com", "organizationName": "influx_org", "query": "from(bucket: hello)", "thresholdValue": "10", "authToken": "myToken"}, false, map[string]string{}},
+	// malformed failoverAddresses
+	{map[string]string{"serverURL": "https://influxdata.com", "failoverAddresses": "influxdata", "organizationName": "influx_org", "query": "from(bucket: hello)", "thresholdValue": "10", "authToken": "myToken"}, true, map[string]string{}},
+	// wrong resultValue
+	{map[string]string{"serverURL": "https://influxdata.com", "organizationName": "influx_org", "query": "from(bucket: hello)", "resultValue": "max", "thresholdValue": "10", "authToken": "myToken"}, true, map[string]string{}},
 }
 
 var influxDBMetricIdentifiers = []influxDBMetricIdentifier{
@@ -72,7 +91,7 @@ func TestInfluxDBGetMetricSpecForScaling(t *testing.T) {
 		if err != nil {
 			t.Fatal("Could not parse metadata:", err)
 		}
-		mockInfluxDBScaler := influxDBScaler{influxdb2.NewClient("https://influxdata.com", "myToken"), meta}
+		mockInfluxDBScaler := influxDBScaler{client: influxdb2.NewClient("https://influxdata.com", "myToken"), metadata: meta}
 
 		metricSpec := mockInfluxDBScaler.GetMetricSpecForScaling(context.Background())
 		metricName := metricSpec[0].External.Metric.Name
@@ -81,3 +100,54 @@ func TestInfluxDBGetMetricSpecForScaling(t *testing.T) {
 		}
 	}
 }
+
+func TestInfluxDBParseBucket(t *testing.T) {
+	meta, err := parseInfluxDBMetadata(&ScalerConfig{TriggerMetadata: map[string]string{"serverURL": "https://influxdata.com", "organizationName": "influx_org", "query": "from(bucket: bucket)", "bucket": "hello", "thresholdValue": "10", "authToken": "myToken"}})
+	if err != nil {
+		t.Fatal("Could not parse metadata:", err)
+	}
+	assert.Equal(t, "bucket = \"hello\"\nfrom(bucket: bucket)", meta.query)
+}
+
+func TestInfluxDBQueryResultValue(t *testing.T) {
+	var testData = []struct {
+		name          string
+		resultValue   string
+		bodyStr       string
+		expectedValue float64
+		isError       bool
+	}{
+		{"first record", "first", "#datatype,string,long,dateTime:RFC3339,double\n#group,false,false,false,false\n#default,_result,,,\n,result,table,_time,_value\n,,0,2022-02-17T22:19:49Z,1.5\n,,0,2022-02-17T22:20:49Z,4\n\n", 1.5, false},
+		{"last record", "last", "#datatype,string,long,dateTime:RFC3339,double\n#group,false,false,false,false\n#default,_result,,,\n,result,table,_time,_value\n,,0,2022-02-17T22:19:49Z,1.5\n,,0,2022-02-17T22:20:49Z,4\n\n", 4, false},
+		{"last integer record", "last", "#datatype,string,long,dateTime:RFC3339,long\n#group,false,false,false,false\n#default,_result,,,\n,result,table,_time,_value\n,,0,2022-02-17T22:19:49Z,1\n,,0,2022-02-17T22:20:49Z,7\n\n", 7, false},
+		{"no records", "last", "", 0, true},
+	}
+
+	for _, test := range testData {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				assert.Equal(t, "/api/v2/query", request.URL.Path)
+				assert.Equal(t, "0c3a8b0f2e7d1e46", request.URL.Query().Get("org"))
+				assert.Equal(t, "Token myToken", request.Header.Get("Authorization"))
+				writer.Header().Set("Content-Type", "text/csv; charset=utf-8")
+				if _, err := writer.Write([]byte(test.bodyStr)); err != nil {
+					t.Fatal(err)
+				}
+			}))
+			defer server.Close()
+
+			client := influxdb2.NewClient(server.URL, "myToken")
+			defer client.Close()
+
+			value, err := queryInfluxDB(context.TODO(), client.QueryAPI("0c3a8b0f2e7d1e46"), "from(bucket: hello)", test.resultValue)
+
+			assert.Equal(t, test.expectedValue, value)
+			if test.isError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,246 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	defaultJenkinsTargetQueueLength = 1
+	// jenkinsQueueTree selects the fields of the queue items, fields a task doesn't export are left out
+	jenkinsQueueTree = "items[id,buildable,blocked,stuck,why,task[name,assignedLabel[name]]]"
+)
+
+type jenkinsScaler struct {
+	metadata   *jenkinsMetadata
+	httpClient *http.Client
+}
+
+type jenkinsMetadata struct {
+	jenkinsURL string
+	// labelExpression is the label expression of the agents, only the queued builds waiting for it are counted
+	labelExpression             string
+	targetQueueLength           int64
+	activationTargetQueueLength int64
+	username                    string
+	apiToken                    string
+	scalerIndex                 int
+}
+
+type jenkinsQueue struct {
+	Items []jenkinsQueueItem `json:"items"`
+}
+
+type jenkinsQueueItem struct {
+	ID        int64  `json:"id"`
+	Buildable bool   `json:"buildable"`
+	Blocked   bool   `json:"blocked"`
+	Stuck     bool   `json:"stuck"`
+	Why       string `json:"why"`
+	Task      struct {
+		Name          string `json:"name"`
+		AssignedLabel *struct {
+			Name string `json:"name"`
+		} `json:"assignedLabel"`
+	} `json:"task"`
+}
+
+var jenkinsMetricNameReplacer = regexp.MustCompile(`[^a-zA-Z0-9_]+`)
+
+var jenkinsLog = logf.Log.WithName("jenkins_scaler")
+
+// NewJenkinsScaler creates a new jenkinsScaler
+func NewJenkinsScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseJenkinsMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing jenkins metadata: %s", err)
+	}
+
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &jenkinsScaler{
+		metadata:   meta,
+		httpClient: httpClient,
+	}, nil
+}
+
+func parseJenkinsMetadata(config *ScalerConfig) (*jenkinsMetadata, error) {
+	meta := jenkinsMetadata{
+		targetQueueLength: defaultJenkinsTargetQueueLength,
+	}
+
+	if val, ok := config.TriggerMetadata["jenkinsURL"]; ok && val != "" {
+		if _, err := url.ParseRequestURI(val); err != nil {
+			return nil, fmt.Errorf("error parsing jenkinsURL: %s", err)
+		}
+		meta.jenkinsURL = strings.TrimSuffix(val, "/")
+	} else {
+		return nil, fmt.Errorf("no jenkinsURL given")
+	}
+
+	meta.labelExpression = normalizeJenkinsLabel(config.TriggerMetadata["labelExpression"])
+
+	if val, ok := config.TriggerMetadata["targetQueueLength"]; ok && val != "" {
+		queueLength, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing targetQueueLength: %s", err)
+		}
+		meta.targetQueueLength = queueLength
+	}
+
+	if val, ok := config.TriggerMetadata["activationTargetQueueLength"]; ok && val != "" {
+		queueLength, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing activationTargetQueueLength: %s", err)
+		}
+		meta.activationTargetQueueLength = queueLength
+	}
+
+	// the queue is read anonymously unless the user and one of its API tokens are given
+	meta.username = config.AuthParams["username"]
+	meta.apiToken = config.AuthParams["apiToken"]
+	if val, ok := config.TriggerMetadata["apiTokenFromEnv"]; ok && val != "" && meta.apiToken == "" {
+		meta.apiToken = config.ResolvedEnv[val]
+	}
+	if meta.username == "" {
+		meta.username = config.TriggerMetadata["username"]
+	}
+	if (meta.username == "") != (meta.apiToken == "") {
+		return nil, fmt.Errorf("both username and apiToken have to be given")
+	}
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+// IsActive returns true if the queued builds are more than the activation target
+func (s *jenkinsScaler) IsActive(ctx context.Context) (bool, error) {
+	queueLength, err := s.getQueueLength(ctx)
+	if err != nil {
+		jenkinsLog.Error(err, "error getting jenkins build queue length")
+		return false, err
+	}
+
+	return queueLength > s.metadata.activationTargetQueueLength, nil
+}
+
+func (s *jenkinsScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *jenkinsScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	name := "jenkins-queue"
+	if s.metadata.labelExpression != "" {
+		// label expressions contain characters not allowed in metric names, like spaces and operators
+		name = fmt.Sprintf("jenkins-%s", strings.Trim(jenkinsMetricNameReplacer.ReplaceAllString(s.metadata.labelExpression, "-"), "-"))
+	}
+	targetQueueLengthQty := resource.NewQuantity(s.metadata.targetQueueLength, resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(name)),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetQueueLengthQty,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{External: externalMetric, Type: externalMetricType}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// GetMetrics returns the number of queued builds waiting for an agent
+func (s *jenkinsScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	queueLength, err := s.getQueueLength(ctx)
+	if err != nil {
+		jenkinsLog.Error(err, "error getting jenkins build queue length")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewQuantity(queueLength, resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
+
+// getQueueLength counts the buildable items of the queue waiting for the label expression, blocked items
+// wait for other builds or a quiet period and more agents don't start them
+func (s *jenkinsScaler) getQueueLength(ctx context.Context) (int64, error) {
+	queueURL := fmt.Sprintf("%s/queue/api/json?tree=%s", s.metadata.jenkinsURL, url.QueryEscape(jenkinsQueueTree))
+	req, err := http.NewRequestWithContext(ctx, "GET", queueURL, nil)
+	if err != nil {
+		return -1, err
+	}
+	if s.metadata.username != "" {
+		req.SetBasicAuth(s.metadata.username, s.metadata.apiToken)
+	}
+
+	r, err := s.httpClient.Do(req)
+	if err != nil {
+		return -1, err
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode != http.StatusOK {
+		return -1, fmt.Errorf("the Jenkins API returned error. url: %s status: %d", queueURL, r.StatusCode)
+	}
+
+	var queue jenkinsQueue
+	if err := json.NewDecoder(r.Body).Decode(&queue); err != nil {
+		return -1, fmt.Errorf("error decoding the Jenkins queue: %s", err)
+	}
+
+	var queueLength int64
+	for _, item := range queue.Items {
+		if !item.Buildable || item.Blocked {
+			continue
+		}
+		if s.metadata.labelExpression == "" || s.metadata.labelExpression == item.label() {
+			queueLength++
+		}
+	}
+	return queueLength, nil
+}
+
+// label returns the label expression the item waits for, pipeline tasks don't export their label
+// so it is read from why, eg. "Waiting for next available executor on ‘linux && docker’"
+func (item *jenkinsQueueItem) label() string {
+	if item.Task.AssignedLabel != nil && item.Task.AssignedLabel.Name != "" {
+		return normalizeJenkinsLabel(item.Task.AssignedLabel.Name)
+	}
+	start := strings.Index(item.Why, "‘")
+	if start < 0 {
+		return ""
+	}
+	end := strings.Index(item.Why[start+len("‘"):], "’")
+	if end < 0 {
+		return ""
+	}
+	return normalizeJenkinsLabel(item.Why[start+len("‘") : start+len("‘")+end])
+}
+
+// normalizeJenkinsLabel removes the whitespace of a label expression, Jenkins shows "linux && docker" as "linux&&docker"
+func normalizeJenkinsLabel(label string) string {
+	return strings.Join(strings.Fields(label), "")
+}
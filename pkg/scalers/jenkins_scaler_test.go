@@ -0,0 +1,128 @@
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type parseJenkinsMetadataTestData struct {
+	metadata    map[string]string
+	isError     bool
+	resolvedEnv map[string]string
+	authParams  map[string]string
+}
+
+type jenkinsMetricIdentifier struct {
+	metadataTestData *parseJenkinsMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testJenkinsResolvedEnv = map[string]string{
+	"JENKINS_API_TOKEN": "token",
+}
+
+var testJenkinsMetadata = []parseJenkinsMetadataTestData{
+	// nothing passed
+	{map[string]string{}, true, testJenkinsResolvedEnv, map[string]string{}},
+	// anonymous
+	{map[string]string{"jenkinsURL": "https://jenkins.example.com/"}, false, testJenkinsResolvedEnv, map[string]string{}},
+	// label expression with credentials in TriggerAuthentication
+	{map[string]string{"jenkinsURL": "https://jenkins.example.com", "labelExpression": "linux && docker", "targetQueueLength": "2", "activationTargetQueueLength": "1"}, false, testJenkinsResolvedEnv, map[string]string{"username": "keda", "apiToken": "token"}},
+	// apiToken from env
+	{map[string]string{"jenkinsURL": "https://jenkins.example.com", "username": "keda", "apiTokenFromEnv": "JENKINS_API_TOKEN"}, false, testJenkinsResolvedEnv, map[string]string{}},
+	// invalid jenkinsURL
+	{map[string]string{"jenkinsURL": "jenkins"}, true, testJenkinsResolvedEnv, map[string]string{}},
+	// invalid targetQueueLength
+	{map[string]string{"jenkinsURL": "https://jenkins.example.com", "targetQueueLength": "a"}, true, testJenkinsResolvedEnv, map[string]string{}},
+	// invalid activationTargetQueueLength
+	{map[string]string{"jenkinsURL": "https://jenkins.example.com", "activationTargetQueueLength": "a"}, true, testJenkinsResolvedEnv, map[string]string{}},
+	// username without apiToken
+	{map[string]string{"jenkinsURL": "https://jenkins.example.com"}, true, testJenkinsResolvedEnv, map[string]string{"username": "keda"}},
+}
+
+var jenkinsMetricIdentifiers = []jenkinsMetricIdentifier{
+	{&testJenkinsMetadata[1], 0, "s0-jenkins-queue"},
+	{&testJenkinsMetadata[2], 1, "s1-jenkins-linux-docker"},
+}
+
+func TestParseJenkinsMetadata(t *testing.T) {
+	for _, testData := range testJenkinsMetadata {
+		_, err := parseJenkinsMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, ResolvedEnv: testData.resolvedEnv, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Errorf("Expected success but got error %s for metadata %v", err, testData.metadata)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Expected error but got success for metadata %v", testData.metadata)
+		}
+	}
+}
+
+func TestJenkinsGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range jenkinsMetricIdentifiers {
+		meta, err := parseJenkinsMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, ResolvedEnv: testData.metadataTestData.resolvedEnv, AuthParams: testData.metadataTestData.authParams, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockJenkinsScaler := jenkinsScaler{metadata: meta, httpClient: http.DefaultClient}
+
+		metricSpec := mockJenkinsScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Errorf("Wrong External metric source name: %s, expected: %s", metricName, testData.name)
+		}
+	}
+}
+
+func TestJenkinsGetQueueLength(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, token, ok := r.BasicAuth(); !ok || user != "keda" || token != "token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/queue/api/json" || r.URL.Query().Get("tree") != jenkinsQueueTree {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprint(w, `{"items":[
+			{"id":1,"buildable":true,"why":"Waiting for next available executor on ‘linux&&docker’","task":{"name":"build","assignedLabel":{"name":"linux&&docker"}}},
+			{"id":2,"buildable":true,"stuck":true,"why":"Waiting for next available executor on ‘linux&&docker’","task":{"name":"part of pipeline #3"}},
+			{"id":3,"buildable":true,"why":"Waiting for next available executor on ‘windows’","task":{"name":"part of pipeline #4"}},
+			{"id":4,"buildable":true,"why":"Waiting for next available executor","task":{"name":"deploy"}},
+			{"id":5,"buildable":false,"blocked":true,"why":"Build #6 is already in progress","task":{"name":"release","assignedLabel":{"name":"linux&&docker"}}},
+			{"id":6,"buildable":false,"why":"In the quiet period. Expires in 4.9 sec","task":{"name":"test"}}
+		]}`)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		labelExpression string
+		expected        int64
+	}{
+		{"", 4},
+		{"linux && docker", 2},
+		{"windows", 1},
+		{"gpu", 0},
+	}
+	for _, test := range tests {
+		meta, err := parseJenkinsMetadata(&ScalerConfig{
+			TriggerMetadata: map[string]string{"jenkinsURL": server.URL, "labelExpression": test.labelExpression},
+			AuthParams:      map[string]string{"username": "keda", "apiToken": "token"},
+		})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		scaler := jenkinsScaler{metadata: meta, httpClient: http.DefaultClient}
+
+		queueLength, err := scaler.getQueueLength(context.Background())
+		if err != nil {
+			t.Errorf("Expected success but got error %s for %s", err, test.labelExpression)
+		}
+		if queueLength != test.expected {
+			t.Errorf("Expected %d, got %d for %s", test.expected, queueLength, test.labelExpression)
+		}
+	}
+}
@@ -0,0 +1,247 @@
+package scalers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+type jolokiaScaler struct {
+	metadata   *jolokiaMetadata
+	httpClient *http.Client
+}
+
+type jolokiaMetadata struct {
+	url         string
+	mbean       string
+	attribute   string
+	path        string
+	targetValue int
+	metricName  string
+
+	// auth
+	username string
+	password string
+	ca       string
+	cert     string
+	key      string
+
+	scalerIndex int
+}
+
+type jolokiaReadRequest struct {
+	Type      string `json:"type"`
+	MBean     string `json:"mbean"`
+	Attribute string `json:"attribute"`
+	Path      string `json:"path,omitempty"`
+}
+
+type jolokiaReadResponse struct {
+	Value  json.RawMessage `json:"value"`
+	Status int             `json:"status"`
+	Error  string          `json:"error"`
+}
+
+var jolokiaLog = logf.Log.WithName("jolokia_scaler")
+
+// NewJolokiaScaler creates a new jolokiaScaler
+func NewJolokiaScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseJolokiaMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing jolokia metadata: %s", err)
+	}
+
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &jolokiaScaler{
+		metadata:   meta,
+		httpClient: httpClient,
+	}, nil
+}
+
+func parseJolokiaMetadata(config *ScalerConfig) (*jolokiaMetadata, error) {
+	meta := jolokiaMetadata{}
+
+	if val, ok := config.TriggerMetadata["url"]; ok && val != "" {
+		meta.url = strings.TrimSuffix(val, "/")
+	} else {
+		return nil, fmt.Errorf("no url given")
+	}
+
+	if val, ok := config.TriggerMetadata["mbean"]; ok && val != "" {
+		meta.mbean = val
+	} else {
+		return nil, fmt.Errorf("no mbean given")
+	}
+
+	if val, ok := config.TriggerMetadata["attribute"]; ok && val != "" {
+		meta.attribute = val
+	} else {
+		return nil, fmt.Errorf("no attribute given")
+	}
+
+	meta.path = config.TriggerMetadata["path"]
+
+	if val, ok := config.TriggerMetadata["targetValue"]; ok && val != "" {
+		targetValue, err := strconv.Atoi(val)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing targetValue: %s", err)
+		}
+		meta.targetValue = targetValue
+	} else {
+		return nil, fmt.Errorf("no targetValue given")
+	}
+
+	if val, ok := config.TriggerMetadata["metricName"]; ok && val != "" {
+		meta.metricName = kedautil.NormalizeString(fmt.Sprintf("jolokia-%s", val))
+	} else {
+		// MBean names contain characters that are not allowed in metric names, the scaler index keeps the name unique
+		meta.metricName = kedautil.NormalizeString(strings.TrimSuffix(fmt.Sprintf("jolokia-%s-%s", meta.attribute, meta.path), "-"))
+	}
+
+	meta.username = config.AuthParams["username"]
+	meta.password = config.AuthParams["password"]
+	if meta.password != "" && meta.username == "" {
+		return nil, fmt.Errorf("username must be provided with password")
+	}
+
+	meta.ca = config.AuthParams["ca"]
+	meta.cert = config.AuthParams["cert"]
+	meta.key = config.AuthParams["key"]
+	if (meta.cert == "") != (meta.key == "") {
+		return nil, fmt.Errorf("both cert and key must be provided for TLS client authentication")
+	}
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+func (s *jolokiaScaler) IsActive(ctx context.Context) (bool, error) {
+	val, err := s.getValue(ctx)
+	if err != nil {
+		jolokiaLog.Error(err, "error getting jolokia attribute")
+		return false, err
+	}
+
+	return val > 0, nil
+}
+
+func (s *jolokiaScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *jolokiaScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetMetricValue := resource.NewQuantity(int64(s.metadata.targetValue), resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, s.metadata.metricName),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetMetricValue,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+func (s *jolokiaScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	val, err := s.getValue(ctx)
+	if err != nil {
+		jolokiaLog.Error(err, "error getting jolokia attribute")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewQuantity(int64(val), resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
+
+// getValue reads the attribute with a Jolokia read request, the body is sent with POST
+// so that MBean names don't need to be escaped for the GET URL format
+func (s *jolokiaScaler) getValue(ctx context.Context) (float64, error) {
+	body, err := json.Marshal(jolokiaReadRequest{
+		Type:      "read",
+		MBean:     s.metadata.mbean,
+		Attribute: s.metadata.attribute,
+		Path:      s.metadata.path,
+	})
+	if err != nil {
+		return -1, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.metadata.url, bytes.NewReader(body))
+	if err != nil {
+		return -1, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.metadata.username != "" {
+		req.SetBasicAuth(s.metadata.username, s.metadata.password)
+	}
+
+	r, err := s.httpClient.Do(req)
+	if err != nil {
+		return -1, err
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode != http.StatusOK {
+		return -1, fmt.Errorf("jolokia agent returned %d", r.StatusCode)
+	}
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return -1, err
+	}
+
+	var response jolokiaReadResponse
+	if err := json.Unmarshal(b, &response); err != nil {
+		return -1, err
+	}
+	// the agent reports request errors in the body with HTTP 200
+	if response.Status != http.StatusOK {
+		return -1, fmt.Errorf("jolokia read of %s %s failed with status %d: %s", s.metadata.mbean, s.metadata.attribute, response.Status, response.Error)
+	}
+
+	return parseJolokiaValue(response.Value)
+}
+
+// parseJolokiaValue accepts JSON numbers and numeric strings, as Jolokia serializes
+// long and BigDecimal attributes as strings depending on the agent configuration
+func parseJolokiaValue(raw json.RawMessage) (float64, error) {
+	var number float64
+	if err := json.Unmarshal(raw, &number); err == nil {
+		return number, nil
+	}
+	var str string
+	if err := json.Unmarshal(raw, &str); err == nil {
+		if number, err := strconv.ParseFloat(str, 64); err == nil {
+			return number, nil
+		}
+	}
+	return -1, fmt.Errorf("jolokia attribute value %s is not numeric", string(raw))
+}
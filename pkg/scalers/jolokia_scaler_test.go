@@ -0,0 +1,140 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type parseJolokiaMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+}
+
+type jolokiaMetricIdentifier struct {
+	metadataTestData *parseJolokiaMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testJolokiaMetadata = []parseJolokiaMetadataTestData{
+	// nothing passed
+	{map[string]string{}, map[string]string{}, true},
+	// properly formed
+	{map[string]string{"url": "http://app:8778/jolokia", "mbean": "org.apache.activemq:type=Broker,brokerName=localhost,destinationType=Queue,destinationName=orders", "attribute": "QueueSize", "targetValue": "10"}, map[string]string{}, false},
+	// properly formed with path and metricName
+	{map[string]string{"url": "http://app:8778/jolokia", "mbean": "java.lang:type=Memory", "attribute": "HeapMemoryUsage", "path": "used", "targetValue": "100", "metricName": "heap"}, map[string]string{}, false},
+	// missing url
+	{map[string]string{"mbean": "java.lang:type=Memory", "attribute": "HeapMemoryUsage", "targetValue": "10"}, map[string]string{}, true},
+	// missing mbean
+	{map[string]string{"url": "http://app:8778/jolokia", "attribute": "HeapMemoryUsage", "targetValue": "10"}, map[string]string{}, true},
+	// missing attribute
+	{map[string]string{"url": "http://app:8778/jolokia", "mbean": "java.lang:type=Memory", "targetValue": "10"}, map[string]string{}, true},
+	// missing targetValue
+	{map[string]string{"url": "http://app:8778/jolokia", "mbean": "java.lang:type=Memory", "attribute": "HeapMemoryUsage"}, map[string]string{}, true},
+	// malformed targetValue
+	{map[string]string{"url": "http://app:8778/jolokia", "mbean": "java.lang:type=Memory", "attribute": "HeapMemoryUsage", "targetValue": "ten"}, map[string]string{}, true},
+	// basic auth and TLS from TriggerAuthentication
+	{map[string]string{"url": "https://app:8778/jolokia", "mbean": "java.lang:type=Memory", "attribute": "HeapMemoryUsage", "targetValue": "10"}, map[string]string{"username": "user", "password": "pass", "ca": "caaa", "cert": "ceert", "key": "keey"}, false},
+	// password without username
+	{map[string]string{"url": "http://app:8778/jolokia", "mbean": "java.lang:type=Memory", "attribute": "HeapMemoryUsage", "targetValue": "10"}, map[string]string{"password": "pass"}, true},
+	// cert without key
+	{map[string]string{"url": "https://app:8778/jolokia", "mbean": "java.lang:type=Memory", "attribute": "HeapMemoryUsage", "targetValue": "10"}, map[string]string{"cert": "ceert"}, true},
+}
+
+var jolokiaMetricIdentifiers = []jolokiaMetricIdentifier{
+	{&testJolokiaMetadata[1], 0, "s0-jolokia-QueueSize"},
+	{&testJolokiaMetadata[2], 1, "s1-jolokia-heap"},
+	{&testJolokiaMetadata[8], 2, "s2-jolokia-HeapMemoryUsage"},
+}
+
+func TestJolokiaParseMetadata(t *testing.T) {
+	for idx, testData := range testJolokiaMetadata {
+		_, err := parseJolokiaMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Errorf("Test %d: expected success but got error: %s", idx, err)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Test %d: expected error but got success", idx)
+		}
+	}
+}
+
+func TestJolokiaGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range jolokiaMetricIdentifiers {
+		meta, err := parseJolokiaMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, AuthParams: testData.metadataTestData.authParams, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockJolokiaScaler := jolokiaScaler{metadata: meta}
+
+		metricSpec := mockJolokiaScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Errorf("Wrong External metric source name: %s, expected: %s", metricName, testData.name)
+		}
+	}
+}
+
+func TestJolokiaGetValue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "user" || pass != "pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		var req jolokiaReadRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		switch {
+		case req.Attribute == "QueueSize":
+			fmt.Fprint(w, `{"value":42,"status":200}`)
+		case req.Attribute == "HeapMemoryUsage" && req.Path == "used":
+			fmt.Fprint(w, `{"value":"1024","status":200}`)
+		case req.Attribute == "Name":
+			fmt.Fprint(w, `{"value":"broker","status":200}`)
+		default:
+			fmt.Fprint(w, `{"status":404,"error":"javax.management.InstanceNotFoundException"}`)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		attribute string
+		path      string
+		value     float64
+		isError   bool
+	}{
+		{"QueueSize", "", 42, false},
+		{"HeapMemoryUsage", "used", 1024, false},
+		{"Name", "", 0, true},
+		{"Missing", "", 0, true},
+	}
+	for _, test := range tests {
+		scaler, err := NewJolokiaScaler(&ScalerConfig{
+			TriggerMetadata: map[string]string{"url": server.URL, "mbean": "test:type=Test", "attribute": test.attribute, "path": test.path, "targetValue": "1"},
+			AuthParams:      map[string]string{"username": "user", "password": "pass"},
+		})
+		if err != nil {
+			t.Fatal("Could not create scaler:", err)
+		}
+		value, err := scaler.(*jolokiaScaler).getValue(context.Background())
+		if test.isError {
+			if err == nil {
+				t.Errorf("Expected error for attribute %s but got success", test.attribute)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Expected success for attribute %s but got error: %s", test.attribute, err)
+		}
+		if value != test.value {
+			t.Errorf("Expected value %f for attribute %s, got %f", test.value, test.attribute, value)
+		}
+	}
+}
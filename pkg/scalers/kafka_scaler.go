@@ -1,9 +1,13 @@
+//go:build !selective_scalers || scalers_kafka
+// +build !selective_scalers scalers_kafka
+
 package scalers
 
 import (
 	"context"
 	"errors"
 	"fmt"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -39,10 +43,8 @@ type kafkaMetadata struct {
 	password string
 
 	// TLS
-	enableTLS bool
-	cert      string
-	key       string
-	ca        string
+	enableTLS  bool
+	tlsOptions kedautil.TLSOptions
 
 	scalerIndex int
 }
@@ -65,7 +67,6 @@ const (
 )
 
 const (
-	lagThresholdMetricName   = "lagThreshold"
 	kafkaMetricType          = "External"
 	defaultKafkaLagThreshold = 10
 	defaultOffsetResetPolicy = latest
@@ -176,9 +177,11 @@ func parseKafkaMetadata(config *ScalerConfig) (kafkaMetadata, error) {
 			if keyGiven && !certGiven {
 				return meta, errors.New("cert must be provided with key")
 			}
-			meta.ca = config.AuthParams["ca"]
-			meta.cert = config.AuthParams["cert"]
-			meta.key = config.AuthParams["key"]
+			tlsOptions, err := getTLSOptions(config)
+			if err != nil {
+				return meta, err
+			}
+			meta.tlsOptions = tlsOptions
 			meta.enableTLS = true
 		} else {
 			return meta, fmt.Errorf("err incorrect value for TLS given: %s", val)
@@ -214,12 +217,12 @@ func (s *kafkaScaler) IsActive(ctx context.Context) (bool, error) {
 		return false, err
 	}
 
-	offsets, err := s.getOffsets(partitions)
+	offsets, err := s.getOffsets(ctx, partitions)
 	if err != nil {
 		return false, err
 	}
 
-	topicOffsets, err := s.getTopicOffsets(partitions)
+	topicOffsets, err := s.getTopicOffsets(ctx, partitions)
 	if err != nil {
 		return false, err
 	}
@@ -252,7 +255,7 @@ func getKafkaClients(metadata kafkaMetadata) (sarama.Client, sarama.ClusterAdmin
 
 	if metadata.enableTLS {
 		config.Net.TLS.Enable = true
-		tlsConfig, err := kedautil.NewTLSConfig(metadata.cert, metadata.key, metadata.ca)
+		tlsConfig, err := kedautil.NewTLSConfigFromOptions(metadata.tlsOptions)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -307,7 +310,128 @@ func (s *kafkaScaler) getPartitions() ([]int32, error) {
 	return partitions, nil
 }
 
-func (s *kafkaScaler) getOffsets(partitions []int32) (*sarama.OffsetFetchResponse, error) {
+// GetMaxReplicaCount returns the partition count of the topic, more consumers of the group than partitions stay idle
+func (s *kafkaScaler) GetMaxReplicaCount(ctx context.Context) (int64, error) {
+	partitions, err := s.getPartitions()
+	if err != nil {
+		return -1, err
+	}
+	return int64(len(partitions)), nil
+}
+
+// GetJobParameters splits the partitions of the topic with lag between up to count Jobs, the Jobs get their
+// partitions in KEDA_KAFKA_PARTITIONS, eg. "0,3", and the annotation kafka.keda.sh/partitions. The partitions
+// in the annotations of the running Jobs are still being consumed and aren't handed out again
+func (s *kafkaScaler) GetJobParameters(ctx context.Context, count int64, running []JobParameters) ([]JobParameters, error) {
+	partitions, err := s.getPartitions()
+	if err != nil {
+		return nil, err
+	}
+
+	offsets, err := s.getOffsets(ctx, partitions)
+	if err != nil {
+		return nil, err
+	}
+
+	topicOffsets, err := s.getTopicOffsets(ctx, partitions)
+	if err != nil {
+		return nil, err
+	}
+
+	consumed := runningKafkaPartitions(running, s.metadata.topic)
+	lags := make(map[int32]int64, len(partitions))
+	for _, partition := range partitions {
+		if consumed[partition] {
+			continue
+		}
+		if lag, _ := s.getLagForPartition(partition, offsets, topicOffsets); lag > 0 {
+			lags[partition] = lag
+		}
+	}
+
+	var parameters []JobParameters
+	for _, jobPartitions := range splitKafkaPartitions(lags, count) {
+		ids := make([]string, len(jobPartitions))
+		for i, partition := range jobPartitions {
+			ids[i] = strconv.Itoa(int(partition))
+		}
+		parameters = append(parameters, JobParameters{
+			Env: map[string]string{
+				"KEDA_KAFKA_TOPIC":      s.metadata.topic,
+				"KEDA_KAFKA_PARTITIONS": strings.Join(ids, ","),
+			},
+			Annotations: map[string]string{
+				"kafka.keda.sh/topic":      s.metadata.topic,
+				"kafka.keda.sh/partitions": strings.Join(ids, ","),
+			},
+		})
+	}
+	return parameters, nil
+}
+
+// runningKafkaPartitions returns the partitions of the topic in the kafka.keda.sh/partitions annotations of the running Jobs
+func runningKafkaPartitions(running []JobParameters, topic string) map[int32]bool {
+	consumed := map[int32]bool{}
+	for _, parameters := range running {
+		if parameters.Annotations["kafka.keda.sh/topic"] != topic {
+			continue
+		}
+		for _, id := range strings.Split(parameters.Annotations["kafka.keda.sh/partitions"], ",") {
+			partition, err := strconv.ParseInt(strings.TrimSpace(id), 10, 32)
+			if err != nil {
+				continue
+			}
+			consumed[int32(partition)] = true
+		}
+	}
+	return consumed
+}
+
+// splitKafkaPartitions assigns the partitions to up to count Jobs, the partitions with the highest lag go first
+// to the Job with the lowest lag so far, the partitions of a Job are sorted
+func splitKafkaPartitions(lags map[int32]int64, count int64) [][]int32 {
+	partitions := make([]int32, 0, len(lags))
+	for partition := range lags {
+		partitions = append(partitions, partition)
+	}
+	sort.Slice(partitions, func(i, j int) bool {
+		if lags[partitions[i]] != lags[partitions[j]] {
+			return lags[partitions[i]] > lags[partitions[j]]
+		}
+		return partitions[i] < partitions[j]
+	})
+
+	if count > int64(len(partitions)) {
+		count = int64(len(partitions))
+	}
+	if count <= 0 {
+		return nil
+	}
+
+	jobs := make([][]int32, count)
+	jobLags := make([]int64, count)
+	for _, partition := range partitions {
+		lowest := 0
+		for i := range jobs {
+			if jobLags[i] < jobLags[lowest] {
+				lowest = i
+			}
+		}
+		jobs[lowest] = append(jobs[lowest], partition)
+		jobLags[lowest] += lags[partition]
+	}
+	for _, job := range jobs {
+		sort.Slice(job, func(i, j int) bool { return job[i] < job[j] })
+	}
+	return jobs
+}
+
+// getOffsets lists the offsets of the consumer group, sarama doesn't take a context so ctx is only checked
+// between the requests
+func (s *kafkaScaler) getOffsets(ctx context.Context, partitions []int32) (*sarama.OffsetFetchResponse, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	offsets, err := s.admin.ListConsumerGroupOffsets(s.metadata.group, map[string][]int32{
 		s.metadata.topic: partitions,
 	})
@@ -371,12 +495,12 @@ func (s *kafkaScaler) GetMetrics(ctx context.Context, metricName string, metricS
 		return []external_metrics.ExternalMetricValue{}, err
 	}
 
-	offsets, err := s.getOffsets(partitions)
+	offsets, err := s.getOffsets(ctx, partitions)
 	if err != nil {
 		return []external_metrics.ExternalMetricValue{}, err
 	}
 
-	topicOffsets, err := s.getTopicOffsets(partitions)
+	topicOffsets, err := s.getTopicOffsets(ctx, partitions)
 	if err != nil {
 		return []external_metrics.ExternalMetricValue{}, err
 	}
@@ -406,7 +530,7 @@ func (s *kafkaScaler) GetMetrics(ctx context.Context, metricName string, metricS
 	return append([]external_metrics.ExternalMetricValue{}, metric), nil
 }
 
-func (s *kafkaScaler) getTopicOffsets(partitions []int32) (map[int32]int64, error) {
+func (s *kafkaScaler) getTopicOffsets(ctx context.Context, partitions []int32) (map[int32]int64, error) {
 	version := int16(0)
 	if s.client.Config().Version.IsAtLeast(sarama.V0_10_1_0) {
 		version = 1
@@ -434,6 +558,9 @@ func (s *kafkaScaler) getTopicOffsets(partitions []int32) (map[int32]int64, erro
 
 	// Step 2: send requests, one per broker, and collect offsets
 	for broker, request := range requests {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
 		response, err := broker.GetAvailableOffsets(request)
 
 		if err != nil {
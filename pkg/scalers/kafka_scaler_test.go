@@ -1,3 +1,6 @@
+//go:build !selective_scalers || scalers_kafka
+// +build !selective_scalers scalers_kafka
+
 package scalers
 
 import (
@@ -205,3 +208,38 @@ func TestKafkaGetMetricSpecForScaling(t *testing.T) {
 		}
 	}
 }
+
+func TestSplitKafkaPartitions(t *testing.T) {
+	lags := map[int32]int64{0: 100, 1: 10, 2: 60, 3: 50, 5: 5}
+	tests := []struct {
+		count    int64
+		expected [][]int32
+	}{
+		{0, nil},
+		{1, [][]int32{{0, 1, 2, 3, 5}}},
+		{2, [][]int32{{0, 1, 5}, {2, 3}}},
+		{3, [][]int32{{0}, {2, 5}, {1, 3}}},
+		// more jobs than partitions with lag
+		{10, [][]int32{{0}, {2}, {3}, {1}, {5}}},
+	}
+	for _, test := range tests {
+		jobs := splitKafkaPartitions(lags, test.count)
+		if !reflect.DeepEqual(jobs, test.expected) {
+			t.Errorf("Expected %v for %d jobs, got %v", test.expected, test.count, jobs)
+		}
+	}
+}
+
+func TestRunningKafkaPartitions(t *testing.T) {
+	running := []JobParameters{
+		{Annotations: map[string]string{"kafka.keda.sh/topic": "orders", "kafka.keda.sh/partitions": "0,3"}},
+		{Annotations: map[string]string{"kafka.keda.sh/topic": "payments", "kafka.keda.sh/partitions": "1"}},
+		{Annotations: map[string]string{"kafka.keda.sh/topic": "orders", "kafka.keda.sh/partitions": "5"}},
+		{Annotations: map[string]string{"kafka.keda.sh/topic": "orders", "kafka.keda.sh/partitions": "x"}},
+		{},
+	}
+	expected := map[int32]bool{0: true, 3: true, 5: true}
+	if consumed := runningKafkaPartitions(running, "orders"); !reflect.DeepEqual(consumed, expected) {
+		t.Errorf("Expected %v, got %v", expected, consumed)
+	}
+}
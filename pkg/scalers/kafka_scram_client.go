@@ -1,3 +1,6 @@
+//go:build !selective_scalers || scalers_kafka
+// +build !selective_scalers scalers_kafka
+
 package scalers
 
 import (
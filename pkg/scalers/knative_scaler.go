@@ -0,0 +1,269 @@
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	knativeSourceAutoscaler = "autoscaler"
+	knativeSourceQueueProxy = "queueProxy"
+
+	knativeWindowStable = "stable"
+	knativeWindowPanic  = "panic"
+
+	// defaultKnativeAutoscalerURL is the metrics endpoint of the Knative Pod Autoscaler of a default Knative Serving install
+	defaultKnativeAutoscalerURL = "http://autoscaler.knative-serving.svc.cluster.local:9090/metrics"
+
+	// knativeQueueProxyMetric is the concurrency the queue-proxy of a revision pod reports to the autoscaler
+	knativeQueueProxyMetric = "queue_average_concurrent_requests"
+)
+
+// knativeAutoscalerMetrics maps the windows to the concurrency the Knative Pod Autoscaler observed for the revisions
+var knativeAutoscalerMetrics = map[string]string{
+	knativeWindowStable: "autoscaler_stable_request_concurrency",
+	knativeWindowPanic:  "autoscaler_panic_request_concurrency",
+}
+
+type knativeScaler struct {
+	metadata   *knativeMetadata
+	httpClient *http.Client
+}
+
+type knativeMetadata struct {
+	source     string
+	metricsURL string
+	// namespace, service and revision select the series of the autoscaler metrics, the queue-proxy metrics are
+	// of the revision pod only
+	namespace             string
+	service               string
+	revision              string
+	window                string
+	targetValue           float64
+	activationTargetValue float64
+	scalerIndex           int
+}
+
+var knativeLog = logf.Log.WithName("knative_scaler")
+
+// NewKnativeScaler creates a new knativeScaler
+func NewKnativeScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseKnativeMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing knative metadata: %s", err)
+	}
+
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &knativeScaler{
+		metadata:   meta,
+		httpClient: httpClient,
+	}, nil
+}
+
+func parseKnativeMetadata(config *ScalerConfig) (*knativeMetadata, error) {
+	meta := knativeMetadata{
+		source:    knativeSourceAutoscaler,
+		namespace: config.Namespace,
+		window:    knativeWindowStable,
+	}
+
+	if val, ok := config.TriggerMetadata["source"]; ok && val != "" {
+		if val != knativeSourceAutoscaler && val != knativeSourceQueueProxy {
+			return nil, fmt.Errorf("source must be %s or %s, got %s", knativeSourceAutoscaler, knativeSourceQueueProxy, val)
+		}
+		meta.source = val
+	}
+
+	if val, ok := config.TriggerMetadata["metricsURL"]; ok && val != "" {
+		meta.metricsURL = val
+	} else if meta.source == knativeSourceAutoscaler {
+		meta.metricsURL = defaultKnativeAutoscalerURL
+	} else {
+		return nil, fmt.Errorf("no metricsURL given")
+	}
+
+	if meta.source == knativeSourceAutoscaler {
+		if val, ok := config.TriggerMetadata["service"]; ok && val != "" {
+			meta.service = val
+		} else {
+			return nil, fmt.Errorf("no service given")
+		}
+		if val, ok := config.TriggerMetadata["namespace"]; ok && val != "" {
+			meta.namespace = val
+		}
+		meta.revision = config.TriggerMetadata["revision"]
+
+		if val, ok := config.TriggerMetadata["window"]; ok && val != "" {
+			if _, ok := knativeAutoscalerMetrics[val]; !ok {
+				return nil, fmt.Errorf("window must be %s or %s, got %s", knativeWindowStable, knativeWindowPanic, val)
+			}
+			meta.window = val
+		}
+	}
+
+	if val, ok := config.TriggerMetadata["targetValue"]; ok && val != "" {
+		targetValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing targetValue: %s", err)
+		}
+		meta.targetValue = targetValue
+	} else {
+		return nil, fmt.Errorf("no targetValue given")
+	}
+
+	if val, ok := config.TriggerMetadata["activationTargetValue"]; ok && val != "" {
+		activationTargetValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing activationTargetValue: %s", err)
+		}
+		meta.activationTargetValue = activationTargetValue
+	}
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+// IsActive returns true if the observed concurrency is above the activation target
+func (s *knativeScaler) IsActive(ctx context.Context) (bool, error) {
+	val, err := s.getConcurrency(ctx)
+	if err != nil {
+		knativeLog.Error(err, "error getting knative concurrency", "source", s.metadata.source)
+		return false, err
+	}
+
+	return val > s.metadata.activationTargetValue, nil
+}
+
+func (s *knativeScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *knativeScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	name := "knative-queue-proxy"
+	if s.metadata.source == knativeSourceAutoscaler {
+		name = fmt.Sprintf("knative-%s-%s", s.metadata.namespace, s.metadata.service)
+		if s.metadata.revision != "" {
+			name = fmt.Sprintf("%s-%s", name, s.metadata.revision)
+		}
+	}
+	targetMetricValue := resource.NewMilliQuantity(int64(s.metadata.targetValue*1000), resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(name)),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetMetricValue,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+func (s *knativeScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	val, err := s.getConcurrency(ctx)
+	if err != nil {
+		knativeLog.Error(err, "error getting knative concurrency", "source", s.metadata.source)
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewMilliQuantity(int64(val*1000), resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
+
+// getConcurrency scrapes the metrics endpoint and adds up the concurrency of the selected series
+func (s *knativeScaler) getConcurrency(ctx context.Context) (float64, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", s.metadata.metricsURL, nil)
+	if err != nil {
+		return -1, err
+	}
+	req.Header.Set("Accept", string(expfmt.FmtText))
+
+	r, err := s.httpClient.Do(req)
+	if err != nil {
+		return -1, err
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode != http.StatusOK {
+		return -1, fmt.Errorf("knative %s metrics endpoint returned %d", s.metadata.source, r.StatusCode)
+	}
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(r.Body)
+	if err != nil {
+		return -1, fmt.Errorf("error parsing knative %s metrics: %s", s.metadata.source, err)
+	}
+
+	metricName := knativeQueueProxyMetric
+	if s.metadata.source == knativeSourceAutoscaler {
+		metricName = knativeAutoscalerMetrics[s.metadata.window]
+	}
+	family, ok := families[metricName]
+	if !ok {
+		return -1, fmt.Errorf("metric %s not found in knative %s metrics", metricName, s.metadata.source)
+	}
+
+	return s.sumConcurrency(family)
+}
+
+// sumConcurrency adds up the series of the family, the autoscaler reports a series per revision so only the
+// series of the revisions of the service are used
+func (s *knativeScaler) sumConcurrency(family *dto.MetricFamily) (float64, error) {
+	var sum float64
+	found := false
+	for _, metric := range family.GetMetric() {
+		if s.metadata.source == knativeSourceAutoscaler && !s.matchesRevision(metric) {
+			continue
+		}
+		switch {
+		case metric.GetGauge() != nil:
+			sum += metric.GetGauge().GetValue()
+		case metric.GetUntyped() != nil:
+			sum += metric.GetUntyped().GetValue()
+		default:
+			return -1, fmt.Errorf("metric %s is not a gauge", family.GetName())
+		}
+		found = true
+	}
+	if !found {
+		return -1, fmt.Errorf("no series of metric %s found for service %s/%s", family.GetName(), s.metadata.namespace, s.metadata.service)
+	}
+	return sum, nil
+}
+
+func (s *knativeScaler) matchesRevision(metric *dto.Metric) bool {
+	metricLabels := make(map[string]string, len(metric.GetLabel()))
+	for _, label := range metric.GetLabel() {
+		metricLabels[label.GetName()] = label.GetValue()
+	}
+	if metricLabels["namespace_name"] != s.metadata.namespace || metricLabels["service_name"] != s.metadata.service {
+		return false
+	}
+	return s.metadata.revision == "" || metricLabels["revision_name"] == s.metadata.revision
+}
@@ -0,0 +1,151 @@
+package scalers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type parseKnativeMetadataTestData struct {
+	metadata map[string]string
+	isError  bool
+}
+
+type knativeMetricIdentifier struct {
+	metadataTestData *parseKnativeMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testKnativeMetadata = []parseKnativeMetadataTestData{
+	// nothing passed
+	{map[string]string{}, true},
+	// autoscaler with defaults
+	{map[string]string{"service": "checkout", "targetValue": "10"}, false},
+	// autoscaler revision with panic window
+	{map[string]string{"service": "checkout", "namespace": "shop", "revision": "checkout-00002", "window": "panic", "targetValue": "2.5", "activationTargetValue": "1"}, false},
+	// queue-proxy
+	{map[string]string{"source": "queueProxy", "metricsURL": "http://10.0.0.12:9090/metrics", "targetValue": "10"}, false},
+	// missing service
+	{map[string]string{"targetValue": "10"}, true},
+	// queue-proxy without metricsURL
+	{map[string]string{"source": "queueProxy", "targetValue": "10"}, true},
+	// invalid source
+	{map[string]string{"source": "activator", "service": "checkout", "targetValue": "10"}, true},
+	// invalid window
+	{map[string]string{"service": "checkout", "window": "burst", "targetValue": "10"}, true},
+	// missing targetValue
+	{map[string]string{"service": "checkout"}, true},
+	// invalid targetValue
+	{map[string]string{"service": "checkout", "targetValue": "a"}, true},
+	// invalid activationTargetValue
+	{map[string]string{"service": "checkout", "targetValue": "10", "activationTargetValue": "a"}, true},
+}
+
+var knativeMetricIdentifiers = []knativeMetricIdentifier{
+	{&testKnativeMetadata[1], 0, "s0-knative-default-checkout"},
+	{&testKnativeMetadata[2], 1, "s1-knative-shop-checkout-checkout-00002"},
+	{&testKnativeMetadata[3], 2, "s2-knative-queue-proxy"},
+}
+
+func TestParseKnativeMetadata(t *testing.T) {
+	for _, testData := range testKnativeMetadata {
+		_, err := parseKnativeMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, Namespace: "default"})
+		if err != nil && !testData.isError {
+			t.Error("Expected success but got error", err)
+		}
+		if testData.isError && err == nil {
+			t.Error("Expected error but got success", testData.metadata)
+		}
+	}
+}
+
+func TestKnativeDefaults(t *testing.T) {
+	meta, err := parseKnativeMetadata(&ScalerConfig{TriggerMetadata: testKnativeMetadata[1].metadata, Namespace: "default"})
+	assert.NoError(t, err)
+	assert.Equal(t, defaultKnativeAutoscalerURL, meta.metricsURL)
+	assert.Equal(t, "default", meta.namespace)
+	assert.Equal(t, knativeWindowStable, meta.window)
+}
+
+func TestKnativeGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range knativeMetricIdentifiers {
+		meta, err := parseKnativeMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, Namespace: "default", ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockKnativeScaler := knativeScaler{metadata: meta}
+
+		metricSpec := mockKnativeScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Error("Wrong External metric source name:", metricName)
+		}
+	}
+}
+
+const testKnativeAutoscalerMetrics = `# HELP autoscaler_stable_request_concurrency Average of requests count per observed pod over the stable window
+# TYPE autoscaler_stable_request_concurrency gauge
+autoscaler_stable_request_concurrency{configuration_name="checkout",namespace_name="shop",revision_name="checkout-00001",service_name="checkout"} 3.5
+autoscaler_stable_request_concurrency{configuration_name="checkout",namespace_name="shop",revision_name="checkout-00002",service_name="checkout"} 6
+autoscaler_stable_request_concurrency{configuration_name="cart",namespace_name="shop",revision_name="cart-00001",service_name="cart"} 40
+autoscaler_stable_request_concurrency{configuration_name="checkout",namespace_name="staging",revision_name="checkout-00001",service_name="checkout"} 11
+# HELP autoscaler_panic_request_concurrency Average of requests count per observed pod over the panic window
+# TYPE autoscaler_panic_request_concurrency gauge
+autoscaler_panic_request_concurrency{configuration_name="checkout",namespace_name="shop",revision_name="checkout-00002",service_name="checkout"} 14
+`
+
+const testKnativeQueueProxyMetrics = `# HELP queue_average_concurrent_requests Number of requests currently being handled by this pod
+# TYPE queue_average_concurrent_requests gauge
+queue_average_concurrent_requests{destination_namespace="shop",destination_revision="checkout-00002"} 2.25
+`
+
+func TestKnativeGetConcurrency(t *testing.T) {
+	var tests = []struct {
+		name     string
+		body     string
+		metadata knativeMetadata
+		expected float64
+		isError  bool
+	}{
+		{"service", testKnativeAutoscalerMetrics, knativeMetadata{source: knativeSourceAutoscaler, namespace: "shop", service: "checkout", window: knativeWindowStable}, 9.5, false},
+		{"revision", testKnativeAutoscalerMetrics, knativeMetadata{source: knativeSourceAutoscaler, namespace: "shop", service: "checkout", revision: "checkout-00001", window: knativeWindowStable}, 3.5, false},
+		{"panic window", testKnativeAutoscalerMetrics, knativeMetadata{source: knativeSourceAutoscaler, namespace: "shop", service: "checkout", window: knativeWindowPanic}, 14, false},
+		{"unknown service", testKnativeAutoscalerMetrics, knativeMetadata{source: knativeSourceAutoscaler, namespace: "shop", service: "payments", window: knativeWindowStable}, 0, true},
+		{"queue-proxy", testKnativeQueueProxyMetrics, knativeMetadata{source: knativeSourceQueueProxy}, 2.25, false},
+		{"missing metric", testKnativeQueueProxyMetrics, knativeMetadata{source: knativeSourceAutoscaler, namespace: "shop", service: "checkout", window: knativeWindowStable}, 0, true},
+	}
+
+	for _, test := range tests {
+		body := test.body
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(body))
+		}))
+		meta := test.metadata
+		meta.metricsURL = server.URL
+		scaler := knativeScaler{metadata: &meta, httpClient: http.DefaultClient}
+
+		val, err := scaler.getConcurrency(context.Background())
+		server.Close()
+		if test.isError {
+			assert.Error(t, err, test.name)
+			continue
+		}
+		assert.NoError(t, err, test.name)
+		assert.Equal(t, test.expected, val, test.name)
+	}
+}
+
+func TestKnativeGetConcurrencyErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+	scaler := knativeScaler{metadata: &knativeMetadata{source: knativeSourceQueueProxy, metricsURL: server.URL}, httpClient: http.DefaultClient}
+
+	_, err := scaler.getConcurrency(context.Background())
+	assert.Error(t, err)
+}
@@ -0,0 +1,224 @@
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	kongMetricRequests    = "requests"
+	kongMetricRateLimited = "rateLimited"
+
+	// kongRequestsMetric is the request counter of the Prometheus plugin of Kong 3.x, kongStatusMetric the one of Kong 2.x
+	kongRequestsMetric = "kong_http_requests_total"
+	kongStatusMetric   = "kong_http_status"
+
+	// kongRateLimitedCode is the status code the rate limiting plugins reject requests with
+	kongRateLimitedCode = "429"
+)
+
+type kongScaler struct {
+	metadata   *kongMetadata
+	httpClient *http.Client
+	rate       counterRate
+}
+
+type kongMetadata struct {
+	metricsURL            string
+	metric                string
+	service               string
+	route                 string
+	targetValue           float64
+	activationTargetValue float64
+	scalerIndex           int
+}
+
+var kongLog = logf.Log.WithName("kong_scaler")
+
+// NewKongScaler creates a new kongScaler
+func NewKongScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseKongMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing kong metadata: %s", err)
+	}
+
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &kongScaler{
+		metadata:   meta,
+		httpClient: httpClient,
+	}, nil
+}
+
+func parseKongMetadata(config *ScalerConfig) (*kongMetadata, error) {
+	meta := kongMetadata{
+		metric: kongMetricRequests,
+	}
+
+	// metricsURL is the /metrics endpoint of the status API of a Kong node, eg. http://kong-status:8100/metrics
+	if val, ok := config.TriggerMetadata["metricsURL"]; ok && val != "" {
+		meta.metricsURL = val
+	} else {
+		return nil, fmt.Errorf("no metricsURL given")
+	}
+
+	if val, ok := config.TriggerMetadata["metric"]; ok && val != "" {
+		if val != kongMetricRequests && val != kongMetricRateLimited {
+			return nil, fmt.Errorf("metric must be %s or %s, got %s", kongMetricRequests, kongMetricRateLimited, val)
+		}
+		meta.metric = val
+	}
+
+	meta.service = config.TriggerMetadata["service"]
+	meta.route = config.TriggerMetadata["route"]
+
+	// targetValue is the number of requests per second
+	if val, ok := config.TriggerMetadata["targetValue"]; ok && val != "" {
+		targetValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing targetValue: %s", err)
+		}
+		meta.targetValue = targetValue
+	} else {
+		return nil, fmt.Errorf("no targetValue given")
+	}
+
+	if val, ok := config.TriggerMetadata["activationTargetValue"]; ok && val != "" {
+		activationTargetValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing activationTargetValue: %s", err)
+		}
+		meta.activationTargetValue = activationTargetValue
+	}
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+// IsActive returns true if the request rate is above the activation target
+func (s *kongScaler) IsActive(ctx context.Context) (bool, error) {
+	rate, err := s.getRequestRate(ctx)
+	if err != nil {
+		kongLog.Error(err, "error getting kong request rate")
+		return false, err
+	}
+
+	return rate > s.metadata.activationTargetValue, nil
+}
+
+func (s *kongScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *kongScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	name := "kong-" + s.metadata.metric
+	if s.metadata.service != "" {
+		name = fmt.Sprintf("%s-%s", name, s.metadata.service)
+	}
+	if s.metadata.route != "" {
+		name = fmt.Sprintf("%s-%s", name, s.metadata.route)
+	}
+	targetMetricValue := resource.NewMilliQuantity(int64(s.metadata.targetValue*1000), resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(name)),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetMetricValue,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+func (s *kongScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	rate, err := s.getRequestRate(ctx)
+	if err != nil {
+		kongLog.Error(err, "error getting kong request rate")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewMilliQuantity(int64(rate*1000), resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
+
+// getRequestRate returns the requests per second since the previous sample of the counter, the first sample
+// only records the counter and returns a rate of 0
+func (s *kongScaler) getRequestRate(ctx context.Context) (float64, error) {
+	total, err := s.getRequestCount(ctx)
+	if err != nil {
+		return -1, err
+	}
+	return s.rate.update(total, time.Now()), nil
+}
+
+// getRequestCount scrapes the metrics endpoint and adds up the request counters of the selected service and route
+func (s *kongScaler) getRequestCount(ctx context.Context) (float64, error) {
+	families, err := scrapeMetricFamilies(ctx, s.httpClient, s.metadata.metricsURL, "")
+	if err != nil {
+		return -1, fmt.Errorf("error scraping kong metrics: %s", err)
+	}
+
+	family, ok := families[kongRequestsMetric]
+	if !ok {
+		family, ok = families[kongStatusMetric]
+	}
+	if !ok {
+		return -1, fmt.Errorf("neither %s nor %s found in kong metrics, is the prometheus plugin enabled?", kongRequestsMetric, kongStatusMetric)
+	}
+
+	return s.sumRequests(family), nil
+}
+
+// sumRequests adds up the series of the family matching the service, route and for rateLimited the status code
+func (s *kongScaler) sumRequests(family *dto.MetricFamily) float64 {
+	var sum float64
+	for _, metric := range family.GetMetric() {
+		if !s.matches(metric) {
+			continue
+		}
+		if value, ok := metricValue(metric); ok {
+			sum += value
+		}
+	}
+	return sum
+}
+
+func (s *kongScaler) matches(metric *dto.Metric) bool {
+	metricLabels := make(map[string]string, len(metric.GetLabel()))
+	for _, label := range metric.GetLabel() {
+		metricLabels[label.GetName()] = label.GetValue()
+	}
+	if s.metadata.service != "" && metricLabels["service"] != s.metadata.service {
+		return false
+	}
+	if s.metadata.route != "" && metricLabels["route"] != s.metadata.route {
+		return false
+	}
+	return s.metadata.metric != kongMetricRateLimited || metricLabels["code"] == kongRateLimitedCode
+}
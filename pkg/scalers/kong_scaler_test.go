@@ -0,0 +1,124 @@
+package scalers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type parseKongMetadataTestData struct {
+	metadata map[string]string
+	isError  bool
+}
+
+type kongMetricIdentifier struct {
+	metadataTestData *parseKongMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testKongMetadata = []parseKongMetadataTestData{
+	// nothing passed
+	{map[string]string{}, true},
+	// requests of all services
+	{map[string]string{"metricsURL": "http://kong-status:8100/metrics", "targetValue": "100"}, false},
+	// rate limited requests of a route
+	{map[string]string{"metricsURL": "http://kong-status:8100/metrics", "metric": "rateLimited", "service": "orders", "route": "orders-create", "targetValue": "2.5", "activationTargetValue": "1"}, false},
+	// missing metricsURL
+	{map[string]string{"targetValue": "100"}, true},
+	// invalid metric
+	{map[string]string{"metricsURL": "http://kong-status:8100/metrics", "metric": "latency", "targetValue": "100"}, true},
+	// missing targetValue
+	{map[string]string{"metricsURL": "http://kong-status:8100/metrics"}, true},
+	// invalid targetValue
+	{map[string]string{"metricsURL": "http://kong-status:8100/metrics", "targetValue": "a"}, true},
+	// invalid activationTargetValue
+	{map[string]string{"metricsURL": "http://kong-status:8100/metrics", "targetValue": "100", "activationTargetValue": "a"}, true},
+}
+
+var kongMetricIdentifiers = []kongMetricIdentifier{
+	{&testKongMetadata[1], 0, "s0-kong-requests"},
+	{&testKongMetadata[2], 1, "s1-kong-rateLimited-orders-orders-create"},
+}
+
+func TestParseKongMetadata(t *testing.T) {
+	for _, testData := range testKongMetadata {
+		_, err := parseKongMetadata(&ScalerConfig{TriggerMetadata: testData.metadata})
+		if err != nil && !testData.isError {
+			t.Error("Expected success but got error", err)
+		}
+		if testData.isError && err == nil {
+			t.Error("Expected error but got success", testData.metadata)
+		}
+	}
+}
+
+func TestKongGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range kongMetricIdentifiers {
+		meta, err := parseKongMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockKongScaler := kongScaler{metadata: meta}
+
+		metricSpec := mockKongScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Error("Wrong External metric source name:", metricName)
+		}
+	}
+}
+
+const testKong3Metrics = `# HELP kong_http_requests_total HTTP status codes per consumer/service/route in Kong
+# TYPE kong_http_requests_total counter
+kong_http_requests_total{service="orders",route="orders-create",code="200",source="service",consumer=""} 120
+kong_http_requests_total{service="orders",route="orders-create",code="429",source="kong",consumer=""} 8
+kong_http_requests_total{service="orders",route="orders-list",code="200",source="service",consumer=""} 300
+kong_http_requests_total{service="payments",route="payments-create",code="200",source="service",consumer=""} 50
+`
+
+const testKong2Metrics = `# HELP kong_http_status HTTP status codes per service/route in Kong
+# TYPE kong_http_status counter
+kong_http_status{service="orders",route="orders-create",code="200"} 40
+kong_http_status{service="orders",route="orders-create",code="429"} 2
+`
+
+func TestKongGetRequestCount(t *testing.T) {
+	var tests = []struct {
+		name     string
+		body     string
+		metadata kongMetadata
+		expected float64
+		isError  bool
+	}{
+		{"all requests", testKong3Metrics, kongMetadata{metric: kongMetricRequests}, 478, false},
+		{"service", testKong3Metrics, kongMetadata{metric: kongMetricRequests, service: "orders"}, 428, false},
+		{"route", testKong3Metrics, kongMetadata{metric: kongMetricRequests, service: "orders", route: "orders-create"}, 128, false},
+		{"rate limited", testKong3Metrics, kongMetadata{metric: kongMetricRateLimited, service: "orders"}, 8, false},
+		{"kong 2.x", testKong2Metrics, kongMetadata{metric: kongMetricRequests, route: "orders-create"}, 42, false},
+		{"unknown service", testKong3Metrics, kongMetadata{metric: kongMetricRequests, service: "cart"}, 0, false},
+		{"plugin disabled", "# TYPE kong_nginx_connections_total gauge\nkong_nginx_connections_total{state=\"active\"} 3\n", kongMetadata{metric: kongMetricRequests}, 0, true},
+	}
+
+	for _, test := range tests {
+		body := test.body
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(body))
+		}))
+		meta := test.metadata
+		meta.metricsURL = server.URL
+		scaler := kongScaler{metadata: &meta, httpClient: http.DefaultClient}
+
+		val, err := scaler.getRequestCount(context.Background())
+		server.Close()
+		if test.isError {
+			assert.Error(t, err, test.name)
+			continue
+		}
+		assert.NoError(t, err, test.name)
+		assert.Equal(t, test.expected, val, test.name)
+	}
+}
@@ -0,0 +1,189 @@
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	litmusChaosEngine     = "chaosEngine"
+	litmusExperimentName  = "experimentName"
+	litmusNamespace       = "namespace"
+	litmusDesiredReplicas = "desiredReplicas"
+
+	// a ChaosEngine runs its experiments while its engineState is active and its engineStatus initialized
+	litmusEngineStateActive       = "active"
+	litmusEngineStatusInitialized = "initialized"
+	litmusExperimentStatusRunning = "Running"
+)
+
+var litmusChaosEngineGVK = schema.GroupVersionKind{Group: "litmuschaos.io", Version: "v1alpha1", Kind: "ChaosEngine"}
+
+type litmusChaosScaler struct {
+	metadata   *litmusChaosMetadata
+	kubeClient client.Client
+}
+
+type litmusChaosMetadata struct {
+	chaosEngine string
+	// experimentName restricts the activation to one experiment of the ChaosEngine
+	experimentName  string
+	namespace       string
+	desiredReplicas int64
+	scalerIndex     int
+}
+
+var litmusChaosLog = logf.Log.WithName("litmus_chaos_scaler")
+
+// NewLitmusChaosScaler creates a new litmusChaosScaler
+func NewLitmusChaosScaler(kubeClient client.Client, config *ScalerConfig) (Scaler, error) {
+	meta, err := parseLitmusChaosMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing litmus chaos metadata: %s", err)
+	}
+
+	return &litmusChaosScaler{
+		metadata:   meta,
+		kubeClient: kubeClient,
+	}, nil
+}
+
+func parseLitmusChaosMetadata(config *ScalerConfig) (*litmusChaosMetadata, error) {
+	meta := litmusChaosMetadata{
+		namespace: config.Namespace,
+	}
+
+	if val, ok := config.TriggerMetadata[litmusChaosEngine]; ok && val != "" {
+		meta.chaosEngine = val
+	} else {
+		return nil, fmt.Errorf("no %s given", litmusChaosEngine)
+	}
+
+	meta.experimentName = config.TriggerMetadata[litmusExperimentName]
+
+	if val, ok := config.TriggerMetadata[litmusNamespace]; ok && val != "" {
+		meta.namespace = val
+	}
+
+	if val, ok := config.TriggerMetadata[litmusDesiredReplicas]; ok && val != "" {
+		desiredReplicas, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", litmusDesiredReplicas, err)
+		}
+		if desiredReplicas < 1 {
+			return nil, fmt.Errorf("%s has to be at least 1", litmusDesiredReplicas)
+		}
+		meta.desiredReplicas = desiredReplicas
+	} else {
+		return nil, fmt.Errorf("no %s given", litmusDesiredReplicas)
+	}
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+// IsActive returns true while the experiments of the ChaosEngine are running, a missing ChaosEngine is not running
+func (s *litmusChaosScaler) IsActive(ctx context.Context) (bool, error) {
+	engine := &unstructured.Unstructured{}
+	engine.SetGroupVersionKind(litmusChaosEngineGVK)
+	err := s.kubeClient.Get(ctx, types.NamespacedName{Name: s.metadata.chaosEngine, Namespace: s.metadata.namespace}, engine)
+	if errors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		litmusChaosLog.Error(err, "error getting ChaosEngine", "chaosEngine", s.metadata.chaosEngine, "namespace", s.metadata.namespace)
+		return false, err
+	}
+
+	return isLitmusChaosEngineRunning(engine, s.metadata.experimentName)
+}
+
+// isLitmusChaosEngineRunning returns true if the engine is running, when experimentName is given
+// the experiment also has to be running
+func isLitmusChaosEngineRunning(engine *unstructured.Unstructured, experimentName string) (bool, error) {
+	engineState, _, err := unstructured.NestedString(engine.Object, "spec", "engineState")
+	if err != nil {
+		return false, err
+	}
+	engineStatus, _, err := unstructured.NestedString(engine.Object, "status", "engineStatus")
+	if err != nil {
+		return false, err
+	}
+	if engineState != litmusEngineStateActive || engineStatus != litmusEngineStatusInitialized {
+		return false, nil
+	}
+	if experimentName == "" {
+		return true, nil
+	}
+
+	experiments, _, err := unstructured.NestedSlice(engine.Object, "status", "experiments")
+	if err != nil {
+		return false, err
+	}
+	for _, e := range experiments {
+		experiment, ok := e.(map[string]interface{})
+		if !ok || experiment["name"] != experimentName {
+			continue
+		}
+		if experiment["status"] == litmusExperimentStatusRunning {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (s *litmusChaosScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *litmusChaosScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetMetricValue := resource.NewQuantity(1, resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(fmt.Sprintf("litmus-chaos-%s-%s", s.metadata.namespace, s.metadata.chaosEngine))),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetMetricValue,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{External: externalMetric, Type: externalMetricType}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// GetMetrics returns desiredReplicas while the experiment runs and 0 otherwise, so the target
+// of 1 asks for desiredReplicas
+func (s *litmusChaosScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	var replicas int64
+	isActive, err := s.IsActive(ctx)
+	if err != nil {
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+	if isActive {
+		replicas = s.metadata.desiredReplicas
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewQuantity(replicas, resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
@@ -0,0 +1,125 @@
+package scalers
+
+import (
+	"context"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+type parseLitmusChaosMetadataTestData struct {
+	metadata map[string]string
+	isError  bool
+}
+
+type litmusChaosMetricIdentifier struct {
+	metadataTestData *parseLitmusChaosMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testLitmusChaosMetadata = []parseLitmusChaosMetadataTestData{
+	// nothing passed
+	{map[string]string{}, true},
+	// properly formed
+	{map[string]string{"chaosEngine": "checkout-chaos", "desiredReplicas": "6"}, false},
+	// experimentName and namespace
+	{map[string]string{"chaosEngine": "checkout-chaos", "experimentName": "pod-delete", "namespace": "litmus", "desiredReplicas": "6"}, false},
+	// missing chaosEngine
+	{map[string]string{"desiredReplicas": "6"}, true},
+	// missing desiredReplicas
+	{map[string]string{"chaosEngine": "checkout-chaos"}, true},
+	// malformed desiredReplicas
+	{map[string]string{"chaosEngine": "checkout-chaos", "desiredReplicas": "a"}, true},
+	// desiredReplicas below 1
+	{map[string]string{"chaosEngine": "checkout-chaos", "desiredReplicas": "0"}, true},
+}
+
+var litmusChaosMetricIdentifiers = []litmusChaosMetricIdentifier{
+	{&testLitmusChaosMetadata[1], 0, "s0-litmus-chaos-default-checkout-chaos"},
+	{&testLitmusChaosMetadata[2], 1, "s1-litmus-chaos-litmus-checkout-chaos"},
+}
+
+func TestParseLitmusChaosMetadata(t *testing.T) {
+	for _, testData := range testLitmusChaosMetadata {
+		_, err := parseLitmusChaosMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, Namespace: "default"})
+		if err != nil && !testData.isError {
+			t.Error("Expected success but got error", err)
+		}
+		if testData.isError && err == nil {
+			t.Error("Expected error but got success")
+		}
+	}
+}
+
+func TestLitmusChaosGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range litmusChaosMetricIdentifiers {
+		meta, err := parseLitmusChaosMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, Namespace: "default", ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockLitmusChaosScaler := litmusChaosScaler{metadata: meta}
+
+		metricSpec := mockLitmusChaosScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Error("Wrong External metric source name:", metricName)
+		}
+	}
+}
+
+func createChaosEngine(engineState, engineStatus, experimentStatus string) *unstructured.Unstructured {
+	engine := &unstructured.Unstructured{Object: map[string]interface{}{
+		"spec": map[string]interface{}{"engineState": engineState},
+		"status": map[string]interface{}{
+			"engineStatus": engineStatus,
+			"experiments": []interface{}{
+				map[string]interface{}{"name": "pod-delete", "status": experimentStatus},
+			},
+		},
+	}}
+	engine.SetGroupVersionKind(litmusChaosEngineGVK)
+	engine.SetName("checkout-chaos")
+	engine.SetNamespace("default")
+	return engine
+}
+
+func TestLitmusChaosGetMetrics(t *testing.T) {
+	var testData = []struct {
+		name           string
+		engine         *unstructured.Unstructured
+		experimentName string
+		expected       int64
+	}{
+		{"no ChaosEngine", nil, "", 0},
+		{"running", createChaosEngine("active", "initialized", "Running"), "", 6},
+		{"running experiment", createChaosEngine("active", "initialized", "Running"), "pod-delete", 6},
+		{"other experiment", createChaosEngine("active", "initialized", "Running"), "network-loss", 0},
+		{"experiment completed", createChaosEngine("active", "initialized", "Completed"), "pod-delete", 0},
+		{"engine completed", createChaosEngine("stop", "completed", "Completed"), "", 0},
+		{"engine stopped", createChaosEngine("stop", "stopped", "Stopped"), "", 0},
+	}
+
+	for _, test := range testData {
+		clientBuilder := fake.NewClientBuilder()
+		if test.engine != nil {
+			clientBuilder = clientBuilder.WithRuntimeObjects(test.engine)
+		}
+		scaler, err := NewLitmusChaosScaler(clientBuilder.Build(), &ScalerConfig{
+			TriggerMetadata: map[string]string{"chaosEngine": "checkout-chaos", "experimentName": test.experimentName, "desiredReplicas": "6"},
+			Namespace:       "default",
+		})
+		if err != nil {
+			t.Fatal("Could not create scaler:", err)
+		}
+
+		metrics, err := scaler.GetMetrics(context.Background(), "MetricName", nil)
+		if err != nil {
+			t.Fatalf("Could not get metrics for %s: %s", test.name, err)
+		}
+		if metrics[0].Value.Value() != test.expected {
+			t.Errorf("Expected %d replicas for %s but got %d", test.expected, test.name, metrics[0].Value.Value())
+		}
+	}
+}
@@ -0,0 +1,313 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	url_pkg "net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/kedacore/keda/v2/pkg/scalers/authentication"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	lokiServerAddress       = "serverAddress"
+	lokiMetricName          = "metricName"
+	lokiQuery               = "query"
+	lokiThreshold           = "threshold"
+	lokiActivationThreshold = "activationThreshold"
+	lokiTenantName          = "tenantName"
+
+	defaultLokiMetricName = "loki"
+)
+
+type lokiScaler struct {
+	metadata   *lokiMetadata
+	httpClient *http.Client
+}
+
+type lokiMetadata struct {
+	serverAddress       string
+	metricName          string
+	query               string
+	threshold           float64
+	activationThreshold float64
+	// tenantName is sent as X-Scope-OrgID to multi-tenant Loki
+	tenantName string
+
+	// bearer auth
+	enableBearerAuth bool
+	bearerToken      string
+
+	// basic auth
+	enableBasicAuth bool
+	username        string
+	password        string // +optional
+
+	scalerIndex int
+}
+
+type lokiQueryResult struct {
+	Status string `json:"status"`
+	Data   struct {
+		ResultType string          `json:"resultType"`
+		Result     json.RawMessage `json:"result"`
+	} `json:"data"`
+}
+
+type lokiVectorSample struct {
+	Metric map[string]string `json:"metric"`
+	Value  []interface{}     `json:"value"`
+}
+
+var lokiLog = logf.Log.WithName("loki_scaler")
+
+// NewLokiScaler creates a new lokiScaler
+func NewLokiScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseLokiMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing loki metadata: %s", err)
+	}
+
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &lokiScaler{
+		metadata:   meta,
+		httpClient: httpClient,
+	}, nil
+}
+
+func parseLokiMetadata(config *ScalerConfig) (*lokiMetadata, error) {
+	meta := lokiMetadata{
+		metricName: defaultLokiMetricName,
+	}
+
+	if val, ok := config.TriggerMetadata[lokiServerAddress]; ok && val != "" {
+		meta.serverAddress = strings.TrimSuffix(val, "/")
+	} else {
+		return nil, fmt.Errorf("no %s given", lokiServerAddress)
+	}
+
+	if val, ok := config.TriggerMetadata[lokiQuery]; ok && val != "" {
+		meta.query = val
+	} else {
+		return nil, fmt.Errorf("no %s given", lokiQuery)
+	}
+
+	if val, ok := config.TriggerMetadata[lokiMetricName]; ok && val != "" {
+		meta.metricName = val
+	}
+
+	if val, ok := config.TriggerMetadata[lokiThreshold]; ok && val != "" {
+		t, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", lokiThreshold, err)
+		}
+		meta.threshold = t
+	} else {
+		return nil, fmt.Errorf("no %s given", lokiThreshold)
+	}
+
+	if val, ok := config.TriggerMetadata[lokiActivationThreshold]; ok && val != "" {
+		t, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", lokiActivationThreshold, err)
+		}
+		meta.activationThreshold = t
+	}
+
+	meta.tenantName = config.TriggerMetadata[lokiTenantName]
+
+	meta.scalerIndex = config.ScalerIndex
+
+	authModes, ok := config.TriggerMetadata["authModes"]
+	// no authMode specified
+	if !ok {
+		return &meta, nil
+	}
+
+	for _, t := range strings.Split(authModes, ",") {
+		authType := authentication.Type(strings.TrimSpace(t))
+		switch authType {
+		case authentication.BearerAuthType:
+			if len(config.AuthParams["bearerToken"]) == 0 {
+				return nil, errors.New("no bearer token provided")
+			}
+			if meta.enableBasicAuth {
+				return nil, errors.New("bearer and basic authentication can not be set both")
+			}
+
+			meta.bearerToken = config.AuthParams["bearerToken"]
+			meta.enableBearerAuth = true
+		case authentication.BasicAuthType:
+			if len(config.AuthParams["username"]) == 0 {
+				return nil, errors.New("no username given")
+			}
+			if meta.enableBearerAuth {
+				return nil, errors.New("bearer and basic authentication can not be set both")
+			}
+
+			meta.username = config.AuthParams["username"]
+			meta.password = config.AuthParams["password"]
+			meta.enableBasicAuth = true
+		case authentication.TLSAuthType:
+			// the client certificate is read by createHTTPClient
+			if len(config.AuthParams["cert"]) == 0 {
+				return nil, errors.New("no cert given")
+			}
+			if len(config.AuthParams["key"]) == 0 {
+				return nil, errors.New("no key given")
+			}
+		default:
+			return nil, fmt.Errorf("err incorrect value for authMode is given: %s", t)
+		}
+	}
+
+	return &meta, nil
+}
+
+func (s *lokiScaler) IsActive(ctx context.Context) (bool, error) {
+	val, err := s.ExecuteLokiQuery(ctx)
+	if err != nil {
+		lokiLog.Error(err, "error executing loki query")
+		return false, err
+	}
+
+	return val > s.metadata.activationThreshold, nil
+}
+
+func (s *lokiScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *lokiScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetMetricValue := resource.NewMilliQuantity(int64(s.metadata.threshold*1000), resource.DecimalSI)
+	metricName := kedautil.NormalizeString(fmt.Sprintf("loki-%s", s.metadata.metricName))
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, metricName),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetMetricValue,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// ExecuteLokiQuery runs the LogQL metric query as an instant query and returns its single value,
+// an empty result is 0
+func (s *lokiScaler) ExecuteLokiQuery(ctx context.Context) (float64, error) {
+	url := fmt.Sprintf("%s/loki/api/v1/query?query=%s&time=%d", s.metadata.serverAddress, url_pkg.QueryEscape(s.metadata.query), time.Now().UnixNano())
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return -1, err
+	}
+
+	if s.metadata.tenantName != "" {
+		req.Header.Add("X-Scope-OrgID", s.metadata.tenantName)
+	}
+	if s.metadata.enableBearerAuth {
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", s.metadata.bearerToken))
+	} else if s.metadata.enableBasicAuth {
+		req.SetBasicAuth(s.metadata.username, s.metadata.password)
+	}
+
+	r, err := s.httpClient.Do(req)
+	if err != nil {
+		return -1, err
+	}
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return -1, err
+	}
+	r.Body.Close()
+
+	if !(r.StatusCode >= 200 && r.StatusCode <= 299) {
+		return -1, fmt.Errorf("loki query api returned error. status: %d response: %s", r.StatusCode, string(b))
+	}
+
+	var result lokiQueryResult
+	err = json.Unmarshal(b, &result)
+	if err != nil {
+		return -1, err
+	}
+
+	var value []interface{}
+	switch result.Data.ResultType {
+	case "", "vector":
+		var samples []lokiVectorSample
+		if len(result.Data.Result) > 0 {
+			if err := json.Unmarshal(result.Data.Result, &samples); err != nil {
+				return -1, err
+			}
+		}
+		// allow for zero element or single element result sets
+		if len(samples) == 0 {
+			return 0, nil
+		} else if len(samples) > 1 {
+			return -1, fmt.Errorf("loki query %s returned multiple elements", s.metadata.query)
+		}
+		value = samples[0].Value
+	case "scalar":
+		if err := json.Unmarshal(result.Data.Result, &value); err != nil {
+			return -1, err
+		}
+	default:
+		return -1, fmt.Errorf("loki query %s returned %s, it must be a metric query returning a vector or a scalar", s.metadata.query, result.Data.ResultType)
+	}
+
+	if len(value) == 0 {
+		return 0, nil
+	} else if len(value) < 2 {
+		return -1, fmt.Errorf("loki query %s didn't return enough values", s.metadata.query)
+	}
+
+	val, ok := value[1].(string)
+	if !ok {
+		return -1, fmt.Errorf("loki query %s returned a value of type %T, expected a string", s.metadata.query, value[1])
+	}
+	v, err := strconv.ParseFloat(val, 64)
+	if err != nil {
+		lokiLog.Error(err, "Error converting loki value", "loki_value", val)
+		return -1, err
+	}
+
+	return v, nil
+}
+
+func (s *lokiScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	val, err := s.ExecuteLokiQuery(ctx)
+	if err != nil {
+		lokiLog.Error(err, "error executing loki query")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewMilliQuantity(int64(val*1000), resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
@@ -0,0 +1,220 @@
+package scalers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type parseLokiMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+}
+
+type lokiMetricIdentifier struct {
+	metadataTestData *parseLokiMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testLokiMetadata = []parseLokiMetadataTestData{
+	{map[string]string{}, map[string]string{}, true},
+	// all properly formed
+	{map[string]string{"serverAddress": "http://localhost:3100", "threshold": "100", "query": "sum(rate({app=\"nginx\"}[1m]))"}, map[string]string{}, false},
+	// with metricName, activationThreshold and tenantName
+	{map[string]string{"serverAddress": "http://localhost:3100", "metricName": "nginx_lines", "threshold": "0.5", "activationThreshold": "1.5", "tenantName": "team-a", "query": "sum(rate({app=\"nginx\"}[1m]))"}, map[string]string{}, false},
+	// missing serverAddress
+	{map[string]string{"serverAddress": "", "threshold": "100", "query": "sum(rate({app=\"nginx\"}[1m]))"}, map[string]string{}, true},
+	// missing query
+	{map[string]string{"serverAddress": "http://localhost:3100", "threshold": "100", "query": ""}, map[string]string{}, true},
+	// missing threshold
+	{map[string]string{"serverAddress": "http://localhost:3100", "query": "sum(rate({app=\"nginx\"}[1m]))"}, map[string]string{}, true},
+	// malformed threshold
+	{map[string]string{"serverAddress": "http://localhost:3100", "threshold": "one", "query": "sum(rate({app=\"nginx\"}[1m]))"}, map[string]string{}, true},
+	// malformed activationThreshold
+	{map[string]string{"serverAddress": "http://localhost:3100", "threshold": "100", "activationThreshold": "one", "query": "sum(rate({app=\"nginx\"}[1m]))"}, map[string]string{}, true},
+	// success bearer
+	{map[string]string{"serverAddress": "http://localhost:3100", "threshold": "100", "query": "sum(rate({app=\"nginx\"}[1m]))", "authModes": "bearer"}, map[string]string{"bearerToken": "tooooken"}, false},
+	// fail bearer with no token
+	{map[string]string{"serverAddress": "http://localhost:3100", "threshold": "100", "query": "sum(rate({app=\"nginx\"}[1m]))", "authModes": "bearer"}, map[string]string{}, true},
+	// success basic
+	{map[string]string{"serverAddress": "http://localhost:3100", "threshold": "100", "query": "sum(rate({app=\"nginx\"}[1m]))", "authModes": "basic"}, map[string]string{"username": "user", "password": "pass"}, false},
+	// fail bearer and basic
+	{map[string]string{"serverAddress": "http://localhost:3100", "threshold": "100", "query": "sum(rate({app=\"nginx\"}[1m]))", "authModes": "bearer,basic"}, map[string]string{"bearerToken": "tooooken", "username": "user"}, true},
+	// fail TLS, key not given
+	{map[string]string{"serverAddress": "http://localhost:3100", "threshold": "100", "query": "sum(rate({app=\"nginx\"}[1m]))", "authModes": "tls"}, map[string]string{"cert": "ceert"}, true},
+}
+
+var lokiMetricIdentifiers = []lokiMetricIdentifier{
+	{&testLokiMetadata[1], 0, "s0-loki-loki"},
+	{&testLokiMetadata[2], 1, "s1-loki-nginx_lines"},
+}
+
+func TestLokiParseMetadata(t *testing.T) {
+	for _, testData := range testLokiMetadata {
+		_, err := parseLokiMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Error("Expected success but got error", err)
+		}
+		if testData.isError && err == nil {
+			t.Error("Expected error but got success")
+		}
+	}
+}
+
+func TestLokiGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range lokiMetricIdentifiers {
+		meta, err := parseLokiMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, AuthParams: testData.metadataTestData.authParams, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockLokiScaler := lokiScaler{
+			metadata:   meta,
+			httpClient: http.DefaultClient,
+		}
+
+		metricSpec := mockLokiScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Error("Wrong External metric source name:", metricName)
+		}
+	}
+}
+
+type lokiQueryResultTestData struct {
+	name           string
+	bodyStr        string
+	responseStatus int
+	expectedValue  float64
+	isError        bool
+}
+
+var testLokiQueryResult = []lokiQueryResultTestData{
+	{
+		name:           "no results",
+		bodyStr:        `{}`,
+		responseStatus: http.StatusOK,
+		expectedValue:  0,
+		isError:        false,
+	},
+	{
+		name:           "no values",
+		bodyStr:        `{"status":"success","data":{"resultType":"vector","result":[]}}`,
+		responseStatus: http.StatusOK,
+		expectedValue:  0,
+		isError:        false,
+	},
+	{
+		name:           "vector value",
+		bodyStr:        `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1588889221,"1267.5"]}]}}`,
+		responseStatus: http.StatusOK,
+		expectedValue:  1267.5,
+		isError:        false,
+	},
+	{
+		name:           "scalar value",
+		bodyStr:        `{"status":"success","data":{"resultType":"scalar","result":[1588889221,"2"]}}`,
+		responseStatus: http.StatusOK,
+		expectedValue:  2,
+		isError:        false,
+	},
+	{
+		name:           "not enough values",
+		bodyStr:        `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1588889221]}]}}`,
+		responseStatus: http.StatusOK,
+		expectedValue:  -1,
+		isError:        true,
+	},
+	{
+		name:           "multiple results",
+		bodyStr:        `{"status":"success","data":{"resultType":"vector","result":[{"metric":{"app":"a"}},{"metric":{"app":"b"}}]}}`,
+		responseStatus: http.StatusOK,
+		expectedValue:  -1,
+		isError:        true,
+	},
+	{
+		name:           "log query",
+		bodyStr:        `{"status":"success","data":{"resultType":"streams","result":[{"stream":{"app":"nginx"},"values":[["1588889221000000000","GET /"]]}]}}`,
+		responseStatus: http.StatusOK,
+		expectedValue:  -1,
+		isError:        true,
+	},
+	{
+		name:           "error status response",
+		bodyStr:        `{}`,
+		responseStatus: http.StatusBadRequest,
+		expectedValue:  -1,
+		isError:        true,
+	},
+}
+
+func TestLokiScalerExecuteLokiQuery(t *testing.T) {
+	for _, testData := range testLokiQueryResult {
+		testData := testData
+		t.Run(testData.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				assert.Equal(t, "/loki/api/v1/query", request.URL.Path)
+				assert.Equal(t, "sum(rate({app=\"nginx\"}[1m]))", request.URL.Query().Get("query"))
+				assert.Equal(t, "team-a", request.Header.Get("X-Scope-OrgID"))
+				writer.WriteHeader(testData.responseStatus)
+
+				if _, err := writer.Write([]byte(testData.bodyStr)); err != nil {
+					t.Fatal(err)
+				}
+			}))
+			defer server.Close()
+
+			scaler := lokiScaler{
+				metadata: &lokiMetadata{
+					serverAddress: server.URL,
+					query:         "sum(rate({app=\"nginx\"}[1m]))",
+					tenantName:    "team-a",
+				},
+				httpClient: http.DefaultClient,
+			}
+
+			value, err := scaler.ExecuteLokiQuery(context.TODO())
+
+			assert.Equal(t, testData.expectedValue, value)
+
+			if testData.isError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestLokiScalerIsActive(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte(`{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1588889221,"1.5"]}]}}`))
+	}))
+	defer server.Close()
+
+	for _, testData := range []struct {
+		activationThreshold float64
+		isActive            bool
+	}{
+		{0, true},
+		{1, true},
+		{1.5, false},
+	} {
+		scaler := lokiScaler{
+			metadata: &lokiMetadata{
+				serverAddress:       server.URL,
+				query:               "sum(rate({app=\"nginx\"}[1m]))",
+				activationThreshold: testData.activationThreshold,
+			},
+			httpClient: http.DefaultClient,
+		}
+
+		active, err := scaler.IsActive(context.TODO())
+		assert.NoError(t, err)
+		assert.Equal(t, testData.isActive, active, "activationThreshold %v", testData.activationThreshold)
+	}
+}
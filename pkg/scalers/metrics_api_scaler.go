@@ -26,11 +26,14 @@ import (
 type metricsAPIScaler struct {
 	metadata *metricsAPIScalerMetadata
 	client   *http.Client
+	health   *failoverHealth
 }
 
 type metricsAPIScalerMetadata struct {
-	targetValue   int
-	url           string
+	targetValue int
+	url         string
+	// failoverURLs are the urls requested in order when url fails, commas in them have to be escaped as %2C
+	failoverURLs  []string
 	valueLocation string
 
 	// apiKeyAuth
@@ -72,20 +75,15 @@ func NewMetricsAPIScaler(config *ScalerConfig) (Scaler, error) {
 		return nil, fmt.Errorf("error parsing metric API metadata: %s", err)
 	}
 
-	httpClient := kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, false)
-
-	if meta.enableTLS || len(meta.ca) > 0 {
-		config, err := kedautil.NewTLSConfig(meta.cert, meta.key, meta.ca)
-		if err != nil {
-			return nil, err
-		}
-
-		httpClient.Transport = &http.Transport{TLSClientConfig: config}
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
 	}
 
 	return &metricsAPIScaler{
 		metadata: meta,
 		client:   httpClient,
+		health:   newFailoverHealth(httpLog),
 	}, nil
 }
 
@@ -109,6 +107,12 @@ func parseMetricsAPIMetadata(config *ScalerConfig) (*metricsAPIScalerMetadata, e
 		return nil, fmt.Errorf("no url given in metadata")
 	}
 
+	failoverURLs, err := parseFailoverAddresses(config)
+	if err != nil {
+		return nil, err
+	}
+	meta.failoverURLs = failoverURLs
+
 	if val, ok := config.TriggerMetadata["valueLocation"]; ok {
 		meta.valueLocation = val
 	} else {
@@ -201,7 +205,27 @@ func GetValueFromResponse(body []byte, valueLocation string) (*resource.Quantity
 }
 
 func (s *metricsAPIScaler) getMetricValue(ctx context.Context) (*resource.Quantity, error) {
-	request, err := getMetricAPIServerRequest(ctx, s.metadata)
+	urls := append([]string{s.metadata.url}, s.metadata.failoverURLs...)
+
+	var b []byte
+	err := s.health.do(ctx, urls, func(i int) error {
+		var err error
+		b, err = s.requestMetricAPI(ctx, urls[i])
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	v, err := GetValueFromResponse(b, s.metadata.valueLocation)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// requestMetricAPI returns the response body of url, a request rejected by the api isn't retried on the failover urls
+func (s *metricsAPIScaler) requestMetricAPI(ctx context.Context, url string) ([]byte, error) {
+	request, err := getMetricAPIServerRequest(ctx, s.metadata, url)
 	if err != nil {
 		return nil, err
 	}
@@ -210,22 +234,17 @@ func (s *metricsAPIScaler) getMetricValue(ctx context.Context) (*resource.Quanti
 	if err != nil {
 		return nil, err
 	}
+	defer r.Body.Close()
 
 	if r.StatusCode != http.StatusOK {
 		msg := fmt.Sprintf("api returned %d", r.StatusCode)
+		if r.StatusCode < http.StatusInternalServerError {
+			return nil, noFailover(errors.New(msg))
+		}
 		return nil, errors.New(msg)
 	}
 
-	defer r.Body.Close()
-	b, err := ioutil.ReadAll(r.Body)
-	if err != nil {
-		return nil, err
-	}
-	v, err := GetValueFromResponse(b, s.metadata.valueLocation)
-	if err != nil {
-		return nil, err
-	}
-	return v, nil
+	return ioutil.ReadAll(r.Body)
 }
 
 // Close does nothing in case of metricsAPIScaler
@@ -278,29 +297,29 @@ func (s *metricsAPIScaler) GetMetrics(ctx context.Context, metricName string, me
 	return append([]external_metrics.ExternalMetricValue{}, metric), nil
 }
 
-func getMetricAPIServerRequest(ctx context.Context, meta *metricsAPIScalerMetadata) (*http.Request, error) {
+func getMetricAPIServerRequest(ctx context.Context, meta *metricsAPIScalerMetadata, url string) (*http.Request, error) {
 	var req *http.Request
 	var err error
 
 	switch {
 	case meta.enableAPIKeyAuth:
 		if meta.method == methodValueQuery {
-			url, _ := neturl.Parse(meta.url)
-			queryString := url.Query()
+			u, _ := neturl.Parse(url)
+			queryString := u.Query()
 			if len(meta.keyParamName) == 0 {
 				queryString.Set("api_key", meta.apiKey)
 			} else {
 				queryString.Set(meta.keyParamName, meta.apiKey)
 			}
 
-			url.RawQuery = queryString.Encode()
-			req, err = http.NewRequestWithContext(ctx, "GET", url.String(), nil)
+			u.RawQuery = queryString.Encode()
+			req, err = http.NewRequestWithContext(ctx, "GET", u.String(), nil)
 			if err != nil {
 				return nil, err
 			}
 		} else {
 			// default behaviour is to use header method
-			req, err = http.NewRequestWithContext(ctx, "GET", meta.url, nil)
+			req, err = http.NewRequestWithContext(ctx, "GET", url, nil)
 			if err != nil {
 				return nil, err
 			}
@@ -312,20 +331,20 @@ func getMetricAPIServerRequest(ctx context.Context, meta *metricsAPIScalerMetada
 			}
 		}
 	case meta.enableBaseAuth:
-		req, err = http.NewRequestWithContext(ctx, "GET", meta.url, nil)
+		req, err = http.NewRequestWithContext(ctx, "GET", url, nil)
 		if err != nil {
 			return nil, err
 		}
 
 		req.SetBasicAuth(meta.username, meta.password)
 	case meta.enableBearerAuth:
-		req, err = http.NewRequestWithContext(ctx, "GET", meta.url, nil)
+		req, err = http.NewRequestWithContext(ctx, "GET", url, nil)
 		if err != nil {
 			return nil, err
 		}
 		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", meta.bearerToken))
 	default:
-		req, err = http.NewRequestWithContext(ctx, "GET", meta.url, nil)
+		req, err = http.NewRequestWithContext(ctx, "GET", url, nil)
 		if err != nil {
 			return nil, err
 		}
@@ -27,6 +27,10 @@ var testMetricsAPIMetadata = []metricsAPIMetadataTestData{
 	{metadata: map[string]string{"valueLocation": "metric", "targetValue": "aa"}, raisesError: true},
 	// Missing targetValue
 	{metadata: map[string]string{"url": "http://dummy:1230/api/v1/", "valueLocation": "metric"}, raisesError: true},
+	// failoverAddresses
+	{metadata: map[string]string{"url": "http://dummy:1230/api/v1/", "failoverAddresses": "http://eu.dummy:1230/api/v1/", "valueLocation": "metric.test", "targetValue": "42"}, raisesError: false},
+	// malformed failoverAddresses
+	{metadata: map[string]string{"url": "http://dummy:1230/api/v1/", "failoverAddresses": "dummy", "valueLocation": "metric.test", "targetValue": "42"}, raisesError: true},
 }
 
 type metricAPIAuthMetadataTestData struct {
@@ -0,0 +1,238 @@
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	minioMetricObjectCount = "objectCount"
+	minioMetricUsageBytes  = "usageBytes"
+
+	defaultMinioMetricsPath = "/minio/v2/metrics/cluster"
+)
+
+// minioMetrics maps the supported metric aliases to the MinIO Prometheus metric names
+var minioMetrics = map[string]string{
+	minioMetricObjectCount: "minio_bucket_usage_object_total",
+	minioMetricUsageBytes:  "minio_bucket_usage_total_bytes",
+}
+
+type minioScaler struct {
+	metadata   *minioMetadata
+	httpClient *http.Client
+}
+
+type minioMetadata struct {
+	endpoint    string
+	metricsPath string
+	bucket      string
+	metric      string
+	targetValue int64
+
+	// auth
+	bearerToken string
+
+	scalerIndex int
+}
+
+var minioLog = logf.Log.WithName("minio_scaler")
+
+// NewMinioScaler creates a new minioScaler
+func NewMinioScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseMinioMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing minio metadata: %s", err)
+	}
+
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &minioScaler{
+		metadata:   meta,
+		httpClient: httpClient,
+	}, nil
+}
+
+func parseMinioMetadata(config *ScalerConfig) (*minioMetadata, error) {
+	meta := minioMetadata{
+		metricsPath: defaultMinioMetricsPath,
+		metric:      minioMetricObjectCount,
+	}
+
+	if val, ok := config.TriggerMetadata["endpoint"]; ok && val != "" {
+		meta.endpoint = strings.TrimSuffix(val, "/")
+	} else {
+		return nil, fmt.Errorf("no endpoint given")
+	}
+
+	if val, ok := config.TriggerMetadata["metricsPath"]; ok && val != "" {
+		meta.metricsPath = val
+	}
+
+	if val, ok := config.TriggerMetadata["metric"]; ok && val != "" {
+		meta.metric = val
+	}
+	if _, ok := minioMetrics[meta.metric]; !ok && !strings.HasPrefix(meta.metric, "minio_") {
+		return nil, fmt.Errorf("metric must be %s, %s or the name of a MinIO metric starting with minio_", minioMetricObjectCount, minioMetricUsageBytes)
+	}
+
+	meta.bucket = config.TriggerMetadata["bucket"]
+	if _, ok := minioMetrics[meta.metric]; ok && meta.bucket == "" {
+		return nil, fmt.Errorf("no bucket given")
+	}
+
+	if val, ok := config.TriggerMetadata["targetValue"]; ok && val != "" {
+		targetValue, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing targetValue: %s", err)
+		}
+		meta.targetValue = targetValue
+	} else {
+		return nil, fmt.Errorf("no targetValue given")
+	}
+
+	// MinIO requires a JWT generated with `mc admin prometheus generate` unless MINIO_PROMETHEUS_AUTH_TYPE is public
+	meta.bearerToken = config.AuthParams["bearerToken"]
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+func (s *minioScaler) IsActive(ctx context.Context) (bool, error) {
+	val, err := s.getValue(ctx)
+	if err != nil {
+		minioLog.Error(err, "error getting minio metric")
+		return false, err
+	}
+
+	return val > 0, nil
+}
+
+func (s *minioScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *minioScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetMetricValue := resource.NewQuantity(s.metadata.targetValue, resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(strings.TrimSuffix(fmt.Sprintf("minio-%s-%s", s.metadata.metric, s.metadata.bucket), "-"))),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetMetricValue,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+func (s *minioScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	val, err := s.getValue(ctx)
+	if err != nil {
+		minioLog.Error(err, "error getting minio metric")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewQuantity(int64(val), resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
+
+// getValue scrapes the MinIO metrics endpoint directly, so no Prometheus server is needed
+func (s *minioScaler) getValue(ctx context.Context) (float64, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", s.metadata.endpoint+s.metadata.metricsPath, nil)
+	if err != nil {
+		return -1, err
+	}
+	if s.metadata.bearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+s.metadata.bearerToken)
+	}
+
+	r, err := s.httpClient.Do(req)
+	if err != nil {
+		return -1, err
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode != http.StatusOK {
+		return -1, fmt.Errorf("minio metrics endpoint returned %d", r.StatusCode)
+	}
+
+	var parser expfmt.TextParser
+	families, err := parser.TextToMetricFamilies(r.Body)
+	if err != nil {
+		return -1, fmt.Errorf("error parsing minio metrics: %s", err)
+	}
+
+	metricName := s.metadata.metric
+	if name, ok := minioMetrics[metricName]; ok {
+		metricName = name
+	}
+	family, ok := families[metricName]
+	if !ok {
+		return -1, fmt.Errorf("metric %s not found in minio metrics", metricName)
+	}
+
+	return sumMinioMetric(family, s.metadata.bucket)
+}
+
+// sumMinioMetric adds up the samples of the family, eg. the per node series of the notification
+// metrics, only the series of the bucket are used when a bucket is given
+func sumMinioMetric(family *dto.MetricFamily, bucket string) (float64, error) {
+	var sum float64
+	found := false
+	for _, metric := range family.GetMetric() {
+		if bucket != "" && minioMetricLabel(metric, "bucket") != bucket {
+			continue
+		}
+		switch {
+		case metric.GetGauge() != nil:
+			sum += metric.GetGauge().GetValue()
+		case metric.GetCounter() != nil:
+			sum += metric.GetCounter().GetValue()
+		case metric.GetUntyped() != nil:
+			sum += metric.GetUntyped().GetValue()
+		default:
+			return -1, fmt.Errorf("metric %s is not a gauge or counter", family.GetName())
+		}
+		found = true
+	}
+	if !found {
+		return -1, fmt.Errorf("no series of metric %s found for bucket %s", family.GetName(), bucket)
+	}
+	return sum, nil
+}
+
+func minioMetricLabel(metric *dto.Metric, name string) string {
+	for _, label := range metric.GetLabel() {
+		if label.GetName() == name {
+			return label.GetValue()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,135 @@
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type parseMinioMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+}
+
+type minioMetricIdentifier struct {
+	metadataTestData *parseMinioMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testMinioMetadata = []parseMinioMetadataTestData{
+	// nothing passed
+	{map[string]string{}, map[string]string{}, true},
+	// properly formed
+	{map[string]string{"endpoint": "http://minio:9000", "bucket": "uploads", "targetValue": "100"}, map[string]string{"bearerToken": "token"}, false},
+	// usageBytes with metricsPath
+	{map[string]string{"endpoint": "http://minio:9000/", "bucket": "uploads", "metric": "usageBytes", "metricsPath": "/minio/v2/metrics/bucket", "targetValue": "1000000"}, map[string]string{}, false},
+	// raw metric without bucket
+	{map[string]string{"endpoint": "http://minio:9000", "metric": "minio_notify_target_queue_length", "targetValue": "10"}, map[string]string{}, false},
+	// missing endpoint
+	{map[string]string{"bucket": "uploads", "targetValue": "100"}, map[string]string{}, true},
+	// missing bucket
+	{map[string]string{"endpoint": "http://minio:9000", "targetValue": "100"}, map[string]string{}, true},
+	// unknown metric
+	{map[string]string{"endpoint": "http://minio:9000", "bucket": "uploads", "metric": "objects", "targetValue": "100"}, map[string]string{}, true},
+	// missing targetValue
+	{map[string]string{"endpoint": "http://minio:9000", "bucket": "uploads"}, map[string]string{}, true},
+	// malformed targetValue
+	{map[string]string{"endpoint": "http://minio:9000", "bucket": "uploads", "targetValue": "many"}, map[string]string{}, true},
+}
+
+var minioMetricIdentifiers = []minioMetricIdentifier{
+	{&testMinioMetadata[1], 0, "s0-minio-objectCount-uploads"},
+	{&testMinioMetadata[2], 1, "s1-minio-usageBytes-uploads"},
+	{&testMinioMetadata[3], 2, "s2-minio-minio_notify_target_queue_length"},
+}
+
+func TestMinioParseMetadata(t *testing.T) {
+	for idx, testData := range testMinioMetadata {
+		_, err := parseMinioMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Errorf("Test %d: expected success but got error: %s", idx, err)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Test %d: expected error but got success", idx)
+		}
+	}
+}
+
+func TestMinioGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range minioMetricIdentifiers {
+		meta, err := parseMinioMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, AuthParams: testData.metadataTestData.authParams, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockMinioScaler := minioScaler{metadata: meta}
+
+		metricSpec := mockMinioScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Errorf("Wrong External metric source name: %s, expected: %s", metricName, testData.name)
+		}
+	}
+}
+
+const testMinioMetrics = `# HELP minio_bucket_usage_object_total Total number of objects
+# TYPE minio_bucket_usage_object_total gauge
+minio_bucket_usage_object_total{bucket="uploads",server="minio-0:9000"} 42
+minio_bucket_usage_object_total{bucket="archive",server="minio-0:9000"} 1000
+# HELP minio_bucket_usage_total_bytes Total bucket size in bytes
+# TYPE minio_bucket_usage_total_bytes gauge
+minio_bucket_usage_total_bytes{bucket="uploads",server="minio-0:9000"} 2048
+# HELP minio_notify_target_queue_length Number of events currently staged in the queue
+# TYPE minio_notify_target_queue_length gauge
+minio_notify_target_queue_length{server="minio-0:9000",target_id="1",target_name="webhook"} 3
+minio_notify_target_queue_length{server="minio-1:9000",target_id="1",target_name="webhook"} 4
+`
+
+func TestMinioGetValue(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprint(w, testMinioMetrics)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		metric  string
+		bucket  string
+		value   float64
+		isError bool
+	}{
+		{"objectCount", "uploads", 42, false},
+		{"usageBytes", "uploads", 2048, false},
+		{"minio_notify_target_queue_length", "", 7, false},
+		{"objectCount", "missing", 0, true},
+		{"minio_missing_metric", "", 0, true},
+	}
+	for _, test := range tests {
+		scaler, err := NewMinioScaler(&ScalerConfig{
+			TriggerMetadata: map[string]string{"endpoint": server.URL, "metric": test.metric, "bucket": test.bucket, "targetValue": "1"},
+			AuthParams:      map[string]string{"bearerToken": "token"},
+		})
+		if err != nil {
+			t.Fatal("Could not create scaler:", err)
+		}
+		value, err := scaler.(*minioScaler).getValue(context.Background())
+		if test.isError {
+			if err == nil {
+				t.Errorf("Expected error for metric %s bucket %s but got success", test.metric, test.bucket)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Expected success for metric %s bucket %s but got error: %s", test.metric, test.bucket, err)
+		}
+		if value != test.value {
+			t.Errorf("Expected value %f for metric %s bucket %s, got %f", test.value, test.metric, test.bucket, value)
+		}
+	}
+}
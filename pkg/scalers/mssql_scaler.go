@@ -1,9 +1,13 @@
+//go:build !selective_scalers || scalers_database
+// +build !selective_scalers scalers_database
+
 package scalers
 
 import (
 	"context"
 	"database/sql"
 	"fmt"
+	"math"
 	"net/url"
 	"strconv"
 
@@ -47,8 +51,16 @@ type mssqlMetadata struct {
 	// +optional
 	database string
 	// The T-SQL query to run against the target database - e.g. SELECT COUNT(*) FROM table.
-	// +required
+	// Required unless elasticPoolName is given.
+	// +optional
 	query string
+	// The name of an Azure SQL elastic pool to scale on the saturation of, instead of the query.
+	// The resource stats of the pools are read from the master database of the logical server.
+	// +optional
+	elasticPoolName string
+	// The saturation of the elastic pool to scale on, dtu (the highest of cpu, data io and log write percent) or worker.
+	// +optional
+	saturationMetric string
 	// The threshold that is used as targetAverageValue in the Horizontal Pod Autoscaler.
 	// +required
 	targetValue int
@@ -60,6 +72,23 @@ type mssqlMetadata struct {
 	scalerIndex int
 }
 
+const (
+	mssqlSaturationMetricDTU    = "dtu"
+	mssqlSaturationMetricWorker = "worker"
+)
+
+// mssqlElasticPoolResourceStatsQuery reads the latest resource stats of an elastic pool, the values are percentages of the pool limits
+const mssqlElasticPoolResourceStatsQuery = `SELECT TOP 1 avg_cpu_percent, avg_data_io_percent, avg_log_write_percent, max_worker_percent
+FROM sys.elastic_pool_resource_stats WHERE elastic_pool_name = @p1 ORDER BY end_time DESC`
+
+// mssqlElasticPoolResourceStats is a row of sys.elastic_pool_resource_stats
+type mssqlElasticPoolResourceStats struct {
+	avgCPUPercent      float64
+	avgDataIOPercent   float64
+	avgLogWritePercent float64
+	maxWorkerPercent   float64
+}
+
 var mssqlLog = logf.Log.WithName("mssql_scaler")
 
 // NewMSSQLScaler creates a new mssql scaler
@@ -84,13 +113,29 @@ func NewMSSQLScaler(config *ScalerConfig) (Scaler, error) {
 func parseMSSQLMetadata(config *ScalerConfig) (*mssqlMetadata, error) {
 	meta := mssqlMetadata{}
 
+	// Elastic pool saturation instead of a query
+	meta.elasticPoolName = config.TriggerMetadata["elasticPoolName"]
+
 	// Query
 	if val, ok := config.TriggerMetadata["query"]; ok {
+		if meta.elasticPoolName != "" {
+			return nil, fmt.Errorf("query and elasticPoolName can't be given both")
+		}
 		meta.query = val
-	} else {
+	} else if meta.elasticPoolName == "" {
 		return nil, fmt.Errorf("no query given")
 	}
 
+	if meta.elasticPoolName != "" {
+		meta.saturationMetric = mssqlSaturationMetricDTU
+		if val, ok := config.TriggerMetadata["saturationMetric"]; ok && val != "" {
+			if val != mssqlSaturationMetricDTU && val != mssqlSaturationMetricWorker {
+				return nil, fmt.Errorf("saturationMetric must be one of %s, %s", mssqlSaturationMetricDTU, mssqlSaturationMetricWorker)
+			}
+			meta.saturationMetric = val
+		}
+	}
+
 	// Target query value
 	if val, ok := config.TriggerMetadata["targetValue"]; ok {
 		targetValue, err := strconv.Atoi(val)
@@ -144,6 +189,8 @@ func parseMSSQLMetadata(config *ScalerConfig) (*mssqlMetadata, error) {
 		meta.metricName = kedautil.NormalizeString(fmt.Sprintf("mssql-%s", val))
 	} else {
 		switch {
+		case meta.elasticPoolName != "":
+			meta.metricName = kedautil.NormalizeString(fmt.Sprintf("mssql-elasticpool-%s-%s", meta.elasticPoolName, meta.saturationMetric))
 		case meta.database != "":
 			meta.metricName = kedautil.NormalizeString(fmt.Sprintf("mssql-%s", meta.database))
 		case meta.host != "":
@@ -230,6 +277,20 @@ func (s *mssqlScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricS
 
 // GetMetrics returns a value for a supported metric or an error if there is a problem getting the metric
 func (s *mssqlScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	if s.metadata.elasticPoolName != "" {
+		percent, err := s.getElasticPoolValue(ctx)
+		if err != nil {
+			return []external_metrics.ExternalMetricValue{}, fmt.Errorf("error inspecting mssql: %s", err)
+		}
+
+		metric := external_metrics.ExternalMetricValue{
+			MetricName: metricName,
+			Value:      *resource.NewMilliQuantity(int64(percent*1000), resource.DecimalSI),
+			Timestamp:  metav1.Now(),
+		}
+		return append([]external_metrics.ExternalMetricValue{}, metric), nil
+	}
+
 	num, err := s.getQueryResult(ctx)
 	if err != nil {
 		return []external_metrics.ExternalMetricValue{}, fmt.Errorf("error inspecting mssql: %s", err)
@@ -259,8 +320,44 @@ func (s *mssqlScaler) getQueryResult(ctx context.Context) (int, error) {
 	return value, nil
 }
 
+// getElasticPoolValue returns the saturation percent of the elastic pool
+func (s *mssqlScaler) getElasticPoolValue(ctx context.Context) (float64, error) {
+	var stats mssqlElasticPoolResourceStats
+	err := s.connection.QueryRowContext(ctx, mssqlElasticPoolResourceStatsQuery, s.metadata.elasticPoolName).
+		Scan(&stats.avgCPUPercent, &stats.avgDataIOPercent, &stats.avgLogWritePercent, &stats.maxWorkerPercent)
+	switch {
+	case err == sql.ErrNoRows:
+		return 0, fmt.Errorf("no resource stats found for elastic pool %s", s.metadata.elasticPoolName)
+	case err != nil:
+		mssqlLog.Error(err, fmt.Sprintf("Could not query elastic pool resource stats: %s", err))
+		return 0, err
+	}
+
+	return getElasticPoolValue(stats, s.metadata.saturationMetric), nil
+}
+
+// getElasticPoolValue returns the saturation percent of the stats, scalingDirection inverse with a linear
+// inverseScaling of 100 scales on the headroom of the pool instead
+func getElasticPoolValue(stats mssqlElasticPoolResourceStats, saturationMetric string) float64 {
+	switch saturationMetric {
+	case mssqlSaturationMetricWorker:
+		return stats.maxWorkerPercent
+	default:
+		// the DTU percent of a pool is its most utilized resource
+		return math.Max(stats.avgCPUPercent, math.Max(stats.avgDataIOPercent, stats.avgLogWritePercent))
+	}
+}
+
 // IsActive returns true if there are pending events to be processed
 func (s *mssqlScaler) IsActive(ctx context.Context) (bool, error) {
+	if s.metadata.elasticPoolName != "" {
+		value, err := s.getElasticPoolValue(ctx)
+		if err != nil {
+			return false, fmt.Errorf("error inspecting mssql: %s", err)
+		}
+		return value > 0, nil
+	}
+
 	messages, err := s.getQueryResult(ctx)
 	if err != nil {
 		return false, fmt.Errorf("error inspecting mssql: %s", err)
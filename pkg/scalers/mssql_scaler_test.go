@@ -1,3 +1,6 @@
+//go:build !selective_scalers || scalers_database
+// +build !selective_scalers scalers_database
+
 package scalers
 
 import (
@@ -101,6 +104,20 @@ var testInputs = []mssqlTestData{
 		authParams:    map[string]string{},
 		expectedError: errors.New("no host given"),
 	},
+	// Error: query and elasticPoolName
+	{
+		metadata:      map[string]string{"query": "SELECT 1", "elasticPoolName": "orders-pool", "targetValue": "80"},
+		resolvedEnv:   map[string]string{},
+		authParams:    map[string]string{"connectionString": "sqlserver://localhost"},
+		expectedError: errors.New("query and elasticPoolName can't be given both"),
+	},
+	// Error: wrong saturationMetric
+	{
+		metadata:      map[string]string{"elasticPoolName": "orders-pool", "saturationMetric": "cpu", "targetValue": "80"},
+		resolvedEnv:   map[string]string{},
+		authParams:    map[string]string{"connectionString": "sqlserver://localhost"},
+		expectedError: errors.New("saturationMetric must be one of dtu, worker"),
+	},
 }
 
 func TestMSSQLMetadataParsing(t *testing.T) {
@@ -142,3 +159,47 @@ func TestMSSQLMetadataParsing(t *testing.T) {
 		}
 	}
 }
+
+func TestMSSQLElasticPoolMetadataParsing(t *testing.T) {
+	var testData = []struct {
+		metadata           map[string]string
+		saturationMetric   string
+		expectedMetricName string
+	}{
+		{map[string]string{"elasticPoolName": "orders-pool", "targetValue": "80"}, "dtu", "mssql-elasticpool-orders-pool-dtu"},
+		{map[string]string{"elasticPoolName": "orders-pool", "saturationMetric": "worker", "targetValue": "20"}, "worker", "mssql-elasticpool-orders-pool-worker"},
+	}
+	for _, test := range testData {
+		meta, err := parseMSSQLMetadata(&ScalerConfig{TriggerMetadata: test.metadata, AuthParams: map[string]string{"connectionString": "sqlserver://localhost"}})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		if meta.query != "" {
+			t.Errorf("Expected no query but got '%s'", meta.query)
+		}
+		if meta.saturationMetric != test.saturationMetric {
+			t.Errorf("Wrong saturationMetric. Expected '%s' but got '%s'", test.saturationMetric, meta.saturationMetric)
+		}
+		if meta.metricName != test.expectedMetricName {
+			t.Errorf("Wrong metric name. Expected '%s' but got '%s'", test.expectedMetricName, meta.metricName)
+		}
+	}
+}
+
+func TestMSSQLGetElasticPoolValue(t *testing.T) {
+	stats := mssqlElasticPoolResourceStats{avgCPUPercent: 45.5, avgDataIOPercent: 72.25, avgLogWritePercent: 10, maxWorkerPercent: 30}
+	var testData = []struct {
+		stats            mssqlElasticPoolResourceStats
+		saturationMetric string
+		expected         float64
+	}{
+		{stats, "dtu", 72.25},
+		{stats, "worker", 30},
+	}
+	for _, test := range testData {
+		value := getElasticPoolValue(test.stats, test.saturationMetric)
+		if value != test.expected {
+			t.Errorf("Wrong value for %s. Expected %v but got %v", test.saturationMetric, test.expected, value)
+		}
+	}
+}
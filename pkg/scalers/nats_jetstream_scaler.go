@@ -0,0 +1,312 @@
+package scalers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nkeys"
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	defaultJetStreamLagThreshold = 10
+
+	// jetStreamPendingTypeAll counts the messages that are not delivered yet and the delivered messages that are not acknowledged
+	jetStreamPendingTypeAll        = "all"
+	jetStreamPendingTypePending    = "pending"
+	jetStreamPendingTypeAckPending = "ackPending"
+)
+
+// jetStreamConsumerInfoGetter is implemented by nats.JetStreamContext
+type jetStreamConsumerInfoGetter interface {
+	ConsumerInfo(stream, consumer string, opts ...nats.JSOpt) (*nats.ConsumerInfo, error)
+}
+
+type natsJetStreamScaler struct {
+	metadata *natsJetStreamMetadata
+
+	mu        sync.Mutex
+	conn      *nats.Conn
+	jetStream jetStreamConsumerInfoGetter
+}
+
+type natsJetStreamMetadata struct {
+	natsServer   string
+	stream       string
+	consumer     string
+	jsDomain     string
+	pendingType  string
+	lagThreshold int64
+
+	// auth
+	username    string
+	password    string
+	token       string
+	credentials string
+
+	// TLS
+	enableTLS  bool
+	tlsOptions kedautil.TLSOptions
+
+	scalerIndex int
+}
+
+var natsJetStreamLog = logf.Log.WithName("nats_jetstream_scaler")
+
+// NewNATSJetStreamScaler creates a new natsJetStreamScaler
+func NewNATSJetStreamScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseNATSJetStreamMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing nats jetstream metadata: %s", err)
+	}
+
+	return &natsJetStreamScaler{
+		metadata: meta,
+	}, nil
+}
+
+func parseNATSJetStreamMetadata(config *ScalerConfig) (*natsJetStreamMetadata, error) {
+	meta := natsJetStreamMetadata{
+		pendingType:  jetStreamPendingTypeAll,
+		lagThreshold: defaultJetStreamLagThreshold,
+	}
+
+	var err error
+	meta.natsServer, err = GetFromAuthOrMeta(config, "natsServer")
+	if err != nil {
+		return nil, err
+	}
+
+	if val, ok := config.TriggerMetadata["stream"]; ok && val != "" {
+		meta.stream = val
+	} else {
+		return nil, fmt.Errorf("no stream given")
+	}
+
+	if val, ok := config.TriggerMetadata["consumer"]; ok && val != "" {
+		meta.consumer = val
+	} else {
+		return nil, fmt.Errorf("no consumer given")
+	}
+
+	meta.jsDomain = config.TriggerMetadata["jsDomain"]
+
+	if val, ok := config.TriggerMetadata["pendingType"]; ok && val != "" {
+		switch val {
+		case jetStreamPendingTypeAll, jetStreamPendingTypePending, jetStreamPendingTypeAckPending:
+			meta.pendingType = val
+		default:
+			return nil, fmt.Errorf("pendingType %s must be one of %s, %s, %s", val, jetStreamPendingTypeAll, jetStreamPendingTypePending, jetStreamPendingTypeAckPending)
+		}
+	}
+
+	if val, ok := config.TriggerMetadata[lagThresholdMetricName]; ok && val != "" {
+		t, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", lagThresholdMetricName, err)
+		}
+		meta.lagThreshold = t
+	}
+
+	meta.username = config.AuthParams["username"]
+	meta.password = config.AuthParams["password"]
+	meta.token = config.AuthParams["token"]
+	meta.credentials = config.AuthParams["credentials"]
+	if meta.username != "" && meta.password == "" {
+		return nil, errors.New("password must be provided with username")
+	}
+	methods := 0
+	for _, given := range []bool{meta.username != "", meta.token != "", meta.credentials != ""} {
+		if given {
+			methods++
+		}
+	}
+	if methods > 1 {
+		return nil, errors.New("only one of username, token or credentials can be given")
+	}
+
+	if val, ok := config.AuthParams["tls"]; ok {
+		val = strings.TrimSpace(val)
+
+		if val == "enable" {
+			certGiven := config.AuthParams["cert"] != ""
+			keyGiven := config.AuthParams["key"] != ""
+			if certGiven && !keyGiven {
+				return nil, errors.New("key must be provided with cert")
+			}
+			if keyGiven && !certGiven {
+				return nil, errors.New("cert must be provided with key")
+			}
+			tlsOptions, err := getTLSOptions(config)
+			if err != nil {
+				return nil, err
+			}
+			meta.tlsOptions = tlsOptions
+			meta.enableTLS = true
+		} else {
+			return nil, fmt.Errorf("err incorrect value for TLS given: %s", val)
+		}
+	}
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+// connectOptions returns the options to connect with the configured authentication and TLS
+func (m *natsJetStreamMetadata) connectOptions() ([]nats.Option, error) {
+	options := []nats.Option{nats.Name("keda-nats-jetstream-scaler")}
+
+	switch {
+	case m.username != "":
+		options = append(options, nats.UserInfo(m.username, m.password))
+	case m.token != "":
+		options = append(options, nats.Token(m.token))
+	case m.credentials != "":
+		// credentials is the content of a .creds file, it is read from a secret so there is no file to pass to nats.UserCredentials
+		jwt, err := nkeys.ParseDecoratedJWT([]byte(m.credentials))
+		if err != nil {
+			return nil, fmt.Errorf("error parsing credentials: %s", err)
+		}
+		keyPair, err := nkeys.ParseDecoratedUserNKey([]byte(m.credentials))
+		if err != nil {
+			return nil, fmt.Errorf("error parsing credentials: %s", err)
+		}
+		options = append(options, nats.UserJWT(
+			func() (string, error) { return jwt, nil },
+			func(nonce []byte) ([]byte, error) { return keyPair.Sign(nonce) },
+		))
+	}
+
+	if m.enableTLS {
+		tlsConfig, err := kedautil.NewTLSConfigFromOptions(m.tlsOptions)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, nats.Secure(tlsConfig))
+	}
+
+	return options, nil
+}
+
+// getJetStream connects on first use, the connection is kept and reconnected by the client until the scaler is closed
+func (s *natsJetStreamScaler) getJetStream() (jetStreamConsumerInfoGetter, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.jetStream != nil {
+		return s.jetStream, nil
+	}
+
+	options, err := s.metadata.connectOptions()
+	if err != nil {
+		return nil, err
+	}
+	conn, err := nats.Connect(s.metadata.natsServer, options...)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to nats server: %s", err)
+	}
+
+	var jsOptions []nats.JSOpt
+	if s.metadata.jsDomain != "" {
+		jsOptions = append(jsOptions, nats.Domain(s.metadata.jsDomain))
+	}
+	jetStream, err := conn.JetStream(jsOptions...)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("error creating jetstream context: %s", err)
+	}
+
+	s.conn = conn
+	s.jetStream = jetStream
+	return jetStream, nil
+}
+
+// getPendingCount returns the number of messages of the consumer that are pending according to pendingType
+func (s *natsJetStreamScaler) getPendingCount(ctx context.Context) (int64, error) {
+	jetStream, err := s.getJetStream()
+	if err != nil {
+		return -1, err
+	}
+
+	info, err := jetStream.ConsumerInfo(s.metadata.stream, s.metadata.consumer, nats.Context(ctx))
+	if err != nil {
+		return -1, fmt.Errorf("error getting info of consumer %s of stream %s: %s", s.metadata.consumer, s.metadata.stream, err)
+	}
+
+	switch s.metadata.pendingType {
+	case jetStreamPendingTypePending:
+		return int64(info.NumPending), nil
+	case jetStreamPendingTypeAckPending:
+		return int64(info.NumAckPending), nil
+	default:
+		return int64(info.NumPending) + int64(info.NumAckPending), nil
+	}
+}
+
+func (s *natsJetStreamScaler) IsActive(ctx context.Context) (bool, error) {
+	pending, err := s.getPendingCount(ctx)
+	if err != nil {
+		natsJetStreamLog.Error(err, "error getting jetstream consumer pending count")
+		return false, err
+	}
+
+	return pending > 0, nil
+}
+
+func (s *natsJetStreamScaler) Close(context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.conn != nil {
+		s.conn.Close()
+		s.conn = nil
+	}
+	s.jetStream = nil
+	return nil
+}
+
+func (s *natsJetStreamScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetMetricValue := resource.NewQuantity(s.metadata.lagThreshold, resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(fmt.Sprintf("nats-jetstream-%s-%s", s.metadata.stream, s.metadata.consumer))),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetMetricValue,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+func (s *natsJetStreamScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	pending, err := s.getPendingCount(ctx)
+	if err != nil {
+		natsJetStreamLog.Error(err, "error getting jetstream consumer pending count")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewQuantity(pending, resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
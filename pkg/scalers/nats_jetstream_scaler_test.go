@@ -0,0 +1,149 @@
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nkeys"
+)
+
+type parseNATSJetStreamMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+}
+
+type natsJetStreamMetricIdentifier struct {
+	metadataTestData *parseNATSJetStreamMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testNATSJetStreamMetadata = []parseNATSJetStreamMetadataTestData{
+	// nothing passed
+	{map[string]string{}, map[string]string{}, true},
+	// all good
+	{map[string]string{"natsServer": "nats://nats:4222", "stream": "ORDERS", "consumer": "worker"}, map[string]string{}, false},
+	// natsServer in authParams
+	{map[string]string{"stream": "ORDERS", "consumer": "worker"}, map[string]string{"natsServer": "nats://nats:4222"}, false},
+	// missing natsServer
+	{map[string]string{"stream": "ORDERS", "consumer": "worker"}, map[string]string{}, true},
+	// missing stream
+	{map[string]string{"natsServer": "nats://nats:4222", "consumer": "worker"}, map[string]string{}, true},
+	// missing consumer
+	{map[string]string{"natsServer": "nats://nats:4222", "stream": "ORDERS"}, map[string]string{}, true},
+	// pendingType and lagThreshold
+	{map[string]string{"natsServer": "nats://nats:4222", "stream": "ORDERS", "consumer": "worker", "pendingType": "ackPending", "lagThreshold": "50"}, map[string]string{}, false},
+	// invalid pendingType
+	{map[string]string{"natsServer": "nats://nats:4222", "stream": "ORDERS", "consumer": "worker", "pendingType": "redelivered"}, map[string]string{}, true},
+	// invalid lagThreshold
+	{map[string]string{"natsServer": "nats://nats:4222", "stream": "ORDERS", "consumer": "worker", "lagThreshold": "AA"}, map[string]string{}, true},
+	// username and password
+	{map[string]string{"natsServer": "nats://nats:4222", "stream": "ORDERS", "consumer": "worker"}, map[string]string{"username": "user", "password": "pass"}, false},
+	// username without password
+	{map[string]string{"natsServer": "nats://nats:4222", "stream": "ORDERS", "consumer": "worker"}, map[string]string{"username": "user"}, true},
+	// token and credentials
+	{map[string]string{"natsServer": "nats://nats:4222", "stream": "ORDERS", "consumer": "worker"}, map[string]string{"token": "token", "credentials": "creds"}, true},
+	// tls with ca, cert and key
+	{map[string]string{"natsServer": "tls://nats:4222", "stream": "ORDERS", "consumer": "worker"}, map[string]string{"tls": "enable", "ca": "caaa", "cert": "ceert", "key": "keey"}, false},
+	// tls with cert without key
+	{map[string]string{"natsServer": "tls://nats:4222", "stream": "ORDERS", "consumer": "worker"}, map[string]string{"tls": "enable", "cert": "ceert"}, true},
+	// invalid tls value
+	{map[string]string{"natsServer": "tls://nats:4222", "stream": "ORDERS", "consumer": "worker"}, map[string]string{"tls": "yes"}, true},
+}
+
+var natsJetStreamMetricIdentifiers = []natsJetStreamMetricIdentifier{
+	{&testNATSJetStreamMetadata[1], 0, "s0-nats-jetstream-ORDERS-worker"},
+	{&testNATSJetStreamMetadata[6], 1, "s1-nats-jetstream-ORDERS-worker"},
+}
+
+func TestNATSJetStreamParseMetadata(t *testing.T) {
+	for idx, testData := range testNATSJetStreamMetadata {
+		_, err := parseNATSJetStreamMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Errorf("Test %d: expected success but got error: %s", idx, err)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Test %d: expected error but got success", idx)
+		}
+	}
+}
+
+func TestNATSJetStreamGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range natsJetStreamMetricIdentifiers {
+		meta, err := parseNATSJetStreamMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, AuthParams: testData.metadataTestData.authParams, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockNATSJetStreamScaler := natsJetStreamScaler{metadata: meta}
+
+		metricSpec := mockNATSJetStreamScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Errorf("Wrong External metric source name: %s, expected: %s", metricName, testData.name)
+		}
+	}
+}
+
+type fakeJetStreamConsumerInfoGetter struct {
+	info *nats.ConsumerInfo
+	err  error
+}
+
+func (f *fakeJetStreamConsumerInfoGetter) ConsumerInfo(stream, consumer string, opts ...nats.JSOpt) (*nats.ConsumerInfo, error) {
+	return f.info, f.err
+}
+
+func TestNATSJetStreamGetPendingCount(t *testing.T) {
+	info := &nats.ConsumerInfo{NumPending: 7, NumAckPending: 3}
+	tests := map[string]int64{
+		jetStreamPendingTypeAll:        10,
+		jetStreamPendingTypePending:    7,
+		jetStreamPendingTypeAckPending: 3,
+	}
+	for pendingType, expected := range tests {
+		s := natsJetStreamScaler{
+			metadata:  &natsJetStreamMetadata{stream: "ORDERS", consumer: "worker", pendingType: pendingType},
+			jetStream: &fakeJetStreamConsumerInfoGetter{info: info},
+		}
+		pending, err := s.getPendingCount(context.Background())
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %s", pendingType, err)
+		}
+		if pending != expected {
+			t.Errorf("%s: expected %d pending messages but got %d", pendingType, expected, pending)
+		}
+	}
+
+	s := natsJetStreamScaler{
+		metadata:  &natsJetStreamMetadata{stream: "ORDERS", consumer: "worker", pendingType: jetStreamPendingTypeAll},
+		jetStream: &fakeJetStreamConsumerInfoGetter{err: nats.ErrConsumerNotFound},
+	}
+	if _, err := s.getPendingCount(context.Background()); err == nil {
+		t.Error("expected error for a missing consumer but got success")
+	}
+}
+
+func TestNATSJetStreamCredentials(t *testing.T) {
+	user, err := nkeys.CreateUser()
+	if err != nil {
+		t.Fatal(err)
+	}
+	seed, err := user.Seed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	credentials := fmt.Sprintf("-----BEGIN NATS USER JWT-----\neyJ0eXAiOiJKV1QiLCJhbGciOiJlZDI1NTE5LW5rZXkifQ.e30.c2ln\n------END NATS USER JWT------\n\n-----BEGIN USER NKEY SEED-----\n%s\n------END USER NKEY SEED------\n", seed)
+
+	meta := natsJetStreamMetadata{credentials: credentials}
+	if _, err := meta.connectOptions(); err != nil {
+		t.Errorf("expected valid credentials but got error: %s", err)
+	}
+
+	meta = natsJetStreamMetadata{credentials: "not a creds file"}
+	if _, err := meta.connectOptions(); err == nil {
+		t.Error("expected error for invalid credentials but got success")
+	}
+}
@@ -0,0 +1,310 @@
+package scalers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	newrelicAccount             = "account"
+	newrelicRegion              = "region"
+	newrelicQueryKey            = "queryKey"
+	newrelicNRQL                = "nrql"
+	newrelicThreshold           = "threshold"
+	newrelicActivationThreshold = "activationThreshold"
+	newrelicNoDataError         = "noDataError"
+	newrelicMetricName          = "metricName"
+	defaultNewrelicMetricName   = "newrelic"
+)
+
+// newrelicGraphQLURLs are the NerdGraph endpoints of the New Relic regions
+var newrelicGraphQLURLs = map[string]string{
+	"US": "https://api.newrelic.com/graphql",
+	"EU": "https://api.eu.newrelic.com/graphql",
+}
+
+// newrelicResultTimeFields are returned by TIMESERIES and SINCE queries next to the value
+var newrelicResultTimeFields = map[string]bool{
+	"beginTimeSeconds": true,
+	"endTimeSeconds":   true,
+}
+
+type newrelicScaler struct {
+	metadata   *newrelicMetadata
+	httpClient *http.Client
+}
+
+type newrelicMetadata struct {
+	account             int
+	graphQLURL          string
+	queryKey            string
+	nrql                string
+	threshold           float64
+	activationThreshold float64
+	// noDataError returns an error instead of 0 when the query returns no results
+	noDataError bool
+	metricName  string
+	scalerIndex int
+}
+
+type newrelicGraphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+type newrelicGraphQLResponse struct {
+	Data struct {
+		Actor struct {
+			Account struct {
+				NRQL *struct {
+					Results []map[string]interface{} `json:"results"`
+				} `json:"nrql"`
+			} `json:"account"`
+		} `json:"actor"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+const newrelicNRQLGraphQLQuery = `query($account: Int!, $nrql: Nrql!) {
+  actor {
+    account(id: $account) {
+      nrql(query: $nrql) {
+        results
+      }
+    }
+  }
+}`
+
+var newrelicLog = logf.Log.WithName("newrelic_scaler")
+
+// NewNewRelicScaler creates a new newrelicScaler
+func NewNewRelicScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseNewRelicMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing newrelic metadata: %s", err)
+	}
+
+	return &newrelicScaler{
+		metadata:   meta,
+		httpClient: kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, false),
+	}, nil
+}
+
+func parseNewRelicMetadata(config *ScalerConfig) (*newrelicMetadata, error) {
+	meta := newrelicMetadata{}
+
+	account, err := GetFromAuthOrMeta(config, newrelicAccount)
+	if err != nil {
+		return nil, err
+	}
+	meta.account, err = strconv.Atoi(account)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing %s: %s", newrelicAccount, err)
+	}
+
+	region := "US"
+	if val, ok := config.AuthParams[newrelicRegion]; ok && val != "" {
+		region = val
+	} else if val, ok := config.TriggerMetadata[newrelicRegion]; ok && val != "" {
+		region = val
+	}
+	graphQLURL, ok := newrelicGraphQLURLs[strings.ToUpper(region)]
+	if !ok {
+		return nil, fmt.Errorf("%s has to be one of US, EU", newrelicRegion)
+	}
+	meta.graphQLURL = graphQLURL
+
+	meta.queryKey, err = GetFromAuthOrMeta(config, newrelicQueryKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if val, ok := config.TriggerMetadata[newrelicNRQL]; ok && val != "" {
+		meta.nrql = val
+	} else {
+		return nil, fmt.Errorf("no %s given", newrelicNRQL)
+	}
+
+	if val, ok := config.TriggerMetadata[newrelicThreshold]; ok && val != "" {
+		t, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", newrelicThreshold, err)
+		}
+		meta.threshold = t
+	} else {
+		return nil, fmt.Errorf("no %s given", newrelicThreshold)
+	}
+
+	if val, ok := config.TriggerMetadata[newrelicActivationThreshold]; ok && val != "" {
+		t, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", newrelicActivationThreshold, err)
+		}
+		meta.activationThreshold = t
+	}
+
+	if val, ok := config.TriggerMetadata[newrelicNoDataError]; ok && val != "" {
+		noDataError, err := strconv.ParseBool(val)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", newrelicNoDataError, err)
+		}
+		meta.noDataError = noDataError
+	}
+
+	metricName := defaultNewrelicMetricName
+	if val, ok := config.TriggerMetadata[newrelicMetricName]; ok && val != "" {
+		metricName = val
+	}
+	meta.metricName = kedautil.NormalizeString(fmt.Sprintf("newrelic-%s", metricName))
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+// IsActive returns true if the result of the NRQL query is above the activation threshold
+func (s *newrelicScaler) IsActive(ctx context.Context) (bool, error) {
+	val, err := s.executeNRQLQuery(ctx)
+	if err != nil {
+		newrelicLog.Error(err, "error executing NRQL query")
+		return false, err
+	}
+
+	return val > s.metadata.activationThreshold, nil
+}
+
+func (s *newrelicScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *newrelicScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetMetricValue := resource.NewMilliQuantity(int64(s.metadata.threshold*1000), resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, s.metadata.metricName),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetMetricValue,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// executeNRQLQuery runs the NRQL query through NerdGraph and returns the single numeric value of its result
+func (s *newrelicScaler) executeNRQLQuery(ctx context.Context) (float64, error) {
+	body, err := json.Marshal(newrelicGraphQLRequest{
+		Query: newrelicNRQLGraphQLQuery,
+		Variables: map[string]interface{}{
+			"account": s.metadata.account,
+			"nrql":    s.metadata.nrql,
+		},
+	})
+	if err != nil {
+		return -1, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.metadata.graphQLURL, bytes.NewReader(body))
+	if err != nil {
+		return -1, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("API-Key", s.metadata.queryKey)
+
+	r, err := s.httpClient.Do(req)
+	if err != nil {
+		return -1, err
+	}
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return -1, err
+	}
+	r.Body.Close()
+
+	if !(r.StatusCode >= 200 && r.StatusCode <= 299) {
+		return -1, fmt.Errorf("newrelic nerdgraph api returned error. status: %d response: %s", r.StatusCode, string(b))
+	}
+
+	var response newrelicGraphQLResponse
+	if err := json.Unmarshal(b, &response); err != nil {
+		return -1, err
+	}
+	if len(response.Errors) > 0 {
+		return -1, fmt.Errorf("newrelic nerdgraph api returned error: %s", response.Errors[0].Message)
+	}
+	if response.Data.Actor.Account.NRQL == nil {
+		return -1, fmt.Errorf("newrelic nerdgraph api returned no NRQL result for account %d", s.metadata.account)
+	}
+
+	return s.getNRQLValue(response.Data.Actor.Account.NRQL.Results)
+}
+
+// getNRQLValue returns the single numeric value of the results, the time fields of timeseries are ignored
+func (s *newrelicScaler) getNRQLValue(results []map[string]interface{}) (float64, error) {
+	if len(results) == 0 {
+		if s.metadata.noDataError {
+			return -1, fmt.Errorf("NRQL query %s returned no results", s.metadata.nrql)
+		}
+		return 0, nil
+	}
+
+	// TIMESERIES queries return a result per bucket, the last one is the latest
+	result := results[len(results)-1]
+	var values []float64
+	for field, value := range result {
+		if newrelicResultTimeFields[field] {
+			continue
+		}
+		switch v := value.(type) {
+		case float64:
+			values = append(values, v)
+		case nil:
+			if s.metadata.noDataError {
+				return -1, fmt.Errorf("NRQL query %s returned null for %s", s.metadata.nrql, field)
+			}
+			values = append(values, 0)
+		}
+	}
+
+	if len(values) != 1 {
+		return -1, fmt.Errorf("NRQL query %s has to return a single numeric value, got %d", s.metadata.nrql, len(values))
+	}
+	return values[0], nil
+}
+
+func (s *newrelicScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	val, err := s.executeNRQLQuery(ctx)
+	if err != nil {
+		newrelicLog.Error(err, "error executing NRQL query")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewMilliQuantity(int64(val*1000), resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
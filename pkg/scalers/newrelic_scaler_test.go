@@ -0,0 +1,146 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type parseNewRelicMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+}
+
+type newrelicMetricIdentifier struct {
+	metadataTestData *parseNewRelicMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testNewRelicMetadata = []parseNewRelicMetadataTestData{
+	// nothing passed
+	{map[string]string{}, map[string]string{}, true},
+	// properly formed
+	{map[string]string{"account": "1234567", "nrql": "SELECT average(duration) FROM Transaction", "threshold": "100"}, map[string]string{"queryKey": "NRAK-key"}, false},
+	// account, region and query key from auth params, metricName, activationThreshold and noDataError
+	{map[string]string{"metricName": "duration", "nrql": "SELECT average(duration) FROM Transaction", "threshold": "0.5", "activationThreshold": "0.1", "noDataError": "true"}, map[string]string{"account": "1234567", "region": "eu", "queryKey": "NRAK-key"}, false},
+	// missing account
+	{map[string]string{"nrql": "SELECT average(duration) FROM Transaction", "threshold": "100"}, map[string]string{"queryKey": "NRAK-key"}, true},
+	// malformed account
+	{map[string]string{"account": "a", "nrql": "SELECT average(duration) FROM Transaction", "threshold": "100"}, map[string]string{"queryKey": "NRAK-key"}, true},
+	// wrong region
+	{map[string]string{"account": "1234567", "region": "APAC", "nrql": "SELECT average(duration) FROM Transaction", "threshold": "100"}, map[string]string{"queryKey": "NRAK-key"}, true},
+	// missing queryKey
+	{map[string]string{"account": "1234567", "nrql": "SELECT average(duration) FROM Transaction", "threshold": "100"}, map[string]string{}, true},
+	// missing nrql
+	{map[string]string{"account": "1234567", "threshold": "100"}, map[string]string{"queryKey": "NRAK-key"}, true},
+	// missing threshold
+	{map[string]string{"account": "1234567", "nrql": "SELECT average(duration) FROM Transaction"}, map[string]string{"queryKey": "NRAK-key"}, true},
+	// malformed threshold
+	{map[string]string{"account": "1234567", "nrql": "SELECT average(duration) FROM Transaction", "threshold": "a"}, map[string]string{"queryKey": "NRAK-key"}, true},
+	// malformed activationThreshold
+	{map[string]string{"account": "1234567", "nrql": "SELECT average(duration) FROM Transaction", "threshold": "100", "activationThreshold": "a"}, map[string]string{"queryKey": "NRAK-key"}, true},
+	// malformed noDataError
+	{map[string]string{"account": "1234567", "nrql": "SELECT average(duration) FROM Transaction", "threshold": "100", "noDataError": "a"}, map[string]string{"queryKey": "NRAK-key"}, true},
+}
+
+var newrelicMetricIdentifiers = []newrelicMetricIdentifier{
+	{&testNewRelicMetadata[1], 0, "s0-newrelic-newrelic"},
+	{&testNewRelicMetadata[2], 1, "s1-newrelic-duration"},
+}
+
+func TestNewRelicParseMetadata(t *testing.T) {
+	for _, testData := range testNewRelicMetadata {
+		_, err := parseNewRelicMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Error("Expected success but got error", err)
+		}
+		if testData.isError && err == nil {
+			t.Error("Expected error but got success")
+		}
+	}
+}
+
+func TestNewRelicGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range newrelicMetricIdentifiers {
+		meta, err := parseNewRelicMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, AuthParams: testData.metadataTestData.authParams, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockNewRelicScaler := newrelicScaler{
+			metadata:   meta,
+			httpClient: http.DefaultClient,
+		}
+
+		metricSpec := mockNewRelicScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Error("Wrong External metric source name:", metricName)
+		}
+	}
+}
+
+func TestNewRelicExecuteNRQLQuery(t *testing.T) {
+	var testData = []struct {
+		name           string
+		noDataError    bool
+		bodyStr        string
+		responseStatus int
+		expectedValue  float64
+		isError        bool
+	}{
+		{"single value", false, `{"data":{"actor":{"account":{"nrql":{"results":[{"average.duration":0.25}]}}}}}`, http.StatusOK, 0.25, false},
+		{"latest timeseries bucket", false, `{"data":{"actor":{"account":{"nrql":{"results":[{"beginTimeSeconds":1,"endTimeSeconds":2,"count":3},{"beginTimeSeconds":2,"endTimeSeconds":3,"count":5}]}}}}}`, http.StatusOK, 5, false},
+		{"no results", false, `{"data":{"actor":{"account":{"nrql":{"results":[]}}}}}`, http.StatusOK, 0, false},
+		{"no results with noDataError", true, `{"data":{"actor":{"account":{"nrql":{"results":[]}}}}}`, http.StatusOK, -1, true},
+		{"null value", false, `{"data":{"actor":{"account":{"nrql":{"results":[{"average.duration":null}]}}}}}`, http.StatusOK, 0, false},
+		{"null value with noDataError", true, `{"data":{"actor":{"account":{"nrql":{"results":[{"average.duration":null}]}}}}}`, http.StatusOK, -1, true},
+		{"multiple values", false, `{"data":{"actor":{"account":{"nrql":{"results":[{"count":3,"sum":5}]}}}}}`, http.StatusOK, -1, true},
+		{"graphql error", false, `{"data":{"actor":{"account":{"nrql":null}}},"errors":[{"message":"NRQL Syntax Error"}]}`, http.StatusOK, -1, true},
+		{"error status response", false, `{}`, http.StatusUnauthorized, -1, true},
+	}
+
+	for _, test := range testData {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				assert.Equal(t, "NRAK-key", request.Header.Get("API-Key"))
+				var body newrelicGraphQLRequest
+				assert.NoError(t, json.NewDecoder(request.Body).Decode(&body))
+				assert.Equal(t, float64(1234567), body.Variables["account"])
+				assert.Equal(t, "SELECT average(duration) FROM Transaction", body.Variables["nrql"])
+				writer.WriteHeader(test.responseStatus)
+
+				if _, err := writer.Write([]byte(test.bodyStr)); err != nil {
+					t.Fatal(err)
+				}
+			}))
+			defer server.Close()
+
+			scaler := newrelicScaler{
+				metadata: &newrelicMetadata{
+					account:     1234567,
+					graphQLURL:  server.URL,
+					queryKey:    "NRAK-key",
+					nrql:        "SELECT average(duration) FROM Transaction",
+					noDataError: test.noDataError,
+				},
+				httpClient: http.DefaultClient,
+			}
+
+			value, err := scaler.executeNRQLQuery(context.TODO())
+
+			assert.Equal(t, test.expectedValue, value)
+			if test.isError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
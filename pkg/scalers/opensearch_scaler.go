@@ -0,0 +1,333 @@
+//go:build !selective_scalers || scalers_database
+// +build !selective_scalers scalers_database
+
+package scalers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/credentials/stscreds"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/tidwall/gjson"
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	openSearchHost                  = "host"
+	openSearchIndex                 = "index"
+	openSearchQuery                 = "query"
+	openSearchSearchTemplateName    = "searchTemplateName"
+	openSearchParameters            = "parameters"
+	openSearchValueLocation         = "valueLocation"
+	openSearchTargetValue           = "targetValue"
+	openSearchActivationTargetValue = "activationTargetValue"
+	openSearchUsername              = "username"
+	openSearchPassword              = "password"
+	openSearchAwsRegion             = "awsRegion"
+	openSearchAwsService            = "awsService"
+
+	// defaultOpenSearchAwsService is the signing name of Amazon OpenSearch Service domains, serverless
+	// collections are signed for aoss
+	defaultOpenSearchAwsService = "es"
+)
+
+type openSearchScaler struct {
+	metadata   *openSearchMetadata
+	httpClient *http.Client
+	// signer signs the requests to Amazon OpenSearch Service with SigV4, it is nil for other clusters
+	signer *v4.Signer
+}
+
+type openSearchMetadata struct {
+	host               string
+	indexes            []string
+	query              string
+	searchTemplateName string
+	parameters         map[string]string
+	// valueLocation is the gjson path of the value in the search response
+	valueLocation         string
+	targetValue           float64
+	activationTargetValue float64
+	// username and password are the credentials of an internal user of the security plugin
+	username string
+	password string
+	// awsRegion is the region of the Amazon OpenSearch Service domain, the requests are signed when it is given
+	// and are mapped to backend roles of the fine-grained access control by the IAM role or user
+	awsRegion        string
+	awsService       string
+	awsAuthorization awsAuthorizationMetadata
+	metricName       string
+	scalerIndex      int
+}
+
+var openSearchLog = logf.Log.WithName("opensearch_scaler")
+
+// NewOpenSearchScaler creates a new openSearchScaler
+func NewOpenSearchScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseOpenSearchMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing opensearch metadata: %s", err)
+	}
+
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	scaler := &openSearchScaler{
+		metadata:   meta,
+		httpClient: httpClient,
+	}
+	if meta.awsRegion != "" {
+		scaler.signer = v4.NewSigner(getOpenSearchAwsCredentials(meta))
+	}
+	return scaler, nil
+}
+
+func parseOpenSearchMetadata(config *ScalerConfig) (*openSearchMetadata, error) {
+	meta := openSearchMetadata{
+		awsService: defaultOpenSearchAwsService,
+	}
+
+	host, err := GetFromAuthOrMeta(config, openSearchHost)
+	if err != nil {
+		return nil, err
+	}
+	meta.host = strings.TrimSuffix(host, "/")
+
+	index, err := GetFromAuthOrMeta(config, openSearchIndex)
+	if err != nil {
+		return nil, err
+	}
+	meta.indexes = splitAndTrimBySep(index, ";")
+
+	query := config.TriggerMetadata[openSearchQuery]
+	meta.searchTemplateName = config.TriggerMetadata[openSearchSearchTemplateName]
+	switch {
+	case query != "" && meta.searchTemplateName != "":
+		return nil, fmt.Errorf("either %s or %s can be given", openSearchQuery, openSearchSearchTemplateName)
+	case query != "":
+		if !json.Valid([]byte(query)) {
+			return nil, fmt.Errorf("%s must be a valid json search request body", openSearchQuery)
+		}
+		meta.query = query
+	case meta.searchTemplateName == "":
+		return nil, fmt.Errorf("no %s or %s given", openSearchQuery, openSearchSearchTemplateName)
+	}
+
+	if val, ok := config.TriggerMetadata[openSearchParameters]; ok && val != "" {
+		meta.parameters = map[string]string{}
+		for _, p := range splitAndTrimBySep(val, ";") {
+			kv := splitAndTrimBySep(p, ":")
+			if len(kv) != 2 || kv[0] == "" {
+				return nil, fmt.Errorf("error parsing %s: %s isn't name:value", openSearchParameters, p)
+			}
+			meta.parameters[kv[0]] = kv[1]
+		}
+	}
+
+	if val, ok := config.TriggerMetadata[openSearchValueLocation]; ok && val != "" {
+		meta.valueLocation = val
+	} else {
+		return nil, fmt.Errorf("no %s given", openSearchValueLocation)
+	}
+
+	if val, ok := config.TriggerMetadata[openSearchTargetValue]; ok && val != "" {
+		targetValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", openSearchTargetValue, err)
+		}
+		meta.targetValue = targetValue
+	} else {
+		return nil, fmt.Errorf("no %s given", openSearchTargetValue)
+	}
+
+	if val, ok := config.TriggerMetadata[openSearchActivationTargetValue]; ok && val != "" {
+		activationTargetValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", openSearchActivationTargetValue, err)
+		}
+		meta.activationTargetValue = activationTargetValue
+	}
+
+	meta.username = config.AuthParams[openSearchUsername]
+	meta.password = config.AuthParams[openSearchPassword]
+	if (meta.username == "") != (meta.password == "") {
+		return nil, fmt.Errorf("%s and %s must be given together", openSearchUsername, openSearchPassword)
+	}
+
+	if val, ok := config.TriggerMetadata[openSearchAwsRegion]; ok && val != "" {
+		if meta.username != "" {
+			return nil, fmt.Errorf("either %s and %s or %s can be given", openSearchUsername, openSearchPassword, openSearchAwsRegion)
+		}
+		meta.awsRegion = val
+
+		if val, ok := config.TriggerMetadata[openSearchAwsService]; ok && val != "" {
+			meta.awsService = val
+		}
+
+		auth, err := getAwsAuthorization(config.AuthParams, config.TriggerMetadata, config.ResolvedEnv)
+		if err != nil {
+			return nil, err
+		}
+		if err := validateAwsPartition(config.TriggerMetadata, meta.awsRegion, auth); err != nil {
+			return nil, err
+		}
+		meta.awsAuthorization = auth
+	}
+
+	if meta.query != "" {
+		meta.metricName = kedautil.NormalizeString(fmt.Sprintf("opensearch-query-%s", strings.Join(meta.indexes, "-")))
+	} else {
+		meta.metricName = kedautil.NormalizeString(fmt.Sprintf("opensearch-%s", meta.searchTemplateName))
+	}
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+// getOpenSearchAwsCredentials returns the credentials the requests are signed with, the role in awsRoleArn
+// is assumed, identityOwner operator uses the credentials of the KEDA operator
+func getOpenSearchAwsCredentials(meta *openSearchMetadata) *credentials.Credentials {
+	sess := newAwsSession(meta.awsRegion)
+	if !meta.awsAuthorization.podIdentityOwner {
+		return sess.Config.Credentials
+	}
+	if meta.awsAuthorization.awsRoleArn != "" {
+		return stscreds.NewCredentials(sess, meta.awsAuthorization.awsRoleArn)
+	}
+	return credentials.NewStaticCredentials(meta.awsAuthorization.awsAccessKeyID, meta.awsAuthorization.awsSecretAccessKey, "")
+}
+
+// IsActive returns true if the value of the search is above the activation target
+func (s *openSearchScaler) IsActive(ctx context.Context) (bool, error) {
+	val, err := s.getSearchValue(ctx)
+	if err != nil {
+		openSearchLog.Error(err, "error searching opensearch")
+		return false, err
+	}
+
+	return val > s.metadata.activationTargetValue, nil
+}
+
+func (s *openSearchScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *openSearchScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetMetricValue := resource.NewMilliQuantity(int64(s.metadata.targetValue*1000), resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, s.metadata.metricName),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetMetricValue,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// getSearchRequest returns the url and the body of the search, either the query or the search template
+func (s *openSearchScaler) getSearchRequest() (string, []byte, error) {
+	url := fmt.Sprintf("%s/%s/_search", s.metadata.host, strings.Join(s.metadata.indexes, ","))
+	if s.metadata.query != "" {
+		return url, []byte(s.metadata.query), nil
+	}
+
+	template := map[string]interface{}{
+		"id": s.metadata.searchTemplateName,
+	}
+	if len(s.metadata.parameters) > 0 {
+		template["params"] = s.metadata.parameters
+	}
+	body, err := json.Marshal(template)
+	return url + "/template", body, err
+}
+
+// getSearchValue runs the search and returns the number at the valueLocation of the response
+func (s *openSearchScaler) getSearchValue(ctx context.Context) (float64, error) {
+	url, body, err := s.getSearchRequest()
+	if err != nil {
+		return -1, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		return -1, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	switch {
+	case s.signer != nil:
+		if _, err := s.signer.Sign(req, bytes.NewReader(body), s.metadata.awsService, s.metadata.awsRegion, time.Now()); err != nil {
+			return -1, fmt.Errorf("error signing opensearch request: %s", err)
+		}
+	case s.metadata.username != "":
+		req.SetBasicAuth(s.metadata.username, s.metadata.password)
+	}
+
+	r, err := s.httpClient.Do(req)
+	if err != nil {
+		return -1, err
+	}
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return -1, err
+	}
+	r.Body.Close()
+
+	if !(r.StatusCode >= 200 && r.StatusCode <= 299) {
+		return -1, fmt.Errorf("opensearch api returned error. status: %d response: %s", r.StatusCode, string(b))
+	}
+
+	value := gjson.GetBytes(b, s.metadata.valueLocation)
+	switch value.Type {
+	case gjson.Number:
+		return value.Num, nil
+	case gjson.String:
+		v, err := strconv.ParseFloat(value.Str, 64)
+		if err != nil {
+			return -1, fmt.Errorf("%s must point to a number but got: '%s'", openSearchValueLocation, value.Str)
+		}
+		return v, nil
+	default:
+		return -1, fmt.Errorf("%s must point to a number but got: '%s'", openSearchValueLocation, value.Type.String())
+	}
+}
+
+func (s *openSearchScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	val, err := s.getSearchValue(ctx)
+	if err != nil {
+		openSearchLog.Error(err, "error searching opensearch")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewMilliQuantity(int64(val*1000), resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
@@ -0,0 +1,196 @@
+//go:build !selective_scalers || scalers_database
+// +build !selective_scalers scalers_database
+
+package scalers
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	v4 "github.com/aws/aws-sdk-go/aws/signer/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+type parseOpenSearchMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+}
+
+type openSearchMetricIdentifier struct {
+	metadataTestData *parseOpenSearchMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testOpenSearchMetadata = []parseOpenSearchMetadataTestData{
+	// nothing passed
+	{map[string]string{}, map[string]string{}, true},
+	// properly formed query with basic auth
+	{map[string]string{"host": "https://opensearch:9200", "index": "orders", "query": `{"size":0,"query":{"term":{"status":"pending"}}}`, "valueLocation": "hits.total.value", "targetValue": "10"}, map[string]string{"username": "admin", "password": "admin"}, false},
+	// search template with parameters, activationTargetValue and SigV4 with a role
+	{map[string]string{"host": "https://search-orders.eu-west-1.es.amazonaws.com", "index": "orders;returns", "searchTemplateName": "pending", "parameters": "status:pending;days:2", "valueLocation": "aggregations.count.value", "targetValue": "2.5", "activationTargetValue": "1", "awsRegion": "eu-west-1"}, map[string]string{"awsRoleArn": "arn:aws:iam::123456789012:role/keda"}, false},
+	// serverless collection with the credentials of the operator
+	{map[string]string{"host": "https://abc.eu-west-1.aoss.amazonaws.com", "index": "orders", "searchTemplateName": "pending", "valueLocation": "hits.total.value", "targetValue": "10", "awsRegion": "eu-west-1", "awsService": "aoss", "identityOwner": "operator"}, map[string]string{}, false},
+	// missing host
+	{map[string]string{"index": "orders", "searchTemplateName": "pending", "valueLocation": "hits.total.value", "targetValue": "10"}, map[string]string{}, true},
+	// missing index
+	{map[string]string{"host": "https://opensearch:9200", "searchTemplateName": "pending", "valueLocation": "hits.total.value", "targetValue": "10"}, map[string]string{}, true},
+	// missing query and searchTemplateName
+	{map[string]string{"host": "https://opensearch:9200", "index": "orders", "valueLocation": "hits.total.value", "targetValue": "10"}, map[string]string{}, true},
+	// query and searchTemplateName
+	{map[string]string{"host": "https://opensearch:9200", "index": "orders", "query": `{"size":0}`, "searchTemplateName": "pending", "valueLocation": "hits.total.value", "targetValue": "10"}, map[string]string{}, true},
+	// malformed query
+	{map[string]string{"host": "https://opensearch:9200", "index": "orders", "query": `{"size":`, "valueLocation": "hits.total.value", "targetValue": "10"}, map[string]string{}, true},
+	// malformed parameters
+	{map[string]string{"host": "https://opensearch:9200", "index": "orders", "searchTemplateName": "pending", "parameters": "status", "valueLocation": "hits.total.value", "targetValue": "10"}, map[string]string{}, true},
+	// missing valueLocation
+	{map[string]string{"host": "https://opensearch:9200", "index": "orders", "searchTemplateName": "pending", "targetValue": "10"}, map[string]string{}, true},
+	// missing targetValue
+	{map[string]string{"host": "https://opensearch:9200", "index": "orders", "searchTemplateName": "pending", "valueLocation": "hits.total.value"}, map[string]string{}, true},
+	// malformed targetValue
+	{map[string]string{"host": "https://opensearch:9200", "index": "orders", "searchTemplateName": "pending", "valueLocation": "hits.total.value", "targetValue": "a"}, map[string]string{}, true},
+	// malformed activationTargetValue
+	{map[string]string{"host": "https://opensearch:9200", "index": "orders", "searchTemplateName": "pending", "valueLocation": "hits.total.value", "targetValue": "10", "activationTargetValue": "a"}, map[string]string{}, true},
+	// username without password
+	{map[string]string{"host": "https://opensearch:9200", "index": "orders", "searchTemplateName": "pending", "valueLocation": "hits.total.value", "targetValue": "10"}, map[string]string{"username": "admin"}, true},
+	// basic auth and SigV4
+	{map[string]string{"host": "https://opensearch:9200", "index": "orders", "searchTemplateName": "pending", "valueLocation": "hits.total.value", "targetValue": "10", "awsRegion": "eu-west-1", "identityOwner": "operator"}, map[string]string{"username": "admin", "password": "admin"}, true},
+	// SigV4 without aws credentials
+	{map[string]string{"host": "https://opensearch:9200", "index": "orders", "searchTemplateName": "pending", "valueLocation": "hits.total.value", "targetValue": "10", "awsRegion": "eu-west-1"}, map[string]string{}, true},
+	// role of another partition
+	{map[string]string{"host": "https://opensearch:9200", "index": "orders", "searchTemplateName": "pending", "valueLocation": "hits.total.value", "targetValue": "10", "awsRegion": "cn-north-1"}, map[string]string{"awsRoleArn": "arn:aws:iam::123456789012:role/keda"}, true},
+}
+
+var openSearchMetricIdentifiers = []openSearchMetricIdentifier{
+	{&testOpenSearchMetadata[1], 0, "s0-opensearch-query-orders"},
+	{&testOpenSearchMetadata[2], 1, "s1-opensearch-pending"},
+}
+
+func TestOpenSearchParseMetadata(t *testing.T) {
+	for _, testData := range testOpenSearchMetadata {
+		_, err := parseOpenSearchMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Error("Expected success but got error", err)
+		}
+		if testData.isError && err == nil {
+			t.Error("Expected error but got success")
+		}
+	}
+}
+
+func TestOpenSearchGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range openSearchMetricIdentifiers {
+		meta, err := parseOpenSearchMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, AuthParams: testData.metadataTestData.authParams, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockOpenSearchScaler := openSearchScaler{
+			metadata:   meta,
+			httpClient: http.DefaultClient,
+		}
+
+		metricSpec := mockOpenSearchScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Error("Wrong External metric source name:", metricName)
+		}
+	}
+}
+
+func TestOpenSearchGetSearchValue(t *testing.T) {
+	var testData = []struct {
+		name           string
+		bodyStr        string
+		responseStatus int
+		expectedValue  float64
+		isError        bool
+	}{
+		{"number", `{"hits":{"total":{"value":12,"relation":"eq"}},"aggregations":{"count":{"value":2.5}}}`, http.StatusOK, 2.5, false},
+		{"string", `{"aggregations":{"count":{"value":"7"}}}`, http.StatusOK, 7, false},
+		{"missing value", `{"hits":{"total":{"value":12,"relation":"eq"}}}`, http.StatusOK, -1, true},
+		{"error status response", `{"error":{"type":"security_exception","reason":"no permissions"},"status":403}`, http.StatusForbidden, -1, true},
+	}
+
+	for _, test := range testData {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				assert.Equal(t, "POST", request.Method)
+				assert.Equal(t, "/orders,returns/_search/template", request.URL.Path)
+				username, password, ok := request.BasicAuth()
+				assert.True(t, ok)
+				assert.Equal(t, "admin", username)
+				assert.Equal(t, "secret", password)
+				body, err := ioutil.ReadAll(request.Body)
+				assert.NoError(t, err)
+				assert.JSONEq(t, `{"id":"pending","params":{"status":"pending"}}`, string(body))
+				writer.WriteHeader(test.responseStatus)
+
+				if _, err := writer.Write([]byte(test.bodyStr)); err != nil {
+					t.Fatal(err)
+				}
+			}))
+			defer server.Close()
+
+			meta, err := parseOpenSearchMetadata(&ScalerConfig{
+				TriggerMetadata: map[string]string{"host": server.URL, "index": "orders;returns", "searchTemplateName": "pending", "parameters": "status:pending", "valueLocation": "aggregations.count.value", "targetValue": "10"},
+				AuthParams:      map[string]string{"username": "admin", "password": "secret"},
+			})
+			if err != nil {
+				t.Fatal("Could not parse metadata:", err)
+			}
+			scaler := openSearchScaler{metadata: meta, httpClient: http.DefaultClient}
+
+			value, err := scaler.getSearchValue(context.TODO())
+
+			assert.Equal(t, test.expectedValue, value)
+			if test.isError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestOpenSearchSignedSearch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		assert.Equal(t, "/orders/_search", request.URL.Path)
+		authorization := request.Header.Get("Authorization")
+		assert.True(t, strings.HasPrefix(authorization, "AWS4-HMAC-SHA256 Credential=AKID/"), authorization)
+		assert.Contains(t, authorization, "/eu-west-1/aoss/aws4_request")
+		assert.NotEmpty(t, request.Header.Get("X-Amz-Date"))
+		body, err := ioutil.ReadAll(request.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, `{"size":0}`, string(body))
+
+		if _, err := writer.Write([]byte(`{"hits":{"total":{"value":3,"relation":"eq"}}}`)); err != nil {
+			t.Fatal(err)
+		}
+	}))
+	defer server.Close()
+
+	meta, err := parseOpenSearchMetadata(&ScalerConfig{
+		TriggerMetadata: map[string]string{"host": server.URL, "index": "orders", "query": `{"size":0}`, "valueLocation": "hits.total.value", "targetValue": "10", "awsRegion": "eu-west-1", "awsService": "aoss"},
+		AuthParams:      map[string]string{"awsAccessKeyID": "AKID", "awsSecretAccessKey": "SECRET"},
+	})
+	if err != nil {
+		t.Fatal("Could not parse metadata:", err)
+	}
+	scaler := openSearchScaler{
+		metadata:   meta,
+		httpClient: http.DefaultClient,
+		signer:     v4.NewSigner(credentials.NewStaticCredentials("AKID", "SECRET", "")),
+	}
+
+	value, err := scaler.getSearchValue(context.TODO())
+
+	assert.NoError(t, err)
+	assert.Equal(t, float64(3), value)
+}
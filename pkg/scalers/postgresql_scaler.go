@@ -1,13 +1,18 @@
+//go:build !selective_scalers || scalers_database
+// +build !selective_scalers scalers_database
+
 package scalers
 
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
 	"strconv"
+	"time"
 
 	// PostreSQL drive required for this scaler
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 	"k8s.io/api/autoscaling/v2beta2"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -18,11 +23,31 @@ import (
 	kedautil "github.com/kedacore/keda/v2/pkg/util"
 )
 
+const (
+	postgreSQLListenerMinReconnectInterval = 2 * time.Second
+	postgreSQLListenerMaxReconnectInterval = time.Minute
+	postgreSQLListenerPingInterval         = 90 * time.Second
+
+	postgreSQLModeQuery = "query"
+	postgreSQLModePgq   = "pgq"
+
+	// pgqMetricPendingEvents is the number of events not yet processed by the consumer, pgqMetricLag
+	// the age in seconds of the last event it processed
+	pgqMetricPendingEvents = "pendingEvents"
+	pgqMetricLag           = "lag"
+)
+
 type postgreSQLScaler struct {
 	metadata   *postgreSQLMetadata
 	connection *sql.DB
 }
 
+// postgreSQLPushScaler holds a LISTEN connection on notifyChannel and activates the
+// scale target as soon as a NOTIFY is received, the query is still used for 1<->N scaling
+type postgreSQLPushScaler struct {
+	*postgreSQLScaler
+}
+
 type postgreSQLMetadata struct {
 	targetQueryValue int
 	connection       string
@@ -31,9 +56,11 @@ type postgreSQLMetadata struct {
 	host             string
 	port             string
 	query            string
+	queryArgs        []interface{}
 	dbName           string
 	sslmode          string
 	metricName       string
+	notifyChannel    string
 	scalerIndex      int
 }
 
@@ -50,19 +77,36 @@ func NewPostgreSQLScaler(config *ScalerConfig) (Scaler, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error establishing postgreSQL connection: %s", err)
 	}
-	return &postgreSQLScaler{
+	scaler := &postgreSQLScaler{
 		metadata:   meta,
 		connection: conn,
-	}, nil
+	}
+	if meta.notifyChannel != "" {
+		return &postgreSQLPushScaler{scaler}, nil
+	}
+	return scaler, nil
 }
 
 func parsePostgreSQLMetadata(config *ScalerConfig) (*postgreSQLMetadata, error) {
 	meta := postgreSQLMetadata{}
 
-	if val, ok := config.TriggerMetadata["query"]; ok {
-		meta.query = val
-	} else {
-		return nil, fmt.Errorf("no query given")
+	mode := postgreSQLModeQuery
+	if val, ok := config.TriggerMetadata["mode"]; ok && val != "" {
+		mode = val
+	}
+	switch mode {
+	case postgreSQLModeQuery:
+		if val, ok := config.TriggerMetadata["query"]; ok {
+			meta.query = val
+		} else {
+			return nil, fmt.Errorf("no query given")
+		}
+	case postgreSQLModePgq:
+		if err := parsePgqMetadata(config, &meta); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("mode must be %s or %s, got %s", postgreSQLModeQuery, postgreSQLModePgq, mode)
 	}
 
 	if val, ok := config.TriggerMetadata["targetQueryValue"]; ok {
@@ -115,8 +159,12 @@ func parsePostgreSQLMetadata(config *ScalerConfig) (*postgreSQLMetadata, error)
 		}
 	}
 
+	meta.notifyChannel = config.TriggerMetadata["notifyChannel"]
+
 	if val, ok := config.TriggerMetadata["metricName"]; ok {
 		meta.metricName = kedautil.NormalizeString(fmt.Sprintf("postgresql-%s", val))
+	} else if mode == postgreSQLModePgq {
+		meta.metricName = kedautil.NormalizeString(fmt.Sprintf("postgresql-pgq-%s", config.TriggerMetadata["queueName"]))
 	} else {
 		meta.metricName = kedautil.NormalizeString("postgresql")
 	}
@@ -124,22 +172,52 @@ func parsePostgreSQLMetadata(config *ScalerConfig) (*postgreSQLMetadata, error)
 	return &meta, nil
 }
 
-func getConnection(meta *postgreSQLMetadata) (*sql.DB, error) {
-	var connStr string
-	if meta.connection != "" {
-		connStr = meta.connection
+// parsePgqMetadata builds the query on pgq.get_consumer_info() of a pgq (Skytools) queue, for all consumers of the
+// queue the slowest one is taken
+func parsePgqMetadata(config *ScalerConfig, meta *postgreSQLMetadata) error {
+	queueName, ok := config.TriggerMetadata["queueName"]
+	if !ok || queueName == "" {
+		return fmt.Errorf("no queueName given")
+	}
+
+	column := "pending_events"
+	if val, ok := config.TriggerMetadata["pgqMetric"]; ok && val != "" {
+		switch val {
+		case pgqMetricPendingEvents:
+		case pgqMetricLag:
+			column = "CEIL(EXTRACT(EPOCH FROM lag))"
+		default:
+			return fmt.Errorf("pgqMetric must be %s or %s, got %s", pgqMetricPendingEvents, pgqMetricLag, val)
+		}
+	}
+
+	if consumerName := config.TriggerMetadata["consumerName"]; consumerName != "" {
+		meta.query = fmt.Sprintf("SELECT COALESCE(MAX(%s), 0)::bigint FROM pgq.get_consumer_info($1, $2)", column)
+		meta.queryArgs = []interface{}{queueName, consumerName}
 	} else {
-		connStr = fmt.Sprintf(
-			"host=%s port=%s user=%s dbname=%s sslmode=%s password=%s",
-			meta.host,
-			meta.port,
-			meta.userName,
-			meta.dbName,
-			meta.sslmode,
-			meta.password,
-		)
-	}
-	db, err := sql.Open("postgres", connStr)
+		meta.query = fmt.Sprintf("SELECT COALESCE(MAX(%s), 0)::bigint FROM pgq.get_consumer_info($1)", column)
+		meta.queryArgs = []interface{}{queueName}
+	}
+	return nil
+}
+
+func getConnectionString(meta *postgreSQLMetadata) string {
+	if meta.connection != "" {
+		return meta.connection
+	}
+	return fmt.Sprintf(
+		"host=%s port=%s user=%s dbname=%s sslmode=%s password=%s",
+		meta.host,
+		meta.port,
+		meta.userName,
+		meta.dbName,
+		meta.sslmode,
+		meta.password,
+	)
+}
+
+func getConnection(meta *postgreSQLMetadata) (*sql.DB, error) {
+	db, err := sql.Open("postgres", getConnectionString(meta))
 	if err != nil {
 		postgreSQLLog.Error(err, fmt.Sprintf("Found error opening postgreSQL: %s", err))
 		return nil, err
@@ -174,7 +252,7 @@ func (s *postgreSQLScaler) IsActive(ctx context.Context) (bool, error) {
 
 func (s *postgreSQLScaler) getActiveNumber(ctx context.Context) (int, error) {
 	var id int
-	err := s.connection.QueryRowContext(ctx, s.metadata.query).Scan(&id)
+	err := s.connection.QueryRowContext(ctx, s.metadata.query, s.metadata.queryArgs...).Scan(&id)
 	if err != nil {
 		postgreSQLLog.Error(err, fmt.Sprintf("could not query postgreSQL: %s", err))
 		return 0, fmt.Errorf("could not query postgreSQL: %s", err)
@@ -216,3 +294,78 @@ func (s *postgreSQLScaler) GetMetrics(ctx context.Context, metricName string, me
 
 	return append([]external_metrics.ExternalMetricValue{}, metric), nil
 }
+
+// Run is the only writer to the active channel and will close it on return.
+func (s *postgreSQLPushScaler) Run(ctx context.Context, active chan<- bool) {
+	defer close(active)
+
+	listener := pq.NewListener(getConnectionString(s.metadata), postgreSQLListenerMinReconnectInterval, postgreSQLListenerMaxReconnectInterval,
+		func(event pq.ListenerEventType, err error) {
+			if err != nil {
+				postgreSQLLog.Error(err, "postgreSQL listener connection error", "channel", s.metadata.notifyChannel)
+			}
+		})
+	// Listen blocks while the database can't be reached, closing the listener unblocks it and stops reconnecting
+	go func() {
+		<-ctx.Done()
+		listener.Close()
+	}()
+
+	if !s.listen(ctx, listener) {
+		return
+	}
+
+	ping := time.NewTicker(postgreSQLListenerPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case notification, ok := <-listener.Notify:
+			if !ok {
+				// the listener was closed
+				return
+			}
+			if notification == nil {
+				// the listener reconnected and notifications may have been lost in between,
+				// so fall back to the query to find out whether there is pending work
+				messages, err := s.getActiveNumber(ctx)
+				if err != nil || messages <= 0 {
+					continue
+				}
+			}
+			select {
+			case active <- true:
+			case <-ctx.Done():
+				return
+			}
+		case <-ping.C:
+			go func() {
+				if err := listener.Ping(); err != nil {
+					postgreSQLLog.V(1).Info("postgreSQL listener ping failed", "error", err.Error())
+				}
+			}()
+		}
+	}
+}
+
+// listen starts listening on the notify channel, failed attempts are retried until ctx is done and
+// the scaler is polled in the meantime. It returns false once ctx is done
+func (s *postgreSQLPushScaler) listen(ctx context.Context, listener *pq.Listener) bool {
+	for {
+		err := listener.Listen(s.metadata.notifyChannel)
+		if err == nil || errors.Is(err, pq.ErrChannelAlreadyOpen) {
+			return true
+		}
+		if ctx.Err() != nil {
+			return false
+		}
+		postgreSQLLog.Error(err, "error listening on postgreSQL channel, falling back to polling until the next attempt", "channel", s.metadata.notifyChannel)
+		select {
+		case <-ctx.Done():
+			return false
+		case <-time.After(postgreSQLListenerMaxReconnectInterval):
+		}
+	}
+}
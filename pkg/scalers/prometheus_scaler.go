@@ -33,13 +33,16 @@ const (
 type prometheusScaler struct {
 	metadata   *prometheusMetadata
 	httpClient *http.Client
+	health     *failoverHealth
 }
 
 type prometheusMetadata struct {
 	serverAddress string
-	metricName    string
-	query         string
-	threshold     int
+	// failoverAddresses are the Prometheus servers queried in order when serverAddress fails
+	failoverAddresses []string
+	metricName        string
+	query             string
+	threshold         int
 
 	// bearer auth
 	enableBearerAuth bool
@@ -80,20 +83,15 @@ func NewPrometheusScaler(config *ScalerConfig) (Scaler, error) {
 		return nil, fmt.Errorf("error parsing prometheus metadata: %s", err)
 	}
 
-	httpClient := kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, false)
-
-	if meta.ca != "" || meta.enableTLS {
-		config, err := kedautil.NewTLSConfig(meta.cert, meta.key, meta.ca)
-		if err != nil || config == nil {
-			return nil, fmt.Errorf("error creating the TLS config: %s", err)
-		}
-
-		httpClient.Transport = &http.Transport{TLSClientConfig: config}
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
 	}
 
 	return &prometheusScaler{
 		metadata:   meta,
 		httpClient: httpClient,
+		health:     newFailoverHealth(prometheusLog),
 	}, nil
 }
 
@@ -106,6 +104,12 @@ func parsePrometheusMetadata(config *ScalerConfig) (*prometheusMetadata, error)
 		return nil, fmt.Errorf("no %s given", promServerAddress)
 	}
 
+	addresses, err := parseFailoverAddresses(config)
+	if err != nil {
+		return nil, err
+	}
+	meta.failoverAddresses = addresses
+
 	if val, ok := config.TriggerMetadata[promQuery]; ok && val != "" {
 		meta.query = val
 	} else {
@@ -220,34 +224,18 @@ func (s *prometheusScaler) GetMetricSpecForScaling(context.Context) []v2beta2.Me
 
 func (s *prometheusScaler) ExecutePromQuery(ctx context.Context) (float64, error) {
 	t := time.Now().UTC().Format(time.RFC3339)
-	queryEscaped := url_pkg.QueryEscape(s.metadata.query)
-	url := fmt.Sprintf("%s/api/v1/query?query=%s&time=%s", s.metadata.serverAddress, queryEscaped, t)
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	addresses := append([]string{s.metadata.serverAddress}, s.metadata.failoverAddresses...)
+
+	var b []byte
+	err := s.health.do(ctx, addresses, func(i int) error {
+		var err error
+		b, err = s.queryPrometheus(ctx, addresses[i], t)
+		return err
+	})
 	if err != nil {
 		return -1, err
 	}
 
-	if s.metadata.enableBearerAuth {
-		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", s.metadata.bearerToken))
-	} else if s.metadata.enableBasicAuth {
-		req.SetBasicAuth(s.metadata.username, s.metadata.password)
-	}
-
-	r, err := s.httpClient.Do(req)
-	if err != nil {
-		return -1, err
-	}
-
-	b, err := ioutil.ReadAll(r.Body)
-	if err != nil {
-		return -1, err
-	}
-	r.Body.Close()
-
-	if !(r.StatusCode >= 200 && r.StatusCode <= 299) {
-		return -1, fmt.Errorf("prometheus query api returned error. status: %d response: %s", r.StatusCode, string(b))
-	}
-
 	var result promQueryResult
 	err = json.Unmarshal(b, &result)
 	if err != nil {
@@ -283,6 +271,43 @@ func (s *prometheusScaler) ExecutePromQuery(ctx context.Context) (float64, error
 	return v, nil
 }
 
+// queryPrometheus runs the query on the Prometheus server at serverAddress and returns the response body, a query
+// rejected by the server isn't retried on the failover addresses
+func (s *prometheusScaler) queryPrometheus(ctx context.Context, serverAddress, t string) ([]byte, error) {
+	queryEscaped := url_pkg.QueryEscape(s.metadata.query)
+	url := fmt.Sprintf("%s/api/v1/query?query=%s&time=%s", serverAddress, queryEscaped, t)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.metadata.enableBearerAuth {
+		req.Header.Add("Authorization", fmt.Sprintf("Bearer %s", s.metadata.bearerToken))
+	} else if s.metadata.enableBasicAuth {
+		req.SetBasicAuth(s.metadata.username, s.metadata.password)
+	}
+
+	r, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	r.Body.Close()
+
+	if !(r.StatusCode >= 200 && r.StatusCode <= 299) {
+		err := fmt.Errorf("prometheus query api returned error. status: %d response: %s", r.StatusCode, string(b))
+		if r.StatusCode < http.StatusInternalServerError {
+			return nil, noFailover(err)
+		}
+		return nil, err
+	}
+	return b, nil
+}
+
 func (s *prometheusScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
 	val, err := s.ExecutePromQuery(ctx)
 	if err != nil {
@@ -35,6 +35,10 @@ var testPromMetadata = []parsePrometheusMetadataTestData{
 	{map[string]string{"serverAddress": "http://localhost:9090", "metricName": "http_requests_total", "threshold": "100", "query": ""}, true},
 	// all properly formed, default disableScaleToZero
 	{map[string]string{"serverAddress": "http://localhost:9090", "metricName": "http_requests_total", "threshold": "100", "query": "up"}, false},
+	// failoverAddresses
+	{map[string]string{"serverAddress": "http://localhost:9090", "failoverAddresses": "http://eu.example.com:9090,http://us.example.com:9090", "metricName": "http_requests_total", "threshold": "100", "query": "up"}, false},
+	// malformed failoverAddresses
+	{map[string]string{"serverAddress": "http://localhost:9090", "failoverAddresses": "eu.example.com", "metricName": "http_requests_total", "threshold": "100", "query": "up"}, true},
 }
 
 var prometheusMetricIdentifiers = []prometheusMetricIdentifier{
@@ -206,3 +210,35 @@ func TestPrometheusScalerExecutePromQuery(t *testing.T) {
 		})
 	}
 }
+
+func TestPrometheusScalerFailover(t *testing.T) {
+	primaryRequests := 0
+	primary := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		primaryRequests++
+		writer.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer primary.Close()
+	secondary := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		_, _ = writer.Write([]byte(`{"data":{"result":[{"value": ["1", "3"]}]}}`))
+	}))
+	defer secondary.Close()
+
+	scaler := prometheusScaler{
+		metadata: &prometheusMetadata{
+			serverAddress:     primary.URL,
+			failoverAddresses: []string{secondary.URL},
+		},
+		httpClient: http.DefaultClient,
+		health:     newFailoverHealth(prometheusLog),
+	}
+
+	value, err := scaler.ExecutePromQuery(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, float64(3), value)
+
+	// the primary is in backoff, the secondary answers without it being requested
+	value, err = scaler.ExecutePromQuery(context.TODO())
+	assert.NoError(t, err)
+	assert.Equal(t, float64(3), value)
+	assert.Equal(t, 1, primaryRequests)
+}
@@ -0,0 +1,254 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	msgBacklogThresholdMetricName = "msgBacklogThreshold"
+	defaultMsgBacklogThreshold    = 10
+)
+
+type pulsarScaler struct {
+	metadata   *pulsarMetadata
+	httpClient *http.Client
+}
+
+type pulsarMetadata struct {
+	adminURL            string
+	topic               string
+	subscription        string
+	isPartitionedTopic  bool
+	msgBacklogThreshold int64
+
+	// auth
+	token string
+
+	// TLS
+	enableTLS bool
+	ca        string
+	cert      string
+	key       string
+
+	scalerIndex int
+}
+
+type pulsarTopicStats struct {
+	Subscriptions map[string]struct {
+		MsgBacklog int64 `json:"msgBacklog"`
+	} `json:"subscriptions"`
+}
+
+var pulsarLog = logf.Log.WithName("pulsar_scaler")
+
+// NewPulsarScaler creates a new pulsarScaler
+func NewPulsarScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parsePulsarMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing pulsar metadata: %s", err)
+	}
+
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &pulsarScaler{
+		metadata:   meta,
+		httpClient: httpClient,
+	}, nil
+}
+
+func parsePulsarMetadata(config *ScalerConfig) (*pulsarMetadata, error) {
+	meta := pulsarMetadata{
+		msgBacklogThreshold: defaultMsgBacklogThreshold,
+	}
+
+	if val, ok := config.TriggerMetadata["adminURL"]; ok && val != "" {
+		if _, err := url.ParseRequestURI(val); err != nil {
+			return nil, fmt.Errorf("error parsing adminURL: %s", err)
+		}
+		meta.adminURL = strings.TrimSuffix(val, "/")
+	} else {
+		return nil, fmt.Errorf("no adminURL given")
+	}
+
+	// topic is the full name of the topic, eg. persistent://public/default/orders
+	if val, ok := config.TriggerMetadata["topic"]; ok && val != "" {
+		if !strings.HasPrefix(val, "persistent://") && !strings.HasPrefix(val, "non-persistent://") {
+			return nil, fmt.Errorf("topic must be in the format persistent://tenant/namespace/topic")
+		}
+		if len(strings.Split(strings.SplitN(val, "://", 2)[1], "/")) != 3 {
+			return nil, fmt.Errorf("topic must be in the format persistent://tenant/namespace/topic")
+		}
+		meta.topic = val
+	} else {
+		return nil, fmt.Errorf("no topic given")
+	}
+
+	if val, ok := config.TriggerMetadata["subscription"]; ok && val != "" {
+		meta.subscription = val
+	} else {
+		return nil, fmt.Errorf("no subscription given")
+	}
+
+	if val, ok := config.TriggerMetadata["isPartitionedTopic"]; ok && val != "" {
+		isPartitionedTopic, err := strconv.ParseBool(val)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing isPartitionedTopic: %s", err)
+		}
+		meta.isPartitionedTopic = isPartitionedTopic
+	}
+
+	if val, ok := config.TriggerMetadata[msgBacklogThresholdMetricName]; ok && val != "" {
+		t, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", msgBacklogThresholdMetricName, err)
+		}
+		meta.msgBacklogThreshold = t
+	}
+
+	meta.token = config.AuthParams["token"]
+
+	if val, ok := config.AuthParams["tls"]; ok {
+		val = strings.TrimSpace(val)
+
+		if val == "enable" {
+			certGiven := config.AuthParams["cert"] != ""
+			keyGiven := config.AuthParams["key"] != ""
+			if certGiven && !keyGiven {
+				return nil, errors.New("key must be provided with cert")
+			}
+			if keyGiven && !certGiven {
+				return nil, errors.New("cert must be provided with key")
+			}
+			meta.ca = config.AuthParams["ca"]
+			meta.cert = config.AuthParams["cert"]
+			meta.key = config.AuthParams["key"]
+			meta.enableTLS = true
+		} else {
+			return nil, fmt.Errorf("err incorrect value for TLS given: %s", val)
+		}
+	}
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+// statsURL returns the admin api url of the topic stats, eg. <adminURL>/admin/v2/persistent/public/default/orders/stats
+func (s *pulsarScaler) statsURL() string {
+	parts := strings.SplitN(s.metadata.topic, "://", 2)
+	path := []string{parts[0]}
+	for _, part := range strings.Split(parts[1], "/") {
+		path = append(path, url.PathEscape(part))
+	}
+
+	stats := "stats"
+	if s.metadata.isPartitionedTopic {
+		stats = "partitioned-stats"
+	}
+	return fmt.Sprintf("%s/admin/v2/%s/%s", s.metadata.adminURL, strings.Join(path, "/"), stats)
+}
+
+// getMsgBacklog returns the backlog of the subscription, the partitioned stats aggregate the backlog of all partitions
+func (s *pulsarScaler) getMsgBacklog(ctx context.Context) (int64, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", s.statsURL(), nil)
+	if err != nil {
+		return -1, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if s.metadata.token != "" {
+		req.Header.Set("Authorization", "Bearer "+s.metadata.token)
+	}
+
+	r, err := s.httpClient.Do(req)
+	if err != nil {
+		return -1, err
+	}
+	defer r.Body.Close()
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return -1, err
+	}
+	if r.StatusCode != http.StatusOK {
+		return -1, fmt.Errorf("pulsar admin api returned %d: %s", r.StatusCode, string(b))
+	}
+
+	var stats pulsarTopicStats
+	if err := json.Unmarshal(b, &stats); err != nil {
+		return -1, fmt.Errorf("error parsing pulsar topic stats: %s", err)
+	}
+
+	subscription, ok := stats.Subscriptions[s.metadata.subscription]
+	if !ok {
+		return -1, fmt.Errorf("subscription %s not found on topic %s", s.metadata.subscription, s.metadata.topic)
+	}
+	return subscription.MsgBacklog, nil
+}
+
+func (s *pulsarScaler) IsActive(ctx context.Context) (bool, error) {
+	backlog, err := s.getMsgBacklog(ctx)
+	if err != nil {
+		pulsarLog.Error(err, "error getting pulsar subscription backlog")
+		return false, err
+	}
+
+	return backlog > 0, nil
+}
+
+func (s *pulsarScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *pulsarScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetMetricValue := resource.NewQuantity(s.metadata.msgBacklogThreshold, resource.DecimalSI)
+	topic := strings.SplitN(s.metadata.topic, "://", 2)[1]
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(fmt.Sprintf("pulsar-%s-%s", topic, s.metadata.subscription))),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetMetricValue,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+func (s *pulsarScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	backlog, err := s.getMsgBacklog(ctx)
+	if err != nil {
+		pulsarLog.Error(err, "error getting pulsar subscription backlog")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewQuantity(backlog, resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
@@ -0,0 +1,140 @@
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type parsePulsarMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+}
+
+type pulsarMetricIdentifier struct {
+	metadataTestData *parsePulsarMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testPulsarMetadata = []parsePulsarMetadataTestData{
+	// nothing passed
+	{map[string]string{}, map[string]string{}, true},
+	// all good
+	{map[string]string{"adminURL": "http://pulsar:8080", "topic": "persistent://public/default/orders", "subscription": "workers"}, map[string]string{}, false},
+	// partitioned topic with threshold
+	{map[string]string{"adminURL": "http://pulsar:8080", "topic": "persistent://public/default/orders", "subscription": "workers", "isPartitionedTopic": "true", "msgBacklogThreshold": "100"}, map[string]string{}, false},
+	// missing adminURL
+	{map[string]string{"topic": "persistent://public/default/orders", "subscription": "workers"}, map[string]string{}, true},
+	// invalid adminURL
+	{map[string]string{"adminURL": "pulsar", "topic": "persistent://public/default/orders", "subscription": "workers"}, map[string]string{}, true},
+	// missing topic
+	{map[string]string{"adminURL": "http://pulsar:8080", "subscription": "workers"}, map[string]string{}, true},
+	// topic without domain
+	{map[string]string{"adminURL": "http://pulsar:8080", "topic": "public/default/orders", "subscription": "workers"}, map[string]string{}, true},
+	// topic without namespace
+	{map[string]string{"adminURL": "http://pulsar:8080", "topic": "persistent://orders", "subscription": "workers"}, map[string]string{}, true},
+	// missing subscription
+	{map[string]string{"adminURL": "http://pulsar:8080", "topic": "persistent://public/default/orders"}, map[string]string{}, true},
+	// invalid isPartitionedTopic
+	{map[string]string{"adminURL": "http://pulsar:8080", "topic": "persistent://public/default/orders", "subscription": "workers", "isPartitionedTopic": "yes"}, map[string]string{}, true},
+	// invalid msgBacklogThreshold
+	{map[string]string{"adminURL": "http://pulsar:8080", "topic": "persistent://public/default/orders", "subscription": "workers", "msgBacklogThreshold": "AA"}, map[string]string{}, true},
+	// token and tls
+	{map[string]string{"adminURL": "https://pulsar:8443", "topic": "persistent://public/default/orders", "subscription": "workers"}, map[string]string{"token": "jwt", "tls": "enable", "ca": "caaa", "cert": "ceert", "key": "keey"}, false},
+	// tls with key without cert
+	{map[string]string{"adminURL": "https://pulsar:8443", "topic": "persistent://public/default/orders", "subscription": "workers"}, map[string]string{"tls": "enable", "key": "keey"}, true},
+	// invalid tls value
+	{map[string]string{"adminURL": "https://pulsar:8443", "topic": "persistent://public/default/orders", "subscription": "workers"}, map[string]string{"tls": "true"}, true},
+}
+
+var pulsarMetricIdentifiers = []pulsarMetricIdentifier{
+	{&testPulsarMetadata[1], 0, "s0-pulsar-public-default-orders-workers"},
+	{&testPulsarMetadata[2], 1, "s1-pulsar-public-default-orders-workers"},
+}
+
+func TestPulsarParseMetadata(t *testing.T) {
+	for idx, testData := range testPulsarMetadata {
+		_, err := parsePulsarMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Errorf("Test %d: expected success but got error: %s", idx, err)
+		}
+		if testData.isError && err == nil {
+			t.Errorf("Test %d: expected error but got success", idx)
+		}
+	}
+}
+
+func TestPulsarGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range pulsarMetricIdentifiers {
+		meta, err := parsePulsarMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, AuthParams: testData.metadataTestData.authParams, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockPulsarScaler := pulsarScaler{metadata: meta}
+
+		metricSpec := mockPulsarScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Errorf("Wrong External metric source name: %s, expected: %s", metricName, testData.name)
+		}
+	}
+}
+
+func TestPulsarGetMsgBacklog(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer jwt" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/admin/v2/persistent/public/default/orders/stats":
+			fmt.Fprint(w, `{"msgRateIn":1.5,"subscriptions":{"workers":{"msgBacklog":42},"audit":{"msgBacklog":3}}}`)
+		case "/admin/v2/persistent/public/default/events/partitioned-stats":
+			fmt.Fprint(w, `{"subscriptions":{"workers":{"msgBacklog":7}},"partitions":{}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		topic         string
+		subscription  string
+		partitioned   string
+		expected      int64
+		expectedError bool
+	}{
+		{"persistent://public/default/orders", "workers", "false", 42, false},
+		{"persistent://public/default/events", "workers", "true", 7, false},
+		{"persistent://public/default/orders", "missing", "false", -1, true},
+		{"persistent://public/default/unknown", "workers", "false", -1, true},
+	}
+	for _, test := range tests {
+		meta, err := parsePulsarMetadata(&ScalerConfig{
+			TriggerMetadata: map[string]string{"adminURL": server.URL, "topic": test.topic, "subscription": test.subscription, "isPartitionedTopic": test.partitioned},
+			AuthParams:      map[string]string{"token": "jwt"},
+		})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		scaler := pulsarScaler{metadata: meta, httpClient: http.DefaultClient}
+
+		backlog, err := scaler.getMsgBacklog(context.Background())
+		if test.expectedError {
+			if err == nil {
+				t.Errorf("%s %s: expected error but got success", test.topic, test.subscription)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s %s: expected success but got error: %s", test.topic, test.subscription, err)
+		}
+		if backlog != test.expected {
+			t.Errorf("%s %s: expected backlog %d, got %d", test.topic, test.subscription, test.expected, backlog)
+		}
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"math"
 	"net/http"
 	"net/url"
 	"regexp"
@@ -34,6 +35,7 @@ const (
 	rabbitValueTriggerConfigName = "value"
 	rabbitModeQueueLength        = "QueueLength"
 	rabbitModeMessageRate        = "MessageRate"
+	rabbitModeAlarms             = "Alarms"
 	defaultRabbitMQQueueLength   = 20
 	rabbitMetricType             = "External"
 )
@@ -61,8 +63,8 @@ type rabbitMQScaler struct {
 
 type rabbitMQMetadata struct {
 	queueName   string
-	mode        string        // QueueLength or MessageRate
-	value       int           // trigger value (queue length or publish/sec. rate)
+	mode        string        // QueueLength, MessageRate or Alarms
+	value       int           // trigger value (queue length, publish/sec. rate or percentage of the closest limit)
 	host        string        // connection string for either HTTP or AMQP protocol
 	protocol    string        // either http or amqp protocol
 	vhostName   *string       // override the vhost from the connection info
@@ -75,10 +77,33 @@ type rabbitMQMetadata struct {
 }
 
 type queueInfo struct {
-	Messages               int         `json:"messages"`
-	MessagesUnacknowledged int         `json:"messages_unacknowledged"`
-	MessageStat            messageStat `json:"message_stats"`
-	Name                   string      `json:"name"`
+	Messages                  int                    `json:"messages"`
+	MessagesUnacknowledged    int                    `json:"messages_unacknowledged"`
+	MessageBytes              int64                  `json:"message_bytes"`
+	MessageStat               messageStat            `json:"message_stats"`
+	Name                      string                 `json:"name"`
+	Arguments                 map[string]interface{} `json:"arguments"`
+	EffectivePolicyDefinition map[string]interface{} `json:"effective_policy_definition"`
+}
+
+type nodeInfo struct {
+	Name          string `json:"name"`
+	Running       bool   `json:"running"`
+	MemUsed       int64  `json:"mem_used"`
+	MemLimit      int64  `json:"mem_limit"`
+	MemAlarm      bool   `json:"mem_alarm"`
+	DiskFree      int64  `json:"disk_free"`
+	DiskFreeLimit int64  `json:"disk_free_limit"`
+	DiskFreeAlarm bool   `json:"disk_free_alarm"`
+}
+
+// brokerUsage is how close the broker is to blocking the publishers of the queue
+type brokerUsage struct {
+	messages int
+	// percentage of the closest memory or disk alarm threshold of a node or length limit of the queue
+	percentage float64
+	// a node raised a memory or disk alarm or the queue reached its length limit
+	alarm bool
 }
 
 type regexQueueInfo struct {
@@ -102,7 +127,10 @@ func NewRabbitMQScaler(config *ScalerConfig) (Scaler, error) {
 	if err != nil {
 		return nil, fmt.Errorf("error parsing rabbitmq metadata: %s", err)
 	}
-	httpClient := kedautil.CreateHTTPClient(meta.timeout, false)
+	httpClient, err := createHTTPClient(config, meta.timeout)
+	if err != nil {
+		return nil, err
+	}
 
 	if meta.protocol == httpProtocol {
 		return &rabbitMQScaler{
@@ -301,8 +329,10 @@ func parseTrigger(meta *rabbitMQMetadata, config *ScalerConfig) (*rabbitMQMetada
 		meta.mode = rabbitModeQueueLength
 	case rabbitModeMessageRate:
 		meta.mode = rabbitModeMessageRate
+	case rabbitModeAlarms:
+		meta.mode = rabbitModeAlarms
 	default:
-		return nil, fmt.Errorf("trigger mode %s must be one of %s, %s", mode, rabbitModeQueueLength, rabbitModeMessageRate)
+		return nil, fmt.Errorf("trigger mode %s must be one of %s, %s, %s", mode, rabbitModeQueueLength, rabbitModeMessageRate, rabbitModeAlarms)
 	}
 	triggerValue, err := strconv.Atoi(value)
 	if err != nil {
@@ -310,8 +340,12 @@ func parseTrigger(meta *rabbitMQMetadata, config *ScalerConfig) (*rabbitMQMetada
 	}
 	meta.value = triggerValue
 
-	if meta.mode == rabbitModeMessageRate && meta.protocol != httpProtocol {
-		return nil, fmt.Errorf("protocol %s not supported; must be http to use mode %s", meta.protocol, rabbitModeMessageRate)
+	if (meta.mode == rabbitModeMessageRate || meta.mode == rabbitModeAlarms) && meta.protocol != httpProtocol {
+		return nil, fmt.Errorf("protocol %s not supported; must be http to use mode %s", meta.protocol, meta.mode)
+	}
+
+	if meta.mode == rabbitModeAlarms && meta.useRegex {
+		return nil, fmt.Errorf("useRegex is not supported with mode %s", rabbitModeAlarms)
 	}
 
 	return meta, nil
@@ -345,7 +379,15 @@ func (s *rabbitMQScaler) Close(context.Context) error {
 
 // IsActive returns true if there are pending messages to be processed
 func (s *rabbitMQScaler) IsActive(ctx context.Context) (bool, error) {
-	messages, publishRate, err := s.getQueueStatus()
+	if s.metadata.mode == rabbitModeAlarms {
+		usage, err := s.getBrokerUsage(ctx)
+		if err != nil {
+			return false, s.anonimizeRabbitMQError(err)
+		}
+		return usage.messages > 0 || usage.alarm, nil
+	}
+
+	messages, publishRate, err := s.getQueueStatus(ctx)
 	if err != nil {
 		return false, s.anonimizeRabbitMQError(err)
 	}
@@ -356,9 +398,9 @@ func (s *rabbitMQScaler) IsActive(ctx context.Context) (bool, error) {
 	return publishRate > 0 || messages > 0, nil
 }
 
-func (s *rabbitMQScaler) getQueueStatus() (int, float64, error) {
+func (s *rabbitMQScaler) getQueueStatus(ctx context.Context) (int, float64, error) {
 	if s.metadata.protocol == httpProtocol {
-		info, err := s.getQueueInfoViaHTTP()
+		info, err := s.getQueueInfoViaHTTP(ctx)
 		if err != nil {
 			return -1, -1, err
 		}
@@ -375,9 +417,13 @@ func (s *rabbitMQScaler) getQueueStatus() (int, float64, error) {
 	return items.Messages, 0, nil
 }
 
-func getJSON(s *rabbitMQScaler, url string) (queueInfo, error) {
+func getJSON(ctx context.Context, s *rabbitMQScaler, url string) (queueInfo, error) {
 	var result queueInfo
-	r, err := s.httpClient.Get(url)
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return result, err
+	}
+	r, err := s.httpClient.Do(req)
 	if err != nil {
 		return result, err
 	}
@@ -405,7 +451,105 @@ func getJSON(s *rabbitMQScaler, url string) (queueInfo, error) {
 	return result, fmt.Errorf("error requesting rabbitMQ API status: %s, response: %s, from: %s", r.Status, body, url)
 }
 
-func (s *rabbitMQScaler) getQueueInfoViaHTTP() (*queueInfo, error) {
+// getBrokerUsage reads the length limits of the queue and the memory and disk alarm thresholds of the
+// running nodes and returns the one the broker is closest to
+func (s *rabbitMQScaler) getBrokerUsage(ctx context.Context) (*brokerUsage, error) {
+	info, err := s.getQueueInfoViaHTTP(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := &brokerUsage{messages: info.Messages}
+	if maxLength := info.lengthLimit("max-length"); maxLength > 0 {
+		usage.percentage = math.Max(usage.percentage, float64(info.Messages)/maxLength*100)
+		usage.alarm = usage.alarm || float64(info.Messages) >= maxLength
+	}
+	if maxLengthBytes := info.lengthLimit("max-length-bytes"); maxLengthBytes > 0 {
+		usage.percentage = math.Max(usage.percentage, float64(info.MessageBytes)/maxLengthBytes*100)
+		usage.alarm = usage.alarm || float64(info.MessageBytes) >= maxLengthBytes
+	}
+
+	nodes, err := s.getNodesInfoViaHTTP(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for _, node := range nodes {
+		// stopped nodes don't report their memory and disk usage
+		if !node.Running {
+			continue
+		}
+		if node.MemLimit > 0 {
+			usage.percentage = math.Max(usage.percentage, float64(node.MemUsed)/float64(node.MemLimit)*100)
+		}
+		// the disk alarm is raised when the free space drops below the limit
+		if node.DiskFree > 0 {
+			usage.percentage = math.Max(usage.percentage, float64(node.DiskFreeLimit)/float64(node.DiskFree)*100)
+		}
+		if node.MemAlarm || node.DiskFreeAlarm {
+			rabbitmqLog.V(1).Info("rabbitmq node raised an alarm", "node", node.Name, "memAlarm", node.MemAlarm, "diskFreeAlarm", node.DiskFreeAlarm)
+			usage.alarm = true
+		}
+	}
+
+	// the thresholds can be crossed between the alarm checks of the broker, report at least the threshold
+	if usage.alarm {
+		usage.percentage = math.Max(usage.percentage, 100)
+	}
+	return usage, nil
+}
+
+// lengthLimit returns the limit of the queue from its x-<name> argument or its policy, the lower one
+// applies when both are set, and 0 when the queue has no limit
+func (q *queueInfo) lengthLimit(name string) float64 {
+	var limit float64
+	for _, value := range []interface{}{q.Arguments["x-"+name], q.EffectivePolicyDefinition[name]} {
+		if v, ok := value.(float64); ok && v > 0 && (limit == 0 || v < limit) {
+			limit = v
+		}
+	}
+	return limit
+}
+
+func (s *rabbitMQScaler) getNodesInfoViaHTTP(ctx context.Context) ([]nodeInfo, error) {
+	managementURL, err := s.getManagementURL()
+	if err != nil {
+		return nil, err
+	}
+
+	getNodesManagementURI := fmt.Sprintf("%s/api/nodes", managementURL)
+	req, err := http.NewRequestWithContext(ctx, "GET", getNodesManagementURI, nil)
+	if err != nil {
+		return nil, err
+	}
+	r, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Body.Close()
+
+	if r.StatusCode != 200 {
+		body, _ := ioutil.ReadAll(r.Body)
+		return nil, fmt.Errorf("error requesting rabbitMQ API nodes: %s, response: %s, from: %s", r.Status, body, getNodesManagementURI)
+	}
+
+	var nodes []nodeInfo
+	if err := json.NewDecoder(r.Body).Decode(&nodes); err != nil {
+		return nil, err
+	}
+	return nodes, nil
+}
+
+// getManagementURL returns the host without the vhost path
+func (s *rabbitMQScaler) getManagementURL() (string, error) {
+	parsedURL, err := url.Parse(s.metadata.host)
+	if err != nil {
+		return "", err
+	}
+	parsedURL.Path = ""
+	return parsedURL.String(), nil
+}
+
+func (s *rabbitMQScaler) getQueueInfoViaHTTP(ctx context.Context) (*queueInfo, error) {
 	parsedURL, err := url.Parse(s.metadata.host)
 
 	if err != nil {
@@ -432,7 +576,7 @@ func (s *rabbitMQScaler) getQueueInfoViaHTTP() (*queueInfo, error) {
 	}
 
 	var info queueInfo
-	info, err = getJSON(s, getQueueInfoManagementURI)
+	info, err = getJSON(ctx, s, getQueueInfoManagementURI)
 
 	if err != nil {
 		return nil, err
@@ -462,7 +606,21 @@ func (s *rabbitMQScaler) GetMetricSpecForScaling(context.Context) []v2beta2.Metr
 
 // GetMetrics returns value for a supported metric and an error if there is a problem getting the metric
 func (s *rabbitMQScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
-	messages, publishRate, err := s.getQueueStatus()
+	if s.metadata.mode == rabbitModeAlarms {
+		usage, err := s.getBrokerUsage(ctx)
+		if err != nil {
+			return []external_metrics.ExternalMetricValue{}, s.anonimizeRabbitMQError(err)
+		}
+
+		metric := external_metrics.ExternalMetricValue{
+			MetricName: metricName,
+			Value:      *resource.NewMilliQuantity(int64(usage.percentage*1000), resource.DecimalSI),
+			Timestamp:  metav1.Now(),
+		}
+		return append([]external_metrics.ExternalMetricValue{}, metric), nil
+	}
+
+	messages, publishRate, err := s.getQueueStatus(ctx)
 	if err != nil {
 		return []external_metrics.ExternalMetricValue{}, s.anonimizeRabbitMQError(err)
 	}
@@ -111,6 +111,12 @@ var testRabbitMQMetadata = []parseRabbitMQMetadataTestData{
 	{map[string]string{"mode": "MessageRate", "value": "1000", "queueName": "sample", "host": "http://", "useRegex": "true", "pageSize": "-1"}, true, map[string]string{}},
 	// invalid pageSize
 	{map[string]string{"mode": "MessageRate", "value": "1000", "queueName": "sample", "host": "http://", "useRegex": "true", "pageSize": "a"}, true, map[string]string{}},
+	// Alarms over http
+	{map[string]string{"mode": "Alarms", "value": "80", "queueName": "sample", "host": "http://"}, false, map[string]string{}},
+	// Alarms over amqp
+	{map[string]string{"mode": "Alarms", "value": "80", "queueName": "sample", "host": "amqp://"}, true, map[string]string{}},
+	// Alarms with useRegex
+	{map[string]string{"mode": "Alarms", "value": "80", "queueName": "sample", "host": "http://", "useRegex": "true"}, true, map[string]string{}},
 }
 
 var rabbitMQMetricIdentifiers = []rabbitMQMetricIdentifier{
@@ -565,3 +571,76 @@ func TestRegexQueueMissingError(t *testing.T) {
 		}
 	}
 }
+
+type getBrokerUsageTestData struct {
+	queueResponse string
+	nodesResponse string
+	percentage    int64
+	isActive      bool
+}
+
+var testBrokerUsageTestData = []getBrokerUsageTestData{
+	// memory is the closest threshold
+	{`{"messages": 0, "message_bytes": 0, "name": "evaluate_trials"}`,
+		`[{"name": "rabbit@a", "running": true, "mem_used": 600, "mem_limit": 1000, "disk_free": 10000, "disk_free_limit": 1000}]`,
+		60000, false},
+	// disk is the closest threshold, stopped nodes are skipped
+	{`{"messages": 3, "message_bytes": 30, "name": "evaluate_trials"}`,
+		`[{"name": "rabbit@a", "running": true, "mem_used": 100, "mem_limit": 1000, "disk_free": 1250, "disk_free_limit": 1000}, {"name": "rabbit@b", "running": false}]`,
+		80000, true},
+	// queue length limit from the arguments and the lower policy limit
+	{`{"messages": 45, "message_bytes": 450, "name": "evaluate_trials", "arguments": {"x-max-length": 100}, "effective_policy_definition": {"max-length": 50}}`,
+		`[{"name": "rabbit@a", "running": true, "mem_used": 100, "mem_limit": 1000, "disk_free": 10000, "disk_free_limit": 1000}]`,
+		90000, true},
+	// queue length bytes limit reached
+	{`{"messages": 0, "message_bytes": 2048, "name": "evaluate_trials", "arguments": {"x-max-length-bytes": 1024}}`,
+		`[{"name": "rabbit@a", "running": true, "mem_used": 100, "mem_limit": 1000, "disk_free": 10000, "disk_free_limit": 1000}]`,
+		200000, true},
+	// memory alarm raised below the reported usage
+	{`{"messages": 0, "message_bytes": 0, "name": "evaluate_trials"}`,
+		`[{"name": "rabbit@a", "running": true, "mem_used": 900, "mem_limit": 1000, "mem_alarm": true, "disk_free": 10000, "disk_free_limit": 1000}]`,
+		100000, true},
+}
+
+func TestGetBrokerUsage(t *testing.T) {
+	for _, testData := range testBrokerUsageTestData {
+		testData := testData
+		var apiStub = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.RequestURI {
+			case "/api/queues/%2F/evaluate_trials":
+				_, _ = w.Write([]byte(testData.queueResponse))
+			case "/api/nodes":
+				_, _ = w.Write([]byte(testData.nodesResponse))
+			default:
+				t.Error("Unexpected request path", r.RequestURI)
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+
+		s, err := NewRabbitMQScaler(
+			&ScalerConfig{
+				ResolvedEnv:       map[string]string{host: apiStub.URL},
+				TriggerMetadata:   map[string]string{"queueName": "evaluate_trials", "hostFromEnv": host, "mode": "Alarms", "value": "80"},
+				AuthParams:        map[string]string{},
+				GlobalHTTPTimeout: 1000 * time.Millisecond,
+			},
+		)
+		if err != nil {
+			t.Fatal("Expect success", err)
+		}
+
+		active, err := s.IsActive(context.TODO())
+		if err != nil {
+			t.Error("Expect success", err)
+		}
+		assert.Equal(t, testData.isActive, active)
+
+		metrics, err := s.GetMetrics(context.TODO(), "MetricName", nil)
+		if err != nil {
+			t.Error("Expect success", err)
+		} else {
+			assert.Equal(t, testData.percentage, metrics[0].Value.MilliValue())
+		}
+		apiStub.Close()
+	}
+}
@@ -1,3 +1,6 @@
+//go:build !selective_scalers || scalers_redis
+// +build !selective_scalers scalers_redis
+
 package scalers
 
 import (
@@ -6,6 +9,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/go-redis/redis/v8"
 	v2beta2 "k8s.io/api/autoscaling/v2beta2"
@@ -22,6 +26,11 @@ const (
 	defaultTargetListLength = 5
 	defaultDBIdx            = 0
 	defaultEnableTLS        = false
+
+	// defaultScanCount is the COUNT hint of a SCAN call when counting the keys matching keyPattern
+	defaultScanCount = 1000
+	// defaultMaxScanIterations bounds the SCAN calls per poll, a larger keyspace is scanned over several polls
+	defaultMaxScanIterations = 10
 )
 
 type redisAddressParser func(metadata, resolvedEnv, authParams map[string]string) (redisConnectionInfo, error)
@@ -45,11 +54,14 @@ type redisConnectionInfo struct {
 }
 
 type redisMetadata struct {
-	targetListLength int
-	listName         string
-	databaseIndex    int
-	connectionInfo   redisConnectionInfo
-	scalerIndex      int
+	targetListLength  int
+	listName          string
+	keyPattern        string
+	scanCount         int64
+	maxScanIterations int
+	databaseIndex     int
+	connectionInfo    redisConnectionInfo
+	scalerIndex       int
 }
 
 var redisLog = logf.Log.WithName("redis_scaler")
@@ -112,6 +124,9 @@ func createClusteredRedisScaler(ctx context.Context, meta *redisMetadata, script
 
 		return cmd.Int64()
 	}
+	if meta.keyPattern != "" {
+		listLengthFn = newRedisClusterKeyCounter(meta, client).count
+	}
 
 	return &redisScaler{
 		metadata:        meta,
@@ -142,6 +157,9 @@ func createSentinelRedisScaler(ctx context.Context, meta *redisMetadata, script
 
 		return cmd.Int64()
 	}
+	if meta.keyPattern != "" {
+		listLengthFn = newRedisKeyCounter(meta, client).count
+	}
 
 	return &redisScaler{
 		metadata:        meta,
@@ -172,6 +190,9 @@ func createRedisScaler(ctx context.Context, meta *redisMetadata, script string)
 
 		return cmd.Int64()
 	}
+	if meta.keyPattern != "" {
+		listLengthFn = newRedisKeyCounter(meta, client).count
+	}
 
 	return &redisScaler{
 		metadata:        meta,
@@ -198,7 +219,25 @@ func parseRedisMetadata(config *ScalerConfig, parserFn redisAddressParser) (*red
 		meta.targetListLength = listLength
 	}
 
-	if val, ok := config.TriggerMetadata["listName"]; ok {
+	if val, ok := config.TriggerMetadata["keyPattern"]; ok && val != "" {
+		meta.keyPattern = val
+		meta.scanCount = defaultScanCount
+		meta.maxScanIterations = defaultMaxScanIterations
+		if val, ok := config.TriggerMetadata["scanCount"]; ok {
+			scanCount, err := strconv.ParseInt(val, 10, 64)
+			if err != nil || scanCount <= 0 {
+				return nil, fmt.Errorf("scanCount must be a positive number")
+			}
+			meta.scanCount = scanCount
+		}
+		if val, ok := config.TriggerMetadata["maxScanIterations"]; ok {
+			maxScanIterations, err := strconv.Atoi(val)
+			if err != nil || maxScanIterations <= 0 {
+				return nil, fmt.Errorf("maxScanIterations must be a positive number")
+			}
+			meta.maxScanIterations = maxScanIterations
+		}
+	} else if val, ok := config.TriggerMetadata["listName"]; ok {
 		meta.listName = val
 	} else {
 		return nil, fmt.Errorf("no list name given")
@@ -236,6 +275,9 @@ func (s *redisScaler) Close(context.Context) error {
 func (s *redisScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
 	targetListLengthQty := resource.NewQuantity(int64(s.metadata.targetListLength), resource.DecimalSI)
 	metricName := kedautil.NormalizeString(fmt.Sprintf("redis-%s", s.metadata.listName))
+	if s.metadata.keyPattern != "" {
+		metricName = kedautil.NormalizeString(fmt.Sprintf("redis-keys-%s", strings.ReplaceAll(s.metadata.keyPattern, "*", "x")))
+	}
 	externalMetric := &v2beta2.ExternalMetricSource{
 		Metric: v2beta2.MetricIdentifier{
 			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, metricName),
@@ -534,6 +576,117 @@ func getRedisClient(ctx context.Context, info redisConnectionInfo, dbIndex int)
 	return c, nil
 }
 
+// redisKeyScanner is implemented by the redis clients of the single node and sentinel modes and by the masters of a cluster
+type redisKeyScanner interface {
+	Scan(ctx context.Context, cursor uint64, match string, count int64) *redis.ScanCmd
+	DBSize(ctx context.Context) *redis.IntCmd
+}
+
+// redisKeyCounter counts the keys matching keyPattern with a bounded number of SCAN calls per poll,
+// the cursor is kept between the polls so that a large keyspace is counted over several polls
+type redisKeyCounter struct {
+	scanner           redisKeyScanner
+	keyPattern        string
+	scanCount         int64
+	maxScanIterations int
+
+	lock     sync.Mutex
+	cursor   uint64
+	partial  int64
+	last     int64
+	complete bool
+}
+
+func newRedisKeyCounter(meta *redisMetadata, scanner redisKeyScanner) *redisKeyCounter {
+	return &redisKeyCounter{
+		scanner:           scanner,
+		keyPattern:        meta.keyPattern,
+		scanCount:         meta.scanCount,
+		maxScanIterations: meta.maxScanIterations,
+	}
+}
+
+// count returns the number of keys of the last complete scan, or the number of keys found so far
+// if that is larger, eg. before the first scan completed or when the keyspace is growing
+func (c *redisKeyCounter) count(ctx context.Context) (int64, error) {
+	// all the keys match, DBSIZE is O(1)
+	if c.keyPattern == "*" {
+		return c.scanner.DBSize(ctx).Result()
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	for i := 0; i < c.maxScanIterations; i++ {
+		keys, cursor, err := c.scanner.Scan(ctx, c.cursor, c.keyPattern, c.scanCount).Result()
+		if err != nil {
+			return -1, err
+		}
+		c.partial += int64(len(keys))
+		c.cursor = cursor
+		if cursor == 0 {
+			c.last = c.partial
+			c.partial = 0
+			c.complete = true
+			return c.last, nil
+		}
+	}
+
+	if !c.complete || c.partial > c.last {
+		return c.partial, nil
+	}
+	return c.last, nil
+}
+
+// redisClusterKeyCounter scans every master of the cluster with its own redisKeyCounter
+type redisClusterKeyCounter struct {
+	client *redis.ClusterClient
+	meta   *redisMetadata
+
+	lock     sync.Mutex
+	counters map[string]*redisKeyCounter
+}
+
+func newRedisClusterKeyCounter(meta *redisMetadata, client *redis.ClusterClient) *redisClusterKeyCounter {
+	return &redisClusterKeyCounter{
+		client:   client,
+		meta:     meta,
+		counters: map[string]*redisKeyCounter{},
+	}
+}
+
+func (c *redisClusterKeyCounter) count(ctx context.Context) (int64, error) {
+	var total int64
+	var totalLock sync.Mutex
+	err := c.client.ForEachMaster(ctx, func(ctx context.Context, master *redis.Client) error {
+		count, err := c.counterFor(master).count(ctx)
+		if err != nil {
+			return err
+		}
+		totalLock.Lock()
+		total += count
+		totalLock.Unlock()
+		return nil
+	})
+	if err != nil {
+		return -1, err
+	}
+	return total, nil
+}
+
+func (c *redisClusterKeyCounter) counterFor(master *redis.Client) *redisKeyCounter {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	addr := master.Options().Addr
+	counter, ok := c.counters[addr]
+	if !ok {
+		counter = newRedisKeyCounter(c.meta, master)
+		c.counters[addr] = counter
+	}
+	return counter
+}
+
 // Splits a string separated by comma and trims space from all the elements.
 func splitAndTrim(s string) []string {
 	x := strings.Split(s, ",")
@@ -1,3 +1,6 @@
+//go:build !selective_scalers || scalers_redis
+// +build !selective_scalers scalers_redis
+
 package scalers
 
 import (
@@ -5,6 +8,7 @@ import (
 	"errors"
 	"testing"
 
+	"github.com/go-redis/redis/v8"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -54,11 +58,18 @@ var testRedisMetadata = []parseRedisMetadataTestData{
 	// host and port is defined in the authParams
 	{map[string]string{"listName": "mylist", "listLength": "0"}, false, map[string]string{"host": "localhost", "port": "6379"}},
 	// host only is defined in the authParams
-	{map[string]string{"listName": "mylist", "listLength": "0"}, true, map[string]string{"host": "localhost"}}}
+	{map[string]string{"listName": "mylist", "listLength": "0"}, true, map[string]string{"host": "localhost"}},
+	// properly formed keyPattern
+	{map[string]string{"keyPattern": "lock:*", "listLength": "10", "scanCount": "100", "maxScanIterations": "5"}, false, map[string]string{"address": "localhost:6379"}},
+	// improperly formed scanCount
+	{map[string]string{"keyPattern": "lock:*", "listLength": "10", "scanCount": "0"}, true, map[string]string{"address": "localhost:6379"}},
+	// improperly formed maxScanIterations
+	{map[string]string{"keyPattern": "lock:*", "listLength": "10", "maxScanIterations": "AA"}, true, map[string]string{"address": "localhost:6379"}}}
 
 var redisMetricIdentifiers = []redisMetricIdentifier{
 	{&testRedisMetadata[1], 0, "s0-redis-mylist"},
 	{&testRedisMetadata[1], 1, "s1-redis-mylist"},
+	{&testRedisMetadata[12], 0, "s0-redis-keys-lock-x"},
 }
 
 func TestRedisParseMetadata(t *testing.T) {
@@ -97,6 +108,56 @@ func TestRedisGetMetricSpecForScaling(t *testing.T) {
 	}
 }
 
+type fakeRedisKeyScanner struct {
+	pages  [][]string
+	dbSize int64
+	scans  int
+}
+
+// Scan returns the pages in order, the cursor of a page is its index
+func (f *fakeRedisKeyScanner) Scan(_ context.Context, cursor uint64, _ string, _ int64) *redis.ScanCmd {
+	f.scans++
+	next := (cursor + 1) % uint64(len(f.pages))
+	return redis.NewScanCmdResult(f.pages[cursor], next, nil)
+}
+
+func (f *fakeRedisKeyScanner) DBSize(context.Context) *redis.IntCmd {
+	return redis.NewIntResult(f.dbSize, nil)
+}
+
+func TestRedisKeyCounter(t *testing.T) {
+	scanner := &fakeRedisKeyScanner{
+		pages:  [][]string{{"lock:1", "lock:2"}, {}, {"lock:3"}, {"lock:4", "lock:5"}},
+		dbSize: 42,
+	}
+	counter := newRedisKeyCounter(&redisMetadata{keyPattern: "lock:*", maxScanIterations: 3}, scanner)
+
+	// the first scan isn't complete yet, the keys found so far are returned
+	count, err := counter.count(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), count)
+	assert.Equal(t, 3, scanner.scans)
+
+	// the scan continues with the cursor of the previous poll and completes
+	count, err = counter.count(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), count)
+	assert.Equal(t, 4, scanner.scans)
+
+	// the count of the last complete scan is kept until the next scan completes
+	count, err = counter.count(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), count)
+	assert.Equal(t, 7, scanner.scans)
+
+	// DBSIZE is used when all the keys match
+	counter = newRedisKeyCounter(&redisMetadata{keyPattern: "*", maxScanIterations: 3}, scanner)
+	count, err = counter.count(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(42), count)
+	assert.Equal(t, 7, scanner.scans)
+}
+
 func TestParseRedisClusterMetadata(t *testing.T) {
 	cases := []struct {
 		name        string
@@ -1,3 +1,6 @@
+//go:build !selective_scalers || scalers_redis
+// +build !selective_scalers scalers_redis
+
 package scalers
 
 import (
@@ -0,0 +1,411 @@
+package scalers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	rocketMQNameServer             = "nameServer"
+	rocketMQTopic                  = "topic"
+	rocketMQConsumerGroup          = "consumerGroup"
+	rocketMQLagThreshold           = "lagThreshold"
+	rocketMQActivationLagThreshold = "activationLagThreshold"
+	rocketMQAccessKey              = "accessKey"
+	rocketMQSecretKey              = "secretKey"
+
+	defaultRocketMQLagThreshold = 10
+
+	// request codes of the remoting protocol
+	rocketMQQueryConsumerOffset = 14
+	rocketMQGetMaxOffset        = 30
+	rocketMQGetRouteInfo        = 105
+
+	// response codes of the remoting protocol
+	rocketMQSuccess       = 0
+	rocketMQQueryNotFound = 22
+
+	// rocketMQMasterID is the broker id of the master in the broker addresses of a route
+	rocketMQMasterID = "0"
+	// rocketMQVersion is the client version sent in the requests, V4_3_0
+	rocketMQVersion = 317
+)
+
+type rocketMQScaler struct {
+	metadata *rocketMQMetadata
+	timeout  time.Duration
+}
+
+type rocketMQMetadata struct {
+	// nameServers are the addresses of the name servers, eg. rocketmq-namesrv:9876
+	nameServers            []string
+	topic                  string
+	consumerGroup          string
+	lagThreshold           int64
+	activationLagThreshold int64
+	// accessKey and secretKey sign the requests when the ACL of the brokers is enabled
+	accessKey   string
+	secretKey   string
+	scalerIndex int
+}
+
+// rocketMQCommand is a request or response of the remoting protocol with a json header
+type rocketMQCommand struct {
+	Code      int               `json:"code"`
+	Language  string            `json:"language"`
+	Version   int               `json:"version"`
+	Opaque    int32             `json:"opaque"`
+	Flag      int               `json:"flag"`
+	Remark    string            `json:"remark,omitempty"`
+	ExtFields map[string]string `json:"extFields,omitempty"`
+	Body      []byte            `json:"-"`
+}
+
+type rocketMQTopicRoute struct {
+	QueueDatas []struct {
+		BrokerName    string `json:"brokerName"`
+		ReadQueueNums int    `json:"readQueueNums"`
+	} `json:"queueDatas"`
+	BrokerDatas []struct {
+		BrokerName  string            `json:"brokerName"`
+		BrokerAddrs map[string]string `json:"brokerAddrs"`
+	} `json:"brokerDatas"`
+}
+
+var (
+	rocketMQLog    = logf.Log.WithName("rocketmq_scaler")
+	rocketMQOpaque int32
+	// rocketMQNumericKey matches the unquoted numeric map keys of the fastjson encoded bodies
+	rocketMQNumericKey = regexp.MustCompile(`([{,])(\d+):`)
+)
+
+// NewRocketMQScaler creates a new rocketMQScaler
+func NewRocketMQScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseRocketMQMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing rocketmq metadata: %s", err)
+	}
+
+	return &rocketMQScaler{
+		metadata: meta,
+		timeout:  config.GlobalHTTPTimeout,
+	}, nil
+}
+
+func parseRocketMQMetadata(config *ScalerConfig) (*rocketMQMetadata, error) {
+	meta := rocketMQMetadata{
+		lagThreshold: defaultRocketMQLagThreshold,
+	}
+
+	nameServer, err := GetFromAuthOrMeta(config, rocketMQNameServer)
+	if err != nil {
+		return nil, err
+	}
+	meta.nameServers = splitAndTrimBySep(nameServer, ";")
+
+	if val, ok := config.TriggerMetadata[rocketMQTopic]; ok && val != "" {
+		meta.topic = val
+	} else {
+		return nil, fmt.Errorf("no %s given", rocketMQTopic)
+	}
+
+	if val, ok := config.TriggerMetadata[rocketMQConsumerGroup]; ok && val != "" {
+		meta.consumerGroup = val
+	} else {
+		return nil, fmt.Errorf("no %s given", rocketMQConsumerGroup)
+	}
+
+	if val, ok := config.TriggerMetadata[rocketMQLagThreshold]; ok && val != "" {
+		lagThreshold, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", rocketMQLagThreshold, err)
+		}
+		if lagThreshold <= 0 {
+			return nil, fmt.Errorf("%s must be positive, got %d", rocketMQLagThreshold, lagThreshold)
+		}
+		meta.lagThreshold = lagThreshold
+	}
+
+	if val, ok := config.TriggerMetadata[rocketMQActivationLagThreshold]; ok && val != "" {
+		activationLagThreshold, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", rocketMQActivationLagThreshold, err)
+		}
+		meta.activationLagThreshold = activationLagThreshold
+	}
+
+	meta.accessKey = config.AuthParams[rocketMQAccessKey]
+	meta.secretKey = config.AuthParams[rocketMQSecretKey]
+	if (meta.accessKey == "") != (meta.secretKey == "") {
+		return nil, fmt.Errorf("%s and %s must be given together", rocketMQAccessKey, rocketMQSecretKey)
+	}
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+// IsActive returns true if the consumer group lag is above the activation threshold
+func (s *rocketMQScaler) IsActive(ctx context.Context) (bool, error) {
+	lag, err := s.getConsumerLag(ctx)
+	if err != nil {
+		rocketMQLog.Error(err, "error getting rocketmq consumer lag", "topic", s.metadata.topic, "consumerGroup", s.metadata.consumerGroup)
+		return false, err
+	}
+
+	return lag > s.metadata.activationLagThreshold, nil
+}
+
+func (s *rocketMQScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *rocketMQScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetMetricValue := resource.NewQuantity(s.metadata.lagThreshold, resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(fmt.Sprintf("rocketmq-%s-%s", s.metadata.topic, s.metadata.consumerGroup))),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetMetricValue,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// getConsumerLag returns the sum of the lag of the consumer group on the read queues of the topic on every
+// master broker, queues the group hasn't consumed from yet lag by all their messages
+func (s *rocketMQScaler) getConsumerLag(ctx context.Context) (int64, error) {
+	route, err := s.getTopicRoute(ctx)
+	if err != nil {
+		return -1, err
+	}
+
+	masters := map[string]string{}
+	for _, broker := range route.BrokerDatas {
+		if addr, ok := broker.BrokerAddrs[rocketMQMasterID]; ok {
+			masters[broker.BrokerName] = addr
+		}
+	}
+
+	var lag int64
+	for _, queues := range route.QueueDatas {
+		addr, ok := masters[queues.BrokerName]
+		if !ok {
+			return -1, fmt.Errorf("rocketmq broker %s has no master", queues.BrokerName)
+		}
+		brokerLag, err := s.getBrokerLag(ctx, addr, queues.ReadQueueNums)
+		if err != nil {
+			return -1, fmt.Errorf("error getting lag on rocketmq broker %s: %s", queues.BrokerName, err)
+		}
+		lag += brokerLag
+	}
+	return lag, nil
+}
+
+// getTopicRoute returns the route of the topic from the first name server that answers
+func (s *rocketMQScaler) getTopicRoute(ctx context.Context) (*rocketMQTopicRoute, error) {
+	var err error
+	for _, nameServer := range s.metadata.nameServers {
+		var response *rocketMQCommand
+		response, err = s.invoke(ctx, nameServer, rocketMQGetRouteInfo, map[string]string{"topic": s.metadata.topic})
+		if err != nil {
+			continue
+		}
+		if response.Code != rocketMQSuccess {
+			return nil, fmt.Errorf("rocketmq name server returned error. code: %d remark: %s", response.Code, response.Remark)
+		}
+
+		var route rocketMQTopicRoute
+		if err := json.Unmarshal(rocketMQNumericKey.ReplaceAll(response.Body, []byte(`$1"$2":`)), &route); err != nil {
+			return nil, fmt.Errorf("error parsing rocketmq topic route: %s", err)
+		}
+		return &route, nil
+	}
+	return nil, fmt.Errorf("error getting rocketmq topic route: %s", err)
+}
+
+func (s *rocketMQScaler) getBrokerLag(ctx context.Context, addr string, queueNums int) (int64, error) {
+	var lag int64
+	for queueID := 0; queueID < queueNums; queueID++ {
+		maxOffset, err := s.getOffset(ctx, addr, rocketMQGetMaxOffset, map[string]string{
+			"topic":   s.metadata.topic,
+			"queueId": strconv.Itoa(queueID),
+		})
+		if err != nil {
+			return -1, err
+		}
+		consumerOffset, err := s.getOffset(ctx, addr, rocketMQQueryConsumerOffset, map[string]string{
+			"consumerGroup": s.metadata.consumerGroup,
+			"topic":         s.metadata.topic,
+			"queueId":       strconv.Itoa(queueID),
+		})
+		if err != nil {
+			return -1, err
+		}
+		if maxOffset > consumerOffset {
+			lag += maxOffset - consumerOffset
+		}
+	}
+	return lag, nil
+}
+
+// getOffset returns the offset in the response header, an offset not found is 0
+func (s *rocketMQScaler) getOffset(ctx context.Context, addr string, code int, extFields map[string]string) (int64, error) {
+	response, err := s.invoke(ctx, addr, code, extFields)
+	if err != nil {
+		return -1, err
+	}
+	switch response.Code {
+	case rocketMQSuccess:
+		return strconv.ParseInt(response.ExtFields["offset"], 10, 64)
+	case rocketMQQueryNotFound:
+		return 0, nil
+	default:
+		return -1, fmt.Errorf("rocketmq broker returned error. code: %d remark: %s", response.Code, response.Remark)
+	}
+}
+
+// invoke sends a request to the name server or broker at addr and returns its response
+func (s *rocketMQScaler) invoke(ctx context.Context, addr string, code int, extFields map[string]string) (*rocketMQCommand, error) {
+	if s.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, s.timeout)
+		defer cancel()
+	}
+
+	var dialer net.Dialer
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		if err := conn.SetDeadline(deadline); err != nil {
+			return nil, err
+		}
+	}
+
+	request := &rocketMQCommand{
+		Code:      code,
+		Language:  "GO",
+		Version:   rocketMQVersion,
+		Opaque:    atomic.AddInt32(&rocketMQOpaque, 1),
+		ExtFields: extFields,
+	}
+	if s.metadata.accessKey != "" {
+		signRocketMQCommand(request, s.metadata.accessKey, s.metadata.secretKey)
+	}
+	if err := writeRocketMQCommand(conn, request); err != nil {
+		return nil, err
+	}
+	return readRocketMQCommand(conn)
+}
+
+// signRocketMQCommand adds the AccessKey and the Signature of the ACL of RocketMQ, the HmacSHA1 of the values
+// of the fields sorted by name followed by the body
+func signRocketMQCommand(command *rocketMQCommand, accessKey, secretKey string) {
+	fields := map[string]string{"AccessKey": accessKey}
+	for k, v := range command.ExtFields {
+		fields[k] = v
+	}
+	names := make([]string, 0, len(fields))
+	for k := range fields {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	mac := hmac.New(sha1.New, []byte(secretKey))
+	for _, k := range names {
+		mac.Write([]byte(fields[k]))
+	}
+	mac.Write(command.Body)
+	fields["Signature"] = base64.StdEncoding.EncodeToString(mac.Sum(nil))
+	command.ExtFields = fields
+}
+
+// writeRocketMQCommand writes the frame of the command, its length, the length of the json header, the header
+// and the body
+func writeRocketMQCommand(w io.Writer, command *rocketMQCommand) error {
+	header, err := json.Marshal(command)
+	if err != nil {
+		return err
+	}
+	frame := make([]byte, 8, 8+len(header)+len(command.Body))
+	binary.BigEndian.PutUint32(frame[0:4], uint32(4+len(header)+len(command.Body)))
+	// the high byte of the header length is the serialization of the header, 0 is json
+	binary.BigEndian.PutUint32(frame[4:8], uint32(len(header)))
+	frame = append(frame, header...)
+	frame = append(frame, command.Body...)
+	_, err = w.Write(frame)
+	return err
+}
+
+func readRocketMQCommand(r io.Reader) (*rocketMQCommand, error) {
+	var length [4]byte
+	if _, err := io.ReadFull(r, length[:]); err != nil {
+		return nil, err
+	}
+	frame := make([]byte, binary.BigEndian.Uint32(length[:]))
+	if _, err := io.ReadFull(r, frame); err != nil {
+		return nil, err
+	}
+	if len(frame) < 4 {
+		return nil, fmt.Errorf("rocketmq frame too short")
+	}
+	if frame[0] != 0 {
+		return nil, fmt.Errorf("rocketmq header serialization %d isn't json", frame[0])
+	}
+	headerLength := int(binary.BigEndian.Uint32(frame[0:4]))
+	if 4+headerLength > len(frame) {
+		return nil, fmt.Errorf("rocketmq header length %d exceeds the frame", headerLength)
+	}
+
+	var command rocketMQCommand
+	if err := json.Unmarshal(frame[4:4+headerLength], &command); err != nil {
+		return nil, err
+	}
+	command.Body = frame[4+headerLength:]
+	return &command, nil
+}
+
+func (s *rocketMQScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	lag, err := s.getConsumerLag(ctx)
+	if err != nil {
+		rocketMQLog.Error(err, "error getting rocketmq consumer lag", "topic", s.metadata.topic, "consumerGroup", s.metadata.consumerGroup)
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewQuantity(lag, resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
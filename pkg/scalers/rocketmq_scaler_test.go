@@ -0,0 +1,187 @@
+package scalers
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type parseRocketMQMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+}
+
+type rocketMQMetricIdentifier struct {
+	metadataTestData *parseRocketMQMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testRocketMQMetadata = []parseRocketMQMetadataTestData{
+	// nothing passed
+	{map[string]string{}, map[string]string{}, true},
+	// properly formed
+	{map[string]string{"nameServer": "rocketmq-namesrv:9876", "topic": "orders", "consumerGroup": "billing"}, map[string]string{}, false},
+	// name servers from auth params, thresholds and acl
+	{map[string]string{"topic": "orders", "consumerGroup": "shipping", "lagThreshold": "100", "activationLagThreshold": "5"}, map[string]string{"nameServer": "namesrv-0:9876;namesrv-1:9876", "accessKey": "keda", "secretKey": "secret"}, false},
+	// missing nameServer
+	{map[string]string{"topic": "orders", "consumerGroup": "billing"}, map[string]string{}, true},
+	// missing topic
+	{map[string]string{"nameServer": "rocketmq-namesrv:9876", "consumerGroup": "billing"}, map[string]string{}, true},
+	// missing consumerGroup
+	{map[string]string{"nameServer": "rocketmq-namesrv:9876", "topic": "orders"}, map[string]string{}, true},
+	// malformed lagThreshold
+	{map[string]string{"nameServer": "rocketmq-namesrv:9876", "topic": "orders", "consumerGroup": "billing", "lagThreshold": "a"}, map[string]string{}, true},
+	// zero lagThreshold
+	{map[string]string{"nameServer": "rocketmq-namesrv:9876", "topic": "orders", "consumerGroup": "billing", "lagThreshold": "0"}, map[string]string{}, true},
+	// malformed activationLagThreshold
+	{map[string]string{"nameServer": "rocketmq-namesrv:9876", "topic": "orders", "consumerGroup": "billing", "activationLagThreshold": "a"}, map[string]string{}, true},
+	// accessKey without secretKey
+	{map[string]string{"nameServer": "rocketmq-namesrv:9876", "topic": "orders", "consumerGroup": "billing"}, map[string]string{"accessKey": "keda"}, true},
+}
+
+var rocketMQMetricIdentifiers = []rocketMQMetricIdentifier{
+	{&testRocketMQMetadata[1], 0, "s0-rocketmq-orders-billing"},
+	{&testRocketMQMetadata[2], 1, "s1-rocketmq-orders-shipping"},
+}
+
+func TestRocketMQParseMetadata(t *testing.T) {
+	for _, testData := range testRocketMQMetadata {
+		_, err := parseRocketMQMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Error("Expected success but got error", err)
+		}
+		if testData.isError && err == nil {
+			t.Error("Expected error but got success")
+		}
+	}
+}
+
+func TestRocketMQGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range rocketMQMetricIdentifiers {
+		meta, err := parseRocketMQMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, AuthParams: testData.metadataTestData.authParams, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockRocketMQScaler := rocketMQScaler{metadata: meta}
+
+		metricSpec := mockRocketMQScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Error("Wrong External metric source name:", metricName)
+		}
+	}
+}
+
+func TestRocketMQCommandFrame(t *testing.T) {
+	var buf bytes.Buffer
+	request := &rocketMQCommand{Code: rocketMQGetMaxOffset, Language: "GO", Version: rocketMQVersion, Opaque: 7, ExtFields: map[string]string{"topic": "orders", "queueId": "1"}, Body: []byte("body")}
+	assert.NoError(t, writeRocketMQCommand(&buf, request))
+
+	command, err := readRocketMQCommand(&buf)
+	assert.NoError(t, err)
+	assert.Equal(t, request, command)
+
+	_, err = readRocketMQCommand(bytes.NewReader([]byte{0, 0, 0, 4, 1, 0, 0, 0}))
+	assert.Error(t, err, "header serialization other than json")
+}
+
+func TestRocketMQSignCommand(t *testing.T) {
+	command := &rocketMQCommand{ExtFields: map[string]string{"topic": "orders", "queueId": "0", "consumerGroup": "billing"}}
+	signRocketMQCommand(command, "keda", "secret")
+
+	// the values sorted by field name, AccessKey, consumerGroup, queueId and topic
+	mac := hmac.New(sha1.New, []byte("secret"))
+	mac.Write([]byte("kedabilling0orders"))
+	assert.Equal(t, base64.StdEncoding.EncodeToString(mac.Sum(nil)), command.ExtFields["Signature"])
+	assert.Equal(t, "keda", command.ExtFields["AccessKey"])
+}
+
+// rocketMQTestServer answers as the name server and the master broker of the topic, the consumer group
+// consumed up to consumerOffsets and has no offset on the queues missing in it
+func rocketMQTestServer(t *testing.T, maxOffsets []int64, consumerOffsets map[int]int64) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { listener.Close() })
+	addr := listener.Addr().String()
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			request, err := readRocketMQCommand(conn)
+			if err != nil {
+				conn.Close()
+				continue
+			}
+			response := &rocketMQCommand{Opaque: request.Opaque, Flag: 1, ExtFields: map[string]string{}}
+			queueID, _ := strconv.Atoi(request.ExtFields["queueId"])
+			switch request.Code {
+			case rocketMQGetRouteInfo:
+				// fastjson writes the broker ids unquoted
+				response.Body = []byte(fmt.Sprintf(`{"brokerDatas":[{"brokerAddrs":{0:"%s",1:"127.0.0.1:1"},"brokerName":"broker-a","cluster":"DefaultCluster"}],"queueDatas":[{"brokerName":"broker-a","perm":6,"readQueueNums":%d,"writeQueueNums":%d}]}`, addr, len(maxOffsets), len(maxOffsets)))
+			case rocketMQGetMaxOffset:
+				response.ExtFields["offset"] = strconv.FormatInt(maxOffsets[queueID], 10)
+			case rocketMQQueryConsumerOffset:
+				if offset, ok := consumerOffsets[queueID]; ok {
+					response.ExtFields["offset"] = strconv.FormatInt(offset, 10)
+				} else {
+					response.Code = rocketMQQueryNotFound
+					response.Remark = "Not found"
+				}
+			default:
+				response.Code = 1
+			}
+			_ = writeRocketMQCommand(conn, response)
+			conn.Close()
+		}
+	}()
+	return addr
+}
+
+func TestRocketMQGetConsumerLag(t *testing.T) {
+	addr := rocketMQTestServer(t, []int64{10, 20, 5}, map[int]int64{0: 4, 1: 20})
+
+	meta, err := parseRocketMQMetadata(&ScalerConfig{
+		TriggerMetadata: map[string]string{"nameServer": "127.0.0.1:1;" + addr, "topic": "orders", "consumerGroup": "billing"},
+		AuthParams:      map[string]string{"accessKey": "keda", "secretKey": "secret"},
+	})
+	if err != nil {
+		t.Fatal("Could not parse metadata:", err)
+	}
+	scaler := rocketMQScaler{metadata: meta, timeout: time.Second}
+
+	lag, err := scaler.getConsumerLag(context.TODO())
+
+	assert.NoError(t, err)
+	// 6 on queue 0, none on queue 1 and 5 on queue 2 without consumer offset
+	assert.Equal(t, int64(11), lag)
+}
+
+func TestRocketMQGetConsumerLagNameServerUnavailable(t *testing.T) {
+	meta, err := parseRocketMQMetadata(&ScalerConfig{
+		TriggerMetadata: map[string]string{"nameServer": "127.0.0.1:1", "topic": "orders", "consumerGroup": "billing"},
+	})
+	if err != nil {
+		t.Fatal("Could not parse metadata:", err)
+	}
+	scaler := rocketMQScaler{metadata: meta}
+
+	_, err = scaler.getConsumerLag(context.TODO())
+
+	assert.Error(t, err)
+}
@@ -18,7 +18,11 @@ package scalers
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"k8s.io/api/autoscaling/v2beta2"
@@ -26,6 +30,7 @@ import (
 	"k8s.io/metrics/pkg/apis/external_metrics"
 
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
 	metrics "github.com/rcrowley/go-metrics"
 )
 
@@ -35,6 +40,12 @@ func init() {
 	metrics.UseNilMetrics = true
 }
 
+const (
+	externalMetricType       = "External"
+	lagThresholdMetricName   = "lagThreshold"
+	defaultTargetQueueLength = 5
+)
+
 // Scaler interface
 type Scaler interface {
 
@@ -59,6 +70,26 @@ type PushScaler interface {
 	Run(ctx context.Context, active chan<- bool)
 }
 
+// MaxReplicaCountProvider is implemented by scalers that know the highest replica count the ScaleTarget can use,
+// eg. the partition count of a topic. It is read on every poll by ScaledObjects with maxReplicaCountFrom the trigger
+type MaxReplicaCountProvider interface {
+	GetMaxReplicaCount(ctx context.Context) (int64, error)
+}
+
+// JobParametersProvider is implemented by scalers that can split the work of their queue between the Jobs of
+// a ScaledJob, eg. by message group or partitions. It is read when ScaledJobs with jobParameters create Jobs
+type JobParametersProvider interface {
+	// GetJobParameters returns the parameters of up to count Jobs, a Job is created for every returned parameters.
+	// running are the parameters of the Jobs of the ScaledJob still running, their work isn't handed out again
+	GetJobParameters(ctx context.Context, count int64, running []JobParameters) ([]JobParameters, error)
+}
+
+// JobParameters are the environment variables and annotations added to a Job created by a ScaledJob
+type JobParameters struct {
+	Env         map[string]string
+	Annotations map[string]string
+}
+
 // ScalerConfig contains config fields common for all scalers
 type ScalerConfig struct {
 	// Name used for external scalers
@@ -105,3 +136,61 @@ func GetFromAuthOrMeta(config *ScalerConfig, field string) (string, error) {
 func GenerateMetricNameWithIndex(scalerIndex int, metricName string) string {
 	return fmt.Sprintf("s%d-%s", scalerIndex, metricName)
 }
+
+// getTLSOptions reads the TLS parameters recognized by every scaler connecting over TLS:
+// ca, cert, key and serverName from the TriggerAuthentication and unsafeSsl from the trigger metadata or the TriggerAuthentication
+func getTLSOptions(config *ScalerConfig) (kedautil.TLSOptions, error) {
+	options := kedautil.TLSOptions{
+		CA:         config.AuthParams["ca"],
+		Cert:       config.AuthParams["cert"],
+		Key:        config.AuthParams["key"],
+		ServerName: config.AuthParams["serverName"],
+	}
+	if (options.Cert == "") != (options.Key == "") {
+		return options, fmt.Errorf("cert and key must be given together")
+	}
+
+	for _, val := range []string{config.TriggerMetadata["unsafeSsl"], config.AuthParams["unsafeSsl"]} {
+		if val == "" {
+			continue
+		}
+		unsafeSsl, err := strconv.ParseBool(val)
+		if err != nil {
+			return options, fmt.Errorf("error parsing unsafeSsl: %s", err)
+		}
+		options.UnsafeSsl = options.UnsafeSsl || unsafeSsl
+	}
+
+	return options, nil
+}
+
+// createTLSConfig returns the TLS config with the TLS parameters of the trigger
+func createTLSConfig(config *ScalerConfig) (*tls.Config, error) {
+	options, err := getTLSOptions(config)
+	if err != nil {
+		return nil, err
+	}
+	tlsConfig, err := kedautil.NewTLSConfigFromOptions(options)
+	if err != nil {
+		return nil, fmt.Errorf("error creating the TLS config: %s", err)
+	}
+	return tlsConfig, nil
+}
+
+// createHTTPClient returns an HTTP client with the given timeout and the TLS parameters of the trigger
+func createHTTPClient(config *ScalerConfig, timeout time.Duration) (*http.Client, error) {
+	tlsConfig, err := createTLSConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	return kedautil.CreateHTTPClientWithTLSConfig(timeout, tlsConfig), nil
+}
+
+// Splits a string separated by a specified separator and trims space from all the elements.
+func splitAndTrimBySep(s string, sep string) []string {
+	x := strings.Split(s, sep)
+	for i := range x {
+		x[i] = strings.Trim(x[i], " ")
+	}
+	return x
+}
@@ -0,0 +1,95 @@
+package scalers
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type parseTLSOptionsTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+	unsafeSsl  bool
+}
+
+var testTLSOptionsData = []parseTLSOptionsTestData{
+	// nothing given
+	{map[string]string{}, map[string]string{}, false, false},
+	// unsafeSsl in metadata
+	{map[string]string{"unsafeSsl": "true"}, map[string]string{}, false, true},
+	// unsafeSsl in auth params
+	{map[string]string{}, map[string]string{"unsafeSsl": "true"}, false, true},
+	// unsafeSsl in metadata and auth params, any of them enables it
+	{map[string]string{"unsafeSsl": "false"}, map[string]string{"unsafeSsl": "true"}, false, true},
+	// malformed unsafeSsl
+	{map[string]string{"unsafeSsl": "yes"}, map[string]string{}, true, false},
+	// cert and key
+	{map[string]string{}, map[string]string{"cert": "ceert", "key": "keey"}, false, false},
+	// cert without key
+	{map[string]string{}, map[string]string{"cert": "ceert"}, true, false},
+	// key without cert
+	{map[string]string{}, map[string]string{"key": "keey"}, true, false},
+}
+
+func TestGetTLSOptions(t *testing.T) {
+	for _, testData := range testTLSOptionsData {
+		options, err := getTLSOptions(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Error("Expected success but got error", err)
+		}
+		if testData.isError && err == nil {
+			t.Error("Expected error but got success")
+		}
+		if err == nil && options.UnsafeSsl != testData.unsafeSsl {
+			t.Errorf("Expected unsafeSsl %v but got %v", testData.unsafeSsl, options.UnsafeSsl)
+		}
+	}
+}
+
+func TestCreateHTTPClientTLS(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+	ca := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+
+	var testData = []struct {
+		name       string
+		metadata   map[string]string
+		authParams map[string]string
+		isError    bool
+	}{
+		{"unknown ca", map[string]string{}, map[string]string{}, true},
+		{"unsafeSsl", map[string]string{"unsafeSsl": "true"}, map[string]string{}, false},
+		{"ca", map[string]string{}, map[string]string{"ca": ca}, false},
+		{"ca and serverName", map[string]string{}, map[string]string{"ca": ca, "serverName": "example.com"}, false},
+		{"ca and wrong serverName", map[string]string{}, map[string]string{"ca": ca, "serverName": "keda.sh"}, true},
+	}
+
+	for _, test := range testData {
+		client, err := createHTTPClient(&ScalerConfig{TriggerMetadata: test.metadata, AuthParams: test.authParams}, time.Second)
+		if err != nil {
+			t.Fatalf("%s: unexpected error creating client: %s", test.name, err)
+		}
+		resp, err := client.Get(server.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		if err != nil && !test.isError {
+			t.Errorf("%s: expected success but got error %s", test.name, err)
+		}
+		if test.isError && err == nil {
+			t.Errorf("%s: expected error but got success", test.name)
+		}
+	}
+}
+
+func TestCreateHTTPClientInvalidCA(t *testing.T) {
+	_, err := createHTTPClient(&ScalerConfig{TriggerMetadata: map[string]string{}, AuthParams: map[string]string{"ca": "caaa"}}, time.Second)
+	if err == nil {
+		t.Error("Expected error for a ca without certificate but got success")
+	}
+}
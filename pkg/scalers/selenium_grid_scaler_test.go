@@ -220,7 +220,6 @@ func Test_parseSeleniumGridScalerMetadata(t *testing.T) {
 				browserName:    "chrome",
 				targetValue:    1,
 				browserVersion: "91.0",
-				unsafeSsl:      false,
 			},
 		},
 		{
@@ -241,7 +240,6 @@ func Test_parseSeleniumGridScalerMetadata(t *testing.T) {
 				browserName:    "chrome",
 				targetValue:    1,
 				browserVersion: "91.0",
-				unsafeSsl:      true,
 			},
 		},
 	}
@@ -0,0 +1,277 @@
+//go:build !selective_scalers || scalers_database
+// +build !selective_scalers scalers_database
+
+package scalers
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"database/sql"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strconv"
+
+	sf "github.com/snowflakedb/gosnowflake"
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const snowflakeApplication = "keda"
+
+type snowflakeScaler struct {
+	metadata   *snowflakeMetadata
+	connection *sql.DB
+}
+
+type snowflakeMetadata struct {
+	// account is the account identifier, eg. myorg-myaccount or xy12345.eu-west-1
+	account  string
+	username string
+	// password authenticates with the password, privateKey with key-pair authentication
+	password   string
+	privateKey *rsa.PrivateKey
+	// warehouse, database, schema and role are the defaults of the session the query runs in
+	warehouse                  string
+	database                   string
+	schema                     string
+	role                       string
+	query                      string
+	targetQueryValue           float64
+	activationTargetQueryValue float64
+	metricName                 string
+	scalerIndex                int
+}
+
+var snowflakeLog = logf.Log.WithName("snowflake_scaler")
+
+// NewSnowflakeScaler creates a new snowflakeScaler
+func NewSnowflakeScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseSnowflakeMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing snowflake metadata: %s", err)
+	}
+
+	conn, err := newSnowflakeConnection(meta)
+	if err != nil {
+		return nil, fmt.Errorf("error establishing snowflake connection: %s", err)
+	}
+
+	return &snowflakeScaler{
+		metadata:   meta,
+		connection: conn,
+	}, nil
+}
+
+func parseSnowflakeMetadata(config *ScalerConfig) (*snowflakeMetadata, error) {
+	meta := snowflakeMetadata{}
+
+	account, err := GetFromAuthOrMeta(config, "account")
+	if err != nil {
+		return nil, err
+	}
+	meta.account = account
+
+	username, err := GetFromAuthOrMeta(config, "username")
+	if err != nil {
+		return nil, err
+	}
+	meta.username = username
+
+	if val, ok := config.AuthParams["password"]; ok && val != "" {
+		meta.password = val
+	} else if val, ok := config.TriggerMetadata["passwordFromEnv"]; ok && val != "" {
+		meta.password = config.ResolvedEnv[val]
+	}
+
+	if val, ok := config.AuthParams["privateKey"]; ok && val != "" {
+		privateKey, err := parseSnowflakePrivateKey(val)
+		if err != nil {
+			return nil, err
+		}
+		meta.privateKey = privateKey
+	}
+
+	switch {
+	case meta.password == "" && meta.privateKey == nil:
+		return nil, fmt.Errorf("no password or privateKey given")
+	case meta.password != "" && meta.privateKey != nil:
+		return nil, fmt.Errorf("password and privateKey can't be given together")
+	}
+
+	meta.warehouse = config.TriggerMetadata["warehouse"]
+	meta.database = config.TriggerMetadata["database"]
+	meta.schema = config.TriggerMetadata["schema"]
+	meta.role = config.TriggerMetadata["role"]
+
+	if val, ok := config.TriggerMetadata["query"]; ok && val != "" {
+		meta.query = val
+	} else {
+		return nil, fmt.Errorf("no query given")
+	}
+
+	if val, ok := config.TriggerMetadata["targetQueryValue"]; ok && val != "" {
+		targetQueryValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing targetQueryValue: %s", err)
+		}
+		meta.targetQueryValue = targetQueryValue
+	} else {
+		return nil, fmt.Errorf("no targetQueryValue given")
+	}
+
+	if val, ok := config.TriggerMetadata["activationTargetQueryValue"]; ok && val != "" {
+		activationTargetQueryValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing activationTargetQueryValue: %s", err)
+		}
+		meta.activationTargetQueryValue = activationTargetQueryValue
+	}
+
+	metricName := "query"
+	if val, ok := config.TriggerMetadata["metricName"]; ok && val != "" {
+		metricName = val
+	}
+	meta.metricName = kedautil.NormalizeString(fmt.Sprintf("snowflake-%s", metricName))
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+// parseSnowflakePrivateKey parses the unencrypted PEM encoded RSA private key of the user, in PKCS#8 as generated
+// with openssl genrsa 2048 | openssl pkcs8 -topk8 -nocrypt, or PKCS#1
+func parseSnowflakePrivateKey(key string) (*rsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(key))
+	if block == nil {
+		return nil, fmt.Errorf("privateKey isn't PEM encoded")
+	}
+
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing privateKey: %s", err)
+		}
+		privateKey, ok := parsed.(*rsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("privateKey has to be a RSA key")
+		}
+		return privateKey, nil
+	case "RSA PRIVATE KEY":
+		privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing privateKey: %s", err)
+		}
+		return privateKey, nil
+	default:
+		return nil, fmt.Errorf("privateKey has to be an unencrypted RSA private key, got %s", block.Type)
+	}
+}
+
+// snowflakeConfig returns the config of the driver, with key-pair authentication when a privateKey is given
+func snowflakeConfig(meta *snowflakeMetadata) sf.Config {
+	config := sf.Config{
+		Account:     meta.account,
+		User:        meta.username,
+		Warehouse:   meta.warehouse,
+		Database:    meta.database,
+		Schema:      meta.schema,
+		Role:        meta.role,
+		Application: snowflakeApplication,
+	}
+	if meta.privateKey != nil {
+		config.Authenticator = sf.AuthTypeJwt
+		config.PrivateKey = meta.privateKey
+	} else {
+		config.Authenticator = sf.AuthTypeSnowflake
+		config.Password = meta.password
+	}
+	return config
+}
+
+// newSnowflakeConnection creates the Snowflake db connection
+func newSnowflakeConnection(meta *snowflakeMetadata) (*sql.DB, error) {
+	db := sql.OpenDB(sf.NewConnector(sf.SnowflakeDriver{}, snowflakeConfig(meta)))
+	if err := db.Ping(); err != nil {
+		snowflakeLog.Error(err, fmt.Sprintf("Found error when pinging snowflake: %s", err))
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// Close disposes of the Snowflake connections
+func (s *snowflakeScaler) Close(context.Context) error {
+	if err := s.connection.Close(); err != nil {
+		snowflakeLog.Error(err, "Error closing snowflake connection")
+		return err
+	}
+	return nil
+}
+
+// IsActive returns true if the result of the query is above the activation target
+func (s *snowflakeScaler) IsActive(ctx context.Context) (bool, error) {
+	val, err := s.getQueryResult(ctx)
+	if err != nil {
+		snowflakeLog.Error(err, "error querying snowflake")
+		return false, err
+	}
+
+	return val > s.metadata.activationTargetQueryValue, nil
+}
+
+// getQueryResult returns the first column of the first row of the query, a query without rows or returning NULL is 0
+func (s *snowflakeScaler) getQueryResult(ctx context.Context) (float64, error) {
+	var value sql.NullFloat64
+	err := s.connection.QueryRowContext(ctx, s.metadata.query).Scan(&value)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return 0, nil
+	case err != nil:
+		return -1, fmt.Errorf("could not query snowflake: %s", err)
+	case !value.Valid:
+		return 0, nil
+	}
+	return value.Float64, nil
+}
+
+func (s *snowflakeScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetMetricValue := resource.NewMilliQuantity(int64(s.metadata.targetQueryValue*1000), resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, s.metadata.metricName),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetMetricValue,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+func (s *snowflakeScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	val, err := s.getQueryResult(ctx)
+	if err != nil {
+		snowflakeLog.Error(err, "error querying snowflake")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewMilliQuantity(int64(val*1000), resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
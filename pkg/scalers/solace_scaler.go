@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
 	"strings"
 
@@ -112,7 +113,10 @@ var solaceLog = logf.Log.WithName(solaceScalerID + "_scaler")
 //	Constructor for SolaceScaler
 func NewSolaceScaler(config *ScalerConfig) (Scaler, error) {
 	// Create HTTP Client
-	httpClient := kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, false)
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
 
 	// Parse Solace Metadata
 	solaceMetadata, err := parseSolaceMetadata(config)
@@ -132,7 +136,7 @@ func parseSolaceMetadata(config *ScalerConfig) (*SolaceMetadata, error) {
 	meta := SolaceMetadata{}
 	//	GET THE SEMP API ENDPOINT
 	if val, ok := config.TriggerMetadata[solaceMetaSempBaseURL]; ok && val != "" {
-		meta.solaceSempURL = val
+		meta.solaceSempURL = strings.TrimSuffix(val, "/")
 	} else {
 		return nil, fmt.Errorf(solaceFoundMetaFalse, solaceMetaSempBaseURL)
 	}
@@ -173,14 +177,15 @@ func parseSolaceMetadata(config *ScalerConfig) (*SolaceMetadata, error) {
 	}
 
 	// Format Solace SEMP Queue Endpoint (REST URL)
+	// Queue names may contain "/" (eg. topic-like names), SEMP expects them escaped in the path
 	meta.endpointURL = fmt.Sprintf(
 		solaceSempEndpointURLTemplate,
 		meta.solaceSempURL,
 		solaceAPIName,
 		solaceAPIVersion,
-		meta.messageVpn,
+		url.PathEscape(meta.messageVpn),
 		solaceAPIObjectTypeQueue,
-		meta.queueName)
+		url.PathEscape(meta.queueName))
 
 	// Get Credentials
 	var e error
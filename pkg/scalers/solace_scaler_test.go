@@ -2,8 +2,10 @@ package scalers
 
 import (
 	"context"
+	"encoding/pem"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"k8s.io/api/autoscaling/v2beta2"
@@ -455,3 +457,50 @@ func TestSolaceGetMetricSpec(t *testing.T) {
 		}
 	}
 }
+
+func TestSolaceGetMetricsFromSEMP(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, ok := r.BasicAuth()
+		if !ok || username != soltestValidUsername || password != soltestValidPassword {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if r.URL.EscapedPath() != "/SEMP/v2/monitor/msgVpns/"+soltestValidVpn+"/queues/orders%2Fin" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"collections":{"msgs":{"count":7}},"data":{"msgSpoolUsage":512},"meta":{"responseCode":200}}`))
+	}))
+	defer server.Close()
+
+	scaler, err := NewSolaceScaler(&ScalerConfig{
+		TriggerMetadata: map[string]string{
+			solaceMetaSempBaseURL:    server.URL + "/",
+			solaceMetaMsgVpn:         soltestValidVpn,
+			solaceMetaQueueName:      "orders/in",
+			solaceMetaMsgCountTarget: soltestValidMsgCountTarget,
+		},
+		AuthParams: map[string]string{
+			solaceMetaUsername: soltestValidUsername,
+			solaceMetaPassword: soltestValidPassword,
+			"ca":               string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})),
+		},
+	})
+	if err != nil {
+		t.Fatal("Could not create scaler:", err)
+	}
+
+	expected := map[string]int64{
+		"s0-solace-orders-in-" + solaceTriggermsgcount:      7,
+		"s0-solace-orders-in-" + solaceTriggermsgspoolusage: 512,
+	}
+	for metricName, value := range expected {
+		metrics, err := scaler.GetMetrics(context.Background(), metricName, nil)
+		if err != nil {
+			t.Fatalf("Could not get metric %s: %s", metricName, err)
+		}
+		if metrics[0].Value.Value() != value {
+			t.Errorf("Expected %d for %s but got %d", value, metricName, metrics[0].Value.Value())
+		}
+	}
+}
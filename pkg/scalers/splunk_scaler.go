@@ -0,0 +1,280 @@
+package scalers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	url_pkg "net/url"
+	"strconv"
+	"strings"
+
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	splunkHost            = "host"
+	splunkUsername        = "username"
+	splunkPassword        = "password"
+	splunkAPIToken        = "apiToken"
+	splunkSavedSearchName = "savedSearchName"
+	splunkQuery           = "query"
+	splunkApp             = "app"
+	splunkOwner           = "owner"
+	splunkValueField      = "valueField"
+	splunkTargetValue     = "targetValue"
+	splunkActivationValue = "activationValue"
+	splunkMetricName      = "metricName"
+
+	defaultSplunkApp   = "search"
+	defaultSplunkOwner = "nobody"
+)
+
+type splunkScaler struct {
+	metadata   *splunkMetadata
+	httpClient *http.Client
+}
+
+type splunkMetadata struct {
+	// host is the URL of the management port, eg. https://splunk.example.com:8089
+	host     string
+	username string
+	password string
+	apiToken string
+	// search is the SPL run as a oneshot search job, either the saved search or the ad-hoc query
+	search string
+	// app and owner are the namespace the search job runs in, saved searches are looked up in it
+	app             string
+	owner           string
+	valueField      string
+	targetValue     float64
+	activationValue float64
+	metricName      string
+	scalerIndex     int
+}
+
+type splunkSearchResults struct {
+	Results []map[string]interface{} `json:"results"`
+}
+
+var splunkLog = logf.Log.WithName("splunk_scaler")
+
+// NewSplunkScaler creates a new splunkScaler
+func NewSplunkScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseSplunkMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing splunk metadata: %s", err)
+	}
+
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &splunkScaler{
+		metadata:   meta,
+		httpClient: httpClient,
+	}, nil
+}
+
+func parseSplunkMetadata(config *ScalerConfig) (*splunkMetadata, error) {
+	meta := splunkMetadata{
+		app:   defaultSplunkApp,
+		owner: defaultSplunkOwner,
+	}
+
+	host, err := GetFromAuthOrMeta(config, splunkHost)
+	if err != nil {
+		return nil, err
+	}
+	meta.host = strings.TrimSuffix(host, "/")
+
+	meta.apiToken = config.AuthParams[splunkAPIToken]
+	meta.username = config.AuthParams[splunkUsername]
+	meta.password = config.AuthParams[splunkPassword]
+	switch {
+	case meta.apiToken != "" && (meta.username != "" || meta.password != ""):
+		return nil, fmt.Errorf("either %s or %s and %s can be given", splunkAPIToken, splunkUsername, splunkPassword)
+	case meta.apiToken == "" && (meta.username == "" || meta.password == ""):
+		return nil, fmt.Errorf("no %s or %s and %s given", splunkAPIToken, splunkUsername, splunkPassword)
+	}
+
+	savedSearchName := config.TriggerMetadata[splunkSavedSearchName]
+	query := strings.TrimSpace(config.TriggerMetadata[splunkQuery])
+	switch {
+	case savedSearchName != "" && query != "":
+		return nil, fmt.Errorf("either %s or %s can be given", splunkSavedSearchName, splunkQuery)
+	case savedSearchName != "":
+		meta.search = fmt.Sprintf("| savedsearch %s", strconv.Quote(savedSearchName))
+	case query != "":
+		// the search jobs endpoint needs a generating command, plain SPL starts with the implicit search command
+		if strings.HasPrefix(query, "search ") || strings.HasPrefix(query, "|") {
+			meta.search = query
+		} else {
+			meta.search = fmt.Sprintf("search %s", query)
+		}
+	default:
+		return nil, fmt.Errorf("no %s or %s given", splunkSavedSearchName, splunkQuery)
+	}
+
+	if val, ok := config.TriggerMetadata[splunkApp]; ok && val != "" {
+		meta.app = val
+	}
+	if val, ok := config.TriggerMetadata[splunkOwner]; ok && val != "" {
+		meta.owner = val
+	}
+
+	if val, ok := config.TriggerMetadata[splunkValueField]; ok && val != "" {
+		meta.valueField = val
+	} else {
+		return nil, fmt.Errorf("no %s given", splunkValueField)
+	}
+
+	if val, ok := config.TriggerMetadata[splunkTargetValue]; ok && val != "" {
+		targetValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", splunkTargetValue, err)
+		}
+		meta.targetValue = targetValue
+	} else {
+		return nil, fmt.Errorf("no %s given", splunkTargetValue)
+	}
+
+	if val, ok := config.TriggerMetadata[splunkActivationValue]; ok && val != "" {
+		activationValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", splunkActivationValue, err)
+		}
+		meta.activationValue = activationValue
+	}
+
+	metricName := savedSearchName
+	if val, ok := config.TriggerMetadata[splunkMetricName]; ok && val != "" {
+		metricName = val
+	} else if metricName == "" {
+		metricName = meta.valueField
+	}
+	// saved search names usually contain spaces
+	meta.metricName = kedautil.NormalizeString(fmt.Sprintf("splunk-%s", strings.Join(strings.Fields(metricName), "-")))
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+// IsActive returns true if the value of the search is above the activation value
+func (s *splunkScaler) IsActive(ctx context.Context) (bool, error) {
+	val, err := s.getSearchValue(ctx)
+	if err != nil {
+		splunkLog.Error(err, "error running splunk search")
+		return false, err
+	}
+
+	return val > s.metadata.activationValue, nil
+}
+
+func (s *splunkScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *splunkScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetMetricValue := resource.NewMilliQuantity(int64(s.metadata.targetValue*1000), resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, s.metadata.metricName),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetMetricValue,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+// getSearchValue runs the search as a oneshot job and returns the valueField of the first result row,
+// a search without results is 0
+func (s *splunkScaler) getSearchValue(ctx context.Context) (float64, error) {
+	form := url_pkg.Values{}
+	form.Set("search", s.metadata.search)
+	form.Set("exec_mode", "oneshot")
+	form.Set("output_mode", "json")
+	form.Set("count", "1")
+	url := fmt.Sprintf("%s/servicesNS/%s/%s/search/jobs", s.metadata.host, url_pkg.PathEscape(s.metadata.owner), url_pkg.PathEscape(s.metadata.app))
+
+	req, err := http.NewRequestWithContext(ctx, "POST", url, strings.NewReader(form.Encode()))
+	if err != nil {
+		return -1, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	if s.metadata.apiToken != "" {
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", s.metadata.apiToken))
+	} else {
+		req.SetBasicAuth(s.metadata.username, s.metadata.password)
+	}
+
+	r, err := s.httpClient.Do(req)
+	if err != nil {
+		return -1, err
+	}
+
+	b, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return -1, err
+	}
+	r.Body.Close()
+
+	if !(r.StatusCode >= 200 && r.StatusCode <= 299) {
+		return -1, fmt.Errorf("splunk api returned error. status: %d response: %s", r.StatusCode, string(b))
+	}
+
+	var result splunkSearchResults
+	if err := json.Unmarshal(b, &result); err != nil {
+		return -1, err
+	}
+
+	if len(result.Results) == 0 {
+		return 0, nil
+	}
+
+	// field values of the results are strings, multivalue fields are arrays of strings
+	field, ok := result.Results[0][s.metadata.valueField]
+	if !ok {
+		return -1, fmt.Errorf("splunk search result has no field %s", s.metadata.valueField)
+	}
+	value, ok := field.(string)
+	if !ok {
+		return -1, fmt.Errorf("splunk search result field %s has to be a single value", s.metadata.valueField)
+	}
+	v, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return -1, fmt.Errorf("error parsing splunk search result field %s: %s", s.metadata.valueField, err)
+	}
+	return v, nil
+}
+
+func (s *splunkScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	val, err := s.getSearchValue(ctx)
+	if err != nil {
+		splunkLog.Error(err, "error running splunk search")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewMilliQuantity(int64(val*1000), resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
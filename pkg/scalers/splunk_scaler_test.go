@@ -0,0 +1,174 @@
+package scalers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type parseSplunkMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+}
+
+type splunkMetricIdentifier struct {
+	metadataTestData *parseSplunkMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testSplunkMetadata = []parseSplunkMetadataTestData{
+	// nothing passed
+	{map[string]string{}, map[string]string{}, true},
+	// properly formed saved search with token
+	{map[string]string{"host": "https://splunk:8089", "savedSearchName": "Pending orders", "valueField": "count", "targetValue": "10"}, map[string]string{"apiToken": "token"}, false},
+	// ad-hoc query with basic auth, app, owner, activationValue, metricName and unsafeSsl
+	{map[string]string{"query": "index=orders status=pending | stats count", "app": "orders", "owner": "admin", "valueField": "count", "targetValue": "2.5", "activationValue": "1", "metricName": "orders", "unsafeSsl": "true"}, map[string]string{"host": "https://splunk:8089/", "username": "admin", "password": "changeme"}, false},
+	// missing host
+	{map[string]string{"savedSearchName": "Pending orders", "valueField": "count", "targetValue": "10"}, map[string]string{"apiToken": "token"}, true},
+	// missing credentials
+	{map[string]string{"host": "https://splunk:8089", "savedSearchName": "Pending orders", "valueField": "count", "targetValue": "10"}, map[string]string{}, true},
+	// username without password
+	{map[string]string{"host": "https://splunk:8089", "savedSearchName": "Pending orders", "valueField": "count", "targetValue": "10"}, map[string]string{"username": "admin"}, true},
+	// token and basic auth
+	{map[string]string{"host": "https://splunk:8089", "savedSearchName": "Pending orders", "valueField": "count", "targetValue": "10"}, map[string]string{"apiToken": "token", "username": "admin", "password": "changeme"}, true},
+	// missing savedSearchName and query
+	{map[string]string{"host": "https://splunk:8089", "valueField": "count", "targetValue": "10"}, map[string]string{"apiToken": "token"}, true},
+	// savedSearchName and query
+	{map[string]string{"host": "https://splunk:8089", "savedSearchName": "Pending orders", "query": "index=orders | stats count", "valueField": "count", "targetValue": "10"}, map[string]string{"apiToken": "token"}, true},
+	// missing valueField
+	{map[string]string{"host": "https://splunk:8089", "savedSearchName": "Pending orders", "targetValue": "10"}, map[string]string{"apiToken": "token"}, true},
+	// missing targetValue
+	{map[string]string{"host": "https://splunk:8089", "savedSearchName": "Pending orders", "valueField": "count"}, map[string]string{"apiToken": "token"}, true},
+	// malformed targetValue
+	{map[string]string{"host": "https://splunk:8089", "savedSearchName": "Pending orders", "valueField": "count", "targetValue": "a"}, map[string]string{"apiToken": "token"}, true},
+	// malformed activationValue
+	{map[string]string{"host": "https://splunk:8089", "savedSearchName": "Pending orders", "valueField": "count", "targetValue": "10", "activationValue": "a"}, map[string]string{"apiToken": "token"}, true},
+}
+
+var splunkMetricIdentifiers = []splunkMetricIdentifier{
+	{&testSplunkMetadata[1], 0, "s0-splunk-Pending-orders"},
+	{&testSplunkMetadata[2], 1, "s1-splunk-orders"},
+}
+
+func TestSplunkParseMetadata(t *testing.T) {
+	for _, testData := range testSplunkMetadata {
+		_, err := parseSplunkMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Error("Expected success but got error", err)
+		}
+		if testData.isError && err == nil {
+			t.Error("Expected error but got success")
+		}
+	}
+}
+
+func TestSplunkParseSearch(t *testing.T) {
+	var testData = []struct {
+		metadata map[string]string
+		search   string
+	}{
+		{map[string]string{"savedSearchName": "Pending orders"}, `| savedsearch "Pending orders"`},
+		{map[string]string{"query": "index=orders | stats count"}, "search index=orders | stats count"},
+		{map[string]string{"query": "search index=orders | stats count"}, "search index=orders | stats count"},
+		{map[string]string{"query": "| tstats count where index=orders"}, "| tstats count where index=orders"},
+	}
+
+	for _, test := range testData {
+		test.metadata["host"] = "https://splunk:8089"
+		test.metadata["valueField"] = "count"
+		test.metadata["targetValue"] = "10"
+		meta, err := parseSplunkMetadata(&ScalerConfig{TriggerMetadata: test.metadata, AuthParams: map[string]string{"apiToken": "token"}})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		assert.Equal(t, test.search, meta.search)
+	}
+}
+
+func TestSplunkGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range splunkMetricIdentifiers {
+		meta, err := parseSplunkMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, AuthParams: testData.metadataTestData.authParams, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockSplunkScaler := splunkScaler{
+			metadata:   meta,
+			httpClient: http.DefaultClient,
+		}
+
+		metricSpec := mockSplunkScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Error("Wrong External metric source name:", metricName)
+		}
+	}
+}
+
+func TestSplunkGetSearchValue(t *testing.T) {
+	var testData = []struct {
+		name           string
+		authParams     map[string]string
+		bodyStr        string
+		responseStatus int
+		expectedValue  float64
+		isError        bool
+	}{
+		{"first row with token", map[string]string{"apiToken": "token"}, `{"results":[{"count":"12.5","host":"a"},{"count":"3","host":"b"}]}`, http.StatusOK, 12.5, false},
+		{"first row with basic auth", map[string]string{"username": "admin", "password": "changeme"}, `{"results":[{"count":"7"}]}`, http.StatusOK, 7, false},
+		{"no results", map[string]string{"apiToken": "token"}, `{"results":[]}`, http.StatusOK, 0, false},
+		{"missing field", map[string]string{"apiToken": "token"}, `{"results":[{"host":"a"}]}`, http.StatusOK, -1, true},
+		{"multivalue field", map[string]string{"apiToken": "token"}, `{"results":[{"count":["1","2"]}]}`, http.StatusOK, -1, true},
+		{"malformed value", map[string]string{"apiToken": "token"}, `{"results":[{"count":"a"}]}`, http.StatusOK, -1, true},
+		{"error status response", map[string]string{"apiToken": "token"}, `{"messages":[{"type":"WARN","text":"call not properly authenticated"}]}`, http.StatusUnauthorized, -1, true},
+	}
+
+	for _, test := range testData {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+				assert.Equal(t, "/servicesNS/nobody/search/search/jobs", request.URL.Path)
+				assert.NoError(t, request.ParseForm())
+				assert.Equal(t, `| savedsearch "Pending orders"`, request.PostForm.Get("search"))
+				assert.Equal(t, "oneshot", request.PostForm.Get("exec_mode"))
+				assert.Equal(t, "json", request.PostForm.Get("output_mode"))
+				if token, ok := test.authParams["apiToken"]; ok {
+					assert.Equal(t, "Bearer "+token, request.Header.Get("Authorization"))
+				} else {
+					username, password, ok := request.BasicAuth()
+					assert.True(t, ok)
+					assert.Equal(t, test.authParams["username"], username)
+					assert.Equal(t, test.authParams["password"], password)
+				}
+				writer.WriteHeader(test.responseStatus)
+
+				if _, err := writer.Write([]byte(test.bodyStr)); err != nil {
+					t.Fatal(err)
+				}
+			}))
+			defer server.Close()
+
+			meta, err := parseSplunkMetadata(&ScalerConfig{
+				TriggerMetadata: map[string]string{"host": server.URL, "savedSearchName": "Pending orders", "valueField": "count", "targetValue": "10"},
+				AuthParams:      test.authParams,
+			})
+			if err != nil {
+				t.Fatal("Could not parse metadata:", err)
+			}
+			scaler := splunkScaler{metadata: meta, httpClient: http.DefaultClient}
+
+			value, err := scaler.getSearchValue(context.TODO())
+
+			assert.Equal(t, test.expectedValue, value)
+			if test.isError {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
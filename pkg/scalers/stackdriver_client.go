@@ -1,3 +1,6 @@
+//go:build !selective_scalers || scalers_gcp
+// +build !selective_scalers scalers_gcp
+
 package scalers
 
 import (
@@ -63,6 +66,105 @@ func NewStackDriverClientPodIdentity(ctx context.Context) (*StackDriverClient, e
 
 // GetMetrics fetches metrics from stackdriver for a specific filter for the last minute
 func (s StackDriverClient) GetMetrics(ctx context.Context, filter string, projectID string) (int64, error) {
+	point, err := s.getLatestPoint(ctx, filter, projectID)
+	if err != nil {
+		return -1, err
+	}
+	if point == nil {
+		return -1, nil
+	}
+	return point.GetValue().GetInt64Value(), nil
+}
+
+// GetDoubleMetrics fetches a metric of value type DOUBLE, eg. a utilization, for a specific filter for the last minute
+func (s StackDriverClient) GetDoubleMetrics(ctx context.Context, filter string, projectID string) (float64, error) {
+	point, err := s.getLatestPoint(ctx, filter, projectID)
+	if err != nil {
+		return -1, err
+	}
+	if point == nil {
+		return -1, nil
+	}
+	return point.GetValue().GetDoubleValue(), nil
+}
+
+// GetSummedMetrics fetches a metric reported per partition, eg. the backlog of a Pub/Sub Lite subscription,
+// for a specific filter for the last minute and adds up the newest points of all its time series
+func (s StackDriverClient) GetSummedMetrics(ctx context.Context, filter string, projectID string) (int64, error) {
+	var sum int64
+	err := s.forEachLatestPoint(ctx, filter, projectID, func(point *monitoringpb.Point) {
+		sum += point.GetValue().GetInt64Value()
+	})
+	if err != nil {
+		return -1, err
+	}
+	return sum, nil
+}
+
+// GetSummedDoubleMetrics is GetSummedMetrics for a metric of value type DOUBLE, eg. a utilization reported per database
+func (s StackDriverClient) GetSummedDoubleMetrics(ctx context.Context, filter string, projectID string) (float64, error) {
+	var sum float64
+	err := s.forEachLatestPoint(ctx, filter, projectID, func(point *monitoringpb.Point) {
+		sum += point.GetValue().GetDoubleValue()
+	})
+	if err != nil {
+		return -1, err
+	}
+	return sum, nil
+}
+
+// forEachLatestPoint calls f with the newest point of every time series matching the filter,
+// it returns an error if no time series matches
+func (s StackDriverClient) forEachLatestPoint(ctx context.Context, filter string, projectID string, f func(point *monitoringpb.Point)) error {
+	it := s.metricsClient.ListTimeSeries(ctx, s.newListTimeSeriesRequest(filter, projectID))
+
+	found := false
+	for {
+		resp, err := it.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return err
+		}
+		found = true
+		if len(resp.GetPoints()) > 0 {
+			f(resp.GetPoints()[0])
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("could not find stackdriver metric with filter %s", filter)
+	}
+	return nil
+}
+
+// getLatestPoint returns the newest point of the first time series matching the filter,
+// it returns nil if the time series has no points
+func (s StackDriverClient) getLatestPoint(ctx context.Context, filter string, projectID string) (*monitoringpb.Point, error) {
+	// Get an iterator with the list of time series
+	it := s.metricsClient.ListTimeSeries(ctx, s.newListTimeSeriesRequest(filter, projectID))
+
+	// Get the value from the first metric returned
+	resp, err := it.Next()
+
+	if err == iterator.Done {
+		return nil, fmt.Errorf("could not find stackdriver metric with filter %s", filter)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(resp.GetPoints()) > 0 {
+		return resp.GetPoints()[0], nil
+	}
+
+	return nil, nil
+}
+
+// newListTimeSeriesRequest returns a request for the time series matching the filter of the last minutes
+func (s StackDriverClient) newListTimeSeriesRequest(filter string, projectID string) *monitoringpb.ListTimeSeriesRequest {
 	// Set the start time to 1 minute ago
 	startTime := time.Now().UTC().Add(time.Minute * -2)
 
@@ -89,28 +191,7 @@ func (s StackDriverClient) GetMetrics(ctx context.Context, filter string, projec
 		req.Name = "projects/" + projectID
 	}
 
-	// Get an iterator with the list of time series
-	it := s.metricsClient.ListTimeSeries(ctx, req)
-
-	var value int64 = -1
-
-	// Get the value from the first metric returned
-	resp, err := it.Next()
-
-	if err == iterator.Done {
-		return value, fmt.Errorf("could not find stackdriver metric with filter %s", filter)
-	}
-
-	if err != nil {
-		return value, err
-	}
-
-	if len(resp.GetPoints()) > 0 {
-		point := resp.GetPoints()[0]
-		value = point.GetValue().GetInt64Value()
-	}
-
-	return value, nil
+	return req
 }
 
 // GoogleApplicationCredentials is a struct representing the format of a service account
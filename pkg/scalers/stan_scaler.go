@@ -67,10 +67,15 @@ func NewStanScaler(config *ScalerConfig) (Scaler, error) {
 		return nil, fmt.Errorf("error parsing stan metadata: %s", err)
 	}
 
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
 	return &stanScaler{
 		channelInfo: &monitorChannelInfo{},
 		metadata:    stanMetadata,
-		httpClient:  kedautil.CreateHTTPClient(config.GlobalHTTPTimeout, false),
+		httpClient:  httpClient,
 	}, nil
 }
 
@@ -0,0 +1,243 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package temporal is a minimal gRPC client of the WorkflowService of a Temporal frontend.
+// It only implements DescribeTaskQueue, encoding the messages of temporal.api.workflowservice.v1
+// by hand so the scaler doesn't depend on the Temporal SDK and its generated API.
+package temporal
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/encoding/protowire"
+)
+
+const (
+	// ServiceName is the full name of the Temporal WorkflowService
+	ServiceName = "temporal.api.workflowservice.v1.WorkflowService"
+	// DescribeTaskQueueMethodName is the name of the DescribeTaskQueue rpc of the WorkflowService
+	DescribeTaskQueueMethodName = "DescribeTaskQueue"
+)
+
+// TaskQueueType is temporal.api.enums.v1.TaskQueueType
+type TaskQueueType int32
+
+const (
+	TaskQueueTypeWorkflow TaskQueueType = 1
+	TaskQueueTypeActivity TaskQueueType = 2
+)
+
+// taskQueueKindNormal is temporal.api.enums.v1.TaskQueueKind TASK_QUEUE_KIND_NORMAL
+const taskQueueKindNormal = 1
+
+// DescribeTaskQueueRequest is temporal.api.workflowservice.v1.DescribeTaskQueueRequest
+type DescribeTaskQueueRequest struct {
+	Namespace              string
+	TaskQueue              string
+	TaskQueueType          TaskQueueType
+	IncludeTaskQueueStatus bool
+}
+
+// DescribeTaskQueueResponse is temporal.api.workflowservice.v1.DescribeTaskQueueResponse with the
+// pollers and the backlog of the task queue status, the other fields are skipped
+type DescribeTaskQueueResponse struct {
+	Pollers          []PollerInfo
+	BacklogCountHint int64
+}
+
+// PollerInfo is temporal.api.taskqueue.v1.PollerInfo with the identity of the poller
+type PollerInfo struct {
+	Identity string
+}
+
+// Marshal encodes the request in the protobuf wire format
+func (r *DescribeTaskQueueRequest) Marshal() ([]byte, error) {
+	var taskQueue []byte
+	taskQueue = protowire.AppendTag(taskQueue, 1, protowire.BytesType)
+	taskQueue = protowire.AppendString(taskQueue, r.TaskQueue)
+	taskQueue = protowire.AppendTag(taskQueue, 2, protowire.VarintType)
+	taskQueue = protowire.AppendVarint(taskQueue, taskQueueKindNormal)
+
+	var b []byte
+	b = protowire.AppendTag(b, 1, protowire.BytesType)
+	b = protowire.AppendString(b, r.Namespace)
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	b = protowire.AppendBytes(b, taskQueue)
+	b = protowire.AppendTag(b, 3, protowire.VarintType)
+	b = protowire.AppendVarint(b, uint64(r.TaskQueueType))
+	b = protowire.AppendTag(b, 4, protowire.VarintType)
+	b = protowire.AppendVarint(b, protowire.EncodeBool(r.IncludeTaskQueueStatus))
+	return b, nil
+}
+
+// Unmarshal decodes the request from the protobuf wire format
+func (r *DescribeTaskQueueRequest) Unmarshal(b []byte) error {
+	return consumeFields(b, func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) error {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			r.Namespace = string(value)
+		case num == 2 && typ == protowire.BytesType:
+			return consumeFields(value, func(num protowire.Number, typ protowire.Type, value []byte, _ uint64) error {
+				if num == 1 && typ == protowire.BytesType {
+					r.TaskQueue = string(value)
+				}
+				return nil
+			})
+		case num == 3 && typ == protowire.VarintType:
+			r.TaskQueueType = TaskQueueType(varint)
+		case num == 4 && typ == protowire.VarintType:
+			r.IncludeTaskQueueStatus = protowire.DecodeBool(varint)
+		}
+		return nil
+	})
+}
+
+// Marshal encodes the response in the protobuf wire format
+func (r *DescribeTaskQueueResponse) Marshal() ([]byte, error) {
+	var b []byte
+	for _, poller := range r.Pollers {
+		var pollerInfo []byte
+		pollerInfo = protowire.AppendTag(pollerInfo, 2, protowire.BytesType)
+		pollerInfo = protowire.AppendString(pollerInfo, poller.Identity)
+		b = protowire.AppendTag(b, 1, protowire.BytesType)
+		b = protowire.AppendBytes(b, pollerInfo)
+	}
+
+	var status []byte
+	status = protowire.AppendTag(status, 1, protowire.VarintType)
+	status = protowire.AppendVarint(status, uint64(r.BacklogCountHint))
+	b = protowire.AppendTag(b, 2, protowire.BytesType)
+	b = protowire.AppendBytes(b, status)
+	return b, nil
+}
+
+// Unmarshal decodes the response from the protobuf wire format
+func (r *DescribeTaskQueueResponse) Unmarshal(b []byte) error {
+	return consumeFields(b, func(num protowire.Number, typ protowire.Type, value []byte, _ uint64) error {
+		switch {
+		case num == 1 && typ == protowire.BytesType:
+			var poller PollerInfo
+			err := consumeFields(value, func(num protowire.Number, typ protowire.Type, value []byte, _ uint64) error {
+				if num == 2 && typ == protowire.BytesType {
+					poller.Identity = string(value)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			r.Pollers = append(r.Pollers, poller)
+		case num == 2 && typ == protowire.BytesType:
+			return consumeFields(value, func(num protowire.Number, typ protowire.Type, _ []byte, varint uint64) error {
+				if num == 1 && typ == protowire.VarintType {
+					r.BacklogCountHint = int64(varint)
+				}
+				return nil
+			})
+		}
+		return nil
+	})
+}
+
+// consumeFields calls fn with the value of every field of the message, length-delimited values are
+// passed as bytes and varint values as integer, other values are skipped
+func consumeFields(b []byte, fn func(num protowire.Number, typ protowire.Type, value []byte, varint uint64) error) error {
+	for len(b) > 0 {
+		num, typ, n := protowire.ConsumeTag(b)
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		var value []byte
+		var varint uint64
+		switch typ {
+		case protowire.BytesType:
+			value, n = protowire.ConsumeBytes(b)
+		case protowire.VarintType:
+			varint, n = protowire.ConsumeVarint(b)
+		default:
+			n = protowire.ConsumeFieldValue(num, typ, b)
+		}
+		if n < 0 {
+			return protowire.ParseError(n)
+		}
+		b = b[n:]
+
+		if err := fn(num, typ, value, varint); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type message interface {
+	Marshal() ([]byte, error)
+	Unmarshal([]byte) error
+}
+
+// Codec is the grpc codec of the messages of this package, it is named proto to send the
+// application/grpc+proto content type a Temporal frontend expects
+type Codec struct{}
+
+// Marshal returns the wire format of v
+func (Codec) Marshal(v interface{}) ([]byte, error) {
+	m, ok := v.(message)
+	if !ok {
+		return nil, fmt.Errorf("failed to marshal, message is %T, want a temporal message", v)
+	}
+	return m.Marshal()
+}
+
+// Unmarshal parses the wire format into v
+func (Codec) Unmarshal(data []byte, v interface{}) error {
+	m, ok := v.(message)
+	if !ok {
+		return fmt.Errorf("failed to unmarshal, message is %T, want a temporal message", v)
+	}
+	return m.Unmarshal(data)
+}
+
+// Name returns the name of the codec
+func (Codec) Name() string {
+	return "proto"
+}
+
+// WorkflowServiceClient is the client of the rpcs of the WorkflowService used by KEDA
+type WorkflowServiceClient interface {
+	DescribeTaskQueue(ctx context.Context, in *DescribeTaskQueueRequest, opts ...grpc.CallOption) (*DescribeTaskQueueResponse, error)
+}
+
+type workflowServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+// NewWorkflowServiceClient returns a WorkflowServiceClient using the connection
+func NewWorkflowServiceClient(cc grpc.ClientConnInterface) WorkflowServiceClient {
+	return &workflowServiceClient{cc}
+}
+
+func (c *workflowServiceClient) DescribeTaskQueue(ctx context.Context, in *DescribeTaskQueueRequest, opts ...grpc.CallOption) (*DescribeTaskQueueResponse, error) {
+	out := new(DescribeTaskQueueResponse)
+	opts = append(opts, grpc.ForceCodec(Codec{}))
+	err := c.cc.Invoke(ctx, "/"+ServiceName+"/"+DescribeTaskQueueMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,229 @@
+package scalers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/metadata"
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/kedacore/keda/v2/pkg/scalers/temporal"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	defaultTemporalNamespace       = "default"
+	defaultTemporalTargetQueueSize = 5
+
+	temporalQueueTypeWorkflow = "workflow"
+	temporalQueueTypeActivity = "activity"
+)
+
+type temporalScaler struct {
+	metadata   *temporalMetadata
+	connection *grpc.ClientConn
+	client     temporal.WorkflowServiceClient
+}
+
+type temporalMetadata struct {
+	endpoint        string
+	namespace       string
+	taskQueue       string
+	queueTypes      []temporal.TaskQueueType
+	targetQueueSize int64
+
+	// auth
+	apiKey string
+
+	// TLS
+	enableTLS bool
+
+	scalerIndex int
+}
+
+var temporalLog = logf.Log.WithName("temporal_scaler")
+
+// NewTemporalScaler creates a new temporalScaler
+func NewTemporalScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseTemporalMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing temporal metadata: %s", err)
+	}
+
+	transportOption := grpc.WithInsecure()
+	if meta.enableTLS {
+		tlsConfig, err := createTLSConfig(config)
+		if err != nil {
+			return nil, err
+		}
+		transportOption = grpc.WithTransportCredentials(credentials.NewTLS(tlsConfig))
+	}
+
+	conn, err := grpc.Dial(meta.endpoint, transportOption)
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to temporal frontend: %s", err)
+	}
+
+	return &temporalScaler{
+		metadata:   meta,
+		connection: conn,
+		client:     temporal.NewWorkflowServiceClient(conn),
+	}, nil
+}
+
+func parseTemporalMetadata(config *ScalerConfig) (*temporalMetadata, error) {
+	meta := temporalMetadata{
+		namespace:       defaultTemporalNamespace,
+		targetQueueSize: defaultTemporalTargetQueueSize,
+		queueTypes:      []temporal.TaskQueueType{temporal.TaskQueueTypeWorkflow, temporal.TaskQueueTypeActivity},
+	}
+
+	// endpoint is the host:port of the gRPC api of the Temporal frontend
+	endpoint, err := GetFromAuthOrMeta(config, "endpoint")
+	if err != nil {
+		return nil, err
+	}
+	meta.endpoint = endpoint
+
+	if val, ok := config.TriggerMetadata["namespace"]; ok && val != "" {
+		meta.namespace = val
+	}
+
+	if val, ok := config.TriggerMetadata["taskQueue"]; ok && val != "" {
+		meta.taskQueue = val
+	} else {
+		return nil, fmt.Errorf("no taskQueue given")
+	}
+
+	// queueTypes selects the backlogs of the task queue that are added up, eg. "activity"
+	if val, ok := config.TriggerMetadata["queueTypes"]; ok && val != "" {
+		meta.queueTypes = nil
+		for _, queueType := range splitAndTrimBySep(val, ",") {
+			switch queueType {
+			case temporalQueueTypeWorkflow:
+				meta.queueTypes = append(meta.queueTypes, temporal.TaskQueueTypeWorkflow)
+			case temporalQueueTypeActivity:
+				meta.queueTypes = append(meta.queueTypes, temporal.TaskQueueTypeActivity)
+			default:
+				return nil, fmt.Errorf("queueType %s must be one of %s, %s", queueType, temporalQueueTypeWorkflow, temporalQueueTypeActivity)
+			}
+		}
+	}
+
+	if val, ok := config.TriggerMetadata["targetQueueSize"]; ok && val != "" {
+		targetQueueSize, err := strconv.ParseInt(val, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing targetQueueSize: %s", err)
+		}
+		meta.targetQueueSize = targetQueueSize
+	}
+
+	meta.apiKey = config.AuthParams["apiKey"]
+
+	if val, ok := config.AuthParams["tls"]; ok {
+		val = strings.TrimSpace(val)
+
+		if val == "enable" {
+			certGiven := config.AuthParams["cert"] != ""
+			keyGiven := config.AuthParams["key"] != ""
+			if certGiven && !keyGiven {
+				return nil, errors.New("key must be provided with cert")
+			}
+			if keyGiven && !certGiven {
+				return nil, errors.New("cert must be provided with key")
+			}
+			meta.enableTLS = true
+		} else {
+			return nil, fmt.Errorf("err incorrect value for TLS given: %s", val)
+		}
+	}
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+// getBacklog returns the approximate number of tasks waiting in the task queue, added up over the queue types
+func (s *temporalScaler) getBacklog(ctx context.Context) (int64, error) {
+	if s.metadata.apiKey != "" {
+		ctx = metadata.AppendToOutgoingContext(ctx,
+			"authorization", "Bearer "+s.metadata.apiKey,
+			"temporal-namespace", s.metadata.namespace)
+	}
+
+	var backlog int64
+	for _, queueType := range s.metadata.queueTypes {
+		resp, err := s.client.DescribeTaskQueue(ctx, &temporal.DescribeTaskQueueRequest{
+			Namespace:              s.metadata.namespace,
+			TaskQueue:              s.metadata.taskQueue,
+			TaskQueueType:          queueType,
+			IncludeTaskQueueStatus: true,
+		})
+		if err != nil {
+			return -1, fmt.Errorf("error describing task queue %s: %s", s.metadata.taskQueue, err)
+		}
+		temporalLog.V(1).Info("described task queue", "taskQueue", s.metadata.taskQueue, "queueType", queueType, "backlog", resp.BacklogCountHint, "pollers", len(resp.Pollers))
+		backlog += resp.BacklogCountHint
+	}
+	return backlog, nil
+}
+
+func (s *temporalScaler) IsActive(ctx context.Context) (bool, error) {
+	backlog, err := s.getBacklog(ctx)
+	if err != nil {
+		temporalLog.Error(err, "error getting temporal task queue backlog")
+		return false, err
+	}
+
+	return backlog > 0, nil
+}
+
+func (s *temporalScaler) Close(context.Context) error {
+	if s.connection != nil {
+		return s.connection.Close()
+	}
+	return nil
+}
+
+func (s *temporalScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	targetMetricValue := resource.NewQuantity(s.metadata.targetQueueSize, resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(fmt.Sprintf("temporal-%s-%s", s.metadata.namespace, s.metadata.taskQueue))),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetMetricValue,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+func (s *temporalScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	backlog, err := s.getBacklog(ctx)
+	if err != nil {
+		temporalLog.Error(err, "error getting temporal task queue backlog")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewQuantity(backlog, resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
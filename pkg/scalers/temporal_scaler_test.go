@@ -0,0 +1,171 @@
+package scalers
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/kedacore/keda/v2/pkg/scalers/temporal"
+)
+
+type parseTemporalMetadataTestData struct {
+	metadata   map[string]string
+	authParams map[string]string
+	isError    bool
+}
+
+type temporalMetricIdentifier struct {
+	metadataTestData *parseTemporalMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testTemporalMetadata = []parseTemporalMetadataTestData{
+	// nothing passed
+	{map[string]string{}, map[string]string{}, true},
+	// properly formed
+	{map[string]string{"endpoint": "temporal-frontend:7233", "taskQueue": "orders"}, map[string]string{}, false},
+	// namespace, queue types and target
+	{map[string]string{"endpoint": "temporal-frontend:7233", "namespace": "payments", "taskQueue": "orders", "queueTypes": "activity", "targetQueueSize": "10"}, map[string]string{}, false},
+	// endpoint from auth params
+	{map[string]string{"taskQueue": "orders"}, map[string]string{"endpoint": "payments.a1b2c.tmprl.cloud:7233"}, false},
+	// missing endpoint
+	{map[string]string{"taskQueue": "orders"}, map[string]string{}, true},
+	// missing taskQueue
+	{map[string]string{"endpoint": "temporal-frontend:7233"}, map[string]string{}, true},
+	// wrong queue type
+	{map[string]string{"endpoint": "temporal-frontend:7233", "taskQueue": "orders", "queueTypes": "workflow,nexus"}, map[string]string{}, true},
+	// malformed targetQueueSize
+	{map[string]string{"endpoint": "temporal-frontend:7233", "taskQueue": "orders", "targetQueueSize": "a"}, map[string]string{}, true},
+	// api key with TLS
+	{map[string]string{"endpoint": "temporal-frontend:7233", "taskQueue": "orders"}, map[string]string{"apiKey": "key", "tls": "enable"}, false},
+	// mTLS
+	{map[string]string{"endpoint": "temporal-frontend:7233", "taskQueue": "orders"}, map[string]string{"tls": "enable", "cert": "ceert", "key": "keey"}, false},
+	// mTLS, cert without key
+	{map[string]string{"endpoint": "temporal-frontend:7233", "taskQueue": "orders"}, map[string]string{"tls": "enable", "cert": "ceert"}, true},
+	// wrong tls value
+	{map[string]string{"endpoint": "temporal-frontend:7233", "taskQueue": "orders"}, map[string]string{"tls": "yes"}, true},
+}
+
+var temporalMetricIdentifiers = []temporalMetricIdentifier{
+	{&testTemporalMetadata[1], 0, "s0-temporal-default-orders"},
+	{&testTemporalMetadata[2], 1, "s1-temporal-payments-orders"},
+}
+
+func TestParseTemporalMetadata(t *testing.T) {
+	for _, testData := range testTemporalMetadata {
+		_, err := parseTemporalMetadata(&ScalerConfig{TriggerMetadata: testData.metadata, AuthParams: testData.authParams})
+		if err != nil && !testData.isError {
+			t.Error("Expected success but got error", err)
+		}
+		if testData.isError && err == nil {
+			t.Error("Expected error but got success")
+		}
+	}
+}
+
+func TestTemporalGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range temporalMetricIdentifiers {
+		meta, err := parseTemporalMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, AuthParams: testData.metadataTestData.authParams, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockTemporalScaler := temporalScaler{metadata: meta}
+
+		metricSpec := mockTemporalScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Error("Wrong External metric source name:", metricName)
+		}
+	}
+}
+
+// testTemporalFrontend answers DescribeTaskQueue with a backlog per task queue type to the requests with the api key
+type testTemporalFrontend struct {
+	backlogs map[temporal.TaskQueueType]int64
+	apiKey   string
+}
+
+func (f *testTemporalFrontend) describeTaskQueue(ctx context.Context, req *temporal.DescribeTaskQueueRequest) (*temporal.DescribeTaskQueueResponse, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	if authorization := md.Get("authorization"); len(authorization) != 1 || authorization[0] != "Bearer "+f.apiKey {
+		return nil, status.Error(codes.Unauthenticated, "missing api key")
+	}
+	if req.Namespace != "payments" || req.TaskQueue != "orders" || !req.IncludeTaskQueueStatus {
+		return &temporal.DescribeTaskQueueResponse{}, nil
+	}
+	return &temporal.DescribeTaskQueueResponse{
+		Pollers:          []temporal.PollerInfo{{Identity: "worker-1"}},
+		BacklogCountHint: f.backlogs[req.TaskQueueType],
+	}, nil
+}
+
+func startTestTemporalFrontend(t *testing.T, frontend *testTemporalFrontend) string {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal("Could not listen:", err)
+	}
+
+	server := grpc.NewServer(grpc.ForceServerCodec(temporal.Codec{}))
+	server.RegisterService(&grpc.ServiceDesc{
+		ServiceName: temporal.ServiceName,
+		HandlerType: (*interface{})(nil),
+		Methods: []grpc.MethodDesc{{
+			MethodName: temporal.DescribeTaskQueueMethodName,
+			Handler: func(_ interface{}, ctx context.Context, dec func(interface{}) error, _ grpc.UnaryServerInterceptor) (interface{}, error) {
+				req := new(temporal.DescribeTaskQueueRequest)
+				if err := dec(req); err != nil {
+					return nil, err
+				}
+				return frontend.describeTaskQueue(ctx, req)
+			},
+		}},
+	}, frontend)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	return listener.Addr().String()
+}
+
+func TestTemporalGetMetrics(t *testing.T) {
+	frontend := &testTemporalFrontend{
+		backlogs: map[temporal.TaskQueueType]int64{
+			temporal.TaskQueueTypeWorkflow: 3,
+			temporal.TaskQueueTypeActivity: 12,
+		},
+		apiKey: "key",
+	}
+	endpoint := startTestTemporalFrontend(t, frontend)
+
+	var testData = []struct {
+		queueTypes string
+		expected   int64
+	}{
+		{"", 15},
+		{"activity", 12},
+		{"workflow", 3},
+	}
+	for _, test := range testData {
+		scaler, err := NewTemporalScaler(&ScalerConfig{
+			TriggerMetadata: map[string]string{"endpoint": endpoint, "namespace": "payments", "taskQueue": "orders", "queueTypes": test.queueTypes},
+			AuthParams:      map[string]string{"apiKey": "key"},
+		})
+		if err != nil {
+			t.Fatal("Could not create scaler:", err)
+		}
+
+		metrics, err := scaler.GetMetrics(context.Background(), "MetricName", nil)
+		if err != nil {
+			t.Fatalf("Could not get metrics with queueTypes %q: %s", test.queueTypes, err)
+		}
+		if metrics[0].Value.Value() != test.expected {
+			t.Errorf("Expected backlog %d with queueTypes %q but got %d", test.expected, test.queueTypes, metrics[0].Value.Value())
+		}
+		_ = scaler.Close(context.Background())
+	}
+}
@@ -0,0 +1,261 @@
+package scalers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	dto "github.com/prometheus/client_model/go"
+	v2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+const (
+	// windowsExporterMSMQMetric is the length of a queue of the msmq collector
+	windowsExporterMSMQMetric = "windows_msmq_messages_in_queue"
+	windowsExporterPrefix     = "windows_"
+	// wmiExporterPrefix is the prefix of the metrics of wmi_exporter, the name of windows_exporter before v0.15
+	wmiExporterPrefix = "wmi_"
+)
+
+type windowsExporterScaler struct {
+	metadata   *windowsExporterMetadata
+	httpClient *http.Client
+	rate       counterRate
+}
+
+type windowsExporterMetadata struct {
+	// urls are the metrics endpoints of the exporters of the nodes, the matching series are added up
+	urls       []string
+	metricName string
+	labels     map[string]string
+	// rate scales on the per second rate of a counter, like a "/sec" performance counter, instead of its value
+	rate                  bool
+	targetValue           float64
+	activationTargetValue float64
+
+	// auth
+	bearerToken string
+
+	scalerIndex int
+}
+
+var windowsExporterMetricNameReplacer = regexp.MustCompile(`[^a-zA-Z0-9_]+`)
+
+var windowsExporterLog = logf.Log.WithName("windows_exporter_scaler")
+
+// NewWindowsExporterScaler creates a new windowsExporterScaler
+func NewWindowsExporterScaler(config *ScalerConfig) (Scaler, error) {
+	meta, err := parseWindowsExporterMetadata(config)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing windows exporter metadata: %s", err)
+	}
+
+	httpClient, err := createHTTPClient(config, config.GlobalHTTPTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	return &windowsExporterScaler{
+		metadata:   meta,
+		httpClient: httpClient,
+	}, nil
+}
+
+func parseWindowsExporterMetadata(config *ScalerConfig) (*windowsExporterMetadata, error) {
+	meta := windowsExporterMetadata{
+		labels: map[string]string{},
+	}
+
+	// url is the metrics endpoint of windows_exporter, eg. http://windows-node:9182/metrics
+	if val, ok := config.TriggerMetadata["url"]; ok && val != "" {
+		for _, exporterURL := range splitAndTrimBySep(val, ",") {
+			if _, err := url.ParseRequestURI(exporterURL); err != nil {
+				return nil, fmt.Errorf("error parsing url: %s", err)
+			}
+			meta.urls = append(meta.urls, exporterURL)
+		}
+	} else {
+		return nil, fmt.Errorf("no url given")
+	}
+
+	// labels selects the series of the metric, eg. "name=private$\orders"
+	if val, ok := config.TriggerMetadata["labels"]; ok && val != "" {
+		for _, pair := range strings.Split(val, ",") {
+			parts := strings.SplitN(pair, "=", 2)
+			if len(parts) != 2 || strings.TrimSpace(parts[0]) == "" {
+				return nil, fmt.Errorf("labels must be in the format name=value,name=value")
+			}
+			meta.labels[strings.TrimSpace(parts[0])] = strings.TrimSpace(parts[1])
+		}
+	}
+
+	meta.metricName = config.TriggerMetadata["metricName"]
+	// msmqQueue is the name of a queue of the msmq collector, a shortcut of its metricName and labels
+	if val, ok := config.TriggerMetadata["msmqQueue"]; ok && val != "" {
+		if meta.metricName != "" {
+			return nil, fmt.Errorf("either metricName or msmqQueue can be given")
+		}
+		meta.metricName = windowsExporterMSMQMetric
+		meta.labels["name"] = val
+	}
+	if meta.metricName == "" {
+		return nil, fmt.Errorf("no metricName or msmqQueue given")
+	}
+
+	if val, ok := config.TriggerMetadata["rate"]; ok && val != "" {
+		rate, err := strconv.ParseBool(val)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing rate: %s", err)
+		}
+		meta.rate = rate
+	}
+
+	if val, ok := config.TriggerMetadata["targetValue"]; ok && val != "" {
+		targetValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing targetValue: %s", err)
+		}
+		meta.targetValue = targetValue
+	} else {
+		return nil, fmt.Errorf("no targetValue given")
+	}
+
+	if val, ok := config.TriggerMetadata["activationTargetValue"]; ok && val != "" {
+		activationTargetValue, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing activationTargetValue: %s", err)
+		}
+		meta.activationTargetValue = activationTargetValue
+	}
+
+	meta.bearerToken = config.AuthParams["bearerToken"]
+
+	meta.scalerIndex = config.ScalerIndex
+
+	return &meta, nil
+}
+
+// IsActive returns true if the value is above the activation target
+func (s *windowsExporterScaler) IsActive(ctx context.Context) (bool, error) {
+	val, err := s.getValue(ctx)
+	if err != nil {
+		windowsExporterLog.Error(err, "error scraping windows exporter")
+		return false, err
+	}
+
+	return val > s.metadata.activationTargetValue, nil
+}
+
+func (s *windowsExporterScaler) Close(context.Context) error {
+	return nil
+}
+
+func (s *windowsExporterScaler) GetMetricSpecForScaling(context.Context) []v2beta2.MetricSpec {
+	name := fmt.Sprintf("windows-exporter-%s", strings.TrimPrefix(s.metadata.metricName, windowsExporterPrefix))
+	if queue, ok := s.metadata.labels["name"]; ok && s.metadata.metricName == windowsExporterMSMQMetric {
+		// queue names contain characters not allowed in metric names, like $ and \
+		name = fmt.Sprintf("windows-exporter-msmq-%s", strings.Trim(windowsExporterMetricNameReplacer.ReplaceAllString(queue, "-"), "-"))
+	}
+	targetMetricValue := resource.NewMilliQuantity(int64(s.metadata.targetValue*1000), resource.DecimalSI)
+	externalMetric := &v2beta2.ExternalMetricSource{
+		Metric: v2beta2.MetricIdentifier{
+			Name: GenerateMetricNameWithIndex(s.metadata.scalerIndex, kedautil.NormalizeString(name)),
+		},
+		Target: v2beta2.MetricTarget{
+			Type:         v2beta2.AverageValueMetricType,
+			AverageValue: targetMetricValue,
+		},
+	}
+	metricSpec := v2beta2.MetricSpec{
+		External: externalMetric, Type: externalMetricType,
+	}
+	return []v2beta2.MetricSpec{metricSpec}
+}
+
+func (s *windowsExporterScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	val, err := s.getValue(ctx)
+	if err != nil {
+		windowsExporterLog.Error(err, "error scraping windows exporter")
+		return []external_metrics.ExternalMetricValue{}, err
+	}
+
+	metric := external_metrics.ExternalMetricValue{
+		MetricName: metricName,
+		Value:      *resource.NewMilliQuantity(int64(val*1000), resource.DecimalSI),
+		Timestamp:  metav1.Now(),
+	}
+
+	return append([]external_metrics.ExternalMetricValue{}, metric), nil
+}
+
+// getValue returns the sum of the matching series of the exporters, or its per second rate with rate
+func (s *windowsExporterScaler) getValue(ctx context.Context) (float64, error) {
+	var total float64
+	for _, exporterURL := range s.metadata.urls {
+		val, err := s.scrape(ctx, exporterURL)
+		if err != nil {
+			return -1, err
+		}
+		total += val
+	}
+	if !s.metadata.rate {
+		return total, nil
+	}
+	return s.rate.update(total, time.Now()), nil
+}
+
+// scrape returns the sum of the series of the metric matching the labels on an exporter
+func (s *windowsExporterScaler) scrape(ctx context.Context, exporterURL string) (float64, error) {
+	families, err := scrapeMetricFamilies(ctx, s.httpClient, exporterURL, s.metadata.bearerToken)
+	if err != nil {
+		return -1, fmt.Errorf("error scraping windows exporter metrics: %s", err)
+	}
+
+	family, ok := families[s.metadata.metricName]
+	if !ok && strings.HasPrefix(s.metadata.metricName, windowsExporterPrefix) {
+		family, ok = families[wmiExporterPrefix+strings.TrimPrefix(s.metadata.metricName, windowsExporterPrefix)]
+	}
+	if !ok {
+		return -1, fmt.Errorf("metric %s not found on %s, is its collector enabled?", s.metadata.metricName, exporterURL)
+	}
+
+	var sum float64
+	for _, metric := range family.GetMetric() {
+		if !windowsExporterLabelsMatch(metric, s.metadata.labels) {
+			continue
+		}
+		value, ok := metricValue(metric)
+		if !ok {
+			return -1, fmt.Errorf("metric %s is not a gauge, counter or untyped metric", s.metadata.metricName)
+		}
+		sum += value
+	}
+	return sum, nil
+}
+
+// windowsExporterLabelsMatch compares the label values case-insensitively, Windows names of queues
+// and instances are case-insensitive
+func windowsExporterLabelsMatch(metric *dto.Metric, selector map[string]string) bool {
+	matched := 0
+	for _, label := range metric.GetLabel() {
+		if value, ok := selector[label.GetName()]; ok {
+			if !strings.EqualFold(value, label.GetValue()) {
+				return false
+			}
+			matched++
+		}
+	}
+	return matched == len(selector)
+}
@@ -0,0 +1,133 @@
+package scalers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type parseWindowsExporterMetadataTestData struct {
+	metadata map[string]string
+	isError  bool
+}
+
+type windowsExporterMetricIdentifier struct {
+	metadataTestData *parseWindowsExporterMetadataTestData
+	scalerIndex      int
+	name             string
+}
+
+var testWindowsExporterMetadata = []parseWindowsExporterMetadataTestData{
+	// nothing passed
+	{map[string]string{}, true},
+	// msmq queue
+	{map[string]string{"url": "http://windows-node:9182/metrics", "msmqQueue": `private$\orders`, "targetValue": "100"}, false},
+	// counter rate on two nodes
+	{map[string]string{"url": "http://10.0.0.1:9182/metrics, http://10.0.0.2:9182/metrics", "metricName": "windows_iis_requests_total", "labels": "site=Default Web Site", "rate": "true", "targetValue": "50", "activationTargetValue": "1"}, false},
+	// missing url
+	{map[string]string{"msmqQueue": "orders", "targetValue": "100"}, true},
+	// invalid url
+	{map[string]string{"url": "windows-node", "msmqQueue": "orders", "targetValue": "100"}, true},
+	// missing metricName and msmqQueue
+	{map[string]string{"url": "http://windows-node:9182/metrics", "targetValue": "100"}, true},
+	// metricName and msmqQueue
+	{map[string]string{"url": "http://windows-node:9182/metrics", "metricName": "windows_cpu_time_total", "msmqQueue": "orders", "targetValue": "100"}, true},
+	// invalid labels
+	{map[string]string{"url": "http://windows-node:9182/metrics", "metricName": "windows_cpu_time_total", "labels": "core", "targetValue": "100"}, true},
+	// invalid rate
+	{map[string]string{"url": "http://windows-node:9182/metrics", "msmqQueue": "orders", "rate": "a", "targetValue": "100"}, true},
+	// missing targetValue
+	{map[string]string{"url": "http://windows-node:9182/metrics", "msmqQueue": "orders"}, true},
+	// invalid activationTargetValue
+	{map[string]string{"url": "http://windows-node:9182/metrics", "msmqQueue": "orders", "targetValue": "100", "activationTargetValue": "a"}, true},
+}
+
+var windowsExporterMetricIdentifiers = []windowsExporterMetricIdentifier{
+	{&testWindowsExporterMetadata[1], 0, "s0-windows-exporter-msmq-private-orders"},
+	{&testWindowsExporterMetadata[2], 1, "s1-windows-exporter-iis_requests_total"},
+}
+
+func TestParseWindowsExporterMetadata(t *testing.T) {
+	for _, testData := range testWindowsExporterMetadata {
+		_, err := parseWindowsExporterMetadata(&ScalerConfig{TriggerMetadata: testData.metadata})
+		if err != nil && !testData.isError {
+			t.Error("Expected success but got error", err)
+		}
+		if testData.isError && err == nil {
+			t.Error("Expected error but got success", testData.metadata)
+		}
+	}
+}
+
+func TestWindowsExporterGetMetricSpecForScaling(t *testing.T) {
+	for _, testData := range windowsExporterMetricIdentifiers {
+		meta, err := parseWindowsExporterMetadata(&ScalerConfig{TriggerMetadata: testData.metadataTestData.metadata, ScalerIndex: testData.scalerIndex})
+		if err != nil {
+			t.Fatal("Could not parse metadata:", err)
+		}
+		mockWindowsExporterScaler := windowsExporterScaler{metadata: meta}
+
+		metricSpec := mockWindowsExporterScaler.GetMetricSpecForScaling(context.Background())
+		metricName := metricSpec[0].External.Metric.Name
+		if metricName != testData.name {
+			t.Error("Wrong External metric source name:", metricName)
+		}
+	}
+}
+
+const testWindowsExporterMetrics = `# HELP windows_msmq_messages_in_queue Count messages in queue.
+# TYPE windows_msmq_messages_in_queue gauge
+windows_msmq_messages_in_queue{name="private$\\orders"} 42
+windows_msmq_messages_in_queue{name="private$\\invoices"} 7
+# HELP windows_iis_requests_total Number of HTTP requests (WebService.TotalRequests)
+# TYPE windows_iis_requests_total counter
+windows_iis_requests_total{method="GET",site="Default Web Site"} 1200
+windows_iis_requests_total{method="POST",site="Default Web Site"} 300
+windows_iis_requests_total{method="GET",site="api"} 50
+`
+
+const testWMIExporterMetrics = `# HELP wmi_msmq_messages_in_queue Count messages in queue.
+# TYPE wmi_msmq_messages_in_queue gauge
+wmi_msmq_messages_in_queue{name="private$\\orders"} 3
+`
+
+func TestWindowsExporterScrape(t *testing.T) {
+	var tests = []struct {
+		name     string
+		body     string
+		metadata map[string]string
+		expected float64
+		isError  bool
+	}{
+		{"msmq queue", testWindowsExporterMetrics, map[string]string{"msmqQueue": `Private$\Orders`}, 42, false},
+		{"all msmq queues", testWindowsExporterMetrics, map[string]string{"metricName": "windows_msmq_messages_in_queue"}, 49, false},
+		{"series of a site", testWindowsExporterMetrics, map[string]string{"metricName": "windows_iis_requests_total", "labels": "site=Default Web Site"}, 1500, false},
+		{"wmi_exporter", testWMIExporterMetrics, map[string]string{"msmqQueue": `private$\orders`}, 3, false},
+		{"no matching series", testWindowsExporterMetrics, map[string]string{"msmqQueue": `private$\payments`}, 0, false},
+		{"collector disabled", testWindowsExporterMetrics, map[string]string{"metricName": "windows_mssql_up"}, 0, true},
+	}
+
+	for _, test := range tests {
+		body := test.body
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(body))
+		}))
+		test.metadata["url"] = server.URL
+		test.metadata["targetValue"] = "10"
+		meta, err := parseWindowsExporterMetadata(&ScalerConfig{TriggerMetadata: test.metadata})
+		assert.NoError(t, err, test.name)
+		scaler := windowsExporterScaler{metadata: meta, httpClient: http.DefaultClient}
+
+		val, err := scaler.getValue(context.Background())
+		server.Close()
+		if test.isError {
+			assert.Error(t, err, test.name)
+			continue
+		}
+		assert.NoError(t, err, test.name)
+		assert.Equal(t, test.expected, val, test.name)
+	}
+}
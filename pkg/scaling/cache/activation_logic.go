@@ -0,0 +1,252 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+// activationExpression is a node of a parsed activationExpression
+type activationExpression interface {
+	eval(triggersActive map[string]bool) bool
+}
+
+type triggerRef string
+
+func (t triggerRef) eval(triggersActive map[string]bool) bool {
+	return triggersActive[string(t)]
+}
+
+type notExpression struct {
+	operand activationExpression
+}
+
+func (e notExpression) eval(triggersActive map[string]bool) bool {
+	return !e.operand.eval(triggersActive)
+}
+
+type andExpression struct {
+	left, right activationExpression
+}
+
+func (e andExpression) eval(triggersActive map[string]bool) bool {
+	return e.left.eval(triggersActive) && e.right.eval(triggersActive)
+}
+
+type orExpression struct {
+	left, right activationExpression
+}
+
+func (e orExpression) eval(triggersActive map[string]bool) bool {
+	return e.left.eval(triggersActive) || e.right.eval(triggersActive)
+}
+
+// ValidateActivationLogic checks the activationLogic of the ScaledObject, for expression
+// the activationExpression has to be valid and reference only named triggers of the ScaledObject
+func ValidateActivationLogic(scaledObject *kedav1alpha1.ScaledObject) error {
+	if scaledObject.Spec.Advanced == nil {
+		return nil
+	}
+
+	switch scaledObject.Spec.Advanced.ActivationLogic {
+	case "", kedav1alpha1.ActivationLogicAnyOf, kedav1alpha1.ActivationLogicAllOf:
+		if scaledObject.Spec.Advanced.ActivationExpression != "" {
+			return fmt.Errorf("activationExpression can only be used with activationLogic %s", kedav1alpha1.ActivationLogicExpression)
+		}
+		return nil
+	case kedav1alpha1.ActivationLogicExpression:
+	default:
+		return fmt.Errorf("unknown activationLogic %s", scaledObject.Spec.Advanced.ActivationLogic)
+	}
+
+	names := make(map[string]bool, len(scaledObject.Spec.Triggers))
+	for _, trigger := range scaledObject.Spec.Triggers {
+		if trigger.Name != "" {
+			names[trigger.Name] = true
+		}
+	}
+
+	tokens, err := tokenizeActivationExpression(scaledObject.Spec.Advanced.ActivationExpression)
+	if err != nil {
+		return err
+	}
+	if _, err := parseActivationExpression(tokens); err != nil {
+		return err
+	}
+	for _, token := range tokens {
+		if !isActivationOperator(token) && !names[token] {
+			return fmt.Errorf("activationExpression references unknown trigger %s", token)
+		}
+	}
+	return nil
+}
+
+func tokenizeActivationExpression(expression string) ([]string, error) {
+	var tokens []string
+	runes := []rune(expression)
+	for i := 0; i < len(runes); {
+		switch r := runes[i]; {
+		case unicode.IsSpace(r):
+			i++
+		case r == '(' || r == ')' || r == '!':
+			tokens = append(tokens, string(r))
+			i++
+		case r == '&' || r == '|':
+			if i+1 >= len(runes) || runes[i+1] != r {
+				return nil, fmt.Errorf("invalid operator %c at position %d in activationExpression", r, i)
+			}
+			tokens = append(tokens, string(runes[i:i+2]))
+			i += 2
+		case unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '.':
+			start := i
+			for i < len(runes) && (unicode.IsLetter(runes[i]) || unicode.IsDigit(runes[i]) || runes[i] == '-' || runes[i] == '_' || runes[i] == '.') {
+				i++
+			}
+			token := string(runes[start:i])
+			// keywords are accepted in any case and normalized to the symbolic operators
+			switch strings.ToUpper(token) {
+			case "AND":
+				token = "&&"
+			case "OR":
+				token = "||"
+			case "NOT":
+				token = "!"
+			}
+			tokens = append(tokens, token)
+		default:
+			return nil, fmt.Errorf("invalid character %c at position %d in activationExpression", r, i)
+		}
+	}
+	if len(tokens) == 0 {
+		return nil, fmt.Errorf("activationExpression is empty")
+	}
+	return tokens, nil
+}
+
+func isActivationOperator(token string) bool {
+	switch token {
+	case "&&", "||", "!", "(", ")":
+		return true
+	}
+	return false
+}
+
+// parseActivationExpression is a recursive descent parser, NOT binds tighter than AND, which binds tighter than OR
+func parseActivationExpression(tokens []string) (activationExpression, error) {
+	p := &activationExpressionParser{tokens: tokens}
+	expression, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("unexpected %s in activationExpression", p.tokens[p.pos])
+	}
+	return expression, nil
+}
+
+type activationExpressionParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *activationExpressionParser) next() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *activationExpressionParser) parseOr() (activationExpression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.next() == "||" {
+		p.pos++
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = orExpression{left, right}
+	}
+	return left, nil
+}
+
+func (p *activationExpressionParser) parseAnd() (activationExpression, error) {
+	left, err := p.parseNot()
+	if err != nil {
+		return nil, err
+	}
+	for p.next() == "&&" {
+		p.pos++
+		right, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		left = andExpression{left, right}
+	}
+	return left, nil
+}
+
+func (p *activationExpressionParser) parseNot() (activationExpression, error) {
+	token := p.next()
+	switch {
+	case token == "":
+		return nil, fmt.Errorf("unexpected end of activationExpression")
+	case token == "!":
+		p.pos++
+		operand, err := p.parseNot()
+		if err != nil {
+			return nil, err
+		}
+		return notExpression{operand}, nil
+	case token == "(":
+		p.pos++
+		expression, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.next() != ")" {
+			return nil, fmt.Errorf("missing ) in activationExpression")
+		}
+		p.pos++
+		return expression, nil
+	case isActivationOperator(token):
+		return nil, fmt.Errorf("unexpected %s in activationExpression", token)
+	default:
+		p.pos++
+		return triggerRef(token), nil
+	}
+}
+
+// evaluateActivationExpression evaluates the expression for the activity of the named triggers,
+// triggers that are missing from triggersActive (eg. failed scalers) are evaluated as not active
+func evaluateActivationExpression(expression string, triggersActive map[string]bool) (bool, error) {
+	tokens, err := tokenizeActivationExpression(expression)
+	if err != nil {
+		return false, err
+	}
+	parsed, err := parseActivationExpression(tokens)
+	if err != nil {
+		return false, err
+	}
+	return parsed.eval(triggersActive), nil
+}
@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/client-go/tools/record"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	mock_scalers "github.com/kedacore/keda/v2/pkg/mock/mock_scaler"
+	"github.com/kedacore/keda/v2/pkg/scalers"
+)
+
+func TestEvaluateActivationExpression(t *testing.T) {
+	triggersActive := map[string]bool{"queue": true, "business-hours": false, "weekend": true}
+	tests := []struct {
+		expression string
+		expected   bool
+		isError    bool
+	}{
+		{"queue", true, false},
+		{"queue AND business-hours", false, false},
+		{"queue && !business-hours", true, false},
+		{"queue and (business-hours or weekend)", true, false},
+		{"NOT queue OR business-hours", false, false},
+		{"queue AND NOT (business-hours OR weekend)", false, false},
+		{"missing || queue", true, false},
+		{"missing", false, false},
+		{"", false, true},
+		{"queue AND", false, true},
+		{"(queue", false, true},
+		{"queue business-hours", false, true},
+		{"queue & weekend", false, true},
+		{"queue == weekend", false, true},
+	}
+	for _, test := range tests {
+		got, err := evaluateActivationExpression(test.expression, triggersActive)
+		if test.isError {
+			assert.Error(t, err, test.expression)
+			continue
+		}
+		assert.NoError(t, err, test.expression)
+		assert.Equal(t, test.expected, got, test.expression)
+	}
+}
+
+func TestValidateActivationLogic(t *testing.T) {
+	newScaledObject := func(advanced *kedav1alpha1.AdvancedConfig) *kedav1alpha1.ScaledObject {
+		return &kedav1alpha1.ScaledObject{
+			Spec: kedav1alpha1.ScaledObjectSpec{
+				Advanced: advanced,
+				Triggers: []kedav1alpha1.ScaleTriggers{{Name: "queue"}, {Name: "business-hours"}, {}},
+			},
+		}
+	}
+	assert.NoError(t, ValidateActivationLogic(newScaledObject(nil)))
+	assert.NoError(t, ValidateActivationLogic(newScaledObject(&kedav1alpha1.AdvancedConfig{ActivationLogic: kedav1alpha1.ActivationLogicAllOf})))
+	assert.NoError(t, ValidateActivationLogic(newScaledObject(&kedav1alpha1.AdvancedConfig{ActivationLogic: kedav1alpha1.ActivationLogicExpression, ActivationExpression: "queue AND business-hours"})))
+	assert.Error(t, ValidateActivationLogic(newScaledObject(&kedav1alpha1.AdvancedConfig{ActivationLogic: "oneOf"})))
+	assert.Error(t, ValidateActivationLogic(newScaledObject(&kedav1alpha1.AdvancedConfig{ActivationExpression: "queue"})))
+	assert.Error(t, ValidateActivationLogic(newScaledObject(&kedav1alpha1.AdvancedConfig{ActivationLogic: kedav1alpha1.ActivationLogicExpression})))
+	assert.Error(t, ValidateActivationLogic(newScaledObject(&kedav1alpha1.AdvancedConfig{ActivationLogic: kedav1alpha1.ActivationLogicExpression, ActivationExpression: "queue AND cron"})))
+}
+
+func TestIsScaledObjectActiveWithActivationLogic(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	createActivationScaler := func(isActive bool) *mock_scalers.MockScaler {
+		scaler := mock_scalers.NewMockScaler(ctrl)
+		scaler.EXPECT().IsActive(gomock.Any()).Return(isActive, nil)
+		scaler.EXPECT().GetMetricSpecForScaling(gomock.Any()).Return([]v2beta2.MetricSpec{createMetricSpec(1)}).AnyTimes()
+		return scaler
+	}
+
+	tests := []struct {
+		name        string
+		advanced    *kedav1alpha1.AdvancedConfig
+		queueActive bool
+		cronActive  bool
+		expected    bool
+	}{
+		{"allOf with all active", &kedav1alpha1.AdvancedConfig{ActivationLogic: kedav1alpha1.ActivationLogicAllOf}, true, true, true},
+		{"allOf with one active", &kedav1alpha1.AdvancedConfig{ActivationLogic: kedav1alpha1.ActivationLogicAllOf}, true, false, false},
+		{"expression true", &kedav1alpha1.AdvancedConfig{ActivationLogic: kedav1alpha1.ActivationLogicExpression, ActivationExpression: "queue AND NOT cron"}, true, false, true},
+		{"expression false", &kedav1alpha1.AdvancedConfig{ActivationLogic: kedav1alpha1.ActivationLogicExpression, ActivationExpression: "queue AND cron"}, false, true, false},
+	}
+	for _, test := range tests {
+		scaledObject := &kedav1alpha1.ScaledObject{
+			Spec: kedav1alpha1.ScaledObjectSpec{
+				Advanced: test.advanced,
+				Triggers: []kedav1alpha1.ScaleTriggers{{Name: "queue"}, {Name: "cron"}},
+			},
+		}
+		cache := ScalersCache{
+			Scalers: []ScalerBuilder{
+				{Scaler: createActivationScaler(test.queueActive), TriggerName: "queue"},
+				{Scaler: createActivationScaler(test.cronActive), TriggerName: "cron"},
+			},
+			Logger:   logr.DiscardLogger{},
+			Recorder: record.NewFakeRecorder(1),
+		}
+
+		isActive, isError, _ := cache.IsScaledObjectActive(context.Background(), scaledObject)
+		assert.False(t, isError, test.name)
+		assert.Equal(t, test.expected, isActive, test.name)
+	}
+}
+
+func TestRefreshScalerKeepsTriggerName(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	refreshed := mock_scalers.NewMockScaler(ctrl)
+	failing := mock_scalers.NewMockScaler(ctrl)
+	failing.EXPECT().Close(gomock.Any()).Return(nil)
+
+	cache := ScalersCache{
+		Scalers: []ScalerBuilder{{
+			Scaler:      failing,
+			Factory:     func() (scalers.Scaler, error) { return refreshed, nil },
+			TriggerName: "queue",
+		}},
+		Logger:   logr.DiscardLogger{},
+		Recorder: record.NewFakeRecorder(1),
+	}
+
+	_, err := cache.refreshScaler(context.Background(), 0)
+	assert.NoError(t, err)
+	assert.Equal(t, "queue", cache.Scalers[0].TriggerName)
+}
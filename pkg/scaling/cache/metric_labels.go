@@ -0,0 +1,50 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+)
+
+const (
+	// TriggerTypeLabel is the label of the metric values with the type of the trigger they were got for
+	TriggerTypeLabel = "trigger.keda.sh/type"
+	// TriggerNameLabel is the label of the metric values with the name of the trigger they were got for
+	TriggerNameLabel = "trigger.keda.sh/name"
+)
+
+// selectMetrics labels the metric values of the scaler with its trigger and returns those matching the selector,
+// labels set by the scaler itself take precedence so scalers returning several values can label each of them
+func selectMetrics(s ScalerBuilder, metrics []external_metrics.ExternalMetricValue, metricSelector labels.Selector) []external_metrics.ExternalMetricValue {
+	selected := make([]external_metrics.ExternalMetricValue, 0, len(metrics))
+	for _, metric := range metrics {
+		metricLabels := map[string]string{TriggerTypeLabel: s.TriggerType}
+		if s.TriggerName != "" {
+			metricLabels[TriggerNameLabel] = s.TriggerName
+		}
+		for k, v := range metric.MetricLabels {
+			metricLabels[k] = v
+		}
+		metric.MetricLabels = metricLabels
+
+		if metricSelector == nil || metricSelector.Matches(labels.Set(metricLabels)) {
+			selected = append(selected, metric)
+		}
+	}
+	return selected
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"fmt"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+// isResourceTrigger returns true for the cpu and memory triggers, their metrics come from the Kubernetes
+// Metrics Server and only exist while the ScaleTarget has replicas
+func isResourceTrigger(triggerType string) bool {
+	return triggerType == "cpu" || triggerType == "memory"
+}
+
+// hasOnlyResourceTriggers returns true if all triggers of the ScaledObject are cpu or memory triggers
+func hasOnlyResourceTriggers(scaledObject *kedav1alpha1.ScaledObject) bool {
+	for _, trigger := range scaledObject.Spec.Triggers {
+		if !isResourceTrigger(trigger.Type) {
+			return false
+		}
+	}
+	return true
+}
+
+// hasResourceActivation returns true for the cpu and memory triggers with an activationValue, they are active while
+// the usage of the ScaleTarget is above it and take part in the activation of a ScaledObject with other triggers
+func hasResourceActivation(triggerType string, metadata map[string]string) bool {
+	return isResourceTrigger(triggerType) && metadata["activationValue"] != ""
+}
+
+// isActivationTrigger returns true if the trigger takes part in the activation of the ScaledObject, the cpu and
+// memory triggers without activationValue are left out unless the ScaledObject has no other triggers
+func isActivationTrigger(scaledObject *kedav1alpha1.ScaledObject, triggerType string, metadata map[string]string) bool {
+	return !isResourceTrigger(triggerType) || hasResourceActivation(triggerType, metadata) || hasOnlyResourceTriggers(scaledObject)
+}
+
+// countActivationTriggers returns the number of triggers taking part in the activation of the ScaledObject
+func countActivationTriggers(scaledObject *kedav1alpha1.ScaledObject) int {
+	count := 0
+	for _, trigger := range scaledObject.Spec.Triggers {
+		if isActivationTrigger(scaledObject, trigger.Type, trigger.Metadata) {
+			count++
+		}
+	}
+	return count
+}
+
+// ValidateResourceTriggers checks that the activationExpression of the ScaledObject doesn't reference cpu or memory
+// triggers without activationValue, they are always active while the ScaleTarget has replicas and don't take part
+// in the activation of a ScaledObject with other triggers
+func ValidateResourceTriggers(scaledObject *kedav1alpha1.ScaledObject) error {
+	if len(scaledObject.Spec.Triggers) == 0 || hasOnlyResourceTriggers(scaledObject) {
+		return nil
+	}
+
+	if scaledObject.Spec.Advanced == nil || scaledObject.Spec.Advanced.ActivationLogic != kedav1alpha1.ActivationLogicExpression {
+		return nil
+	}
+	tokens, err := tokenizeActivationExpression(scaledObject.Spec.Advanced.ActivationExpression)
+	if err != nil {
+		return err
+	}
+	for _, trigger := range scaledObject.Spec.Triggers {
+		if trigger.Name == "" || isActivationTrigger(scaledObject, trigger.Type, trigger.Metadata) {
+			continue
+		}
+		for _, token := range tokens {
+			if token == trigger.Name {
+				return fmt.Errorf("activationExpression can't reference the %s trigger %s without activationValue, cpu and memory triggers without activationValue don't take part in the activation", trigger.Type, trigger.Name)
+			}
+		}
+	}
+	return nil
+}
+
+// ResourceTriggersWarning returns a warning if the ScaledObject has only cpu and memory triggers, one of them with an
+// activationValue, and can scale to zero: the usage of pods can't be measured without replicas, so the ScaleTarget
+// would stay at zero replicas once the usage dropped below the activationValue
+func ResourceTriggersWarning(scaledObject *kedav1alpha1.ScaledObject) string {
+	if len(scaledObject.Spec.Triggers) == 0 || !hasOnlyResourceTriggers(scaledObject) {
+		return ""
+	}
+	if scaledObject.Spec.MinReplicaCount != nil && *scaledObject.Spec.MinReplicaCount > 0 {
+		return ""
+	}
+	for _, trigger := range scaledObject.Spec.Triggers {
+		if hasResourceActivation(trigger.Type, trigger.Metadata) {
+			return "ScaledObject has only cpu or memory triggers and a minReplicaCount of 0, the ScaleTarget can't be activated again once it's scaled to zero"
+		}
+	}
+	return ""
+}
@@ -0,0 +1,113 @@
+package cache
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/client-go/tools/record"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	mock_scalers "github.com/kedacore/keda/v2/pkg/mock/mock_scaler"
+)
+
+func TestValidateResourceTriggers(t *testing.T) {
+	zero := int32(0)
+	newScaledObject := func(minReplicaCount *int32, advanced *kedav1alpha1.AdvancedConfig, triggers ...kedav1alpha1.ScaleTriggers) *kedav1alpha1.ScaledObject {
+		return &kedav1alpha1.ScaledObject{
+			Spec: kedav1alpha1.ScaledObjectSpec{
+				MinReplicaCount: minReplicaCount,
+				Advanced:        advanced,
+				Triggers:        triggers,
+			},
+		}
+	}
+	cpu := kedav1alpha1.ScaleTriggers{Name: "cpu", Type: "cpu"}
+	cpuActivation := kedav1alpha1.ScaleTriggers{Name: "cpu", Type: "cpu", Metadata: map[string]string{"activationValue": "20"}}
+	queue := kedav1alpha1.ScaleTriggers{Name: "queue", Type: "rabbitmq"}
+	expression := func(activationExpression string) *kedav1alpha1.AdvancedConfig {
+		return &kedav1alpha1.AdvancedConfig{ActivationLogic: kedav1alpha1.ActivationLogicExpression, ActivationExpression: activationExpression}
+	}
+
+	assert.NoError(t, ValidateResourceTriggers(newScaledObject(&zero, nil, cpu)))
+	assert.NoError(t, ValidateResourceTriggers(newScaledObject(&zero, nil, cpu, queue)))
+	assert.NoError(t, ValidateResourceTriggers(newScaledObject(&zero, expression("queue"), cpu, queue)))
+	assert.Error(t, ValidateResourceTriggers(newScaledObject(&zero, expression("queue OR cpu"), cpu, queue)))
+	assert.NoError(t, ValidateResourceTriggers(newScaledObject(&zero, expression("queue OR cpu"), cpuActivation, queue)))
+}
+
+func TestResourceTriggersWarning(t *testing.T) {
+	zero, one := int32(0), int32(1)
+	newScaledObject := func(minReplicaCount *int32, triggers ...kedav1alpha1.ScaleTriggers) *kedav1alpha1.ScaledObject {
+		return &kedav1alpha1.ScaledObject{
+			Spec: kedav1alpha1.ScaledObjectSpec{
+				MinReplicaCount: minReplicaCount,
+				Triggers:        triggers,
+			},
+		}
+	}
+	cpu := kedav1alpha1.ScaleTriggers{Name: "cpu", Type: "cpu"}
+	cpuActivation := kedav1alpha1.ScaleTriggers{Name: "cpu", Type: "cpu", Metadata: map[string]string{"activationValue": "20"}}
+	queue := kedav1alpha1.ScaleTriggers{Name: "queue", Type: "rabbitmq"}
+
+	assert.Empty(t, ResourceTriggersWarning(newScaledObject(&zero, cpu)))
+	assert.Empty(t, ResourceTriggersWarning(newScaledObject(&one, cpuActivation)))
+	assert.Empty(t, ResourceTriggersWarning(newScaledObject(&zero, cpuActivation, queue)))
+	assert.NotEmpty(t, ResourceTriggersWarning(newScaledObject(&zero, cpuActivation)))
+	assert.NotEmpty(t, ResourceTriggersWarning(newScaledObject(nil, cpuActivation)))
+}
+
+func TestIsScaledObjectActiveWithResourceTriggers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	createActivationScaler := func(isActive bool) *mock_scalers.MockScaler {
+		scaler := mock_scalers.NewMockScaler(ctrl)
+		scaler.EXPECT().IsActive(gomock.Any()).Return(isActive, nil).AnyTimes()
+		scaler.EXPECT().GetMetricSpecForScaling(gomock.Any()).Return([]v2beta2.MetricSpec{createMetricSpec(1)}).AnyTimes()
+		return scaler
+	}
+
+	activation := map[string]string{"activationValue": "20"}
+	tests := []struct {
+		name        string
+		advanced    *kedav1alpha1.AdvancedConfig
+		cpuMetadata map[string]string
+		cpuActive   bool
+		withQueue   bool
+		queueActive bool
+		expected    bool
+	}{
+		{"only cpu", nil, nil, true, false, false, true},
+		{"cpu with inactive queue", nil, nil, true, true, false, false},
+		{"cpu with active queue", nil, nil, true, true, true, true},
+		{"allOf cpu with active queue", &kedav1alpha1.AdvancedConfig{ActivationLogic: kedav1alpha1.ActivationLogicAllOf}, nil, true, true, true, true},
+		{"allOf only cpu", &kedav1alpha1.AdvancedConfig{ActivationLogic: kedav1alpha1.ActivationLogicAllOf}, nil, true, false, false, true},
+		{"cpu above activationValue with inactive queue", nil, activation, true, true, false, true},
+		{"cpu below activationValue with inactive queue", nil, activation, false, true, false, false},
+		{"allOf cpu below activationValue with active queue", &kedav1alpha1.AdvancedConfig{ActivationLogic: kedav1alpha1.ActivationLogicAllOf}, activation, false, true, true, false},
+	}
+	for _, test := range tests {
+		scaledObject := &kedav1alpha1.ScaledObject{
+			Spec: kedav1alpha1.ScaledObjectSpec{
+				Advanced: test.advanced,
+				Triggers: []kedav1alpha1.ScaleTriggers{{Name: "cpu", Type: "cpu", Metadata: test.cpuMetadata}},
+			},
+		}
+		cache := ScalersCache{
+			Scalers:  []ScalerBuilder{{Scaler: createActivationScaler(test.cpuActive), TriggerName: "cpu", TriggerType: "cpu", TriggerMetadata: test.cpuMetadata}},
+			Logger:   logr.DiscardLogger{},
+			Recorder: record.NewFakeRecorder(1),
+		}
+		if test.withQueue {
+			scaledObject.Spec.Triggers = append(scaledObject.Spec.Triggers, kedav1alpha1.ScaleTriggers{Name: "queue", Type: "rabbitmq"})
+			cache.Scalers = append(cache.Scalers, ScalerBuilder{Scaler: createActivationScaler(test.queueActive), TriggerName: "queue", TriggerType: "rabbitmq"})
+		}
+
+		isActive, isError, _ := cache.IsScaledObjectActive(context.Background(), scaledObject)
+		assert.False(t, isError, test.name)
+		assert.Equal(t, test.expected, isActive, test.name)
+	}
+}
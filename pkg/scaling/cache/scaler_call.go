@@ -0,0 +1,227 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/kedacore/keda/v2/pkg/metrics"
+	"github.com/kedacore/keda/v2/pkg/scalers"
+)
+
+// ErrScalerCallTimeout is wrapped by the errors of the scaler calls that didn't return within the CallTimeout
+var ErrScalerCallTimeout = errors.New("scaler call timed out")
+
+// errScalerCallRunning is wrapped by the errors of the scaler calls that were not started because an abandoned
+// call of the same trigger is still running
+var errScalerCallRunning = fmt.Errorf("%w: abandoned call still running", ErrScalerCallTimeout)
+
+// abandonGracePeriod is how long a call is waited for after its context is done before it's abandoned, scalers
+// honoring the context return within it
+const abandonGracePeriod = 100 * time.Millisecond
+
+// abandonedCalls tracks the scaler calls that are still running after their CallTimeout passed. A trigger isn't called
+// again until its abandoned call returned, so a hung scaler holds at most one goroutine, and a scaler is closed only
+// once its abandoned calls returned, so it isn't closed under them
+type abandonedCalls struct {
+	lock sync.Mutex
+	// byTrigger and byScaler count the abandoned calls by scaler id and by scaler
+	byTrigger map[int]int
+	byScaler  map[scalers.Scaler]int
+	// closing are the scalers closed while they had abandoned calls, they are closed when the last one returns
+	closing map[scalers.Scaler]bool
+}
+
+func (a *abandonedCalls) isRunning(id int) bool {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	return a.byTrigger[id] > 0
+}
+
+func (a *abandonedCalls) add(id int, scaler scalers.Scaler) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	if a.byTrigger == nil {
+		a.byTrigger = map[int]int{}
+		a.byScaler = map[scalers.Scaler]int{}
+		a.closing = map[scalers.Scaler]bool{}
+	}
+	a.byTrigger[id]++
+	a.byScaler[scaler]++
+}
+
+// returned records that an abandoned call returned and closes its scaler if it was closed in the meantime
+func (a *abandonedCalls) returned(id int, scaler scalers.Scaler) {
+	a.lock.Lock()
+	a.byTrigger[id]--
+	if a.byTrigger[id] == 0 {
+		delete(a.byTrigger, id)
+	}
+	a.byScaler[scaler]--
+	closeScaler := false
+	if a.byScaler[scaler] == 0 {
+		delete(a.byScaler, scaler)
+		closeScaler = a.closing[scaler]
+		delete(a.closing, scaler)
+	}
+	a.lock.Unlock()
+
+	if closeScaler {
+		// the context of the cache may be done by now
+		if err := scaler.Close(context.Background()); err != nil {
+			logf.Log.WithName("scalers_cache").Error(err, "error closing scaler after its abandoned call returned")
+		}
+	}
+}
+
+// close closes the scaler, or defers it until its abandoned calls returned
+func (a *abandonedCalls) close(ctx context.Context, scaler scalers.Scaler) error {
+	a.lock.Lock()
+	if a.byScaler[scaler] > 0 {
+		a.closing[scaler] = true
+		a.lock.Unlock()
+		return nil
+	}
+	a.lock.Unlock()
+	return scaler.Close(ctx)
+}
+
+// callScaler runs the call of the scaler with a context bounded by the CallTimeout. Scalers honoring the context
+// return once it is done, a scaler whose client ignores it, eg. an SDK without context support, is abandoned
+// when the timeout passes so it doesn't block the polling loop, its call keeps running in the background and
+// the trigger isn't called again until it returned
+func (c *ScalersCache) callScaler(ctx context.Context, id int, scaler scalers.Scaler, call string, fn func(ctx context.Context) error) error {
+	if c.CallTimeout <= 0 {
+		return fn(ctx)
+	}
+	if c.abandoned.isRunning(id) {
+		return fmt.Errorf("%s of scaler %d not started: %w", call, id, errScalerCallRunning)
+	}
+
+	callCtx, cancel := context.WithTimeout(ctx, c.CallTimeout)
+	defer cancel()
+
+	var lock sync.Mutex
+	finished, abandoned := false, false
+	done := make(chan error, 1)
+	go func() {
+		err := fn(callCtx)
+		lock.Lock()
+		finished = true
+		if abandoned {
+			c.abandoned.returned(id, scaler)
+		}
+		lock.Unlock()
+		done <- err
+	}()
+
+	select {
+	case err := <-done:
+		if err != nil && ctx.Err() == nil && errors.Is(callCtx.Err(), context.DeadlineExceeded) {
+			return c.scalerCallTimeout(id, call, err)
+		}
+		return err
+	case <-callCtx.Done():
+	}
+
+	select {
+	case err := <-done:
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return c.scalerCallTimeout(id, call, err)
+	case <-time.After(abandonGracePeriod):
+	}
+
+	lock.Lock()
+	if !finished {
+		abandoned = true
+		c.abandoned.add(id, scaler)
+	}
+	lock.Unlock()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return c.scalerCallTimeout(id, call, nil)
+}
+
+// scalerCallTimeout records the timeout of the call and returns its error
+func (c *ScalersCache) scalerCallTimeout(id int, call string, err error) error {
+	triggerType := ""
+	if id >= 0 && id < len(c.Scalers) {
+		triggerType = c.Scalers[id].TriggerType
+	}
+	metrics.RecordScalerCallTimeout(c.Namespace, c.Name, triggerType, id, call)
+	if err != nil {
+		return fmt.Errorf("%w: %s of scaler %d took longer than %s: %s", ErrScalerCallTimeout, call, id, c.CallTimeout, err)
+	}
+	return fmt.Errorf("%w: %s of scaler %d took longer than %s", ErrScalerCallTimeout, call, id, c.CallTimeout)
+}
+
+// isActive calls IsActive of the scaler within the CallTimeout
+func (c *ScalersCache) isActive(ctx context.Context, id int, scaler scalers.Scaler) (bool, error) {
+	var isActive bool
+	err := c.callScaler(ctx, id, scaler, "IsActive", func(ctx context.Context) error {
+		var err error
+		isActive, err = scaler.IsActive(ctx)
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+	return isActive, nil
+}
+
+// getMetrics calls GetMetrics of the scaler within the CallTimeout
+func (c *ScalersCache) getMetrics(ctx context.Context, id int, scaler scalers.Scaler, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	var metrics []external_metrics.ExternalMetricValue
+	err := c.callScaler(ctx, id, scaler, "GetMetrics", func(ctx context.Context) error {
+		var err error
+		metrics, err = scaler.GetMetrics(ctx, metricName, metricSelector)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
+
+// refreshForRetry rebuilds the scaler after its call failed with err and returns the new scaler to retry the call with.
+// A scaler that timed out is rebuilt so its abandoned call doesn't run concurrently with the next calls, but the
+// timeout is returned instead of retrying to keep the polling loop within the timeout. A call that wasn't started
+// because of a running abandoned call is neither retried nor rebuilt again
+func (c *ScalersCache) refreshForRetry(ctx context.Context, id int, err error) (scalers.Scaler, error) {
+	if errors.Is(err, errScalerCallRunning) {
+		return nil, err
+	}
+	ns, refreshErr := c.refreshScaler(ctx, id)
+	if refreshErr != nil {
+		return nil, refreshErr
+	}
+	if errors.Is(err, ErrScalerCallTimeout) {
+		return nil, err
+	}
+	return ns, nil
+}
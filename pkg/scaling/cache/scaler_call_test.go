@@ -0,0 +1,123 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+
+	mock_scalers "github.com/kedacore/keda/v2/pkg/mock/mock_scaler"
+	"github.com/kedacore/keda/v2/pkg/scalers"
+)
+
+func TestCallScalerAbandonsBlockingScaler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	release := make(chan struct{})
+	defer close(release)
+
+	scaler := mock_scalers.NewMockScaler(ctrl)
+	// the scaler ignores the context like an SDK without context support
+	scaler.EXPECT().IsActive(gomock.Any()).DoAndReturn(func(context.Context) (bool, error) {
+		<-release
+		return true, nil
+	})
+
+	cache := ScalersCache{CallTimeout: 50 * time.Millisecond, Scalers: []ScalerBuilder{{Scaler: scaler, TriggerType: "kafka"}}}
+
+	start := time.Now()
+	isActive, err := cache.isActive(context.Background(), 0, scaler)
+	assert.False(t, isActive)
+	assert.True(t, errors.Is(err, ErrScalerCallTimeout))
+	assert.Less(t, int64(time.Since(start)), int64(time.Second))
+}
+
+func TestCallScalerWithContextHonoringScaler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	scaler := mock_scalers.NewMockScaler(ctrl)
+	scaler.EXPECT().GetMetrics(gomock.Any(), "queueLength", nil).DoAndReturn(func(ctx context.Context, _ string, _ interface{}) ([]external_metrics.ExternalMetricValue, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	scaler.EXPECT().GetMetrics(gomock.Any(), "queueLength", nil).Return([]external_metrics.ExternalMetricValue{{MetricName: "queueLength"}}, nil)
+
+	cache := ScalersCache{CallTimeout: 50 * time.Millisecond, Scalers: []ScalerBuilder{{Scaler: scaler}}}
+
+	_, err := cache.getMetrics(context.Background(), 0, scaler, "queueLength", nil)
+	assert.True(t, errors.Is(err, ErrScalerCallTimeout))
+
+	metrics, err := cache.getMetrics(context.Background(), 0, scaler, "queueLength", nil)
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 1)
+}
+
+func TestCallScalerCanceledContext(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	scaler := mock_scalers.NewMockScaler(ctrl)
+	scaler.EXPECT().IsActive(gomock.Any()).DoAndReturn(func(ctx context.Context) (bool, error) {
+		<-ctx.Done()
+		return false, ctx.Err()
+	}).MaxTimes(1)
+
+	cache := ScalersCache{CallTimeout: time.Minute, Scalers: []ScalerBuilder{{Scaler: scaler}}}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := cache.isActive(ctx, 0, scaler)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestGetMetricsForScalerDoesNotRetryAfterTimeout(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	release := make(chan struct{})
+	closed := make(chan struct{})
+
+	scaler := mock_scalers.NewMockScaler(ctrl)
+	scaler.EXPECT().GetMetrics(gomock.Any(), "queueLength", nil).DoAndReturn(func(context.Context, string, interface{}) ([]external_metrics.ExternalMetricValue, error) {
+		<-release
+		return nil, nil
+	})
+	// the scaler is closed only once its abandoned call returned
+	scaler.EXPECT().Close(gomock.Any()).DoAndReturn(func(context.Context) error {
+		close(closed)
+		return nil
+	})
+
+	// the refreshed scaler isn't called while the abandoned call is running
+	refreshed := mock_scalers.NewMockScaler(ctrl)
+	factory := func() (scalers.Scaler, error) {
+		return refreshed, nil
+	}
+
+	cache := ScalersCache{CallTimeout: 50 * time.Millisecond, Scalers: []ScalerBuilder{{Scaler: scaler, Factory: factory}}}
+
+	_, err := cache.GetMetricsForScaler(context.Background(), 0, "queueLength", nil)
+	assert.True(t, errors.Is(err, ErrScalerCallTimeout))
+	assert.Equal(t, refreshed, cache.Scalers[0].Scaler)
+
+	_, err = cache.GetMetricsForScaler(context.Background(), 0, "queueLength", nil)
+	assert.True(t, errors.Is(err, ErrScalerCallTimeout))
+	assert.Equal(t, refreshed, cache.Scalers[0].Scaler)
+
+	select {
+	case <-closed:
+		t.Error("scaler closed while its call is running")
+	default:
+	}
+	close(release)
+	select {
+	case <-closed:
+	case <-time.After(time.Second):
+		t.Error("scaler not closed after its call returned")
+	}
+
+	refreshed.EXPECT().GetMetrics(gomock.Any(), "queueLength", nil).Return([]external_metrics.ExternalMetricValue{{MetricName: "queueLength"}}, nil)
+	metrics, err := cache.GetMetricsForScaler(context.Background(), 0, "queueLength", nil)
+	assert.NoError(t, err)
+	assert.Len(t, metrics, 1)
+}
@@ -19,6 +19,7 @@ package cache
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/go-logr/logr"
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
@@ -37,11 +38,32 @@ type ScalersCache struct {
 	Scalers    []ScalerBuilder
 	Logger     logr.Logger
 	Recorder   record.EventRecorder
+	// CallTimeout bounds the IsActive and GetMetrics calls of the scalers, no timeout is applied when it's 0
+	CallTimeout time.Duration
+	// Namespace and Name are the namespace and name of the ScaledObject or ScaledJob, they label the timeout metrics
+	Namespace string
+	Name      string
+	// abandoned are the scaler calls still running after their CallTimeout passed
+	abandoned abandonedCalls
+	// RefreshAt is when the ServiceAccount tokens resolved for the scalers have to be requested again by rebuilding
+	// the cache, the cache is kept until its generation changes when it's zero
+	RefreshAt time.Time
+}
+
+// IsCurrent returns true if the cache was built for generation and doesn't need to be rebuilt to refresh its tokens
+func (c *ScalersCache) IsCurrent(generation int64) bool {
+	return c.Generation == generation && (c.RefreshAt.IsZero() || time.Now().Before(c.RefreshAt))
 }
 
 type ScalerBuilder struct {
 	Scaler  scalers.Scaler
 	Factory func() (scalers.Scaler, error)
+	// TriggerName is the name of the trigger the scaler was built for, used by activationExpression
+	TriggerName string
+	// TriggerType is the type of the trigger the scaler was built for
+	TriggerType string
+	// TriggerMetadata is the metadata of the trigger the scaler was built for
+	TriggerMetadata map[string]string
 }
 
 func (c *ScalersCache) GetScalers() []scalers.Scaler {
@@ -66,29 +88,46 @@ func (c *ScalersCache) GetMetricsForScaler(ctx context.Context, id int, metricNa
 	if id < 0 || id >= len(c.Scalers) {
 		return nil, fmt.Errorf("scaler with id %d not found. Len = %d", id, len(c.Scalers))
 	}
-	m, err := c.Scalers[id].Scaler.GetMetrics(ctx, metricName, metricSelector)
+	m, err := c.getMetrics(ctx, id, c.Scalers[id].Scaler, metricName, metricSelector)
 	if err == nil {
-		return m, nil
+		return selectMetrics(c.Scalers[id], m, metricSelector), nil
 	}
 
-	ns, err := c.refreshScaler(ctx, id)
+	ns, err := c.refreshForRetry(ctx, id, err)
 	if err != nil {
 		return nil, err
 	}
 
-	return ns.GetMetrics(ctx, metricName, metricSelector)
+	m, err = c.getMetrics(ctx, id, ns, metricName, metricSelector)
+	if err != nil {
+		return nil, err
+	}
+	return selectMetrics(c.Scalers[id], m, metricSelector), nil
 }
 
 func (c *ScalersCache) IsScaledObjectActive(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject) (bool, bool, []external_metrics.ExternalMetricValue) {
+	activationLogic := kedav1alpha1.ActivationLogicAnyOf
+	if scaledObject.Spec.Advanced != nil && scaledObject.Spec.Advanced.ActivationLogic != "" {
+		activationLogic = scaledObject.Spec.Advanced.ActivationLogic
+	}
+
 	isActive := false
 	isError := false
+	activeTriggers := 0
+	triggersActive := make(map[string]bool, len(c.Scalers))
 	for i, s := range c.Scalers {
-		isTriggerActive, err := s.Scaler.IsActive(ctx)
+		// cpu/memory scalers without activationValue are always active while the ScaleTarget has replicas, they
+		// would keep a ScaledObject with other triggers from scaling to zero
+		if !isActivationTrigger(scaledObject, s.TriggerType, s.TriggerMetadata) {
+			continue
+		}
+
+		isTriggerActive, err := c.isActive(ctx, i, s.Scaler)
 		if err != nil {
 			var ns scalers.Scaler
-			ns, err = c.refreshScaler(ctx, i)
+			ns, err = c.refreshForRetry(ctx, i, err)
 			if err == nil {
-				isTriggerActive, err = ns.IsActive(ctx)
+				isTriggerActive, err = c.isActive(ctx, i, ns)
 			}
 		}
 
@@ -97,23 +136,85 @@ func (c *ScalersCache) IsScaledObjectActive(ctx context.Context, scaledObject *k
 			isError = true
 			c.Recorder.Event(scaledObject, corev1.EventTypeWarning, eventreason.KEDAScalerFailed, err.Error())
 		} else if isTriggerActive {
-			isActive = true
 			if externalMetricsSpec := s.Scaler.GetMetricSpecForScaling(ctx)[0].External; externalMetricsSpec != nil {
 				c.Logger.V(1).Info("Scaler for scaledObject is active", "Metrics Name", externalMetricsSpec.Metric.Name)
 			}
-			if resourceMetricsSpec := s.Scaler.GetMetricSpecForScaling(ctx)[0].Resource; resourceMetricsSpec != nil {
-				c.Logger.V(1).Info("Scaler for scaledObject is active", "Metrics Name", resourceMetricsSpec.Name)
+			if metricSpec := s.Scaler.GetMetricSpecForScaling(ctx)[0]; metricSpec.Resource != nil {
+				c.Logger.V(1).Info("Scaler for scaledObject is active", "Metrics Name", metricSpec.Resource.Name)
+			} else if metricSpec.ContainerResource != nil {
+				c.Logger.V(1).Info("Scaler for scaledObject is active", "Metrics Name", metricSpec.ContainerResource.Name, "Container", metricSpec.ContainerResource.Container)
+			}
+			if activationLogic == kedav1alpha1.ActivationLogicAnyOf {
+				isActive = true
+				break
+			}
+			activeTriggers++
+			if s.TriggerName != "" {
+				triggersActive[s.TriggerName] = true
 			}
-			break
+		}
+	}
+
+	switch activationLogic {
+	case kedav1alpha1.ActivationLogicAllOf:
+		// triggers whose scaler couldn't be built are missing from the cache and count as not active
+		isActive = activeTriggers > 0 && activeTriggers == countActivationTriggers(scaledObject)
+	case kedav1alpha1.ActivationLogicExpression:
+		var err error
+		isActive, err = evaluateActivationExpression(scaledObject.Spec.Advanced.ActivationExpression, triggersActive)
+		if err != nil {
+			c.Logger.Error(err, "Error evaluating activationExpression")
+			isError = true
 		}
 	}
 
 	return isActive, isError, []external_metrics.ExternalMetricValue{}
 }
 
-func (c *ScalersCache) IsScaledJobActive(ctx context.Context, scaledJob *kedav1alpha1.ScaledJob) (bool, int64, int64) {
+// GetScaledObjectDesiredReplicas computes the replica count the HPA would target for the external
+// metrics of all scalers, ie. the highest ceil(metric value / target average value) of all metrics
+func (c *ScalersCache) GetScaledObjectDesiredReplicas(ctx context.Context) (int64, error) {
+	var desiredReplicas int64
+	found := false
+	for i, s := range c.Scalers {
+		for _, metricSpec := range s.Scaler.GetMetricSpecForScaling(ctx) {
+			// cpu/memory scalers are served by the Kubernetes Metrics Server, not by KEDA
+			if metricSpec.External == nil || metricSpec.External.Target.AverageValue == nil {
+				continue
+			}
+			targetAverageValue := metricSpec.External.Target.AverageValue.MilliValue()
+			if targetAverageValue <= 0 {
+				continue
+			}
+
+			metrics, err := c.GetMetricsForScaler(ctx, i, metricSpec.External.Metric.Name, nil)
+			if err != nil {
+				return 0, err
+			}
+			var metricValue int64
+			for _, m := range metrics {
+				metricValue += m.Value.MilliValue()
+			}
+
+			found = true
+			if replicas := divideWithCeil(metricValue, targetAverageValue); replicas > desiredReplicas {
+				desiredReplicas = replicas
+			}
+		}
+	}
+
+	if !found {
+		return 0, fmt.Errorf("no external metrics with an average value target found")
+	}
+	return desiredReplicas, nil
+}
+
+// IsScaledJobActive returns whether the ScaledJob is active, the queue length and the max value of the scalers
+// combined with the MultipleScalersCalculation, and the trigger the created jobs are attributed to
+func (c *ScalersCache) IsScaledJobActive(ctx context.Context, scaledJob *kedav1alpha1.ScaledJob) (bool, int64, int64, string) {
 	var queueLength int64
 	var maxValue int64
+	var trigger string
 	isActive := false
 
 	logger := logf.Log.WithName("scalemetrics")
@@ -125,6 +226,7 @@ func (c *ScalersCache) IsScaledJobActive(ctx context.Context, scaledJob *kedav1a
 				queueLength = metrics.queueLength
 				maxValue = metrics.maxValue
 				isActive = metrics.isActive
+				trigger = metrics.trigger
 			}
 		}
 	case "avg":
@@ -143,6 +245,7 @@ func (c *ScalersCache) IsScaledJobActive(ctx context.Context, scaledJob *kedav1a
 			queueLength = divideWithCeil(queueLengthSum, int64(length))
 			maxValue = divideWithCeil(maxValueSum, int64(length))
 		}
+		trigger = largestActiveTrigger(scalersMetrics)
 	case "sum":
 		for _, metrics := range scalersMetrics {
 			if metrics.isActive {
@@ -151,31 +254,47 @@ func (c *ScalersCache) IsScaledJobActive(ctx context.Context, scaledJob *kedav1a
 				isActive = metrics.isActive
 			}
 		}
+		trigger = largestActiveTrigger(scalersMetrics)
 	default: // max
 		for _, metrics := range scalersMetrics {
 			if metrics.queueLength > queueLength && metrics.isActive {
 				queueLength = metrics.queueLength
 				maxValue = metrics.maxValue
 				isActive = metrics.isActive
+				trigger = metrics.trigger
 			}
 		}
 	}
 	maxValue = min(scaledJob.MaxReplicaCount(), maxValue)
-	logger.V(1).WithValues("ScaledJob", scaledJob.Name).Info("Checking if ScaleJob Scalers are active", "isActive", isActive, "maxValue", maxValue, "MultipleScalersCalculation", scaledJob.Spec.ScalingStrategy.MultipleScalersCalculation)
+	logger.V(1).WithValues("ScaledJob", scaledJob.Name).Info("Checking if ScaleJob Scalers are active", "isActive", isActive, "maxValue", maxValue, "MultipleScalersCalculation", scaledJob.Spec.ScalingStrategy.MultipleScalersCalculation, "trigger", trigger)
+
+	return isActive, queueLength, maxValue, trigger
+}
 
-	return isActive, queueLength, maxValue
+// largestActiveTrigger returns the active trigger with the largest queue length, for "avg" and "sum"
+// this is the trigger contributing the most to the number of created jobs
+func largestActiveTrigger(scalersMetrics []scalerMetrics) string {
+	var queueLength int64
+	var trigger string
+	for _, metrics := range scalersMetrics {
+		if metrics.isActive && (trigger == "" || metrics.queueLength > queueLength) {
+			queueLength = metrics.queueLength
+			trigger = metrics.trigger
+		}
+	}
+	return trigger
 }
 
 func (c *ScalersCache) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
 	var metrics []external_metrics.ExternalMetricValue
 	for i, s := range c.Scalers {
-		m, err := s.Scaler.GetMetrics(ctx, metricName, metricSelector)
+		m, err := c.getMetrics(ctx, i, s.Scaler, metricName, metricSelector)
 		if err != nil {
-			ns, err := c.refreshScaler(ctx, i)
+			ns, err := c.refreshForRetry(ctx, i, err)
 			if err != nil {
 				return metrics, err
 			}
-			m, err = ns.GetMetrics(ctx, metricName, metricSelector)
+			m, err = c.getMetrics(ctx, i, ns, metricName, metricSelector)
 			if err != nil {
 				return metrics, err
 			}
@@ -198,10 +317,15 @@ func (c *ScalersCache) refreshScaler(ctx context.Context, id int) (scalers.Scale
 	}
 
 	c.Scalers[id] = ScalerBuilder{
-		Scaler:  ns,
-		Factory: sb.Factory,
+		Scaler:          ns,
+		Factory:         sb.Factory,
+		TriggerName:     sb.TriggerName,
+		TriggerType:     sb.TriggerType,
+		TriggerMetadata: sb.TriggerMetadata,
+	}
+	if err := c.abandoned.close(ctx, sb.Scaler); err != nil {
+		c.Logger.Error(err, "error closing scaler", "scaler", sb)
 	}
-	sb.Scaler.Close(ctx)
 
 	return ns, nil
 }
@@ -218,7 +342,7 @@ func (c *ScalersCache) Close(ctx context.Context) {
 	scalers := c.Scalers
 	c.Scalers = nil
 	for _, s := range scalers {
-		err := s.Scaler.Close(ctx)
+		err := c.abandoned.close(ctx, s.Scaler)
 		if err != nil {
 			c.Logger.Error(err, "error closing scaler", "scaler", s)
 		}
@@ -229,6 +353,8 @@ type scalerMetrics struct {
 	queueLength int64
 	maxValue    int64
 	isActive    bool
+	// trigger is the name of the trigger, or its type if it has no name
+	trigger string
 }
 
 func (c *ScalersCache) getScaledJobMetrics(ctx context.Context, scaledJob *kedav1alpha1.ScaledJob) []scalerMetrics {
@@ -250,12 +376,12 @@ func (c *ScalersCache) getScaledJobMetrics(ctx context.Context, scaledJob *kedav
 			continue
 		}
 
-		isTriggerActive, err := s.Scaler.IsActive(ctx)
+		isTriggerActive, err := c.isActive(ctx, i, s.Scaler)
 		if err != nil {
 			var ns scalers.Scaler
-			ns, err = c.refreshScaler(ctx, i)
+			ns, err = c.refreshForRetry(ctx, i, err)
 			if err == nil {
-				isTriggerActive, err = ns.IsActive(ctx)
+				isTriggerActive, err = c.isActive(ctx, i, ns)
 			}
 		}
 
@@ -267,7 +393,7 @@ func (c *ScalersCache) getScaledJobMetrics(ctx context.Context, scaledJob *kedav
 
 		targetAverageValue = getTargetAverageValue(metricSpecs)
 
-		metrics, err := s.Scaler.GetMetrics(ctx, "queueLength", nil)
+		metrics, err := c.getMetrics(ctx, i, c.Scalers[i].Scaler, "queueLength", nil)
 		if err != nil {
 			scalerLogger.V(1).Info("Error getting scaler metrics, but continue", "Error", err)
 			c.Recorder.Event(scaledJob, corev1.EventTypeWarning, eventreason.KEDAScalerFailed, err.Error())
@@ -291,10 +417,15 @@ func (c *ScalersCache) getScaledJobMetrics(ctx context.Context, scaledJob *kedav
 		if targetAverageValue != 0 {
 			maxValue = min(scaledJob.MaxReplicaCount(), divideWithCeil(queueLength, targetAverageValue))
 		}
+		trigger := s.TriggerName
+		if trigger == "" {
+			trigger = s.TriggerType
+		}
 		scalersMetrics = append(scalersMetrics, scalerMetrics{
 			queueLength: queueLength,
 			maxValue:    maxValue,
 			isActive:    isActive,
+			trigger:     trigger,
 		})
 	}
 	return scalersMetrics
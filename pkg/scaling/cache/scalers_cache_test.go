@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"k8s.io/api/autoscaling/v2beta2"
 	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/metrics/pkg/apis/external_metrics"
 
@@ -80,7 +81,7 @@ func TestIsScaledJobActive(t *testing.T) {
 		Recorder: recorder,
 	}
 
-	isActive, queueLength, maxValue := cache.IsScaledJobActive(context.TODO(), scaledJobSingle)
+	isActive, queueLength, maxValue, _ := cache.IsScaledJobActive(context.TODO(), scaledJobSingle)
 	assert.Equal(t, true, isActive)
 	assert.Equal(t, int64(20), queueLength)
 	assert.Equal(t, int64(10), maxValue)
@@ -100,7 +101,7 @@ func TestIsScaledJobActive(t *testing.T) {
 		Recorder: recorder,
 	}
 
-	isActive, queueLength, maxValue = cache.IsScaledJobActive(context.TODO(), scaledJobSingle)
+	isActive, queueLength, maxValue, _ = cache.IsScaledJobActive(context.TODO(), scaledJobSingle)
 	assert.Equal(t, false, isActive)
 	assert.Equal(t, int64(0), queueLength)
 	assert.Equal(t, int64(0), maxValue)
@@ -145,7 +146,7 @@ func TestIsScaledJobActive(t *testing.T) {
 			Recorder: recorder,
 		}
 		fmt.Printf("index: %d", index)
-		isActive, queueLength, maxValue = cache.IsScaledJobActive(context.TODO(), scaledJob)
+		isActive, queueLength, maxValue, _ = cache.IsScaledJobActive(context.TODO(), scaledJob)
 		//	assert.Equal(t, 5, index)
 		assert.Equal(t, scalerTestData.ResultIsActive, isActive)
 		assert.Equal(t, scalerTestData.ResultQueueLength, queueLength)
@@ -154,6 +155,51 @@ func TestIsScaledJobActive(t *testing.T) {
 	}
 }
 
+func TestIsScaledJobActiveTrigger(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	recorder := record.NewFakeRecorder(1)
+
+	for _, calculation := range []string{"max", "sum", "avg"} {
+		scaledJob := createScaledObject(100, calculation)
+		cache := ScalersCache{
+			Scalers: []ScalerBuilder{{
+				Scaler:      createScaler(ctrl, int64(5), int32(1), true),
+				TriggerName: "small",
+				TriggerType: "kafka",
+			}, {
+				Scaler:      createScaler(ctrl, int64(20), int32(1), true),
+				TriggerType: "rabbitmq",
+			}, {
+				Scaler:      createScaler(ctrl, int64(50), int32(1), false),
+				TriggerName: "inactive",
+			}},
+			Logger:   logr.DiscardLogger{},
+			Recorder: recorder,
+		}
+
+		// the active trigger with the largest queue is used, a trigger without name is attributed by its type
+		_, _, _, trigger := cache.IsScaledJobActive(context.TODO(), scaledJob)
+		assert.Equal(t, "rabbitmq", trigger, calculation)
+		cache.Close(context.Background())
+	}
+
+	scaledJob := createScaledObject(100, "min")
+	cache := ScalersCache{
+		Scalers: []ScalerBuilder{{
+			Scaler:      createScaler(ctrl, int64(5), int32(1), true),
+			TriggerName: "small",
+		}, {
+			Scaler:      createScaler(ctrl, int64(20), int32(1), true),
+			TriggerName: "large",
+		}},
+		Logger:   logr.DiscardLogger{},
+		Recorder: recorder,
+	}
+	_, _, _, trigger := cache.IsScaledJobActive(context.TODO(), scaledJob)
+	assert.Equal(t, "small", trigger)
+	cache.Close(context.Background())
+}
+
 func newScalerTestData(
 	maxReplicaCount int,
 	multipleScalersCalculation string,
@@ -247,3 +293,76 @@ func createScaler(ctrl *gomock.Controller, queueLength int64, averageValue int32
 	scaler.EXPECT().Close(gomock.Any())
 	return scaler
 }
+
+func TestGetScaledObjectDesiredReplicas(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	createDesiredReplicasScaler := func(metricName string, value int64, averageValue int) *mock_scalers.MockScaler {
+		scaler := mock_scalers.NewMockScaler(ctrl)
+		metricSpec := createMetricSpec(averageValue)
+		metricSpec.External.Metric.Name = metricName
+		scaler.EXPECT().GetMetricSpecForScaling(gomock.Any()).Return([]v2beta2.MetricSpec{metricSpec})
+		scaler.EXPECT().GetMetrics(gomock.Any(), metricName, nil).Return([]external_metrics.ExternalMetricValue{
+			{MetricName: metricName, Value: *resource.NewQuantity(value, resource.DecimalSI)},
+		}, nil)
+		return scaler
+	}
+
+	cache := ScalersCache{
+		Scalers: []ScalerBuilder{
+			{Scaler: createDesiredReplicasScaler("s0-queue", 21, 5)},
+			{Scaler: createDesiredReplicasScaler("s1-lag", 7, 10)},
+		},
+		Logger:   logr.DiscardLogger{},
+		Recorder: record.NewFakeRecorder(1),
+	}
+
+	desiredReplicas, err := cache.GetScaledObjectDesiredReplicas(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), desiredReplicas)
+
+	// resource metrics are not served by KEDA
+	cpuScaler := mock_scalers.NewMockScaler(ctrl)
+	cpuScaler.EXPECT().GetMetricSpecForScaling(gomock.Any()).Return([]v2beta2.MetricSpec{{Type: v2beta2.ResourceMetricSourceType, Resource: &v2beta2.ResourceMetricSource{}}})
+	cache.Scalers = []ScalerBuilder{{Scaler: cpuScaler}}
+
+	_, err = cache.GetScaledObjectDesiredReplicas(context.Background())
+	assert.Error(t, err)
+}
+
+func TestGetMetricsForScalerSelectsByLabels(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	scaler := mock_scalers.NewMockScaler(ctrl)
+	metrics := []external_metrics.ExternalMetricValue{
+		{MetricName: "s0-kafka-orders", Value: *resource.NewQuantity(10, resource.DecimalSI)},
+		{MetricName: "s0-kafka-orders", Value: *resource.NewQuantity(20, resource.DecimalSI), MetricLabels: map[string]string{"partition": "1"}},
+	}
+	scaler.EXPECT().GetMetrics(gomock.Any(), "s0-kafka-orders", gomock.Any()).Return(metrics, nil).AnyTimes()
+
+	cache := ScalersCache{
+		Scalers: []ScalerBuilder{{Scaler: scaler, TriggerName: "orders", TriggerType: "kafka"}},
+		Logger:  logr.Discard(),
+	}
+
+	m, err := cache.GetMetricsForScaler(context.Background(), 0, "s0-kafka-orders", nil)
+	assert.NoError(t, err)
+	assert.Len(t, m, 2)
+	assert.Equal(t, map[string]string{TriggerTypeLabel: "kafka", TriggerNameLabel: "orders"}, m[0].MetricLabels)
+	assert.Equal(t, map[string]string{TriggerTypeLabel: "kafka", TriggerNameLabel: "orders", "partition": "1"}, m[1].MetricLabels)
+	// the values of the scaler aren't changed
+	assert.Nil(t, metrics[0].MetricLabels)
+
+	for selector, expected := range map[string]int{
+		"":                                2,
+		"trigger.keda.sh/name=orders":     2,
+		"trigger.keda.sh/type=rabbitmq":   0,
+		"partition=1":                     1,
+		"trigger.keda.sh/type,!partition": 1,
+	} {
+		s, err := labels.Parse(selector)
+		assert.NoError(t, err)
+		m, err := cache.GetMetricsForScaler(context.Background(), 0, "s0-kafka-orders", s)
+		assert.NoError(t, err)
+		assert.Len(t, m, expected, selector)
+	}
+}
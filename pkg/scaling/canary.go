@@ -0,0 +1,180 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/eventreason"
+	"github.com/kedacore/keda/v2/pkg/scaling/cache"
+	"github.com/kedacore/keda/v2/pkg/scaling/resolver"
+)
+
+// withAppliedTriggers returns the ScaledObject with the triggers in effect, ie. the applied triggers
+// while changed triggers are evaluated, so the activation logic counts and names the cached scalers
+func withAppliedTriggers(scaledObject *kedav1alpha1.ScaledObject) *kedav1alpha1.ScaledObject {
+	if !scaledObject.Status.Canary.IsEvaluating() {
+		return scaledObject
+	}
+	applied := *scaledObject
+	applied.Spec.Triggers = scaledObject.Status.Canary.AppliedTriggers
+	return &applied
+}
+
+// evaluateCanary runs the changed triggers of a ScaledObject with changePolicy canary next to the applied triggers
+// and records on its status whether they lead to another scaling decision, the changed triggers take effect
+// once the canary polls are done
+func (h *scaleHandler) evaluateCanary(ctx context.Context, appliedCache *cache.ScalersCache, scaledObject *kedav1alpha1.ScaledObject, isActive, isError bool) {
+	canary := scaledObject.Status.Canary
+	// the controller hasn't started the evaluation of the current generation yet
+	if !canary.IsEvaluating() || canary.CandidateGeneration != scaledObject.Generation {
+		return
+	}
+	logger := h.logger.WithValues("scaledObject.Namespace", scaledObject.Namespace, "scaledObject.Name", scaledObject.Name)
+
+	var divergence string
+	candidateCache, err := h.getCanaryScalersCache(ctx, scaledObject)
+	if err != nil {
+		divergence = fmt.Sprintf("changed triggers failed: %s", err)
+	} else {
+		divergence = compareScalingDecisions(ctx, appliedCache, candidateCache, scaledObject, isActive, isError)
+	}
+
+	next := canary.DeepCopy()
+	next.RemainingPolls--
+	if divergence != "" {
+		next.DivergentPolls++
+		next.LastDivergence = divergence
+		logger.Info("Changed triggers diverged from the applied triggers", "divergence", divergence)
+		h.recorder.Event(scaledObject, corev1.EventTypeWarning, eventreason.KEDACanaryDiverged, divergence)
+	}
+	completed := next.RemainingPolls <= 0
+	if completed {
+		next.AppliedTriggers = make([]kedav1alpha1.ScaleTriggers, len(scaledObject.Spec.Triggers))
+		for i := range scaledObject.Spec.Triggers {
+			scaledObject.Spec.Triggers[i].DeepCopyInto(&next.AppliedTriggers[i])
+		}
+		next.AppliedGeneration = next.CandidateGeneration
+		next.CandidateGeneration = 0
+		next.RemainingPolls = 0
+	}
+
+	patch := client.MergeFrom(scaledObject.DeepCopy())
+	scaledObject.Status.Canary = next
+	if err := h.client.Status().Patch(ctx, scaledObject, patch); err != nil {
+		logger.Error(err, "Error updating the canary status")
+		scaledObject.Status.Canary = canary
+		return
+	}
+
+	if completed {
+		h.closeCanaryScalersCache(ctx, scaledObject)
+		logger.Info("Changed triggers took effect", "divergentPolls", next.DivergentPolls)
+		h.recorder.Eventf(scaledObject, corev1.EventTypeNormal, eventreason.KEDACanaryCompleted, "Changed triggers took effect, they diverged in %d polls", next.DivergentPolls)
+	}
+}
+
+// compareScalingDecisions returns the differences of the activity and the desired replicas of the applied and the
+// changed triggers, or an empty string when they lead to the same scaling decision
+func compareScalingDecisions(ctx context.Context, appliedCache, candidateCache *cache.ScalersCache, scaledObject *kedav1alpha1.ScaledObject, isActive, isError bool) string {
+	var differences []string
+
+	candidateActive, candidateError, _ := candidateCache.IsScaledObjectActive(ctx, scaledObject)
+	if candidateError && !isError {
+		differences = append(differences, "changed triggers failed to get the activity")
+	}
+	if candidateActive != isActive {
+		differences = append(differences, fmt.Sprintf("active: applied %t, changed %t", isActive, candidateActive))
+	}
+
+	appliedReplicas, appliedErr := appliedCache.GetScaledObjectDesiredReplicas(ctx)
+	candidateReplicas, candidateErr := candidateCache.GetScaledObjectDesiredReplicas(ctx)
+	switch {
+	case appliedErr == nil && candidateErr != nil:
+		differences = append(differences, fmt.Sprintf("changed triggers failed to get the metrics: %s", candidateErr))
+	case appliedErr == nil && appliedReplicas != candidateReplicas:
+		differences = append(differences, fmt.Sprintf("desired replicas: applied %d, changed %d", appliedReplicas, candidateReplicas))
+	}
+
+	return strings.Join(differences, "; ")
+}
+
+// getCanaryScalersCache returns the scalers of the changed triggers of the ScaledObject, they are cached
+// for the generation under evaluation
+func (h *scaleHandler) getCanaryScalersCache(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject) (*cache.ScalersCache, error) {
+	key := strings.ToLower(fmt.Sprintf("%s.%s", scaledObject.Name, scaledObject.Namespace))
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if cache, ok := h.canaryCaches[key]; ok && cache.IsCurrent(scaledObject.Generation) {
+		return cache, nil
+	} else if ok {
+		cache.Close(ctx)
+		delete(h.canaryCaches, key)
+	}
+
+	podTemplateSpec, containerName, err := resolver.ResolveScaleTargetPodSpec(ctx, h.client, h.logger, scaledObject)
+	if err != nil {
+		return nil, err
+	}
+
+	withTriggers := &kedav1alpha1.WithTriggers{
+		TypeMeta:   scaledObject.TypeMeta,
+		ObjectMeta: scaledObject.ObjectMeta,
+		Spec: kedav1alpha1.WithTriggersSpec{
+			PollingInterval: scaledObject.Spec.PollingInterval,
+			Triggers:        scaledObject.Spec.Triggers,
+		},
+	}
+	tokenCtx := resolver.WithTokenRefresh(ctx)
+	scalers := h.buildScalers(tokenCtx, withTriggers, podTemplateSpec, containerName)
+	if len(scalers) != len(scaledObject.Spec.Triggers) {
+		for _, s := range scalers {
+			s.Scaler.Close(ctx)
+		}
+		return nil, fmt.Errorf("built %d of %d scalers", len(scalers), len(scaledObject.Spec.Triggers))
+	}
+
+	h.canaryCaches[key] = &cache.ScalersCache{
+		Generation:  scaledObject.Generation,
+		Scalers:     scalers,
+		Logger:      h.logger,
+		Recorder:    h.recorder,
+		CallTimeout: h.scalerCallTimeout(withTriggers),
+		Namespace:   scaledObject.Namespace,
+		Name:        scaledObject.Name,
+		RefreshAt:   resolver.TokenRefreshTime(tokenCtx),
+	}
+	return h.canaryCaches[key], nil
+}
+
+func (h *scaleHandler) closeCanaryScalersCache(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject) {
+	key := strings.ToLower(fmt.Sprintf("%s.%s", scaledObject.Name, scaledObject.Namespace))
+
+	h.lock.Lock()
+	defer h.lock.Unlock()
+	if cache, ok := h.canaryCaches[key]; ok {
+		cache.Close(ctx)
+		delete(h.canaryCaches, key)
+	}
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	mock_scalers "github.com/kedacore/keda/v2/pkg/mock/mock_scaler"
+	"github.com/kedacore/keda/v2/pkg/scaling/cache"
+)
+
+func newCanaryTestScaledObject(remainingPolls int32) *kedav1alpha1.ScaledObject {
+	return &kedav1alpha1.ScaledObject{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "test", Generation: 3},
+		Spec: kedav1alpha1.ScaledObjectSpec{
+			Advanced: &kedav1alpha1.AdvancedConfig{ChangePolicy: kedav1alpha1.ChangePolicyCanary},
+			Triggers: []kedav1alpha1.ScaleTriggers{{Type: "kafka", Metadata: map[string]string{"lagThreshold": "20"}}},
+		},
+		Status: kedav1alpha1.ScaledObjectStatus{
+			Canary: &kedav1alpha1.CanaryStatus{
+				AppliedTriggers:     []kedav1alpha1.ScaleTriggers{{Type: "kafka", Metadata: map[string]string{"lagThreshold": "10"}}},
+				AppliedGeneration:   2,
+				CandidateGeneration: 3,
+				RemainingPolls:      remainingPolls,
+			},
+		},
+	}
+}
+
+func newCanaryTestCache(ctrl *gomock.Controller, generation int64, lag int64, target int64) *cache.ScalersCache {
+	scaler := mock_scalers.NewMockScaler(ctrl)
+	metricSpec := v2beta2.MetricSpec{
+		External: &v2beta2.ExternalMetricSource{
+			Metric: v2beta2.MetricIdentifier{Name: "s0-kafka-orders"},
+			Target: v2beta2.MetricTarget{Type: v2beta2.AverageValueMetricType, AverageValue: resource.NewQuantity(target, resource.DecimalSI)},
+		},
+		Type: v2beta2.ExternalMetricSourceType,
+	}
+	scaler.EXPECT().IsActive(gomock.Any()).Return(lag > 0, nil).AnyTimes()
+	scaler.EXPECT().GetMetricSpecForScaling(gomock.Any()).Return([]v2beta2.MetricSpec{metricSpec}).AnyTimes()
+	scaler.EXPECT().GetMetrics(gomock.Any(), "s0-kafka-orders", nil).Return([]external_metrics.ExternalMetricValue{
+		{MetricName: "s0-kafka-orders", Value: *resource.NewQuantity(lag, resource.DecimalSI)},
+	}, nil).AnyTimes()
+	scaler.EXPECT().Close(gomock.Any()).AnyTimes()
+	return &cache.ScalersCache{
+		Generation: generation,
+		Scalers:    []cache.ScalerBuilder{{Scaler: scaler, TriggerType: "kafka"}},
+		Logger:     logf.Log.WithName("test"),
+		Recorder:   record.NewFakeRecorder(1),
+	}
+}
+
+func TestAsDuckWithTriggersKeepsAppliedTriggers(t *testing.T) {
+	scaledObject := newCanaryTestScaledObject(5)
+
+	withTriggers, err := asDuckWithTriggers(scaledObject)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), withTriggers.Generation)
+	assert.Equal(t, "10", withTriggers.Spec.Triggers[0].Metadata["lagThreshold"])
+	assert.Equal(t, int64(3), scaledObject.Generation, "the ScaledObject isn't changed")
+
+	scaledObject.Status.Canary.CandidateGeneration = 0
+	withTriggers, err = asDuckWithTriggers(scaledObject)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(3), withTriggers.Generation)
+	assert.Equal(t, "20", withTriggers.Spec.Triggers[0].Metadata["lagThreshold"])
+}
+
+func TestEvaluateCanaryRecordsDivergence(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	scaledObject := newCanaryTestScaledObject(2)
+	handler, recorder := newMaxReplicaCountTestHandler(t, scaledObject)
+	handler.lock = &sync.RWMutex{}
+	handler.canaryCaches = map[string]*cache.ScalersCache{"orders.test": newCanaryTestCache(ctrl, 3, 40, 20)}
+
+	handler.evaluateCanary(context.Background(), newCanaryTestCache(ctrl, 2, 40, 10), scaledObject, true, false)
+
+	stored := &kedav1alpha1.ScaledObject{}
+	assert.NoError(t, handler.client.Get(context.Background(), types.NamespacedName{Name: "orders", Namespace: "test"}, stored))
+	assert.True(t, stored.Status.Canary.IsEvaluating())
+	assert.Equal(t, int32(1), stored.Status.Canary.RemainingPolls)
+	assert.Equal(t, int32(1), stored.Status.Canary.DivergentPolls)
+	assert.Equal(t, "desired replicas: applied 4, changed 2", stored.Status.Canary.LastDivergence)
+	assert.Equal(t, "10", stored.Status.Canary.AppliedTriggers[0].Metadata["lagThreshold"])
+	assert.Len(t, recorder.Events, 1)
+}
+
+func TestEvaluateCanaryAppliesChangedTriggers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	scaledObject := newCanaryTestScaledObject(1)
+	handler, recorder := newMaxReplicaCountTestHandler(t, scaledObject)
+	handler.lock = &sync.RWMutex{}
+	handler.canaryCaches = map[string]*cache.ScalersCache{"orders.test": newCanaryTestCache(ctrl, 3, 40, 20)}
+
+	handler.evaluateCanary(context.Background(), newCanaryTestCache(ctrl, 2, 40, 20), scaledObject, true, false)
+
+	stored := &kedav1alpha1.ScaledObject{}
+	assert.NoError(t, handler.client.Get(context.Background(), types.NamespacedName{Name: "orders", Namespace: "test"}, stored))
+	assert.False(t, stored.Status.Canary.IsEvaluating())
+	assert.Equal(t, int64(3), stored.Status.Canary.AppliedGeneration)
+	assert.Equal(t, "20", stored.Status.Canary.AppliedTriggers[0].Metadata["lagThreshold"])
+	assert.Equal(t, int32(0), stored.Status.Canary.DivergentPolls)
+	assert.Empty(t, handler.canaryCaches, "the scalers of the changed triggers are closed")
+	assert.Len(t, recorder.Events, 1)
+}
+
+func TestEvaluateCanarySkipsOutdatedEvaluation(t *testing.T) {
+	scaledObject := newCanaryTestScaledObject(1)
+	scaledObject.Generation = 4
+	handler, recorder := newMaxReplicaCountTestHandler(t, scaledObject)
+
+	handler.evaluateCanary(context.Background(), &cache.ScalersCache{}, scaledObject, true, false)
+
+	assert.Equal(t, int32(1), scaledObject.Status.Canary.RemainingPolls)
+	assert.Len(t, recorder.Events, 0)
+}
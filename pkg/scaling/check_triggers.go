@@ -0,0 +1,148 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/scalers"
+	"github.com/kedacore/keda/v2/pkg/scaling/resolver"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
+)
+
+// TriggerCheck is the result of building the scaler of a trigger and calling it once
+type TriggerCheck struct {
+	Index   int
+	Name    string
+	Type    string
+	Active  bool
+	Metrics []external_metrics.ExternalMetricValue
+	// Err is the error building the scaler or getting its activity or metrics
+	Err error
+}
+
+// CheckTriggers builds the scalers of the triggers of a ScaledObject or ScaledJob the way the operator does,
+// resolving their metadata and authentication, and calls each of them once. The scalers aren't cached,
+// no events are recorded and the scalers are closed afterwards
+func CheckTriggers(ctx context.Context, client client.Client, scalableObject interface{}, globalHTTPTimeout time.Duration) ([]TriggerCheck, error) {
+	target, err := newOneShotTarget(ctx, client, scalableObject, globalHTTPTimeout, "checktriggers")
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = target.withRequester(ctx)
+	checks := make([]TriggerCheck, 0, len(target.withTriggers.Spec.Triggers))
+	for scalerIndex, trigger := range target.withTriggers.Spec.Triggers {
+		check := TriggerCheck{Index: scalerIndex, Name: trigger.Name, Type: trigger.Type}
+		scaler, err := target.handler.newScalerFactory(ctx, target.logger, target.withTriggers, scalerIndex, trigger, target.podTemplateSpec, target.containerName)()
+		if err != nil {
+			check.Err = err
+		} else {
+			check.Active, check.Metrics, check.Err = checkScaler(ctx, scaler)
+		}
+		if scaler != nil {
+			scaler.Close(ctx)
+		}
+		checks = append(checks, check)
+	}
+	return checks, nil
+}
+
+// oneShotTarget is a ScaledObject or ScaledJob with its resolved scale target, for building its scalers once
+// outside of the scale loops
+type oneShotTarget struct {
+	handler         *scaleHandler
+	logger          logr.Logger
+	withTriggers    *kedav1alpha1.WithTriggers
+	podTemplateSpec *corev1.PodTemplateSpec
+	containerName   string
+}
+
+func newOneShotTarget(ctx context.Context, client client.Client, scalableObject interface{}, globalHTTPTimeout time.Duration, name string) (*oneShotTarget, error) {
+	// ScaledObjects not reconciled yet have no resolved scale target in their status
+	if scaledObject, ok := scalableObject.(*kedav1alpha1.ScaledObject); ok && scaledObject.Status.ScaleTargetGVKR == nil {
+		gvkr, err := kedautil.ParseGVKR(client.RESTMapper(), scaledObject.Spec.ScaleTargetRef.APIVersion, scaledObject.Spec.ScaleTargetRef.Kind)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing the scale target: %s", err)
+		}
+		scaledObject = scaledObject.DeepCopy()
+		scaledObject.Status.ScaleTargetGVKR = &gvkr
+		scalableObject = scaledObject
+	}
+
+	withTriggers, err := asDuckWithTriggers(scalableObject)
+	if err != nil {
+		return nil, err
+	}
+
+	h := &scaleHandler{
+		client:            client,
+		logger:            logf.Log.WithName(name),
+		globalHTTPTimeout: globalHTTPTimeout,
+		// the events of a one-shot build aren't recorded on the object
+		recorder: &record.FakeRecorder{},
+	}
+
+	podTemplateSpec, containerName, err := resolver.ResolveScaleTargetPodSpec(ctx, client, h.logger, scalableObject)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving the scale target: %s", err)
+	}
+
+	return &oneShotTarget{
+		handler:         h,
+		logger:          h.logger.WithValues("type", withTriggers.Kind, "namespace", withTriggers.Namespace, "name", withTriggers.Name),
+		withTriggers:    withTriggers,
+		podTemplateSpec: podTemplateSpec,
+		containerName:   containerName,
+	}, nil
+}
+
+func (t *oneShotTarget) withRequester(ctx context.Context) context.Context {
+	return resolver.WithRequester(ctx, resolver.Requester{Kind: t.withTriggers.Kind, Namespace: t.withTriggers.Namespace, Name: t.withTriggers.Name})
+}
+
+// checkScaler returns the activity of the scaler and the values of its external metrics
+func checkScaler(ctx context.Context, scaler scalers.Scaler) (bool, []external_metrics.ExternalMetricValue, error) {
+	active, err := scaler.IsActive(ctx)
+	if err != nil {
+		return false, nil, fmt.Errorf("error getting activity: %s", err)
+	}
+
+	var metrics []external_metrics.ExternalMetricValue
+	for _, spec := range scaler.GetMetricSpecForScaling(ctx) {
+		// cpu and memory metrics are provided by the metrics server
+		if spec.External == nil {
+			continue
+		}
+		values, err := scaler.GetMetrics(ctx, spec.External.Metric.Name, nil)
+		if err != nil {
+			return active, metrics, fmt.Errorf("error getting metric %s: %s", spec.External.Metric.Name, err)
+		}
+		metrics = append(metrics, values...)
+	}
+	return active, metrics, nil
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+func TestCheckTriggers(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, appsv1.AddToScheme(scheme))
+	assert.NoError(t, kedav1alpha1.AddToScheme(scheme))
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "test"},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "orders"}}},
+			},
+		},
+	}
+	// not reconciled yet, the scale target is resolved from the spec
+	scaledObject := &kedav1alpha1.ScaledObject{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "test"},
+		Spec: kedav1alpha1.ScaledObjectSpec{
+			ScaleTargetRef: &kedav1alpha1.ScaleTarget{Name: "orders"},
+			Triggers: []kedav1alpha1.ScaleTriggers{
+				{
+					Type: "cron",
+					Name: "office-hours",
+					Metadata: map[string]string{
+						"timezone":        "Etc/UTC",
+						"start":           "0 8 * * *",
+						"end":             "0 18 * * *",
+						"desiredReplicas": "5",
+					},
+				},
+				{
+					Type:     "unknown",
+					Metadata: map[string]string{},
+				},
+			},
+		},
+	}
+
+	checks, err := CheckTriggers(context.Background(), fake.NewFakeClientWithScheme(scheme, deployment), scaledObject, time.Second)
+	assert.NoError(t, err)
+	assert.Nil(t, scaledObject.Status.ScaleTargetGVKR)
+	assert.Len(t, checks, 2)
+
+	assert.Equal(t, 0, checks[0].Index)
+	assert.Equal(t, "office-hours", checks[0].Name)
+	assert.Equal(t, "cron", checks[0].Type)
+	assert.NoError(t, checks[0].Err)
+	assert.Len(t, checks[0].Metrics, 1)
+
+	assert.Equal(t, 1, checks[1].Index)
+	assert.Equal(t, "unknown", checks[1].Type)
+	assert.Error(t, checks[1].Err)
+}
+
+func TestCheckTriggersMissingScaleTarget(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, kedav1alpha1.AddToScheme(scheme))
+
+	scaledObject := &kedav1alpha1.ScaledObject{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "test"},
+		Spec: kedav1alpha1.ScaledObjectSpec{
+			ScaleTargetRef: &kedav1alpha1.ScaleTarget{Name: "orders"},
+		},
+	}
+
+	_, err := CheckTriggers(context.Background(), fake.NewFakeClientWithScheme(scheme), scaledObject, time.Second)
+	assert.Error(t, err)
+}
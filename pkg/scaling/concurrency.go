@@ -0,0 +1,186 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+
+	"github.com/kedacore/keda/v2/pkg/scalers"
+)
+
+const (
+	// scaleLoopWorkersEnvVar is the number of workers checking the scalers of all scalable objects, 0 means unbounded
+	scaleLoopWorkersEnvVar = "KEDA_SCALE_LOOP_WORKERS"
+	// defaultScaleLoopWorkers bounds the concurrent checks, so thousands of scalable objects don't all poll at once
+	defaultScaleLoopWorkers = 100
+	// scalerMaxConcurrencyEnvVar limits the concurrent requests per scaler type, eg. "kafka=5,prometheus=20"
+	scalerMaxConcurrencyEnvVar = "KEDA_SCALER_MAX_CONCURRENCY"
+)
+
+// workerPool runs tasks on a bounded number of goroutines, a nil workerPool runs tasks on the calling goroutine
+type workerPool struct {
+	tasks chan func()
+}
+
+func newWorkerPool(size int) *workerPool {
+	if size <= 0 {
+		return nil
+	}
+
+	p := &workerPool{tasks: make(chan func())}
+	for i := 0; i < size; i++ {
+		go func() {
+			for task := range p.tasks {
+				task()
+			}
+		}()
+	}
+	return p
+}
+
+// run executes the task on a worker and waits until it is finished,
+// the task is not executed if ctx is done before a worker is available
+func (p *workerPool) run(ctx context.Context, task func()) {
+	if p == nil {
+		task()
+		return
+	}
+
+	done := make(chan struct{})
+	select {
+	case p.tasks <- func() {
+		defer close(done)
+		task()
+	}:
+		<-done
+	case <-ctx.Done():
+	}
+}
+
+// scalerTypeLimits holds a semaphore for each scaler type with a concurrency limit
+type scalerTypeLimits map[string]chan struct{}
+
+func parseScalerTypeLimits(value string) (scalerTypeLimits, error) {
+	limits := scalerTypeLimits{}
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%s is not in the format <scaler type>=<limit>", entry)
+		}
+		limit, err := strconv.Atoi(strings.TrimSpace(parts[1]))
+		if err != nil || limit <= 0 {
+			return nil, fmt.Errorf("limit of %s must be a positive number", parts[0])
+		}
+		limits[strings.TrimSpace(parts[0])] = make(chan struct{}, limit)
+	}
+	return limits, nil
+}
+
+// wrap returns the scaler with IsActive and GetMetrics calls limited by the semaphore of its type
+func (l scalerTypeLimits) wrap(triggerType string, scaler scalers.Scaler) scalers.Scaler {
+	semaphore, ok := l[triggerType]
+	if !ok {
+		return scaler
+	}
+	limited := &limitedScaler{Scaler: scaler, semaphore: semaphore}
+	if pushScaler, ok := scaler.(scalers.PushScaler); ok {
+		return &limitedPushScaler{limitedScaler: limited, pushScaler: pushScaler}
+	}
+	return limited
+}
+
+type limitedScaler struct {
+	scalers.Scaler
+	semaphore chan struct{}
+}
+
+func (s *limitedScaler) acquire(ctx context.Context) error {
+	select {
+	case s.semaphore <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *limitedScaler) release() {
+	<-s.semaphore
+}
+
+func (s *limitedScaler) IsActive(ctx context.Context) (bool, error) {
+	if err := s.acquire(ctx); err != nil {
+		return false, err
+	}
+	defer s.release()
+	return s.Scaler.IsActive(ctx)
+}
+
+func (s *limitedScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	if err := s.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer s.release()
+	return s.Scaler.GetMetrics(ctx, metricName, metricSelector)
+}
+
+// limitedPushScaler doesn't limit Run, it runs for the lifetime of the scaler
+type limitedPushScaler struct {
+	*limitedScaler
+	pushScaler scalers.PushScaler
+}
+
+func (s *limitedPushScaler) Run(ctx context.Context, active chan<- bool) {
+	s.pushScaler.Run(ctx, active)
+}
+
+// resolveConcurrencyConfig reads the worker pool size and the scaler type limits from the environment,
+// invalid values are reported and replaced by the defaults, defaultScaleLoopWorkers and no scaler type limits
+func resolveConcurrencyConfig() (int, scalerTypeLimits, error) {
+	var errs []string
+
+	workers := defaultScaleLoopWorkers
+	if val := os.Getenv(scaleLoopWorkersEnvVar); val != "" {
+		var err error
+		workers, err = strconv.Atoi(val)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("invalid %s: %s", scaleLoopWorkersEnvVar, err))
+			workers = defaultScaleLoopWorkers
+		}
+	}
+
+	limits, err := parseScalerTypeLimits(os.Getenv(scalerMaxConcurrencyEnvVar))
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("invalid %s: %s", scalerMaxConcurrencyEnvVar, err))
+		limits = scalerTypeLimits{}
+	}
+
+	if len(errs) > 0 {
+		return workers, limits, fmt.Errorf("%s", strings.Join(errs, ", "))
+	}
+	return workers, limits, nil
+}
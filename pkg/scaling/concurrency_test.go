@@ -0,0 +1,148 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	mock_scalers "github.com/kedacore/keda/v2/pkg/mock/mock_scaler"
+	"github.com/kedacore/keda/v2/pkg/scalers"
+)
+
+func TestWorkerPoolLimitsConcurrency(t *testing.T) {
+	pool := newWorkerPool(2)
+
+	var running, maxRunning int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			pool.run(context.Background(), func() {
+				current := atomic.AddInt32(&running, 1)
+				for {
+					max := atomic.LoadInt32(&maxRunning)
+					if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+						break
+					}
+				}
+				time.Sleep(10 * time.Millisecond)
+				atomic.AddInt32(&running, -1)
+			})
+		}()
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(2), maxRunning)
+}
+
+func TestWorkerPoolUnbounded(t *testing.T) {
+	pool := newWorkerPool(0)
+	assert.Nil(t, pool)
+
+	executed := false
+	pool.run(context.Background(), func() { executed = true })
+	assert.True(t, executed)
+}
+
+func TestWorkerPoolCanceled(t *testing.T) {
+	pool := newWorkerPool(1)
+	started := make(chan struct{})
+	block := make(chan struct{})
+	go pool.run(context.Background(), func() {
+		close(started)
+		<-block
+	})
+	defer close(block)
+	<-started
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	executed := false
+	pool.run(ctx, func() { executed = true })
+	assert.False(t, executed)
+}
+
+func TestParseScalerTypeLimits(t *testing.T) {
+	limits, err := parseScalerTypeLimits("kafka=5, prometheus=20")
+	assert.NoError(t, err)
+	assert.Equal(t, 5, cap(limits["kafka"]))
+	assert.Equal(t, 20, cap(limits["prometheus"]))
+
+	limits, err = parseScalerTypeLimits("")
+	assert.NoError(t, err)
+	assert.Empty(t, limits)
+
+	_, err = parseScalerTypeLimits("kafka")
+	assert.Error(t, err)
+	_, err = parseScalerTypeLimits("kafka=0")
+	assert.Error(t, err)
+	_, err = parseScalerTypeLimits("kafka=many")
+	assert.Error(t, err)
+}
+
+func TestResolveConcurrencyConfig(t *testing.T) {
+	t.Setenv(scaleLoopWorkersEnvVar, "")
+	t.Setenv(scalerMaxConcurrencyEnvVar, "")
+	workers, _, err := resolveConcurrencyConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, defaultScaleLoopWorkers, workers)
+
+	t.Setenv(scaleLoopWorkersEnvVar, "0")
+	workers, _, err = resolveConcurrencyConfig()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, workers)
+
+	t.Setenv(scaleLoopWorkersEnvVar, "many")
+	workers, _, err = resolveConcurrencyConfig()
+	assert.Error(t, err)
+	assert.Equal(t, defaultScaleLoopWorkers, workers)
+}
+
+func TestScalerTypeLimitsWrap(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	limits, err := parseScalerTypeLimits("kafka=1")
+	assert.NoError(t, err)
+
+	scaler := mock_scalers.NewMockScaler(ctrl)
+	assert.Equal(t, scalers.Scaler(scaler), limits.wrap("prometheus", scaler))
+
+	pushScaler := mock_scalers.NewMockPushScaler(ctrl)
+	_, ok := limits.wrap("kafka", pushScaler).(scalers.PushScaler)
+	assert.True(t, ok)
+
+	// the single slot of kafka is taken, so the call waits until the context is done
+	limits["kafka"] <- struct{}{}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = limits.wrap("kafka", scaler).IsActive(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	<-limits["kafka"]
+	scaler.EXPECT().IsActive(gomock.Any()).Return(true, nil)
+	isActive, err := limits.wrap("kafka", scaler).IsActive(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, isActive)
+	assert.Empty(t, limits["kafka"])
+}
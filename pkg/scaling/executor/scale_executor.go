@@ -19,9 +19,13 @@ package executor
 import (
 	"context"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/scale"
 	"k8s.io/client-go/tools/record"
@@ -29,17 +33,30 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
 )
 
 const (
 	// Default cooldown period for a ScaleTarget if no cooldownPeriod is defined on the scaledObject
 	defaultCooldownPeriod = 5 * 60 // 5 minutes
+
+	// Default number of minutes a ScaleTarget has to be saturated at maxReplicaCount before the AtMaxReplicas condition is set
+	defaultSaturationMinutes = 5
+
+	// statusUpdateIntervalEnvVar is the minimum number of seconds between two writes of lastActiveTime of a scalable object
+	statusUpdateIntervalEnvVar  = "KEDA_STATUS_UPDATE_INTERVAL"
+	defaultStatusUpdateInterval = 60
+
+	// lastActiveTimeFieldOwner is the field manager owning lastActiveTime, it is written with server-side apply
+	lastActiveTimeFieldOwner = "keda-operator-last-active-time"
 )
 
-// ScaleExecutor contains methods RequestJobScale and RequestScale
+// ScaleExecutor contains methods RequestJobScale, RequestScale, RequestDirectScale and RequestAtMaxReplicasCheck
 type ScaleExecutor interface {
-	RequestJobScale(ctx context.Context, scaledJob *kedav1alpha1.ScaledJob, isActive bool, scaleTo int64, maxScale int64)
+	RequestJobScale(ctx context.Context, scaledJob *kedav1alpha1.ScaledJob, isActive bool, scaleTo int64, maxScale int64, trigger string, jobParameters JobParametersFunc)
 	RequestScale(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject, isActive bool, isError bool)
+	RequestDirectScale(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject, replicas int32)
+	RequestAtMaxReplicasCheck(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject, hpa *autoscalingv2beta2.HorizontalPodAutoscaler)
 }
 
 type scaleExecutor struct {
@@ -48,44 +65,123 @@ type scaleExecutor struct {
 	reconcilerScheme *runtime.Scheme
 	logger           logr.Logger
 	recorder         record.EventRecorder
+	// statusUpdateInterval rate limits the writes of lastActiveTime, it is refreshed on every poll
+	// while the triggers are active but only needs to be written well within the cooldownPeriod
+	statusUpdateInterval time.Duration
+	// unwrittenLastActiveTimes are the lastActiveTimes of ScaledObjects whose write was skipped by the status update
+	// interval, by namespace/name. They are written when the triggers become inactive, so the cooldownPeriod is
+	// counted from the last time the triggers were active
+	unwrittenLastActiveTimes sync.Map
 }
 
 // NewScaleExecutor creates a ScaleExecutor object
 func NewScaleExecutor(client runtimeclient.Client, scaleClient scale.ScalesGetter, reconcilerScheme *runtime.Scheme, recorder record.EventRecorder) ScaleExecutor {
+	logger := logf.Log.WithName("scaleexecutor")
+	statusUpdateInterval, err := kedautil.ResolveOsEnvInt(statusUpdateIntervalEnvVar, defaultStatusUpdateInterval)
+	if err != nil {
+		logger.Error(err, "Invalid "+statusUpdateIntervalEnvVar+", using the default", "default", defaultStatusUpdateInterval)
+		statusUpdateInterval = defaultStatusUpdateInterval
+	}
+
 	return &scaleExecutor{
-		client:           client,
-		scaleClient:      scaleClient,
-		reconcilerScheme: reconcilerScheme,
-		logger:           logf.Log.WithName("scaleexecutor"),
-		recorder:         recorder,
+		client:               client,
+		scaleClient:          scaleClient,
+		reconcilerScheme:     reconcilerScheme,
+		logger:               logger,
+		recorder:             recorder,
+		statusUpdateInterval: time.Duration(statusUpdateInterval) * time.Second,
 	}
 }
 
+// updateLastActiveTime sets lastActiveTime of the object to now, the write to the API server is skipped
+// while the stored lastActiveTime is more recent than the status update interval
 func (e *scaleExecutor) updateLastActiveTime(ctx context.Context, logger logr.Logger, object interface{}) error {
-	var patch runtimeclient.Patch
+	var previous *metav1.Time
+	var kind string
 
 	now := metav1.Now()
+	interval := e.statusUpdateInterval
 	runtimeObj := object.(runtimeclient.Object)
 	switch obj := runtimeObj.(type) {
 	case *kedav1alpha1.ScaledObject:
-		patch = runtimeclient.MergeFrom(obj.DeepCopy())
+		previous = obj.Status.LastActiveTime
 		obj.Status.LastActiveTime = &now
+		kind = "ScaledObject"
+
+		// the stored lastActiveTime must not get close to the cooldownPeriod while the triggers are active
+		cooldownPeriod := time.Second * time.Duration(defaultCooldownPeriod)
+		if obj.Spec.CooldownPeriod != nil {
+			cooldownPeriod = time.Second * time.Duration(*obj.Spec.CooldownPeriod)
+		}
+		if cooldownPeriod/2 < interval {
+			interval = cooldownPeriod / 2
+		}
 	case *kedav1alpha1.ScaledJob:
-		patch = runtimeclient.MergeFrom(obj.DeepCopy())
+		previous = obj.Status.LastActiveTime
 		obj.Status.LastActiveTime = &now
+		kind = "ScaledJob"
 	default:
 		err := fmt.Errorf("unknown scalable object type %v", obj)
 		logger.Error(err, "Failed to patch Objects Status")
 		return err
 	}
 
-	err := e.client.Status().Patch(ctx, runtimeObj, patch)
+	key := lastActiveTimeKey(runtimeObj)
+	if previous != nil && now.Sub(previous.Time) < interval {
+		if kind == "ScaledObject" {
+			e.unwrittenLastActiveTimes.Store(key, now)
+		}
+		return nil
+	}
+
+	err := e.patchLastActiveTime(ctx, logger, kind, runtimeObj, now)
+	if err == nil {
+		e.unwrittenLastActiveTimes.Delete(key)
+	}
+	return err
+}
+
+// flushLastActiveTime writes the lastActiveTime of a ScaledObject whose triggers just became inactive, when its last
+// write was skipped by the status update interval. An active ScaledObject without a known lastActiveTime, eg. after
+// a restart of the operator, gets now, so the cooldownPeriod is never shortened by the skipped writes
+func (e *scaleExecutor) flushLastActiveTime(ctx context.Context, logger logr.Logger, scaledObject *kedav1alpha1.ScaledObject) error {
+	key := lastActiveTimeKey(scaledObject)
+	lastActiveTime := metav1.Now()
+	if unwritten, ok := e.unwrittenLastActiveTimes.Load(key); ok {
+		lastActiveTime = unwritten.(metav1.Time)
+	}
+
+	if err := e.patchLastActiveTime(ctx, logger, "ScaledObject", scaledObject, lastActiveTime); err != nil {
+		return err
+	}
+	e.unwrittenLastActiveTimes.Delete(key)
+	scaledObject.Status.LastActiveTime = &lastActiveTime
+	return nil
+}
+
+// patchLastActiveTime writes lastActiveTime of the object, only lastActiveTime is applied so the write
+// doesn't conflict with the other status updates
+func (e *scaleExecutor) patchLastActiveTime(ctx context.Context, logger logr.Logger, kind string, object runtimeclient.Object, lastActiveTime metav1.Time) error {
+	applied := &unstructured.Unstructured{}
+	applied.SetAPIVersion(kedav1alpha1.GroupVersion.String())
+	applied.SetKind(kind)
+	applied.SetName(object.GetName())
+	applied.SetNamespace(object.GetNamespace())
+	applied.Object["status"] = map[string]interface{}{
+		"lastActiveTime": lastActiveTime.UTC().Format(time.RFC3339),
+	}
+
+	err := e.client.Status().Patch(ctx, applied, runtimeclient.Apply, runtimeclient.FieldOwner(lastActiveTimeFieldOwner), runtimeclient.ForceOwnership)
 	if err != nil {
 		logger.Error(err, "Failed to patch Objects Status")
 	}
 	return err
 }
 
+func lastActiveTimeKey(object runtimeclient.Object) string {
+	return object.GetNamespace() + "/" + object.GetName()
+}
+
 func (e *scaleExecutor) setCondition(ctx context.Context, logger logr.Logger, object interface{}, status metav1.ConditionStatus, reason string, message string, setCondition func(kedav1alpha1.Conditions, metav1.ConditionStatus, string, string)) error {
 	var patch runtimeclient.Patch
 
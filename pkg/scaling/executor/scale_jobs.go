@@ -25,20 +25,29 @@ import (
 	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
 
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	"github.com/kedacore/keda/v2/pkg/eventreason"
+	"github.com/kedacore/keda/v2/pkg/scalers"
 	version "github.com/kedacore/keda/v2/version"
 )
 
 const (
 	defaultSuccessfulJobsHistoryLimit = int32(100)
 	defaultFailedJobsHistoryLimit     = int32(100)
+
+	// scaledJobTriggerLabel holds the trigger that caused the creation of a Job, the history limits are applied per trigger
+	scaledJobTriggerLabel = "scaledjob.keda.sh/trigger"
 )
 
-func (e *scaleExecutor) RequestJobScale(ctx context.Context, scaledJob *kedav1alpha1.ScaledJob, isActive bool, scaleTo int64, maxScale int64) {
+// JobParametersFunc returns the parameters of up to count Jobs of a ScaledJob with jobParameters, running are the
+// parameters of its Jobs that are still running
+type JobParametersFunc func(ctx context.Context, count int64, running []scalers.JobParameters) ([]scalers.JobParameters, error)
+
+func (e *scaleExecutor) RequestJobScale(ctx context.Context, scaledJob *kedav1alpha1.ScaledJob, isActive bool, scaleTo int64, maxScale int64, trigger string, jobParameters JobParametersFunc) {
 	logger := e.logger.WithValues("scaledJob.Name", scaledJob.Name, "scaledJob.Namespace", scaledJob.Namespace)
 
 	runningJobCount := e.getRunningJobCount(ctx, scaledJob)
@@ -54,13 +63,11 @@ func (e *scaleExecutor) RequestJobScale(ctx context.Context, scaledJob *kedav1al
 
 	if isActive {
 		logger.V(1).Info("At least one scaler is active")
-		now := metav1.Now()
-		scaledJob.Status.LastActiveTime = &now
 		err := e.updateLastActiveTime(ctx, logger, scaledJob)
 		if err != nil {
 			logger.Error(err, "Failed to update last active time")
 		}
-		e.createJobs(ctx, logger, scaledJob, scaleTo, effectiveMaxScale)
+		e.createJobs(ctx, logger, scaledJob, scaleTo, effectiveMaxScale, trigger, jobParameters)
 	} else {
 		logger.V(1).Info("No change in activity")
 	}
@@ -86,7 +93,7 @@ func (e *scaleExecutor) RequestJobScale(ctx context.Context, scaledJob *kedav1al
 	}
 }
 
-func (e *scaleExecutor) createJobs(ctx context.Context, logger logr.Logger, scaledJob *kedav1alpha1.ScaledJob, scaleTo int64, maxScale int64) {
+func (e *scaleExecutor) createJobs(ctx context.Context, logger logr.Logger, scaledJob *kedav1alpha1.ScaledJob, scaleTo int64, maxScale int64, trigger string, jobParameters JobParametersFunc) {
 	scaledJob.Spec.JobTargetRef.Template.GenerateName = scaledJob.GetName() + "-"
 	if scaledJob.Spec.JobTargetRef.Template.Labels == nil {
 		scaledJob.Spec.JobTargetRef.Template.Labels = map[string]string{}
@@ -100,6 +107,27 @@ func (e *scaleExecutor) createJobs(ctx context.Context, logger logr.Logger, scal
 	}
 	logger.Info("Creating jobs", "Number of jobs", scaleTo)
 
+	var parameters []scalers.JobParameters
+	if jobParameters != nil && scaleTo > 0 {
+		running, err := e.getRunningJobParameters(ctx, scaledJob)
+		if err != nil {
+			logger.Error(err, "Failed to get the parameters of the running jobs")
+			e.recorder.Event(scaledJob, corev1.EventTypeWarning, eventreason.KEDAJobParametersFailed, err.Error())
+			return
+		}
+		parameters, err = jobParameters(ctx, scaleTo, running)
+		if err != nil {
+			logger.Error(err, "Failed to get the parameters of the jobs")
+			e.recorder.Event(scaledJob, corev1.EventTypeWarning, eventreason.KEDAJobParametersFailed, err.Error())
+			return
+		}
+		// the trigger may split the work between fewer jobs, a job without parameters would have no work
+		if int64(len(parameters)) < scaleTo {
+			scaleTo = int64(len(parameters))
+			logger.Info("Creating jobs", "Number of jobs with parameters", scaleTo)
+		}
+	}
+
 	labels := map[string]string{
 		"app.kubernetes.io/name":       scaledJob.GetName(),
 		"app.kubernetes.io/version":    version.Version,
@@ -110,6 +138,13 @@ func (e *scaleExecutor) createJobs(ctx context.Context, logger logr.Logger, scal
 	for key, value := range scaledJob.ObjectMeta.Labels {
 		labels[key] = value
 	}
+	if trigger != "" {
+		if errs := validation.IsValidLabelValue(trigger); len(errs) == 0 {
+			labels[scaledJobTriggerLabel] = trigger
+		} else {
+			logger.V(1).Info("Trigger is not a valid label value, not labeling jobs with it", "trigger", trigger, "errors", errs)
+		}
+	}
 
 	for i := 0; i < int(scaleTo); i++ {
 		job := &batchv1.Job{
@@ -120,6 +155,9 @@ func (e *scaleExecutor) createJobs(ctx context.Context, logger logr.Logger, scal
 			},
 			Spec: *scaledJob.Spec.JobTargetRef.DeepCopy(),
 		}
+		if parameters != nil {
+			applyJobParameters(job, parameters[i], scaledJob.Spec.JobParameters)
+		}
 
 		// Job doesn't allow RestartPolicyAlways, it seems like this value is set by the client as a default one,
 		// we should set this property to allowed value in that case
@@ -143,6 +181,67 @@ func (e *scaleExecutor) createJobs(ctx context.Context, logger logr.Logger, scal
 	e.recorder.Eventf(scaledJob, corev1.EventTypeNormal, eventreason.KEDAJobsCreated, "Created %d jobs", scaleTo)
 }
 
+// applyJobParameters adds the environment variables of the parameters to the containers of the Job and the
+// annotations to the Job and its pods, the variables are sorted by name so the pod spec is stable
+func applyJobParameters(job *batchv1.Job, parameters scalers.JobParameters, spec *kedav1alpha1.JobParameters) {
+	containerName := ""
+	if spec != nil {
+		containerName = spec.ContainerName
+	}
+
+	names := make([]string, 0, len(parameters.Env))
+	for name := range parameters.Env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for i := range job.Spec.Template.Spec.Containers {
+		container := &job.Spec.Template.Spec.Containers[i]
+		if containerName != "" && container.Name != containerName {
+			continue
+		}
+		for _, name := range names {
+			container.Env = append(container.Env, corev1.EnvVar{Name: name, Value: parameters.Env[name]})
+		}
+	}
+
+	if len(parameters.Annotations) == 0 {
+		return
+	}
+	if job.Annotations == nil {
+		job.Annotations = map[string]string{}
+	}
+	if job.Spec.Template.Annotations == nil {
+		job.Spec.Template.Annotations = map[string]string{}
+	}
+	for key, value := range parameters.Annotations {
+		job.Annotations[key] = value
+		job.Spec.Template.Annotations[key] = value
+	}
+}
+
+// getRunningJobParameters returns the annotations of the Jobs of the ScaledJob that aren't finished, so the work
+// they are still doing isn't handed out to the new Jobs
+func (e *scaleExecutor) getRunningJobParameters(ctx context.Context, scaledJob *kedav1alpha1.ScaledJob) ([]scalers.JobParameters, error) {
+	opts := []client.ListOption{
+		client.InNamespace(scaledJob.GetNamespace()),
+		client.MatchingLabels(map[string]string{"scaledjob.keda.sh/name": scaledJob.GetName()}),
+	}
+
+	jobs := &batchv1.JobList{}
+	if err := e.client.List(ctx, jobs, opts...); err != nil {
+		return nil, err
+	}
+
+	var running []scalers.JobParameters
+	for _, job := range jobs.Items {
+		job := job
+		if !e.isJobFinished(&job) {
+			running = append(running, scalers.JobParameters{Annotations: job.Annotations})
+		}
+	}
+	return running, nil
+}
+
 func (e *scaleExecutor) isJobFinished(j *batchv1.Job) bool {
 	for _, c := range j.Status.Conditions {
 		if (c.Type == batchv1.JobComplete || c.Type == batchv1.JobFailed) && c.Status == corev1.ConditionTrue {
@@ -276,22 +375,6 @@ func (e *scaleExecutor) cleanUp(ctx context.Context, scaledJob *kedav1alpha1.Sca
 		return err
 	}
 
-	completedJobs := []batchv1.Job{}
-	failedJobs := []batchv1.Job{}
-	for _, job := range jobs.Items {
-		job := job
-		finishedJobConditionType := e.getFinishedJobConditionType(&job)
-		switch finishedJobConditionType {
-		case batchv1.JobComplete:
-			completedJobs = append(completedJobs, job)
-		case batchv1.JobFailed:
-			failedJobs = append(failedJobs, job)
-		}
-	}
-
-	sort.Sort(byCompletedTime(completedJobs))
-	sort.Sort(byCompletedTime(failedJobs))
-
 	successfulJobsHistoryLimit := defaultSuccessfulJobsHistoryLimit
 	failedJobsHistoryLimit := defaultFailedJobsHistoryLimit
 
@@ -303,13 +386,35 @@ func (e *scaleExecutor) cleanUp(ctx context.Context, scaledJob *kedav1alpha1.Sca
 		failedJobsHistoryLimit = *scaledJob.Spec.FailedJobsHistoryLimit
 	}
 
-	err = e.deleteJobsWithHistoryLimit(ctx, logger, completedJobs, successfulJobsHistoryLimit)
-	if err != nil {
-		return err
+	// the history limits are applied per trigger, so that the jobs of a busy trigger
+	// don't remove the history of the other triggers, jobs without a trigger label form their own group
+	completedJobs := map[string][]batchv1.Job{}
+	failedJobs := map[string][]batchv1.Job{}
+	for _, job := range jobs.Items {
+		job := job
+		trigger := job.Labels[scaledJobTriggerLabel]
+		finishedJobConditionType := e.getFinishedJobConditionType(&job)
+		switch finishedJobConditionType {
+		case batchv1.JobComplete:
+			completedJobs[trigger] = append(completedJobs[trigger], job)
+		case batchv1.JobFailed:
+			failedJobs[trigger] = append(failedJobs[trigger], job)
+		}
 	}
-	err = e.deleteJobsWithHistoryLimit(ctx, logger, failedJobs, failedJobsHistoryLimit)
-	if err != nil {
-		return err
+
+	for _, triggerJobs := range completedJobs {
+		sort.Sort(byCompletedTime(triggerJobs))
+		err = e.deleteJobsWithHistoryLimit(ctx, logger, triggerJobs, successfulJobsHistoryLimit)
+		if err != nil {
+			return err
+		}
+	}
+	for _, triggerJobs := range failedJobs {
+		sort.Sort(byCompletedTime(triggerJobs))
+		err = e.deleteJobsWithHistoryLimit(ctx, logger, triggerJobs, failedJobsHistoryLimit)
+		if err != nil {
+			return err
+		}
 	}
 	return nil
 }
@@ -329,7 +434,7 @@ func (e *scaleExecutor) deleteJobsWithHistoryLimit(ctx context.Context, logger l
 		if err != nil {
 			return err
 		}
-		logger.Info("Remove a job by reaching the historyLimit", "job.Name", j.ObjectMeta.Name, "historyLimit", historyLimit)
+		logger.Info("Remove a job by reaching the historyLimit", "job.Name", j.ObjectMeta.Name, "trigger", j.Labels[scaledJobTriggerLabel], "historyLimit", historyLimit)
 	}
 	return nil
 }
@@ -28,11 +28,13 @@ import (
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	"github.com/kedacore/keda/v2/pkg/mock/mock_client"
+	"github.com/kedacore/keda/v2/pkg/scalers"
 )
 
 func TestCleanUpNormalCase(t *testing.T) {
@@ -171,6 +173,43 @@ func TestCleanUpMixedCaseWithSortByTime(t *testing.T) {
 	assert.True(t, ok)
 }
 
+func TestCleanUpPerTrigger(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	// Setup ScaledJob
+	// successfulJobHistoryLimit = 1
+	// failedJobHistoryLimit = 1
+	scaledJob := getMockScaledJob(1, 1)
+
+	var actualDeletedJobName = make(map[string]string)
+
+	// Setup current running jobs, the limits are applied to each trigger and to the jobs without a trigger
+	client := getMockClient(t, ctrl, &[]mockJobParameter{
+		{Name: "queue-success1", CompletionTime: "2020-07-29T15:37:00Z", JobConditionType: batchv1.JobComplete, Trigger: "queue"},
+		{Name: "queue-success2", CompletionTime: "2020-07-29T15:36:00Z", JobConditionType: batchv1.JobComplete, Trigger: "queue"},
+		{Name: "cron-success1", CompletionTime: "2020-07-29T15:30:00Z", JobConditionType: batchv1.JobComplete, Trigger: "cron"},
+		{Name: "queue-fail1", CompletionTime: "2020-07-29T15:37:00Z", JobConditionType: batchv1.JobFailed, Trigger: "queue"},
+		{Name: "cron-fail1", CompletionTime: "2020-07-29T15:35:00Z", JobConditionType: batchv1.JobFailed, Trigger: "cron"},
+		{Name: "cron-fail2", CompletionTime: "2020-07-29T15:36:00Z", JobConditionType: batchv1.JobFailed, Trigger: "cron"},
+		{Name: "success1", CompletionTime: "2020-07-29T15:35:00Z", JobConditionType: batchv1.JobComplete},
+	}, &actualDeletedJobName)
+
+	scaleExecutor := getMockScaleExecutor(client)
+
+	err := scaleExecutor.cleanUp(ctx, scaledJob)
+	if err != nil {
+		t.Errorf("Unable to cleanup as: %v", err)
+		return
+	}
+	assert.Equal(t, 2, len(actualDeletedJobName))
+	_, ok := actualDeletedJobName["queue-success2"]
+	assert.True(t, ok)
+	_, ok = actualDeletedJobName["cron-fail1"]
+	assert.True(t, ok)
+}
+
 func TestCleanUpDefaultValue(t *testing.T) {
 	ctx := context.Background()
 	ctrl := gomock.NewController(t)
@@ -248,6 +287,7 @@ type mockJobParameter struct {
 	Name             string
 	CompletionTime   string
 	JobConditionType batchv1.JobConditionType
+	Trigger          string
 }
 
 type pendingJobTestData struct {
@@ -256,6 +296,87 @@ type pendingJobTestData struct {
 	PendingJobCount      int64
 }
 
+func TestCreateJobsWithJobParameters(t *testing.T) {
+	ctx := context.Background()
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	scheme := runtime.NewScheme()
+	assert.NoError(t, kedav1alpha1.AddToScheme(scheme))
+	scaledJob := getMockScaledJobWithDefault()
+	scaledJob.Spec.JobParameters = &kedav1alpha1.JobParameters{ContainerName: "consumer"}
+	scaledJob.Spec.JobTargetRef = &batchv1.JobSpec{
+		Template: v1.PodTemplateSpec{
+			Spec: v1.PodSpec{
+				Containers: []v1.Container{{Name: "consumer"}, {Name: "sidecar"}},
+			},
+		},
+	}
+
+	var created []*batchv1.Job
+	client := mock_client.NewMockClient(ctrl)
+	runningJob := batchv1.Job{ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"kafka.keda.sh/partitions": "3"}}}
+	finishedJob := batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{Annotations: map[string]string{"kafka.keda.sh/partitions": "4"}},
+		Status:     batchv1.JobStatus{Conditions: []batchv1.JobCondition{{Type: batchv1.JobComplete, Status: v1.ConditionTrue}}},
+	}
+	client.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any()).SetArg(1, batchv1.JobList{Items: []batchv1.Job{runningJob, finishedJob}})
+	client.EXPECT().Create(gomock.Any(), gomock.Any()).DoAndReturn(func(_ context.Context, obj runtimeclient.Object, _ ...runtimeclient.CreateOption) error {
+		created = append(created, obj.(*batchv1.Job))
+		return nil
+	}).Times(2)
+	scaleExecutor := getMockScaleExecutor(client)
+	scaleExecutor.reconcilerScheme = scheme
+	scaleExecutor.recorder = record.NewFakeRecorder(10)
+
+	var requested int64
+	var running []scalers.JobParameters
+	jobParameters := func(_ context.Context, count int64, runningJobs []scalers.JobParameters) ([]scalers.JobParameters, error) {
+		requested = count
+		running = runningJobs
+		return []scalers.JobParameters{
+			{Env: map[string]string{"PARTITIONS": "0,2", "TOPIC": "orders"}, Annotations: map[string]string{"kafka.keda.sh/partitions": "0,2"}},
+			{Env: map[string]string{"PARTITIONS": "1", "TOPIC": "orders"}},
+		}, nil
+	}
+	scaleExecutor.createJobs(ctx, logf.Log, scaledJob, 3, 5, "kafka", jobParameters)
+
+	// the trigger split the work between 2 jobs, it got the parameters of the running jobs
+	assert.Equal(t, int64(3), requested)
+	assert.Equal(t, []scalers.JobParameters{{Annotations: runningJob.Annotations}}, running)
+	assert.Len(t, created, 2)
+	assert.Equal(t, []v1.EnvVar{{Name: "PARTITIONS", Value: "0,2"}, {Name: "TOPIC", Value: "orders"}}, created[0].Spec.Template.Spec.Containers[0].Env)
+	assert.Empty(t, created[0].Spec.Template.Spec.Containers[1].Env)
+	assert.Equal(t, "0,2", created[0].Annotations["kafka.keda.sh/partitions"])
+	assert.Equal(t, "0,2", created[0].Spec.Template.Annotations["kafka.keda.sh/partitions"])
+	assert.Equal(t, []v1.EnvVar{{Name: "PARTITIONS", Value: "1"}, {Name: "TOPIC", Value: "orders"}}, created[1].Spec.Template.Spec.Containers[0].Env)
+	assert.Empty(t, created[1].Annotations)
+	// the template of the ScaledJob isn't changed
+	assert.Empty(t, scaledJob.Spec.JobTargetRef.Template.Spec.Containers[0].Env)
+}
+
+func TestCreateJobsWithFailingJobParameters(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	scaledJob := getMockScaledJobWithDefault()
+	scaledJob.Spec.JobParameters = &kedav1alpha1.JobParameters{}
+	scaledJob.Spec.JobTargetRef = &batchv1.JobSpec{}
+
+	// no jobs are created without their parameters
+	client := mock_client.NewMockClient(ctrl)
+	client.EXPECT().List(gomock.Any(), gomock.Any(), gomock.Any())
+	scaleExecutor := getMockScaleExecutor(client)
+	recorder := record.NewFakeRecorder(10)
+	scaleExecutor.recorder = recorder
+
+	jobParameters := func(context.Context, int64, []scalers.JobParameters) ([]scalers.JobParameters, error) {
+		return nil, fmt.Errorf("broker not available")
+	}
+	scaleExecutor.createJobs(context.Background(), logf.Log, scaledJob, 3, 5, "kafka", jobParameters)
+	assert.Len(t, recorder.Events, 1)
+}
+
 func getMockScaleExecutor(client *mock_client.MockClient) *scaleExecutor {
 	return &scaleExecutor{
 		client:           client,
@@ -340,7 +461,11 @@ func getMockClient(t *testing.T, ctrl *gomock.Controller, jobs *[]mockJobParamet
 		j, ok := list.(*batchv1.JobList)
 		if ok {
 			for _, job := range *jobs {
-				j.Items = append(j.Items, *getJob(t, job.Name, job.CompletionTime, job.JobConditionType))
+				mockJob := getJob(t, job.Name, job.CompletionTime, job.JobConditionType)
+				if job.Trigger != "" {
+					mockJob.Labels = map[string]string{scaledJobTriggerLabel: job.Trigger}
+				}
+				j.Items = append(j.Items, *mockJob)
 			}
 		}
 	}).
@@ -18,19 +18,27 @@ package executor
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	"github.com/kedacore/keda/v2/pkg/eventreason"
+	"github.com/kedacore/keda/v2/pkg/metrics"
 )
 
+// RequestScale activates or deactivates the ScaleTarget, isActive doesn't take the cpu/memory triggers into account
+// when the ScaledObject has other triggers: they only drive the HPA while the ScaleTarget has replicas, so the
+// ScaleTarget is scaled to zero when the other triggers are not active, whatever its cpu or memory usage
 func (e *scaleExecutor) RequestScale(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject, isActive bool, isError bool) {
 	logger := e.logger.WithValues("scaledobject.Name", scaledObject.Name,
 		"scaledObject.Namespace", scaledObject.Namespace,
@@ -101,6 +109,13 @@ func (e *scaleExecutor) RequestScale(ctx context.Context, scaledObject *kedav1al
 		}
 	} else {
 		// isActive == false
+		if activeCondition := scaledObject.Status.Conditions.GetActiveCondition(); activeCondition.IsTrue() {
+			// the triggers just became inactive, the cooldownPeriod starts from their last active time
+			if err := e.flushLastActiveTime(ctx, logger, scaledObject); err != nil {
+				logger.Error(err, "Error updating last active time")
+				return
+			}
+		}
 		switch {
 		case isError && scaledObject.Spec.Fallback != nil && scaledObject.Spec.Fallback.Replicas != 0:
 			// there are no active triggers, but a scaler responded with an error
@@ -129,8 +144,8 @@ func (e *scaleExecutor) RequestScale(ctx context.Context, scaledObject *kedav1al
 			// Idle Replicas mode is disabled
 
 			// ScaleTarget replicas count to correct value
-			_, err := e.updateScaleOnScaleTarget(ctx, scaledObject, currentScale, *scaledObject.Spec.MinReplicaCount)
-			if err == nil {
+			_, dryRun, err := e.updateScaleOnScaleTarget(ctx, scaledObject, currentScale, *scaledObject.Spec.MinReplicaCount)
+			if err == nil && !dryRun {
 				logger.Info("Successfully set ScaleTarget replicas count to ScaledObject minReplicaCount",
 					"Original Replicas Count", currentReplicas,
 					"New Replicas Count", *scaledObject.Spec.MinReplicaCount)
@@ -160,7 +175,10 @@ func (e *scaleExecutor) RequestScale(ctx context.Context, scaledObject *kedav1al
 }
 
 func (e *scaleExecutor) doFallbackScaling(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject, currentScale *autoscalingv1.Scale, logger logr.Logger, currentReplicas int32) {
-	_, err := e.updateScaleOnScaleTarget(ctx, scaledObject, currentScale, scaledObject.Spec.Fallback.Replicas)
+	_, dryRun, err := e.updateScaleOnScaleTarget(ctx, scaledObject, currentScale, scaledObject.Spec.Fallback.Replicas)
+	if dryRun {
+		return
+	}
 	if err == nil {
 		logger.Info("Successfully set ScaleTarget replicas count to ScaledObject fallback.replicas",
 			"Original Replicas Count", currentReplicas,
@@ -190,7 +208,10 @@ func (e *scaleExecutor) scaleToZeroOrIdle(ctx context.Context, logger logr.Logge
 
 		idleValue, scaleToReplicas := getIdleOrMinimumReplicaCount(scaledObject)
 
-		currentReplicas, err := e.updateScaleOnScaleTarget(ctx, scaledObject, scale, scaleToReplicas)
+		currentReplicas, dryRun, err := e.updateScaleOnScaleTarget(ctx, scaledObject, scale, scaleToReplicas)
+		if dryRun {
+			return
+		}
 		if err == nil {
 			msg := "Successfully set ScaleTarget replicas count to ScaledObject"
 			if idleValue {
@@ -233,7 +254,10 @@ func (e *scaleExecutor) scaleFromZeroOrIdle(ctx context.Context, logger logr.Log
 		replicas = 1
 	}
 
-	currentReplicas, err := e.updateScaleOnScaleTarget(ctx, scaledObject, scale, replicas)
+	currentReplicas, dryRun, err := e.updateScaleOnScaleTarget(ctx, scaledObject, scale, replicas)
+	if dryRun {
+		return
+	}
 
 	if err == nil {
 		logger.Info("Successfully updated ScaleTarget",
@@ -251,26 +275,143 @@ func (e *scaleExecutor) scaleFromZeroOrIdle(ctx context.Context, logger logr.Log
 	}
 }
 
+// RequestDirectScale sets the replica count of the ScaleTarget to the passed value, it is used
+// instead of the HPA for ScaledObjects with directScalingFallback while the HPA can't get metrics
+func (e *scaleExecutor) RequestDirectScale(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject, replicas int32) {
+	logger := e.logger.WithValues("scaledobject.Name", scaledObject.Name,
+		"scaledObject.Namespace", scaledObject.Namespace,
+		"scaleTarget.Name", scaledObject.Spec.ScaleTargetRef.Name)
+
+	currentScale, err := e.getScaleTargetScale(ctx, scaledObject)
+	if err != nil {
+		logger.Error(err, "Error getting information on the current Scale (ie. replicas count) on the scaleTarget")
+		return
+	}
+	currentReplicas := currentScale.Spec.Replicas
+	if currentReplicas == replicas {
+		return
+	}
+
+	_, dryRun, err := e.updateScaleOnScaleTarget(ctx, scaledObject, currentScale, replicas)
+	if err != nil {
+		logger.Error(err, "Error setting ScaleTarget replicas count directly")
+		return
+	}
+	if dryRun {
+		return
+	}
+	logger.Info("Successfully set ScaleTarget replicas count directly, HPA is not able to get metrics",
+		"Original Replicas Count", currentReplicas,
+		"New Replicas Count", replicas)
+	e.recorder.Eventf(scaledObject, corev1.EventTypeNormal, eventreason.KEDAScaleTargetDirectlyScaled, "Scaled %s %s/%s from %d to %d because HPA is not able to get metrics", scaledObject.Status.ScaleTargetKind, scaledObject.Namespace, scaledObject.Spec.ScaleTargetRef.Name, currentReplicas, replicas)
+}
+
+// RequestAtMaxReplicasCheck sets the AtMaxReplicas condition and metric of the ScaledObject, the ScaleTarget is saturated
+// when the HPA has been at maxReplicaCount while the metrics ask for more replicas for the saturation window.
+// A nil HPA, eg. not created yet, isn't saturated
+func (e *scaleExecutor) RequestAtMaxReplicasCheck(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject, hpa *autoscalingv2beta2.HorizontalPodAutoscaler) {
+	logger := e.logger.WithValues("scaledobject.Name", scaledObject.Name,
+		"scaledObject.Namespace", scaledObject.Namespace,
+		"scaleTarget.Name", scaledObject.Spec.ScaleTargetRef.Name)
+
+	window := time.Duration(defaultSaturationMinutes) * time.Minute
+	if scaledObject.Spec.Advanced != nil && scaledObject.Spec.Advanced.SaturationMinutes != nil {
+		window = time.Duration(*scaledObject.Spec.Advanced.SaturationMinutes) * time.Minute
+	}
+	since, limited := hpaLimitedAtMaxReplicasSince(hpa)
+	saturated := limited && time.Since(since) >= window
+	metrics.RecordScaleTargetAtMaxReplicas(scaledObject.Namespace, scaledObject.Name, saturated)
+
+	condition := scaledObject.Status.Conditions.GetAtMaxReplicasCondition()
+	if !condition.IsUnknown() && condition.IsTrue() == saturated {
+		return
+	}
+
+	patch := client.MergeFrom(scaledObject.DeepCopy())
+	if saturated {
+		message := fmt.Sprintf("ScaleTarget is at maxReplicaCount %d with the metrics above target since %s", hpa.Spec.MaxReplicas, since.UTC().Format(time.RFC3339))
+		scaledObject.Status.Conditions.SetAtMaxReplicasCondition(metav1.ConditionTrue, "MaxReplicasSaturated", message)
+		e.recorder.Event(scaledObject, corev1.EventTypeWarning, eventreason.KEDAScaleTargetAtMaxReplicas, message)
+	} else {
+		scaledObject.Status.Conditions.SetAtMaxReplicasCondition(metav1.ConditionFalse, "NotSaturated", "ScaleTarget is below maxReplicaCount or the metrics are within target")
+	}
+	if err := e.client.Status().Patch(ctx, scaledObject, patch); err != nil {
+		logger.Error(err, "Error setting AtMaxReplicas condition")
+	}
+}
+
+// hpaLimitedAtMaxReplicasSince returns since when the HPA is at maxReplicas and limited by it,
+// the HPA sets the ScalingLimited condition with reason TooManyReplicas when the desired replicas are above maxReplicas
+func hpaLimitedAtMaxReplicasSince(hpa *autoscalingv2beta2.HorizontalPodAutoscaler) (time.Time, bool) {
+	if hpa == nil || hpa.Status.CurrentReplicas < hpa.Spec.MaxReplicas {
+		return time.Time{}, false
+	}
+	for _, condition := range hpa.Status.Conditions {
+		if condition.Type == autoscalingv2beta2.ScalingLimited {
+			if condition.Status == corev1.ConditionTrue && condition.Reason == "TooManyReplicas" {
+				return condition.LastTransitionTime.Time, true
+			}
+			return time.Time{}, false
+		}
+	}
+	return time.Time{}, false
+}
+
 func (e *scaleExecutor) getScaleTargetScale(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject) (*autoscalingv1.Scale, error) {
 	return e.scaleClient.Scales(scaledObject.Namespace).Get(ctx, scaledObject.Status.ScaleTargetGVKR.GroupResource(), scaledObject.Spec.ScaleTargetRef.Name, metav1.GetOptions{})
 }
 
-func (e *scaleExecutor) updateScaleOnScaleTarget(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject, scale *autoscalingv1.Scale, replicas int32) (int32, error) {
-	if scale == nil {
-		// Wasn't retrieved earlier, grab it now.
-		var err error
-		scale, err = e.getScaleTargetScale(ctx, scaledObject)
-		if err != nil {
-			return -1, err
-		}
+// updateScaleOnScaleTarget sets the replicas of the ScaleTarget and returns its previous replicas. It returns true
+// when the update was a dry-run of a ScaledObject with the scale-dry-run annotation, the ScaleTarget wasn't changed
+// so the callers must not report the scale with events, conditions or lastActiveTime
+func (e *scaleExecutor) updateScaleOnScaleTarget(ctx context.Context, scaledObject *kedav1alpha1.ScaledObject, scale *autoscalingv1.Scale, replicas int32) (int32, bool, error) {
+	logger := e.logger.WithValues("scaledobject.Name", scaledObject.Name,
+		"scaledObject.Namespace", scaledObject.Namespace,
+		"scaleTarget.Name", scaledObject.Spec.ScaleTargetRef.Name)
+
+	updateOptions := metav1.UpdateOptions{}
+	if scaledObject.Annotations[kedav1alpha1.ScaleDryRunAnnotation] == "true" {
+		updateOptions.DryRun = []string{metav1.DryRunAll}
 	}
 
-	// Update with requested repliacs.
-	currentReplicas := scale.Spec.Replicas
-	scale.Spec.Replicas = replicas
+	currentReplicas := int32(-1)
+	attempt := 0
+	// The scale subresource is updated with optimistic concurrency: the update carries the resourceVersion
+	// of the Scale it was computed from. If another controller changed the ScaleTarget in the meantime, the
+	// update is rejected with a conflict and retried with backoff on a freshly retrieved Scale.
+	err := retry.RetryOnConflict(retry.DefaultBackoff, func() error {
+		attempt++
+		if scale == nil || attempt > 1 {
+			// Wasn't retrieved earlier or is outdated, grab it now.
+			var err error
+			scale, err = e.getScaleTargetScale(ctx, scaledObject)
+			if err != nil {
+				return err
+			}
+		}
+
+		// Update with requested replicas.
+		currentReplicas = scale.Spec.Replicas
+		scale.Spec.Replicas = replicas
+
+		_, err := e.scaleClient.Scales(scaledObject.Namespace).Update(ctx, scaledObject.Status.ScaleTargetGVKR.GroupResource(), scale, updateOptions)
+		if apierrors.IsConflict(err) {
+			metrics.RecordScaleUpdateConflict(scaledObject.Namespace, scaledObject.Name)
+			logger.V(1).Info("Conflict while updating the ScaleTarget, retrying", "attempt", attempt)
+		}
+		return err
+	})
+	if err != nil {
+		metrics.RecordScaleUpdateError(scaledObject.Namespace, scaledObject.Name)
+		logger.Error(err, "Error updating the ScaleTarget", "attempts", attempt)
+		return currentReplicas, false, err
+	}
 
-	_, err := e.scaleClient.Scales(scaledObject.Namespace).Update(ctx, scaledObject.Status.ScaleTargetGVKR.GroupResource(), scale, metav1.UpdateOptions{})
-	return currentReplicas, err
+	if len(updateOptions.DryRun) > 0 {
+		logger.Info("Dry-run update of the ScaleTarget succeeded, replicas were not changed", "Original Replicas Count", currentReplicas, "Requested Replicas Count", replicas)
+		return currentReplicas, true, nil
+	}
+	return currentReplicas, false, nil
 }
 
 // getIdleOrMinimumReplicaCount returns true if the second value returned is from IdleReplicaCount
@@ -18,14 +18,22 @@ package executor
 
 import (
 	"context"
+	"errors"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	appsv1 "k8s.io/api/apps/v1"
 	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/tools/record"
+	runtimeclient "sigs.k8s.io/controller-runtime/pkg/client"
 
 	"github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	"github.com/kedacore/keda/v2/pkg/mock/mock_client"
@@ -266,7 +274,9 @@ func TestScaleFromMinReplicasWhenActive(t *testing.T) {
 	mockScaleInterface.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Eq(scale), gomock.Any())
 
 	client.EXPECT().Status().Times(2).Return(statusWriter)
-	statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+	statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any())
+	// lastActiveTime is applied server-side with a field owner
+	statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), runtimeclient.Apply, gomock.Any(), gomock.Any())
 
 	scaleExecutor.RequestScale(context.TODO(), &scaledObject, true, false)
 
@@ -390,7 +400,9 @@ func TestScaleFromIdleToMinReplicasWhenActive(t *testing.T) {
 	mockScaleInterface.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Eq(scale), gomock.Any())
 
 	client.EXPECT().Status().Times(2).Return(statusWriter)
-	statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Times(2)
+	statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any())
+	// lastActiveTime is applied server-side with a field owner
+	statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), runtimeclient.Apply, gomock.Any(), gomock.Any())
 
 	scaleExecutor.RequestScale(context.TODO(), &scaledObject, true, false)
 
@@ -398,3 +410,239 @@ func TestScaleFromIdleToMinReplicasWhenActive(t *testing.T) {
 	condition := scaledObject.Status.Conditions.GetActiveCondition()
 	assert.Equal(t, true, condition.IsTrue())
 }
+
+func TestScaleTargetUpdateRetriesOnConflict(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	client := mock_client.NewMockClient(ctrl)
+	recorder := record.NewFakeRecorder(1)
+	mockScaleClient := mock_scale.NewMockScalesGetter(ctrl)
+	mockScaleInterface := mock_scale.NewMockScaleInterface(ctrl)
+
+	scaleExecutor := NewScaleExecutor(client, mockScaleClient, nil, recorder).(*scaleExecutor)
+
+	scaledObject := v1alpha1.ScaledObject{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "name",
+			Namespace: "namespace",
+		},
+		Spec: v1alpha1.ScaledObjectSpec{
+			ScaleTargetRef: &v1alpha1.ScaleTarget{
+				Name: "name",
+			},
+		},
+		Status: v1alpha1.ScaledObjectStatus{
+			ScaleTargetGVKR: &v1alpha1.GroupVersionKindResource{
+				Group: "apps",
+				Kind:  "Deployment",
+			},
+		},
+	}
+
+	staleScale := &autoscalingv1.Scale{Spec: autoscalingv1.ScaleSpec{Replicas: 0}}
+	freshScale := &autoscalingv1.Scale{Spec: autoscalingv1.ScaleSpec{Replicas: 3}}
+	conflict := apierrors.NewConflict(schema.GroupResource{Group: "apps", Resource: "deployments"}, "name", errors.New("object has been modified"))
+
+	mockScaleClient.EXPECT().Scales(gomock.Any()).Return(mockScaleInterface).Times(3)
+	gomock.InOrder(
+		mockScaleInterface.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Eq(staleScale), gomock.Any()).Return(nil, conflict),
+		mockScaleInterface.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(freshScale, nil),
+		mockScaleInterface.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Eq(freshScale), gomock.Any()).Return(freshScale, nil),
+	)
+
+	currentReplicas, _, err := scaleExecutor.updateScaleOnScaleTarget(context.TODO(), &scaledObject, staleScale, 5)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int32(3), currentReplicas)
+	assert.Equal(t, int32(5), freshScale.Spec.Replicas)
+}
+
+func TestScaleTargetUpdateDryRun(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	client := mock_client.NewMockClient(ctrl)
+	recorder := record.NewFakeRecorder(1)
+	mockScaleClient := mock_scale.NewMockScalesGetter(ctrl)
+	mockScaleInterface := mock_scale.NewMockScaleInterface(ctrl)
+
+	scaleExecutor := NewScaleExecutor(client, mockScaleClient, nil, recorder).(*scaleExecutor)
+
+	scaledObject := v1alpha1.ScaledObject{
+		ObjectMeta: v1.ObjectMeta{
+			Name:        "name",
+			Namespace:   "namespace",
+			Annotations: map[string]string{v1alpha1.ScaleDryRunAnnotation: "true"},
+		},
+		Spec: v1alpha1.ScaledObjectSpec{
+			ScaleTargetRef: &v1alpha1.ScaleTarget{
+				Name: "name",
+			},
+		},
+		Status: v1alpha1.ScaledObjectStatus{
+			ScaleTargetGVKR: &v1alpha1.GroupVersionKindResource{
+				Group: "apps",
+				Kind:  "Deployment",
+			},
+		},
+	}
+
+	scale := &autoscalingv1.Scale{Spec: autoscalingv1.ScaleSpec{Replicas: 0}}
+
+	mockScaleClient.EXPECT().Scales(gomock.Any()).Return(mockScaleInterface)
+	mockScaleInterface.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Eq(v1.UpdateOptions{DryRun: []string{v1.DryRunAll}})).Return(scale, nil)
+
+	_, dryRun, err := scaleExecutor.updateScaleOnScaleTarget(context.TODO(), &scaledObject, scale, 1)
+
+	assert.Nil(t, err)
+	assert.True(t, dryRun)
+
+	// a dry-run scale isn't reported, no event is recorded
+	mockScaleClient.EXPECT().Scales(gomock.Any()).Return(mockScaleInterface).Times(2)
+	mockScaleInterface.EXPECT().Get(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(&autoscalingv1.Scale{Spec: autoscalingv1.ScaleSpec{Replicas: 2}}, nil)
+	mockScaleInterface.EXPECT().Update(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).Return(scale, nil)
+
+	scaleExecutor.RequestDirectScale(context.TODO(), &scaledObject, 4)
+	assert.Len(t, recorder.Events, 0)
+}
+
+func TestRequestAtMaxReplicasCheck(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	client := mock_client.NewMockClient(ctrl)
+	recorder := record.NewFakeRecorder(1)
+	statusWriter := mock_client.NewMockStatusWriter(ctrl)
+
+	scaleExecutor := NewScaleExecutor(client, nil, nil, recorder)
+
+	saturationMinutes := int32(10)
+	scaledObject := v1alpha1.ScaledObject{
+		ObjectMeta: v1.ObjectMeta{
+			Name:      "name",
+			Namespace: "namespace",
+		},
+		Spec: v1alpha1.ScaledObjectSpec{
+			ScaleTargetRef: &v1alpha1.ScaleTarget{
+				Name: "name",
+			},
+			Advanced: &v1alpha1.AdvancedConfig{
+				SaturationMinutes: &saturationMinutes,
+			},
+		},
+	}
+	scaledObject.Status.Conditions = *v1alpha1.GetInitializedConditions()
+
+	hpa := func(currentReplicas int32, limitedSince time.Duration) *autoscalingv2beta2.HorizontalPodAutoscaler {
+		return &autoscalingv2beta2.HorizontalPodAutoscaler{
+			Spec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+				MaxReplicas: 10,
+			},
+			Status: autoscalingv2beta2.HorizontalPodAutoscalerStatus{
+				CurrentReplicas: currentReplicas,
+				Conditions: []autoscalingv2beta2.HorizontalPodAutoscalerCondition{{
+					Type:               autoscalingv2beta2.ScalingLimited,
+					Status:             corev1.ConditionTrue,
+					Reason:             "TooManyReplicas",
+					LastTransitionTime: v1.NewTime(time.Now().Add(-limitedSince)),
+				}},
+			},
+		}
+	}
+
+	client.EXPECT().Status().Times(3).Return(statusWriter)
+	statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), gomock.Any()).Times(3)
+
+	// below maxReplicaCount
+	scaleExecutor.RequestAtMaxReplicasCheck(context.TODO(), &scaledObject, hpa(5, time.Hour))
+	condition := scaledObject.Status.Conditions.GetAtMaxReplicasCondition()
+	assert.True(t, condition.IsFalse())
+
+	// at maxReplicaCount for less than the saturation window, the condition doesn't change
+	scaleExecutor.RequestAtMaxReplicasCheck(context.TODO(), &scaledObject, hpa(10, 5*time.Minute))
+	condition = scaledObject.Status.Conditions.GetAtMaxReplicasCondition()
+	assert.True(t, condition.IsFalse())
+
+	// at maxReplicaCount for longer than the saturation window
+	scaleExecutor.RequestAtMaxReplicasCheck(context.TODO(), &scaledObject, hpa(10, 15*time.Minute))
+	condition = scaledObject.Status.Conditions.GetAtMaxReplicasCondition()
+	assert.True(t, condition.IsTrue())
+	assert.Equal(t, "MaxReplicasSaturated", condition.Reason)
+	assert.Len(t, recorder.Events, 1)
+
+	// still saturated, the condition doesn't change
+	scaleExecutor.RequestAtMaxReplicasCheck(context.TODO(), &scaledObject, hpa(10, 20*time.Minute))
+
+	// metrics back within target
+	withinTarget := hpa(10, 20*time.Minute)
+	withinTarget.Status.Conditions[0].Status = corev1.ConditionFalse
+	withinTarget.Status.Conditions[0].Reason = "DesiredWithinRange"
+	scaleExecutor.RequestAtMaxReplicasCheck(context.TODO(), &scaledObject, withinTarget)
+	condition = scaledObject.Status.Conditions.GetAtMaxReplicasCondition()
+	assert.True(t, condition.IsFalse())
+}
+
+func TestUpdateLastActiveTimeRateLimited(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	client := mock_client.NewMockClient(ctrl)
+	statusWriter := mock_client.NewMockStatusWriter(ctrl)
+	scaleExecutor := NewScaleExecutor(client, nil, nil, record.NewFakeRecorder(1)).(*scaleExecutor)
+	scaleExecutor.statusUpdateInterval = time.Minute
+
+	cooldownPeriod := int32(60)
+	tests := []struct {
+		name           string
+		lastActiveTime time.Duration
+		cooldownPeriod *int32
+		written        bool
+	}{
+		{"recent lastActiveTime", 10 * time.Second, nil, false},
+		{"lastActiveTime older than the interval", 2 * time.Minute, nil, true},
+		// the interval is capped at half of the cooldownPeriod
+		{"lastActiveTime older than half of the cooldownPeriod", 40 * time.Second, &cooldownPeriod, true},
+	}
+	for _, test := range tests {
+		lastActiveTime := v1.NewTime(time.Now().Add(-test.lastActiveTime))
+		scaledObject := &v1alpha1.ScaledObject{
+			ObjectMeta: v1.ObjectMeta{Name: "name", Namespace: "namespace"},
+			Spec:       v1alpha1.ScaledObjectSpec{CooldownPeriod: test.cooldownPeriod},
+			Status:     v1alpha1.ScaledObjectStatus{LastActiveTime: &lastActiveTime},
+		}
+		if test.written {
+			client.EXPECT().Status().Return(statusWriter)
+			statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), runtimeclient.Apply, gomock.Any(), gomock.Any())
+		}
+
+		err := scaleExecutor.updateLastActiveTime(context.TODO(), scaleExecutor.logger, scaledObject)
+		assert.NoError(t, err, test.name)
+		assert.True(t, scaledObject.Status.LastActiveTime.After(lastActiveTime.Time), test.name)
+	}
+}
+
+func TestFlushLastActiveTime(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	client := mock_client.NewMockClient(ctrl)
+	statusWriter := mock_client.NewMockStatusWriter(ctrl)
+	scaleExecutor := NewScaleExecutor(client, nil, nil, record.NewFakeRecorder(1)).(*scaleExecutor)
+	scaleExecutor.statusUpdateInterval = time.Minute
+
+	written := v1.NewTime(time.Now().Add(-10 * time.Second))
+	scaledObject := &v1alpha1.ScaledObject{
+		ObjectMeta: v1.ObjectMeta{Name: "name", Namespace: "namespace"},
+		Status:     v1alpha1.ScaledObjectStatus{LastActiveTime: &written},
+	}
+
+	// the write of the active poll is skipped by the interval
+	err := scaleExecutor.updateLastActiveTime(context.TODO(), scaleExecutor.logger, scaledObject)
+	assert.NoError(t, err)
+	unwritten := *scaledObject.Status.LastActiveTime
+	scaledObject.Status.LastActiveTime = &written
+
+	// the triggers become inactive, the skipped lastActiveTime is written
+	client.EXPECT().Status().Return(statusWriter)
+	statusWriter.EXPECT().Patch(gomock.Any(), gomock.Any(), runtimeclient.Apply, gomock.Any(), gomock.Any()).
+		Do(func(_ context.Context, obj runtimeclient.Object, _ runtimeclient.Patch, _ ...runtimeclient.PatchOption) {
+			status := obj.(*unstructured.Unstructured).Object["status"].(map[string]interface{})
+			assert.Equal(t, unwritten.UTC().Format(time.RFC3339), status["lastActiveTime"])
+		})
+	err = scaleExecutor.flushLastActiveTime(context.TODO(), scaleExecutor.logger, scaledObject)
+	assert.NoError(t, err)
+	assert.Equal(t, unwritten, *scaledObject.Status.LastActiveTime)
+	_, ok := scaleExecutor.unwrittenLastActiveTimes.Load("namespace/name")
+	assert.False(t, ok)
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"time"
+
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/scalers"
+)
+
+// RedactedValue replaces the values of secrets in explanations
+const RedactedValue = "<redacted>"
+
+// TriggerExplanation is the configuration a scaler of a trigger is built with and the metrics it specifies
+type TriggerExplanation struct {
+	Index int    `json:"index"`
+	Name  string `json:"name,omitempty"`
+	Type  string `json:"type"`
+	// Metadata is the trigger metadata after expanding templates and resolving the environment and valueFrom references,
+	// values from the environment of the scale target and from Secrets are redacted
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// AuthParams are the names of the resolved auth params, their values are redacted
+	AuthParams  map[string]string                `json:"authParams,omitempty"`
+	PodIdentity kedav1alpha1.PodIdentityProvider `json:"podIdentity,omitempty"`
+	MetricSpecs []autoscalingv2beta2.MetricSpec  `json:"metricSpecs,omitempty"`
+	Error       string                           `json:"error,omitempty"`
+}
+
+// ExplainTriggers builds the scalers of the triggers of a ScaledObject or ScaledJob the way the operator does and
+// returns their resolved configuration and metric specs, without calling the scalers
+func ExplainTriggers(ctx context.Context, client client.Client, scalableObject interface{}, globalHTTPTimeout time.Duration) ([]TriggerExplanation, error) {
+	target, err := newOneShotTarget(ctx, client, scalableObject, globalHTTPTimeout, "explain")
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = target.withRequester(ctx)
+	explanations := make([]TriggerExplanation, 0, len(target.withTriggers.Spec.Triggers))
+	for scalerIndex, trigger := range target.withTriggers.Spec.Triggers {
+		explanation := TriggerExplanation{Index: scalerIndex, Name: trigger.Name, Type: trigger.Type}
+		config, err := target.handler.resolveScalerConfig(ctx, target.logger, target.withTriggers, scalerIndex, trigger, target.podTemplateSpec, target.containerName)
+		if err != nil {
+			explanation.Error = err.Error()
+			explanations = append(explanations, explanation)
+			continue
+		}
+		explanation.Metadata = redactTriggerMetadata(&trigger, config.TriggerMetadata)
+		explanation.AuthParams = redactAuthParams(config.AuthParams)
+		explanation.PodIdentity = config.PodIdentity
+
+		var scaler scalers.Scaler
+		transform, err := newMetricTransform(&trigger)
+		if err == nil {
+			scaler, err = target.handler.buildWrappedScaler(ctx, trigger, config, transform, newMetricBounds(&trigger, target.withTriggers, target.handler.recorder))
+		}
+		if err != nil {
+			explanation.Error = err.Error()
+		} else {
+			explanation.MetricSpecs = scaler.GetMetricSpecForScaling(ctx)
+		}
+		if scaler != nil {
+			scaler.Close(ctx)
+		}
+		explanations = append(explanations, explanation)
+	}
+	return explanations, nil
+}
+
+// ExplainedMetricSpecs returns the metric specs of the triggers in the order the scalers cache returns them
+func ExplainedMetricSpecs(explanations []TriggerExplanation) []autoscalingv2beta2.MetricSpec {
+	var metricSpecs []autoscalingv2beta2.MetricSpec
+	for _, explanation := range explanations {
+		metricSpecs = append(metricSpecs, explanation.MetricSpecs...)
+	}
+	return metricSpecs
+}
+
+// redactTriggerMetadata returns the resolved metadata with the values resolved from the environment or a Secret redacted
+func redactTriggerMetadata(trigger *kedav1alpha1.ScaleTriggers, metadata map[string]string) map[string]string {
+	redacted := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		redacted[k] = v
+		if trigger.Metadata[k] == "" && trigger.Metadata[k+"FromEnv"] != "" {
+			redacted[k] = RedactedValue
+		}
+	}
+	for _, valueFrom := range trigger.ValueFrom {
+		if valueFrom.SecretKeyRef != nil {
+			if _, ok := redacted[valueFrom.Parameter]; ok {
+				redacted[valueFrom.Parameter] = RedactedValue
+			}
+		}
+	}
+	return redacted
+}
+
+func redactAuthParams(authParams map[string]string) map[string]string {
+	if len(authParams) == 0 {
+		return nil
+	}
+	redacted := make(map[string]string, len(authParams))
+	for k := range authParams {
+		redacted[k] = RedactedValue
+	}
+	return redacted
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+func TestExplainTriggers(t *testing.T) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, appsv1.AddToScheme(scheme))
+	assert.NoError(t, kedav1alpha1.AddToScheme(scheme))
+
+	deployment := &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "test"},
+		Spec: appsv1.DeploymentSpec{
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{Containers: []corev1.Container{{
+					Name: "orders",
+					Env:  []corev1.EnvVar{{Name: "TZ", Value: "Etc/UTC"}},
+				}}},
+			},
+		},
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "test"},
+		Data:       map[string][]byte{"token": []byte("s3cr3t")},
+	}
+	triggerAuthentication := &kedav1alpha1.TriggerAuthentication{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "test"},
+		Spec: kedav1alpha1.TriggerAuthenticationSpec{
+			SecretTargetRef: []kedav1alpha1.AuthSecretTargetRef{{Parameter: "token", Name: "orders", Key: "token"}},
+		},
+	}
+	scaledObject := &kedav1alpha1.ScaledObject{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "test"},
+		Spec: kedav1alpha1.ScaledObjectSpec{
+			ScaleTargetRef: &kedav1alpha1.ScaleTarget{Name: "orders"},
+			Triggers: []kedav1alpha1.ScaleTriggers{
+				{
+					Type: "cron",
+					Name: "office-hours",
+					Metadata: map[string]string{
+						"timezoneFromEnv": "TZ",
+						"start":           "0 8 * * *",
+						"end":             "0 18 * * *",
+						"desiredReplicas": "5",
+					},
+					AuthenticationRef: &kedav1alpha1.ScaledObjectAuthRef{Name: "orders"},
+				},
+				{
+					Type:     "unknown",
+					Metadata: map[string]string{},
+				},
+			},
+		},
+	}
+
+	explanations, err := ExplainTriggers(context.Background(), fake.NewFakeClientWithScheme(scheme, deployment, secret, triggerAuthentication), scaledObject, time.Second)
+	assert.NoError(t, err)
+	assert.Len(t, explanations, 2)
+
+	assert.Equal(t, "office-hours", explanations[0].Name)
+	assert.Empty(t, explanations[0].Error)
+	assert.Equal(t, RedactedValue, explanations[0].Metadata["timezone"])
+	assert.Equal(t, "TZ", explanations[0].Metadata["timezoneFromEnv"])
+	assert.Equal(t, "5", explanations[0].Metadata["desiredReplicas"])
+	assert.Equal(t, map[string]string{"token": RedactedValue}, explanations[0].AuthParams)
+	assert.Len(t, explanations[0].MetricSpecs, 1)
+	assert.Equal(t, "s0-cron-Etc-UTC-08xxx-018xxx", explanations[0].MetricSpecs[0].External.Metric.Name)
+
+	assert.Equal(t, "unknown", explanations[1].Type)
+	assert.NotEmpty(t, explanations[1].Error)
+	assert.Len(t, ExplainedMetricSpecs(explanations), 1)
+}
+
+func TestRedactTriggerMetadata(t *testing.T) {
+	trigger := &kedav1alpha1.ScaleTriggers{
+		Metadata: map[string]string{"host": "rabbitmq", "passwordFromEnv": "PASSWORD", "queueName": "orders"},
+		ValueFrom: []kedav1alpha1.TriggerMetadataValueFrom{
+			{Parameter: "apiKey", SecretKeyRef: &corev1.SecretKeySelector{Key: "apiKey"}},
+			{Parameter: "vhost", ConfigMapKeyRef: &corev1.ConfigMapKeySelector{Key: "vhost"}},
+		},
+	}
+	redacted := redactTriggerMetadata(trigger, map[string]string{
+		"host": "rabbitmq", "password": "pass", "passwordFromEnv": "PASSWORD", "queueName": "orders", "apiKey": "key", "vhost": "/",
+	})
+	assert.Equal(t, map[string]string{
+		"host": "rabbitmq", "password": RedactedValue, "passwordFromEnv": "PASSWORD", "queueName": "orders", "apiKey": RedactedValue, "vhost": "/",
+	}, redacted)
+}
@@ -0,0 +1,57 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"fmt"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/scalers"
+	"github.com/kedacore/keda/v2/pkg/scaling/cache"
+	"github.com/kedacore/keda/v2/pkg/scaling/executor"
+)
+
+// jobParametersFunc returns the function reading the parameters of the Jobs created for the trigger from the
+// trigger referenced by the jobParameters of the ScaledJob, ScaledJobs without jobParameters get nil
+func jobParametersFunc(scalersCache *cache.ScalersCache, scaledJob *kedav1alpha1.ScaledJob, trigger string) executor.JobParametersFunc {
+	if scaledJob.Spec.JobParameters == nil {
+		return nil
+	}
+	triggerName := scaledJob.Spec.JobParameters.TriggerName
+	if triggerName == "" {
+		triggerName = trigger
+	}
+
+	return func(ctx context.Context, count int64, running []scalers.JobParameters) ([]scalers.JobParameters, error) {
+		for _, builder := range scalersCache.Scalers {
+			name := builder.TriggerName
+			if name == "" {
+				name = builder.TriggerType
+			}
+			if name != triggerName {
+				continue
+			}
+			provider, ok := unwrapScaler(builder.Scaler).(scalers.JobParametersProvider)
+			if !ok {
+				return nil, fmt.Errorf("trigger %s of type %s doesn't provide job parameters", triggerName, builder.TriggerType)
+			}
+			return provider.GetJobParameters(ctx, count, running)
+		}
+		return nil, fmt.Errorf("trigger %s for jobParameters not found", triggerName)
+	}
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/tools/record"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	mock_scalers "github.com/kedacore/keda/v2/pkg/mock/mock_scaler"
+	"github.com/kedacore/keda/v2/pkg/scalers"
+	"github.com/kedacore/keda/v2/pkg/scaling/cache"
+)
+
+// messageGroupsScaler hands out a message group to every Job
+type messageGroupsScaler struct {
+	scalers.Scaler
+	groups []string
+}
+
+func (s *messageGroupsScaler) GetJobParameters(_ context.Context, count int64, _ []scalers.JobParameters) ([]scalers.JobParameters, error) {
+	var parameters []scalers.JobParameters
+	for i := 0; i < len(s.groups) && int64(i) < count; i++ {
+		parameters = append(parameters, scalers.JobParameters{Env: map[string]string{"MESSAGE_GROUP": s.groups[i]}})
+	}
+	return parameters, nil
+}
+
+func TestJobParametersFunc(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	maxAllowedValue := resource.MustParse("100")
+	bounds := newMetricBounds(&kedav1alpha1.ScaleTriggers{Type: "fake", MaxAllowedValue: &maxAllowedValue}, &kedav1alpha1.ScaledJob{}, record.NewFakeRecorder(1))
+	scalersCache := &cache.ScalersCache{Scalers: []cache.ScalerBuilder{
+		{Scaler: mock_scalers.NewMockScaler(ctrl), TriggerType: "cron"},
+		{Scaler: bounds.wrap(&messageGroupsScaler{groups: []string{"a", "b", "c"}}), TriggerName: "orders", TriggerType: "aws-sqs-queue"},
+	}}
+
+	scaledJob := &kedav1alpha1.ScaledJob{}
+	assert.Nil(t, jobParametersFunc(scalersCache, scaledJob, "orders"))
+
+	// the parameters come from the trigger the jobs are created for
+	scaledJob.Spec.JobParameters = &kedav1alpha1.JobParameters{}
+	parameters, err := jobParametersFunc(scalersCache, scaledJob, "orders")(context.Background(), 2, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []scalers.JobParameters{{Env: map[string]string{"MESSAGE_GROUP": "a"}}, {Env: map[string]string{"MESSAGE_GROUP": "b"}}}, parameters)
+
+	_, err = jobParametersFunc(scalersCache, scaledJob, "cron")(context.Background(), 2, nil)
+	assert.Error(t, err)
+
+	// or from the referenced trigger
+	scaledJob.Spec.JobParameters.TriggerName = "orders"
+	parameters, err = jobParametersFunc(scalersCache, scaledJob, "cron")(context.Background(), 5, nil)
+	assert.NoError(t, err)
+	assert.Len(t, parameters, 3)
+
+	scaledJob.Spec.JobParameters.TriggerName = "payments"
+	_, err = jobParametersFunc(scalersCache, scaledJob, "orders")(context.Background(), 2, nil)
+	assert.Error(t, err)
+}
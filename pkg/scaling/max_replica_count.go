@@ -0,0 +1,166 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/eventreason"
+	"github.com/kedacore/keda/v2/pkg/scalers"
+	"github.com/kedacore/keda/v2/pkg/scaling/cache"
+)
+
+// defaultMaxReplicaCount is the maxReplicas of the HPA of a ScaledObject without maxReplicaCount
+const defaultMaxReplicaCount int32 = 100
+
+// updateMaxReplicaCount reads the maxReplicaCount of the ScaledObject from maxReplicaCountFrom and sets it on the
+// status of the ScaledObject, so the controller keeps it on the HPA, and on the HPA. The last ceiling is kept
+// when the source can't be read
+func (h *scaleHandler) updateMaxReplicaCount(ctx context.Context, scalersCache *cache.ScalersCache, scaledObject *kedav1alpha1.ScaledObject, hpa *autoscalingv2beta2.HorizontalPodAutoscaler) {
+	if scaledObject.Spec.MaxReplicaCountFrom == nil {
+		return
+	}
+	logger := h.logger.WithValues("scaledObject.Namespace", scaledObject.Namespace, "scaledObject.Name", scaledObject.Name)
+
+	ceiling, err := h.resolveMaxReplicaCount(ctx, scalersCache, scaledObject)
+	if err != nil {
+		logger.Error(err, "Error resolving maxReplicaCountFrom")
+		h.recorder.Event(scaledObject, corev1.EventTypeWarning, eventreason.KEDAMaxReplicaCountFailed, err.Error())
+		return
+	}
+	minReplicas := scaledObject.Spec.MinReplicaCount
+	if hpa != nil {
+		minReplicas = hpa.Spec.MinReplicas
+	}
+	maxReplicas := clampMaxReplicaCount(ceiling, minReplicas)
+
+	if scaledObject.Status.ResolvedMaxReplicaCount == nil || *scaledObject.Status.ResolvedMaxReplicaCount != maxReplicas {
+		patch := client.MergeFrom(scaledObject.DeepCopy())
+		scaledObject.Status.ResolvedMaxReplicaCount = &maxReplicas
+		if err := h.client.Status().Patch(ctx, scaledObject, patch); err != nil {
+			logger.Error(err, "Error updating resolvedMaxReplicaCount")
+			return
+		}
+	}
+
+	// without HPA the ScaledObject controller creates it with the resolvedMaxReplicaCount
+	if hpa != nil && hpa.Spec.MaxReplicas != maxReplicas {
+		previous := hpa.Spec.MaxReplicas
+		patch := client.MergeFrom(hpa.DeepCopy())
+		hpa.Spec.MaxReplicas = maxReplicas
+		if err := h.client.Patch(ctx, hpa, patch); err != nil {
+			logger.Error(err, "Error updating maxReplicas of the HPA", "HPA.Name", hpa.Name)
+			hpa.Spec.MaxReplicas = previous
+			return
+		}
+		logger.Info("Updated maxReplicas of the HPA from maxReplicaCountFrom", "HPA.Name", hpa.Name, "from", previous, "to", maxReplicas)
+		h.recorder.Eventf(scaledObject, corev1.EventTypeNormal, eventreason.KEDAMaxReplicaCountUpdated, "Updated maxReplicaCount from %d to %d", previous, maxReplicas)
+	}
+}
+
+// resolveMaxReplicaCount returns the ceiling from the ConfigMap key or the trigger referenced by maxReplicaCountFrom
+func (h *scaleHandler) resolveMaxReplicaCount(ctx context.Context, scalersCache *cache.ScalersCache, scaledObject *kedav1alpha1.ScaledObject) (int64, error) {
+	source := scaledObject.Spec.MaxReplicaCountFrom
+	switch {
+	case source.ConfigMapKeyRef != nil && source.TriggerName != "":
+		return -1, fmt.Errorf("maxReplicaCountFrom can't reference both a ConfigMap and a trigger")
+	case source.ConfigMapKeyRef != nil:
+		configMap := &corev1.ConfigMap{}
+		if err := h.client.Get(ctx, types.NamespacedName{Name: source.ConfigMapKeyRef.Name, Namespace: scaledObject.Namespace}, configMap); err != nil {
+			return -1, fmt.Errorf("error getting ConfigMap %s for maxReplicaCountFrom: %s", source.ConfigMapKeyRef.Name, err)
+		}
+		value, ok := configMap.Data[source.ConfigMapKeyRef.Key]
+		if !ok {
+			return -1, fmt.Errorf("key %s not found in ConfigMap %s for maxReplicaCountFrom", source.ConfigMapKeyRef.Key, source.ConfigMapKeyRef.Name)
+		}
+		ceiling, err := strconv.ParseInt(strings.TrimSpace(value), 10, 32)
+		if err != nil {
+			return -1, fmt.Errorf("error parsing key %s of ConfigMap %s for maxReplicaCountFrom: %s", source.ConfigMapKeyRef.Key, source.ConfigMapKeyRef.Name, err)
+		}
+		return ceiling, nil
+	case source.TriggerName != "":
+		for _, builder := range scalersCache.Scalers {
+			if builder.TriggerName != source.TriggerName {
+				continue
+			}
+			provider, ok := unwrapScaler(builder.Scaler).(scalers.MaxReplicaCountProvider)
+			if !ok {
+				return -1, fmt.Errorf("trigger %s of type %s doesn't provide a maxReplicaCount", source.TriggerName, builder.TriggerType)
+			}
+			return provider.GetMaxReplicaCount(ctx)
+		}
+		return -1, fmt.Errorf("trigger %s for maxReplicaCountFrom not found", source.TriggerName)
+	default:
+		return -1, fmt.Errorf("maxReplicaCountFrom has to reference a ConfigMap or a trigger")
+	}
+}
+
+// clampMaxReplicaCount keeps the ceiling at minReplicas or above, the HPA rejects a lower maxReplicas
+func clampMaxReplicaCount(ceiling int64, minReplicas *int32) int32 {
+	min := int64(1)
+	if minReplicas != nil && *minReplicas > 1 {
+		min = int64(*minReplicas)
+	}
+	switch {
+	case ceiling < min:
+		return int32(min)
+	case ceiling > math.MaxInt32:
+		return math.MaxInt32
+	default:
+		return int32(ceiling)
+	}
+}
+
+// unwrapScaler returns the scaler built for the trigger without the wrappers added by the scale handler
+func unwrapScaler(scaler scalers.Scaler) scalers.Scaler {
+	for {
+		switch s := scaler.(type) {
+		case *invertedPushScaler:
+			scaler = s.invertedScaler.Scaler
+		case *invertedScaler:
+			scaler = s.Scaler
+		case *boundedPushScaler:
+			scaler = s.boundedScaler.Scaler
+		case *boundedScaler:
+			scaler = s.Scaler
+		case *transformedPushScaler:
+			scaler = s.transformedScaler.Scaler
+		case *transformedScaler:
+			scaler = s.Scaler
+		case *budgetedPushScaler:
+			scaler = s.budgetedScaler.Scaler
+		case *budgetedScaler:
+			scaler = s.Scaler
+		case *limitedPushScaler:
+			scaler = s.limitedScaler.Scaler
+		case *limitedScaler:
+			scaler = s.Scaler
+		default:
+			return scaler
+		}
+	}
+}
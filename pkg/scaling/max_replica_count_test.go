@@ -0,0 +1,175 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	mock_scalers "github.com/kedacore/keda/v2/pkg/mock/mock_scaler"
+	"github.com/kedacore/keda/v2/pkg/scalers"
+	"github.com/kedacore/keda/v2/pkg/scaling/cache"
+)
+
+// partitionsScaler provides the partition count of a topic as maxReplicaCount
+type partitionsScaler struct {
+	scalers.Scaler
+	partitions int64
+}
+
+func (s *partitionsScaler) GetMaxReplicaCount(context.Context) (int64, error) {
+	return s.partitions, nil
+}
+
+func newMaxReplicaCountTestHandler(t *testing.T, objects ...runtime.Object) (*scaleHandler, *record.FakeRecorder) {
+	scheme := runtime.NewScheme()
+	assert.NoError(t, corev1.AddToScheme(scheme))
+	assert.NoError(t, autoscalingv2beta2.AddToScheme(scheme))
+	assert.NoError(t, kedav1alpha1.AddToScheme(scheme))
+
+	recorder := record.NewFakeRecorder(10)
+	return &scaleHandler{
+		client:   fake.NewFakeClientWithScheme(scheme, objects...),
+		logger:   logf.Log.WithName("test"),
+		recorder: recorder,
+	}, recorder
+}
+
+func newMaxReplicaCountTestObjects(source *kedav1alpha1.MaxReplicaCountSource) (*kedav1alpha1.ScaledObject, *autoscalingv2beta2.HorizontalPodAutoscaler) {
+	minReplicas := int32(2)
+	maxReplicas := int32(10)
+	scaledObject := &kedav1alpha1.ScaledObject{
+		ObjectMeta: metav1.ObjectMeta{Name: "orders", Namespace: "test"},
+		Spec: kedav1alpha1.ScaledObjectSpec{
+			MinReplicaCount:     &minReplicas,
+			MaxReplicaCount:     &maxReplicas,
+			MaxReplicaCountFrom: source,
+		},
+	}
+	hpa := &autoscalingv2beta2.HorizontalPodAutoscaler{
+		ObjectMeta: metav1.ObjectMeta{Name: "keda-hpa-orders", Namespace: "test"},
+		Spec: autoscalingv2beta2.HorizontalPodAutoscalerSpec{
+			MinReplicas: &minReplicas,
+			MaxReplicas: maxReplicas,
+		},
+	}
+	return scaledObject, hpa
+}
+
+func TestUpdateMaxReplicaCountFromConfigMap(t *testing.T) {
+	scaledObject, hpa := newMaxReplicaCountTestObjects(&kedav1alpha1.MaxReplicaCountSource{
+		ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "topology"}, Key: "orders"},
+	})
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "topology", Namespace: "test"},
+		Data:       map[string]string{"orders": " 24\n"},
+	}
+	handler, _ := newMaxReplicaCountTestHandler(t, scaledObject, hpa, configMap)
+
+	handler.updateMaxReplicaCount(context.Background(), &cache.ScalersCache{}, scaledObject, hpa)
+
+	assert.Equal(t, int32(24), hpa.Spec.MaxReplicas)
+	storedHPA := &autoscalingv2beta2.HorizontalPodAutoscaler{}
+	assert.NoError(t, handler.client.Get(context.Background(), types.NamespacedName{Name: "keda-hpa-orders", Namespace: "test"}, storedHPA))
+	assert.Equal(t, int32(24), storedHPA.Spec.MaxReplicas)
+	storedScaledObject := &kedav1alpha1.ScaledObject{}
+	assert.NoError(t, handler.client.Get(context.Background(), types.NamespacedName{Name: "orders", Namespace: "test"}, storedScaledObject))
+	assert.Equal(t, int32(24), *storedScaledObject.Status.ResolvedMaxReplicaCount)
+}
+
+func TestUpdateMaxReplicaCountWithoutHPA(t *testing.T) {
+	scaledObject, _ := newMaxReplicaCountTestObjects(&kedav1alpha1.MaxReplicaCountSource{
+		ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "topology"}, Key: "orders"},
+	})
+	configMap := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "topology", Namespace: "test"},
+		Data:       map[string]string{"orders": "24"},
+	}
+	handler, _ := newMaxReplicaCountTestHandler(t, scaledObject, configMap)
+
+	// the HPA couldn't be read, the ceiling is still recorded for the controller creating it
+	handler.updateMaxReplicaCount(context.Background(), &cache.ScalersCache{}, scaledObject, nil)
+	assert.Equal(t, int32(24), *scaledObject.Status.ResolvedMaxReplicaCount)
+}
+
+func TestUpdateMaxReplicaCountFromTrigger(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	scaledObject, hpa := newMaxReplicaCountTestObjects(&kedav1alpha1.MaxReplicaCountSource{TriggerName: "orders-topic"})
+	handler, _ := newMaxReplicaCountTestHandler(t, scaledObject, hpa)
+
+	limits := scalerTypeLimits{"kafka": make(chan struct{}, 1)}
+	scalersCache := &cache.ScalersCache{Scalers: []cache.ScalerBuilder{
+		{Scaler: mock_scalers.NewMockScaler(ctrl), TriggerName: "cpu", TriggerType: "cpu"},
+		{Scaler: limits.wrap("kafka", &partitionsScaler{Scaler: mock_scalers.NewMockScaler(ctrl), partitions: 6}), TriggerName: "orders-topic", TriggerType: "kafka"},
+	}}
+
+	handler.updateMaxReplicaCount(context.Background(), scalersCache, scaledObject, hpa)
+	assert.Equal(t, int32(6), hpa.Spec.MaxReplicas)
+	assert.Equal(t, int32(6), *scaledObject.Status.ResolvedMaxReplicaCount)
+
+	// the ceiling doesn't go below minReplicaCount
+	scalersCache.Scalers[1].Scaler = &partitionsScaler{Scaler: mock_scalers.NewMockScaler(ctrl), partitions: 1}
+	handler.updateMaxReplicaCount(context.Background(), scalersCache, scaledObject, hpa)
+	assert.Equal(t, int32(2), hpa.Spec.MaxReplicas)
+	assert.Equal(t, int32(2), *scaledObject.Status.ResolvedMaxReplicaCount)
+}
+
+func TestUpdateMaxReplicaCountKeepsCeilingOnError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	var tests = []struct {
+		name   string
+		source *kedav1alpha1.MaxReplicaCountSource
+	}{
+		{"missing ConfigMap", &kedav1alpha1.MaxReplicaCountSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "topology"}, Key: "orders"}}},
+		{"missing trigger", &kedav1alpha1.MaxReplicaCountSource{TriggerName: "orders-queue"}},
+		{"trigger without ceiling", &kedav1alpha1.MaxReplicaCountSource{TriggerName: "cpu"}},
+		{"no source", &kedav1alpha1.MaxReplicaCountSource{}},
+	}
+	for _, test := range tests {
+		scaledObject, hpa := newMaxReplicaCountTestObjects(test.source)
+		handler, recorder := newMaxReplicaCountTestHandler(t, scaledObject, hpa)
+		scalersCache := &cache.ScalersCache{Scalers: []cache.ScalerBuilder{
+			{Scaler: mock_scalers.NewMockScaler(ctrl), TriggerName: "cpu", TriggerType: "cpu"},
+		}}
+
+		handler.updateMaxReplicaCount(context.Background(), scalersCache, scaledObject, hpa)
+		assert.Equal(t, int32(10), hpa.Spec.MaxReplicas, test.name)
+		assert.Nil(t, scaledObject.Status.ResolvedMaxReplicaCount, test.name)
+		assert.Len(t, recorder.Events, 1, test.name)
+	}
+}
+
+func TestUnwrapScaler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	scaler := &partitionsScaler{Scaler: mock_scalers.NewMockScaler(ctrl), partitions: 3}
+	bounds := &metricBounds{}
+	limits := scalerTypeLimits{"kafka": make(chan struct{}, 1)}
+
+	assert.Equal(t, scalers.Scaler(scaler), unwrapScaler(bounds.wrap(limits.wrap("kafka", scaler))))
+}
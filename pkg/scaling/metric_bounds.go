@@ -0,0 +1,132 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/eventreason"
+	"github.com/kedacore/keda/v2/pkg/scalers"
+)
+
+// metricBounds holds the maxAllowedValue and spikeDampening of a trigger and the last returned
+// metric values, it is shared by all scalers built for the trigger so it survives scaler refreshes
+type metricBounds struct {
+	maxAllowedValue *resource.Quantity
+	maxIncrease     *resource.Quantity
+
+	object      runtime.Object
+	triggerName string
+	recorder    record.EventRecorder
+
+	lock       sync.Mutex
+	lastValues map[string]resource.Quantity
+}
+
+func newMetricBounds(trigger *kedav1alpha1.ScaleTriggers, object runtime.Object, recorder record.EventRecorder) *metricBounds {
+	if trigger.MaxAllowedValue == nil && trigger.SpikeDampening == nil {
+		return nil
+	}
+
+	triggerName := trigger.Name
+	if triggerName == "" {
+		triggerName = trigger.Type
+	}
+
+	bounds := &metricBounds{
+		maxAllowedValue: trigger.MaxAllowedValue,
+		object:          object,
+		triggerName:     triggerName,
+		recorder:        recorder,
+		lastValues:      map[string]resource.Quantity{},
+	}
+	if trigger.SpikeDampening != nil {
+		bounds.maxIncrease = &trigger.SpikeDampening.MaxIncrease
+	}
+	return bounds
+}
+
+// wrap returns the scaler with its metric values clamped to the bounds, push scalers stay push scalers
+func (b *metricBounds) wrap(scaler scalers.Scaler) scalers.Scaler {
+	if b == nil {
+		return scaler
+	}
+	bounded := &boundedScaler{Scaler: scaler, bounds: b}
+	if pushScaler, ok := scaler.(scalers.PushScaler); ok {
+		return &boundedPushScaler{boundedScaler: bounded, pushScaler: pushScaler}
+	}
+	return bounded
+}
+
+func (b *metricBounds) clamp(metricName string, value resource.Quantity) resource.Quantity {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.maxAllowedValue != nil && value.Cmp(*b.maxAllowedValue) > 0 {
+		b.recorder.Event(b.object, corev1.EventTypeWarning, eventreason.KEDAMetricValueClamped,
+			fmt.Sprintf("Value %s of metric %s of trigger %s is above maxAllowedValue, using %s", value.String(), metricName, b.triggerName, b.maxAllowedValue.String()))
+		value = b.maxAllowedValue.DeepCopy()
+	}
+
+	if last, ok := b.lastValues[metricName]; ok && b.maxIncrease != nil {
+		limit := last.DeepCopy()
+		limit.Add(*b.maxIncrease)
+		if value.Cmp(limit) > 0 {
+			b.recorder.Event(b.object, corev1.EventTypeWarning, eventreason.KEDAMetricValueClamped,
+				fmt.Sprintf("Value %s of metric %s of trigger %s increased by more than spikeDampening.maxIncrease, using %s", value.String(), metricName, b.triggerName, limit.String()))
+			value = limit
+		}
+	}
+
+	b.lastValues[metricName] = value
+	return value
+}
+
+type boundedScaler struct {
+	scalers.Scaler
+	bounds *metricBounds
+}
+
+func (s *boundedScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	metrics, err := s.Scaler.GetMetrics(ctx, metricName, metricSelector)
+	if err != nil {
+		return metrics, err
+	}
+	for i := range metrics {
+		metrics[i].Value = s.bounds.clamp(metrics[i].MetricName, metrics[i].Value)
+	}
+	return metrics, nil
+}
+
+type boundedPushScaler struct {
+	*boundedScaler
+	pushScaler scalers.PushScaler
+}
+
+func (s *boundedPushScaler) Run(ctx context.Context, active chan<- bool) {
+	s.pushScaler.Run(ctx, active)
+}
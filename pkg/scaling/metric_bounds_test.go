@@ -0,0 +1,101 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	mock_scalers "github.com/kedacore/keda/v2/pkg/mock/mock_scaler"
+	"github.com/kedacore/keda/v2/pkg/scalers"
+)
+
+func TestMetricBoundsNotConfigured(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	scaler := mock_scalers.NewMockScaler(ctrl)
+
+	bounds := newMetricBounds(&kedav1alpha1.ScaleTriggers{Type: "fake"}, &kedav1alpha1.ScaledObject{}, record.NewFakeRecorder(1))
+	assert.Nil(t, bounds)
+	assert.Equal(t, scalers.Scaler(scaler), bounds.wrap(scaler))
+}
+
+func TestMetricBoundsClamp(t *testing.T) {
+	maxAllowedValue := resource.MustParse("100")
+	trigger := &kedav1alpha1.ScaleTriggers{
+		Type:            "fake",
+		MaxAllowedValue: &maxAllowedValue,
+		SpikeDampening:  &kedav1alpha1.SpikeDampening{MaxIncrease: resource.MustParse("20")},
+	}
+	recorder := record.NewFakeRecorder(10)
+	bounds := newMetricBounds(trigger, &kedav1alpha1.ScaledObject{}, recorder)
+
+	tests := []struct {
+		value    int64
+		expected int64
+		clamped  bool
+	}{
+		// above maxAllowedValue, first value is not dampened
+		{9007199254740992, 100, true},
+		{50, 50, false},
+		// dampened to last value + maxIncrease
+		{90, 70, true},
+		{85, 85, false},
+		// decreases are never dampened
+		{0, 0, false},
+	}
+	for _, test := range tests {
+		value := bounds.clamp("metric", *resource.NewQuantity(test.value, resource.DecimalSI))
+		assert.Equal(t, test.expected, value.Value(), "value %d", test.value)
+		assert.Equal(t, test.clamped, len(recorder.Events) > 0, "event for value %d", test.value)
+		for len(recorder.Events) > 0 {
+			<-recorder.Events
+		}
+	}
+}
+
+func TestBoundedScalerGetMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	scaler := mock_scalers.NewMockScaler(ctrl)
+	scaler.EXPECT().GetMetrics(gomock.Any(), "metric", nil).Return([]external_metrics.ExternalMetricValue{
+		{MetricName: "metric", Value: *resource.NewQuantity(1000, resource.DecimalSI)},
+	}, nil)
+
+	maxAllowedValue := resource.MustParse("10")
+	bounds := newMetricBounds(&kedav1alpha1.ScaleTriggers{Type: "fake", MaxAllowedValue: &maxAllowedValue}, &kedav1alpha1.ScaledObject{}, record.NewFakeRecorder(1))
+
+	metrics, err := bounds.wrap(scaler).GetMetrics(context.Background(), "metric", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), metrics[0].Value.Value())
+}
+
+func TestBoundedScalerKeepsPushScaler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	pushScaler := mock_scalers.NewMockPushScaler(ctrl)
+
+	maxAllowedValue := resource.MustParse("10")
+	bounds := newMetricBounds(&kedav1alpha1.ScaleTriggers{Type: "fake", MaxAllowedValue: &maxAllowedValue}, &kedav1alpha1.ScaledObject{}, record.NewFakeRecorder(1))
+
+	_, ok := bounds.wrap(pushScaler).(scalers.PushScaler)
+	assert.True(t, ok)
+}
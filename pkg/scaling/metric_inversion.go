@@ -0,0 +1,161 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/scalers"
+)
+
+// metricInversion maps the metric values of a trigger with scalingDirection inverse, so the
+// replicas computed from them go down as the metric value of the scaler goes up
+type metricInversion struct {
+	mapping kedav1alpha1.InverseMapping
+	value   float64
+}
+
+func newMetricInversion(trigger *kedav1alpha1.ScaleTriggers) (*metricInversion, error) {
+	switch trigger.ScalingDirection {
+	case "", kedav1alpha1.ScalingDirectionNormal:
+		if trigger.InverseScaling != nil {
+			return nil, fmt.Errorf("inverseScaling requires scalingDirection %s", kedav1alpha1.ScalingDirectionInverse)
+		}
+		return nil, nil
+	case kedav1alpha1.ScalingDirectionInverse:
+	default:
+		return nil, fmt.Errorf("unknown scalingDirection %s", trigger.ScalingDirection)
+	}
+
+	// cpu and memory triggers are resource metrics computed by the HPA, KEDA doesn't serve their values
+	if trigger.Type == "cpu" || trigger.Type == "memory" {
+		return nil, fmt.Errorf("scalingDirection %s is not supported for %s triggers", kedav1alpha1.ScalingDirectionInverse, trigger.Type)
+	}
+	if trigger.InverseScaling == nil {
+		return nil, fmt.Errorf("scalingDirection %s requires inverseScaling", kedav1alpha1.ScalingDirectionInverse)
+	}
+
+	inversion := &metricInversion{
+		mapping: trigger.InverseScaling.Mapping,
+		value:   trigger.InverseScaling.Value.AsApproximateFloat64(),
+	}
+	switch inversion.mapping {
+	case "":
+		inversion.mapping = kedav1alpha1.InverseMappingLinear
+	case kedav1alpha1.InverseMappingLinear, kedav1alpha1.InverseMappingReciprocal:
+	default:
+		return nil, fmt.Errorf("unknown inverseScaling mapping %s", inversion.mapping)
+	}
+	if inversion.value <= 0 {
+		return nil, fmt.Errorf("inverseScaling value has to be greater than 0")
+	}
+	return inversion, nil
+}
+
+// wrap returns the scaler with its metric values inverted, push scalers stay push scalers
+func (m *metricInversion) wrap(scaler scalers.Scaler) scalers.Scaler {
+	if m == nil {
+		return scaler
+	}
+	inverted := &invertedScaler{Scaler: scaler, inversion: m}
+	if pushScaler, ok := scaler.(scalers.PushScaler); ok {
+		return &invertedPushScaler{invertedScaler: inverted, pushScaler: pushScaler}
+	}
+	return inverted
+}
+
+func (m *metricInversion) invert(value resource.Quantity) resource.Quantity {
+	metric := value.AsApproximateFloat64()
+	var inverted float64
+	switch m.mapping {
+	case kedav1alpha1.InverseMappingReciprocal:
+		inverted = m.value / math.Max(metric, 1)
+	default:
+		inverted = math.Max(m.value-metric, 0)
+	}
+	return *resource.NewMilliQuantity(int64(inverted*1000), resource.DecimalSI)
+}
+
+type invertedScaler struct {
+	scalers.Scaler
+	inversion *metricInversion
+}
+
+func (s *invertedScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	metrics, err := s.Scaler.GetMetrics(ctx, metricName, metricSelector)
+	if err != nil {
+		return metrics, err
+	}
+	for i := range metrics {
+		metrics[i].Value = s.inversion.invert(metrics[i].Value)
+	}
+	return metrics, nil
+}
+
+// IsActive returns true if any inverted metric value is above 0, the activity of the scaler
+// follows the metric value going up so it can't be used
+func (s *invertedScaler) IsActive(ctx context.Context) (bool, error) {
+	for _, spec := range s.Scaler.GetMetricSpecForScaling(ctx) {
+		if spec.External == nil {
+			continue
+		}
+		metrics, err := s.GetMetrics(ctx, spec.External.Metric.Name, nil)
+		if err != nil {
+			return false, err
+		}
+		for _, metric := range metrics {
+			if metric.Value.Sign() > 0 {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+type invertedPushScaler struct {
+	*invertedScaler
+	pushScaler scalers.PushScaler
+}
+
+// Run checks the inverted activity whenever the push scaler reports a change
+func (s *invertedPushScaler) Run(ctx context.Context, active chan<- bool) {
+	pushed := make(chan bool)
+	go s.pushScaler.Run(ctx, pushed)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-pushed:
+			isActive, err := s.IsActive(ctx)
+			if err != nil {
+				continue
+			}
+			select {
+			case active <- isActive:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	mock_scalers "github.com/kedacore/keda/v2/pkg/mock/mock_scaler"
+	"github.com/kedacore/keda/v2/pkg/scalers"
+)
+
+func TestNewMetricInversion(t *testing.T) {
+	value := resource.MustParse("100")
+	tests := []struct {
+		name     string
+		trigger  kedav1alpha1.ScaleTriggers
+		inverted bool
+		isError  bool
+	}{
+		{"not configured", kedav1alpha1.ScaleTriggers{Type: "fake"}, false, false},
+		{"normal", kedav1alpha1.ScaleTriggers{Type: "fake", ScalingDirection: kedav1alpha1.ScalingDirectionNormal}, false, false},
+		{"inverse", kedav1alpha1.ScaleTriggers{Type: "fake", ScalingDirection: kedav1alpha1.ScalingDirectionInverse, InverseScaling: &kedav1alpha1.InverseScaling{Value: value}}, true, false},
+		{"inverse reciprocal", kedav1alpha1.ScaleTriggers{Type: "fake", ScalingDirection: kedav1alpha1.ScalingDirectionInverse, InverseScaling: &kedav1alpha1.InverseScaling{Mapping: kedav1alpha1.InverseMappingReciprocal, Value: value}}, true, false},
+		{"inverse without inverseScaling", kedav1alpha1.ScaleTriggers{Type: "fake", ScalingDirection: kedav1alpha1.ScalingDirectionInverse}, false, true},
+		{"inverseScaling without inverse", kedav1alpha1.ScaleTriggers{Type: "fake", InverseScaling: &kedav1alpha1.InverseScaling{Value: value}}, false, true},
+		{"unknown direction", kedav1alpha1.ScaleTriggers{Type: "fake", ScalingDirection: "sideways"}, false, true},
+		{"unknown mapping", kedav1alpha1.ScaleTriggers{Type: "fake", ScalingDirection: kedav1alpha1.ScalingDirectionInverse, InverseScaling: &kedav1alpha1.InverseScaling{Mapping: "log", Value: value}}, false, true},
+		{"zero value", kedav1alpha1.ScaleTriggers{Type: "fake", ScalingDirection: kedav1alpha1.ScalingDirectionInverse, InverseScaling: &kedav1alpha1.InverseScaling{}}, false, true},
+		{"cpu", kedav1alpha1.ScaleTriggers{Type: "cpu", ScalingDirection: kedav1alpha1.ScalingDirectionInverse, InverseScaling: &kedav1alpha1.InverseScaling{Value: value}}, false, true},
+	}
+	for _, test := range tests {
+		inversion, err := newMetricInversion(&test.trigger)
+		assert.Equal(t, test.isError, err != nil, test.name)
+		assert.Equal(t, test.inverted, inversion != nil, test.name)
+	}
+}
+
+func TestMetricInversionInvert(t *testing.T) {
+	tests := []struct {
+		mapping  kedav1alpha1.InverseMapping
+		value    string
+		expected int64
+	}{
+		{kedav1alpha1.InverseMappingLinear, "0", 100000},
+		{kedav1alpha1.InverseMappingLinear, "30", 70000},
+		{kedav1alpha1.InverseMappingLinear, "250", 0},
+		{kedav1alpha1.InverseMappingReciprocal, "0", 100000},
+		{kedav1alpha1.InverseMappingReciprocal, "400", 250},
+	}
+	for _, test := range tests {
+		inversion := &metricInversion{mapping: test.mapping, value: 100}
+		value := inversion.invert(resource.MustParse(test.value))
+		assert.Equal(t, test.expected, value.MilliValue(), "%s of %s", test.mapping, test.value)
+	}
+}
+
+func TestInvertedScalerGetMetricsAndIsActive(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	scaler := mock_scalers.NewMockScaler(ctrl)
+	scaler.EXPECT().GetMetricSpecForScaling(gomock.Any()).Return([]v2beta2.MetricSpec{
+		{External: &v2beta2.ExternalMetricSource{Metric: v2beta2.MetricIdentifier{Name: "metric"}}},
+	}).AnyTimes()
+	queueLength := int64(40)
+	scaler.EXPECT().GetMetrics(gomock.Any(), "metric", nil).DoAndReturn(func(context.Context, string, interface{}) ([]external_metrics.ExternalMetricValue, error) {
+		return []external_metrics.ExternalMetricValue{
+			{MetricName: "metric", Value: *resource.NewQuantity(queueLength, resource.DecimalSI)},
+		}, nil
+	}).AnyTimes()
+
+	inversion, err := newMetricInversion(&kedav1alpha1.ScaleTriggers{
+		Type:             "fake",
+		ScalingDirection: kedav1alpha1.ScalingDirectionInverse,
+		InverseScaling:   &kedav1alpha1.InverseScaling{Value: resource.MustParse("100")},
+	})
+	assert.NoError(t, err)
+	inverted := inversion.wrap(scaler)
+
+	metrics, err := inverted.GetMetrics(context.Background(), "metric", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(60), metrics[0].Value.Value())
+	isActive, err := inverted.IsActive(context.Background())
+	assert.NoError(t, err)
+	assert.True(t, isActive)
+
+	// the downstream queue is full, no producers are needed
+	queueLength = 120
+	isActive, err = inverted.IsActive(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, isActive)
+}
+
+func TestInvertedScalerKeepsPushScaler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	pushScaler := mock_scalers.NewMockPushScaler(ctrl)
+	inversion := &metricInversion{mapping: kedav1alpha1.InverseMappingLinear, value: 100}
+
+	wrapped := inversion.wrap(pushScaler)
+	_, ok := wrapped.(scalers.PushScaler)
+	assert.True(t, ok)
+	assert.Equal(t, scalers.Scaler(pushScaler), unwrapScaler(wrapped))
+}
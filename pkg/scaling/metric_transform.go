@@ -0,0 +1,178 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	"github.com/kedacore/keda/v2/pkg/scalers"
+)
+
+// metricTransform applies the transform steps of a trigger to its metric values, it is shared by all
+// scalers built for the trigger so the moving averages survive scaler refreshes
+type metricTransform struct {
+	steps []transformStep
+
+	lock sync.Mutex
+	// averages are the last values of the ema steps by series, see seriesKey, and step index
+	averages map[string]map[int]float64
+}
+
+type transformStep struct {
+	transformType kedav1alpha1.MetricTransformType
+	value         float64
+	min           *float64
+	max           *float64
+}
+
+func newMetricTransform(trigger *kedav1alpha1.ScaleTriggers) (*metricTransform, error) {
+	if len(trigger.Transform) == 0 {
+		return nil, nil
+	}
+
+	// cpu and memory triggers are resource metrics computed by the HPA, KEDA doesn't serve their values
+	if trigger.Type == "cpu" || trigger.Type == "memory" {
+		return nil, fmt.Errorf("transform is not supported for %s triggers", trigger.Type)
+	}
+
+	transform := &metricTransform{averages: map[string]map[int]float64{}}
+	for i, t := range trigger.Transform {
+		step := transformStep{transformType: t.Type}
+		switch t.Type {
+		case kedav1alpha1.MetricTransformMultiply, kedav1alpha1.MetricTransformAdd:
+			if t.Value == nil {
+				return nil, fmt.Errorf("transform %d (%s) requires value", i, t.Type)
+			}
+			step.value = t.Value.AsApproximateFloat64()
+		case kedav1alpha1.MetricTransformClamp:
+			if t.Min == nil && t.Max == nil {
+				return nil, fmt.Errorf("transform %d (%s) requires min or max", i, t.Type)
+			}
+			if t.Min != nil {
+				min := t.Min.AsApproximateFloat64()
+				step.min = &min
+			}
+			if t.Max != nil {
+				max := t.Max.AsApproximateFloat64()
+				step.max = &max
+			}
+			if step.min != nil && step.max != nil && *step.min > *step.max {
+				return nil, fmt.Errorf("transform %d (%s) min is greater than max", i, t.Type)
+			}
+		case kedav1alpha1.MetricTransformEMA:
+			if t.Alpha == nil {
+				return nil, fmt.Errorf("transform %d (%s) requires alpha", i, t.Type)
+			}
+			step.value = t.Alpha.AsApproximateFloat64()
+			if step.value <= 0 || step.value > 1 {
+				return nil, fmt.Errorf("transform %d (%s) alpha has to be greater than 0 and at most 1", i, t.Type)
+			}
+		default:
+			return nil, fmt.Errorf("unknown transform type %s", t.Type)
+		}
+		transform.steps = append(transform.steps, step)
+	}
+	return transform, nil
+}
+
+// wrap returns the scaler with its metric values transformed, push scalers stay push scalers
+func (m *metricTransform) wrap(scaler scalers.Scaler) scalers.Scaler {
+	if m == nil {
+		return scaler
+	}
+	transformed := &transformedScaler{Scaler: scaler, transform: m}
+	if pushScaler, ok := scaler.(scalers.PushScaler); ok {
+		return &transformedPushScaler{transformedScaler: transformed, pushScaler: pushScaler}
+	}
+	return transformed
+}
+
+// seriesKey identifies a series of metric values by the metric name and labels, scalers returning several
+// values for a metric, eg. one per queue, label them and each series has its own moving averages
+func seriesKey(metric external_metrics.ExternalMetricValue) string {
+	if len(metric.MetricLabels) == 0 {
+		return metric.MetricName
+	}
+	return metric.MetricName + "{" + labels.Set(metric.MetricLabels).String() + "}"
+}
+
+func (m *metricTransform) apply(series string, value resource.Quantity) resource.Quantity {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	metric := value.AsApproximateFloat64()
+	for i, step := range m.steps {
+		switch step.transformType {
+		case kedav1alpha1.MetricTransformMultiply:
+			metric *= step.value
+		case kedav1alpha1.MetricTransformAdd:
+			metric += step.value
+		case kedav1alpha1.MetricTransformClamp:
+			if step.min != nil {
+				metric = math.Max(metric, *step.min)
+			}
+			if step.max != nil {
+				metric = math.Min(metric, *step.max)
+			}
+		case kedav1alpha1.MetricTransformEMA:
+			averages, ok := m.averages[series]
+			if !ok {
+				averages = map[int]float64{}
+				m.averages[series] = averages
+			}
+			// the first value starts the average
+			if last, ok := averages[i]; ok {
+				metric = step.value*metric + (1-step.value)*last
+			}
+			averages[i] = metric
+		}
+	}
+	return *resource.NewMilliQuantity(int64(math.Round(metric*1000)), resource.DecimalSI)
+}
+
+type transformedScaler struct {
+	scalers.Scaler
+	transform *metricTransform
+}
+
+func (s *transformedScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	metrics, err := s.Scaler.GetMetrics(ctx, metricName, metricSelector)
+	if err != nil {
+		return metrics, err
+	}
+	for i := range metrics {
+		metrics[i].Value = s.transform.apply(seriesKey(metrics[i]), metrics[i].Value)
+	}
+	return metrics, nil
+}
+
+type transformedPushScaler struct {
+	*transformedScaler
+	pushScaler scalers.PushScaler
+}
+
+func (s *transformedPushScaler) Run(ctx context.Context, active chan<- bool) {
+	s.pushScaler.Run(ctx, active)
+}
@@ -0,0 +1,154 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+	mock_scalers "github.com/kedacore/keda/v2/pkg/mock/mock_scaler"
+	"github.com/kedacore/keda/v2/pkg/scalers"
+)
+
+func quantity(value string) *resource.Quantity {
+	q := resource.MustParse(value)
+	return &q
+}
+
+func TestNewMetricTransform(t *testing.T) {
+	tests := []struct {
+		name        string
+		trigger     kedav1alpha1.ScaleTriggers
+		transformed bool
+		isError     bool
+	}{
+		{"not configured", kedav1alpha1.ScaleTriggers{Type: "fake"}, false, false},
+		{"all steps", kedav1alpha1.ScaleTriggers{Type: "fake", Transform: []kedav1alpha1.MetricTransform{
+			{Type: kedav1alpha1.MetricTransformMultiply, Value: quantity("0.5")},
+			{Type: kedav1alpha1.MetricTransformAdd, Value: quantity("-10")},
+			{Type: kedav1alpha1.MetricTransformClamp, Min: quantity("0")},
+			{Type: kedav1alpha1.MetricTransformEMA, Alpha: quantity("0.3")},
+		}}, true, false},
+		{"multiply without value", kedav1alpha1.ScaleTriggers{Type: "fake", Transform: []kedav1alpha1.MetricTransform{{Type: kedav1alpha1.MetricTransformMultiply}}}, false, true},
+		{"add without value", kedav1alpha1.ScaleTriggers{Type: "fake", Transform: []kedav1alpha1.MetricTransform{{Type: kedav1alpha1.MetricTransformAdd}}}, false, true},
+		{"clamp without bounds", kedav1alpha1.ScaleTriggers{Type: "fake", Transform: []kedav1alpha1.MetricTransform{{Type: kedav1alpha1.MetricTransformClamp}}}, false, true},
+		{"clamp min above max", kedav1alpha1.ScaleTriggers{Type: "fake", Transform: []kedav1alpha1.MetricTransform{{Type: kedav1alpha1.MetricTransformClamp, Min: quantity("10"), Max: quantity("5")}}}, false, true},
+		{"ema without alpha", kedav1alpha1.ScaleTriggers{Type: "fake", Transform: []kedav1alpha1.MetricTransform{{Type: kedav1alpha1.MetricTransformEMA}}}, false, true},
+		{"ema alpha above 1", kedav1alpha1.ScaleTriggers{Type: "fake", Transform: []kedav1alpha1.MetricTransform{{Type: kedav1alpha1.MetricTransformEMA, Alpha: quantity("1.5")}}}, false, true},
+		{"unknown type", kedav1alpha1.ScaleTriggers{Type: "fake", Transform: []kedav1alpha1.MetricTransform{{Type: "log"}}}, false, true},
+		{"cpu", kedav1alpha1.ScaleTriggers{Type: "cpu", Transform: []kedav1alpha1.MetricTransform{{Type: kedav1alpha1.MetricTransformAdd, Value: quantity("1")}}}, false, true},
+	}
+	for _, test := range tests {
+		transform, err := newMetricTransform(&test.trigger)
+		assert.Equal(t, test.isError, err != nil, test.name)
+		assert.Equal(t, test.transformed, transform != nil, test.name)
+	}
+}
+
+func TestMetricTransformApply(t *testing.T) {
+	transform, err := newMetricTransform(&kedav1alpha1.ScaleTriggers{Type: "fake", Transform: []kedav1alpha1.MetricTransform{
+		{Type: kedav1alpha1.MetricTransformMultiply, Value: quantity("2")},
+		{Type: kedav1alpha1.MetricTransformAdd, Value: quantity("-10")},
+		{Type: kedav1alpha1.MetricTransformClamp, Min: quantity("0"), Max: quantity("100")},
+		{Type: kedav1alpha1.MetricTransformEMA, Alpha: quantity("0.5")},
+	}})
+	assert.NoError(t, err)
+
+	tests := []struct {
+		value    string
+		expected int64
+	}{
+		// the first value starts the average
+		{"30", 50000},
+		// clamped to 100 before it is averaged
+		{"500", 75000},
+		// clamped to 0
+		{"1", 37500},
+		{"17.5", 31250},
+	}
+	for _, test := range tests {
+		value := transform.apply("metric", resource.MustParse(test.value))
+		assert.Equal(t, test.expected, value.MilliValue(), "value %s", test.value)
+	}
+
+	// the averages are kept by series
+	value := transform.apply("other", resource.MustParse("30"))
+	assert.Equal(t, int64(50000), value.MilliValue())
+}
+
+func TestTransformedScalerGetMetricsAveragesBySeries(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	scaler := mock_scalers.NewMockScaler(ctrl)
+	scaler.EXPECT().GetMetrics(gomock.Any(), "metric", nil).Return([]external_metrics.ExternalMetricValue{
+		{MetricName: "metric", MetricLabels: map[string]string{"queue": "a"}, Value: resource.MustParse("10")},
+		{MetricName: "metric", MetricLabels: map[string]string{"queue": "b"}, Value: resource.MustParse("100")},
+	}, nil)
+	scaler.EXPECT().GetMetrics(gomock.Any(), "metric", nil).Return([]external_metrics.ExternalMetricValue{
+		{MetricName: "metric", MetricLabels: map[string]string{"queue": "a"}, Value: resource.MustParse("30")},
+		{MetricName: "metric", MetricLabels: map[string]string{"queue": "b"}, Value: resource.MustParse("100")},
+	}, nil)
+
+	transform, err := newMetricTransform(&kedav1alpha1.ScaleTriggers{Type: "fake", Transform: []kedav1alpha1.MetricTransform{
+		{Type: kedav1alpha1.MetricTransformEMA, Alpha: quantity("0.5")},
+	}})
+	assert.NoError(t, err)
+
+	wrapped := transform.wrap(scaler)
+	_, err = wrapped.GetMetrics(context.Background(), "metric", nil)
+	assert.NoError(t, err)
+	metrics, err := wrapped.GetMetrics(context.Background(), "metric", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(20), metrics[0].Value.Value())
+	assert.Equal(t, int64(100), metrics[1].Value.Value())
+}
+
+func TestTransformedScalerGetMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	scaler := mock_scalers.NewMockScaler(ctrl)
+	scaler.EXPECT().GetMetrics(gomock.Any(), "metric", nil).Return([]external_metrics.ExternalMetricValue{
+		{MetricName: "metric", Value: resource.MustParse("40")},
+	}, nil)
+
+	transform, err := newMetricTransform(&kedav1alpha1.ScaleTriggers{Type: "fake", Transform: []kedav1alpha1.MetricTransform{
+		{Type: kedav1alpha1.MetricTransformMultiply, Value: quantity("0.25")},
+	}})
+	assert.NoError(t, err)
+
+	metrics, err := transform.wrap(scaler).GetMetrics(context.Background(), "metric", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(10), metrics[0].Value.Value())
+}
+
+func TestTransformedScalerKeepsPushScaler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	pushScaler := mock_scalers.NewMockPushScaler(ctrl)
+	transform, err := newMetricTransform(&kedav1alpha1.ScaleTriggers{Type: "fake", Transform: []kedav1alpha1.MetricTransform{
+		{Type: kedav1alpha1.MetricTransformAdd, Value: quantity("1")},
+	}})
+	assert.NoError(t, err)
+
+	wrapped := transform.wrap(pushScaler)
+	_, ok := wrapped.(scalers.PushScaler)
+	assert.True(t, ok)
+	assert.Equal(t, scalers.Scaler(pushScaler), unwrapScaler(wrapped))
+}
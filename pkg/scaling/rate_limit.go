@@ -0,0 +1,223 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+
+	"github.com/kedacore/keda/v2/pkg/scalers"
+)
+
+// scalerRateLimitsEnvVar limits the requests per credential of the scaler types, eg. "datadog=300/1h,aws-*=20/1s",
+// the scalers of the types matching a pattern share the budget of a credential
+const scalerRateLimitsEnvVar = "KEDA_SCALER_RATE_LIMITS"
+
+// rateLimitRule is the request budget of the credentials of the scaler types matching the pattern,
+// a pattern ending with * matches the types with the prefix
+type rateLimitRule struct {
+	pattern  string
+	requests int
+	interval time.Duration
+}
+
+func (r rateLimitRule) matches(triggerType string) bool {
+	if strings.HasSuffix(r.pattern, "*") {
+		return strings.HasPrefix(triggerType, strings.TrimSuffix(r.pattern, "*"))
+	}
+	return r.pattern == triggerType
+}
+
+// credentialBudgets holds a token bucket for each credential of the scaler types with a rate limit, they are shared
+// by all scalers of the process using the credential
+type credentialBudgets struct {
+	rules []rateLimitRule
+
+	lock     sync.Mutex
+	limiters map[string]*rate.Limiter
+}
+
+func parseCredentialBudgets(value string) (*credentialBudgets, error) {
+	var rules []rateLimitRule
+	for _, entry := range strings.Split(value, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, "=", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("%s is not in the format <scaler type>=<requests>/<interval>", entry)
+		}
+		budget := strings.SplitN(strings.TrimSpace(parts[1]), "/", 2)
+		if len(budget) != 2 {
+			return nil, fmt.Errorf("budget of %s is not in the format <requests>/<interval>", parts[0])
+		}
+		requests, err := strconv.Atoi(budget[0])
+		if err != nil || requests <= 0 {
+			return nil, fmt.Errorf("requests of %s must be a positive number", parts[0])
+		}
+		interval, err := time.ParseDuration(budget[1])
+		if err != nil || interval <= 0 {
+			return nil, fmt.Errorf("interval of %s must be a positive duration", parts[0])
+		}
+		rules = append(rules, rateLimitRule{pattern: strings.TrimSpace(parts[0]), requests: requests, interval: interval})
+	}
+	if len(rules) == 0 {
+		return nil, nil
+	}
+	return &credentialBudgets{rules: rules, limiters: map[string]*rate.Limiter{}}, nil
+}
+
+// limiter returns the token bucket of the credential of the scaler, the first rule matching the type applies
+func (b *credentialBudgets) limiter(triggerType string, config *scalers.ScalerConfig) *rate.Limiter {
+	for _, rule := range b.rules {
+		if !rule.matches(triggerType) {
+			continue
+		}
+		key := rule.pattern + "/" + credentialKey(config)
+
+		b.lock.Lock()
+		defer b.lock.Unlock()
+		limiter, ok := b.limiters[key]
+		if !ok {
+			limiter = rate.NewLimiter(rate.Every(rule.interval/time.Duration(rule.requests)), rule.requests)
+			b.limiters[key] = limiter
+		}
+		return limiter
+	}
+	return nil
+}
+
+// credentialKey identifies the credential of the scaler by a hash of its auth params and pod identity, scalers
+// using the identity of the operator share the empty credential
+func credentialKey(config *scalers.ScalerConfig) string {
+	keys := make([]string, 0, len(config.AuthParams))
+	for k := range config.AuthParams {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	hash := sha256.New()
+	fmt.Fprintf(hash, "podIdentity=%s\n", config.PodIdentity)
+	for _, k := range keys {
+		fmt.Fprintf(hash, "%s=%s\n", k, config.AuthParams[k])
+	}
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+// wrap returns the scaler with IsActive and GetMetrics calls taken from the budget of its credential,
+// push scalers stay push scalers
+func (b *credentialBudgets) wrap(triggerType string, config *scalers.ScalerConfig, scaler scalers.Scaler) scalers.Scaler {
+	if b == nil {
+		return scaler
+	}
+	limiter := b.limiter(triggerType, config)
+	if limiter == nil {
+		return scaler
+	}
+	budgeted := &budgetedScaler{Scaler: scaler, limiter: limiter, lastMetrics: map[string][]external_metrics.ExternalMetricValue{}}
+	if pushScaler, ok := scaler.(scalers.PushScaler); ok {
+		return &budgetedPushScaler{budgetedScaler: budgeted, pushScaler: pushScaler}
+	}
+	return budgeted
+}
+
+// budgetedScaler serves the last values of the scaler while the budget of its credential is exceeded,
+// calls without a previous value wait for the budget
+type budgetedScaler struct {
+	scalers.Scaler
+	limiter *rate.Limiter
+
+	lock        sync.Mutex
+	lastActive  *bool
+	lastMetrics map[string][]external_metrics.ExternalMetricValue
+}
+
+func (s *budgetedScaler) IsActive(ctx context.Context) (bool, error) {
+	if !s.limiter.Allow() {
+		s.lock.Lock()
+		lastActive := s.lastActive
+		s.lock.Unlock()
+		if lastActive != nil {
+			return *lastActive, nil
+		}
+		if err := s.limiter.Wait(ctx); err != nil {
+			return false, err
+		}
+	}
+
+	active, err := s.Scaler.IsActive(ctx)
+	if err == nil {
+		s.lock.Lock()
+		s.lastActive = &active
+		s.lock.Unlock()
+	}
+	return active, err
+}
+
+func (s *budgetedScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	if !s.limiter.Allow() {
+		s.lock.Lock()
+		lastMetrics, ok := s.lastMetrics[metricName]
+		s.lock.Unlock()
+		if ok {
+			return append([]external_metrics.ExternalMetricValue{}, lastMetrics...), nil
+		}
+		if err := s.limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	metrics, err := s.Scaler.GetMetrics(ctx, metricName, metricSelector)
+	if err == nil {
+		s.lock.Lock()
+		s.lastMetrics[metricName] = append([]external_metrics.ExternalMetricValue{}, metrics...)
+		s.lock.Unlock()
+	}
+	return metrics, err
+}
+
+// budgetedPushScaler doesn't limit Run, the push scaler is notified by the upstream
+type budgetedPushScaler struct {
+	*budgetedScaler
+	pushScaler scalers.PushScaler
+}
+
+func (s *budgetedPushScaler) Run(ctx context.Context, active chan<- bool) {
+	s.pushScaler.Run(ctx, active)
+}
+
+// resolveCredentialBudgets reads the rate limits of the scaler types from the environment
+func resolveCredentialBudgets() (*credentialBudgets, error) {
+	budgets, err := parseCredentialBudgets(os.Getenv(scalerRateLimitsEnvVar))
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %s", scalerRateLimitsEnvVar, err)
+	}
+	return budgets, nil
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+
+	mock_scalers "github.com/kedacore/keda/v2/pkg/mock/mock_scaler"
+	"github.com/kedacore/keda/v2/pkg/scalers"
+)
+
+func TestParseCredentialBudgets(t *testing.T) {
+	budgets, err := parseCredentialBudgets("datadog=300/1h, aws-*=20/1s")
+	assert.NoError(t, err)
+	assert.Equal(t, []rateLimitRule{{"datadog", 300, time.Hour}, {"aws-*", 20, time.Second}}, budgets.rules)
+
+	budgets, err = parseCredentialBudgets("")
+	assert.NoError(t, err)
+	assert.Nil(t, budgets)
+
+	for _, value := range []string{"datadog", "datadog=300", "datadog=0/1h", "datadog=many/1h", "datadog=300/hour", "datadog=300/0s"} {
+		_, err = parseCredentialBudgets(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestCredentialBudgetsShareLimiterPerCredential(t *testing.T) {
+	budgets, err := parseCredentialBudgets("aws-*=20/1s,datadog=300/1h")
+	assert.NoError(t, err)
+
+	role := &scalers.ScalerConfig{AuthParams: map[string]string{"awsRoleArn": "arn:aws:iam::123456789012:role/keda"}}
+	sameRole := &scalers.ScalerConfig{AuthParams: map[string]string{"awsRoleArn": "arn:aws:iam::123456789012:role/keda"}}
+	otherRole := &scalers.ScalerConfig{AuthParams: map[string]string{"awsRoleArn": "arn:aws:iam::123456789012:role/other"}}
+
+	assert.Same(t, budgets.limiter("aws-sqs-queue", role), budgets.limiter("aws-cloudwatch", sameRole))
+	assert.NotSame(t, budgets.limiter("aws-sqs-queue", role), budgets.limiter("aws-sqs-queue", otherRole))
+	assert.NotSame(t, budgets.limiter("aws-sqs-queue", role), budgets.limiter("datadog", role))
+	assert.Nil(t, budgets.limiter("prometheus", role))
+}
+
+func TestCredentialBudgetsWrap(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	budgets, err := parseCredentialBudgets("datadog=1/1h")
+	assert.NoError(t, err)
+	config := &scalers.ScalerConfig{AuthParams: map[string]string{"apiKey": "key"}}
+
+	var nilBudgets *credentialBudgets
+	scaler := mock_scalers.NewMockScaler(ctrl)
+	assert.Equal(t, scalers.Scaler(scaler), nilBudgets.wrap("datadog", config, scaler))
+	assert.Equal(t, scalers.Scaler(scaler), budgets.wrap("prometheus", config, scaler))
+
+	pushScaler := mock_scalers.NewMockPushScaler(ctrl)
+	_, ok := budgets.wrap("datadog", config, pushScaler).(scalers.PushScaler)
+	assert.True(t, ok)
+}
+
+func TestBudgetedScalerServesLastValues(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	budgets, err := parseCredentialBudgets("datadog=2/1h")
+	assert.NoError(t, err)
+	config := &scalers.ScalerConfig{AuthParams: map[string]string{"apiKey": "key"}}
+
+	metrics := []external_metrics.ExternalMetricValue{{MetricName: "s0-datadog-queue", Value: *resource.NewQuantity(7, resource.DecimalSI)}}
+	scaler := mock_scalers.NewMockScaler(ctrl)
+	scaler.EXPECT().IsActive(gomock.Any()).Return(true, nil).Times(1)
+	scaler.EXPECT().GetMetrics(gomock.Any(), "s0-datadog-queue", nil).Return(metrics, nil).Times(1)
+	budgeted := budgets.wrap("datadog", config, scaler)
+
+	// the budget of 2 requests is used up by the first calls, the next calls get the last values
+	for i := 0; i < 3; i++ {
+		active, err := budgeted.IsActive(context.Background())
+		assert.NoError(t, err)
+		assert.True(t, active)
+		values, err := budgeted.GetMetrics(context.Background(), "s0-datadog-queue", nil)
+		assert.NoError(t, err)
+		assert.Equal(t, metrics, values)
+	}
+
+	// a scaler of another trigger with the same credential has no last value, so it waits for the budget
+	other := mock_scalers.NewMockScaler(ctrl)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = budgets.wrap("datadog", config, other).IsActive(ctx)
+	assert.Error(t, err)
+}
+
+func TestBudgetedScalerDoesNotCacheErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	budgets, err := parseCredentialBudgets("datadog=1/1h")
+	assert.NoError(t, err)
+
+	scaler := mock_scalers.NewMockScaler(ctrl)
+	scaler.EXPECT().IsActive(gomock.Any()).Return(false, errors.New("rate limited"))
+	budgeted := budgets.wrap("datadog", &scalers.ScalerConfig{}, scaler)
+
+	_, err = budgeted.IsActive(context.Background())
+	assert.Error(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err = budgeted.IsActive(ctx)
+	assert.Error(t, err, "without a last value the call waits for the budget")
+}
+
+func TestUnwrapBudgetedScaler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	budgets, err := parseCredentialBudgets("kafka=1/1s")
+	assert.NoError(t, err)
+	scaler := &partitionsScaler{Scaler: mock_scalers.NewMockScaler(ctrl), partitions: 3}
+
+	assert.Equal(t, scalers.Scaler(scaler), unwrapScaler(budgets.wrap("kafka", &scalers.ScalerConfig{}, scaler)))
+}
@@ -22,6 +22,8 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strings"
+	"text/template"
 
 	"github.com/go-logr/logr"
 	appsv1 "k8s.io/api/apps/v1"
@@ -39,6 +41,8 @@ const (
 	referenceOperator = '$'
 	referenceOpener   = '('
 	referenceCloser   = ')'
+
+	fromEnvSuffix = "FromEnv"
 )
 
 // ResolveScaleTargetPodSpec for given scalableObject inspects the scale target workload,
@@ -129,6 +133,89 @@ func ResolveContainerEnv(ctx context.Context, client client.Client, logger logr.
 	return resolveEnv(ctx, client, logger, &container, namespace)
 }
 
+// ExpandTriggerMetadata returns the trigger metadata with the Go templates in its values expanded with the
+// ScaledObject or ScaledJob, eg. `{{.ObjectMeta.Name}}`, `{{.Namespace}}` or `{{index .Labels "app"}}`, so
+// identical triggers can be generated for objects that only differ by naming convention.
+// Values without `{{` are returned as is, referencing a missing field or label is an error.
+func ExpandTriggerMetadata(metadata map[string]string, withTriggers *kedav1alpha1.WithTriggers) (map[string]string, error) {
+	expanded := make(map[string]string, len(metadata))
+	for k, v := range metadata {
+		if !strings.Contains(v, "{{") {
+			expanded[k] = v
+			continue
+		}
+
+		tmpl, err := template.New(k).Option("missingkey=error").Parse(v)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing template of trigger metadata %s: %s", k, err)
+		}
+		var buf bytes.Buffer
+		if err := tmpl.Execute(&buf, withTriggers); err != nil {
+			return nil, fmt.Errorf("error expanding template of trigger metadata %s: %s", k, err)
+		}
+		expanded[k] = buf.String()
+	}
+	return expanded, nil
+}
+
+// ResolveTriggerMetadata returns the trigger metadata with values resolved by KEDA for all scalers:
+// every `<parameter>FromEnv` entry is resolved from the environment of the scale target (unless the parameter
+// itself is set) and every valueFrom entry is resolved from the referenced Secret or ConfigMap key.
+// Values resolved from Secrets are also returned separately, so they can be exposed as auth params.
+func ResolveTriggerMetadata(ctx context.Context, client client.Client, trigger *kedav1alpha1.ScaleTriggers, resolvedEnv map[string]string, namespace string) (map[string]string, map[string]string, error) {
+	metadata := make(map[string]string, len(trigger.Metadata))
+	for k, v := range trigger.Metadata {
+		metadata[k] = v
+	}
+
+	for k, v := range trigger.Metadata {
+		parameter := strings.TrimSuffix(k, fromEnvSuffix)
+		if parameter == k || parameter == "" || metadata[parameter] != "" {
+			continue
+		}
+		if value, ok := resolvedEnv[v]; ok && value != "" {
+			metadata[parameter] = value
+		}
+	}
+
+	secrets := make(map[string]string)
+	for _, valueFrom := range trigger.ValueFrom {
+		if valueFrom.Parameter == "" {
+			return nil, nil, fmt.Errorf("valueFrom requires a parameter")
+		}
+		if trigger.Metadata[valueFrom.Parameter] != "" {
+			return nil, nil, fmt.Errorf("parameter %s is set both in metadata and valueFrom", valueFrom.Parameter)
+		}
+
+		var value string
+		var err error
+		switch {
+		case valueFrom.SecretKeyRef != nil && valueFrom.ConfigMapKeyRef != nil:
+			return nil, nil, fmt.Errorf("valueFrom for parameter %s can't reference both a Secret and a ConfigMap", valueFrom.Parameter)
+		case valueFrom.SecretKeyRef != nil:
+			value, err = resolveSecretValue(ctx, client, valueFrom.SecretKeyRef, valueFrom.SecretKeyRef.Key, namespace)
+			if err != nil && !isOptional(valueFrom.SecretKeyRef.Optional) {
+				return nil, nil, fmt.Errorf("error resolving secret %s for parameter %s in namespace %s: %s", valueFrom.SecretKeyRef.Name, valueFrom.Parameter, namespace, err)
+			}
+			secrets[valueFrom.Parameter] = value
+		case valueFrom.ConfigMapKeyRef != nil:
+			value, err = resolveConfigValue(ctx, client, valueFrom.ConfigMapKeyRef, valueFrom.ConfigMapKeyRef.Key, namespace)
+			if err != nil && !isOptional(valueFrom.ConfigMapKeyRef.Optional) {
+				return nil, nil, fmt.Errorf("error resolving config %s for parameter %s in namespace %s: %s", valueFrom.ConfigMapKeyRef.Name, valueFrom.Parameter, namespace, err)
+			}
+		default:
+			return nil, nil, fmt.Errorf("valueFrom for parameter %s requires either secretKeyRef or configMapKeyRef", valueFrom.Parameter)
+		}
+		metadata[valueFrom.Parameter] = value
+	}
+
+	return metadata, secrets, nil
+}
+
+func isOptional(optional *bool) bool {
+	return optional != nil && *optional
+}
+
 // ResolveAuthRefAndPodIdentity provides authentication parameters and pod identity needed authenticate scaler with the environment.
 func ResolveAuthRefAndPodIdentity(ctx context.Context, client client.Client, logger logr.Logger, triggerAuthRef *kedav1alpha1.ScaledObjectAuthRef, podTemplateSpec *corev1.PodTemplateSpec, namespace string) (map[string]string, kedav1alpha1.PodIdentityProvider, error) {
 	if podTemplateSpec != nil {
@@ -185,6 +272,11 @@ func resolveAuthRef(ctx context.Context, client client.Client, logger logr.Logge
 					result[e.Parameter] = resolveAuthSecret(ctx, client, logger, e.Name, triggerNamespace, e.Key)
 				}
 			}
+			if triggerAuthSpec.BoundServiceAccountToken != nil {
+				for _, e := range triggerAuthSpec.BoundServiceAccountToken {
+					result[e.Parameter] = resolveBoundServiceAccountToken(ctx, client, logger, e, triggerNamespace)
+				}
+			}
 			if triggerAuthSpec.HashiCorpVault != nil && len(triggerAuthSpec.HashiCorpVault.Secrets) > 0 {
 				vault := NewHashicorpVaultHandler(triggerAuthSpec.HashiCorpVault)
 				err := vault.Initialize(logger)
@@ -446,6 +538,30 @@ func resolveAuthSecret(ctx context.Context, client client.Client, logger logr.Lo
 	return string(result)
 }
 
+func resolveBoundServiceAccountToken(ctx context.Context, client client.Client, logger logr.Logger, boundToken kedav1alpha1.BoundServiceAccountToken, namespace string) string {
+	name := boundToken.ServiceAccountName
+	if name == "" || namespace == "" {
+		logger.Error(fmt.Errorf("error trying to get token"), "name and namespace of the ServiceAccount are required", "ServiceAccount.Namespace", namespace, "ServiceAccount.Name", name)
+		return ""
+	}
+	requester, ok := client.(serviceAccountTokenRequester)
+	if !ok {
+		logger.Error(fmt.Errorf("client doesn't support requesting ServiceAccount tokens"), "error trying to get token", "ServiceAccount.Namespace", namespace, "ServiceAccount.Name", name)
+		return ""
+	}
+	expirationSeconds := defaultBoundServiceAccountTokenExpirationSeconds
+	if boundToken.ExpirationSeconds != nil {
+		expirationSeconds = *boundToken.ExpirationSeconds
+	}
+	token, expiresAt, err := requester.RequestServiceAccountToken(ctx, namespace, name, boundToken.Audiences, expirationSeconds)
+	if err != nil {
+		logger.Error(err, "error trying to get token", "ServiceAccount.Namespace", namespace, "ServiceAccount.Name", name)
+		return ""
+	}
+	recordTokenExpiration(ctx, expiresAt)
+	return token
+}
+
 func resolveVaultSecret(logger logr.Logger, data map[string]interface{}, key string) string {
 	if v2Data, ok := data["data"].(map[string]interface{}); ok {
 		if value, ok := v2Data[key]; ok {
@@ -457,3 +457,189 @@ func TestResolveDependentEnv(t *testing.T) {
 		})
 	}
 }
+
+func TestResolveTriggerMetadata(t *testing.T) {
+	existing := []runtime.Object{
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: secretName},
+			Data:       map[string][]byte{secretKey: []byte(secretData)},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: "config"},
+			Data:       map[string]string{"queueName": "orders"},
+		},
+	}
+	resolvedEnv := map[string]string{envKey: envValue}
+
+	tests := []struct {
+		name             string
+		trigger          *kedav1alpha1.ScaleTriggers
+		isError          bool
+		expectedMetadata map[string]string
+		expectedSecrets  map[string]string
+	}{
+		{
+			name:             "fromEnv resolved into the parameter",
+			trigger:          &kedav1alpha1.ScaleTriggers{Metadata: map[string]string{"hostFromEnv": envKey}},
+			expectedMetadata: map[string]string{"hostFromEnv": envKey, "host": envValue},
+			expectedSecrets:  map[string]string{},
+		},
+		{
+			name:             "parameter takes precedence over fromEnv",
+			trigger:          &kedav1alpha1.ScaleTriggers{Metadata: map[string]string{"host": "localhost", "hostFromEnv": envKey}},
+			expectedMetadata: map[string]string{"host": "localhost", "hostFromEnv": envKey},
+			expectedSecrets:  map[string]string{},
+		},
+		{
+			name:             "fromEnv with missing env var is left to the scaler",
+			trigger:          &kedav1alpha1.ScaleTriggers{Metadata: map[string]string{"hostFromEnv": "missing"}},
+			expectedMetadata: map[string]string{"hostFromEnv": "missing"},
+			expectedSecrets:  map[string]string{},
+		},
+		{
+			name: "valueFrom secret and configmap",
+			trigger: &kedav1alpha1.ScaleTriggers{
+				Metadata: map[string]string{"targetValue": "5"},
+				ValueFrom: []kedav1alpha1.TriggerMetadataValueFrom{
+					{
+						Parameter:    "password",
+						SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: secretName}, Key: secretKey},
+					},
+					{
+						Parameter:       "queueName",
+						ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "config"}, Key: "queueName"},
+					},
+				},
+			},
+			expectedMetadata: map[string]string{"targetValue": "5", "password": secretData, "queueName": "orders"},
+			expectedSecrets:  map[string]string{"password": secretData},
+		},
+		{
+			name: "valueFrom missing optional configmap",
+			trigger: &kedav1alpha1.ScaleTriggers{
+				Metadata: map[string]string{},
+				ValueFrom: []kedav1alpha1.TriggerMetadataValueFrom{{
+					Parameter:       "queueName",
+					ConfigMapKeyRef: &corev1.ConfigMapKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "notthere"}, Key: "queueName", Optional: &trueValue},
+				}},
+			},
+			expectedMetadata: map[string]string{"queueName": ""},
+			expectedSecrets:  map[string]string{},
+		},
+		{
+			name: "valueFrom missing secret",
+			trigger: &kedav1alpha1.ScaleTriggers{
+				Metadata: map[string]string{},
+				ValueFrom: []kedav1alpha1.TriggerMetadataValueFrom{{
+					Parameter:    "password",
+					SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: "notthere"}, Key: secretKey},
+				}},
+			},
+			isError: true,
+		},
+		{
+			name: "valueFrom parameter also set in metadata",
+			trigger: &kedav1alpha1.ScaleTriggers{
+				Metadata: map[string]string{"password": "plain"},
+				ValueFrom: []kedav1alpha1.TriggerMetadataValueFrom{{
+					Parameter:    "password",
+					SecretKeyRef: &corev1.SecretKeySelector{LocalObjectReference: corev1.LocalObjectReference{Name: secretName}, Key: secretKey},
+				}},
+			},
+			isError: true,
+		},
+		{
+			name: "valueFrom without a source",
+			trigger: &kedav1alpha1.ScaleTriggers{
+				Metadata:  map[string]string{},
+				ValueFrom: []kedav1alpha1.TriggerMetadataValueFrom{{Parameter: "password"}},
+			},
+			isError: true,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			gotMetadata, gotSecrets, err := ResolveTriggerMetadata(context.Background(), fake.NewFakeClientWithScheme(scheme.Scheme, existing...), test.trigger, resolvedEnv, namespace)
+			if test.isError {
+				if err == nil {
+					t.Error("Expected error but got success")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected success but got error: %s", err)
+			}
+			if diff := cmp.Diff(gotMetadata, test.expectedMetadata); diff != "" {
+				t.Errorf("Returned metadata is different: %s", diff)
+			}
+			if diff := cmp.Diff(gotSecrets, test.expectedSecrets); diff != "" {
+				t.Errorf("Returned secrets are different: %s", diff)
+			}
+		})
+	}
+}
+
+func TestExpandTriggerMetadata(t *testing.T) {
+	withTriggers := &kedav1alpha1.WithTriggers{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "orders-api",
+			Namespace: namespace,
+			Labels:    map[string]string{"app.kubernetes.io/part-of": "shop"},
+		},
+	}
+
+	tests := []struct {
+		name     string
+		metadata map[string]string
+		expected map[string]string
+		isError  bool
+	}{
+		{
+			name:     "no templates",
+			metadata: map[string]string{"query": `sum(rate(http_requests_total{app="orders"}[2m]))`},
+			expected: map[string]string{"query": `sum(rate(http_requests_total{app="orders"}[2m]))`},
+		},
+		{
+			name: "object fields and labels",
+			metadata: map[string]string{
+				"queueName": "{{.ObjectMeta.Name}}-queue",
+				"vhost":     "{{.Namespace}}",
+				"topic":     `{{index .Labels "app.kubernetes.io/part-of"}}.{{.Name}}`,
+			},
+			expected: map[string]string{
+				"queueName": "orders-api-queue",
+				"vhost":     namespace,
+				"topic":     "shop.orders-api",
+			},
+		},
+		{
+			name:     "missing label",
+			metadata: map[string]string{"queueName": "{{.Labels.team}}"},
+			isError:  true,
+		},
+		{
+			name:     "malformed template",
+			metadata: map[string]string{"queueName": "{{.Name"},
+			isError:  true,
+		},
+	}
+	for _, test := range tests {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			got, err := ExpandTriggerMetadata(test.metadata, withTriggers)
+			if test.isError {
+				if err == nil {
+					t.Error("Expected error but got success")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Expected success but got error: %s", err)
+			}
+			if diff := cmp.Diff(got, test.expected); diff != "" {
+				t.Errorf("Returned metadata is different: %s", diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,242 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	corev1client "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// SecretResolverServiceAccountEnvVar is the name of a ServiceAccount in the KEDA namespace,
+// when set Secrets and ConfigMaps are read by impersonating this ServiceAccount
+const SecretResolverServiceAccountEnvVar = "KEDA_SECRET_RESOLVER_SERVICE_ACCOUNT"
+
+// SecretResolverServiceAccount is the only ServiceAccount the RBAC of the operator allows to impersonate
+const SecretResolverServiceAccount = "keda-secret-resolver"
+
+// SecretAuditLogEnvVar turns the log of every Secret and ConfigMap read off when set to false, it is on by default
+const SecretAuditLogEnvVar = "KEDA_SECRET_AUDIT_LOG"
+
+// BoundServiceAccountTokenAnnotation has to be "true" on a ServiceAccount for TriggerAuthentications to request tokens for it
+const BoundServiceAccountTokenAnnotation = "keda.sh/allow-bound-service-account-token"
+
+// defaultBoundServiceAccountTokenExpirationSeconds is the lifetime of the requested tokens when the
+// TriggerAuthentication doesn't set expirationSeconds
+const defaultBoundServiceAccountTokenExpirationSeconds = int64(3600)
+
+type requesterContextKey struct{}
+
+type tokenRefreshContextKey struct{}
+
+// tokenRefresh records the earliest time a token resolved with a context has to be requested again
+type tokenRefresh struct {
+	lock      sync.Mutex
+	refreshAt time.Time
+}
+
+// Requester identifies the scalable object on whose behalf Secrets and ConfigMaps are read
+type Requester struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+// WithRequester returns a copy of ctx carrying the Requester that is logged for every Secret and ConfigMap access
+func WithRequester(ctx context.Context, requester Requester) context.Context {
+	return context.WithValue(ctx, requesterContextKey{}, requester)
+}
+
+func requesterFromContext(ctx context.Context) Requester {
+	if requester, ok := ctx.Value(requesterContextKey{}).(Requester); ok {
+		return requester
+	}
+	return Requester{}
+}
+
+// WithTokenRefresh returns a copy of ctx recording when the ServiceAccount tokens resolved with it have to be
+// requested again, see TokenRefreshTime
+func WithTokenRefresh(ctx context.Context) context.Context {
+	return context.WithValue(ctx, tokenRefreshContextKey{}, &tokenRefresh{})
+}
+
+// TokenRefreshTime returns the time the earliest expiring ServiceAccount token resolved with ctx has to be requested
+// again, the zero time if no token was resolved with it
+func TokenRefreshTime(ctx context.Context) time.Time {
+	if refresh, ok := ctx.Value(tokenRefreshContextKey{}).(*tokenRefresh); ok {
+		refresh.lock.Lock()
+		defer refresh.lock.Unlock()
+		return refresh.refreshAt
+	}
+	return time.Time{}
+}
+
+// recordTokenExpiration records a token issued now and expiring at expiresAt, it is refreshed after 80% of its lifetime
+func recordTokenExpiration(ctx context.Context, expiresAt time.Time) {
+	refresh, ok := ctx.Value(tokenRefreshContextKey{}).(*tokenRefresh)
+	if !ok {
+		return
+	}
+	now := time.Now()
+	refreshAt := now.Add(expiresAt.Sub(now) * 4 / 5)
+
+	refresh.lock.Lock()
+	defer refresh.lock.Unlock()
+	if refresh.refreshAt.IsZero() || refreshAt.Before(refresh.refreshAt) {
+		refresh.refreshAt = refreshAt
+	}
+}
+
+// serviceAccountTokenRequester requests tokens for ServiceAccounts, it is implemented by the client returned by NewSecretsClient
+type serviceAccountTokenRequester interface {
+	RequestServiceAccountToken(ctx context.Context, namespace, name string, audiences []string, expirationSeconds int64) (string, time.Time, error)
+}
+
+// secretsClient reads Secrets and ConfigMaps through a dedicated reader and logs every access,
+// all other requests are served by the embedded client
+type secretsClient struct {
+	client.Client
+	secretsReader client.Reader
+	tokenClient   corev1client.ServiceAccountsGetter
+	logger        logr.Logger
+	auditLog      bool
+}
+
+// NewSecretsClient wraps kubeClient so that all Secret and ConfigMap reads are audit logged with
+// the identity of the requesting scalable object, unless KEDA_SECRET_AUDIT_LOG is false. If KEDA_SECRET_RESOLVER_SERVICE_ACCOUNT is set,
+// the reads impersonate that ServiceAccount, so its RBAC limits which credentials KEDA can resolve.
+// config/secret-resolver has the manifests of the keda-secret-resolver ServiceAccount the operator may impersonate.
+func NewSecretsClient(config *rest.Config, scheme *runtime.Scheme, kubeClient client.Client) (client.Client, error) {
+	logger := logf.Log.WithName("secret_audit")
+	auditLog := true
+	if val, ok := os.LookupEnv(SecretAuditLogEnvVar); ok && val != "" {
+		var err error
+		auditLog, err = strconv.ParseBool(val)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing %s: %s", SecretAuditLogEnvVar, err)
+		}
+	}
+	var secretsReader client.Reader = kubeClient
+	tokenConfig := config
+
+	if serviceAccount := os.Getenv(SecretResolverServiceAccountEnvVar); serviceAccount != "" {
+		if serviceAccount != SecretResolverServiceAccount {
+			return nil, fmt.Errorf("%s has to be %s, the RBAC of KEDA only allows to impersonate it", SecretResolverServiceAccountEnvVar, SecretResolverServiceAccount)
+		}
+		namespace, err := getClusterObjectNamespace()
+		if err != nil {
+			return nil, fmt.Errorf("error getting KEDA namespace for %s: %s", SecretResolverServiceAccountEnvVar, err)
+		}
+
+		impersonatedConfig := rest.CopyConfig(config)
+		impersonatedConfig.Impersonate = rest.ImpersonationConfig{
+			UserName: fmt.Sprintf("system:serviceaccount:%s:%s", namespace, serviceAccount),
+		}
+		// the impersonated reads go directly to the API server, so they show up in its audit log under the ServiceAccount
+		secretsReader, err = client.New(impersonatedConfig, client.Options{Scheme: scheme})
+		if err != nil {
+			return nil, fmt.Errorf("error creating client impersonating %s: %s", impersonatedConfig.Impersonate.UserName, err)
+		}
+		logger = logger.WithValues("impersonate", impersonatedConfig.Impersonate.UserName)
+		tokenConfig = impersonatedConfig
+	}
+
+	tokenClient, err := corev1client.NewForConfig(tokenConfig)
+	if err != nil {
+		return nil, fmt.Errorf("error creating client for ServiceAccount tokens: %s", err)
+	}
+
+	return &secretsClient{
+		Client:        kubeClient,
+		secretsReader: secretsReader,
+		tokenClient:   tokenClient,
+		logger:        logger,
+		auditLog:      auditLog,
+	}, nil
+}
+
+func (c *secretsClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	var kind string
+	switch obj.(type) {
+	case *corev1.Secret:
+		kind = "Secret"
+	case *corev1.ConfigMap:
+		kind = "ConfigMap"
+	default:
+		return c.Client.Get(ctx, key, obj)
+	}
+
+	err := c.secretsReader.Get(ctx, key, obj)
+	if c.auditLog {
+		requester := requesterFromContext(ctx)
+		c.logger.Info("Resolving "+kind, "kind", kind, "namespace", key.Namespace, "name", key.Name,
+			"requester.kind", requester.Kind, "requester.namespace", requester.Namespace, "requester.name", requester.Name,
+			"success", err == nil)
+	}
+	return err
+}
+
+// RequestServiceAccountToken requests a bound token for the ServiceAccount with the TokenRequest API and returns it
+// with its expiration time. Only ServiceAccounts annotated with BoundServiceAccountTokenAnnotation outside of the
+// KEDA namespace can be requested, otherwise anyone allowed to write a TriggerAuthentication could get their tokens
+func (c *secretsClient) RequestServiceAccountToken(ctx context.Context, namespace, name string, audiences []string, expirationSeconds int64) (string, time.Time, error) {
+	requester := requesterFromContext(ctx)
+	logger := c.logger.WithValues("kind", "ServiceAccount", "namespace", namespace, "name", name,
+		"requester.kind", requester.Kind, "requester.namespace", requester.Namespace, "requester.name", requester.Name)
+
+	kedaNamespace, err := getClusterObjectNamespace()
+	if err != nil {
+		return "", time.Time{}, fmt.Errorf("error getting KEDA namespace: %s", err)
+	}
+	if namespace == kedaNamespace {
+		return "", time.Time{}, fmt.Errorf("tokens can't be requested for ServiceAccounts in the KEDA namespace %s", kedaNamespace)
+	}
+
+	serviceAccount, err := c.tokenClient.ServiceAccounts(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if serviceAccount.Annotations[BoundServiceAccountTokenAnnotation] != "true" {
+		return "", time.Time{}, fmt.Errorf("ServiceAccount %s/%s doesn't allow token requests, annotate it with %s: \"true\"", namespace, name, BoundServiceAccountTokenAnnotation)
+	}
+
+	tokenRequest := &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{
+			Audiences:         audiences,
+			ExpirationSeconds: &expirationSeconds,
+		},
+	}
+	tokenRequest, err = c.tokenClient.ServiceAccounts(namespace).CreateToken(ctx, name, tokenRequest, metav1.CreateOptions{})
+	logger.V(1).Info("Requesting ServiceAccount token", "success", err == nil)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	return tokenRequest.Status.Token, tokenRequest.Status.ExpirationTimestamp.Time, nil
+}
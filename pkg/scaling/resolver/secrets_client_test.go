@@ -0,0 +1,187 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package resolver
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	k8stesting "k8s.io/client-go/testing"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+func TestSecretsClientRoutesSecretReads(t *testing.T) {
+	// the kube client can read every object, the secrets reader only the allowed Secret and ConfigMap
+	kubeClient := fake.NewFakeClientWithScheme(scheme.Scheme,
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: namespace}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "forbidden", Namespace: namespace}},
+	)
+	secretsReader := fake.NewFakeClientWithScheme(scheme.Scheme,
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: namespace}, Data: map[string][]byte{secretKey: []byte(secretData)}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "config", Namespace: namespace}},
+	)
+	c := &secretsClient{Client: kubeClient, secretsReader: secretsReader, logger: logf.Log.WithName("test")}
+	ctx := WithRequester(context.Background(), Requester{Kind: "ScaledObject", Namespace: namespace, Name: "so"})
+
+	secret := &corev1.Secret{}
+	if err := c.Get(ctx, types.NamespacedName{Name: secretName, Namespace: namespace}, secret); err != nil {
+		t.Errorf("Expected success reading Secret but got error: %s", err)
+	} else if string(secret.Data[secretKey]) != secretData {
+		t.Errorf("Expected Secret data %s, got %s", secretData, string(secret.Data[secretKey]))
+	}
+	if err := c.Get(ctx, types.NamespacedName{Name: "config", Namespace: namespace}, &corev1.ConfigMap{}); err != nil {
+		t.Errorf("Expected success reading ConfigMap but got error: %s", err)
+	}
+	if err := c.Get(ctx, types.NamespacedName{Name: "forbidden", Namespace: namespace}, &corev1.Secret{}); err == nil {
+		t.Error("Expected Secret not visible to the secrets reader to fail")
+	}
+	if err := c.Get(ctx, types.NamespacedName{Name: "pod", Namespace: namespace}, &corev1.Pod{}); err != nil {
+		t.Errorf("Expected other objects to be read with the kube client but got error: %s", err)
+	}
+}
+
+func TestRequesterFromContext(t *testing.T) {
+	if requester := requesterFromContext(context.Background()); requester != (Requester{}) {
+		t.Errorf("Expected empty requester, got %v", requester)
+	}
+	expected := Requester{Kind: "ScaledJob", Namespace: namespace, Name: "job"}
+	if requester := requesterFromContext(WithRequester(context.Background(), expected)); requester != expected {
+		t.Errorf("Expected requester %v, got %v", expected, requester)
+	}
+}
+
+func TestResolveBoundServiceAccountToken(t *testing.T) {
+	if err := kedav1alpha1.AddToScheme(scheme.Scheme); err != nil {
+		t.Errorf("Expected Error because: %v", err)
+	}
+	kubeClient := fake.NewFakeClientWithScheme(scheme.Scheme,
+		&kedav1alpha1.TriggerAuthentication{
+			ObjectMeta: metav1.ObjectMeta{Name: triggerAuthenticationName, Namespace: namespace},
+			Spec: kedav1alpha1.TriggerAuthenticationSpec{
+				BoundServiceAccountToken: []kedav1alpha1.BoundServiceAccountToken{
+					{Parameter: "bearerToken", ServiceAccountName: "prometheus-reader", Audiences: []string{"prometheus"}},
+					{Parameter: "notAllowedToken", ServiceAccountName: "default"},
+				},
+			},
+		},
+		&kedav1alpha1.ClusterTriggerAuthentication{
+			ObjectMeta: metav1.ObjectMeta{Name: triggerAuthenticationName},
+			Spec: kedav1alpha1.TriggerAuthenticationSpec{
+				BoundServiceAccountToken: []kedav1alpha1.BoundServiceAccountToken{
+					{Parameter: "bearerToken", ServiceAccountName: "keda-operator"},
+				},
+			},
+		},
+	)
+	clusterNamespace := "keda"
+	clusterObjectNamespaceCache = &clusterNamespace
+	allowed := map[string]string{BoundServiceAccountTokenAnnotation: "true"}
+	clientset := k8sfake.NewSimpleClientset(
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "prometheus-reader", Namespace: namespace, Annotations: allowed}},
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "default", Namespace: namespace}},
+		&corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: "keda-operator", Namespace: clusterNamespace, Annotations: allowed}},
+	)
+	expiresAt := time.Now().Add(time.Hour)
+	clientset.PrependReactor("create", "serviceaccounts", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		createAction := action.(k8stesting.CreateActionImpl)
+		if createAction.GetSubresource() != "token" {
+			return false, nil, nil
+		}
+		tokenRequest := createAction.GetObject().(*authenticationv1.TokenRequest)
+		if *tokenRequest.Spec.ExpirationSeconds != 3600 {
+			t.Errorf("Expected token requested for 3600 seconds, got %d", *tokenRequest.Spec.ExpirationSeconds)
+		}
+		tokenRequest.Status.Token = fmt.Sprintf("%s/%s/%v", createAction.GetNamespace(), createAction.Name, tokenRequest.Spec.Audiences)
+		tokenRequest.Status.ExpirationTimestamp = metav1.NewTime(expiresAt)
+		return true, tokenRequest, nil
+	})
+	c := &secretsClient{Client: kubeClient, secretsReader: kubeClient, tokenClient: clientset.CoreV1(), logger: logf.Log.WithName("test")}
+	authRef := &kedav1alpha1.ScaledObjectAuthRef{Name: triggerAuthenticationName}
+
+	ctx := WithTokenRefresh(context.Background())
+	authParams, _ := resolveAuthRef(ctx, c, logf.Log.WithName("test"), authRef, nil, namespace)
+	if expected := namespace + "/prometheus-reader/[prometheus]"; authParams["bearerToken"] != expected {
+		t.Errorf("Expected token %s, got %s", expected, authParams["bearerToken"])
+	}
+	// the token is requested again after 80% of its lifetime
+	if refreshAt := TokenRefreshTime(ctx); refreshAt.IsZero() || !refreshAt.Before(expiresAt.Add(-10*time.Minute)) {
+		t.Errorf("Expected token refresh before %s, got %s", expiresAt.Add(-10*time.Minute), refreshAt)
+	}
+
+	// ServiceAccounts that didn't opt in resolve an empty token
+	if authParams["notAllowedToken"] != "" {
+		t.Errorf("Expected empty token, got %s", authParams["notAllowedToken"])
+	}
+
+	// ServiceAccounts of the KEDA namespace resolve an empty token
+	clusterAuthRef := &kedav1alpha1.ScaledObjectAuthRef{Name: triggerAuthenticationName, Kind: "ClusterTriggerAuthentication"}
+	authParams, _ = resolveAuthRef(context.Background(), c, logf.Log.WithName("test"), clusterAuthRef, nil, namespace)
+	if authParams["bearerToken"] != "" {
+		t.Errorf("Expected empty token, got %s", authParams["bearerToken"])
+	}
+
+	// a client not supporting token requests resolves an empty token
+	authParams, _ = resolveAuthRef(context.Background(), kubeClient, logf.Log.WithName("test"), authRef, nil, namespace)
+	if authParams["bearerToken"] != "" {
+		t.Errorf("Expected empty token, got %s", authParams["bearerToken"])
+	}
+}
+
+func TestNewSecretsClientAuditLog(t *testing.T) {
+	t.Setenv(SecretAuditLogEnvVar, "")
+	c, err := NewSecretsClient(&rest.Config{}, scheme.Scheme, nil)
+	if err != nil {
+		t.Fatalf("Expected success but got error: %s", err)
+	}
+	if !c.(*secretsClient).auditLog {
+		t.Error("Expected the audit log to be on by default")
+	}
+
+	t.Setenv(SecretAuditLogEnvVar, "false")
+	c, err = NewSecretsClient(&rest.Config{}, scheme.Scheme, nil)
+	if err != nil {
+		t.Fatalf("Expected success but got error: %s", err)
+	}
+	if c.(*secretsClient).auditLog {
+		t.Errorf("Expected the audit log to be off with %s false", SecretAuditLogEnvVar)
+	}
+
+	t.Setenv(SecretAuditLogEnvVar, "sometimes")
+	if _, err := NewSecretsClient(&rest.Config{}, scheme.Scheme, nil); err == nil {
+		t.Errorf("Expected error for an invalid %s", SecretAuditLogEnvVar)
+	}
+}
+
+func TestNewSecretsClientRejectsOtherServiceAccounts(t *testing.T) {
+	t.Setenv(SecretResolverServiceAccountEnvVar, "reader")
+	if _, err := NewSecretsClient(&rest.Config{}, scheme.Scheme, nil); err == nil {
+		t.Errorf("Expected error for a ServiceAccount other than %s", SecretResolverServiceAccount)
+	}
+}
@@ -24,6 +24,7 @@ import (
 	"time"
 
 	"github.com/go-logr/logr"
+	autoscalingv2beta2 "k8s.io/api/autoscaling/v2beta2"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
@@ -34,10 +35,12 @@ import (
 
 	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
 	"github.com/kedacore/keda/v2/pkg/eventreason"
+	"github.com/kedacore/keda/v2/pkg/metrics"
 	"github.com/kedacore/keda/v2/pkg/scalers"
 	"github.com/kedacore/keda/v2/pkg/scaling/cache"
 	"github.com/kedacore/keda/v2/pkg/scaling/executor"
 	"github.com/kedacore/keda/v2/pkg/scaling/resolver"
+	kedautil "github.com/kedacore/keda/v2/pkg/util"
 )
 
 // ScaleHandler encapsulates the logic of calling the right scalers for
@@ -57,20 +60,44 @@ type scaleHandler struct {
 	globalHTTPTimeout time.Duration
 	recorder          record.EventRecorder
 	scalerCaches      map[string]*cache.ScalersCache
+	canaryCaches      map[string]*cache.ScalersCache
 	lock              *sync.RWMutex
+	workerPool        *workerPool
+	scalerTypeLimits  scalerTypeLimits
+	credentialBudgets *credentialBudgets
+	hpaNameTemplate   string
 }
 
 // NewScaleHandler creates a ScaleHandler object
 func NewScaleHandler(client client.Client, scaleClient scale.ScalesGetter, reconcilerScheme *runtime.Scheme, globalHTTPTimeout time.Duration, recorder record.EventRecorder) ScaleHandler {
+	logger := logf.Log.WithName("scalehandler")
+	workers, limits, err := resolveConcurrencyConfig()
+	if err != nil {
+		logger.Error(err, "Error resolving scaler concurrency settings")
+	}
+	budgets, err := resolveCredentialBudgets()
+	if err != nil {
+		logger.Error(err, "Error resolving scaler rate limits")
+	}
+	hpaNameTemplate, err := kedautil.ResolveHPANameTemplate()
+	if err != nil {
+		logger.Error(err, "Error resolving HPA name template")
+	}
+
 	return &scaleHandler{
 		client:            client,
-		logger:            logf.Log.WithName("scalehandler"),
+		logger:            logger,
 		scaleLoopContexts: &sync.Map{},
 		scaleExecutor:     executor.NewScaleExecutor(client, scaleClient, reconcilerScheme, recorder),
 		globalHTTPTimeout: globalHTTPTimeout,
 		recorder:          recorder,
 		scalerCaches:      map[string]*cache.ScalersCache{},
+		canaryCaches:      map[string]*cache.ScalersCache{},
 		lock:              &sync.RWMutex{},
+		workerPool:        newWorkerPool(workers),
+		scalerTypeLimits:  limits,
+		credentialBudgets: budgets,
+		hpaNameTemplate:   hpaNameTemplate,
 	}
 }
 
@@ -127,6 +154,9 @@ func (h *scaleHandler) DeleteScalableObject(ctx context.Context, scalableObject
 		}
 		h.scaleLoopContexts.Delete(key)
 		h.recorder.Event(withTriggers, corev1.EventTypeNormal, eventreason.KEDAScalersStopped, "Stopped scalers watch")
+		if _, ok := scalableObject.(*kedav1alpha1.ScaledObject); ok {
+			metrics.DeleteScaledObjectMetrics(withTriggers.Namespace, withTriggers.Name)
+		}
 	} else {
 		h.logger.V(1).Info("ScaleObject was not found in controller cache", "key", key)
 	}
@@ -134,7 +164,8 @@ func (h *scaleHandler) DeleteScalableObject(ctx context.Context, scalableObject
 	return nil
 }
 
-// startScaleLoop blocks forever and checks the scaledObject based on its pollingInterval
+// startScaleLoop blocks forever and checks the scaledObject based on its pollingInterval,
+// the checks run on the worker pool so only a bounded number of scalable objects are checked at once
 func (h *scaleHandler) startScaleLoop(ctx context.Context, withTriggers *kedav1alpha1.WithTriggers, scalableObject interface{}, scalingMutex sync.Locker) {
 	logger := h.logger.WithValues("type", withTriggers.Kind, "namespace", withTriggers.Namespace, "name", withTriggers.Name)
 
@@ -143,7 +174,9 @@ func (h *scaleHandler) startScaleLoop(ctx context.Context, withTriggers *kedav1a
 
 	for {
 		tmr := time.NewTimer(pollingInterval)
-		h.checkScalers(ctx, scalableObject, scalingMutex)
+		h.workerPool.run(ctx, func() {
+			h.checkScalers(ctx, scalableObject, scalingMutex)
+		})
 
 		select {
 		case <-tmr.C:
@@ -166,7 +199,7 @@ func (h *scaleHandler) GetScalersCache(ctx context.Context, scalableObject inter
 	key := strings.ToLower(fmt.Sprintf("%s.%s.%s", withTriggers.Kind, withTriggers.Name, withTriggers.Namespace))
 
 	h.lock.RLock()
-	if cache, ok := h.scalerCaches[key]; ok && cache.Generation == withTriggers.Generation {
+	if cache, ok := h.scalerCaches[key]; ok && cache.IsCurrent(withTriggers.Generation) {
 		h.lock.RUnlock()
 		return cache, nil
 	}
@@ -174,7 +207,7 @@ func (h *scaleHandler) GetScalersCache(ctx context.Context, scalableObject inter
 
 	h.lock.Lock()
 	defer h.lock.Unlock()
-	if cache, ok := h.scalerCaches[key]; ok && cache.Generation == withTriggers.Generation {
+	if cache, ok := h.scalerCaches[key]; ok && cache.IsCurrent(withTriggers.Generation) {
 		return cache, nil
 	} else if ok {
 		cache.Close(ctx)
@@ -185,18 +218,33 @@ func (h *scaleHandler) GetScalersCache(ctx context.Context, scalableObject inter
 		return nil, err
 	}
 
-	scalers := h.buildScalers(ctx, withTriggers, podTemplateSpec, containerName)
+	tokenCtx := resolver.WithTokenRefresh(ctx)
+	scalers := h.buildScalers(tokenCtx, withTriggers, podTemplateSpec, containerName)
 
 	h.scalerCaches[key] = &cache.ScalersCache{
-		Generation: withTriggers.Generation,
-		Scalers:    scalers,
-		Logger:     h.logger,
-		Recorder:   h.recorder,
+		Generation:  withTriggers.Generation,
+		Scalers:     scalers,
+		Logger:      h.logger,
+		Recorder:    h.recorder,
+		CallTimeout: h.scalerCallTimeout(withTriggers),
+		Namespace:   withTriggers.Namespace,
+		Name:        withTriggers.Name,
+		RefreshAt:   resolver.TokenRefreshTime(tokenCtx),
 	}
 
 	return h.scalerCaches[key], nil
 }
 
+// scalerCallTimeout returns the timeout of the calls to the scalers, a call can take up to the polling interval so it
+// doesn't delay the next poll, but at least the global HTTP timeout the scalers already apply to their requests
+func (h *scaleHandler) scalerCallTimeout(withTriggers *kedav1alpha1.WithTriggers) time.Duration {
+	timeout := withTriggers.GetPollingInterval()
+	if timeout < h.globalHTTPTimeout {
+		timeout = h.globalHTTPTimeout
+	}
+	return timeout
+}
+
 func (h *scaleHandler) ClearScalersCache(ctx context.Context, name, namespace string) {
 	h.lock.Lock()
 	defer h.lock.Unlock()
@@ -206,6 +254,10 @@ func (h *scaleHandler) ClearScalersCache(ctx context.Context, name, namespace st
 		cache.Close(ctx)
 		delete(h.scalerCaches, key)
 	}
+	if cache, ok := h.canaryCaches[key]; ok {
+		cache.Close(ctx)
+		delete(h.canaryCaches, key)
+	}
 }
 
 func (h *scaleHandler) startPushScalers(ctx context.Context, withTriggers *kedav1alpha1.WithTriggers, scalableObject interface{}, scalingMutex sync.Locker) {
@@ -231,7 +283,7 @@ func (h *scaleHandler) startPushScalers(ctx context.Context, withTriggers *kedav
 					case *kedav1alpha1.ScaledObject:
 						h.scaleExecutor.RequestScale(ctx, obj, active, false)
 					case *kedav1alpha1.ScaledJob:
-						h.logger.Info("Warning: External Push Scaler does not support ScaledJob", "object", scalableObject)
+						h.logger.Info("Warning: Push Scalers do not support ScaledJob", "object", scalableObject)
 					}
 					scalingMutex.Unlock()
 				}
@@ -258,52 +310,111 @@ func (h *scaleHandler) checkScalers(ctx context.Context, scalableObject interfac
 			h.logger.Error(err, "Error getting scaledObject", "object", scalableObject)
 			return
 		}
-		isActive, isError, _ := cache.IsScaledObjectActive(ctx, obj)
+		hpa := &autoscalingv2beta2.HorizontalPodAutoscaler{}
+		// the name of the HPA created by the ScaledObject controller, it is recorded in the status once
+		// the HPA with the name from the configured template exists
+		hpaName := obj.Status.HpaName
+		if hpaName == "" {
+			hpaName = kedautil.GenerateHPAName(h.hpaNameTemplate, obj)
+		}
+		if err := h.client.Get(ctx, types.NamespacedName{Name: hpaName, Namespace: obj.Namespace}, hpa); err != nil {
+			// the HPA may not be created yet, the checks below work without it
+			h.logger.V(1).Info("HPA not available", "HPA.Name", hpaName, "object", scalableObject, "error", err.Error())
+			hpa = nil
+		}
+
+		// the cpu/memory scalers with an activationValue compare it to the usage reported by the HPA
+		activationCtx := ctx
+		if hpa != nil {
+			activationCtx = scalers.WithResourceMetrics(ctx, hpa.Status.CurrentMetrics)
+		}
+		isActive, isError, _ := cache.IsScaledObjectActive(activationCtx, withAppliedTriggers(obj))
 		h.scaleExecutor.RequestScale(ctx, obj, isActive, isError)
+		h.evaluateCanary(activationCtx, cache, obj, isActive, isError)
+
+		h.updateMaxReplicaCount(ctx, cache, obj, hpa)
+		if isActive && obj.Spec.Advanced != nil && obj.Spec.Advanced.DirectScalingFallback {
+			h.directScaleIfHPAFailing(ctx, cache, obj, hpa)
+		}
+		h.scaleExecutor.RequestAtMaxReplicasCheck(ctx, obj, hpa)
 	case *kedav1alpha1.ScaledJob:
 		err = h.client.Get(ctx, types.NamespacedName{Name: obj.Name, Namespace: obj.Namespace}, obj)
 		if err != nil {
 			h.logger.Error(err, "Error getting scaledJob", "object", scalableObject)
 			return
 		}
-		isActive, scaleTo, maxScale := cache.IsScaledJobActive(ctx, obj)
-		h.scaleExecutor.RequestJobScale(ctx, obj, isActive, scaleTo, maxScale)
+		isActive, scaleTo, maxScale, trigger := cache.IsScaledJobActive(ctx, obj)
+		h.scaleExecutor.RequestJobScale(ctx, obj, isActive, scaleTo, maxScale, trigger, jobParametersFunc(cache, obj, trigger))
+	}
+}
+
+// directScaleIfHPAFailing scales the ScaleTarget directly to the replica count computed from the scalers,
+// if the HPA of the ScaledObject is not able to get the metrics from the KEDA Metrics Server or is nil because
+// it couldn't be read, the replicas are bounded by the HPA or by the ScaledObject without HPA
+func (h *scaleHandler) directScaleIfHPAFailing(ctx context.Context, scalersCache *cache.ScalersCache, scaledObject *kedav1alpha1.ScaledObject, hpa *autoscalingv2beta2.HorizontalPodAutoscaler) {
+	logger := h.logger.WithValues("scaledObject.Namespace", scaledObject.Namespace, "scaledObject.Name", scaledObject.Name)
+
+	if hpa != nil && !isHPAFailingToGetExternalMetrics(hpa) {
+		return
+	}
+
+	desiredReplicas, err := scalersCache.GetScaledObjectDesiredReplicas(ctx)
+	if err != nil {
+		logger.Error(err, "Error computing replicas count for direct scaling")
+		return
+	}
+
+	minReplicas, maxReplicas := scaledObject.Spec.MinReplicaCount, scaledObjectMaxReplicas(scaledObject)
+	if hpa != nil {
+		minReplicas, maxReplicas = hpa.Spec.MinReplicas, hpa.Spec.MaxReplicas
+	}
+	h.scaleExecutor.RequestDirectScale(ctx, scaledObject, clampReplicas(desiredReplicas, minReplicas, maxReplicas))
+}
+
+// scaledObjectMaxReplicas returns the maxReplicas of the HPA of the ScaledObject, used while the HPA can't be read
+func scaledObjectMaxReplicas(scaledObject *kedav1alpha1.ScaledObject) int32 {
+	if scaledObject.Spec.MaxReplicaCountFrom != nil && scaledObject.Status.ResolvedMaxReplicaCount != nil {
+		return *scaledObject.Status.ResolvedMaxReplicaCount
+	}
+	if scaledObject.Spec.MaxReplicaCount != nil {
+		return *scaledObject.Spec.MaxReplicaCount
+	}
+	return defaultMaxReplicaCount
+}
+
+func isHPAFailingToGetExternalMetrics(hpa *autoscalingv2beta2.HorizontalPodAutoscaler) bool {
+	for _, condition := range hpa.Status.Conditions {
+		if condition.Type == autoscalingv2beta2.ScalingActive {
+			return condition.Status == corev1.ConditionFalse && condition.Reason == "FailedGetExternalMetric"
+		}
+	}
+	return false
+}
+
+func clampReplicas(replicas int64, minReplicas *int32, maxReplicas int32) int32 {
+	min := int64(1)
+	if minReplicas != nil && *minReplicas > 1 {
+		min = int64(*minReplicas)
+	}
+	switch {
+	case replicas < min:
+		return int32(min)
+	case replicas > int64(maxReplicas):
+		return maxReplicas
+	default:
+		return int32(replicas)
 	}
 }
 
 // buildScalers returns list of Scalers for the specified triggers
 func (h *scaleHandler) buildScalers(ctx context.Context, withTriggers *kedav1alpha1.WithTriggers, podTemplateSpec *corev1.PodTemplateSpec, containerName string) []cache.ScalerBuilder {
 	logger := h.logger.WithValues("type", withTriggers.Kind, "namespace", withTriggers.Namespace, "name", withTriggers.Name)
-	var err error
-	resolvedEnv := make(map[string]string)
+	ctx = resolver.WithRequester(ctx, resolver.Requester{Kind: withTriggers.Kind, Namespace: withTriggers.Namespace, Name: withTriggers.Name})
 	result := make([]cache.ScalerBuilder, 0, len(withTriggers.Spec.Triggers))
 
 	for scalerIndex, t := range withTriggers.Spec.Triggers {
 		triggerName, trigger := scalerIndex, t
-		factory := func() (scalers.Scaler, error) {
-			if podTemplateSpec != nil {
-				resolvedEnv, err = resolver.ResolveContainerEnv(ctx, h.client, logger, &podTemplateSpec.Spec, containerName, withTriggers.Namespace)
-				if err != nil {
-					return nil, fmt.Errorf("error resolving secrets for ScaleTarget: %s", err)
-				}
-			}
-			config := &scalers.ScalerConfig{
-				Name:              withTriggers.Name,
-				Namespace:         withTriggers.Namespace,
-				TriggerMetadata:   trigger.Metadata,
-				ResolvedEnv:       resolvedEnv,
-				AuthParams:        make(map[string]string),
-				GlobalHTTPTimeout: h.globalHTTPTimeout,
-				ScalerIndex:       scalerIndex,
-			}
-
-			config.AuthParams, config.PodIdentity, err = resolver.ResolveAuthRefAndPodIdentity(ctx, h.client, logger, trigger.AuthenticationRef, podTemplateSpec, withTriggers.Namespace)
-			if err != nil {
-				return nil, err
-			}
-
-			return buildScaler(ctx, h.client, trigger.Type, config)
-		}
+		factory := h.newScalerFactory(ctx, logger, withTriggers, scalerIndex, trigger, podTemplateSpec, containerName)
 
 		scaler, err := factory()
 		if err != nil {
@@ -316,106 +427,195 @@ func (h *scaleHandler) buildScalers(ctx context.Context, withTriggers *kedav1alp
 		}
 
 		result = append(result, cache.ScalerBuilder{
-			Scaler:  scaler,
-			Factory: factory,
+			Scaler:          scaler,
+			Factory:         factory,
+			TriggerName:     trigger.Name,
+			TriggerType:     trigger.Type,
+			TriggerMetadata: trigger.Metadata,
 		})
 	}
 
 	return result
 }
 
+// newScalerFactory returns the function building the scaler of the trigger with its resolved metadata and authentication
+func (h *scaleHandler) newScalerFactory(ctx context.Context, logger logr.Logger, withTriggers *kedav1alpha1.WithTriggers, scalerIndex int, trigger kedav1alpha1.ScaleTriggers, podTemplateSpec *corev1.PodTemplateSpec, containerName string) func() (scalers.Scaler, error) {
+	bounds := newMetricBounds(&trigger, withTriggers, h.recorder)
+	transform, transformErr := newMetricTransform(&trigger)
+	return func() (scalers.Scaler, error) {
+		if transformErr != nil {
+			return nil, transformErr
+		}
+		config, err := h.resolveScalerConfig(ctx, logger, withTriggers, scalerIndex, trigger, podTemplateSpec, containerName)
+		if err != nil {
+			return nil, err
+		}
+		return h.buildWrappedScaler(ctx, trigger, config, transform, bounds)
+	}
+}
+
+// resolveScalerConfig returns the config of the scaler of the trigger with its resolved metadata and authentication
+func (h *scaleHandler) resolveScalerConfig(ctx context.Context, logger logr.Logger, withTriggers *kedav1alpha1.WithTriggers, scalerIndex int, trigger kedav1alpha1.ScaleTriggers, podTemplateSpec *corev1.PodTemplateSpec, containerName string) (*scalers.ScalerConfig, error) {
+	var err error
+	resolvedEnv := make(map[string]string)
+	if podTemplateSpec != nil {
+		resolvedEnv, err = resolver.ResolveContainerEnv(ctx, h.client, logger, &podTemplateSpec.Spec, containerName, withTriggers.Namespace)
+		if err != nil {
+			return nil, fmt.Errorf("error resolving secrets for ScaleTarget: %s", err)
+		}
+	}
+	expandedTrigger := trigger
+	expandedTrigger.Metadata, err = resolver.ExpandTriggerMetadata(trigger.Metadata, withTriggers)
+	if err != nil {
+		return nil, err
+	}
+	triggerMetadata, secretParams, err := resolver.ResolveTriggerMetadata(ctx, h.client, &expandedTrigger, resolvedEnv, withTriggers.Namespace)
+	if err != nil {
+		return nil, fmt.Errorf("error resolving trigger metadata: %s", err)
+	}
+	config := &scalers.ScalerConfig{
+		Name:              withTriggers.Name,
+		Namespace:         withTriggers.Namespace,
+		TriggerMetadata:   triggerMetadata,
+		ResolvedEnv:       resolvedEnv,
+		AuthParams:        make(map[string]string),
+		GlobalHTTPTimeout: h.globalHTTPTimeout,
+		ScalerIndex:       scalerIndex,
+	}
+
+	config.AuthParams, config.PodIdentity, err = resolver.ResolveAuthRefAndPodIdentity(ctx, h.client, logger, trigger.AuthenticationRef, podTemplateSpec, withTriggers.Namespace)
+	if err != nil {
+		return nil, err
+	}
+	// values resolved from Secrets can be used wherever scalers expect auth params,
+	// parameters of the referenced TriggerAuthentication take precedence
+	for k, v := range secretParams {
+		if _, ok := config.AuthParams[k]; !ok {
+			config.AuthParams[k] = v
+		}
+	}
+	return config, nil
+}
+
+// buildWrappedScaler builds the scaler of the trigger wrapped by the metric inversion, bounds, transform and limits of the handler
+func (h *scaleHandler) buildWrappedScaler(ctx context.Context, trigger kedav1alpha1.ScaleTriggers, config *scalers.ScalerConfig, transform *metricTransform, bounds *metricBounds) (scalers.Scaler, error) {
+	inversion, err := newMetricInversion(&trigger)
+	if err != nil {
+		return nil, err
+	}
+
+	scaler, err := buildScaler(ctx, h.client, trigger.Type, config)
+	if err != nil {
+		return scaler, err
+	}
+	return inversion.wrap(bounds.wrap(transform.wrap(h.credentialBudgets.wrap(trigger.Type, config, h.scalerTypeLimits.wrap(trigger.Type, scaler))))), nil
+}
+
 func buildScaler(ctx context.Context, client client.Client, triggerType string, config *scalers.ScalerConfig) (scalers.Scaler, error) {
 	// TRIGGERS-START
 	switch triggerType {
+	case "activemq":
+		return scalers.NewActiveMQScaler(config)
 	case "artemis-queue":
 		return scalers.NewArtemisQueueScaler(config)
-	case "aws-cloudwatch":
-		return scalers.NewAwsCloudwatchScaler(config)
-	case "aws-kinesis-stream":
-		return scalers.NewAwsKinesisStreamScaler(config)
-	case "aws-sqs-queue":
-		return scalers.NewAwsSqsQueueScaler(config)
-	case "azure-blob":
-		return scalers.NewAzureBlobScaler(config)
-	case "azure-eventhub":
-		return scalers.NewAzureEventHubScaler(config)
-	case "azure-log-analytics":
-		return scalers.NewAzureLogAnalyticsScaler(config)
-	case "azure-monitor":
-		return scalers.NewAzureMonitorScaler(config)
-	case "azure-pipelines":
-		return scalers.NewAzurePipelinesScaler(config)
-	case "azure-queue":
-		return scalers.NewAzureQueueScaler(config)
-	case "azure-servicebus":
-		return scalers.NewAzureServiceBusScaler(ctx, config)
-	case "cassandra":
-		return scalers.NewCassandraScaler(config)
+	case "artifactory":
+		return scalers.NewArtifactoryScaler(config)
+	case "beanstalkd":
+		return scalers.NewBeanstalkdScaler(config)
+	case "buildkite":
+		return scalers.NewBuildkiteScaler(config)
+	case "camunda-zeebe":
+		return scalers.NewZeebeScaler(config)
+	case "consul":
+		return scalers.NewConsulScaler(config)
+	case "coredns":
+		return scalers.NewCoreDNSScaler(config)
 	case "cpu":
 		return scalers.NewCPUMemoryScaler(corev1.ResourceCPU, config)
 	case "cron":
 		return scalers.NewCronScaler(config)
-	case "elasticsearch":
-		return scalers.NewElasticsearchScaler(config)
+	case "datadog":
+		return scalers.NewDatadogScaler(config)
+	case "dynatrace":
+		return scalers.NewDynatraceScaler(config)
+	case "etcd":
+		return scalers.NewEtcdScaler(config)
+	case "exporter-scrape":
+		return scalers.NewExporterScrapeScaler(config)
 	case "external":
 		return scalers.NewExternalScaler(config)
 	case "external-push":
 		return scalers.NewExternalPushScaler(config)
-	case "gcp-pubsub":
-		return scalers.NewPubSubScaler(config)
+	case "feature-flag":
+		return scalers.NewFeatureFlagScaler(config)
+	case "github-runner":
+		return scalers.NewGitHubRunnerScaler(config)
+	case "gitlab-runner":
+		return scalers.NewGitLabRunnerScaler(config)
 	case "graphite":
 		return scalers.NewGraphiteScaler(config)
-	case "huawei-cloudeye":
-		return scalers.NewHuaweiCloudeyeScaler(config)
+	case "haproxy":
+		return scalers.NewHAProxyScaler(config)
 	case "ibmmq":
 		return scalers.NewIBMMQScaler(config)
-	case "influxdb":
-		return scalers.NewInfluxDBScaler(config)
-	case "kafka":
-		return scalers.NewKafkaScaler(config)
+	case "imap":
+		return scalers.NewImapScaler(config)
+	case "jenkins":
+		return scalers.NewJenkinsScaler(config)
+	case "jolokia":
+		return scalers.NewJolokiaScaler(config)
+	case "knative":
+		return scalers.NewKnativeScaler(config)
+	case "kong":
+		return scalers.NewKongScaler(config)
 	case "kubernetes-workload":
 		return scalers.NewKubernetesWorkloadScaler(client, config)
 	case "liiklus":
 		return scalers.NewLiiklusScaler(config)
+	case "litmus-chaos":
+		return scalers.NewLitmusChaosScaler(client, config)
+	case "loki":
+		return scalers.NewLokiScaler(config)
 	case "memory":
 		return scalers.NewCPUMemoryScaler(corev1.ResourceMemory, config)
 	case "metrics-api":
 		return scalers.NewMetricsAPIScaler(config)
-	case "mongodb":
-		return scalers.NewMongoDBScaler(ctx, config)
-	case "mssql":
-		return scalers.NewMSSQLScaler(config)
-	case "mysql":
-		return scalers.NewMySQLScaler(config)
+	case "minio":
+		return scalers.NewMinioScaler(config)
+	case "nats-jetstream":
+		return scalers.NewNATSJetStreamScaler(config)
+	case "new-relic":
+		return scalers.NewNewRelicScaler(config)
 	case "openstack-metric":
 		return scalers.NewOpenstackMetricScaler(ctx, config)
 	case "openstack-swift":
 		return scalers.NewOpenstackSwiftScaler(ctx, config)
-	case "postgresql":
-		return scalers.NewPostgreSQLScaler(config)
 	case "prometheus":
 		return scalers.NewPrometheusScaler(config)
+	case "pulsar":
+		return scalers.NewPulsarScaler(config)
 	case "rabbitmq":
 		return scalers.NewRabbitMQScaler(config)
-	case "redis":
-		return scalers.NewRedisScaler(ctx, false, false, config)
-	case "redis-cluster":
-		return scalers.NewRedisScaler(ctx, true, false, config)
-	case "redis-cluster-streams":
-		return scalers.NewRedisStreamsScaler(ctx, true, false, config)
-	case "redis-sentinel":
-		return scalers.NewRedisScaler(ctx, false, true, config)
-	case "redis-sentinel-streams":
-		return scalers.NewRedisStreamsScaler(ctx, false, true, config)
-	case "redis-streams":
-		return scalers.NewRedisStreamsScaler(ctx, false, false, config)
+	case "rocketmq":
+		return scalers.NewRocketMQScaler(config)
 	case "selenium-grid":
 		return scalers.NewSeleniumGridScaler(config)
 	case "solace-event-queue":
 		return scalers.NewSolaceScaler(config)
+	case "splunk":
+		return scalers.NewSplunkScaler(config)
 	case "stan":
 		return scalers.NewStanScaler(config)
+	case "temporal":
+		return scalers.NewTemporalScaler(config)
+	case "warm-pool":
+		return buildWarmPoolScaler(ctx, client, config)
+	case "windows-exporter":
+		return scalers.NewWindowsExporterScaler(config)
 	default:
+		if builder, ok := registeredScalerBuilders[triggerType]; ok {
+			return builder(ctx, client, config)
+		}
 		return nil, fmt.Errorf("no scaler found for type: %s", triggerType)
 	}
 	// TRIGGERS-END
@@ -424,12 +624,19 @@ func buildScaler(ctx context.Context, client client.Client, triggerType string,
 func asDuckWithTriggers(scalableObject interface{}) (*kedav1alpha1.WithTriggers, error) {
 	switch obj := scalableObject.(type) {
 	case *kedav1alpha1.ScaledObject:
+		objectMeta, triggers := obj.ObjectMeta, obj.Spec.Triggers
+		// the applied triggers stay in effect while changed triggers are evaluated, the scalers cached
+		// for the generation they are from are kept until the changed triggers take effect
+		if obj.Status.Canary.IsEvaluating() {
+			objectMeta.Generation = obj.Status.Canary.AppliedGeneration
+			triggers = obj.Status.Canary.AppliedTriggers
+		}
 		return &kedav1alpha1.WithTriggers{
 			TypeMeta:   obj.TypeMeta,
-			ObjectMeta: obj.ObjectMeta,
+			ObjectMeta: objectMeta,
 			Spec: kedav1alpha1.WithTriggersSpec{
 				PollingInterval: obj.Spec.PollingInterval,
-				Triggers:        obj.Spec.Triggers,
+				Triggers:        triggers,
 			},
 		}, nil
 	case *kedav1alpha1.ScaledJob:
@@ -24,6 +24,7 @@ import (
 	"github.com/golang/mock/gomock"
 	"github.com/stretchr/testify/assert"
 	"k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/record"
@@ -124,3 +125,39 @@ func createMetricSpec(averageValue int) v2beta2.MetricSpec {
 		},
 	}
 }
+
+func TestIsHPAFailingToGetExternalMetrics(t *testing.T) {
+	hpa := &v2beta2.HorizontalPodAutoscaler{}
+	assert.False(t, isHPAFailingToGetExternalMetrics(hpa))
+
+	hpa.Status.Conditions = []v2beta2.HorizontalPodAutoscalerCondition{
+		{Type: v2beta2.AbleToScale, Status: corev1.ConditionTrue},
+		{Type: v2beta2.ScalingActive, Status: corev1.ConditionTrue, Reason: "ValidMetricFound"},
+	}
+	assert.False(t, isHPAFailingToGetExternalMetrics(hpa))
+
+	hpa.Status.Conditions[1] = v2beta2.HorizontalPodAutoscalerCondition{Type: v2beta2.ScalingActive, Status: corev1.ConditionFalse, Reason: "FailedGetExternalMetric"}
+	assert.True(t, isHPAFailingToGetExternalMetrics(hpa))
+}
+
+func TestClampReplicas(t *testing.T) {
+	minReplicas := int32(2)
+	assert.Equal(t, int32(1), clampReplicas(0, nil, 10))
+	assert.Equal(t, int32(2), clampReplicas(1, &minReplicas, 10))
+	assert.Equal(t, int32(5), clampReplicas(5, &minReplicas, 10))
+	assert.Equal(t, int32(10), clampReplicas(50, &minReplicas, 10))
+}
+
+func TestScaledObjectMaxReplicas(t *testing.T) {
+	scaledObject := &kedav1alpha1.ScaledObject{}
+	assert.Equal(t, int32(100), scaledObjectMaxReplicas(scaledObject))
+
+	maxReplicas := int32(10)
+	scaledObject.Spec.MaxReplicaCount = &maxReplicas
+	assert.Equal(t, int32(10), scaledObjectMaxReplicas(scaledObject))
+
+	resolved := int32(6)
+	scaledObject.Spec.MaxReplicaCountFrom = &kedav1alpha1.MaxReplicaCountSource{TriggerName: "orders"}
+	scaledObject.Status.ResolvedMaxReplicaCount = &resolved
+	assert.Equal(t, int32(6), scaledObjectMaxReplicas(scaledObject))
+}
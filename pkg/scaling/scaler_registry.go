@@ -0,0 +1,45 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kedacore/keda/v2/pkg/scalers"
+)
+
+// Scalers with large SDK dependencies are grouped in families which register their builders from scalers_<family>.go.
+// Every family is built by default, building with the tag selective_scalers only includes the families
+// whose tag is also set, eg. "-tags selective_scalers,scalers_aws" builds the AWS scalers and the core scalers.
+
+// scalerBuilderFunc creates the scaler of a trigger type that is registered by a scaler family
+type scalerBuilderFunc func(ctx context.Context, client client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error)
+
+var registeredScalerBuilders = map[string]scalerBuilderFunc{}
+
+// registerScalerBuilders is called from the init of the scaler families included in the build
+func registerScalerBuilders(builders map[string]scalerBuilderFunc) {
+	for triggerType, builder := range builders {
+		if _, ok := registeredScalerBuilders[triggerType]; ok {
+			panic(fmt.Sprintf("scaler for type %s is registered twice", triggerType))
+		}
+		registeredScalerBuilders[triggerType] = builder
+	}
+}
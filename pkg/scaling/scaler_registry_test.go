@@ -0,0 +1,44 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kedacore/keda/v2/pkg/scalers"
+)
+
+func TestRegisterScalerBuildersTwicePanics(t *testing.T) {
+	builder := func(context.Context, client.Client, *scalers.ScalerConfig) (scalers.Scaler, error) {
+		return nil, nil
+	}
+	registerScalerBuilders(map[string]scalerBuilderFunc{"test-registry": builder})
+	defer delete(registeredScalerBuilders, "test-registry")
+
+	assert.Panics(t, func() {
+		registerScalerBuilders(map[string]scalerBuilderFunc{"test-registry": builder})
+	})
+}
+
+func TestBuildScalerUnknownType(t *testing.T) {
+	_, err := buildScaler(context.Background(), nil, "unknown", &scalers.ScalerConfig{})
+	assert.EqualError(t, err, "no scaler found for type: unknown")
+}
@@ -0,0 +1,57 @@
+//go:build !selective_scalers || scalers_aws
+// +build !selective_scalers scalers_aws
+
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kedacore/keda/v2/pkg/scalers"
+)
+
+func init() {
+	registerScalerBuilders(map[string]scalerBuilderFunc{
+		"aws-batch": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewAwsBatchScaler(config)
+		},
+		"aws-cloudwatch": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewAwsCloudwatchScaler(config)
+		},
+		"aws-dynamodb": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewAwsDynamoDBScaler(config)
+		},
+		"aws-dynamodb-streams": func(ctx context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewAwsDynamoDBStreamsScaler(ctx, config)
+		},
+		"aws-kinesis-stream": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewAwsKinesisStreamScaler(config)
+		},
+		"aws-s3": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewAwsS3Scaler(config)
+		},
+		"aws-sqs-queue": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewAwsSqsQueueScaler(config)
+		},
+		"aws-step-functions": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewAwsStepFunctionsScaler(config)
+		},
+	})
+}
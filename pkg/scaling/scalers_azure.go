@@ -0,0 +1,72 @@
+//go:build !selective_scalers || scalers_azure
+// +build !selective_scalers scalers_azure
+
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kedacore/keda/v2/pkg/scalers"
+)
+
+func init() {
+	registerScalerBuilders(map[string]scalerBuilderFunc{
+		"azure-blob": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewAzureBlobScaler(config)
+		},
+		"azure-cosmosdb": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewAzureCosmosDBScaler(config)
+		},
+		"azure-data-explorer": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewAzureDataExplorerScaler(config)
+		},
+		"azure-eventhub": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewAzureEventHubScaler(config)
+		},
+		"azure-iot-hub-twin": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewAzureIotHubTwinScaler(config)
+		},
+		"azure-log-analytics": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewAzureLogAnalyticsScaler(config)
+		},
+		"azure-managed-grafana": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewAzureManagedGrafanaScaler(config)
+		},
+		"azure-monitor": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewAzureMonitorScaler(config)
+		},
+		"azure-notification-hubs": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewAzureNotificationHubsScaler(config)
+		},
+		"azure-pipelines": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewAzurePipelinesScaler(config)
+		},
+		"azure-purview": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewAzurePurviewScaler(config)
+		},
+		"azure-queue": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewAzureQueueScaler(config)
+		},
+		"azure-servicebus": func(ctx context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewAzureServiceBusScaler(ctx, config)
+		},
+	})
+}
@@ -0,0 +1,72 @@
+//go:build !selective_scalers || scalers_database
+// +build !selective_scalers scalers_database
+
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kedacore/keda/v2/pkg/scalers"
+)
+
+func init() {
+	registerScalerBuilders(map[string]scalerBuilderFunc{
+		"cassandra": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewCassandraScaler(config)
+		},
+		"clickhouse": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewClickHouseScaler(config)
+		},
+		"cockroachdb": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewCockroachDBScaler(config)
+		},
+		"couchbase": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewCouchbaseScaler(config)
+		},
+		"elasticsearch": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewElasticsearchScaler(config)
+		},
+		"influxdb": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewInfluxDBScaler(config)
+		},
+		"mongodb": func(ctx context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewMongoDBScaler(ctx, config)
+		},
+		"mssql": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewMSSQLScaler(config)
+		},
+		"mysql": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewMySQLScaler(config)
+		},
+		"opensearch": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewOpenSearchScaler(config)
+		},
+		"oracle-aq": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewOracleAQScaler(config)
+		},
+		"postgresql": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewPostgreSQLScaler(config)
+		},
+		"snowflake": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewSnowflakeScaler(config)
+		},
+	})
+}
@@ -0,0 +1,54 @@
+//go:build !selective_scalers || scalers_gcp
+// +build !selective_scalers scalers_gcp
+
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kedacore/keda/v2/pkg/scalers"
+)
+
+func init() {
+	registerScalerBuilders(map[string]scalerBuilderFunc{
+		"gcp-bigquery": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewBigQueryScaler(config)
+		},
+		"gcp-cloud-tasks": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewCloudTasksScaler(config)
+		},
+		"gcp-dataflow": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewDataflowScaler(config)
+		},
+		"gcp-fcm": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewFcmScaler(config)
+		},
+		"gcp-pubsub": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewPubSubScaler(config)
+		},
+		"gcp-pubsub-lite": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewPubSubLiteScaler(config)
+		},
+		"gcp-spanner": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewSpannerScaler(config)
+		},
+	})
+}
@@ -0,0 +1,36 @@
+//go:build !selective_scalers || scalers_huawei
+// +build !selective_scalers scalers_huawei
+
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/kedacore/keda/v2/pkg/scalers"
+)
+
+func init() {
+	registerScalerBuilders(map[string]scalerBuilderFunc{
+		"huawei-cloudeye": func(_ context.Context, _ client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+			return scalers.NewHuaweiCloudeyeScaler(config)
+		},
+	})
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"k8s.io/api/autoscaling/v2beta2"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+
+	"github.com/kedacore/keda/v2/pkg/scalers"
+)
+
+const warmPoolTriggerType = "warm-pool"
+
+// warmPoolCronKeys are the metadata of a warm-pool trigger configuring the cron baseline, the other metadata
+// but scalerType configure the scaler of scalerType
+var warmPoolCronKeys = []string{"timezone", "start", "end", "desiredReplicas"}
+
+var warmPoolLog = logf.Log.WithName("warm_pool_scaler")
+
+// warmPoolScaler combines the desired replicas of a cron schedule with the metrics of a live scaler, the HPA
+// scales to the max of both so the schedule keeps a warm pool the live scaler can only add to
+type warmPoolScaler struct {
+	cron   scalers.Scaler
+	scaler scalers.Scaler
+}
+
+// buildWarmPoolScaler builds the cron scaler and the scaler of scalerType of a warm-pool trigger,
+// the auth params are those of the scaler
+func buildWarmPoolScaler(ctx context.Context, client client.Client, config *scalers.ScalerConfig) (scalers.Scaler, error) {
+	scalerType := config.TriggerMetadata["scalerType"]
+	switch scalerType {
+	case "":
+		return nil, fmt.Errorf("error parsing warm-pool metadata: no scalerType given")
+	case warmPoolTriggerType, "cron", "external-push":
+		return nil, fmt.Errorf("error parsing warm-pool metadata: scalerType %s can't be combined with a cron schedule", scalerType)
+	}
+
+	cronConfig := *config
+	cronConfig.TriggerMetadata = map[string]string{}
+	scalerConfig := *config
+	scalerConfig.TriggerMetadata = map[string]string{}
+	for key, value := range config.TriggerMetadata {
+		if key == "scalerType" {
+			continue
+		}
+		if isWarmPoolCronKey(key) {
+			cronConfig.TriggerMetadata[key] = value
+		} else {
+			scalerConfig.TriggerMetadata[key] = value
+		}
+	}
+
+	cron, err := scalers.NewCronScaler(&cronConfig)
+	if err != nil {
+		return nil, err
+	}
+	scaler, err := buildScaler(ctx, client, scalerType, &scalerConfig)
+	if err != nil {
+		return nil, err
+	}
+	return &warmPoolScaler{cron: cron, scaler: scaler}, nil
+}
+
+func isWarmPoolCronKey(key string) bool {
+	for _, cronKey := range warmPoolCronKeys {
+		if key == cronKey {
+			return true
+		}
+	}
+	return false
+}
+
+// IsActive is true during the schedule or while the scaler is active, errors of the scaler are ignored
+// during the schedule so the warm pool is kept when the scaler fails
+func (s *warmPoolScaler) IsActive(ctx context.Context) (bool, error) {
+	cronActive, err := s.cron.IsActive(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	active, err := s.scaler.IsActive(ctx)
+	if cronActive {
+		if err != nil {
+			warmPoolLog.Error(err, "error checking the scaler of the warm pool, keeping the scheduled replicas")
+		}
+		return true, nil
+	}
+	return active, err
+}
+
+func (s *warmPoolScaler) Close(ctx context.Context) error {
+	if err := s.cron.Close(ctx); err != nil {
+		return err
+	}
+	return s.scaler.Close(ctx)
+}
+
+// GetMetricSpecForScaling returns the metric specs of the schedule and of the scaler, the HPA takes the max of them
+func (s *warmPoolScaler) GetMetricSpecForScaling(ctx context.Context) []v2beta2.MetricSpec {
+	return append(s.cron.GetMetricSpecForScaling(ctx), s.scaler.GetMetricSpecForScaling(ctx)...)
+}
+
+// GetMetrics returns the metric of the schedule or of the scaler, depending on the metric name
+func (s *warmPoolScaler) GetMetrics(ctx context.Context, metricName string, metricSelector labels.Selector) ([]external_metrics.ExternalMetricValue, error) {
+	for _, spec := range s.cron.GetMetricSpecForScaling(ctx) {
+		if spec.External != nil && strings.EqualFold(spec.External.Metric.Name, metricName) {
+			return s.cron.GetMetrics(ctx, metricName, metricSelector)
+		}
+	}
+	return s.scaler.GetMetrics(ctx, metricName, metricSelector)
+}
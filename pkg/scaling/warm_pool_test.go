@@ -0,0 +1,131 @@
+/*
+Copyright 2021 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package scaling
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/api/autoscaling/v2beta2"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/metrics/pkg/apis/external_metrics"
+
+	mock_scalers "github.com/kedacore/keda/v2/pkg/mock/mock_scaler"
+	"github.com/kedacore/keda/v2/pkg/scalers"
+)
+
+func TestBuildWarmPoolScaler(t *testing.T) {
+	cronMetadata := map[string]string{"timezone": "Etc/UTC", "start": "0 8 * * 1-5", "end": "0 18 * * 1-5", "desiredReplicas": "5"}
+	tests := []struct {
+		name       string
+		metadata   map[string]string
+		specsTypes []v2beta2.MetricSourceType
+		isError    bool
+	}{
+		{"cron and cpu", map[string]string{"scalerType": "cpu", "type": "Utilization", "value": "50"}, []v2beta2.MetricSourceType{v2beta2.ExternalMetricSourceType, v2beta2.ResourceMetricSourceType}, false},
+		{"missing scalerType", map[string]string{"type": "Utilization", "value": "50"}, nil, true},
+		{"nested warm-pool", map[string]string{"scalerType": "warm-pool"}, nil, true},
+		{"cron scalerType", map[string]string{"scalerType": "cron"}, nil, true},
+		{"unknown scalerType", map[string]string{"scalerType": "unknown"}, nil, true},
+		{"invalid scaler metadata", map[string]string{"scalerType": "cpu", "type": "Utilization"}, nil, true},
+		{"invalid cron metadata", map[string]string{"scalerType": "cpu", "type": "Utilization", "value": "50", "desiredReplicas": "a"}, nil, true},
+	}
+	for _, test := range tests {
+		metadata := map[string]string{}
+		for k, v := range cronMetadata {
+			metadata[k] = v
+		}
+		for k, v := range test.metadata {
+			metadata[k] = v
+		}
+
+		scaler, err := buildWarmPoolScaler(context.TODO(), nil, &scalers.ScalerConfig{TriggerMetadata: metadata, ScalerIndex: 1})
+		if test.isError {
+			assert.Error(t, err, test.name)
+			continue
+		}
+		assert.NoError(t, err, test.name)
+
+		var specTypes []v2beta2.MetricSourceType
+		for _, spec := range scaler.GetMetricSpecForScaling(context.TODO()) {
+			specTypes = append(specTypes, spec.Type)
+		}
+		assert.Equal(t, test.specsTypes, specTypes, test.name)
+		assert.Equal(t, corev1.ResourceCPU, scaler.GetMetricSpecForScaling(context.TODO())[1].Resource.Name, test.name)
+	}
+}
+
+func TestWarmPoolScalerIsActive(t *testing.T) {
+	scalerErr := errors.New("broker unavailable")
+	tests := []struct {
+		name         string
+		cronActive   bool
+		scalerActive bool
+		scalerErr    error
+		active       bool
+		isError      bool
+	}{
+		{"scheduled and idle", true, false, nil, true, false},
+		{"unscheduled and busy", false, true, nil, true, false},
+		{"unscheduled and idle", false, false, nil, false, false},
+		{"scheduled and failing", true, false, scalerErr, true, false},
+		{"unscheduled and failing", false, false, scalerErr, false, true},
+	}
+	for _, test := range tests {
+		ctrl := gomock.NewController(t)
+		cron := mock_scalers.NewMockScaler(ctrl)
+		scaler := mock_scalers.NewMockScaler(ctrl)
+		cron.EXPECT().IsActive(gomock.Any()).Return(test.cronActive, nil)
+		scaler.EXPECT().IsActive(gomock.Any()).Return(test.scalerActive, test.scalerErr)
+
+		active, err := (&warmPoolScaler{cron: cron, scaler: scaler}).IsActive(context.TODO())
+		assert.Equal(t, test.active, active, test.name)
+		assert.Equal(t, test.isError, err != nil, test.name)
+		ctrl.Finish()
+	}
+}
+
+func TestWarmPoolScalerGetMetrics(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	cron := mock_scalers.NewMockScaler(ctrl)
+	scaler := mock_scalers.NewMockScaler(ctrl)
+	warmPool := &warmPoolScaler{cron: cron, scaler: scaler}
+
+	cronSpec := v2beta2.MetricSpec{Type: v2beta2.ExternalMetricSourceType, External: &v2beta2.ExternalMetricSource{Metric: v2beta2.MetricIdentifier{Name: "s0-cron-Etc-UTC-08xx1-5-018xx1-5"}}}
+	scalerSpec := v2beta2.MetricSpec{Type: v2beta2.ExternalMetricSourceType, External: &v2beta2.ExternalMetricSource{Metric: v2beta2.MetricIdentifier{Name: "s0-rabbitmq-orders"}}}
+	cron.EXPECT().GetMetricSpecForScaling(gomock.Any()).Return([]v2beta2.MetricSpec{cronSpec}).AnyTimes()
+	scaler.EXPECT().GetMetricSpecForScaling(gomock.Any()).Return([]v2beta2.MetricSpec{scalerSpec}).AnyTimes()
+
+	assert.Equal(t, []v2beta2.MetricSpec{cronSpec, scalerSpec}, warmPool.GetMetricSpecForScaling(context.TODO()))
+
+	cronMetric := external_metrics.ExternalMetricValue{MetricName: "s0-cron-etc-utc-08xx1-5-018xx1-5", Value: resource.MustParse("5")}
+	cron.EXPECT().GetMetrics(gomock.Any(), cronMetric.MetricName, nil).Return([]external_metrics.ExternalMetricValue{cronMetric}, nil)
+	metrics, err := warmPool.GetMetrics(context.TODO(), cronMetric.MetricName, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []external_metrics.ExternalMetricValue{cronMetric}, metrics)
+
+	scalerMetric := external_metrics.ExternalMetricValue{MetricName: "s0-rabbitmq-orders", Value: resource.MustParse("12")}
+	scaler.EXPECT().GetMetrics(gomock.Any(), scalerMetric.MetricName, nil).Return([]external_metrics.ExternalMetricValue{scalerMetric}, nil)
+	metrics, err = warmPool.GetMetrics(context.TODO(), scalerMetric.MetricName, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []external_metrics.ExternalMetricValue{scalerMetric}, metrics)
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2022 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+	"unicode"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+
+	kedav1alpha1 "github.com/kedacore/keda/v2/apis/keda/v1alpha1"
+)
+
+const (
+	// DefaultHPANameTemplate is the template of the names of the HPAs created for ScaledObjects
+	DefaultHPANameTemplate = "keda-hpa-{scaledobject}"
+
+	hpaNameTemplateEnvVar       = "KEDA_HPA_NAME_TEMPLATE"
+	hpaNameTemplateScaledObject = "{scaledobject}"
+	hpaNameTemplateNamespace    = "{namespace}"
+
+	// HPA names are used as label value, so they are limited to its length
+	maxHPANameLength  = 63
+	hpaNameHashLength = 8
+)
+
+// ResolveHPANameTemplate returns the HPA name template configured with KEDA_HPA_NAME_TEMPLATE, or the default one
+func ResolveHPANameTemplate() (string, error) {
+	template, ok := os.LookupEnv(hpaNameTemplateEnvVar)
+	if !ok || template == "" {
+		return DefaultHPANameTemplate, nil
+	}
+	if err := ValidateHPANameTemplate(template); err != nil {
+		return DefaultHPANameTemplate, err
+	}
+	return template, nil
+}
+
+// ValidateHPANameTemplate checks that the HPA names generated from the template are valid and unique per ScaledObject
+func ValidateHPANameTemplate(template string) error {
+	if !strings.Contains(template, hpaNameTemplateScaledObject) {
+		return fmt.Errorf("HPA name template %q doesn't contain %s", template, hpaNameTemplateScaledObject)
+	}
+	name := GenerateHPAName(template, &kedav1alpha1.ScaledObject{ObjectMeta: metav1.ObjectMeta{Name: "name", Namespace: "namespace"}})
+	if errs := validation.IsDNS1123Subdomain(name); len(errs) > 0 {
+		return fmt.Errorf("HPA name template %q doesn't generate a valid name: %s", template, strings.Join(errs, ", "))
+	}
+	return nil
+}
+
+// GenerateHPAName returns the HPA name for the ScaledObject from the template, names longer than a label value
+// are shortened and suffixed with a hash of the full name so they stay unique and stable
+func GenerateHPAName(template string, scaledObject *kedav1alpha1.ScaledObject) string {
+	name := strings.NewReplacer(
+		hpaNameTemplateScaledObject, scaledObject.Name,
+		hpaNameTemplateNamespace, scaledObject.Namespace,
+	).Replace(template)
+	if len(name) <= maxHPANameLength {
+		return name
+	}
+
+	hash := sha256.Sum256([]byte(name))
+	suffix := hex.EncodeToString(hash[:])[:hpaNameHashLength]
+	prefix := strings.TrimRightFunc(name[:maxHPANameLength-hpaNameHashLength-1], func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	})
+	return prefix + "-" + suffix
+}
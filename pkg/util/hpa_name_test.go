@@ -0,0 +1,59 @@
+/*
+Copyright 2022 The KEDA Authors
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package util
+
+import (
+	"testing"
+)
+
+func TestValidateHPANameTemplate(t *testing.T) {
+	tests := []struct {
+		template string
+		isError  bool
+	}{
+		{DefaultHPANameTemplate, false},
+		{"{namespace}.{scaledobject}", false},
+		{"keda-hpa", true},
+		{"KEDA_{scaledobject}", true},
+	}
+	for _, test := range tests {
+		err := ValidateHPANameTemplate(test.template)
+		if test.isError && err == nil {
+			t.Errorf("expected error for template %q", test.template)
+		}
+		if !test.isError && err != nil {
+			t.Errorf("expected no error for template %q, got %s", test.template, err)
+		}
+	}
+}
+
+func TestResolveHPANameTemplate(t *testing.T) {
+	t.Setenv(hpaNameTemplateEnvVar, "")
+	if template, err := ResolveHPANameTemplate(); err != nil || template != DefaultHPANameTemplate {
+		t.Errorf("expected the default template, got %q, %v", template, err)
+	}
+
+	t.Setenv(hpaNameTemplateEnvVar, "{scaledobject}-hpa")
+	if template, err := ResolveHPANameTemplate(); err != nil || template != "{scaledobject}-hpa" {
+		t.Errorf("expected the configured template, got %q, %v", template, err)
+	}
+
+	t.Setenv(hpaNameTemplateEnvVar, "keda-hpa")
+	if _, err := ResolveHPANameTemplate(); err == nil {
+		t.Error("expected error for a template without {scaledobject}")
+	}
+}
@@ -33,6 +33,11 @@ type HTTPDoer interface {
 // timeoutMS milliseconds, or 300 milliseconds if timeoutMS <= 0.
 // unsafeSsl parameter allows to avoid tls cert validation if it's required
 func CreateHTTPClient(timeout time.Duration, unsafeSsl bool) *http.Client {
+	return CreateHTTPClientWithTLSConfig(timeout, &tls.Config{InsecureSkipVerify: unsafeSsl})
+}
+
+// CreateHTTPClientWithTLSConfig returns a new HTTP client like CreateHTTPClient using the given TLS config
+func CreateHTTPClientWithTLSConfig(timeout time.Duration, tlsConfig *tls.Config) *http.Client {
 	// default the timeout to 300ms
 	if timeout <= 0 {
 		timeout = 300 * time.Millisecond
@@ -40,7 +45,7 @@ func CreateHTTPClient(timeout time.Duration, unsafeSsl bool) *http.Client {
 	httpClient := &http.Client{
 		Timeout: timeout,
 		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: unsafeSsl},
+			TLSClientConfig: tlsConfig,
 		},
 	}
 
@@ -55,3 +55,39 @@ func NewTLSConfig(clientCert, clientKey, caCert string) (*tls.Config, error) {
 
 	return config, nil
 }
+
+// TLSOptions are the TLS parameters a scaler reads from its trigger
+type TLSOptions struct {
+	CA         string
+	Cert       string
+	Key        string
+	ServerName string
+	UnsafeSsl  bool
+}
+
+// NewTLSConfigFromOptions returns a *tls.Config with the client certificate, the CA and the server name of the options.
+// Unlike NewTLSConfig the server certificate is verified against the CA, verification is only skipped with UnsafeSsl.
+func NewTLSConfigFromOptions(options TLSOptions) (*tls.Config, error) {
+	config := &tls.Config{
+		ServerName:         options.ServerName,
+		InsecureSkipVerify: options.UnsafeSsl,
+	}
+
+	if options.Cert != "" && options.Key != "" {
+		cert, err := tls.X509KeyPair([]byte(options.Cert), []byte(options.Key))
+		if err != nil {
+			return nil, fmt.Errorf("error parse X509KeyPair: %s", err)
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+
+	if options.CA != "" {
+		caCertPool := x509.NewCertPool()
+		if !caCertPool.AppendCertsFromPEM([]byte(options.CA)) {
+			return nil, fmt.Errorf("no certificate found in ca")
+		}
+		config.RootCAs = caCertPool
+	}
+
+	return config, nil
+}